@@ -0,0 +1,388 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// accountReferences lists what refers to an account by name, as lines for
+// display: directory mappings, overrides, schedule and network rules
+func accountReferences(config *Config, name string) []string {
+	var refs []string
+	for _, mapping := range config.Directories {
+		if mapping.Account == name {
+			if mapping.Worktrees != "" {
+				refs = append(refs, fmt.Sprintf("📁 worktrees '%s' of %s", mapping.Worktrees, contractHomePath(mapping.Path)))
+			} else {
+				refs = append(refs, "📁 "+contractHomePath(mapping.Path))
+			}
+		}
+	}
+	for _, override := range config.Overrides {
+		if override.Account == name {
+			refs = append(refs, "🎯 override "+override.Repo)
+		}
+	}
+	if config.Schedule != nil {
+		for i, rule := range config.Schedule.Rules {
+			if rule.Account == name {
+				refs = append(refs, fmt.Sprintf("⏰ schedule rule %d", i+1))
+			}
+		}
+	}
+	if config.Context != nil {
+		for i, rule := range config.Context.Rules {
+			if rule.Account == name {
+				refs = append(refs, fmt.Sprintf("🌐 network rule %d", i+1))
+			}
+		}
+	}
+	if config.CurrentAccount == name {
+		refs = append(refs, "⭐ current account")
+	}
+	return refs
+}
+
+// derivedFields adds what the stored fields imply: the SSH alias and the
+// fingerprint of the key, which tells whether two key paths hold the same key
+func derivedFields(account Account) accountFields {
+	fields := accountFields{}
+	alias, _ := json.Marshal(account.GetSSHHost())
+	fields["ssh alias"] = string(alias)
+	if account.SSHKey != "" {
+		if _, fingerprint, err := describePublicKey(account.SSHKey + ".pub"); err == nil {
+			value, _ := json.Marshal(fingerprint)
+			fields["key fingerprint"] = string(value)
+		}
+	}
+	return fields
+}
+
+func maskToken(display string) string {
+	if display == "-" || display == "(sealed)" {
+		return display
+	}
+	return "(set)"
+}
+
+// printAccountDiff shows two accounts side by side, marking differing rows
+func printAccountDiff(config *Config, a, b Account) {
+	fieldsA, fieldsB := fieldsOf(a), fieldsOf(b)
+	for key, value := range derivedFields(a) {
+		fieldsA[key] = value
+	}
+	for key, value := range derivedFields(b) {
+		fieldsB[key] = value
+	}
+	var keys []string
+	for key := range fieldsA {
+		keys = append(keys, key)
+	}
+	for key := range fieldsB {
+		if _, ok := fieldsA[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	width := len(a.Name)
+	for _, key := range keys {
+		if n := len([]rune(fieldsA.display(key))); n > width {
+			width = n
+		}
+	}
+	fmt.Printf("   %-18s %-*s %s\n", "FIELD", width, strings.ToUpper(a.Name), strings.ToUpper(b.Name))
+	for _, key := range keys {
+		mark := "="
+		if fieldsA[key] != fieldsB[key] {
+			mark = "≠"
+		}
+		displayA, displayB := fieldsA.display(key), fieldsB.display(key)
+		if displayA == "-" && displayB == "-" {
+			continue
+		}
+		// Tokens are compared but never shown
+		if key == "token" {
+			displayA, displayB = maskToken(displayA), maskToken(displayB)
+		}
+		fmt.Printf(" %s %-18s %-*s %s\n", mark, key, width, displayA, displayB)
+	}
+
+	fmt.Println()
+	for _, account := range []Account{a, b} {
+		refs := accountReferences(config, account.Name)
+		if len(refs) == 0 {
+			fmt.Printf("🔗 '%s' is not referenced by mappings or rules\n", account.Name)
+			continue
+		}
+		fmt.Printf("🔗 '%s' is used by:\n", account.Name)
+		for _, ref := range refs {
+			fmt.Println("   " + ref)
+		}
+	}
+}
+
+// mergePlan folds one account into another
+type mergePlan struct {
+	Keep, Fold Account
+	TakeFold   []string // Fields taken from the folded account
+}
+
+// planMerge takes the folded account's value for every field the kept one
+// leaves empty. Fields set on both sides keep the kept account's value unless
+// choose says otherwise; orgs are combined.
+func planMerge(keep, fold Account, choose func(key string, fieldsKeep, fieldsFold accountFields) bool) mergePlan {
+	plan := mergePlan{Keep: keep, Fold: fold}
+	fieldsKeep, fieldsFold := fieldsOf(keep), fieldsOf(fold)
+	for _, key := range differingFields(fieldsKeep, fieldsFold) {
+		if key == "orgs" || fieldsFold.display(key) == "-" {
+			continue
+		}
+		if fieldsKeep.display(key) == "-" || (choose != nil && choose(key, fieldsKeep, fieldsFold)) {
+			plan.TakeFold = append(plan.TakeFold, key)
+		}
+	}
+	return plan
+}
+
+// foldAccount replaces the kept account with merged, drops the folded account
+// and retargets everything that referred to it
+func (c *Config) foldAccount(merged Account, fold string) {
+	var accounts []Account
+	for _, account := range c.Accounts {
+		switch account.Name {
+		case merged.Name:
+			accounts = append(accounts, merged)
+		case fold:
+			// Dropped
+		default:
+			accounts = append(accounts, account)
+		}
+	}
+	c.Accounts = accounts
+
+	for i := range c.Directories {
+		if c.Directories[i].Account == fold {
+			c.Directories[i].Account = merged.Name
+		}
+	}
+	for i := range c.Overrides {
+		if c.Overrides[i].Account == fold {
+			c.Overrides[i].Account = merged.Name
+		}
+	}
+	if c.Schedule != nil {
+		for i := range c.Schedule.Rules {
+			if c.Schedule.Rules[i].Account == fold {
+				c.Schedule.Rules[i].Account = merged.Name
+			}
+		}
+	}
+	if c.Context != nil {
+		for i := range c.Context.Rules {
+			if c.Context.Rules[i].Account == fold {
+				c.Context.Rules[i].Account = merged.Name
+			}
+		}
+	}
+	if c.CurrentAccount == fold {
+		c.CurrentAccount = merged.Name
+	}
+}
+
+// mergeAccounts applies a merge plan: the kept account gets the chosen fields,
+// everything referring to the folded account is retargeted to it, and the
+// folded account and its generated SSH Host block are removed. The SSH key
+// files are left alone.
+func mergeAccounts(config *Config, plan mergePlan) error {
+	merged := mergeAccount(plan.Keep, plan.Fold, plan.TakeFold)
+	for _, org := range plan.Fold.Orgs {
+		if !containsString(merged.Orgs, org) {
+			merged.Orgs = append(merged.Orgs, org)
+		}
+	}
+
+	wasCurrent := config.CurrentAccount == plan.Fold.Name || config.CurrentAccount == merged.Name
+	retargetedOverride := false
+	for _, override := range config.Overrides {
+		if override.Account == plan.Fold.Name {
+			retargetedOverride = true
+		}
+	}
+	// The include files carry the identity, so they are rewritten too
+	identity := merged
+	if err := config.revealAccount(&identity); err != nil {
+		return err
+	}
+	if err := writeCommitTemplate(&identity); err != nil {
+		return err
+	}
+	if err := config.update(func(config *Config) error {
+		config.foldAccount(merged, plan.Fold.Name)
+		return nil
+	}); err != nil {
+		return errSaveConfig(err)
+	}
+	for _, mapping := range config.Directories {
+		if mapping.Account != merged.Name {
+			continue
+		}
+		trackFile(mapping.ConfigFile)
+		if err := writePrivateFile(mapping.ConfigFile, []byte(mapping.identityInclude(&identity))); err != nil {
+			return fmt.Errorf("failed to write %s: %w", mapping.ConfigFile, err)
+		}
+	}
+	if retargetedOverride {
+		if err := config.syncOverrides(); err != nil {
+			return fmt.Errorf("failed to update repository overrides: %w", err)
+		}
+	}
+	if wasCurrent {
+		if _, err := config.applyGlobalIdentity(&identity); err != nil {
+			return fmt.Errorf("failed to update the global identity: %w", err)
+		}
+	}
+
+	// Generated Host blocks belong to krakn; linked ones belong to the user
+	if plan.Fold.SSHHost == "" && plan.Fold.GetSSHHost() != merged.GetSSHHost() {
+		if _, err := removeSSHHostBlock(plan.Fold.GetSSHHost()); err != nil {
+			return fmt.Errorf("failed to remove the SSH Host block of '%s': %w", plan.Fold.Name, err)
+		}
+	}
+	if merged.SSHHost == "" && merged.SSHKey != "" && len(plan.TakeFold) > 0 {
+		if err := upsertSSHHostBlock(merged.GetSSHHost(), merged.GenerateSSHConfig()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printMergeResult summarizes a merge, including what is left to do by hand
+func printMergeResult(config *Config, plan mergePlan) {
+	fmt.Printf("🔀 Folded '%s' into '%s'\n", plan.Fold.Name, plan.Keep.Name)
+	if len(plan.TakeFold) > 0 {
+		fmt.Printf("   Taken from '%s': %s\n", plan.Fold.Name, strings.Join(plan.TakeFold, ", "))
+	}
+	for _, ref := range accountReferences(config, plan.Keep.Name) {
+		fmt.Println("   " + ref)
+	}
+	kept := mergeAccount(plan.Keep, plan.Fold, plan.TakeFold)
+	if plan.Fold.SSHKey != "" && plan.Fold.SSHKey != kept.SSHKey {
+		fmt.Printf("💡 %s is no longer used by any account; remove it and its provider key when you are sure\n", contractHomePath(plan.Fold.SSHKey))
+	}
+	if plan.Fold.GetSSHHost() != kept.GetSSHHost() {
+		fmt.Printf("💡 Remotes using %s need 'krakn fix-remote --account %s'\n", plan.Fold.GetSSHHost(), plan.Keep.Name)
+	}
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <accountA> <accountB>",
+	Short: "Compare two accounts field by field",
+	Long: `Compare two accounts field by field: email, username, provider, SSH key and
+its fingerprint, SSH options and the other settings, followed by the directory
+mappings, overrides and rules that use each account. Rows marked ≠ differ.
+This helps to consolidate duplicates, e.g. from running 'krakn migrate' twice.
+
+With --merge, accountB is folded into accountA: fields accountA leaves empty
+are taken from accountB, and for fields set on both you choose (accountA wins
+without a terminal). Directory mappings, overrides, schedule and network rules
+move to accountA, and accountB is removed together with its generated SSH
+Host block. SSH key files are never deleted.
+
+Examples:
+  krakn diff work work-2
+  krakn diff work work-2 --merge
+  krakn diff work work-2 --merge --yes`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		merge, _ := cmd.Flags().GetBool("merge")
+		yes, _ := cmd.Flags().GetBool("yes")
+		if args[0] == args[1] {
+			return fmt.Errorf("❌ Name two different accounts")
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		a := config.getAccount(args[0])
+		if a == nil {
+			return config.accountNotFound(args[0])
+		}
+		b := config.getAccount(args[1])
+		if b == nil {
+			return config.accountNotFound(args[1])
+		}
+
+		fmt.Printf("🔍 Comparing '%s' and '%s'\n\n", a.Name, b.Name)
+		printAccountDiff(config, *a, *b)
+		if !merge {
+			if len(differingFields(fieldsOf(*a), fieldsOf(*b))) == 0 {
+				fmt.Printf("\n💡 The accounts are identical; fold one into the other with 'krakn diff %s %s --merge'\n", a.Name, b.Name)
+			}
+			return nil
+		}
+		return runMerge(config, *a, *b, yes)
+	},
+}
+
+// runMerge asks how to merge two accounts, confirms and applies it
+func runMerge(config *Config, keep, fold Account, yes bool) error {
+	if keep.GetProvider().Hostname != fold.GetProvider().Hostname {
+		return fmt.Errorf("❌ '%s' is on %s and '%s' on %s; accounts on different providers cannot be merged",
+			keep.Name, keep.GetProvider().Hostname, fold.Name, fold.GetProvider().Hostname)
+	}
+	interactive := term.IsTerminal(int(os.Stdin.Fd()))
+	if !interactive && !yes {
+		return fmt.Errorf("❌ Merging needs a terminal to confirm; pass --yes to merge without asking")
+	}
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Printf("\n🔀 Folding '%s' into '%s'\n", fold.Name, keep.Name)
+	plan := planMerge(keep, fold, func(key string, fieldsKeep, fieldsFold accountFields) bool {
+		if !interactive {
+			return false
+		}
+		fmt.Printf("   %s: [k]eep %s / [t]ake %s [k]: ", key, fieldsKeep.display(key), fieldsFold.display(key))
+		input, _ := reader.ReadString('\n')
+		return strings.HasPrefix(strings.ToLower(strings.TrimSpace(input)), "t")
+	})
+	if len(plan.TakeFold) > 0 {
+		fmt.Printf("   Taken from '%s': %s\n", fold.Name, strings.Join(plan.TakeFold, ", "))
+	}
+	if refs := accountReferences(config, fold.Name); len(refs) > 0 {
+		fmt.Printf("   Moved to '%s':\n", keep.Name)
+		for _, ref := range refs {
+			fmt.Println("      " + ref)
+		}
+	}
+	fmt.Printf("   Removed: account '%s'\n", fold.Name)
+
+	if !yes {
+		fmt.Print("💬 Merge? [y/N]: ")
+		input, _ := reader.ReadString('\n')
+		if answer := strings.ToLower(strings.TrimSpace(input)); answer != "y" && answer != "yes" {
+			fmt.Println("❌ Merge cancelled")
+			return nil
+		}
+	}
+
+	if err := mergeAccounts(config, plan); err != nil {
+		return err
+	}
+	printMergeResult(config, plan)
+	return nil
+}
+
+func init() {
+	diffCmd.Flags().Bool("merge", false, "Fold accountB into accountA and retarget its mappings")
+	diffCmd.Flags().BoolP("yes", "y", false, "Merge without asking for confirmation")
+	RootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,33 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// restrictToOwner removes the group and other permission bits of path, so
+// that only its owner can use it. Files krakn creates already start out this
+// way; this also covers a restrictive create mode being widened elsewhere.
+func restrictToOwner(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if mode := info.Mode().Perm(); mode&0077 != 0 {
+		return os.Chmod(path, mode&^0077)
+	}
+	return nil
+}
+
+// listenPrivate opens a unix socket at path that only its owner can connect
+// to. The umask is tightened around Listen, so the socket never exists with
+// wider permissions, not even between creating and chmodding it.
+func listenPrivate(path string) (net.Listener, error) {
+	umask := syscall.Umask(0177)
+	listener, err := net.Listen("unix", path)
+	syscall.Umask(umask)
+	return listener, err
+}
@@ -0,0 +1,60 @@
+//go:build windows
+
+package cmd
+
+import (
+	"net"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// restrictToOwner replaces the access control list of path with one that
+// grants the current user alone full access, without inheriting the entries
+// of the parent directory. File modes mean little on Windows; this is what
+// OpenSSH for Windows checks on keys and ~/.ssh/config.
+func restrictToOwner(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tokenUser, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return err
+	}
+
+	inheritance := uint32(windows.NO_INHERITANCE)
+	if info.IsDir() {
+		inheritance = windows.SUB_CONTAINERS_AND_OBJECTS_INHERIT
+	}
+	acl, err := windows.ACLFromEntries([]windows.EXPLICIT_ACCESS{{
+		AccessPermissions: windows.GENERIC_ALL,
+		AccessMode:        windows.GRANT_ACCESS,
+		Inheritance:       inheritance,
+		Trustee: windows.TRUSTEE{
+			TrusteeForm:  windows.TRUSTEE_IS_SID,
+			TrusteeType:  windows.TRUSTEE_IS_USER,
+			TrusteeValue: windows.TrusteeValueFromSID(tokenUser.User.Sid),
+		},
+	}}, nil)
+	if err != nil {
+		return err
+	}
+	return windows.SetNamedSecurityInfo(path, windows.SE_FILE_OBJECT,
+		windows.DACL_SECURITY_INFORMATION|windows.PROTECTED_DACL_SECURITY_INFORMATION, nil, nil, acl, nil)
+}
+
+// listenPrivate opens a unix socket at path that only its owner can connect
+// to. Windows has no umask; the socket's access list is replaced right after
+// it is created.
+func listenPrivate(path string) (net.Listener, error) {
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := restrictToOwner(path); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
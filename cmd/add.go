@@ -12,10 +12,36 @@ import (
 
 var addCmd = &cobra.Command{
 	Use:   "add",
-	Short: "Add a new GitHub account",
-	Long:  "Add a new GitHub account with SSH key configuration",
+	Short: "Add a new account on GitHub, GitLab, Bitbucket, Codeberg or another provider",
+	Long: `Add a new account with SSH key configuration. You are prompted for the
+account name, email, username and SSH key; a new key can be generated on the spot
+and uploaded to the provider (see 'krakn generate-key --help' for the token it needs).
+
+--provider selects where the account lives: github (the default), gitlab,
+gitea, bitbucket, codeberg, azure-devops, sourcehut, the hostname of a
+self-hosted server, or custom to be asked for the server's SSH user, port and
+key settings page after probing it. ghes sets up a GitHub Enterprise Server
+instance: it asks for the hostname (or takes ghes:<hostname>), checks the
+instance and uses its API at https://<hostname>/api/v3 and its key settings
+page, next to any github.com accounts.
+
+Examples:
+  krakn add                 # Interactive setup
+  krakn account add         # Same, using the account command group
+  krakn add --key-dir /Volumes/Corp/ssh  # Keep the key on an encrypted volume
+  krakn add --upload        # Upload the public key to the provider without asking
+  krakn add --provider gitlab  # An account on gitlab.com
+  krakn add --provider ghes:github.company.com  # An account on GitHub Enterprise Server
+  krakn add --provider azure-devops  # Clones from git@ssh.dev.azure.com:v3/<org>/<project>/<repo>
+  krakn add --provider git.company.com  # An account on a self-hosted server
+  krakn add --provider custom  # Probe and describe a self-hosted server`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		providerName, _ := cmd.Flags().GetString("provider")
 		reader := bufio.NewReader(os.Stdin)
+		provider, err := resolveProvider(providerName, reader)
+		if err != nil {
+			return err
+		}
 
 		// Get account name
 		fmt.Print("💬 Account name (e.g., 'work', 'personal'): ")
@@ -33,39 +59,54 @@ var addCmd = &cobra.Command{
 			return fmt.Errorf("email cannot be empty")
 		}
 
-		// Get GitHub username
-		fmt.Print("👤 GitHub username: ")
+		// Get the provider username
+		fmt.Printf("👤 %s username: ", provider.DisplayName)
 		username, _ := reader.ReadString('\n')
 		username = strings.TrimSpace(username)
 		if username == "" {
-			return fmt.Errorf("GitHub username cannot be empty")
+			return fmt.Errorf("%s username cannot be empty", provider.DisplayName)
 		}
 
 		// Check for existing SSH key
-		homeDir, _ := os.UserHomeDir()
-		defaultSSHKey := filepath.Join(homeDir, ".ssh", fmt.Sprintf("id_ed25519_gh_%s", name))
-		
+		keyDir, _ := cmd.Flags().GetString("key-dir")
+		if keyDir != "" {
+			keyDir, _ = filepath.Abs(expandUserPath(keyDir))
+			if info, err := os.Stat(keyDir); err != nil || !info.IsDir() {
+				return fmt.Errorf("❌ Key directory not found: %s. Mount the volume and create it first", keyDir)
+			}
+		}
+		defaultSSHKey := defaultKeyPath(keyDir, provider.KeySuffix, name)
+
 		fmt.Printf("🔑 SSH key path [%s]: ", defaultSSHKey)
 		sshKeyInput, _ := reader.ReadString('\n')
 		sshKeyInput = strings.TrimSpace(sshKeyInput)
-		
+
 		sshKey := defaultSSHKey
 		if sshKeyInput != "" {
 			sshKey = sshKeyInput
 		}
+		if keyDir != "" && !insideDir(expandUserPath(sshKey), keyDir) {
+			return fmt.Errorf("❌ SSH key %s is outside the key directory %s", sshKey, contractHomePath(keyDir))
+		}
 
 		// Verify SSH key exists
+		generated := false
 		if _, err := os.Stat(sshKey); os.IsNotExist(err) {
 			fmt.Printf("⚠️  SSH key not found at %s\n", sshKey)
 			fmt.Print("🤔 Do you want to generate it now? [Y/n]: ")
 			resp, _ := reader.ReadString('\n')
 			resp = strings.ToLower(strings.TrimSpace(resp))
-			
+
 			if resp == "y" || resp == "" {
+				passphrase, err := newKeyPassphrase(false)
+				if err != nil {
+					return err
+				}
 				// Generate SSH key
-				if err := generateSSHKey(name, email, sshKey); err != nil {
+				if err := generateSSHKey(name, email, sshKey, passphrase, &provider); err != nil {
 					return fmt.Errorf("failed to generate SSH key: %w", err)
 				}
+				generated = true
 			} else {
 				return fmt.Errorf("cannot add account without SSH key")
 			}
@@ -74,7 +115,7 @@ var addCmd = &cobra.Command{
 		// Load config and add account
 		config, err := loadConfig()
 		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+			return errLoadConfig(err)
 		}
 
 		account := Account{
@@ -82,6 +123,10 @@ var addCmd = &cobra.Command{
 			Email:    email,
 			SSHKey:   sshKey,
 			Username: username,
+			KeyDir:   keyDir,
+		}
+		if provider != DefaultProviders["github"] {
+			account.Provider = &provider
 		}
 
 		if err := config.addAccount(account); err != nil {
@@ -89,13 +134,28 @@ var addCmd = &cobra.Command{
 		}
 
 		fmt.Printf("✅ Account '%s' added successfully!\n", name)
-		fmt.Printf("🔗 SSH Host: github.com-%s\n", name)
+		fmt.Printf("🔗 SSH Host: %s\n", account.GetSSHHost())
+		if fingerprint := keyFingerprint(sshKey); fingerprint != "" {
+			fmt.Printf("🔏 Key fingerprint: %s\n", fingerprint)
+		}
 		fmt.Printf("📂 Config saved to: %s\n", getConfigPath())
 
+		// A new key is not on GitHub yet; an existing one usually already is
+		upload, _ := cmd.Flags().GetBool("upload")
+		if (generated || upload) && offerKeyUpload(config, &account, upload) {
+			if err := config.saveKeyID(account.Name, account.KeyID); err != nil {
+				return errSaveConfig(err)
+			}
+		}
+
 		return nil
 	},
 }
 
 func init() {
+	addCmd.Flags().String("provider", "github", "Provider of the account: github, ghes[:<hostname>], gitlab, gitea, bitbucket, codeberg, azure-devops, sourcehut, a self-hosted hostname, custom, or sandbox (see 'krakn demo')")
+	addCmd.RegisterFlagCompletionFunc("provider", completeProvider)
+	addCmd.Flags().Bool("upload", false, "Upload the public key to the provider without asking")
+	addCmd.Flags().String("key-dir", "", "Directory the account's key must live in, e.g. on an encrypted volume")
 	RootCmd.AddCommand(addCmd)
 }
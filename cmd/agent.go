@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultControlPersist keeps an idle master connection open for follow-up fetches
+const defaultControlPersist = "10m"
+
+// getSocketDir returns the directory holding ControlMaster sockets
+func getSocketDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".krakncat", "sockets")
+}
+
+// ensureSocketDir creates the socket directory and tightens its permissions.
+// OpenSSH refuses control sockets in directories other users can write to.
+func ensureSocketDir() error {
+	dir := getSocketDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm() != 0700 {
+		if err := os.Chmod(dir, 0700); err != nil {
+			return fmt.Errorf("failed to secure socket directory: %w", err)
+		}
+		fmt.Printf("🔒 Fixed permissions on %s (was %o)\n", dir, info.Mode().Perm())
+	}
+	return nil
+}
+
+// resetControlSockets asks every master connection to exit and removes
+// sockets whose master is gone. It returns the number of sockets cleaned.
+func resetControlSockets() (int, error) {
+	entries, err := os.ReadDir(getSocketDir())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	cleaned := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		socket := filepath.Join(getSocketDir(), entry.Name())
+
+		// The destination is ignored when -S points at an existing master
+		output, err := traceExec(exec.Command("ssh", "-S", socket, "-O", "exit", "krakn-control")).CombinedOutput()
+		if err == nil {
+			fmt.Printf("🛑 Closed master connection %s\n", entry.Name())
+		} else {
+			fmt.Printf("🧹 Removed stale socket %s (%s)\n", entry.Name(), strings.TrimSpace(string(output)))
+		}
+		if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+			return cleaned, fmt.Errorf("failed to remove %s: %w", socket, err)
+		}
+		cleaned++
+	}
+	return cleaned, nil
+}
+
+// checkSocketDir reports a socket directory that ssh would refuse to use
+func checkSocketDir(ctx *doctorContext) []doctorFinding {
+	multiplexed := false
+	for _, account := range ctx.Config.Accounts {
+		if account.Multiplex {
+			multiplexed = true
+			break
+		}
+	}
+	if !multiplexed {
+		return nil
+	}
+
+	info, err := os.Stat(getSocketDir())
+	switch {
+	case os.IsNotExist(err):
+		return []doctorFinding{{Level: doctorWarn, Message: "Socket directory " + getSocketDir() + " is missing", Hint: "krakn agent reset"}}
+	case err != nil:
+		return []doctorFinding{{Level: doctorError, Message: err.Error()}}
+	case info.Mode().Perm()&0077 != 0:
+		return []doctorFinding{{
+			Level:   doctorWarn,
+			Message: fmt.Sprintf("Socket directory %s is accessible by other users (%o)", getSocketDir(), info.Mode().Perm()),
+			Hint:    "krakn agent reset",
+		}}
+	}
+	return []doctorFinding{{Level: doctorOK, Message: "Socket directory " + getSocketDir() + " is private"}}
+}
+
+var multiplexCmd = &cobra.Command{
+	Use:   "multiplex [account-name] [on|off]",
+	Short: "Enable or disable SSH connection multiplexing for an account",
+	Long: `Enable or disable ControlMaster/ControlPersist for an account's SSH host.
+A shared master connection makes many small fetches much faster. Sockets live
+in ~/.krakncat/sockets, which krakncat keeps private (0700).
+
+If connections start failing after a network change, run 'krakn agent reset'.
+
+Examples:
+  krakn multiplex work on
+  krakn multiplex work on --persist 30m
+  krakn multiplex work off`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountName := args[0]
+		persist, _ := cmd.Flags().GetString("persist")
+
+		var enable bool
+		switch strings.ToLower(args[1]) {
+		case "on", "true", "yes":
+			enable = true
+		case "off", "false", "no":
+			enable = false
+		default:
+			return fmt.Errorf("❌ Expected 'on' or 'off', got '%s'", args[1])
+		}
+
+		if enable && runtime.GOOS == "windows" {
+			return fmt.Errorf("❌ OpenSSH for Windows does not support ControlMaster")
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		account := config.getAccount(accountName)
+		if account == nil {
+			return config.accountNotFound(accountName)
+		}
+		if account.SSHHost != "" {
+			return fmt.Errorf("❌ Account '%s' is linked to your own alias %s; add ControlMaster options to that block directly", accountName, account.SSHHost)
+		}
+
+		if enable {
+			if err := ensureSocketDir(); err != nil {
+				return err
+			}
+		}
+
+		account.Multiplex = enable
+		account.ControlPersist = ""
+		if enable && persist != defaultControlPersist {
+			account.ControlPersist = persist
+		}
+
+		if err := config.addAccount(*account); err != nil {
+			return errSaveConfig(err)
+		}
+		if err := upsertSSHHostBlock(account.GetSSHHost(), account.GenerateSSHConfig()); err != nil {
+			return err
+		}
+
+		if enable {
+			fmt.Printf("⚡ Multiplexing enabled for '%s' (%s)\n", accountName, account.GetSSHHost())
+		} else {
+			fmt.Printf("✅ Multiplexing disabled for '%s'\n", accountName)
+		}
+		return nil
+	},
+}
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Manage SSH connection state used by krakncat accounts",
+	Long: `Manage SSH connection state used by krakncat accounts.
+
+Examples:
+  krakn agent reset`,
+}
+
+var agentResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Close multiplexed SSH connections and remove stale control sockets",
+	Long: `Ask every ControlMaster connection in ~/.krakncat/sockets to exit and remove
+sockets whose master is gone. Run this when multiplexed connections hang after
+a network change or sleep.
+
+Examples:
+  krakn agent reset`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := ensureSocketDir(); err != nil {
+			return err
+		}
+
+		cleaned, err := resetControlSockets()
+		if err != nil {
+			return err
+		}
+		if cleaned == 0 {
+			fmt.Println("✅ No control sockets to clean up")
+		} else {
+			fmt.Printf("✅ Cleaned %d control socket(s)\n", cleaned)
+		}
+		return nil
+	},
+}
+
+func init() {
+	multiplexCmd.Flags().String("persist", defaultControlPersist, "How long an idle master connection stays open")
+	registerDoctorCheck(doctorCheck{Name: "Connection multiplexing", Run: checkSocketDir})
+	agentCmd.AddCommand(agentResetCmd)
+	RootCmd.AddCommand(agentCmd)
+	RootCmd.AddCommand(multiplexCmd)
+}
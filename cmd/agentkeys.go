@@ -0,0 +1,510 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/term"
+)
+
+// agentKey is a key loaded in ssh-agent, as listed by 'ssh-add -L'
+type agentKey struct {
+	Line        string // authorized_keys line
+	Type        string
+	Fingerprint string
+	Comment     string
+	// File is the private key on disk holding the same key, "" when the key
+	// only exists in the agent (a hardware token, a password manager's agent)
+	File string
+	// PublicFile is a public key file on disk matching the key, if any
+	PublicFile string
+}
+
+// errNoAgent means no ssh-agent is reachable through SSH_AUTH_SOCK
+var errNoAgent = errors.New("no ssh-agent is running (SSH_AUTH_SOCK is not set or unreachable)")
+
+// listAgentKeys returns the keys loaded in ssh-agent, matched to key files in
+// ~/.ssh and to the keys of accounts
+func listAgentKeys(config *Config) ([]agentKey, error) {
+	keys, err := readAgentKeys()
+	if err != nil {
+		return nil, err
+	}
+	matchAgentKeyFiles(config, keys)
+	return keys, nil
+}
+
+// readAgentKeys returns the keys loaded in ssh-agent
+func readAgentKeys() ([]agentKey, error) {
+	output, err := traceExec(exec.Command("ssh-add", "-L")).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		// "The agent has no identities."
+		return nil, nil
+	}
+	if errors.As(err, &exitErr) {
+		return nil, errNoAgent
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot run ssh-add: %w", err)
+	}
+
+	var keys []agentKey
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+		if err != nil {
+			continue
+		}
+		keys = append(keys, agentKey{Line: line, Type: key.Type(), Fingerprint: ssh.FingerprintSHA256(key), Comment: comment})
+	}
+	return keys, nil
+}
+
+// matchAgentKeyFiles fills in the files on disk holding each agent key, looking
+// at the .pub files of ~/.ssh and of the accounts' keys
+func matchAgentKeyFiles(config *Config, keys []agentKey) {
+	homeDir, _ := os.UserHomeDir()
+	publicKeys, _ := filepath.Glob(filepath.Join(homeDir, ".ssh", "*.pub"))
+	for _, account := range config.Accounts {
+		if account.SSHKey != "" {
+			publicKeys = append(publicKeys, account.SSHKey+".pub")
+		}
+	}
+
+	for _, path := range publicKeys {
+		_, fingerprint, err := describePublicKey(path)
+		if err != nil {
+			continue
+		}
+		for i := range keys {
+			if keys[i].Fingerprint != fingerprint {
+				continue
+			}
+			if keys[i].PublicFile == "" {
+				keys[i].PublicFile = path
+			}
+			if private := strings.TrimSuffix(path, ".pub"); keys[i].File == "" && fileExists(private) {
+				keys[i].File = private
+			}
+		}
+	}
+}
+
+// accountForAgentKey returns the account using an agent key, if any
+func (c *Config) accountForAgentKey(key agentKey) *Account {
+	for i := range c.Accounts {
+		account := &c.Accounts[i]
+		if account.SSHKey == "" {
+			continue
+		}
+		if _, fingerprint, err := describePublicKey(account.SSHKey + ".pub"); err == nil && fingerprint == key.Fingerprint {
+			return account
+		}
+	}
+	return nil
+}
+
+var agentKeyNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// bindAgentKey returns the SSHKey of an account using an agent key and whether
+// the key only lives in the agent. For an agent-only key without a public key
+// file, the public key is written next to where a key of the account would be
+// generated, because ssh selects agent keys by their IdentityFile.
+func bindAgentKey(key agentKey, keyDir, suffix, name string) (string, bool, error) {
+	if key.File != "" {
+		return key.File, false, nil
+	}
+	if key.PublicFile != "" {
+		return strings.TrimSuffix(key.PublicFile, ".pub"), true, nil
+	}
+
+	keyPath := strings.Replace(defaultKeyPath(keyDir, suffix, agentKeyNameUnsafe.ReplaceAllString(name, "-")), "id_ed25519_", "agent_", 1)
+	if fileExists(keyPath + ".pub") {
+		return "", false, fmt.Errorf("❌ %s.pub already exists and holds another key", contractHomePath(keyPath))
+	}
+	if err := ensureSSHDirectory(); err != nil {
+		return "", false, err
+	}
+	trackFile(keyPath + ".pub")
+	if err := os.WriteFile(keyPath+".pub", []byte(key.Line+"\n"), 0644); err != nil {
+		return "", false, fmt.Errorf("failed to save the public key: %w", err)
+	}
+	fmt.Printf("📄 Saved the agent's public key to %s.pub\n", contractHomePath(keyPath))
+	return keyPath, true, nil
+}
+
+// identityFile is the IdentityFile of the account's Host block. Keys that
+// only live in ssh-agent are named by their public key, which makes ssh use
+// the agent's copy.
+func (a *Account) identityFile() string {
+	if a.AgentKey {
+		return a.SSHKey + ".pub"
+	}
+	return a.SSHKey
+}
+
+// agentSigner returns the agent's signer for the public key in pubPath
+func agentSigner(pubPath string) (ssh.Signer, error) {
+	data, err := os.ReadFile(pubPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+	want, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", contractHomePath(pubPath), err)
+	}
+
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return nil, errNoAgent
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, errNoAgent
+	}
+	signers, err := agent.NewClient(conn).Signers()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to list the agent's keys: %w", err)
+	}
+	for _, signer := range signers {
+		if bytes.Equal(signer.PublicKey().Marshal(), want.Marshal()) {
+			return signer, nil
+		}
+	}
+	conn.Close()
+	return nil, fmt.Errorf("the key %s is not loaded in ssh-agent; insert the token or unlock the agent", ssh.FingerprintSHA256(want))
+}
+
+// agentHasKey reports whether the key in pubPath is loaded in ssh-agent
+func agentHasKey(pubPath string) (bool, error) {
+	_, fingerprint, err := describePublicKey(pubPath)
+	if err != nil {
+		return false, err
+	}
+	keys, err := readAgentKeys()
+	if err != nil {
+		return false, err
+	}
+	for _, key := range keys {
+		if key.Fingerprint == fingerprint {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// addKeyToAgent decrypts a private key and adds it to ssh-agent
+func addKeyToAgent(keyPath, passphrase string) error {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return fmt.Errorf("failed to read SSH key: %w", err)
+	}
+	key, err := ssh.ParseRawPrivateKeyWithPassphrase(data, []byte(passphrase))
+	if err != nil {
+		return fmt.Errorf("cannot decrypt %s: %w", contractHomePath(keyPath), err)
+	}
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return errNoAgent
+	}
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return errNoAgent
+	}
+	defer conn.Close()
+	return agent.NewClient(conn).Add(agent.AddedKey{PrivateKey: key, Comment: keyPath})
+}
+
+// keyIsEncrypted reports whether a private key file needs a passphrase
+func keyIsEncrypted(keyPath string) bool {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return false
+	}
+	_, err = ssh.ParseRawPrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	return errors.As(err, &missing)
+}
+
+// agentRunning reports whether ssh-add reaches an agent; it exits with 1 for
+// an agent without keys and 2 when there is none
+func agentRunning() bool {
+	err := traceExec(exec.Command("ssh-add", "-l")).Run()
+	var exitErr *exec.ExitError
+	return err == nil || errors.As(err, &exitErr) && exitErr.ExitCode() == 1
+}
+
+// offerAgentAdd offers to load an account's passphrase-protected key into
+// ssh-agent, without which git asks for the passphrase on every fetch and
+// push, and non-interactive ssh fails. It reports whether the agent holds the
+// key afterwards.
+func offerAgentAdd(account *Account) bool {
+	if account.AgentKey || account.SSHKey == "" || !keyIsEncrypted(account.SSHKey) {
+		return false
+	}
+	if loaded, err := agentHasKey(account.SSHKey + ".pub"); err == nil && loaded {
+		return true
+	}
+	if passphrase, store, ok := storedKeyPassphrase(account.SSHKey); ok {
+		err := addKeyToAgent(account.SSHKey, passphrase)
+		if err == nil {
+			fmt.Printf("🔓 Loaded %s into ssh-agent with the passphrase from %s\n", contractHomePath(account.SSHKey), store)
+			return true
+		}
+		fmt.Printf("⚠️  Could not load %s with the passphrase from %s: %v\n", contractHomePath(account.SSHKey), store, err)
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) || structuredOutput() {
+		return false
+	}
+	if !agentRunning() {
+		fmt.Printf("💡 %s has a passphrase and no ssh-agent is running; start one with eval \"$(ssh-agent -s)\" and run: ssh-add %s\n", contractHomePath(account.SSHKey), contractHomePath(account.SSHKey))
+		return false
+	}
+
+	fmt.Printf("🔐 %s has a passphrase and is not loaded in ssh-agent\n", contractHomePath(account.SSHKey))
+	fmt.Print("💬 Add it to ssh-agent now? [Y/n]: ")
+	resp, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if resp = strings.ToLower(strings.TrimSpace(resp)); resp != "" && resp != "y" {
+		return false
+	}
+
+	// With a keychain the passphrase is read here, so it can be remembered
+	if store := systemSecretStore(); store != nil && os.Getenv("SSH_AUTH_SOCK") != "" {
+		passphrase, err := readSecret(fmt.Sprintf("🔐 Passphrase for %s: ", contractHomePath(account.SSHKey)))
+		if err != nil {
+			return false
+		}
+		if err := addKeyToAgent(account.SSHKey, passphrase); err == nil {
+			fmt.Printf("✅ Added %s to ssh-agent\n", contractHomePath(account.SSHKey))
+			offerRememberPassphrase(store, account.SSHKey, passphrase)
+			return true
+		} else if !errors.Is(err, errNoAgent) {
+			fmt.Printf("⚠️  %v\n", err)
+			return false
+		}
+	}
+
+	add := traceExec(exec.Command("ssh-add", account.SSHKey))
+	add.Stdin, add.Stdout, add.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := add.Run(); err != nil {
+		fmt.Printf("⚠️  ssh-add failed: %v\n", err)
+		return false
+	}
+	return true
+}
+
+// checkAgentKey verifies that the key of an account bound to ssh-agent is
+// loaded, since there is no private key file to check
+func checkAgentKey(account *Account) doctorFinding {
+	pubPath := account.SSHKey + ".pub"
+	loaded, err := agentHasKey(pubPath)
+	switch {
+	case err != nil && fileExists(pubPath):
+		return doctorFinding{
+			Level:   doctorWarn,
+			Message: fmt.Sprintf("Account '%s': agent key %s cannot be checked: %v", account.Name, contractHomePath(pubPath), err),
+			Hint:    "Start ssh-agent (or your token's agent) and load the key",
+		}
+	case err != nil:
+		return doctorFinding{
+			Level:   doctorError,
+			Message: fmt.Sprintf("Account '%s': public key %s of its agent key is missing", account.Name, contractHomePath(pubPath)),
+			Hint:    fmt.Sprintf("ssh-add -L > %s with the key loaded", contractHomePath(pubPath)),
+		}
+	case !loaded:
+		return doctorFinding{
+			Level:   doctorWarn,
+			Message: fmt.Sprintf("Account '%s': agent key %s is not loaded in ssh-agent", account.Name, contractHomePath(pubPath)),
+			Hint:    "Insert the hardware token or add the key with ssh-add",
+		}
+	}
+	return doctorFinding{
+		Level:   doctorOK,
+		Message: fmt.Sprintf("Account '%s': SSH key %s, loaded in ssh-agent", account.Name, contractHomePath(pubPath)),
+	}
+}
+
+// discoverAgentAccounts offers the agent keys no account uses yet to
+// 'krakn migrate'
+func discoverAgentAccounts() []DiscoveredAccount {
+	config, err := loadConfig()
+	if err != nil {
+		return nil
+	}
+	keys, err := listAgentKeys(config)
+	if err != nil {
+		return nil
+	}
+
+	var accounts []DiscoveredAccount
+	for _, key := range keys {
+		where := "agent only"
+		if key.File != "" {
+			where = contractHomePath(key.File)
+		}
+		agentKey := key
+		discovered := DiscoveredAccount{
+			Source: fmt.Sprintf("ssh-agent (%s %s, %s)", key.Type, key.Fingerprint, where),
+			SSHKey: key.File,
+			Agent:  &agentKey,
+		}
+		if discovered.SSHKey == "" && key.PublicFile != "" {
+			// Matches an account bound to the key by its public key
+			discovered.SSHKey = strings.TrimSuffix(key.PublicFile, ".pub")
+		}
+		accounts = append(accounts, discovered)
+	}
+	return accounts
+}
+
+// selectAgentKey finds a key by its number in the listing or its fingerprint
+func selectAgentKey(keys []agentKey, choice string) (agentKey, error) {
+	if index, err := strconv.Atoi(choice); err == nil {
+		if index < 1 || index > len(keys) {
+			return agentKey{}, fmt.Errorf("❌ No key %d; the agent holds %d key(s)", index, len(keys))
+		}
+		return keys[index-1], nil
+	}
+	for _, key := range keys {
+		if key.Fingerprint == choice || key.Fingerprint == "SHA256:"+choice {
+			return key, nil
+		}
+	}
+	return agentKey{}, fmt.Errorf("❌ No key with fingerprint %s in ssh-agent", choice)
+}
+
+var keyAgentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "List the keys in ssh-agent and create accounts bound to them",
+	Long: `List the keys loaded in ssh-agent ('ssh-add -L'), the file on disk each one
+comes from, and the account using it. Keys without a private key file, such as
+those of hardware tokens (YubiKey, ssh-agent of a password manager), are
+marked agent only.
+
+--create adds an account bound to a listed key. For an agent-only key its
+public key is saved in ~/.ssh and the account's Host block names it as the
+IdentityFile, so ssh signs with the agent's copy. 'krakn doctor' then checks
+that the key is loaded rather than that a private key file exists.
+
+Examples:
+  krakn key agent
+  krakn key agent --create work --key 2
+  krakn key agent --create yubi --key SHA256:1Fq... --email me@company.com --username me`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("create")
+		choice, _ := cmd.Flags().GetString("key")
+		email, _ := cmd.Flags().GetString("email")
+		username, _ := cmd.Flags().GetString("username")
+		hostname, _ := cmd.Flags().GetString("hostname")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		keys, err := listAgentKeys(config)
+		if err != nil {
+			return fmt.Errorf("❌ %v", err)
+		}
+		if len(keys) == 0 {
+			fmt.Println("📭 ssh-agent holds no keys; add one with ssh-add")
+			return nil
+		}
+
+		if name == "" {
+			fmt.Println("🔑 Keys in ssh-agent:")
+			for i, key := range keys {
+				fmt.Printf("\n  %d. %s %s %s\n", i+1, key.Type, key.Fingerprint, key.Comment)
+				if key.File != "" {
+					fmt.Printf("     📄 %s\n", contractHomePath(key.File))
+				} else {
+					fmt.Println("     🔒 Agent only: no private key file on disk")
+				}
+				if account := config.accountForAgentKey(key); account != nil {
+					fmt.Printf("     👤 Used by '%s'\n", account.Name)
+				}
+			}
+			fmt.Println("\n💡 Create an account for a key with: krakn key agent --create <name> --key <number>")
+			return nil
+		}
+
+		if config.getAccount(name) != nil {
+			return fmt.Errorf("❌ Account '%s' already exists", name)
+		}
+		if choice == "" {
+			if len(keys) > 1 {
+				return fmt.Errorf("❌ ssh-agent holds %d keys; choose one with --key <number|fingerprint>", len(keys))
+			}
+			choice = "1"
+		}
+		key, err := selectAgentKey(keys, choice)
+		if err != nil {
+			return err
+		}
+		if account := config.accountForAgentKey(key); account != nil {
+			return fmt.Errorf("❌ Key %s is already used by '%s'", key.Fingerprint, account.Name)
+		}
+
+		provider := providerForHostname(hostname)
+		reader := bufio.NewReader(os.Stdin)
+		if email == "" {
+			fmt.Print("📧 Email address: ")
+			input, _ := reader.ReadString('\n')
+			email = strings.TrimSpace(input)
+		}
+		if username == "" {
+			fmt.Printf("👤 %s username: ", provider.DisplayName)
+			input, _ := reader.ReadString('\n')
+			username = strings.TrimSpace(input)
+		}
+
+		sshKey, agentOnly, err := bindAgentKey(key, "", provider.KeySuffix, name)
+		if err != nil {
+			return err
+		}
+		account := Account{Name: name, Email: email, Username: username, SSHKey: sshKey, AgentKey: agentOnly}
+		if provider.Hostname != DefaultProviders["github"].Hostname {
+			account.Provider = &provider
+		}
+		if err := config.addAccount(account); err != nil {
+			return fmt.Errorf("failed to add account: %w", err)
+		}
+
+		if err := upsertSSHHostBlock(account.GetSSHHost(), account.GenerateSSHConfig()); err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Account '%s' added with %s key %s\n", name, key.Type, key.Fingerprint)
+		if agentOnly {
+			fmt.Println("🔒 The private key stays in ssh-agent; keep it loaded (or the token inserted) to push")
+		}
+		fmt.Printf("🔗 SSH Host: %s\n", account.GetSSHHost())
+		return nil
+	},
+}
+
+func init() {
+	keyAgentCmd.Flags().String("create", "", "Create an account with this name bound to an agent key")
+	keyAgentCmd.Flags().String("key", "", "Key to bind: its number in the listing or its SHA256 fingerprint")
+	keyAgentCmd.Flags().String("email", "", "Email of the new account")
+	keyAgentCmd.Flags().String("username", "", "Provider username of the new account")
+	keyAgentCmd.Flags().String("hostname", "github.com", "Git hosting hostname of the new account")
+	keyCmd.AddCommand(keyAgentCmd)
+}
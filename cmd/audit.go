@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Defaults for the audit log
+const (
+	defaultAuditMaxSize = 10 << 20 // Rotate after 10 MiB
+	defaultAuditKeep    = 5        // Rotated files kept next to the log
+)
+
+// AuditConfig enables the append-only audit log of mutating operations
+type AuditConfig struct {
+	Path    string `json:"path,omitempty"`     // Log file; default ~/.krakncat/audit.jsonl
+	MaxSize int64  `json:"max_size,omitempty"` // Size in bytes that triggers rotation
+	Keep    int    `json:"keep,omitempty"`     // Number of rotated files kept
+}
+
+// auditRecord is one line of the audit log
+type auditRecord struct {
+	Time      time.Time   `json:"time"`
+	Operation string      `json:"operation"` // Id in 'krakn log'
+	Command   string      `json:"command"`
+	User      string      `json:"user"`
+	Host      string      `json:"host"`
+	PID       int         `json:"pid"`
+	Files     []auditFile `json:"files"`
+}
+
+type auditFile struct {
+	Path       string `json:"path"`
+	Action     string `json:"action"`
+	BeforeHash string `json:"before_sha256,omitempty"`
+	AfterHash  string `json:"after_sha256,omitempty"`
+}
+
+// auditOverride keeps logging the running command when it turns the audit
+// log off, so disabling it is itself on record
+var auditOverride *AuditConfig
+
+// auditSettings returns the effective audit settings, or nil when auditing is
+// off. KRAKN_AUDIT_LOG enables it machine-wide, e.g. from a managed profile,
+// and cannot be turned off with 'krakn audit-log off'.
+func auditSettings() *AuditConfig {
+	settings := auditOverride
+	if settings == nil {
+		if config, err := loadConfig(); err == nil && config.Audit != nil {
+			settings = config.Audit
+		}
+	}
+	if path := os.Getenv("KRAKN_AUDIT_LOG"); path != "" {
+		forced := AuditConfig{Path: expandUserPath(path)}
+		if settings != nil {
+			forced.MaxSize, forced.Keep = settings.MaxSize, settings.Keep
+		}
+		settings = &forced
+	}
+	if settings == nil {
+		return nil
+	}
+
+	effective := *settings
+	if effective.Path == "" {
+		homeDir, _ := os.UserHomeDir()
+		effective.Path = filepath.Join(homeDir, ".krakncat", "audit.jsonl")
+	}
+	if effective.MaxSize <= 0 {
+		effective.MaxSize = defaultAuditMaxSize
+	}
+	if effective.Keep <= 0 {
+		effective.Keep = defaultAuditKeep
+	}
+	return &effective
+}
+
+// writeAuditRecord appends an operation to the audit log, rotating it first
+// when it has grown past the size limit
+func writeAuditRecord(settings *AuditConfig, op Operation) error {
+	record := auditRecord{
+		Time:      op.Time,
+		Operation: op.ID,
+		Command:   op.Command,
+		User:      os.Getenv("USER"),
+		PID:       os.Getpid(),
+	}
+	if u, err := user.Current(); err == nil {
+		record.User = u.Username
+	}
+	record.Host, _ = os.Hostname()
+	for _, change := range op.Files {
+		record.Files = append(record.Files, auditFile{
+			Path:       change.Path,
+			Action:     change.action(),
+			BeforeHash: change.BeforeHash,
+			AfterHash:  change.AfterHash,
+		})
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(settings.Path), 0700); err != nil {
+		return err
+	}
+	return withFileLock(settings.Path, func() error {
+		if info, err := os.Stat(settings.Path); err == nil && info.Size()+int64(len(data)) > settings.MaxSize {
+			rotateAuditLog(settings.Path, settings.Keep)
+		}
+		f, err := os.OpenFile(settings.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = f.Write(append(data, '\n'))
+		return err
+	})
+}
+
+// rotateAuditLog shifts log → log.1 → log.2 ..., dropping the oldest
+func rotateAuditLog(path string, keep int) {
+	os.Remove(fmt.Sprintf("%s.%d", path, keep))
+	for i := keep - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+	}
+	os.Rename(path, path+".1")
+}
+
+var auditLogCmd = &cobra.Command{
+	Use:   "audit-log",
+	Short: "Record every configuration change in an append-only audit log",
+	Long: `Keep an append-only audit log of every operation that changes git identity
+configuration: one JSON line per operation with the time, user, host, command
+and the SHA-256 of each touched file before and after the change. It is meant
+for workstations that must show who changed what and when; 'krakn log' and
+'krakn revert' keep working as before.
+
+The log rotates at --max-size, keeping --keep older files (log.1, log.2, ...).
+Setting KRAKN_AUDIT_LOG=<path> in the environment, e.g. from a managed shell
+profile, turns the log on for every user regardless of 'krakn audit-log off'.
+
+Examples:
+  krakn audit-log                          # Show the settings and recent records
+  krakn audit-log on
+  krakn audit-log on --path /var/log/krakn/$USER.jsonl --max-size 50 --keep 10
+  krakn audit-log off`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		settings := auditSettings()
+		if settings == nil {
+			fmt.Println("📴 The audit log is off. Turn it on with 'krakn audit-log on'.")
+			return nil
+		}
+		fmt.Printf("📝 Audit log: %s (rotates at %d MiB, keeps %d)\n", contractHomePath(settings.Path), settings.MaxSize>>20, settings.Keep)
+		if os.Getenv("KRAKN_AUDIT_LOG") != "" {
+			fmt.Println("🏢 Enabled by KRAKN_AUDIT_LOG")
+		}
+
+		data, err := os.ReadFile(settings.Path)
+		if err != nil {
+			fmt.Println("📭 No records yet")
+			return nil
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(lines) > 10 {
+			lines = lines[len(lines)-10:]
+		}
+		fmt.Println("\n🕘 Recent records:")
+		for _, line := range lines {
+			var record auditRecord
+			if json.Unmarshal([]byte(line), &record) != nil {
+				continue
+			}
+			fmt.Printf("   %s  %s@%s  %s (%d files)\n", record.Time.Local().Format("2006-01-02 15:04:05"), record.User, record.Host, record.Command, len(record.Files))
+		}
+		return nil
+	},
+}
+
+var auditLogOnCmd = &cobra.Command{
+	Use:   "on",
+	Short: "Turn the audit log on",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("path")
+		maxSize, _ := cmd.Flags().GetInt64("max-size")
+		keep, _ := cmd.Flags().GetInt("keep")
+		if maxSize < 1 || keep < 1 {
+			return fmt.Errorf("❌ --max-size and --keep must be at least 1")
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if path != "" {
+			if path, err = filepath.Abs(expandUserPath(path)); err != nil {
+				return err
+			}
+		}
+		if err := config.update(func(config *Config) error {
+			config.Audit = &AuditConfig{Path: path, MaxSize: maxSize << 20, Keep: keep}
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		settings := auditSettings()
+		fmt.Printf("📝 Recording changes in %s\n", contractHomePath(settings.Path))
+		return nil
+	},
+}
+
+var auditLogOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Turn the audit log off",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if config.Audit == nil {
+			fmt.Println("📴 The audit log is already off")
+			return nil
+		}
+		auditOverride = config.Audit
+		if err := config.update(func(config *Config) error {
+			config.Audit = nil
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Println("📴 The audit log is off; existing records are kept")
+		if os.Getenv("KRAKN_AUDIT_LOG") != "" {
+			fmt.Println("🏢 KRAKN_AUDIT_LOG is set, so changes are still recorded there")
+		}
+		return nil
+	},
+}
+
+func init() {
+	auditLogOnCmd.Flags().String("path", "", "Log file (default: ~/.krakncat/audit.jsonl)")
+	auditLogOnCmd.Flags().Int64("max-size", defaultAuditMaxSize>>20, "Size in MiB that triggers rotation")
+	auditLogOnCmd.Flags().Int("keep", defaultAuditKeep, "Number of rotated files to keep")
+	auditLogCmd.AddCommand(auditLogOnCmd)
+	auditLogCmd.AddCommand(auditLogOffCmd)
+	RootCmd.AddCommand(auditLogCmd)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// authResult is the outcome of the last 'krakn test' of an account, cached so
+// quick views such as 'krakn list --check' can show it without connecting
+type authResult struct {
+	OK       bool      `json:"ok"`
+	Username string    `json:"username,omitempty"` // Username the provider greeted the key with
+	Error    string    `json:"error,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+func getAuthCachePath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".krakncat", "auth-cache.json")
+}
+
+// loadAuthCache returns the cached results by account name
+func loadAuthCache() map[string]authResult {
+	cache := map[string]authResult{}
+	if data, err := os.ReadFile(getAuthCachePath()); err == nil {
+		json.Unmarshal(data, &cache)
+	}
+	return cache
+}
+
+// saveAuthResults merges results into the cache
+func saveAuthResults(results map[string]authResult) error {
+	if err := ensureConfigDir(); err != nil {
+		return err
+	}
+	path := getAuthCachePath()
+	return withFileLock(path, func() error {
+		cache := loadAuthCache()
+		for name, result := range results {
+			cache[name] = result
+		}
+		data, err := json.MarshalIndent(cache, "", "  ")
+		if err != nil {
+			return err
+		}
+		return writeFileAtomic(path, data, 0600)
+	})
+}
+
+// formatAge renders how long ago t was, e.g. "5m ago" or "2d ago"
+func formatAge(t time.Time) string {
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	}
+	return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+}
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// AuthRefreshConfig lets 'krakn watch' refresh the cached 'krakn test'
+// results in the background
+type AuthRefreshConfig struct {
+	Interval string `json:"interval"` // Target age of a cached result, e.g. "6h"
+}
+
+// defaultAuthRefreshInterval is how old a cached result gets before it is
+// refreshed, unless configured otherwise
+const defaultAuthRefreshInterval = 6 * time.Hour
+
+// minAuthRefreshInterval keeps the daemon from hammering providers
+const minAuthRefreshInterval = 15 * time.Minute
+
+// authRefreshBatch is the most accounts probed per wake-up, so a long account
+// list is spread over several wake-ups instead of connecting in a burst
+const authRefreshBatch = 2
+
+// authRefreshRetry is how long an account waits after its provider could not
+// be reached, or after it was skipped because its key needs a passphrase
+const authRefreshRetry = 15 * time.Minute
+
+// authRefreshRunning is set while a refresh runs in the background; guarded
+// by daemonMu
+var authRefreshRunning bool
+
+// interval returns the configured interval, or the default for an unset or
+// invalid one
+func (r *AuthRefreshConfig) interval() time.Duration {
+	interval, err := time.ParseDuration(r.Interval)
+	if err != nil || interval < minAuthRefreshInterval {
+		return defaultAuthRefreshInterval
+	}
+	return interval
+}
+
+// jitter spreads d by ±10%, so accounts added together, and machines sharing
+// a config, do not all connect at the same moment
+func jitter(d time.Duration) time.Duration {
+	return d - d/10 + time.Duration(rand.Int63n(int64(d/5)+1))
+}
+
+// networkUp reports whether an interface other than loopback has an address
+// beyond link-local, i.e. whether connecting is worth trying at all
+func networkUp() bool {
+	interfaces, _ := net.Interfaces()
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, _ := iface.Addrs()
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.IsGlobalUnicast() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// refreshesUnattended reports whether an account's key can sign without a
+// prompt: it is unencrypted, in the agent, or has a stored passphrase
+func refreshesUnattended(account *Account) bool {
+	if account.AgentKey || !keyIsEncrypted(account.SSHKey) {
+		return true
+	}
+	if loaded, _ := agentHasKey(account.SSHKey + ".pub"); loaded {
+		return true
+	}
+	_, _, ok := storedKeyPassphrase(account.SSHKey)
+	return ok
+}
+
+// runAuthRefresh picks the SSH accounts whose cached result is older than the
+// interval and probes them in the background, outside daemonMu. Only answers
+// from the provider are cached; when it cannot be reached the old result
+// stays and the account is retried later.
+func runAuthRefresh(ctx *watchContext) error {
+	if ctx.Config.AuthRefresh == nil || offlineMode || authRefreshRunning {
+		return nil
+	}
+	interval := ctx.Config.AuthRefresh.interval()
+	if ctx.State.NextVerify == nil {
+		ctx.State.NextVerify = map[string]time.Time{}
+	}
+
+	cache := loadAuthCache()
+	var due []Account
+	for _, account := range ctx.Config.Accounts {
+		if account.usesHTTPS() || account.SSHKey == "" || ctx.Now.Before(ctx.State.NextVerify[account.Name]) {
+			continue
+		}
+		// A manual 'krakn test' counts as a refresh
+		if cached, found := cache[account.Name]; found && ctx.Now.Sub(cached.Time) < interval-interval/10 {
+			continue
+		}
+		if !refreshesUnattended(&account) {
+			ctx.State.NextVerify[account.Name] = ctx.Now.Add(authRefreshRetry)
+			continue
+		}
+		due = append(due, account)
+		if len(due) == authRefreshBatch {
+			break
+		}
+	}
+	if len(due) == 0 || !networkUp() {
+		return nil
+	}
+
+	config, state := ctx.Config, ctx.State
+	authRefreshRunning = true
+	watchBackground.Add(1)
+	go func() {
+		defer watchBackground.Done()
+		results := map[string]authResult{}
+		next := map[string]time.Time{}
+		for i := range due {
+			account := &due[i]
+			result, err := nativeSSHProbe(account, false)
+			now := time.Now()
+			if err != nil && !errors.Is(err, errKeyRejected) {
+				fmt.Printf("⚠️  %s Could not refresh '%s': %v\n", now.Format("15:04"), account.Name, err)
+				next[account.Name] = now.Add(authRefreshRetry)
+				continue
+			}
+			next[account.Name] = now.Add(jitter(interval))
+
+			if err == nil {
+				confirmKeyOwner(config, account, result)
+				results[account.Name] = authResult{OK: true, Username: result.Username, Time: now}
+				if account.Username != "" && result.Username != "" && !strings.EqualFold(result.Username, account.Username) {
+					results[account.Name] = authResult{Username: result.Username, Error: "authenticated as " + result.Username, Time: now}
+				}
+			} else {
+				results[account.Name] = authResult{Error: err.Error(), Time: now}
+			}
+
+			refreshed := results[account.Name]
+			if refreshed.OK {
+				fmt.Printf("🔑 %s Verified '%s'\n", now.Format("15:04"), account.Name)
+				continue
+			}
+			fmt.Printf("❌ %s '%s' failed verification: %s\n", now.Format("15:04"), account.Name, refreshed.Error)
+			// An account that was already failing is not reported again
+			if previous, found := cache[account.Name]; !found || previous.OK {
+				if err := config.notify(doctorError, "krakn: "+account.Name, "Verification failed: "+refreshed.Error); err != nil {
+					fmt.Printf("⚠️  Could not show notification: %v\n", err)
+				}
+			}
+		}
+		if err := saveAuthResults(results); err != nil {
+			fmt.Printf("⚠️  Could not cache the results: %v\n", err)
+		}
+
+		daemonMu.Lock()
+		defer daemonMu.Unlock()
+		authRefreshRunning = false
+		for name, t := range next {
+			state.NextVerify[name] = t
+		}
+		if err := state.save(); err != nil {
+			fmt.Printf("⚠️  Could not save watch state: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+var authRefreshCmd = &cobra.Command{
+	Use:   "auth-refresh",
+	Short: "Keep the cached 'krakn test' results fresh from 'krakn watch'",
+	Long: `Let 'krakn watch' refresh the cached result of 'krakn test' for every SSH
+account, so 'krakn list --check' shows a recent verification without
+connecting to anything itself.
+
+A result is refreshed once it is older than the interval, give or take 10% so
+accounts do not all connect at once, and at most two accounts are probed per
+wake-up. The probes run in the background and never hold up the daemon's
+socket requests. Nothing is attempted offline (--offline, or no network
+interface with an address); when the provider cannot be reached the previous
+result is kept and retried 15 minutes later. Keys that need a passphrase are
+skipped unless they are in the agent or the passphrase is stored.
+
+A key that stops working raises a notification (see 'krakn notify').
+
+Examples:
+  krakn auth-refresh                  # Show the setting and the age of each result
+  krakn auth-refresh on               # Every 6 hours
+  krakn auth-refresh on --interval 1h
+  krakn auth-refresh off`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if config.AuthRefresh == nil {
+			fmt.Println("⏸️  Background verification is off. Turn it on with 'krakn auth-refresh on'.")
+			return nil
+		}
+		fmt.Printf("🔄 'krakn watch' refreshes verification every %s\n", config.AuthRefresh.interval())
+		cache := loadAuthCache()
+		state := loadWatchState()
+		for _, account := range config.Accounts {
+			if account.usesHTTPS() || account.SSHKey == "" {
+				continue
+			}
+			line := "not tested yet"
+			if result, found := cache[account.Name]; found {
+				line = "tested " + formatAge(result.Time)
+			}
+			if next, found := state.NextVerify[account.Name]; found && next.After(time.Now()) {
+				line += ", next around " + next.Format("Jan 2 15:04")
+			}
+			fmt.Printf("   %s: %s\n", account.Name, line)
+		}
+		return nil
+	},
+}
+
+var authRefreshOnCmd = &cobra.Command{
+	Use:   "on",
+	Short: "Refresh verification in the background",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		if interval < minAuthRefreshInterval {
+			return fmt.Errorf("❌ --interval must be at least %s", minAuthRefreshInterval)
+		}
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if err := config.update(func(config *Config) error {
+			config.AuthRefresh = &AuthRefreshConfig{Interval: interval.String()}
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("🔄 'krakn watch' refreshes verification every %s\n", interval)
+		fmt.Println("💡 Keep the daemon running with 'krakn service install'")
+		return nil
+	},
+}
+
+var authRefreshOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Stop refreshing verification in the background",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if err := config.update(func(config *Config) error {
+			config.AuthRefresh = nil
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Println("⏸️  Background verification is off; 'krakn test' still refreshes the results")
+		return nil
+	},
+}
+
+func init() {
+	authRefreshOnCmd.Flags().Duration("interval", defaultAuthRefreshInterval, "How old a cached result gets before it is refreshed")
+	authRefreshCmd.AddCommand(authRefreshOnCmd)
+	authRefreshCmd.AddCommand(authRefreshOffCmd)
+	RootCmd.AddCommand(authRefreshCmd)
+	registerWatchTask(watchTask{Name: "Verification", Run: runAuthRefresh})
+}
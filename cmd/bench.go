@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// sshOver443Hosts lists providers that accept SSH on port 443, for networks
+// that block or throttle port 22
+var sshOver443Hosts = map[string]string{
+	"github.com": "ssh.github.com",
+	"gitlab.com": "altssh.gitlab.com",
+}
+
+// benchResult holds the timings measured for one account
+type benchResult struct {
+	Account   string
+	Host      string
+	TCP       time.Duration // Plain TCP connect to the SSH port
+	TCP443    time.Duration // TCP connect to the port 443 fallback, if the provider has one
+	Handshake time.Duration // Median of the full ssh handshake and authentication
+	Fetch     time.Duration // git ls-remote against --repo, if given
+	Err       string
+}
+
+// timeTCPConnect measures how long it takes to open a TCP connection
+func timeTCPConnect(address string) (time.Duration, error) {
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", address, 10*time.Second)
+	if err != nil {
+		return 0, err
+	}
+	elapsed := time.Since(start)
+	conn.Close()
+	return elapsed, nil
+}
+
+// timeSSHHandshake runs 'ssh -T' against the alias and returns the elapsed time.
+// Providers exit non-zero after authenticating, so only a connection failure
+// (exit status 255) counts as an error.
+func timeSSHHandshake(alias string) (time.Duration, error) {
+	start := time.Now()
+	output, err := traceExec(exec.Command("ssh", "-T", "-o", "BatchMode=yes", "-o", "ConnectTimeout=10", alias)).CombinedOutput()
+	elapsed := time.Since(start)
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 255 {
+		return elapsed, fmt.Errorf("%s", firstLine(string(output)))
+	} else if err != nil && !ok {
+		return elapsed, err
+	}
+	return elapsed, nil
+}
+
+// firstLine returns the first non-empty line of command output
+func firstLine(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return "connection failed"
+}
+
+// medianDuration returns the median of the measured durations
+func medianDuration(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[len(sorted)/2]
+}
+
+func formatLatency(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
+
+// benchAccount measures connection, handshake and fetch latency for an account
+func benchAccount(account *Account, runs int, repo string) benchResult {
+	provider := account.GetProvider()
+	result := benchResult{Account: account.Name, Host: account.GetSSHHost()}
+
+	port := provider.SSHPort
+	if port == "" {
+		port = "22"
+	}
+	if elapsed, err := timeTCPConnect(net.JoinHostPort(provider.Hostname, port)); err == nil {
+		result.TCP = elapsed
+	}
+	if fallback, ok := sshOver443Hosts[provider.Hostname]; ok {
+		if elapsed, err := timeTCPConnect(net.JoinHostPort(fallback, "443")); err == nil {
+			result.TCP443 = elapsed
+		}
+	}
+
+	var handshakes []time.Duration
+	for i := 0; i < runs; i++ {
+		elapsed, err := timeSSHHandshake(result.Host)
+		if err != nil {
+			result.Err = err.Error()
+			return result
+		}
+		handshakes = append(handshakes, elapsed)
+	}
+	result.Handshake = medianDuration(handshakes)
+
+	if repo != "" {
+		start := time.Now()
+		url := fmt.Sprintf("git@%s:%s", result.Host, repo)
+		if err := traceExec(exec.Command("git", "ls-remote", "--exit-code", url, "HEAD")).Run(); err != nil {
+			result.Err = fmt.Sprintf("git ls-remote %s failed", url)
+		} else {
+			result.Fetch = time.Since(start)
+		}
+	}
+
+	return result
+}
+
+var benchCmd = &cobra.Command{
+	Use:   "bench [account-name...]",
+	Short: "Measure SSH connection and authentication latency per account",
+	Long: `Time the TCP connection, SSH handshake and (optionally) a no-op fetch for each
+account, and suggest multiplexing or the port 443 fallback where they would help.
+
+Useful when git is slow for only one of your accounts.
+
+Examples:
+  krakn bench                          # Benchmark all accounts
+  krakn bench work --runs 5            # Five handshakes for the work account
+  krakn bench --repo org/repo.git      # Also time git ls-remote against org/repo`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runs, _ := cmd.Flags().GetInt("runs")
+		repo, _ := cmd.Flags().GetString("repo")
+		if runs < 1 {
+			return fmt.Errorf("❌ --runs must be at least 1")
+		}
+
+		if _, err := exec.LookPath("ssh"); err != nil {
+			return fmt.Errorf("❌ ssh not found in PATH")
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		var accounts []*Account
+		if len(args) == 0 {
+			for i := range config.Accounts {
+				accounts = append(accounts, &config.Accounts[i])
+			}
+		} else {
+			for _, name := range args {
+				account := config.getAccount(name)
+				if account == nil {
+					return config.accountNotFound(name)
+				}
+				accounts = append(accounts, account)
+			}
+		}
+		if len(accounts) == 0 {
+			fmt.Println("📭 No accounts configured. Use 'krakn add' to add an account.")
+			return nil
+		}
+
+		fmt.Printf("⏱️  Benchmarking %d account(s), %d handshake(s) each...\n\n", len(accounts), runs)
+
+		var results []benchResult
+		for _, account := range accounts {
+			spin := startSpinner(fmt.Sprintf("Benchmarking %s", account.Name))
+			results = append(results, benchAccount(account, runs, repo))
+			spin.Stop()
+		}
+
+		fmt.Printf("   %-16s %-30s %-8s %-8s %-10s %s\n", "ACCOUNT", "HOST", "TCP", "TCP:443", "HANDSHAKE", "FETCH")
+		for _, result := range results {
+			fmt.Printf("   %-16s %-30s %-8s %-8s %-10s %s\n", result.Account, result.Host,
+				formatLatency(result.TCP), formatLatency(result.TCP443), formatLatency(result.Handshake), formatLatency(result.Fetch))
+		}
+		fmt.Println()
+
+		// Suggestions
+		for i, result := range results {
+			account := accounts[i]
+			blocked := result.TCP == 0 && result.TCP443 != 0
+			if blocked {
+				fmt.Printf("💡 %s: port 22 is unreachable but %s:443 works; add 'HostName %s' and 'Port 443' to the Host block\n",
+					result.Account, sshOver443Hosts[account.GetProvider().Hostname], sshOver443Hosts[account.GetProvider().Hostname])
+			}
+			// A blocked port 22 fails the handshake too, so the hint above
+			// comes before giving up on the account
+			if result.Err != "" {
+				fmt.Printf("❌ %s: %s\n", result.Account, result.Err)
+				continue
+			}
+			if !blocked && result.TCP443 != 0 && result.TCP > 2*result.TCP443 {
+				fmt.Printf("💡 %s: port 443 connects %s faster than port 22; consider the 443 fallback\n",
+					result.Account, formatLatency(result.TCP-result.TCP443))
+			}
+			if !account.Multiplex && account.SSHHost == "" && result.Handshake > 500*time.Millisecond {
+				fmt.Printf("💡 %s: handshakes take %s; 'krakn multiplex %s on' reuses one connection\n",
+					result.Account, formatLatency(result.Handshake), result.Account)
+			}
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	benchCmd.Flags().Int("runs", 3, "Number of SSH handshakes per account")
+	benchCmd.Flags().String("repo", "", "Repository path (e.g. org/repo.git) to time a no-op fetch against")
+	RootCmd.AddCommand(benchCmd)
+}
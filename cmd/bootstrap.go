@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// shellQuote quotes a value for a POSIX shell
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// tokenEnvName is the environment variable a bootstrap script reads an
+// account's token from, e.g. KRAKN_TOKEN_WORK
+func tokenEnvName(account *Account) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, account.Name)
+	return "KRAKN_TOKEN_" + name
+}
+
+// bootstrapScript renders a setup script for a remote dev box. It contains
+// public keys and identities only: with agent forwarding the account's public
+// key selects the matching forwarded key, with tokens the credential helper
+// reads the token from the environment (e.g. a Codespaces secret).
+func bootstrapScript(accounts []*Account, primary *Account, auth string) string {
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(&b, format+"\n", args...)
+	}
+
+	line("#!/bin/sh")
+	line("# Generated by 'krakn remote-bootstrap' on %s.", time.Now().Format("2006-01-02"))
+	line("# Contains no private keys or tokens; authentication uses %s.", map[string]string{
+		"agent": "the forwarded SSH agent",
+		"token": "tokens from environment variables",
+	}[auth])
+	line("set -e")
+	line("")
+	line("git config --global user.name %s", shellQuote(primary.CommitName()))
+	line("git config --global user.email %s", shellQuote(primary.Email))
+	line("mkdir -p \"$HOME/.config/krakn\"")
+
+	if auth == "agent" {
+		line("mkdir -p \"$HOME/.ssh\" && chmod 700 \"$HOME/.ssh\"")
+		line("touch \"$HOME/.ssh/config\"")
+	}
+
+	for _, account := range accounts {
+		provider := account.GetProvider()
+		line("")
+		line("# Account '%s' (%s)", account.Name, provider.DisplayName)
+
+		includePath := fmt.Sprintf("$HOME/.config/krakn/%s.gitconfig", account.Name)
+		line("cat > \"%s\" <<'KRAKN_EOF'", includePath)
+		line("[user]")
+		line("\tname = %s", formatGitConfigValue(account.CommitName()))
+		line("\temail = %s", formatGitConfigValue(account.Email))
+		line("KRAKN_EOF")
+
+		var sshPrefix string
+		switch auth {
+		case "agent":
+			publicKey, err := os.ReadFile(account.SSHKey + ".pub")
+			if err != nil {
+				line("# ⚠️  No public key at %s; pushes fall back to the agent's first key", contractHomePath(account.SSHKey)+".pub")
+				break
+			}
+			// ssh offers the forwarded key whose public half IdentityFile names
+			keyPath := fmt.Sprintf("$HOME/.ssh/krakn_%s.pub", account.Name)
+			alias := account.GetSSHHost()
+			line("printf '%%s\\n' %s > \"%s\"", shellQuote(strings.TrimSpace(string(publicKey))), keyPath)
+			line("if ! grep -q %s \"$HOME/.ssh/config\"; then", shellQuote("^Host "+alias+"$"))
+			line("  cat >> \"$HOME/.ssh/config\" <<KRAKN_EOF")
+			line("")
+			line("Host %s", alias)
+			line("  HostName %s", provider.Hostname)
+			line("  User %s", provider.SSHUser)
+			if provider.SSHPort != "" && provider.SSHPort != "22" {
+				line("  Port %s", provider.SSHPort)
+			}
+			line("  IdentityFile %s", keyPath)
+			line("  IdentitiesOnly yes")
+			line("KRAKN_EOF")
+			line("fi")
+			sshPrefix = fmt.Sprintf("%s@%s:", provider.SSHUser, alias)
+		case "token":
+			variable := tokenEnvName(account)
+			line("git config --global credential.https://%s.useHttpPath true", provider.Hostname)
+			line("git config --global --replace-all credential.https://%s/%s.helper %s", provider.Hostname, account.Username,
+				shellQuote(fmt.Sprintf(`!f() { echo username=%s; echo "password=$%s"; }; f`, account.Username, variable)))
+		}
+
+		for _, owner := range append([]string{account.Username}, account.Orgs...) {
+			if owner == "" {
+				continue
+			}
+			for _, url := range []string{
+				fmt.Sprintf("git@%s:%s/", provider.Hostname, owner),
+				fmt.Sprintf("https://%s/%s/", provider.Hostname, owner),
+			} {
+				line("git config --global %s %s", shellQuote("includeIf.hasconfig:remote.*.url:"+url+"**.path"), "\""+includePath+"\"")
+			}
+			switch {
+			case auth == "agent" && sshPrefix != "":
+				line("git config --global --replace-all %s %s", shellQuote("url."+sshPrefix+owner+"/.insteadOf"),
+					shellQuote(fmt.Sprintf("git@%s:%s/", provider.Hostname, owner)))
+			case auth == "token" && owner != account.Username:
+				line("git config --global --replace-all credential.https://%s/%s.helper %s", provider.Hostname, owner,
+					shellQuote(fmt.Sprintf(`!f() { echo username=%s; echo "password=$%s"; }; f`, account.Username, tokenEnvName(account))))
+			}
+		}
+	}
+
+	line("")
+	line("echo '✅ git identities configured by krakn'")
+	return b.String()
+}
+
+var remoteBootstrapCmd = &cobra.Command{
+	Use:   "remote-bootstrap",
+	Short: "Print a setup script that configures identities on a remote dev box",
+	Long: `Print a shell script that configures git identities on an ephemeral remote
+development environment (GitHub Codespaces, a cloud VM, a dev container) from
+your local accounts. The script never contains private keys or tokens.
+
+Repositories owned by an account's username or organizations (see 'krakn org')
+get that account's name and email through includeIf hasconfig:remote.*.url
+(git 2.36 or newer). The global identity is the current account, or --account.
+
+Authentication:
+  agent  (default) Forward your SSH agent ('ssh -A', Codespaces does this
+         for you). The script installs each account's public key and a host
+         alias, so ssh offers the right forwarded key per organization.
+  token  Use HTTPS with a token per account, read from KRAKN_TOKEN_<ACCOUNT>
+         (e.g. a Codespaces secret). Use a token scoped to what the box needs.
+
+Examples:
+  krakn remote-bootstrap | ssh -A devbox sh
+  krakn remote-bootstrap --auth token -o bootstrap.sh
+  krakn remote-bootstrap --account work --only work`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		auth, _ := cmd.Flags().GetString("auth")
+		primaryName, _ := cmd.Flags().GetString("account")
+		only, _ := cmd.Flags().GetStringSlice("only")
+		output, _ := cmd.Flags().GetString("output")
+		if auth != "agent" && auth != "token" {
+			return fmt.Errorf("❌ Unknown --auth '%s'. Use agent or token", auth)
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if len(config.Accounts) == 0 {
+			return errNoAccounts()
+		}
+
+		var accounts []*Account
+		for i := range config.Accounts {
+			account := &config.Accounts[i]
+			if len(only) > 0 && !containsString(only, account.Name) {
+				continue
+			}
+			if err := config.revealAccount(account); err != nil {
+				return err
+			}
+			accounts = append(accounts, account)
+		}
+		for _, name := range only {
+			if config.getAccount(name) == nil {
+				return config.accountNotFound(name)
+			}
+		}
+
+		explicit := primaryName != ""
+		if !explicit {
+			primaryName = config.CurrentAccount
+		}
+		primary := accounts[0]
+		for _, account := range accounts {
+			if account.Name == primaryName {
+				primary = account
+			}
+		}
+		if explicit && primary.Name != primaryName {
+			return fmt.Errorf("❌ Account '%s' is not among the accounts being bootstrapped", primaryName)
+		}
+
+		script := bootstrapScript(accounts, primary, auth)
+		if output == "" || output == "-" {
+			fmt.Print(script)
+			return nil
+		}
+		if err := os.WriteFile(output, []byte(script), 0755); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output, err)
+		}
+		fmt.Fprintf(os.Stderr, "✅ Wrote %s for %d accounts (global identity: %s)\n", output, len(accounts), primary.Name)
+		if auth == "token" {
+			var variables []string
+			for _, account := range accounts {
+				variables = append(variables, tokenEnvName(account))
+			}
+			fmt.Fprintf(os.Stderr, "💡 Provide the tokens on the remote box as %s\n", strings.Join(variables, ", "))
+		}
+		return nil
+	},
+}
+
+func init() {
+	remoteBootstrapCmd.Flags().String("auth", "agent", "How the remote box authenticates: agent or token")
+	remoteBootstrapCmd.Flags().StringP("account", "a", "", "Account for the global identity (default: the current account)")
+	remoteBootstrapCmd.Flags().StringSlice("only", nil, "Only include these accounts (default: all)")
+	remoteBootstrapCmd.Flags().StringP("output", "o", "", "Write the script to a file instead of stdout")
+	RootCmd.AddCommand(remoteBootstrapCmd)
+}
@@ -0,0 +1,379 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+// identityCardVersion is bumped when a card field is removed or changes
+// meaning. Version 2 signs with an SSH signature instead of a bare one.
+const identityCardVersion = 2
+
+// cardSignatureNamespace is the namespace of card signatures; check one with
+// ssh-keygen -Y check-novalidate -n krakn-card
+const cardSignatureNamespace = "krakn-card"
+
+// identityCard describes an account without secrets, so a teammate or another
+// machine can set up a matching account. It is signed with the account's SSH
+// key, which proves the card was made by whoever holds the key it names.
+type identityCard struct {
+	Version     int       `json:"card_version"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	Username    string    `json:"username"`
+	Provider    Provider  `json:"provider"`
+	SSHHost     string    `json:"ssh_host,omitempty"` // A custom alias; empty means the <hostname>-<name> scheme
+	AuthMethod  string    `json:"auth_method,omitempty"`
+	Orgs        []string  `json:"orgs,omitempty"`
+	PublicKey   string    `json:"public_key,omitempty"` // authorized_keys line of the account's key
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	Created     time.Time `json:"created"`
+	Signature   string    `json:"signature,omitempty"` // Armored SSH signature over the card without this field
+}
+
+// payload is the signed form of the card
+func (c identityCard) payload() []byte {
+	c.Signature = ""
+	data, _ := json.Marshal(c)
+	return data
+}
+
+// newIdentityCard describes an account. The card is signed when the account
+// has a key krakn can sign with.
+func newIdentityCard(account *Account) (*identityCard, error) {
+	card := &identityCard{
+		Version:    identityCardVersion,
+		Name:       account.Name,
+		Email:      account.Email,
+		Username:   account.Username,
+		Provider:   account.GetProvider(),
+		SSHHost:    account.SSHHost,
+		AuthMethod: account.AuthMethod,
+		Orgs:       account.Orgs,
+		Created:    time.Now().UTC().Truncate(time.Second),
+	}
+	if account.SSHKey == "" || account.usesHTTPS() {
+		return card, nil
+	}
+
+	data, err := os.ReadFile(account.SSHKey + ".pub")
+	if err != nil {
+		return nil, fmt.Errorf("❌ Cannot read the public key of '%s': %v", account.Name, err)
+	}
+	public, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Cannot parse %s: %v", contractHomePath(account.SSHKey+".pub"), err)
+	}
+	card.PublicKey = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(public)))
+	card.Fingerprint = ssh.FingerprintSHA256(public)
+
+	var signer ssh.Signer
+	if account.AgentKey || keyIsEncrypted(account.SSHKey) {
+		signer, err = agentSigner(account.SSHKey + ".pub")
+	}
+	if signer == nil && !account.AgentKey {
+		signer, err = loadSSHSigner(account.SSHKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("❌ Cannot sign the card with the key of '%s': %v", account.Name, err)
+	}
+	if card.Signature, err = sshsigSign(signer, cardSignatureNamespace, card.payload()); err != nil {
+		return nil, fmt.Errorf("❌ Cannot sign the card: %v", err)
+	}
+	return card, nil
+}
+
+// verify checks that the card was signed by the key it names. Unsigned
+// cards report false without an error.
+func (c *identityCard) verify() (bool, error) {
+	if c.Signature == "" {
+		return false, nil
+	}
+	if c.Version < 2 {
+		return false, fmt.Errorf("the card was signed by an older krakn; export it again")
+	}
+	public, _, _, _, err := ssh.ParseAuthorizedKey([]byte(c.PublicKey))
+	if err != nil {
+		return false, fmt.Errorf("the card's public key is invalid: %w", err)
+	}
+	if ssh.FingerprintSHA256(public) != c.Fingerprint {
+		return false, fmt.Errorf("the card's fingerprint does not match its public key")
+	}
+	signer, err := sshsigVerify(c.Signature, cardSignatureNamespace, c.payload())
+	if err != nil {
+		return false, fmt.Errorf("the card's signature is invalid: %w", err)
+	}
+	if !bytes.Equal(signer.Marshal(), public.Marshal()) {
+		return false, fmt.Errorf("the card is signed by %s, not by its own key", ssh.FingerprintSHA256(signer))
+	}
+	return true, nil
+}
+
+// readIdentityCard reads a card from a file, or from stdin for "-"
+func readIdentityCard(path string) (*identityCard, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(expandUserPath(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("❌ Cannot read the card: %v", err)
+	}
+	var card identityCard
+	if err := json.Unmarshal(data, &card); err != nil {
+		return nil, fmt.Errorf("❌ Not an identity card: %v", err)
+	}
+	if card.Version == 0 || card.Name == "" || card.Provider.Hostname == "" {
+		return nil, fmt.Errorf("❌ Not an identity card: the name or provider is missing")
+	}
+	if card.Version > identityCardVersion {
+		return nil, fmt.Errorf("❌ The card has version %d; this krakn reads version %d. Update krakn", card.Version, identityCardVersion)
+	}
+	return &card, nil
+}
+
+// cardKeySource sets up the private key of an imported account: an existing
+// key file, the key in ssh-agent with the card's fingerprint, a new key, or
+// none. It returns the account's SSHKey and whether it lives in the agent.
+func cardKeySource(config *Config, card *identityCard, name, source string) (string, bool, error) {
+	defaultPath := defaultKeyPath("", card.Provider.KeySuffix, name)
+	if source == "" {
+		reader := bufio.NewReader(os.Stdin)
+		fmt.Println("🔑 Where does the private key come from?")
+		fmt.Printf("   1) A key file [%s]\n", contractHomePath(defaultPath))
+		if card.Fingerprint != "" {
+			fmt.Printf("   2) ssh-agent (key %s)\n", card.Fingerprint)
+		}
+		fmt.Println("   3) Generate a new key")
+		fmt.Println("   4) None for now")
+		fmt.Print("💬 Choice [1]: ")
+		choice, _ := reader.ReadString('\n')
+		switch strings.TrimSpace(choice) {
+		case "", "1":
+			fmt.Printf("🔑 SSH key path [%s]: ", contractHomePath(defaultPath))
+			input, _ := reader.ReadString('\n')
+			source = strings.TrimSpace(input)
+			if source == "" {
+				source = defaultPath
+			}
+		case "2":
+			source = "agent"
+		case "3":
+			source = "generate"
+		case "4":
+			source = "none"
+		default:
+			return "", false, fmt.Errorf("❌ Unknown choice '%s'", strings.TrimSpace(choice))
+		}
+	}
+
+	switch source {
+	case "none":
+		return "", false, nil
+	case "generate":
+		if err := generateSSHKey(name, card.Email, defaultPath, "", &card.Provider); err != nil {
+			return "", false, err
+		}
+		return defaultPath, false, nil
+	case "agent":
+		if card.Fingerprint == "" {
+			return "", false, fmt.Errorf("❌ The card names no key to look for in ssh-agent")
+		}
+		keys, err := listAgentKeys(config)
+		if err != nil {
+			return "", false, err
+		}
+		for _, key := range keys {
+			if key.Fingerprint == card.Fingerprint {
+				return bindAgentKey(key, "", card.Provider.KeySuffix, name)
+			}
+		}
+		return "", false, fmt.Errorf("❌ ssh-agent does not hold key %s; load it with ssh-add first", card.Fingerprint)
+	}
+
+	keyPath, err := filepath.Abs(expandUserPath(source))
+	if err != nil {
+		return "", false, err
+	}
+	if !fileExists(keyPath) {
+		return "", false, fmt.Errorf("❌ SSH key not found: %s", keyPath)
+	}
+	if _, fingerprint, err := describePublicKey(keyPath + ".pub"); err == nil && card.Fingerprint != "" && fingerprint != card.Fingerprint {
+		fmt.Printf("ℹ️  %s is not the card's key %s; that is expected for a teammate's card\n", contractHomePath(keyPath), card.Fingerprint)
+	}
+	return keyPath, false, nil
+}
+
+var cardCmd = &cobra.Command{
+	Use:   "card",
+	Short: "Export and import secret-free account descriptions",
+	Long: `Share an account as an identity card: a small signed JSON file with the
+provider, username, email, key fingerprint and host alias, and no secrets.
+Import it on another machine, or give it to a teammate, to set up a matching
+account.
+
+Examples:
+  krakn card export work -o work.card.json
+  krakn card import work.card.json`,
+}
+
+var cardExportCmd = &cobra.Command{
+	Use:   "export <account-name>",
+	Short: "Print an account's identity card",
+	Long: `Print the identity card of an account: its provider, username, email,
+organizations, host alias and public key, signed with the account's SSH key so
+the importer can tell it was made by the key's holder. The signature is an
+SSH signature in the krakn-card namespace, the format of 'ssh-keygen -Y sign'.
+Tokens, private keys and machine paths are never included. HTTPS accounts get
+an unsigned card.
+
+Examples:
+  krakn card export work
+  krakn card export work -o work.card.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+		if err := config.revealAccount(account); err != nil {
+			return err
+		}
+
+		card, err := newIdentityCard(account)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(card, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append(data, '\n')
+		if output == "" || output == "-" {
+			os.Stdout.Write(data)
+			return nil
+		}
+		if err := os.WriteFile(output, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output, err)
+		}
+		if card.Signature != "" {
+			fmt.Fprintf(os.Stderr, "✅ Wrote %s, signed with %s\n", output, card.Fingerprint)
+		} else {
+			fmt.Fprintf(os.Stderr, "✅ Wrote %s (unsigned: the account has no SSH key)\n", output)
+		}
+		return nil
+	},
+}
+
+var cardImportCmd = &cobra.Command{
+	Use:   "import <card-file|->",
+	Short: "Set up an account from an identity card",
+	Long: `Create an account from an identity card made with 'krakn card export'. The
+signature is checked first; a card whose signature does not match is refused.
+Only the private key source is asked for: an existing key file, the card's key
+in ssh-agent, a newly generated key, or none yet (--key skips the question).
+
+Examples:
+  krakn card import work.card.json
+  krakn card import work.card.json --name alice-work --key ~/.ssh/id_ed25519
+  krakn card import - --key agent < work.card.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("name")
+		source, _ := cmd.Flags().GetString("key")
+		if args[0] == "-" && source == "" {
+			return fmt.Errorf("❌ Reading the card from stdin needs --key, since stdin cannot answer the prompt")
+		}
+
+		card, err := readIdentityCard(args[0])
+		if err != nil {
+			return err
+		}
+		signed, err := card.verify()
+		if err != nil {
+			return fmt.Errorf("❌ Refusing the card: %v", err)
+		}
+		if name == "" {
+			name = card.Name
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if config.getAccount(name) != nil {
+			return fmt.Errorf("❌ Account '%s' already exists; import under another name with --name", name)
+		}
+
+		fmt.Printf("🪪 %s: %s <%s> on %s\n", card.Name, card.Username, card.Email, card.Provider.DisplayName)
+		if signed {
+			fmt.Printf("✅ Signed by key %s\n", card.Fingerprint)
+		} else {
+			fmt.Println("⚠️  The card is unsigned; check it came from who you think")
+		}
+
+		account := Account{
+			Name:       name,
+			Email:      card.Email,
+			Username:   card.Username,
+			SSHHost:    card.SSHHost,
+			AuthMethod: card.AuthMethod,
+			Orgs:       card.Orgs,
+		}
+		if card.Provider.Hostname != DefaultProviders["github"].Hostname {
+			provider := card.Provider
+			account.Provider = &provider
+		}
+		if !account.usesHTTPS() {
+			if account.SSHKey, account.AgentKey, err = cardKeySource(config, card, name, source); err != nil {
+				return err
+			}
+		}
+		if err := config.addAccount(account); err != nil {
+			return fmt.Errorf("failed to add account: %w", err)
+		}
+		if account.SSHKey != "" {
+			if err := upsertSSHHostBlock(account.GetSSHHost(), account.GenerateSSHConfig()); err != nil {
+				return err
+			}
+		}
+
+		fmt.Printf("✅ Account '%s' imported\n", name)
+		switch {
+		case account.usesHTTPS():
+			fmt.Printf("💡 Finish the HTTPS setup with: krakn https-only %s\n", name)
+		case account.SSHKey == "":
+			fmt.Printf("💡 Add a key later with: krakn key generate --name %s --email %s\n", name, account.Email)
+		default:
+			fmt.Printf("🔗 SSH Host: %s\n", account.GetSSHHost())
+		}
+		return nil
+	},
+}
+
+func init() {
+	cardExportCmd.Flags().StringP("output", "o", "", "Write the card to a file instead of stdout")
+	cardImportCmd.Flags().String("name", "", "Name of the new account (default: the card's)")
+	cardImportCmd.Flags().String("key", "", "Private key source: a key file, agent, generate or none")
+	cardCmd.AddCommand(cardExportCmd)
+	cardCmd.AddCommand(cardImportCmd)
+	RootCmd.AddCommand(cardCmd)
+}
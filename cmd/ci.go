@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// ciTargets are the pipeline formats 'krakn ci render' produces
+var ciTargets = []string{"github-actions", "gitlab-ci"}
+
+// ciSecretName is the CI secret or variable a rendered pipeline reads the
+// account's private key from, e.g. KRAKN_SSH_KEY_WORK
+func ciSecretName(account *Account) string {
+	return "KRAKN_SSH_KEY_" + strings.TrimPrefix(tokenEnvName(account), "KRAKN_TOKEN_")
+}
+
+// pinnedHostKeys returns the known_hosts lines pinning the host keys of the
+// account's provider, taken from the local ~/.ssh/known_hosts so CI trusts
+// exactly the keys this machine does. A throwaway key is looked up, so the
+// mismatch lists every pinned key.
+func pinnedHostKeys(account *Account) ([]string, error) {
+	provider := account.GetProvider()
+	port := provider.SSHPort
+	if port == "" {
+		port = "22"
+	}
+	notPinned := fmt.Errorf("❌ The host key of %s is not pinned in ~/.ssh/known_hosts. Run 'krakn test %s --accept-new' first", provider.Hostname, account.Name)
+	homeDir, _ := os.UserHomeDir()
+	check, err := knownhosts.New(filepath.Join(homeDir, ".ssh", "known_hosts"))
+	if os.IsNotExist(err) {
+		return nil, notPinned
+	} else if err != nil {
+		return nil, fmt.Errorf("❌ Cannot read ~/.ssh/known_hosts: %v", err)
+	}
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	probe, err := ssh.NewPublicKey(public)
+	if err != nil {
+		return nil, err
+	}
+
+	address := net.JoinHostPort(provider.Hostname, port)
+	err = check(address, &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 22}, probe)
+	var keyErr *knownhosts.KeyError
+	if !errors.As(err, &keyErr) || len(keyErr.Want) == 0 {
+		return nil, notPinned
+	}
+	var lines []string
+	for _, known := range keyErr.Want {
+		lines = append(lines, knownhosts.Line([]string{knownhosts.Normalize(address)}, known.Key))
+	}
+	return lines, nil
+}
+
+// ciSetupScript renders the shell commands that install the account's key
+// from the variable keyVar, pin the provider's host keys, add the account's
+// host alias and set the git identity. A GitLab file variable holds a path
+// instead of the key, so both are accepted.
+func ciSetupScript(account *Account, keyVar string, hostKeys []string) string {
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(&b, format+"\n", args...)
+	}
+	provider := account.GetProvider()
+	keyPath := "~/.ssh/krakn_" + account.Name
+	alias := account.GetSSHHost()
+
+	line("mkdir -p ~/.ssh && chmod 700 ~/.ssh")
+	line(`if [ -f "$%s" ]; then cp "$%s" %s; else printf '%%s\n' "$%s" > %s; fi`, keyVar, keyVar, keyPath, keyVar, keyPath)
+	line("chmod 600 %s", keyPath)
+	line("cat >> ~/.ssh/known_hosts <<'KRAKN_EOF'")
+	for _, hostKey := range hostKeys {
+		line("%s", hostKey)
+	}
+	line("KRAKN_EOF")
+	line("cat >> ~/.ssh/config <<'KRAKN_EOF'")
+	line("Host %s", alias)
+	line("  HostName %s", provider.Hostname)
+	line("  User %s", provider.SSHUser)
+	if provider.SSHPort != "" && provider.SSHPort != "22" {
+		line("  Port %s", provider.SSHPort)
+	}
+	line("  IdentityFile %s", keyPath)
+	line("  IdentitiesOnly yes")
+	line("KRAKN_EOF")
+	line("git config --global user.name %s", shellQuote(account.CommitName()))
+	line("git config --global user.email %s", shellQuote(account.Email))
+	for _, owner := range append([]string{account.Username}, account.Orgs...) {
+		if owner == "" {
+			continue
+		}
+		line("git config --global --add %s %s", shellQuote(fmt.Sprintf("url.%s@%s:%s/.insteadOf", provider.SSHUser, alias, owner)),
+			shellQuote(fmt.Sprintf("%s@%s:%s/", provider.SSHUser, provider.Hostname, owner)))
+	}
+	return b.String()
+}
+
+// indent prefixes every line of text with prefix
+func indent(text, prefix string) string {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	for i := range lines {
+		lines[i] = prefix + lines[i]
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// renderCIPipeline renders the pipeline snippet of a target for an account
+func renderCIPipeline(target string, account *Account, hostKeys []string) string {
+	secret := ciSecretName(account)
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Generated by 'krakn ci render %s %s' on %s; rerun it after changing the account.\n",
+		target, account.Name, time.Now().Format("2006-01-02"))
+
+	switch target {
+	case "github-actions":
+		fmt.Fprintf(&b, "# Store the private key of the deploy or bot key as the secret %s.\n", secret)
+		fmt.Fprintf(&b, "- name: Set up the git identity of '%s'\n", account.Name)
+		b.WriteString("  shell: bash\n")
+		b.WriteString("  env:\n")
+		fmt.Fprintf(&b, "    KRAKN_SSH_KEY: ${{ secrets.%s }}\n", secret)
+		b.WriteString("  run: |\n")
+		b.WriteString(indent(ciSetupScript(account, "KRAKN_SSH_KEY", hostKeys), "    "))
+	case "gitlab-ci":
+		fmt.Fprintf(&b, "# Store the private key of the deploy or bot key as the CI/CD variable %s\n", secret)
+		b.WriteString("# (type File, protected), and extend this template in jobs that push.\n")
+		fmt.Fprintf(&b, ".krakn-%s:\n", account.Name)
+		b.WriteString("  before_script:\n")
+		b.WriteString("    - |\n")
+		b.WriteString(indent(ciSetupScript(account, secret, hostKeys), "      "))
+	}
+	return b.String()
+}
+
+var ciCmd = &cobra.Command{
+	Use:   "ci",
+	Short: "Generate CI pipeline setup from an account",
+	Long: `Generate CI configuration from account definitions, so runners use the same
+host alias, host keys and identity as your machine.
+
+Examples:
+  krakn ci render github-actions bot`,
+}
+
+var ciRenderCmd = &cobra.Command{
+	Use:   "render <github-actions|gitlab-ci> <account-name>",
+	Short: "Print a pipeline snippet that sets up an account's key and identity",
+	Long: `Print a pipeline snippet that installs the account's deploy or bot key from a
+CI secret, pins the provider's host keys, writes the account's host alias to
+~/.ssh/config and sets the git identity. Remotes of the account's username and
+organizations (see 'krakn org') are rewritten to the alias.
+
+The snippet is generated from the account definition: the host alias, the SSH
+port, the commit name and email, and the host keys pinned in your
+~/.ssh/known_hosts. Render it again after changing the account so the pipeline
+does not drift from your local setup. It never contains a private key; the key
+is read from the secret KRAKN_SSH_KEY_<ACCOUNT> and must have no passphrase.
+
+  github-actions  A step for a job's steps list
+  gitlab-ci       A hidden job (.krakn-<account>) to extend in jobs that push
+
+Examples:
+  krakn ci render github-actions bot
+  krakn ci render gitlab-ci release -o .gitlab/krakn-release.yml`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target, name := args[0], args[1]
+		output, _ := cmd.Flags().GetString("output")
+		if !containsString(ciTargets, target) {
+			return fmt.Errorf("❌ Unknown CI target '%s'. Use %s", target, strings.Join(ciTargets, " or "))
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(name)
+		if account == nil {
+			return config.accountNotFound(name)
+		}
+		if account.usesHTTPS() {
+			return fmt.Errorf("❌ Account '%s' is HTTPS-only; give the pipeline a token instead of an SSH key", account.Name)
+		}
+		if err := config.revealAccount(account); err != nil {
+			return err
+		}
+
+		hostKeys, err := pinnedHostKeys(account)
+		if err != nil {
+			return err
+		}
+		snippet := renderCIPipeline(target, account, hostKeys)
+		if output == "" || output == "-" {
+			fmt.Print(snippet)
+			return nil
+		}
+		if err := os.WriteFile(output, []byte(snippet), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output, err)
+		}
+		fmt.Fprintf(os.Stderr, "✅ Wrote %s\n", output)
+		fmt.Fprintf(os.Stderr, "💡 Store the private key as the CI secret %s\n", ciSecretName(account))
+		return nil
+	},
+}
+
+func init() {
+	ciRenderCmd.Flags().StringP("output", "o", "", "Write the snippet to a file instead of stdout")
+	ciCmd.AddCommand(ciRenderCmd)
+	RootCmd.AddCommand(ciCmd)
+}
@@ -0,0 +1,291 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// providerCLI is the command line client of a provider whose logged-in
+// account is independent of krakn's: gh for GitHub, glab for GitLab. PRs,
+// issues and comments made with it come from that account.
+type providerCLI struct {
+	Tool     string
+	Provider string
+	TokenEnv []string // Environment variables that override the logged-in account
+}
+
+var providerCLIs = []providerCLI{
+	{Tool: "gh", Provider: "github", TokenEnv: []string{"GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}},
+	{Tool: "glab", Provider: "gitlab", TokenEnv: []string{"GITLAB_TOKEN", "GITLAB_ACCESS_TOKEN", "OAUTH_TOKEN"}},
+}
+
+// cliLogin is what a provider CLI knows about a host
+type cliLogin struct {
+	Active string   // The account the CLI uses
+	Users  []string // Every account logged in, when the CLI supports several
+}
+
+// configDir returns the directory the CLI keeps its hosts file in
+func (c providerCLI) configDir() string {
+	homeDir, _ := os.UserHomeDir()
+	if c.Tool == "gh" {
+		if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+			return dir
+		}
+		if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+			return filepath.Join(dir, "gh")
+		}
+		if runtime.GOOS == "windows" && os.Getenv("AppData") != "" {
+			return filepath.Join(os.Getenv("AppData"), "GitHub CLI")
+		}
+		return filepath.Join(homeDir, ".config", "gh")
+	}
+	if dir := os.Getenv("GLAB_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "glab-cli")
+	}
+	return filepath.Join(homeDir, ".config", "glab-cli")
+}
+
+// login reads the account the CLI is logged in as on a host from its config
+// file, without running it. ok is false when the CLI has no login there.
+func (c providerCLI) login(hostname string) (cliLogin, bool) {
+	type hostEntry struct {
+		User  string               `yaml:"user"`
+		Users map[string]yaml.Node `yaml:"users"`
+	}
+	var hosts map[string]hostEntry
+	if c.Tool == "gh" {
+		data, err := os.ReadFile(filepath.Join(c.configDir(), "hosts.yml"))
+		if err != nil || yaml.Unmarshal(data, &hosts) != nil {
+			return cliLogin{}, false
+		}
+	} else {
+		data, err := os.ReadFile(filepath.Join(c.configDir(), "config.yml"))
+		var config struct {
+			Hosts map[string]hostEntry `yaml:"hosts"`
+		}
+		if err != nil || yaml.Unmarshal(data, &config) != nil {
+			return cliLogin{}, false
+		}
+		hosts = config.Hosts
+	}
+
+	entry, ok := hosts[hostname]
+	if !ok || entry.User == "" {
+		return cliLogin{}, false
+	}
+	login := cliLogin{Active: entry.User}
+	for user := range entry.Users {
+		login.Users = append(login.Users, user)
+	}
+	return login, true
+}
+
+// tokenOverride returns the environment variable that makes the CLI ignore
+// its logged-in account, if one is set
+func (c providerCLI) tokenOverride() string {
+	for _, name := range c.TokenEnv {
+		if os.Getenv(name) != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// switchCommand returns the command that makes the CLI use another of its
+// logged-in accounts, or nil when the CLI has to log in again instead
+func (c providerCLI) switchCommand(hostname, user string, login cliLogin) []string {
+	if c.Tool == "gh" && containsString(login.Users, user) {
+		return []string{"gh", "auth", "switch", "--hostname", hostname, "--user", user}
+	}
+	return nil
+}
+
+// loginCommand is how the user logs the CLI in as an account
+func (c providerCLI) loginCommand(hostname string) string {
+	return fmt.Sprintf("%s auth login --hostname %s", c.Tool, hostname)
+}
+
+// cliDrift compares the account a provider CLI uses with the one krakn uses
+type cliDrift struct {
+	CLI      providerCLI
+	Account  *Account
+	Hostname string
+	Login    cliLogin
+	Override string // Token variable overriding the CLI's login
+}
+
+func (d cliDrift) drifted() bool {
+	return d.Override == "" && !strings.EqualFold(d.Login.Active, d.Account.Username)
+}
+
+// repoAccountForCLI returns the account work in dir should be done as: the
+// account the directory or repository is mapped to, the one its remote
+// authenticates as, or the one whose identity it commits with
+func repoAccountForCLI(config *Config, dir string) *Account {
+	state := currentState(config, dir)
+	for _, name := range []string{state.Mapped, state.RemoteAccount, state.Account} {
+		if name == "" {
+			continue
+		}
+		if account := config.getAccount(name); account != nil {
+			return account
+		}
+	}
+	return nil
+}
+
+// detectCLIDrift returns the provider CLIs logged in on the account's host,
+// with the account each uses
+func detectCLIDrift(account *Account) []cliDrift {
+	var drifts []cliDrift
+	provider := account.GetProvider()
+	for _, cli := range providerCLIs {
+		if cli.Provider != provider.Name || account.Username == "" {
+			continue
+		}
+		if _, err := exec.LookPath(cli.Tool); err != nil {
+			continue
+		}
+		login, ok := cli.login(provider.Hostname)
+		if !ok {
+			continue
+		}
+		drifts = append(drifts, cliDrift{CLI: cli, Account: account, Hostname: provider.Hostname, Login: login, Override: cli.tokenOverride()})
+	}
+	return drifts
+}
+
+// checkCLIDrift warns when gh or glab would act as another user than the
+// account krakn uses for the current repository
+func checkCLIDrift(ctx *doctorContext) []doctorFinding {
+	dir := ctx.RepoRoot
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	account := repoAccountForCLI(ctx.Config, dir)
+	if account == nil {
+		return nil
+	}
+
+	var findings []doctorFinding
+	for _, drift := range detectCLIDrift(account) {
+		switch {
+		case drift.Override != "":
+			findings = append(findings, doctorFinding{
+				Level:   doctorInfo,
+				Message: fmt.Sprintf("%s uses the token in %s instead of its login on %s", drift.CLI.Tool, drift.Override, drift.Hostname),
+			})
+		case drift.drifted():
+			hint := "krakn cli-auth --switch"
+			if drift.CLI.switchCommand(drift.Hostname, account.Username, drift.Login) == nil {
+				hint = fmt.Sprintf("Log in as %s: %s", account.Username, drift.CLI.loginCommand(drift.Hostname))
+			}
+			findings = append(findings, doctorFinding{
+				Level:   doctorWarn,
+				Message: fmt.Sprintf("%s acts as %s on %s, but this directory uses '%s' (%s)", drift.CLI.Tool, drift.Login.Active, drift.Hostname, account.Name, account.Username),
+				Hint:    hint,
+			})
+		default:
+			findings = append(findings, doctorFinding{
+				Level:   doctorOK,
+				Message: fmt.Sprintf("%s acts as %s on %s (account '%s')", drift.CLI.Tool, drift.Login.Active, drift.Hostname, account.Name),
+			})
+		}
+	}
+	return findings
+}
+
+var cliAuthCmd = &cobra.Command{
+	Use:   "cli-auth [path]",
+	Short: "Check that gh and glab act as the directory's account",
+	Long: `Compare the account the GitHub CLI (gh) and GitLab CLI (glab) are logged in as
+with the account krakn uses for a directory. A mismatch makes pull requests,
+issues and comments come from the wrong user even though commits and pushes
+are right. 'krakn doctor' reports the same.
+
+The account is the one the directory or repository is mapped to, the one the
+remote's host alias belongs to, or the one whose email commits use. It is
+compared with the account's provider username.
+
+With --switch, gh is switched to the account with 'gh auth switch' when it is
+logged in as it too. glab keeps one login per host, so it has to log in again.
+
+Examples:
+  krakn cli-auth
+  krakn cli-auth ~/work/api --switch`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		doSwitch, _ := cmd.Flags().GetBool("switch")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		dir := "."
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		if dir, err = filepath.Abs(expandUserPath(dir)); err != nil {
+			return err
+		}
+
+		account := repoAccountForCLI(config, dir)
+		if account == nil {
+			return fmt.Errorf("❌ No krakn account applies to %s", contractHomePath(dir))
+		}
+		if account.Username == "" {
+			return fmt.Errorf("❌ Account '%s' has no provider username to compare with", account.Name)
+		}
+		drifts := detectCLIDrift(account)
+		if len(drifts) == 0 {
+			fmt.Printf("ℹ️  No gh or glab login found for %s\n", account.GetProvider().Hostname)
+			return nil
+		}
+
+		for _, drift := range drifts {
+			if drift.Override != "" {
+				fmt.Printf("ℹ️  %s uses the token in %s, not its login\n", drift.CLI.Tool, drift.Override)
+				continue
+			}
+			if !drift.drifted() {
+				fmt.Printf("✅ %s acts as %s (account '%s')\n", drift.CLI.Tool, drift.Login.Active, account.Name)
+				continue
+			}
+			fmt.Printf("⚠️  %s acts as %s on %s, but %s uses '%s' (%s)\n", drift.CLI.Tool, drift.Login.Active, drift.Hostname, contractHomePath(dir), account.Name, account.Username)
+
+			switchArgs := drift.CLI.switchCommand(drift.Hostname, account.Username, drift.Login)
+			if switchArgs == nil {
+				fmt.Printf("   💡 Log in as %s: %s\n", account.Username, drift.CLI.loginCommand(drift.Hostname))
+				continue
+			}
+			if !doSwitch {
+				fmt.Printf("   💡 Run: %s  (or krakn cli-auth --switch)\n", strings.Join(switchArgs, " "))
+				continue
+			}
+			switchCmd := traceExec(exec.Command(switchArgs[0], switchArgs[1:]...))
+			switchCmd.Stdout, switchCmd.Stderr = os.Stdout, os.Stderr
+			if err := switchCmd.Run(); err != nil {
+				return fmt.Errorf("❌ %s failed: %w", strings.Join(switchArgs, " "), err)
+			}
+			fmt.Printf("🔄 %s now acts as %s\n", drift.CLI.Tool, account.Username)
+		}
+		return nil
+	},
+}
+
+func init() {
+	cliAuthCmd.Flags().Bool("switch", false, "Switch gh to the account when it is logged in as it")
+	RootCmd.AddCommand(cliAuthCmd)
+	registerDoctorCheck(doctorCheck{Name: "Provider CLIs", Run: checkCLIDrift})
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// cloneAccount picks the account a clone of a repository on host into dest
+// uses: the named one, the one whose host alias the URL already uses, the
+// one mapped to the target directory, the only account on the provider, or
+// the current account. host is empty when only owner/repo was given.
+func cloneAccount(config *Config, accountName, urlHost, host, dest string) (*Account, error) {
+	if accountName != "" {
+		account := config.getAccount(accountName)
+		if account == nil {
+			return nil, config.accountNotFound(accountName)
+		}
+		tracef(traceMatch, "Account '%s' was named on the command line", account.Name)
+		return account, nil
+	}
+	if account := config.findAccountByHost(urlHost); account != nil && urlHost != host {
+		tracef(traceMatch, "The URL already uses the host alias of account '%s'", account.Name)
+		return account, nil
+	}
+	if account := config.findAccountForPath(filepath.Dir(dest)); account != nil {
+		tracef(traceMatch, "%s is mapped to account '%s'", contractHomePath(filepath.Dir(dest)), account.Name)
+		return account, nil
+	}
+
+	if host == "" {
+		// owner/repo names no provider, so any account can clone it
+		if account := config.getAccount(config.CurrentAccount); account != nil {
+			tracef(traceMatch, "Falling back to the current account '%s'", account.Name)
+			return account, nil
+		}
+		return nil, fmt.Errorf("❌ No account selected for the clone; choose one with --account")
+	}
+
+	var onHost []*Account
+	for i := range config.Accounts {
+		if strings.EqualFold(config.Accounts[i].GetProvider().Hostname, host) {
+			onHost = append(onHost, &config.Accounts[i])
+		}
+	}
+	if len(onHost) == 1 {
+		tracef(traceMatch, "Account '%s' is the only one on %s", onHost[0].Name, host)
+		return onHost[0], nil
+	}
+	if account := config.getAccount(config.CurrentAccount); account != nil && strings.EqualFold(account.GetProvider().Hostname, host) {
+		tracef(traceMatch, "Falling back to the current account '%s'", account.Name)
+		return account, nil
+	}
+	if len(onHost) == 0 {
+		return nil, fmt.Errorf("❌ No account is configured for %s. Add one with 'krakn add'", host)
+	}
+	var names []string
+	for _, account := range onHost {
+		names = append(names, account.Name)
+	}
+	return nil, fmt.Errorf("❌ Several accounts use %s (%s); choose one with --account", host, strings.Join(names, ", "))
+}
+
+var cloneCmd = &cobra.Command{
+	Use:   "clone <url|owner/repo> [directory]",
+	Short: "Clone a repository through an account's host alias and identity",
+	Long: `Clone a repository so that it pushes with the right key and commits with the
+right identity from the start. The SSH URL is rewritten to the account's host
+alias (git@github.com:org/repo.git → git@github.com-work:org/repo.git) and
+user.name and user.email are set in the new repository. HTTPS-only accounts
+get an https:// URL; in local-only mode the hostname is kept and
+core.sshCommand selects the key instead.
+
+The account is --account, the account whose alias the URL already uses, the
+account mapped to the target directory ('krakn config'), the only account on
+the provider, or the current account.
+
+Examples:
+  krakn clone git@github.com:org/repo.git --account work
+  krakn clone org/repo ~/work/repo          # Account from the ~/work mapping
+  krakn clone https://gitlab.com/group/tool.git --branch develop
+  krakn clone https://dev.azure.com/org/project/_git/repo  # Cloned from ssh.dev.azure.com:v3/...`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeRemoteURL,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountName, _ := cmd.Flags().GetString("account")
+		branch, _ := cmd.Flags().GetString("branch")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if len(config.Accounts) == 0 {
+			return errNoAccounts()
+		}
+
+		host, path, err := parseUpstream(config, args[0])
+		if err != nil {
+			return err
+		}
+		urlHost := host
+		if remote, err := parseRemoteURL(args[0]); err == nil {
+			urlHost = remote.Host
+		}
+
+		dest := filepath.Base(path)
+		if len(args) == 2 {
+			dest = args[1]
+		}
+		dest, err = filepath.Abs(expandUserPath(dest))
+		if err != nil {
+			return err
+		}
+		if fileExists(dest) {
+			return fmt.Errorf("❌ %s already exists", contractHomePath(dest))
+		}
+
+		account, err := cloneAccount(config, accountName, urlHost, host, dest)
+		if err != nil {
+			return err
+		}
+		provider := account.GetProvider()
+		if host == "" {
+			// owner/repo names no host; the account's provider supplies it
+			host = provider.Hostname
+		}
+		if !strings.EqualFold(host, provider.Hostname) {
+			return fmt.Errorf("❌ %s is on %s, but account '%s' is on %s", path, host, account.Name, provider.Hostname)
+		}
+		if err := config.revealAccount(account); err != nil {
+			return err
+		}
+		if err := account.checkKeyDir(); err != nil {
+			return err
+		}
+
+		// SSH URLs keep their form (scheme, port); owner/repo and https URLs
+		// become SSH URLs, which HTTPS-only accounts turn back into https
+		source := args[0]
+		if remote, err := parseRemoteURL(source); err != nil || !remote.isSSH() {
+			source = provider.GetSSHCloneURL(provider.Hostname, path)
+		}
+		spin := startSpinner(fmt.Sprintf("Cloning %s as '%s'", path, account.Name))
+		err = cloneWorkspaceRepo(config, workspaceRepo{URL: source, Account: account.Name, Branch: branch}, dest)
+		spin.Stop()
+		if err != nil {
+			return fmt.Errorf("failed to clone %s: %w", path, err)
+		}
+
+		fmt.Printf("📁 Cloned %s into %s as '%s'\n", path, contractHomePath(dest), account.Name)
+		fmt.Printf("   origin %s\n", getRepoRemoteURL(dest, "origin"))
+		fmt.Printf("👤 Name: %s\n", account.CommitName())
+		fmt.Printf("📧 Email: %s\n", account.Email)
+		return nil
+	},
+}
+
+func init() {
+	cloneCmd.Flags().String("account", "", "Account to clone with (default: URL alias, directory mapping, only or current account)")
+	cloneCmd.Flags().StringP("branch", "b", "", "Branch to check out")
+	RootCmd.AddCommand(cloneCmd)
+}
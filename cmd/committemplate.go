@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// getCommitTemplateDir returns where the commit templates with an account's
+// trailers are generated
+func getCommitTemplateDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".krakncat", "templates")
+}
+
+// expandTrailer turns a bare trailer token into a trailer carrying the
+// account's identity, e.g. "Signed-off-by" → "Signed-off-by: Name <email>".
+// Trailers with a value are kept as they are.
+func (a *Account) expandTrailer(trailer string) string {
+	trailer = strings.TrimSpace(trailer)
+	if strings.Contains(trailer, ":") {
+		return trailer
+	}
+	return fmt.Sprintf("%s: %s <%s>", trailer, a.CommitName(), a.Email)
+}
+
+// commitTemplatePath returns the commit.template of the account: its own
+// template, or the one generated with its trailers. It is "" when the account
+// has neither.
+func (a *Account) commitTemplatePath() string {
+	if len(a.Trailers) > 0 {
+		return filepath.Join(getCommitTemplateDir(), a.Name+".txt")
+	}
+	return a.CommitTemplate
+}
+
+// writeCommitTemplate generates the template with the account's trailers,
+// after the text of its own template if it has one
+func writeCommitTemplate(account *Account) error {
+	if len(account.Trailers) == 0 {
+		return nil
+	}
+	var content string
+	if account.CommitTemplate != "" {
+		data, err := os.ReadFile(account.CommitTemplate)
+		if err != nil {
+			return fmt.Errorf("failed to read commit template: %w", err)
+		}
+		content = strings.TrimRight(string(data), "\n") + "\n"
+	}
+	content += "\n"
+	for _, trailer := range account.Trailers {
+		content += account.expandTrailer(trailer) + "\n"
+	}
+
+	if err := makePrivateDir(getCommitTemplateDir()); err != nil {
+		return err
+	}
+	path := account.commitTemplatePath()
+	trackFile(path)
+	if err := writePrivateFile(path, []byte(content)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// identityInclude renders an include file giving repositories an account's
+// identity: user.name, user.email (email, when an override changes it), the
+// account's commit template and its commit signing
+func identityInclude(account *Account, email string) string {
+	return identityIncludeAs(account, "", email)
+}
+
+// identityIncludeAs renders the include file of an account under another
+// commit name and email; empty values keep the account's
+func identityIncludeAs(account *Account, name, email string) string {
+	if name == "" {
+		name = account.CommitName()
+	}
+	if email == "" {
+		email = account.Email
+	}
+	content := fmt.Sprintf("[user]\n\tname = %s\n\temail = %s\n", formatGitConfigValue(name), formatGitConfigValue(email))
+	if account.SigningKey != "" {
+		content += fmt.Sprintf("\tsigningkey = %s\n", formatGitConfigValue(account.SigningKey))
+	}
+	var commit string
+	if template := account.commitTemplatePath(); template != "" {
+		commit += fmt.Sprintf("\ttemplate = %s\n", formatGitConfigValue(contractHomePath(template)))
+	}
+	switch {
+	case account.SigningKey != "":
+		commit += "\tgpgsign = true\n"
+	case account.NoSigning:
+		commit += "\tgpgsign = false\n"
+	}
+	if commit != "" {
+		content += "[commit]\n" + commit
+	}
+	return content
+}
+
+// rewriteAccountIncludes rewrites the include files of an account's directory
+// mappings and repository overrides after its identity settings changed
+func (c *Config) rewriteAccountIncludes(account *Account) error {
+	identity := *account
+	if err := c.revealAccount(&identity); err != nil {
+		return err
+	}
+	if err := writeCommitTemplate(&identity); err != nil {
+		return err
+	}
+	for _, mapping := range c.Directories {
+		if mapping.Account != account.Name || mapping.ConfigFile == "" {
+			continue
+		}
+		trackFile(mapping.ConfigFile)
+		if err := writePrivateFile(mapping.ConfigFile, []byte(mapping.identityInclude(&identity))); err != nil {
+			return fmt.Errorf("failed to write %s: %w", mapping.ConfigFile, err)
+		}
+		fmt.Printf("📝 Updated %s\n", contractHomePath(mapping.ConfigFile))
+	}
+	for _, override := range c.Overrides {
+		if override.Account != account.Name {
+			continue
+		}
+		trackFile(override.ConfigFile)
+		if err := writePrivateFile(override.ConfigFile, []byte(identityInclude(&identity, override.Email))); err != nil {
+			return fmt.Errorf("failed to write %s: %w", override.ConfigFile, err)
+		}
+		fmt.Printf("📝 Updated %s\n", contractHomePath(override.ConfigFile))
+	}
+	return nil
+}
+
+// isGeneratedCommitTemplate reports whether a commit.template value is one
+// krakncat generated, which a switch to another account may replace
+func isGeneratedCommitTemplate(value string) bool {
+	return value != "" && insideDir(expandUserPath(value), getCommitTemplateDir())
+}
+
+var commitTemplateCmd = &cobra.Command{
+	Use:   "commit-template <account-name> [template-file]",
+	Short: "Set an account's commit message template and default trailers",
+	Long: `Give an account a commit message template (git's commit.template) and
+trailers added to every commit message written in the editor, such as the
+Signed-off-by a DCO requires or a Jira footer. They are written into the
+include files of the account's directory mappings and overrides and applied by
+'krakn use', so they only appear under the right identity.
+
+A bare trailer name is filled in with the account's identity: Signed-off-by
+becomes "Signed-off-by: Name <email>". Trailers are added through a template
+generated in ~/.krakncat/templates, so 'git commit -m' skips them; use
+'git commit -s' there.
+
+Without a template file or flags, the current settings are shown.
+
+Examples:
+  krakn commit-template oss --trailer Signed-off-by
+  krakn commit-template work ~/templates/work.txt --trailer "Jira: PROJ-0"
+  krakn commit-template work --clear`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		trailers, _ := cmd.Flags().GetStringArray("trailer")
+		reset, _ := cmd.Flags().GetBool("clear")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+
+		if len(args) == 1 && len(trailers) == 0 && !reset {
+			if account.CommitTemplate == "" && len(account.Trailers) == 0 {
+				fmt.Printf("ℹ️  Account '%s' has no commit template or trailers\n", account.Name)
+				return nil
+			}
+			if account.CommitTemplate != "" {
+				fmt.Printf("📄 Template: %s\n", contractHomePath(account.CommitTemplate))
+			}
+			for _, trailer := range account.Trailers {
+				fmt.Printf("🏷️  Trailer: %s\n", account.expandTrailer(trailer))
+			}
+			return nil
+		}
+
+		previous := account.commitTemplatePath()
+		if reset {
+			account.CommitTemplate = ""
+			account.Trailers = nil
+		}
+		if len(args) == 2 {
+			path, err := filepath.Abs(expandUserPath(args[1]))
+			if err != nil {
+				return err
+			}
+			if !fileExists(path) {
+				return fmt.Errorf("❌ Commit template not found: %s", path)
+			}
+			account.CommitTemplate = path
+		}
+		if len(trailers) > 0 {
+			account.Trailers = trailers
+		}
+
+		if err := config.addAccount(*account); err != nil {
+			return errSaveConfig(err)
+		}
+		if previous != account.commitTemplatePath() && isGeneratedCommitTemplate(previous) {
+			os.Remove(previous)
+		}
+		if err := config.rewriteAccountIncludes(account); err != nil {
+			return err
+		}
+
+		if template := account.commitTemplatePath(); template != "" {
+			fmt.Printf("✅ Commits of '%s' use the template %s\n", account.Name, contractHomePath(template))
+			for _, trailer := range account.Trailers {
+				fmt.Printf("🏷️  %s\n", account.expandTrailer(trailer))
+			}
+		} else {
+			fmt.Printf("✅ Removed the commit template of '%s'\n", account.Name)
+		}
+		fmt.Printf("💡 Run 'krakn use %s' to apply it to the global or a repository's config\n", account.Name)
+		return nil
+	},
+}
+
+func init() {
+	commitTemplateCmd.Flags().StringArray("trailer", nil, "Trailer to add, e.g. Signed-off-by or \"Jira: PROJ-0\" (repeatable; replaces the current trailers)")
+	commitTemplateCmd.Flags().Bool("clear", false, "Remove the template and trailers")
+	RootCmd.AddCommand(commitTemplateCmd)
+}
@@ -13,12 +13,93 @@ type Account struct {
 	SSHKey    string `json:"ssh_key"`
 	Username  string `json:"username"`
 	IsDefault bool   `json:"is_default"`
+	Token     string `json:"token,omitempty"` // Provider API token, used when no keychain is available
+
+	// Provider is the Git hosting provider; nil means GitHub (configs created before multi-provider support)
+	Provider *Provider `json:"provider,omitempty"`
+	// SSHHost links an existing ~/.ssh/config alias instead of the generated <hostname>-<name> alias
+	SSHHost string `json:"ssh_host,omitempty"`
+	// SSHOptions are extra ssh_config options rendered into the account's Host block
+	SSHOptions []SSHOption `json:"ssh_options,omitempty"`
+	// Multiplex enables ControlMaster connection sharing for the account's host
+	Multiplex      bool   `json:"multiplex,omitempty"`
+	ControlPersist string `json:"control_persist,omitempty"` // How long an idle master stays open, e.g. "10m"
+	// Orgs are the GitHub organizations the account works with (see org.go)
+	Orgs []string `json:"orgs,omitempty"`
+	// Confidential accounts may not push to public repositories (see guard.go)
+	Confidential bool `json:"confidential,omitempty"`
+	// Profile is the provider profile fetched with the account's token (see profile.go)
+	Profile *AccountProfile `json:"profile,omitempty"`
+	// AuthMethod is how git authenticates the account: "ssh" (the default) or
+	// "https", where remotes are https URLs and git gets the token from
+	// 'krakn credential' (see https.go)
+	AuthMethod string `json:"auth_method,omitempty"`
+	// HTTPSOnly is the "https" AuthMethod of configs written before it existed;
+	// loadConfig converts it
+	HTTPSOnly bool `json:"https_only,omitempty"`
+	// TokenStore is "keychain" when Token lives in the system keychain instead
+	// of this file (see keychain.go)
+	TokenStore string `json:"token_store,omitempty"`
+	// Timezone is the IANA time zone commits are stamped in when the 'krakn env' shell hook is active
+	Timezone string `json:"timezone,omitempty"`
+	// KeyDir is where the account's keys must live, e.g. a directory on an encrypted volume (see keydir.go)
+	KeyDir string `json:"key_dir,omitempty"`
+	// AgentKey means the private key only lives in ssh-agent, e.g. on a hardware
+	// token; SSHKey+".pub" holds its public key (see agentkeys.go)
+	AgentKey bool `json:"agent_key,omitempty"`
+	// KeyID is the GitHub API ID of the public key krakn uploaded, deleted again
+	// when the account is removed (see keyupload.go)
+	KeyID int64 `json:"key_id,omitempty"`
+	// CommitTemplate is the account's commit.template; Trailers are appended to
+	// it in a generated template, e.g. "Signed-off-by" (see committemplate.go)
+	CommitTemplate string   `json:"commit_template,omitempty"`
+	Trailers       []string `json:"trailers,omitempty"`
+	// SigningKey is the GPG key commits of the account are signed with;
+	// NoSigning turns signing off for it instead (see signing.go)
+	SigningKey string `json:"signing_key,omitempty"`
+	NoSigning  bool   `json:"no_signing,omitempty"`
+
+	// Sealed holds encrypted values for private fields (see private.go).
+	// A sealed field is kept empty in the plaintext struct until revealed.
+	Sealed map[string]string `json:"sealed,omitempty"`
+}
+
+// SSHOption is an extra ssh_config keyword/value pair for an account
+type SSHOption struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// DirectoryMapping records a directory configured with 'krakn config'
+type DirectoryMapping struct {
+	Path       string `json:"path"`                // Directory matched by the includeIf gitdir pattern
+	Account    string `json:"account"`             // Account name the directory maps to
+	ConfigFile string `json:"config_file"`         // Include file referenced by the includeIf section
+	Worktrees  string `json:"worktrees,omitempty"` // Worktree name glob; Path is then the repository's common git directory
+	Name       string `json:"name,omitempty"`      // Commit name in the directory instead of the account's
+	Email      string `json:"email,omitempty"`     // Commit email in the directory instead of the account's, e.g. another legal entity's
 }
 
 type Config struct {
-	Accounts        []Account `json:"accounts"`
-	CurrentAccount  string    `json:"current_account"`
-	MigrationDone   bool      `json:"migration_done"`
+	Accounts       []Account          `json:"accounts"`
+	CurrentAccount string             `json:"current_account"`
+	MigrationDone  bool               `json:"migration_done"`
+	Sealing        *SealConfig        `json:"sealing,omitempty"`
+	Directories    []DirectoryMapping `json:"directories,omitempty"`
+	Overrides      []RepoOverride     `json:"overrides,omitempty"` // Per-repository identities that win over directories (see override.go)
+	Ignore         []string           `json:"ignore,omitempty"`    // Directories skipped by repository scans (see ignore.go)
+	OrgApps        []OrgApp           `json:"org_apps,omitempty"`
+	PushGuard      *PushGuard         `json:"push_guard,omitempty"`
+	Schedule       *ScheduleConfig    `json:"schedule,omitempty"`
+	Context        *ContextConfig     `json:"context,omitempty"`
+	Notify         *NotifyConfig      `json:"notify,omitempty"`
+	Webhook        *WebhookConfig     `json:"webhook,omitempty"`
+	Metrics        *MetricsConfig     `json:"metrics,omitempty"`
+	AuthRefresh    *AuthRefreshConfig `json:"auth_refresh,omitempty"` // Background re-verification by 'krakn watch' (see authrefresh.go)
+	Integrations   []Integration      `json:"integrations,omitempty"` // Generated launcher extensions, kept up to date (see integrations.go)
+	Audit          *AuditConfig       `json:"audit,omitempty"`
+	LocalOnly      bool               `json:"local_only,omitempty"`     // Never edit ~/.gitconfig or ~/.ssh/config (see localonly.go)
+	ConfigVersion  int                `json:"config_version,omitempty"` // Format version, see configVersion in providers.go
 }
 
 func getConfigPath() string {
@@ -28,14 +109,14 @@ func getConfigPath() string {
 
 func ensureConfigDir() error {
 	homeDir, _ := os.UserHomeDir()
-	configDir := filepath.Join(homeDir, ".krakncat")
-	return os.MkdirAll(configDir, 0755)
+	return makePrivateDir(filepath.Join(homeDir, ".krakncat"))
 }
 
 func loadConfig() (*Config, error) {
 	configPath := getConfigPath()
-	
+
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		tracef(traceRead, "%s does not exist, starting with an empty config", contractHomePath(configPath))
 		return &Config{
 			Accounts:       []Account{},
 			CurrentAccount: "",
@@ -48,11 +129,26 @@ func loadConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to read config: %w", err)
 	}
 
+	config, err := parseConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	tracef(traceRead, "%s (%d accounts, current '%s')", contractHomePath(configPath), len(config.Accounts), config.CurrentAccount)
+
+	return config, nil
+}
+
+// parseConfig decodes the content of a config.json, or of one of its backups
+func parseConfig(data []byte) (*Config, error) {
 	var config Config
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
+	// Paths are stored as ~/... so configs can be synced between machines
+	config.expandPaths()
+	config.upgradeAuthMethods()
+	config.upgradeProviders()
 	return &config, nil
 }
 
@@ -61,39 +157,94 @@ func (c *Config) saveConfig() error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	data, err := json.MarshalIndent(c, "", "  ")
+	// Never write revealed plaintext for sealed fields back to disk
+	out := *c
+	out.Accounts = make([]Account, len(c.Accounts))
+	for i, account := range c.Accounts {
+		out.Accounts[i] = account.stripSealed()
+	}
+	out.Directories = append([]DirectoryMapping(nil), c.Directories...)
+	out.Overrides = append([]RepoOverride(nil), c.Overrides...)
+	out.OrgApps = append([]OrgApp(nil), c.OrgApps...)
+	out.Integrations = append([]Integration(nil), c.Integrations...)
+	out.contractPaths()
+	out.ConfigVersion = configVersion
+
+	data, err := json.MarshalIndent(&out, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
 	configPath := getConfigPath()
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write config: %w", err)
-	}
+	return withFileLock(configPath, func() error {
+		if err := backupConfigFile(configPath); err != nil {
+			return fmt.Errorf("failed to back up config: %w", err)
+		}
+		trackFile(configPath)
+		if err := writeFileAtomic(configPath, data, 0600); err != nil {
+			return fmt.Errorf("failed to write config: %w", err)
+		}
+		return nil
+	})
+}
 
-	return nil
+// updateConfig runs a read-modify-write of config.json under its lock: it
+// loads the config, lets fn change it and saves it unless fn fails, so
+// concurrent krakn runs and the watch daemon cannot lose each other's
+// changes. fn must not prompt; ask first, then apply the answer in fn.
+func updateConfig(fn func(config *Config) error) error {
+	return withFileLock(getConfigPath(), func() error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if err := fn(config); err != nil {
+			return err
+		}
+		if err := config.saveConfig(); err != nil {
+			return errSaveConfig(err)
+		}
+		return nil
+	})
+}
+
+// update applies fn to config.json with updateConfig and then to c, so c
+// shows the change without it overwriting what other runs saved meanwhile.
+// fn is called twice and must only set values, e.g. by account name.
+func (c *Config) update(fn func(config *Config) error) error {
+	if err := updateConfig(fn); err != nil {
+		return err
+	}
+	return fn(c)
 }
 
+// addAccount adds or replaces an account in config.json and in c
 func (c *Config) addAccount(account Account) error {
+	return c.update(func(config *Config) error {
+		config.putAccount(account)
+		return nil
+	})
+}
+
+// putAccount adds or replaces an account without saving
+func (c *Config) putAccount(account Account) {
 	// Check if account already exists
 	for i, existing := range c.Accounts {
 		if existing.Name == account.Name {
 			c.Accounts[i] = account
-			return c.saveConfig()
+			return
 		}
 	}
 
 	// Add new account
 	c.Accounts = append(c.Accounts, account)
-	
+
 	// Set as default if it's the first account
 	if len(c.Accounts) == 1 {
 		account.IsDefault = true
 		c.CurrentAccount = account.Name
 		c.Accounts[0] = account
 	}
-
-	return c.saveConfig()
 }
 
 func (c *Config) getAccount(name string) *Account {
@@ -105,12 +256,81 @@ func (c *Config) getAccount(name string) *Account {
 	return nil
 }
 
+// GetProvider returns the account's provider, defaulting to GitHub
+func (a *Account) GetProvider() Provider {
+	if a.Provider != nil {
+		return *a.Provider
+	}
+	return DefaultProviders["github"]
+}
+
+// GetSSHHost returns the SSH host alias used for the account
+func (a *Account) GetSSHHost() string {
+	if a.SSHHost != "" {
+		return a.SSHHost
+	}
+	return fmt.Sprintf("%s-%s", a.GetProvider().Hostname, a.Name)
+}
+
+// getDirectoryMapping returns the mapping whose includeIf pattern matches the
+// absolute directory path (see DirectoryMapping.patternDir)
+func (c *Config) getDirectoryMapping(path string) *DirectoryMapping {
+	for i := range c.Directories {
+		if c.Directories[i].patternDir() == path {
+			return &c.Directories[i]
+		}
+	}
+	return nil
+}
+
+// setDirectoryMapping adds or replaces the mapping for a directory in
+// config.json and in c
+func (c *Config) setDirectoryMapping(mapping DirectoryMapping) error {
+	return c.update(func(config *Config) error {
+		config.putDirectoryMapping(mapping)
+		return nil
+	})
+}
+
+// putDirectoryMapping adds or replaces the mapping for a directory without saving
+func (c *Config) putDirectoryMapping(mapping DirectoryMapping) {
+	if existing := c.getDirectoryMapping(mapping.patternDir()); existing != nil {
+		*existing = mapping
+	} else {
+		c.Directories = append(c.Directories, mapping)
+	}
+}
+
+// removeDirectoryMapping forgets the mapping for a directory in config.json
+// and in c
+func (c *Config) removeDirectoryMapping(path string) error {
+	if c.getDirectoryMapping(path) == nil {
+		return nil
+	}
+	return c.update(func(config *Config) error {
+		config.dropDirectoryMapping(path)
+		return nil
+	})
+}
+
+// dropDirectoryMapping forgets the mapping for a directory without saving
+func (c *Config) dropDirectoryMapping(path string) {
+	for i := range c.Directories {
+		if c.Directories[i].patternDir() == path {
+			c.Directories = append(c.Directories[:i], c.Directories[i+1:]...)
+			return
+		}
+	}
+}
+
 func (c *Config) setCurrentAccount(name string) error {
 	account := c.getAccount(name)
 	if account == nil {
-		return fmt.Errorf("account '%s' not found", name)
+		return c.accountNotFound(name)
 	}
 
-	c.CurrentAccount = name
-	return c.saveConfig()
+	return c.update(func(config *Config) error {
+		config.CurrentAccount = name
+		return nil
+	})
 }
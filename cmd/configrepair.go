@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// maxConfigBackups is how many earlier versions of config.json are kept as
+// config.json.bak.1 (newest) to config.json.bak.N
+const maxConfigBackups = 3
+
+// configBackedUp is set once this run rotated the backups, so a command that
+// saves several times keeps the version from before it ran
+var configBackedUp bool
+
+func configBackupPath(n int) string {
+	return fmt.Sprintf("%s.bak.%d", getConfigPath(), n)
+}
+
+// backupConfigFile runs before config.json is written. A valid file is
+// rotated into the backups, once per run. A file that does not parse is never
+// overwritten without a copy: it is kept as config.json.corrupt-<time>.
+func backupConfigFile(configPath string) error {
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := parseConfig(data); err != nil {
+		return preserveCorruptConfig(data)
+	}
+	if configBackedUp {
+		return nil
+	}
+	configBackedUp = true
+	os.Remove(configBackupPath(maxConfigBackups))
+	for n := maxConfigBackups - 1; n >= 1; n-- {
+		os.Rename(configBackupPath(n), configBackupPath(n+1))
+	}
+	return writePrivateFile(configBackupPath(1), data)
+}
+
+// preserveCorruptConfig copies a config.json that does not parse next to it
+func preserveCorruptConfig(data []byte) error {
+	path := getConfigPath() + ".corrupt-" + time.Now().Format("20060102-150405")
+	if err := writePrivateFile(path, data); err != nil {
+		return fmt.Errorf("failed to keep the damaged config: %w", err)
+	}
+	fmt.Printf("💾 Damaged config kept as %s\n", contractHomePath(path))
+	return nil
+}
+
+// configBackup is a backup of config.json that parses
+type configBackup struct {
+	Path   string
+	Time   time.Time
+	Data   []byte
+	Config *Config
+}
+
+// configBackups returns the valid backups, newest first
+func configBackups() []configBackup {
+	var backups []configBackup
+	for n := 1; n <= maxConfigBackups; n++ {
+		path := configBackupPath(n)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		config, err := parseConfig(data)
+		if err != nil {
+			continue
+		}
+		info, _ := os.Stat(path)
+		backups = append(backups, configBackup{Path: path, Time: info.ModTime(), Data: data, Config: config})
+	}
+	return backups
+}
+
+// corruptConfig returns the content of config.json and why it does not
+// parse; the error is nil when the file is missing or valid
+func corruptConfig() ([]byte, error) {
+	data, err := os.ReadFile(getConfigPath())
+	if err != nil {
+		return nil, nil
+	}
+	if _, err := parseConfig(data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+// salvageObjects decodes every complete JSON object after the first
+// occurrence of "key" and before stop, trying again after each opening brace
+// when one does not decode. accept unmarshals an object and reports whether
+// it is an entry worth keeping; kept objects are skipped as a whole.
+func salvageObjects(data []byte, key, stop string, accept func(raw json.RawMessage) bool) {
+	start := bytes.Index(data, []byte(`"`+key+`"`))
+	if start == -1 {
+		return
+	}
+	region := data[start:]
+	if end := bytes.Index(region, []byte(`"`+stop+`"`)); stop != "" && end != -1 {
+		region = region[:end]
+	}
+	for i := 0; i < len(region); i++ {
+		if region[i] != '{' {
+			continue
+		}
+		var raw json.RawMessage
+		decoder := json.NewDecoder(bytes.NewReader(region[i:]))
+		if decoder.Decode(&raw) == nil && accept(raw) {
+			i += int(decoder.InputOffset()) - 1
+		}
+	}
+}
+
+// salvageConfig recovers the accounts, directory mappings and sealing
+// settings that are still intact in a damaged config.json
+func salvageConfig(data []byte) *Config {
+	config := &Config{Accounts: []Account{}, MigrationDone: true}
+	salvageObjects(data, "accounts", "current_account", func(raw json.RawMessage) bool {
+		var account Account
+		if json.Unmarshal(raw, &account) != nil || account.Name == "" || config.getAccount(account.Name) != nil {
+			return false
+		}
+		// Nested objects such as the provider have a name but none of these
+		if account.Email == "" && account.SSHKey == "" && len(account.Sealed) == 0 {
+			return false
+		}
+		config.Accounts = append(config.Accounts, account)
+		return true
+	})
+	salvageObjects(data, "directories", "", func(raw json.RawMessage) bool {
+		var mapping DirectoryMapping
+		if json.Unmarshal(raw, &mapping) != nil || mapping.Path == "" || mapping.ConfigFile == "" || config.getAccount(mapping.Account) == nil {
+			return false
+		}
+		config.Directories = append(config.Directories, mapping)
+		return true
+	})
+
+	salvageObjects(data, "sealing", "", func(raw json.RawMessage) bool {
+		var sealing SealConfig
+		if config.Sealing != nil || json.Unmarshal(raw, &sealing) != nil || sealing.Method == "" {
+			return false
+		}
+		config.Sealing = &sealing
+		return true
+	})
+
+	var current struct {
+		CurrentAccount string `json:"current_account"`
+	}
+	if start := bytes.Index(data, []byte(`"current_account"`)); start != -1 {
+		if end := bytes.IndexAny(data[start:], ",}\n"); end != -1 {
+			json.Unmarshal([]byte("{"+string(data[start:start+end])+"}"), &current)
+		}
+	}
+	if config.getAccount(current.CurrentAccount) != nil {
+		config.CurrentAccount = current.CurrentAccount
+	}
+	config.expandPaths()
+	config.upgradeAuthMethods()
+	config.upgradeProviders()
+	return config
+}
+
+// accountNames lists the names of the config's accounts for a prompt
+func accountNames(config *Config) string {
+	var names []string
+	for _, account := range config.Accounts {
+		names = append(names, account.Name)
+	}
+	if len(names) == 0 {
+		return "no accounts"
+	}
+	return strings.Join(names, ", ")
+}
+
+// restoreConfigBackup writes a backup back as config.json, keeping the
+// damaged file next to it
+func restoreConfigBackup(damaged []byte, backup configBackup) error {
+	if err := preserveCorruptConfig(damaged); err != nil {
+		return err
+	}
+	configPath := getConfigPath()
+	return withFileLock(configPath, func() error {
+		trackFile(configPath)
+		if err := writeFileAtomic(configPath, backup.Data, 0600); err != nil {
+			return fmt.Errorf("failed to write config: %w", err)
+		}
+		return nil
+	})
+}
+
+// repairConfig offers the ways to recover a config.json that does not parse:
+// restoring a backup, salvaging the intact entries or starting over
+func repairConfig(reader *bufio.Reader, data []byte, parseErr error) error {
+	fmt.Printf("⚠️  %s is damaged: %v\n", contractHomePath(getConfigPath()), parseErr)
+	backups := configBackups()
+	salvaged := salvageConfig(data)
+
+	fmt.Println("\n🩹 How do you want to repair it?")
+	for i, backup := range backups {
+		fmt.Printf("  [%d] Restore the backup from %s (%s)\n", i+1, backup.Time.Format("2006-01-02 15:04"), accountNames(backup.Config))
+	}
+	if len(salvaged.Accounts) > 0 {
+		fmt.Printf("  [s] Salvage what is intact (%s, %d directory mapping(s))\n", accountNames(salvaged), len(salvaged.Directories))
+	}
+	fmt.Println("  [r] Start over with an empty config")
+	fmt.Println("  [q] Leave it as it is and fix it by hand")
+	fmt.Print("💬 Choice [q]: ")
+	answer, _ := reader.ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	switch {
+	case answer == "" || answer == "q":
+		fmt.Println("⏭️  Left unchanged. 'krakn repair-config' offers this again")
+		return nil
+	case answer == "s" && len(salvaged.Accounts) > 0:
+		if err := salvaged.saveConfig(); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("✅ Recovered %s\n", accountNames(salvaged))
+		fmt.Println("💡 Overrides and other settings were not recovered; 'krakn doctor' shows what is missing")
+		return nil
+	case answer == "r":
+		fmt.Print("⚠️  Every account and setting in the file is dropped (the file is kept aside). Continue? [y/N]: ")
+		confirm, _ := reader.ReadString('\n')
+		if confirm = strings.ToLower(strings.TrimSpace(confirm)); confirm != "y" && confirm != "yes" {
+			fmt.Println("⏭️  Left unchanged")
+			return nil
+		}
+		empty := &Config{Accounts: []Account{}, MigrationDone: true}
+		if err := empty.saveConfig(); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Println("✅ Started over with an empty config. Add accounts with 'krakn add' or 'krakn migrate'")
+		return nil
+	}
+	n, err := strconv.Atoi(answer)
+	if err != nil || n < 1 || n > len(backups) {
+		return fmt.Errorf("❌ Invalid choice: %s", answer)
+	}
+	if err := restoreConfigBackup(data, backups[n-1]); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Restored %s (%s)\n", contractHomePath(backups[n-1].Path), accountNames(backups[n-1].Config))
+	return nil
+}
+
+// offerConfigRepair runs the repair prompt before a command when config.json
+// does not parse and someone is there to answer it
+func offerConfigRepair() {
+	data, parseErr := corruptConfig()
+	if parseErr == nil || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return
+	}
+	if err := repairConfig(bufio.NewReader(os.Stdin), data, parseErr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	fmt.Println()
+}
+
+var repairConfigCmd = &cobra.Command{
+	Use:   "repair-config",
+	Short: "Repair a damaged config.json from a backup or what is still intact",
+	Long: `Repair ~/.krakncat/config.json when it is no longer valid JSON, after a
+manual edit or an interrupted sync. Interactive commands offer the same repair
+as soon as they find the file damaged.
+
+Every krakn run that changes the config first keeps the previous version as
+config.json.bak.1; the last three are kept. The repair can restore one of them,
+salvage the accounts and directory mappings that are still intact in the
+damaged file, or start over with an empty config. The damaged file is always
+kept aside as config.json.corrupt-<time>.
+
+Examples:
+  krakn repair-config              # Choose interactively
+  krakn repair-config --restore 1  # Restore the newest backup
+  krakn repair-config --salvage
+  krakn repair-config --reset`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		restore, _ := cmd.Flags().GetInt("restore")
+		salvage, _ := cmd.Flags().GetBool("salvage")
+		reset, _ := cmd.Flags().GetBool("reset")
+
+		data, parseErr := corruptConfig()
+		if parseErr == nil {
+			fmt.Printf("✅ %s is valid\n", contractHomePath(getConfigPath()))
+			for _, backup := range configBackups() {
+				fmt.Printf("   💾 %s from %s (%s)\n", contractHomePath(backup.Path), backup.Time.Format("2006-01-02 15:04"), accountNames(backup.Config))
+			}
+			return nil
+		}
+
+		switch {
+		case restore > 0:
+			backups := configBackups()
+			if restore > len(backups) {
+				return fmt.Errorf("❌ There are %d valid backup(s), not %d", len(backups), restore)
+			}
+			if err := restoreConfigBackup(data, backups[restore-1]); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Restored %s (%s)\n", contractHomePath(backups[restore-1].Path), accountNames(backups[restore-1].Config))
+		case salvage:
+			salvaged := salvageConfig(data)
+			if len(salvaged.Accounts) == 0 {
+				return fmt.Errorf("❌ No intact account found in %s", contractHomePath(getConfigPath()))
+			}
+			if err := salvaged.saveConfig(); err != nil {
+				return errSaveConfig(err)
+			}
+			fmt.Printf("✅ Recovered %s\n", accountNames(salvaged))
+		case reset:
+			empty := &Config{Accounts: []Account{}, MigrationDone: true}
+			if err := empty.saveConfig(); err != nil {
+				return errSaveConfig(err)
+			}
+			fmt.Println("✅ Started over with an empty config")
+		default:
+			if !term.IsTerminal(int(os.Stdin.Fd())) {
+				return fmt.Errorf("❌ %s is damaged (%v); pass --restore <n>, --salvage or --reset", contractHomePath(getConfigPath()), parseErr)
+			}
+			if err := repairConfig(bufio.NewReader(os.Stdin), data, parseErr); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	repairConfigCmd.Flags().Int("restore", 0, "Restore backup n (1 is the newest)")
+	repairConfigCmd.Flags().Bool("salvage", false, "Keep the accounts and directory mappings that are still intact")
+	repairConfigCmd.Flags().Bool("reset", false, "Start over with an empty config (the damaged file is kept aside)")
+	repairConfigCmd.MarkFlagsMutuallyExclusive("restore", "salvage", "reset")
+	RootCmd.AddCommand(repairConfigCmd)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// currentAccountInfo is the account in effect in a directory, as printed by
+// 'krakn current --output json|yaml'
+type currentAccountInfo struct {
+	SchemaVersion int    `json:"schema_version"`
+	Account       string `json:"account,omitempty"`
+	Reason        string `json:"reason,omitempty" desc:"Why the account applies, e.g. directory mapping"`
+	Name          string `json:"name,omitempty"`
+	Email         string `json:"email,omitempty"`
+	Provider      string `json:"provider,omitempty"`
+	SSHHost       string `json:"ssh_host,omitempty"`
+	Global        string `json:"global,omitempty" desc:"The global current account"`
+}
+
+var currentCmd = &cobra.Command{
+	Use:   "current",
+	Short: "Show the account in effect in the current directory",
+	Long: `Show which account git uses in the current directory: the account whose
+email is the effective user.email, else the repository override, the directory
+mapping or the global current account. Meant for prompts and status lines.
+
+Examples:
+  krakn current
+  krakn current --output json   # {"account":"work","reason":"directory mapping",...}`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+
+		info := currentAccountInfo{SchemaVersion: schemaVersion("current"), Global: config.CurrentAccount}
+		account, reason := envAccount(config, dir)
+		if account != nil {
+			info.Account = account.Name
+			info.Reason = reason
+			info.Name = account.CommitName()
+			info.Provider = account.GetProvider().Name
+			info.SSHHost = account.GetSSHHost()
+			if !account.isSealed("email") {
+				info.Email = account.Email
+			}
+		}
+
+		if structuredOutput() {
+			return printStructured(info)
+		}
+		if account == nil {
+			fmt.Println("🚫 No account in effect here")
+			fmt.Println("💡 Use 'krakn use <account>' to select one")
+			return nil
+		}
+		fmt.Printf("👤 %s (%s)\n", info.Account, info.Reason)
+		if info.Email != "" {
+			fmt.Printf("📧 Email: %s\n", info.Email)
+		}
+		fmt.Printf("🔗 SSH Host: %s\n", info.SSHHost)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(currentCmd)
+	registerOutputSchema(outputSchema{Command: "current", Version: 1, Description: "The account in effect in the working directory and why", Type: currentAccountInfo{}})
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// duplicateAccounts is a pair of accounts that look like the same identity,
+// typically left behind by running 'krakn migrate' twice
+type duplicateAccounts struct {
+	A, B    Account
+	Reasons []string // What they share: "SSH key", "email", "SSH alias"
+}
+
+// sameKey reports whether two key paths are the same file or hold the same key
+func sameKey(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	_, fingerprintA, errA := describePublicKey(a + ".pub")
+	_, fingerprintB, errB := describePublicKey(b + ".pub")
+	return errA == nil && errB == nil && fingerprintA == fingerprintB
+}
+
+// findDuplicateAccounts returns the pairs of accounts on the same provider
+// that share an SSH key, an email or a Host alias. The same email or key on
+// two providers is normal and not reported.
+func findDuplicateAccounts(config *Config) []duplicateAccounts {
+	var duplicates []duplicateAccounts
+	for i := 0; i < len(config.Accounts); i++ {
+		for j := i + 1; j < len(config.Accounts); j++ {
+			a, b := config.Accounts[i], config.Accounts[j]
+			if a.GetProvider().Hostname != b.GetProvider().Hostname {
+				continue
+			}
+			var reasons []string
+			if !a.usesHTTPS() && !b.usesHTTPS() && sameKey(a.SSHKey, b.SSHKey) {
+				reasons = append(reasons, "SSH key")
+			}
+			if a.Email != "" && strings.EqualFold(a.Email, b.Email) {
+				reasons = append(reasons, "email")
+			}
+			if a.GetSSHHost() == b.GetSSHHost() {
+				reasons = append(reasons, "SSH alias")
+			}
+			if len(reasons) > 0 {
+				duplicates = append(duplicates, duplicateAccounts{A: a, B: b, Reasons: reasons})
+			}
+		}
+	}
+	return duplicates
+}
+
+// joinWords renders "a", "a and b", "a, b and c"
+func joinWords(words []string) string {
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
+
+// checkDuplicateAccounts warns about accounts that look like the same identity
+func checkDuplicateAccounts(ctx *doctorContext) []doctorFinding {
+	var findings []doctorFinding
+	for _, pair := range findDuplicateAccounts(ctx.Config) {
+		findings = append(findings, doctorFinding{
+			Level:   doctorWarn,
+			Message: fmt.Sprintf("Accounts '%s' and '%s' share the same %s", pair.A.Name, pair.B.Name, joinWords(pair.Reasons)),
+			Hint:    "krakn dedupe",
+		})
+	}
+	return findings
+}
+
+// canonicalAccount picks the account to keep from a pair: the one more
+// mappings and rules refer to, or else the older one
+func canonicalAccount(config *Config, pair duplicateAccounts) (keep, fold Account) {
+	if len(accountReferences(config, pair.B.Name)) > len(accountReferences(config, pair.A.Name)) {
+		return pair.B, pair.A
+	}
+	return pair.A, pair.B
+}
+
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Find and merge duplicate accounts",
+	Long: `Find accounts on the same provider that share an SSH key (the same file or
+the same fingerprint), an email or an SSH Host alias, as left behind by running
+'krakn migrate' twice, and merge each pair into one canonical account.
+
+For every pair the accounts are compared field by field and you choose which
+to keep; the suggestion is the one more mappings and rules use, or else the
+older one. The merge works like 'krakn diff <keep> <other> --merge': mappings,
+overrides and rules are retargeted to the kept account and the other one is
+removed. SSH key files are never deleted.
+
+Examples:
+  krakn dedupe          # Guided merge of every duplicate
+  krakn dedupe --list   # Only show the duplicates
+  krakn dedupe --yes    # Keep the suggested account of every pair without asking`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		list, _ := cmd.Flags().GetBool("list")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		duplicates := findDuplicateAccounts(config)
+		if len(duplicates) == 0 {
+			fmt.Println("✅ No duplicate accounts")
+			return nil
+		}
+		if list {
+			for _, pair := range duplicates {
+				keep, fold := canonicalAccount(config, pair)
+				fmt.Printf("👯 '%s' and '%s' share the same %s\n", pair.A.Name, pair.B.Name, joinWords(pair.Reasons))
+				fmt.Printf("   💡 krakn diff %s %s --merge\n", keep.Name, fold.Name)
+			}
+			return nil
+		}
+		if !yes && !term.IsTerminal(int(os.Stdin.Fd())) {
+			return fmt.Errorf("❌ Found %d duplicate pair(s); merging needs a terminal. Pass --list to show them or --yes to merge", len(duplicates))
+		}
+
+		reader := bufio.NewReader(os.Stdin)
+		skipped := map[string]bool{}
+		merged := 0
+		for {
+			// Every merge removes an account, so the pairs are found again
+			var pair *duplicateAccounts
+			for _, candidate := range findDuplicateAccounts(config) {
+				if !skipped[candidate.A.Name+"\x00"+candidate.B.Name] {
+					pair = &candidate
+					break
+				}
+			}
+			if pair == nil {
+				break
+			}
+
+			fmt.Printf("\n%s\n👯 '%s' and '%s' share the same %s\n\n", strings.Repeat("─", 60), pair.A.Name, pair.B.Name, joinWords(pair.Reasons))
+			printAccountDiff(config, pair.A, pair.B)
+			keep, fold := canonicalAccount(config, *pair)
+			if !yes {
+				fmt.Printf("\n💬 Keep [1] %s, [2] %s or [s]kip? [%s]: ", pair.A.Name, pair.B.Name, keep.Name)
+				input, _ := reader.ReadString('\n')
+				switch strings.ToLower(strings.TrimSpace(input)) {
+				case "":
+				case "1", strings.ToLower(pair.A.Name):
+					keep, fold = pair.A, pair.B
+				case "2", strings.ToLower(pair.B.Name):
+					keep, fold = pair.B, pair.A
+				case "s", "skip":
+					skipped[pair.A.Name+"\x00"+pair.B.Name] = true
+					continue
+				default:
+					return fmt.Errorf("❌ Invalid choice: %s", strings.TrimSpace(input))
+				}
+			}
+			before := len(config.Accounts)
+			if err := runMerge(config, keep, fold, yes); err != nil {
+				return err
+			}
+			if len(config.Accounts) == before {
+				// Merge cancelled
+				skipped[pair.A.Name+"\x00"+pair.B.Name] = true
+				continue
+			}
+			merged++
+		}
+
+		fmt.Println()
+		if merged == 0 {
+			fmt.Println("⏭️  No accounts merged")
+		} else {
+			fmt.Printf("🎉 Merged %d duplicate account(s)\n", merged)
+		}
+		return nil
+	},
+}
+
+func init() {
+	dedupeCmd.Flags().Bool("list", false, "Only list the duplicate accounts")
+	dedupeCmd.Flags().BoolP("yes", "y", false, "Keep the suggested account of every pair without asking")
+	RootCmd.AddCommand(dedupeCmd)
+	registerDoctorCheck(doctorCheck{Name: "Duplicate accounts", Run: checkDuplicateAccounts})
+}
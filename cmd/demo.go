@@ -0,0 +1,285 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+// builtinProvider returns a predefined provider by name. The sandbox provider
+// points at the server 'krakn demo' runs, whose port is in KRAKN_SANDBOX_PORT.
+func builtinProvider(name string) (Provider, bool) {
+	provider, ok := DefaultProviders[strings.ToLower(name)]
+	if ok && provider.Name == "sandbox" {
+		provider.SSHPort = os.Getenv("KRAKN_SANDBOX_PORT")
+	}
+	return provider, ok
+}
+
+// sandboxServer is an in-process SSH server standing in for a provider. It
+// accepts the keys of the sandbox accounts configured in its home directory
+// and greets each with its username, as GitHub does for 'ssh -T'.
+type sandboxServer struct {
+	home     string
+	listener net.Listener
+	config   *ssh.ServerConfig
+}
+
+func startSandboxServer(home string) (*sandboxServer, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	hostKey, err := ssh.NewSignerFromKey(private)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the sandbox server: %w", err)
+	}
+
+	server := &sandboxServer{home: home, listener: listener}
+	server.config = &ssh.ServerConfig{PublicKeyCallback: server.authenticate}
+	server.config.AddHostKey(hostKey)
+	go server.serve()
+	return server, nil
+}
+
+// port is the port the server listens on
+func (s *sandboxServer) port() string {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return port
+}
+
+func (s *sandboxServer) Close() error {
+	return s.listener.Close()
+}
+
+// authenticate accepts a key when a sandbox account in the sandbox's config
+// uses it, the way a provider accepts the keys uploaded to a user
+func (s *sandboxServer) authenticate(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+	data, err := os.ReadFile(filepath.Join(s.home, ".krakncat", "config.json"))
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	for _, account := range config.Accounts {
+		if account.GetProvider().Name != "sandbox" || account.SSHKey == "" {
+			continue
+		}
+		// Paths in the config are relative to the sandbox home, not this process's
+		keyPath := account.SSHKey
+		if strings.HasPrefix(keyPath, "~/") {
+			keyPath = filepath.Join(s.home, keyPath[2:])
+		}
+		public, err := os.ReadFile(keyPath + ".pub")
+		if err != nil {
+			continue
+		}
+		known, _, _, _, err := ssh.ParseAuthorizedKey(public)
+		if err == nil && bytes.Equal(known.Marshal(), key.Marshal()) {
+			return &ssh.Permissions{Extensions: map[string]string{"username": account.Username}}, nil
+		}
+	}
+	return nil, fmt.Errorf("key %s belongs to no sandbox account", ssh.FingerprintSHA256(key))
+}
+
+func (s *sandboxServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle greets the authenticated user on every session and closes it, as
+// providers do for shell requests. There are no repositories to serve.
+func (s *sandboxServer) handle(conn net.Conn) {
+	defer conn.Close()
+	server, channels, requests, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		return
+	}
+	defer server.Close()
+	go ssh.DiscardRequests(requests)
+	username := server.Permissions.Extensions["username"]
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "the sandbox only opens sessions")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer channel.Close()
+			for request := range requests {
+				switch request.Type {
+				case "shell", "exec":
+					request.Reply(true, nil)
+					if request.Type == "exec" {
+						fmt.Fprintln(channel.Stderr(), "krakn sandbox: there are no repositories to serve")
+					}
+					fmt.Fprintf(channel.Stderr(), "Hi %s! You've authenticated to the krakn sandbox, which does not provide shell access.\n", username)
+					channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{1}))
+					return
+				default:
+					request.Reply(request.Type == "pty-req" || request.Type == "env", nil)
+				}
+			}
+		}()
+	}
+}
+
+var demoSteps = []tutorialStep{
+	{
+		Title: "Add a work and a personal account on the sandbox provider",
+		Run: func(s *tutorialSandbox) error {
+			if err := s.krakn("", "work\nalice@acme.example\nalice-acme\n\ny\ny\n", "add", "--provider", "sandbox"); err != nil {
+				return err
+			}
+			return s.krakn("", "personal\nalice@home.example\nalice\n\ny\ny\n", "add", "--provider", "sandbox")
+		},
+	},
+	{
+		Title: "Verify which user each key authenticates as",
+		Run: func(s *tutorialSandbox) error {
+			return s.krakn("", "", "test", "--accept-new")
+		},
+	},
+	{
+		Title: "Make personal the default",
+		Run: func(s *tutorialSandbox) error {
+			return s.krakn("", "", "use", "personal")
+		},
+	},
+	{
+		Title: "Map ~/work to the work account",
+		Run: func(s *tutorialSandbox) error {
+			if err := os.MkdirAll(filepath.Join(s.Home, "work", "api"), 0755); err != nil {
+				return err
+			}
+			return s.krakn("", "", "config", filepath.Join(s.Home, "work"), "work")
+		},
+	},
+	{
+		Title: "Check the identity of a work repository",
+		Run: func(s *tutorialSandbox) error {
+			for _, args := range [][]string{
+				{"init", "--quiet"},
+				{"remote", "add", "origin", "git@localhost-work:acme/api.git"},
+				{"config", "user.email"},
+			} {
+				if _, err := s.git("work/api", args...); err != nil {
+					return err
+				}
+			}
+			return s.krakn("work/api", "", "current")
+		},
+	},
+	{
+		Title: "Check every account",
+		Run: func(s *tutorialSandbox) error {
+			return s.krakn("", "", "list", "--check")
+		},
+	},
+}
+
+var demoCmd = &cobra.Command{
+	Use:   "demo",
+	Short: "Run krakn's main flows against a built-in sandbox provider",
+	Long: `Run the add, test, use and config flows end to end in a throwaway home
+directory, against a mock SSH server started inside krakn. The server stands
+in for a provider called "sandbox" on localhost: it accepts the keys of the
+sandbox's accounts and greets each with its username, so 'krakn test' verifies
+identities without any real account or network access.
+
+Nothing outside the sandbox is read or changed. The exit status is non-zero
+when a step fails, so CI can run the demo as a smoke test. 'krakn tutorial'
+explains the same flows step by step.
+
+With --serve, the server keeps running after the demo until Ctrl-C, and the
+sandbox is kept so you can try commands in it yourself.
+
+Examples:
+  krakn demo
+  krakn demo --keep
+  krakn demo --serve`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keep, _ := cmd.Flags().GetBool("keep")
+		serve, _ := cmd.Flags().GetBool("serve")
+		keep = keep || serve
+		cmd.SilenceUsage = true
+
+		sandbox, err := newTutorialSandbox()
+		if err != nil {
+			return err
+		}
+		server, err := startSandboxServer(sandbox.Home)
+		if err != nil {
+			os.RemoveAll(sandbox.Home)
+			return err
+		}
+		defer server.Close()
+		sandbox.env = append(sandbox.env, "KRAKN_SANDBOX_PORT="+server.port())
+		failed := false
+		defer func() {
+			if !keep && !failed {
+				os.RemoveAll(sandbox.Home)
+			}
+		}()
+
+		fmt.Println("🧪 krakn demo")
+		fmt.Printf("   Sandbox home: %s (shown as ~ below)\n", sandbox.Home)
+		fmt.Printf("   Sandbox provider: localhost:%s\n", server.port())
+		for i, step := range demoSteps {
+			fmt.Printf("\n▶️  %d/%d %s\n", i+1, len(demoSteps), step.Title)
+			if err := step.Run(sandbox); err != nil {
+				failed = true
+				return fmt.Errorf("❌ Step %d failed: %w (sandbox kept at %s)", i+1, err, sandbox.Home)
+			}
+		}
+		fmt.Println("\n✅ All steps passed")
+
+		if !serve {
+			if keep {
+				fmt.Printf("📁 Sandbox kept at %s\n", sandbox.Home)
+			}
+			return nil
+		}
+		fmt.Printf("\n🧪 The sandbox provider keeps running on localhost:%s. In another shell:\n", server.port())
+		fmt.Printf("   export HOME=%s KRAKN_SANDBOX_PORT=%s\n", shellQuote(sandbox.Home), server.port())
+		fmt.Println("   krakn list; krakn test; krakn add --provider sandbox")
+		fmt.Println("⏹️  Press Ctrl-C to stop")
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		<-interrupt
+		fmt.Printf("\n📁 Sandbox kept at %s\n", sandbox.Home)
+		return nil
+	},
+}
+
+func init() {
+	demoCmd.Flags().Bool("keep", false, "Keep the sandbox directory afterwards")
+	demoCmd.Flags().Bool("serve", false, "Keep the sandbox provider running until Ctrl-C (implies --keep)")
+	RootCmd.AddCommand(demoCmd)
+}
@@ -17,14 +17,60 @@ var dirConfigCmd = &cobra.Command{
 If no arguments provided, interactively configures the current directory.
 If directory and account provided, configures that directory for the account.
 
+Use --move when a configured directory has been relocated: the includeIf
+pattern, the include file path and the stored mapping are all rewritten.
+
+Use --name and --email to commit under another name or email in the directory
+while keeping the account's key, e.g. the same work account with a client's
+legal-entity email. They are stored in the mapping and written into its
+include file; map the directory again without them to go back.
+
+Use --existing-repos to leave the global .gitconfig untouched: instead of an
+includeIf for the whole directory, user.name and user.email are written into
+the local config of every git repository currently below it. Repositories
+cloned later are not covered; run the command again to pick them up.
+
+Linked worktrees ('git worktree add') are matched by git through their git
+directory, <repo>/.git/worktrees/<name>, not by where the worktree is checked
+out. Use --worktrees with a name glob to give worktrees of one repository (or
+a bare repository) their own identity; the name is the worktree directory's
+base name. The includeIf entry written is:
+
+  [includeIf "gitdir:~/mono/.git/worktrees/client-a-*"]
+          path = ~/mono/.git/krakn-work.gitconfig
+
 Examples:
   krakn config                     # Interactive setup for current directory
   krakn config ~/work personal     # Setup ~/work for 'personal' account
-  krakn config . work              # Setup current directory for 'work' account`,
+  krakn config . work              # Setup current directory for 'work' account
+  krakn config --move ~/work ~/clients/acme  # Retarget a relocated directory
+  krakn config ~/mono acme --worktrees 'acme-*' # Worktrees named acme-* use 'acme'
+  krakn config ~/work work --existing-repos     # Write the identity into each repo's own config
+  krakn config ~/clients/acme work --email alice@acme-gmbh.example  # Same account, another email`,
 	Args: cobra.MaximumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		worktrees, _ := cmd.Flags().GetString("worktrees")
+		existingRepos, _ := cmd.Flags().GetBool("existing-repos")
+		name, _ := cmd.Flags().GetString("name")
+		email, _ := cmd.Flags().GetString("email")
+		if existingRepos && worktrees != "" {
+			return fmt.Errorf("--existing-repos cannot be combined with --worktrees")
+		}
+		if existingRepos && (name != "" || email != "") {
+			return fmt.Errorf("--name and --email are stored in a directory mapping, which --existing-repos does not create")
+		}
+		if move, _ := cmd.Flags().GetBool("move"); move {
+			if worktrees != "" || existingRepos || name != "" || email != "" {
+				return fmt.Errorf("--move cannot be combined with --worktrees, --existing-repos, --name or --email")
+			}
+			if len(args) != 2 {
+				return fmt.Errorf("--move requires the old and the new directory")
+			}
+			return moveDirectoryConfig(args[0], args[1])
+		}
+
 		// Interactive mode (no arguments)
-		if len(args) == 0 {
+		if len(args) == 0 && worktrees == "" && !existingRepos && name == "" && email == "" {
 			return interactiveDirectoryConfig()
 		}
 
@@ -42,32 +88,49 @@ Examples:
 			return fmt.Errorf("failed to resolve directory path: %w", err)
 		}
 
-		// Ensure directory exists
-		if err := os.MkdirAll(absPath, 0755); err != nil {
+		// Worktree mappings need an existing repository
+		var commonDir string
+		if worktrees != "" {
+			if strings.ContainsAny(worktrees, `/\`) {
+				return fmt.Errorf("❌ --worktrees takes a worktree name pattern such as 'client-*', not a path")
+			}
+			if commonDir, err = repoCommonDir(absPath); err != nil {
+				return err
+			}
+		} else if existingRepos {
+			if info, err := os.Stat(absPath); err != nil || !info.IsDir() {
+				return fmt.Errorf("❌ Directory not found: %s", absPath)
+			}
+		} else if err := os.MkdirAll(absPath, 0755); err != nil {
+			// Ensure directory exists
 			return fmt.Errorf("failed to create directory: %w", err)
 		}
 
 		// Load config to get account details
 		config, err := loadConfig()
 		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+			return errLoadConfig(err)
 		}
 
 		account := config.getAccount(accountName)
 		if account == nil {
-			if len(config.Accounts) == 0 {
-				return fmt.Errorf("❌ No accounts configured. Use 'krakn add' to add accounts first")
-			}
-			
-			var availableNames []string
-			for _, acc := range config.Accounts {
-				availableNames = append(availableNames, acc.Name)
-			}
-			return fmt.Errorf("❌ Account '%s' not found. Available accounts: %s", 
-				accountName, strings.Join(availableNames, ", "))
+			return config.accountNotFound(accountName)
+		}
+
+		if err := config.revealAccount(account); err != nil {
+			return err
 		}
 
-		return setupDirectoryConfig(absPath, account)
+		if worktrees != "" {
+			if config.LocalOnly {
+				return fmt.Errorf("❌ Worktree mappings need an includeIf in ~/.gitconfig, which local-only mode does not edit. Use 'krakn use %s <worktree>' for each worktree", account.Name)
+			}
+			return setupWorktreeConfig(config, commonDir, worktrees, account, name, email)
+		}
+		if existingRepos {
+			return setupExistingReposConfig(config, absPath, account)
+		}
+		return setupDirectoryConfig(config, absPath, account, name, email)
 	},
 }
 
@@ -83,11 +146,11 @@ func interactiveDirectoryConfig() error {
 	// Load available accounts
 	config, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return errLoadConfig(err)
 	}
 
 	if len(config.Accounts) == 0 {
-		return fmt.Errorf("❌ No accounts configured. Use 'krakn add' to add accounts first")
+		return errNoAccounts()
 	}
 
 	// Show current directory
@@ -99,10 +162,18 @@ func interactiveDirectoryConfig() error {
 		fmt.Printf("  %d. %s (%s)\n", i+1, account.Name, account.Email)
 	}
 
-	// Ask user to select account
-	fmt.Print("\n💬 Select account number: ")
+	// Ask user to select account; the network or schedule suggestion is the default
+	suggested := config.suggestedChoice()
+	if suggested > 0 {
+		fmt.Printf("\n💬 Select account number [%d]: ", suggested)
+	} else {
+		fmt.Print("\n💬 Select account number: ")
+	}
 	resp, _ := reader.ReadString('\n')
 	resp = strings.TrimSpace(resp)
+	if resp == "" && suggested > 0 {
+		resp = fmt.Sprintf("%d", suggested)
+	}
 
 	// Parse selection
 	var selectedAccount *Account
@@ -117,73 +188,605 @@ func interactiveDirectoryConfig() error {
 		return fmt.Errorf("❌ Invalid selection")
 	}
 
+	if err := config.revealAccount(selectedAccount); err != nil {
+		return err
+	}
+
 	// Setup the directory
-	return setupDirectoryConfig(currentDir, selectedAccount)
+	return setupDirectoryConfig(config, currentDir, selectedAccount, "", "")
 }
 
-func addConditionalInclude(dirPath, configPath string) error {
+func addConditionalInclude(mapping DirectoryMapping) error {
 	homeDir, _ := os.UserHomeDir()
 	globalConfigPath := filepath.Join(homeDir, ".gitconfig")
 
 	// Prepare the conditional include entry
-	// Git requires trailing slash for gitdir
-	gitDirPattern := dirPath
-	if !strings.HasSuffix(gitDirPattern, "/") {
-		gitDirPattern += "/"
+	if skipDotfileEdit(globalConfigPath, "adding includeIf for "+contractHomePath(mapping.patternDir())) {
+		return nil
 	}
 
-	includeSection := fmt.Sprintf("\n[includeIf \"gitdir:%s\"]\n\tpath = %s\n", gitDirPattern, configPath)
+	includeSection := fmt.Sprintf("\n[includeIf \"gitdir:%s\"]\n\tpath = %s\n", mapping.includeIfPattern(), contractHomePath(mapping.ConfigFile))
+
+	unlock, err := lockFile(globalConfigPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-	// Check if this include already exists
-	if existingConfig, err := os.ReadFile(globalConfigPath); err == nil {
-		if strings.Contains(string(existingConfig), fmt.Sprintf("gitdir:%s", gitDirPattern)) {
+	// Check if this include already exists (in either ~/ or absolute form)
+	if existingConfig, err := readGitConfigFile(globalConfigPath); err == nil {
+		if findIncludeIfForDir(existingConfig, mapping.patternDir()) != nil {
 			fmt.Println("ℹ️  Conditional include already exists in global .gitconfig")
 			return nil
 		}
 	}
 
 	// Append to global .gitconfig
+	trackFile(globalConfigPath)
 	f, err := os.OpenFile(globalConfigPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to open global .gitconfig: %w", err)
 	}
-	defer f.Close()
-
-	if _, err := f.WriteString(includeSection); err != nil {
+	_, err = f.WriteString(includeSection)
+	f.Close()
+	if err != nil {
 		return fmt.Errorf("failed to write conditional include: %w", err)
 	}
 
+	// Repository overrides must stay after every directory mapping to win
+	if config, err := loadConfig(); err == nil && len(config.Overrides) > 0 {
+		if err := config.syncOverrides(); err != nil {
+			return fmt.Errorf("failed to keep repository overrides last: %w", err)
+		}
+	}
+
 	fmt.Println("✅ Added conditional include to global .gitconfig")
 	return nil
 }
 
-func setupDirectoryConfig(dirPath string, account *Account) error {
+// setupDirectoryConfig maps a directory to an account; name and email, when
+// set, replace the account's commit name and email in it
+func setupDirectoryConfig(config *Config, dirPath string, account *Account, name, email string) error {
+	if config.LocalOnly && (name != "" || email != "") {
+		return fmt.Errorf("❌ --name and --email need a directory mapping, which local-only mode does not create. Use 'krakn use %s <repo>' and 'git config user.email' in each repository", account.Name)
+	}
+	if config.LocalOnly {
+		fmt.Println("⏭️  Local-only mode: no includeIf in ~/.gitconfig; configuring the repositories under the directory instead")
+		return setupExistingReposConfig(config, dirPath, account)
+	}
+
 	// Create directory-specific .gitconfig
 	gitConfigPath := filepath.Join(dirPath, ".gitconfig")
-	gitConfigContent := fmt.Sprintf(`[user]
-	name = %s
-	email = %s
-`, account.Username, account.Email)
+	mapping := DirectoryMapping{
+		Path:       dirPath,
+		Account:    account.Name,
+		ConfigFile: gitConfigPath,
+		Name:       name,
+		Email:      email,
+	}
+	if err := writeCommitTemplate(account); err != nil {
+		return err
+	}
+	gitConfigContent := mapping.identityInclude(account)
 
-	if err := os.WriteFile(gitConfigPath, []byte(gitConfigContent), 0644); err != nil {
+	trackFile(gitConfigPath)
+	if err := writePrivateFile(gitConfigPath, []byte(gitConfigContent)); err != nil {
 		return fmt.Errorf("failed to create .gitconfig: %w", err)
 	}
 
 	// Add conditional include to global .gitconfig
-	if err := addConditionalInclude(dirPath, gitConfigPath); err != nil {
+	if err := addConditionalInclude(mapping); err != nil {
 		return fmt.Errorf("failed to add conditional include: %w", err)
 	}
 
+	// Remember the mapping so it can be moved or verified later
+	if err := config.setDirectoryMapping(mapping); err != nil {
+		return fmt.Errorf("failed to save directory mapping: %w", err)
+	}
+
+	// A linked worktree's git directory lives in the main repository, so a
+	// gitdir pattern for its checkout path never matches
+	if gitDir, commonDir, err := findGitDir(dirPath); err == nil && gitDir != commonDir {
+		fmt.Printf("⚠️  %s is a linked worktree; git matches its git directory %s instead\n", contractHomePath(dirPath), contractHomePath(gitDir))
+		fmt.Printf("💡 Map it by name: krakn config %s %s --worktrees %s\n", contractHomePath(commonDir), account.Name, filepath.Base(gitDir))
+	}
+
 	fmt.Printf("✅ Directory '%s' configured for account '%s'\n", dirPath, account.Name)
-	fmt.Printf("👤 Name: %s\n", account.Username)
-	fmt.Printf("📧 Email: %s\n", account.Email)
+	fmt.Printf("👤 Name: %s\n", mapping.commitName(account))
+	fmt.Printf("📧 Email: %s\n", mapping.commitEmail(account))
 	fmt.Printf("📁 Config file: %s\n", gitConfigPath)
-	fmt.Printf("🔗 SSH Host: github.com-%s\n", account.Name)
+	fmt.Printf("🔗 SSH Host: %s\n", account.GetSSHHost())
 	fmt.Println("\n💡 Git will automatically use these settings in this directory!")
 
 	return nil
 }
 
+// setupWorktreeConfig maps the linked worktrees of a repository whose name
+// matches a glob to an account. The include file lives in the common git
+// directory, so it is shared by the worktrees without appearing in any of them.
+func setupWorktreeConfig(config *Config, commonDir, worktrees string, account *Account, name, email string) error {
+	mapping := DirectoryMapping{
+		Path:       commonDir,
+		Account:    account.Name,
+		ConfigFile: filepath.Join(commonDir, "krakn-"+account.Name+".gitconfig"),
+		Worktrees:  worktrees,
+		Name:       name,
+		Email:      email,
+	}
+
+	if err := writeCommitTemplate(account); err != nil {
+		return err
+	}
+	trackFile(mapping.ConfigFile)
+	if err := writePrivateFile(mapping.ConfigFile, []byte(mapping.identityInclude(account))); err != nil {
+		return fmt.Errorf("failed to create %s: %w", mapping.ConfigFile, err)
+	}
+	if err := addConditionalInclude(mapping); err != nil {
+		return fmt.Errorf("failed to add conditional include: %w", err)
+	}
+	if err := config.setDirectoryMapping(mapping); err != nil {
+		return fmt.Errorf("failed to save directory mapping: %w", err)
+	}
+
+	fmt.Printf("✅ Worktrees '%s' of %s configured for account '%s'\n", worktrees, contractHomePath(commonDir), account.Name)
+	fmt.Printf("👤 Name: %s\n", mapping.commitName(account))
+	fmt.Printf("📧 Email: %s\n", mapping.commitEmail(account))
+	fmt.Printf("📁 Config file: %s\n", contractHomePath(mapping.ConfigFile))
+	fmt.Printf("🔗 includeIf: gitdir:%s\n", mapping.includeIfPattern())
+
+	for _, other := range config.Directories {
+		if other.Worktrees != "" {
+			continue
+		}
+		if rel, err := filepath.Rel(other.Path, commonDir); err == nil && !strings.HasPrefix(rel, "..") {
+			fmt.Printf("ℹ️  %s is also mapped to '%s'; the worktree include comes later in .gitconfig and wins\n", contractHomePath(other.Path), other.Account)
+		}
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(commonDir, "worktrees"))
+	var matched int
+	for _, entry := range entries {
+		if !entry.IsDir() || !matchGlobPath(worktrees, entry.Name()) {
+			continue
+		}
+		// gitdir points at the worktree's .git file
+		checkout := entry.Name()
+		if data, err := os.ReadFile(filepath.Join(commonDir, "worktrees", entry.Name(), "gitdir")); err == nil {
+			checkout = contractHomePath(filepath.Dir(strings.TrimSpace(string(data))))
+		}
+		if matched == 0 {
+			fmt.Println("\n🌿 Matching worktrees:")
+		}
+		fmt.Printf("   %s (%s)\n", entry.Name(), checkout)
+		matched++
+	}
+	if matched == 0 {
+		fmt.Printf("\n💡 No worktree matches yet; new worktrees named like '%s' pick up the identity automatically\n", worktrees)
+	}
+	return nil
+}
+
+// setupExistingReposConfig writes the account's identity into the local
+// config of every repository below dirPath. The global .gitconfig is not
+// touched and no mapping is stored, since new repositories are not covered.
+func setupExistingReposConfig(config *Config, dirPath string, account *Account) error {
+	repos := findGitRepos(dirPath)
+	if len(repos) == 0 {
+		return fmt.Errorf("❌ No git repositories found under %s", contractHomePath(dirPath))
+	}
+
+	if err := writeCommitTemplate(account); err != nil {
+		return err
+	}
+	values := config.localIdentityValues(account)
+	updated := 0
+	var failed []string
+	for _, repo := range repos {
+		configPath, err := gitConfigTarget(repo, false)
+		if err != nil {
+			failed = append(failed, repo)
+			continue
+		}
+		changes, err := applyGitConfigValues(configPath, values)
+		if err != nil {
+			fmt.Printf("⚠️  %s: %v\n", contractHomePath(repo), err)
+			failed = append(failed, repo)
+			continue
+		}
+		if len(changes) > 0 {
+			updated++
+		}
+		printGitConfigChanges(configPath, changes)
+	}
+
+	fmt.Printf("\n✅ %d of %d repositories under %s use account '%s' (%d updated)\n", len(repos)-len(failed), len(repos), contractHomePath(dirPath), account.Name, updated)
+	fmt.Printf("👤 Name: %s\n", account.CommitName())
+	fmt.Printf("📧 Email: %s\n", account.Email)
+	fmt.Println("\n💡 The global .gitconfig was not changed. Repositories cloned later are not")
+	fmt.Printf("   covered; run 'krakn config %s %s --existing-repos' again to add them\n", contractHomePath(dirPath), account.Name)
+	if len(failed) > 0 {
+		return fmt.Errorf("❌ Failed to update %d repositories", len(failed))
+	}
+	return nil
+}
+
+// repoCommonDir returns the git directory shared by all worktrees of the
+// repository at path: a working tree, a linked worktree or a bare repository
+func repoCommonDir(path string) (string, error) {
+	if _, commonDir, err := findGitDir(path); err == nil {
+		return commonDir, nil
+	}
+	if fileExists(filepath.Join(path, "HEAD")) && fileExists(filepath.Join(path, "objects")) {
+		return filepath.Clean(path), nil
+	}
+	return "", errNotARepository(path)
+}
+
+// patternDir returns the directory the mapping's includeIf gitdir pattern
+// names: the mapped directory, or the worktree glob below the git directory
+func (m DirectoryMapping) patternDir() string {
+	if m.Worktrees == "" {
+		return m.Path
+	}
+	return filepath.Join(m.Path, "worktrees", m.Worktrees)
+}
+
+// includeIfPattern returns the gitdir pattern of the mapping's includeIf
+// section. Git requires a trailing slash to match everything below a
+// directory; a worktree's git directory is matched by itself, without one.
+// ~/ keeps the entry portable across machines.
+func (m DirectoryMapping) includeIfPattern() string {
+	if m.Worktrees != "" {
+		return contractHomePath(m.patternDir())
+	}
+	return gitDirPattern(contractHomePath(m.Path))
+}
+
+// identityInclude renders the mapping's include file: the account's identity
+// under the commit name and email the mapping overrides
+func (m DirectoryMapping) identityInclude(account *Account) string {
+	return identityIncludeAs(account, m.Name, m.Email)
+}
+
+// commitName returns the user.name of commits in the mapped directory
+func (m DirectoryMapping) commitName(account *Account) string {
+	if m.Name != "" {
+		return m.Name
+	}
+	return account.CommitName()
+}
+
+// commitEmail returns the user.email of commits in the mapped directory
+func (m DirectoryMapping) commitEmail(account *Account) string {
+	if m.Email != "" {
+		return m.Email
+	}
+	return account.Email
+}
+
+// gitDirPattern returns the includeIf gitdir pattern for a directory
+func gitDirPattern(dirPath string) string {
+	if !strings.HasSuffix(dirPath, "/") {
+		dirPath += "/"
+	}
+	return dirPath
+}
+
+// findIncludeIfForDir locates the includeIf section whose gitdir pattern
+// matches the directory, accepting both absolute and ~/ spellings. Other
+// conditions, such as onbranch, never match, so krakncat leaves them alone.
+func findIncludeIfForDir(gitConfig *gitConfigFile, dirPath string) *gitConfigSection {
+	for _, section := range gitConfig.findSections("includeIf") {
+		pattern := strings.TrimPrefix(section.Subsection, "gitdir:")
+		if pattern == section.Subsection {
+			continue
+		}
+		if filepath.Clean(expandUserPath(pattern)) == filepath.Clean(dirPath) {
+			return section
+		}
+	}
+	return nil
+}
+
+// moveDirectoryConfig retargets a directory mapping after the directory was relocated
+func moveDirectoryConfig(oldDir, newDir string) error {
+	oldPath, err := filepath.Abs(expandUserPath(oldDir))
+	if err != nil {
+		return fmt.Errorf("failed to resolve directory path: %w", err)
+	}
+	newPath, err := filepath.Abs(expandUserPath(newDir))
+	if err != nil {
+		return fmt.Errorf("failed to resolve directory path: %w", err)
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return errLoadConfig(err)
+	}
+
+	gitConfig, err := readGitConfigFile(globalGitConfigPath())
+	if err != nil {
+		return err
+	}
+
+	mapping := config.getDirectoryMapping(oldPath)
+	section := findIncludeIfForDir(gitConfig, oldPath)
+	if mapping == nil && section == nil {
+		return fmt.Errorf("❌ No directory configuration found for %s. Use 'krakn show-includes' to list them", oldPath)
+	}
+
+	// Move the directory itself if it has not been moved yet
+	if _, err := os.Stat(newPath); os.IsNotExist(err) {
+		if _, err := os.Stat(oldPath); err == nil {
+			if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+				return fmt.Errorf("failed to create parent directory: %w", err)
+			}
+			if err := os.Rename(oldPath, newPath); err != nil {
+				return fmt.Errorf("failed to move directory: %w", err)
+			}
+			fmt.Printf("📦 Moved %s → %s\n", oldPath, newPath)
+		} else {
+			return fmt.Errorf("❌ Neither %s nor %s exists", oldPath, newPath)
+		}
+	}
+
+	// Work out where the include file lives now
+	oldConfigFile := ""
+	if mapping != nil {
+		oldConfigFile = mapping.ConfigFile
+	} else if section != nil {
+		oldConfigFile = expandUserPath(section.get("path"))
+	}
+	newConfigFile := oldConfigFile
+	if rel, err := filepath.Rel(oldPath, oldConfigFile); err == nil && !strings.HasPrefix(rel, "..") {
+		newConfigFile = filepath.Join(newPath, rel)
+	}
+
+	// Recreate the include file if it did not travel with the directory
+	if _, err := os.Stat(newConfigFile); os.IsNotExist(err) && mapping != nil {
+		if account := config.getAccount(mapping.Account); account != nil {
+			if err := config.revealAccount(account); err != nil {
+				return err
+			}
+			trackFile(newConfigFile)
+			if err := writePrivateFile(newConfigFile, []byte(mapping.identityInclude(account))); err != nil {
+				return fmt.Errorf("failed to recreate %s: %w", newConfigFile, err)
+			}
+			fmt.Printf("📝 Recreated include file: %s\n", newConfigFile)
+		}
+	}
+
+	// Rewrite the conditional include, reading ~/.gitconfig again under its
+	// lock so changes made while the directory moved are kept
+	newPattern := "gitdir:" + gitDirPattern(contractHomePath(newPath))
+	if err := withFileLock(globalGitConfigPath(), func() error {
+		gitConfig, err := readGitConfigFile(globalGitConfigPath())
+		if err != nil {
+			return err
+		}
+		section := findIncludeIfForDir(gitConfig, oldPath)
+		if section == nil {
+			section = gitConfig.addSection("includeIf", newPattern)
+		} else {
+			section.setSubsection(newPattern)
+		}
+		section.set("path", contractHomePath(newConfigFile))
+		return gitConfig.save()
+	}); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Updated conditional include: %s → %s\n", newPattern, newConfigFile)
+
+	// Update the stored mapping
+	accountName, mappedEmail := "", ""
+	if mapping != nil {
+		accountName, mappedEmail = mapping.Account, mapping.Email
+		if err := config.update(func(config *Config) error {
+			if mapping := config.getDirectoryMapping(oldPath); mapping != nil {
+				mapping.Path = newPath
+				mapping.ConfigFile = newConfigFile
+			}
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+	}
+
+	// Re-verify repositories under the new location
+	var expectedEmail string
+	if account := config.getAccount(accountName); account != nil {
+		if err := config.revealAccount(account); err == nil {
+			expectedEmail = account.Email
+		}
+	}
+	if mappedEmail != "" {
+		expectedEmail = mappedEmail
+	}
+	spin := startSpinner("Scanning " + newPath + " for repositories")
+	repos := findGitRepos(newPath)
+	spin.Stop()
+	if len(repos) == 0 {
+		fmt.Println("ℹ️  No git repositories found under the new location")
+		return nil
+	}
+
+	fmt.Printf("\n🔍 Verifying %d repositories:\n", len(repos))
+	for _, repo := range repos {
+		email := getRepoGitConfig(repo, "user.email")
+		switch {
+		case expectedEmail == "":
+			fmt.Printf("   📁 %s → %s\n", repo, email)
+		case email == expectedEmail:
+			fmt.Printf("   ✅ %s → %s\n", repo, email)
+		default:
+			fmt.Printf("   ⚠️  %s → %s (expected %s)\n", repo, email, expectedEmail)
+		}
+	}
+
+	return nil
+}
+
+// unmapDirectory removes a directory's conditional include, its include file
+// and the stored mapping. The directory and its repositories are untouched.
+func unmapDirectory(dir, worktrees string) error {
+	dirPath, err := filepath.Abs(expandUserPath(dir))
+	if err != nil {
+		return fmt.Errorf("failed to resolve directory path: %w", err)
+	}
+	if worktrees != "" {
+		commonDir, err := repoCommonDir(dirPath)
+		if err != nil {
+			return err
+		}
+		dirPath = DirectoryMapping{Path: commonDir, Worktrees: worktrees}.patternDir()
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return errLoadConfig(err)
+	}
+
+	return unmapDirectoryConfig(config, dirPath)
+}
+
+// unmapDirectoryConfig removes the configuration of an absolute directory path,
+// or of a worktree mapping's pattern directory
+func unmapDirectoryConfig(config *Config, dirPath string) error {
+	gitConfig, err := readGitConfigFile(globalGitConfigPath())
+	if err != nil {
+		return err
+	}
+
+	mapping := config.getDirectoryMapping(dirPath)
+	section := findIncludeIfForDir(gitConfig, dirPath)
+	if mapping == nil && section == nil {
+		return fmt.Errorf("❌ No directory configuration found for %s. Use 'krakn dir list' to list them", dirPath)
+	}
+
+	includeFile := ""
+	if mapping != nil {
+		includeFile = mapping.ConfigFile
+	} else {
+		includeFile = expandUserPath(section.get("path"))
+	}
+
+	if section != nil {
+		if err := withFileLock(globalGitConfigPath(), func() error {
+			gitConfig, err := readGitConfigFile(globalGitConfigPath())
+			if err != nil {
+				return err
+			}
+			if section := findIncludeIfForDir(gitConfig, dirPath); section != nil {
+				gitConfig.removeSection(section)
+				return gitConfig.save()
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Removed conditional include for %s\n", dirPath)
+	}
+
+	// Only delete include files krakncat created inside the directory, or in
+	// the git directory for worktrees when no other worktree mapping uses it
+	owner := dirPath
+	if mapping != nil && mapping.Worktrees != "" {
+		owner = mapping.Path
+		for _, other := range config.Directories {
+			if other.ConfigFile == includeFile && other.patternDir() != dirPath {
+				owner = ""
+			}
+		}
+	}
+	if rel, err := filepath.Rel(owner, includeFile); owner != "" && err == nil && !strings.HasPrefix(rel, "..") && fileExists(includeFile) {
+		trackFile(includeFile)
+		if err := os.Remove(includeFile); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", includeFile, err)
+		}
+		fmt.Printf("🗑️  Removed include file %s\n", includeFile)
+	}
+
+	if mapping != nil {
+		if err := config.removeDirectoryMapping(dirPath); err != nil {
+			return errSaveConfig(err)
+		}
+	}
+
+	if mapping != nil && mapping.Worktrees != "" {
+		fmt.Printf("✅ Worktrees '%s' of %s are no longer mapped to an account\n", mapping.Worktrees, contractHomePath(mapping.Path))
+		return nil
+	}
+	fmt.Printf("✅ Directory '%s' is no longer mapped to an account\n", dirPath)
+	return nil
+}
+
+var dirListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List directories mapped to accounts",
+	Long: `List the directories mapped to accounts with 'krakn dir map', flagging
+directories or include files that no longer exist.
+
+Examples:
+  krakn dir list`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		if len(config.Directories) == 0 {
+			fmt.Println("📭 No directories mapped. Use 'krakn dir map <directory> <account>' to add one.")
+			return nil
+		}
+
+		fmt.Println("📁 Directory mappings:")
+		for _, mapping := range config.Directories {
+			status := "✅"
+			if !fileExists(mapping.Path) {
+				status = "⚠️  (missing)"
+			} else if !fileExists(mapping.ConfigFile) {
+				status = "⚠️  (include file missing)"
+			}
+			if mapping.Worktrees != "" {
+				fmt.Printf("  %s %s worktrees '%s' → %s\n", status, contractHomePath(mapping.Path), mapping.Worktrees, mapping.Account)
+			} else {
+				fmt.Printf("  %s %s → %s\n", status, contractHomePath(mapping.Path), mapping.Account)
+			}
+			if mapping.Name != "" {
+				fmt.Printf("     👤 Name: %s\n", mapping.Name)
+			}
+			if mapping.Email != "" {
+				fmt.Printf("     📧 Email: %s\n", mapping.Email)
+			}
+			fmt.Printf("     🔗 %s\n", contractHomePath(mapping.ConfigFile))
+		}
+		return nil
+	},
+}
+
+var dirUnmapCmd = &cobra.Command{
+	Use:   "unmap [directory]",
+	Short: "Remove the account mapping of a directory",
+	Long: `Remove the conditional include, the include file and the stored mapping of a
+directory configured with 'krakn dir map'. Repositories inside keep working but
+fall back to the global identity.
+
+Examples:
+  krakn dir unmap ~/work
+  krakn dir unmap ~/mono --worktrees 'acme-*'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		worktrees, _ := cmd.Flags().GetString("worktrees")
+		return unmapDirectory(args[0], worktrees)
+	},
+}
+
 func init() {
+	dirConfigCmd.Flags().Bool("move", false, "Retarget an existing directory configuration: config --move <old-dir> <new-dir>")
+	dirConfigCmd.Flags().String("worktrees", "", "Map the repository's linked worktrees whose name matches this glob")
+	dirConfigCmd.Flags().String("name", "", "Commit name in the directory instead of the account's")
+	dirConfigCmd.Flags().String("email", "", "Commit email in the directory instead of the account's")
+	dirConfigCmd.Flags().Bool("existing-repos", false, "Write the identity into each existing repository's local config instead of an includeIf")
+	dirUnmapCmd.Flags().String("worktrees", "", "Remove the mapping of the repository's worktrees matching this glob")
 	RootCmd.AddCommand(dirConfigCmd)
 }
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var docsCmd = &cobra.Command{
+	Use:    "docs",
+	Short:  "Generate man pages and markdown reference documentation",
+	Hidden: true,
+	Long: `Generate reference documentation for every krakn command. Intended for
+packagers and for the documentation site.
+
+Examples:
+  krakn docs man ./man          # man pages (krakn.1, krakn-account-add.1, ...)
+  krakn docs markdown ./docs    # one markdown file per command`,
+}
+
+// prepareDocsDir creates the output directory for generated documentation
+func prepareDocsDir(args []string, fallback string) (string, error) {
+	dir := fallback
+	if len(args) == 1 {
+		dir = args[0]
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	// Generated files must not depend on the day they were built
+	RootCmd.DisableAutoGenTag = true
+	return dir, nil
+}
+
+var docsManCmd = &cobra.Command{
+	Use:   "man [directory]",
+	Short: "Generate man pages (section 1)",
+	Long: `Generate a section 1 man page for every command.
+
+Examples:
+  krakn docs man ./man`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := prepareDocsDir(args, "man")
+		if err != nil {
+			return err
+		}
+
+		header := &doc.GenManHeader{
+			Title:   "KRAKN",
+			Section: "1",
+			Source:  "krakncat",
+			Manual:  "krakncat Manual",
+		}
+		if err := doc.GenManTree(RootCmd, header, dir); err != nil {
+			return fmt.Errorf("failed to generate man pages: %w", err)
+		}
+
+		fmt.Printf("✅ Man pages written to %s\n", dir)
+		return nil
+	},
+}
+
+var docsMarkdownCmd = &cobra.Command{
+	Use:   "markdown [directory]",
+	Short: "Generate one markdown file per command",
+	Long: `Generate one markdown file per command, linked to each other.
+
+Examples:
+  krakn docs markdown ./docs`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := prepareDocsDir(args, "docs")
+		if err != nil {
+			return err
+		}
+
+		if err := doc.GenMarkdownTree(RootCmd, dir); err != nil {
+			return fmt.Errorf("failed to generate markdown: %w", err)
+		}
+
+		fmt.Printf("✅ Markdown reference written to %s\n", dir)
+		return nil
+	},
+}
+
+func init() {
+	docsCmd.AddCommand(docsManCmd)
+	docsCmd.AddCommand(docsMarkdownCmd)
+	RootCmd.AddCommand(docsCmd)
+}
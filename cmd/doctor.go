@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// Severity levels reported by doctor checks
+const (
+	doctorOK    = "ok"
+	doctorInfo  = "info"
+	doctorWarn  = "warn"
+	doctorError = "error"
+)
+
+// doctorFinding is a single result reported by a doctor check
+type doctorFinding struct {
+	Level   string `json:"level" desc:"ok, info, warn or error"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty" desc:"Suggested remediation, e.g. a krakn command"`
+}
+
+// doctorCheckResult is the findings of one check in a doctor run
+type doctorCheckResult struct {
+	Name     string          `json:"name"`
+	Findings []doctorFinding `json:"findings"`
+}
+
+// doctorReport is printed by 'krakn doctor --output json|yaml'
+type doctorReport struct {
+	SchemaVersion int                 `json:"schema_version"`
+	Repository    string              `json:"repository,omitempty" desc:"Root of the repository checked, if any"`
+	Problems      int                 `json:"problems" desc:"Number of warnings and errors"`
+	Checks        []doctorCheckResult `json:"checks"`
+}
+
+// doctorContext is shared by all checks of a doctor run
+type doctorContext struct {
+	Config   *Config
+	RepoRoot string // Empty when doctor does not run inside a repository
+	Offline  bool   // Skip the checks that contact the provider, as with --offline
+}
+
+// offline reports whether checks may not contact the provider
+func (ctx *doctorContext) offline() bool {
+	return ctx.Offline || offlineMode
+}
+
+// doctorCheck is a named diagnostic registered by the files that own the feature
+type doctorCheck struct {
+	Name string
+	Run  func(ctx *doctorContext) []doctorFinding
+}
+
+var doctorChecks []doctorCheck
+
+// registerDoctorCheck adds a check to every doctor run
+func registerDoctorCheck(check doctorCheck) {
+	doctorChecks = append(doctorChecks, check)
+}
+
+func (f doctorFinding) icon() string {
+	switch f.Level {
+	case doctorOK:
+		return "✅"
+	case doctorWarn:
+		return "⚠️ "
+	case doctorError:
+		return "❌"
+	}
+	return "ℹ️ "
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor [path]",
+	Short: "Diagnose account, SSH and repository configuration",
+	Long: `Run diagnostics on the krakncat configuration and, when run inside a git
+repository, on the identity that repository uses.
+
+Examples:
+  krakn doctor              # Check accounts and the current repository
+  krakn doctor ~/work/api   # Check a specific repository
+  krakn doctor --output json   # Findings for scripts ('krakn schema doctor')`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		ctx := &doctorContext{Config: config}
+		if root, err := findRepoRoot(path); err == nil {
+			ctx.RepoRoot = root
+		}
+
+		if structuredOutput() {
+			return printStructured(runDoctorChecks(ctx))
+		}
+		if problems := printDoctorReport(os.Stdout, ctx); problems == 0 {
+			fmt.Println("🎉 No problems found")
+		} else {
+			fmt.Printf("🔎 %d problem(s) found\n", problems)
+		}
+		return nil
+	},
+}
+
+// runDoctorChecks runs every check. Checks without findings are left out.
+func runDoctorChecks(ctx *doctorContext) doctorReport {
+	report := doctorReport{SchemaVersion: schemaVersion("doctor"), Repository: ctx.RepoRoot, Checks: []doctorCheckResult{}}
+	for _, check := range doctorChecks {
+		findings := check.Run(ctx)
+		if len(findings) == 0 {
+			continue
+		}
+		for _, finding := range findings {
+			if finding.Level == doctorWarn || finding.Level == doctorError {
+				report.Problems++
+			}
+		}
+		report.Checks = append(report.Checks, doctorCheckResult{Name: check.Name, Findings: findings})
+	}
+	return report
+}
+
+// printDoctorReport runs every check and writes the findings to w. It returns
+// the number of warnings and errors.
+func printDoctorReport(w io.Writer, ctx *doctorContext) int {
+	report := runDoctorChecks(ctx)
+	for _, check := range report.Checks {
+		fmt.Fprintf(w, "🩺 %s\n", check.Name)
+		for _, finding := range check.Findings {
+			fmt.Fprintf(w, "   %s %s\n", finding.icon(), finding.Message)
+			if finding.Hint != "" {
+				fmt.Fprintf(w, "      💡 %s\n", finding.Hint)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+	return report.Problems
+}
+
+// checkAccountKeys verifies every account's SSH key exists
+func checkAccountKeys(ctx *doctorContext) []doctorFinding {
+	var findings []doctorFinding
+	if len(ctx.Config.Accounts) == 0 {
+		return []doctorFinding{{Level: doctorInfo, Message: "No accounts configured", Hint: "krakn add"}}
+	}
+
+	for _, account := range ctx.Config.Accounts {
+		switch {
+		case account.usesHTTPS() && !account.hasToken():
+			findings = append(findings, doctorFinding{
+				Level:   doctorWarn,
+				Message: fmt.Sprintf("Account '%s' is HTTPS-only but has no token", account.Name),
+				Hint:    "krakn token set " + account.Name,
+			})
+		case account.usesHTTPS():
+			findings = append(findings, doctorFinding{
+				Level:   doctorOK,
+				Message: fmt.Sprintf("Account '%s': HTTPS only, token served by the credential helper", account.Name),
+			})
+		case account.SSHKey == "":
+			findings = append(findings, doctorFinding{
+				Level:   doctorWarn,
+				Message: fmt.Sprintf("Account '%s' has no SSH key", account.Name),
+				Hint:    fmt.Sprintf("krakn generate-key --name %s --email <email>", account.Name),
+			})
+		case !filepath.IsAbs(account.SSHKey):
+			findings = append(findings, doctorFinding{
+				Level:   doctorWarn,
+				Message: fmt.Sprintf("Account '%s': SSH key path %s is relative; ssh resolves it against the working directory", account.Name, account.SSHKey),
+				Hint:    "Use a path starting with ~/ or %d/ in config.json and ~/.ssh/config",
+			})
+		case account.AgentKey:
+			findings = append(findings, checkAgentKey(&account))
+		case !fileExists(account.SSHKey):
+			findings = append(findings, doctorFinding{
+				Level:   doctorError,
+				Message: fmt.Sprintf("Account '%s': SSH key %s is missing", account.Name, account.SSHKey),
+			})
+		default:
+			findings = append(findings, doctorFinding{
+				Level:   doctorOK,
+				Message: fmt.Sprintf("Account '%s': SSH key %s", account.Name, account.SSHKey),
+			})
+		}
+	}
+	return findings
+}
+
+// checkRepoIdentity reports which account the current repository uses and
+// whether the mechanisms involved agree with each other
+func checkRepoIdentity(ctx *doctorContext) []doctorFinding {
+	if ctx.RepoRoot == "" {
+		return nil
+	}
+
+	identity := inspectRepoIdentity(ctx.Config, ctx.RepoRoot, "origin")
+	findings := []doctorFinding{{Level: doctorInfo, Message: "Repository: " + ctx.RepoRoot}}
+
+	if identity.Email != "" {
+		owner := "no account"
+		if identity.EmailAccount != nil {
+			owner = "account '" + identity.EmailAccount.Name + "'"
+		} else if identity.Override != nil && identity.Override.Email == identity.Email {
+			owner = "override of account '" + identity.Override.Account + "'"
+		}
+		findings = append(findings, doctorFinding{Level: doctorInfo, Message: fmt.Sprintf("user.email %s (%s)", identity.Email, owner)})
+	}
+
+	mapping := "Directory is mapped"
+	if identity.Override != nil {
+		mapping = "Repository " + identity.Override.Repo + " is overridden"
+		findings = append(findings, doctorFinding{Level: doctorInfo, Message: fmt.Sprintf("%s to '%s'", mapping, identity.Override.Account)})
+		if identity.HostAccount != nil && identity.HostAccount.Name != identity.Override.Account {
+			findings = append(findings, doctorFinding{
+				Level:   doctorWarn,
+				Message: fmt.Sprintf("Commits use the override '%s' but the remote authenticates as '%s'", identity.Override.Account, identity.HostAccount.Name),
+				Hint:    "krakn fix-remote --account " + identity.Override.Account,
+			})
+		}
+	}
+	if identity.MappedAccount != nil && identity.EmailAccount != nil && identity.MappedAccount.Name != identity.EmailAccount.Name {
+		findings = append(findings, doctorFinding{
+			Level:   doctorWarn,
+			Message: fmt.Sprintf("%s to '%s' but user.email belongs to '%s'", mapping, identity.MappedAccount.Name, identity.EmailAccount.Name),
+		})
+	}
+
+	// An HTTPS-only account needs an https remote, and none of the SSH checks apply
+	for _, account := range []*Account{identity.MappedAccount, identity.EmailAccount} {
+		if account == nil || !account.usesHTTPS() {
+			continue
+		}
+		if identity.Remote != nil && identity.Remote.isSSH() {
+			findings = append(findings, doctorFinding{
+				Level:   doctorWarn,
+				Message: fmt.Sprintf("Account '%s' is HTTPS-only but the remote uses SSH", account.Name),
+				Hint:    "krakn fix-remote --account " + account.Name,
+			})
+		}
+		return findings
+	}
+
+	// Which account will SSH authenticate as?
+	var sshAccount *Account
+	if identity.SSHCommand != "" {
+		if identity.KeyAccount != nil {
+			sshAccount = identity.KeyAccount
+			findings = append(findings, doctorFinding{
+				Level:   doctorOK,
+				Message: fmt.Sprintf("core.sshCommand selects the key of account '%s' (intentional override)", identity.KeyAccount.Name),
+			})
+		} else {
+			findings = append(findings, doctorFinding{
+				Level:   doctorInfo,
+				Message: fmt.Sprintf("core.sshCommand is set (%s) but does not select a krakncat key", identity.SSHCommand),
+			})
+		}
+
+		if identity.HostAccount != nil && identity.KeyAccount != nil && identity.HostAccount.Name != identity.KeyAccount.Name {
+			findings = append(findings, doctorFinding{
+				Level:   doctorWarn,
+				Message: fmt.Sprintf("Remote uses the host alias of '%s' while core.sshCommand selects the key of '%s'", identity.HostAccount.Name, identity.KeyAccount.Name),
+				Hint:    "Keep one mechanism: git config --unset core.sshCommand, or point the remote at the plain hostname",
+			})
+		}
+	} else if identity.HostAccount != nil {
+		sshAccount = identity.HostAccount
+		findings = append(findings, doctorFinding{
+			Level:   doctorOK,
+			Message: fmt.Sprintf("Remote uses host alias %s (account '%s')", identity.Remote.Host, identity.HostAccount.Name),
+		})
+	} else if identity.Remote != nil && identity.Remote.isSSH() {
+		findings = append(findings, doctorFinding{
+			Level:   doctorWarn,
+			Message: fmt.Sprintf("Remote uses %s directly, so SSH picks the default key", identity.Remote.Host),
+			Hint:    "krakn fix-remote",
+		})
+	}
+
+	if sshAccount != nil && identity.EmailAccount != nil && sshAccount.Name != identity.EmailAccount.Name {
+		findings = append(findings, doctorFinding{
+			Level:   doctorWarn,
+			Message: fmt.Sprintf("Commits are authored as '%s' but pushes authenticate as '%s'", identity.EmailAccount.Name, sshAccount.Name),
+			Hint:    fmt.Sprintf("krakn use %s %s", sshAccount.Name, ctx.RepoRoot),
+		})
+	}
+
+	return findings
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func init() {
+	registerDoctorCheck(doctorCheck{Name: "Accounts", Run: checkAccountKeys})
+	registerDoctorCheck(doctorCheck{Name: "Repository identity", Run: checkRepoIdentity})
+	RootCmd.AddCommand(doctorCmd)
+	registerOutputSchema(outputSchema{Command: "doctor", Version: 1, Description: "Findings of every diagnostic check", Type: doctorReport{}})
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// resealField seals a changed value again when the field was sealed, with the
+// method the config uses, so editing does not leave it in plaintext
+func (c *Config) resealField(account *Account, field string) error {
+	if !account.isSealed(field) {
+		return nil
+	}
+	recipients, err := c.sealRecipients()
+	if err != nil {
+		return err
+	}
+	sealed, err := sealValue(*account.fieldValue(field), recipients)
+	if err != nil {
+		return fmt.Errorf("failed to seal %s: %w", field, err)
+	}
+	account.Sealed[field] = sealed
+	return nil
+}
+
+// promptEdit shows the current value and returns the answer, or the current
+// value when the answer is empty
+func promptEdit(reader *bufio.Reader, label, current string) string {
+	fmt.Printf("%s [%s]: ", label, current)
+	answer, _ := reader.ReadString('\n')
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return current
+	}
+	return answer
+}
+
+var editCmd = &cobra.Command{
+	Use:   "edit <account-name>",
+	Short: "Change an account's email, username, SSH key or provider",
+	Long: `Change the email, provider username, SSH key path or provider of an account.
+Without flags, each field is asked for with its current value as the default.
+
+The change is carried to everything krakncat generated for the account: the
+include files of its directory mappings and overrides, the global identity
+when it still carries the old name or email, and its Host block in
+~/.ssh/config. A new provider also renames the generated host alias, so
+remotes need 'krakn fix-remote' afterwards. Host blocks linked from an
+existing ~/.ssh/config entry are yours and are only reported.
+
+--provider takes github, ghes[:<hostname>] for GitHub Enterprise Server,
+gitlab, gitea, bitbucket, codeberg, azure-devops, sourcehut, the hostname of a
+self-hosted server, or custom to be asked for its SSH user, port and key
+settings page.
+
+Examples:
+  krakn edit work                          # Asks for each field
+  krakn edit work --email new@corp.com
+  krakn edit work --ssh-key ~/.ssh/id_ed25519_work_2024
+  krakn edit oss --provider gitlab --username alice`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		email, _ := cmd.Flags().GetString("email")
+		username, _ := cmd.Flags().GetString("username")
+		sshKey, _ := cmd.Flags().GetString("ssh-key")
+		providerName, _ := cmd.Flags().GetString("provider")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+		if err := config.revealAccount(account); err != nil {
+			return err
+		}
+		before := *account
+
+		reader := bufio.NewReader(os.Stdin)
+		if email == "" && username == "" && sshKey == "" && providerName == "" {
+			fmt.Printf("✏️  Editing account '%s' (Enter keeps the current value)\n", account.Name)
+			email = promptEdit(reader, "📧 Email address", account.Email)
+			username = promptEdit(reader, fmt.Sprintf("👤 %s username", account.GetProvider().DisplayName), account.Username)
+			if !account.usesHTTPS() {
+				sshKey = promptEdit(reader, "🔑 SSH key path", contractHomePath(account.SSHKey))
+			}
+			providerName = promptEdit(reader, fmt.Sprintf("🌐 Provider (%s or a hostname)", strings.Join(providerPresets, ", ")), account.GetProvider().Hostname)
+		}
+
+		if email != "" {
+			account.Email = strings.TrimSpace(email)
+		}
+		if username != "" {
+			account.Username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+		}
+		if sshKey != "" {
+			keyPath, err := filepath.Abs(expandUserPath(sshKey))
+			if err != nil {
+				return fmt.Errorf("failed to resolve key path: %w", err)
+			}
+			if !fileExists(keyPath) && !fileExists(keyPath+".pub") {
+				return fmt.Errorf("❌ No SSH key at %s. Create one with 'krakn key generate --name %s'", keyPath, account.Name)
+			}
+			account.SSHKey = keyPath
+		}
+		if providerName != "" && providerName != before.GetProvider().Hostname {
+			provider, err := resolveProvider(providerName, reader)
+			if err != nil {
+				return err
+			}
+			account.Provider = &provider
+			if provider == DefaultProviders["github"] {
+				account.Provider = nil
+			}
+		}
+
+		changed := []string{}
+		if account.Email != before.Email {
+			changed = append(changed, "email")
+		}
+		if account.Username != before.Username {
+			changed = append(changed, "username")
+		}
+		if account.SSHKey != before.SSHKey {
+			changed = append(changed, "SSH key")
+		}
+		if account.GetProvider().Hostname != before.GetProvider().Hostname {
+			changed = append(changed, "provider")
+		}
+		if len(changed) == 0 {
+			fmt.Printf("ℹ️  Nothing changed for account '%s'\n", account.Name)
+			return nil
+		}
+
+		// The verified profile belongs to the old login, and the ID of an uploaded
+		// key means nothing on another provider
+		if account.Username != before.Username || account.GetProvider().Hostname != before.GetProvider().Hostname {
+			account.Profile = nil
+		}
+		if account.GetProvider().Hostname != before.GetProvider().Hostname {
+			account.KeyID = 0
+		}
+
+		// A sealed email stays sealed
+		saved := *account
+		if err := config.resealField(&saved, "email"); err != nil {
+			return err
+		}
+		if err := config.addAccount(saved); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("✅ Updated %s of account '%s'\n", strings.Join(changed, ", "), account.Name)
+
+		// Identity files krakncat generated
+		if account.Email != before.Email || account.CommitName() != before.CommitName() {
+			if err := config.rewriteAccountIncludes(account); err != nil {
+				return err
+			}
+			if config.CurrentAccount == account.Name && !config.LocalOnly {
+				values := identityUpdates(globalGitConfigPath(), before.CommitName(), account.CommitName(), before.Email, account.Email)
+				if len(values) > 0 {
+					changes, err := applyGitConfigValues(globalGitConfigPath(), values)
+					if err != nil {
+						return fmt.Errorf("failed to update the global identity: %w", err)
+					}
+					printGitConfigChanges(globalGitConfigPath(), changes)
+				}
+			}
+		}
+
+		// Credential helpers are keyed by hostname and owner
+		if account.usesHTTPS() && (account.Username != before.Username || account.GetProvider().Hostname != before.GetProvider().Hostname) {
+			hostToo := !config.hostUsesHTTPS(before.GetProvider().Hostname, account.Name)
+			if err := removeCredentialHelper(&before, hostToo); err != nil {
+				return fmt.Errorf("failed to update ~/.gitconfig: %w", err)
+			}
+			if err := installCredentialHelper(account); err != nil {
+				return fmt.Errorf("failed to update ~/.gitconfig: %w", err)
+			}
+			fmt.Printf("🔐 Updated the credential helper for https://%s/\n", account.GetProvider().Hostname)
+		}
+
+		// The Host block carries the key, hostname and alias
+		if !account.usesHTTPS() && (account.SSHKey != before.SSHKey || account.GetSSHHost() != before.GetSSHHost() || account.GetProvider().Hostname != before.GetProvider().Hostname) {
+			if account.SSHHost != "" {
+				fmt.Printf("⚠️  Host %s is linked from your ~/.ssh/config and was left alone; update its HostName and IdentityFile yourself\n", account.SSHHost)
+			} else {
+				if before.GetSSHHost() != account.GetSSHHost() {
+					if _, err := removeSSHHostBlock(before.GetSSHHost()); err != nil {
+						return fmt.Errorf("failed to remove Host %s: %w", before.GetSSHHost(), err)
+					}
+				}
+				if err := upsertSSHHostBlock(account.GetSSHHost(), account.GenerateSSHConfig()); err != nil {
+					return err
+				}
+				fmt.Printf("🔗 Updated Host %s in %s\n", account.GetSSHHost(), contractHomePath(getSSHConfigPath()))
+			}
+		}
+		if account.GetSSHHost() != before.GetSSHHost() {
+			fmt.Printf("💡 Remotes using %s need 'krakn fix-remote --account %s'\n", before.GetSSHHost(), account.Name)
+		}
+		if account.SSHKey != before.SSHKey {
+			if fingerprint := keyFingerprint(account.SSHKey); fingerprint != "" {
+				fmt.Printf("🔏 Key fingerprint: %s\n", fingerprint)
+			}
+			fmt.Printf("💡 Run 'krakn test %s' to check the provider accepts the key\n", account.Name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	editCmd.Flags().String("email", "", "New email address")
+	editCmd.Flags().String("username", "", "New provider username")
+	editCmd.Flags().String("ssh-key", "", "Path of the SSH private key to use")
+	editCmd.Flags().String("provider", "", "New provider: github, ghes[:<hostname>], gitlab, gitea, bitbucket, codeberg, azure-devops, sourcehut or a self-hosted hostname")
+	editCmd.RegisterFlagCompletionFunc("provider", completeProvider)
+	RootCmd.AddCommand(editCmd)
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// shellHooks re-run 'krakn env' before every prompt, so the variables follow
+// both directory changes and 'krakn use'. The git wrapper applies the account
+// time zone to git alone instead of the whole shell.
+var shellHooks = map[string]string{
+	"bash": `_krakn_env() { eval "$(command krakn env --shell bash 2>/dev/null)"; }
+case ";${PROMPT_COMMAND:-};" in
+  *";_krakn_env;"*) ;;
+  *) PROMPT_COMMAND="_krakn_env${PROMPT_COMMAND:+;$PROMPT_COMMAND}" ;;
+esac
+git() { if [ -n "${KRAKN_GIT_TZ:-}" ]; then TZ="$KRAKN_GIT_TZ" command git "$@"; else command git "$@"; fi; }
+`,
+	"zsh": `_krakn_env() { eval "$(command krakn env --shell zsh 2>/dev/null)"; }
+autoload -Uz add-zsh-hook
+add-zsh-hook precmd _krakn_env
+git() { if [[ -n "${KRAKN_GIT_TZ:-}" ]]; then TZ="$KRAKN_GIT_TZ" command git "$@"; else command git "$@"; fi; }
+`,
+	"fish": `function _krakn_env --on-event fish_prompt
+    command krakn env --shell fish 2>/dev/null | source
+end
+function git --wraps git
+    if test -n "$KRAKN_GIT_TZ"
+        TZ=$KRAKN_GIT_TZ command git $argv
+    else
+        command git $argv
+    end
+end
+`,
+}
+
+// envAccount returns the account git uses at path: the one whose email is the
+// effective user.email there, else the overridden, mapped or global current
+// account
+func envAccount(config *Config, path string) (*Account, string) {
+	if email := getRepoGitConfig(path, "user.email"); email != "" {
+		for i := range config.Accounts {
+			if config.Accounts[i].Email == email {
+				return &config.Accounts[i], "user.email is " + email
+			}
+		}
+	}
+	if repoRoot, err := findRepoRoot(path); err == nil {
+		if override := config.findOverride(repoRoot); override != nil {
+			if account := config.getAccount(override.Account); account != nil {
+				return account, "override for " + override.Repo
+			}
+		}
+	}
+	if account := config.findAccountForPath(path); account != nil {
+		return account, "directory mapping"
+	}
+	if account := config.getAccount(config.CurrentAccount); account != nil {
+		return account, "current account"
+	}
+	return nil, ""
+}
+
+// envLine renders an export, or an unset when value is empty
+func envLine(shell, name, value string) string {
+	switch {
+	case shell == "fish" && value == "":
+		return "set -e " + name
+	case shell == "fish":
+		return fmt.Sprintf("set -gx %s %s", name, shellQuote(value))
+	case value == "":
+		return "unset " + name
+	}
+	return fmt.Sprintf("export %s=%s", name, shellQuote(value))
+}
+
+var envCmd = &cobra.Command{
+	Use:   "env [path]",
+	Short: "Print shell variables for the account used in a directory",
+	Long: `Print the environment for the account git uses in a directory (default: the
+current one) as shell commands: KRAKN_ACCOUNT and, when the account has a time
+zone set with 'krakn timezone', KRAKN_GIT_TZ.
+
+Install the hook once to keep them current. It re-evaluates 'krakn env' before
+every prompt, and wraps git so that commits get the account's time zone while
+the rest of the shell keeps yours:
+
+  eval "$(krakn env --hook bash)"     # in ~/.bashrc
+  eval "$(krakn env --hook zsh)"      # in ~/.zshrc
+  krakn env --hook fish | source      # in ~/.config/fish/config.fish
+
+Git stamps commits with the offset of TZ. GIT_AUTHOR_DATE and
+GIT_COMMITTER_DATE, when set with an explicit offset (e.g. by rebase
+--committer-date-is-author-date or a script), take precedence, and commits made
+outside the shell, e.g. by an IDE, use the system time zone.
+
+Examples:
+  krakn env
+  krakn env ~/work/api --shell fish
+  krakn env --hook zsh`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shell, _ := cmd.Flags().GetString("shell")
+		hook, _ := cmd.Flags().GetString("hook")
+		if hook != "" {
+			script, ok := shellHooks[hook]
+			if !ok {
+				return fmt.Errorf("❌ Unsupported shell '%s'. Supported: bash, zsh, fish", hook)
+			}
+			fmt.Print(script)
+			return nil
+		}
+		if _, ok := shellHooks[shell]; !ok {
+			return fmt.Errorf("❌ Unsupported shell '%s'. Supported: bash, zsh, fish", shell)
+		}
+
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		path, err := filepath.Abs(expandUserPath(path))
+		if err != nil {
+			return err
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account, reason := envAccount(config, path)
+		if account == nil {
+			fmt.Println("# krakn: no account applies here")
+			fmt.Println(envLine(shell, "KRAKN_ACCOUNT", ""))
+			fmt.Println(envLine(shell, "KRAKN_GIT_TZ", ""))
+			return nil
+		}
+
+		fmt.Printf("# krakn: account '%s' (%s)\n", account.Name, reason)
+		if account.Timezone != "" {
+			offset := ""
+			if location, err := time.LoadLocation(account.Timezone); err == nil {
+				offset = " (" + time.Now().In(location).Format("-0700") + ")"
+			}
+			fmt.Printf("# Commits made through the git wrapper of 'krakn env --hook' are stamped in %s%s.\n", account.Timezone, offset)
+			fmt.Println("# An explicit offset in GIT_AUTHOR_DATE or GIT_COMMITTER_DATE takes precedence.")
+		}
+		fmt.Println(envLine(shell, "KRAKN_ACCOUNT", account.Name))
+		fmt.Println(envLine(shell, "KRAKN_GIT_TZ", account.Timezone))
+		return nil
+	},
+}
+
+var timezoneCmd = &cobra.Command{
+	Use:   "timezone <account-name> [zone]",
+	Short: "Set the time zone an account's commits are stamped in",
+	Long: `Set the time zone commit timestamps use for an account, e.g. the company's
+zone for work and UTC for open source. Zones are IANA names such as
+Europe/Berlin, America/New_York or UTC. Without a zone the current setting is
+shown.
+
+The zone is applied by the shell hook of 'krakn env', which sets TZ for git
+commands run in directories that use the account.
+
+Examples:
+  krakn timezone work Europe/Berlin
+  krakn timezone oss UTC
+  krakn timezone work            # Show the zone
+  krakn timezone work --unset    # Use the system time zone again`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		unset, _ := cmd.Flags().GetBool("unset")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+
+		switch {
+		case unset:
+			account.Timezone = ""
+		case len(args) == 2:
+			if _, err := time.LoadLocation(args[1]); err != nil || strings.EqualFold(args[1], "Local") {
+				return fmt.Errorf("❌ Unknown time zone '%s'. Use an IANA name such as Europe/Berlin or UTC", args[1])
+			}
+			account.Timezone = args[1]
+		default:
+			if account.Timezone == "" {
+				fmt.Printf("🕐 Account '%s' uses the system time zone\n", account.Name)
+			} else {
+				fmt.Printf("🕐 Account '%s' commits in %s\n", account.Name, account.Timezone)
+			}
+			return nil
+		}
+
+		if err := config.addAccount(*account); err != nil {
+			return errSaveConfig(err)
+		}
+		if account.Timezone == "" {
+			fmt.Printf("✅ Account '%s' uses the system time zone again\n", account.Name)
+			return nil
+		}
+		fmt.Printf("✅ Account '%s' commits in %s\n", account.Name, account.Timezone)
+		if os.Getenv("KRAKN_ACCOUNT") == "" {
+			fmt.Println("💡 The zone is applied by the shell hook; install it with: eval \"$(krakn env --hook bash)\"")
+		}
+		return nil
+	},
+}
+
+func init() {
+	envCmd.Flags().String("shell", "bash", "Syntax of the output: bash, zsh or fish")
+	envCmd.Flags().String("hook", "", "Print the shell hook for bash, zsh or fish")
+	timezoneCmd.Flags().Bool("unset", false, "Remove the account's time zone")
+	RootCmd.AddCommand(envCmd)
+	RootCmd.AddCommand(timezoneCmd)
+}
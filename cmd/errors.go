@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Error codes. Codes are stable: once released, a code keeps its meaning so
+// users can search for it and paste it into issues. Add new codes at the end.
+const (
+	codeConfigUnreadable = "KRKN-001"
+	codeAccountNotFound  = "KRKN-002"
+	codeNoAccounts       = "KRKN-003"
+	codeNotARepository   = "KRKN-004"
+	codeNoSSHKey         = "KRKN-005"
+	codeRunningAsRoot    = "KRKN-006"
+	codeFileLocked       = "KRKN-007"
+	codeCannotUnseal     = "KRKN-008"
+	codeConfigUnsaved    = "KRKN-009"
+	codeKeyDirUnusable   = "KRKN-010"
+)
+
+// errorCatalog explains each code for 'krakn explain KRKN-nnn'
+var errorCatalog = map[string]string{
+	codeConfigUnreadable: "~/.krakncat/config.json exists but cannot be read or is not valid JSON. A manual edit or an interrupted sync is the usual cause; 'krakn repair-config' restores one of the last three versions or salvages the intact accounts, and 'krakn revert' restores the version before the last krakn change.",
+	codeAccountNotFound:  "No account has the given name. Names are case-sensitive; 'krakn list' shows the configured ones.",
+	codeNoAccounts:       "The command needs at least one account, but none is configured yet.",
+	codeNotARepository:   "The path is not inside a git working tree. Run the command inside a repository or pass its path.",
+	codeNoSSHKey:         "The account has no SSH key configured, or the key file does not exist.",
+	codeRunningAsRoot:    "krakn was started as root (often through sudo) and would manage root's configuration instead of yours.",
+	codeFileLocked:       "Another krakn process is changing the same file. Stale locks of crashed processes are removed automatically after a while.",
+	codeCannotUnseal:     "A sealed account field could not be decrypted: the passphrase is wrong or the age identity is missing.",
+	codeConfigUnsaved:    "~/.krakncat/config.json could not be written, usually because of permissions or a full disk.",
+	codeKeyDirUnusable:   "The account keeps its keys in a directory of its own ('krakn key dir'), and that directory is missing, readable by other users or does not hold the key. An encrypted volume that is not mounted is the usual cause.",
+}
+
+// kraknError is a failure with a stable code and a concrete next step.
+// Commands return it like any error; Execute renders it.
+type kraknError struct {
+	Code  string
+	Cause string // One line, what went wrong
+	Try   string // A command or action that resolves it
+	Err   error  // Underlying error, if any
+}
+
+func (e *kraknError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Cause, e.Err)
+	}
+	return e.Cause
+}
+
+func (e *kraknError) Unwrap() error {
+	return e.Err
+}
+
+// newError creates a coded error. try may be empty when there is no single fix.
+func newError(code, try, format string, args ...interface{}) *kraknError {
+	return &kraknError{Code: code, Cause: fmt.Sprintf(format, args...), Try: try}
+}
+
+// errLoadConfig wraps a loadConfig failure
+func errLoadConfig(err error) error {
+	return &kraknError{
+		Code:  codeConfigUnreadable,
+		Cause: "Failed to load ~/.krakncat/config.json",
+		Try:   "krakn repair-config to restore a backup or salvage the accounts, or krakn revert to undo the last change",
+		Err:   err,
+	}
+}
+
+// errSaveConfig wraps a saveConfig failure. Errors that already carry a
+// code, e.g. from updateConfig, are returned as they are.
+func errSaveConfig(err error) error {
+	var coded *kraknError
+	if errors.As(err, &coded) {
+		return err
+	}
+	return &kraknError{Code: codeConfigUnsaved, Cause: "Failed to save config", Try: "check the permissions of ~/.krakncat", Err: err}
+}
+
+// errNoAccounts is returned by commands that need at least one account
+func errNoAccounts() error {
+	return newError(codeNoAccounts, "krakn add", "No accounts configured")
+}
+
+// accountNotFound reports an unknown account name with the available ones
+func (c *Config) accountNotFound(name string) error {
+	if len(c.Accounts) == 0 {
+		return newError(codeAccountNotFound, "krakn add", "Account '%s' not found; no accounts are configured", name)
+	}
+	var names []string
+	for _, account := range c.Accounts {
+		names = append(names, account.Name)
+	}
+	return newError(codeAccountNotFound, "krakn list", "Account '%s' not found. Available accounts: %s", name, strings.Join(names, ", "))
+}
+
+// errNotARepository reports a path outside any git working tree
+func errNotARepository(path string) error {
+	return newError(codeNotARepository, "cd into the repository, or pass its path", "'%s' is not a git repository", path)
+}
+
+// leadingMarker strips the emoji an ad-hoc error message may start with
+var leadingMarker = regexp.MustCompile(`^(❌|⚠️)\s*`)
+
+// presentError renders any error the same way: the cause, then the code and
+// the suggested next step for coded errors
+func presentError(w io.Writer, err error) {
+	var coded *kraknError
+	if !errors.As(err, &coded) {
+		fmt.Fprintf(w, "❌ %s\n", leadingMarker.ReplaceAllString(err.Error(), ""))
+		return
+	}
+	fmt.Fprintf(w, "❌ %s (%s)\n", leadingMarker.ReplaceAllString(err.Error(), ""), coded.Code)
+	if coded.Try != "" {
+		fmt.Fprintf(w, "💡 Try: %s\n", coded.Try)
+	}
+}
+
+// setupErrorPresenter hands error output from cobra to presentError. A coded
+// error is a runtime failure with its own advice, so the usage text that cobra
+// prints after errors is skipped for it.
+func setupErrorPresenter(cmd *cobra.Command) {
+	RootCmd.SilenceErrors = true
+	if run := cmd.RunE; run != nil {
+		cmd.RunE = func(c *cobra.Command, args []string) error {
+			err := run(c, args)
+			var coded *kraknError
+			if errors.As(err, &coded) {
+				c.SilenceUsage = true
+			}
+			return err
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		setupErrorPresenter(sub)
+	}
+}
@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// forgeRequest calls the REST API of a GitLab, Gitea or Forgejo provider with
+// the account's token. path is relative to the API root (/api/v4 or /api/v1).
+func forgeRequest(account *Account, method, path string, body, target interface{}) error {
+	provider := account.GetProvider()
+	var endpoint, header, value string
+	switch provider.Name {
+	case "gitlab":
+		endpoint, header, value = "https://"+provider.Hostname+"/api/v4"+path, "PRIVATE-TOKEN", account.Token
+	case "gitea", "forgejo":
+		endpoint, header, value = "https://"+provider.Hostname+"/api/v1"+path, "Authorization", "token "+account.Token
+	default:
+		return fmt.Errorf("❌ The %s API is not supported", provider.DisplayName)
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(header, value)
+	req.Header.Set("User-Agent", "krakn/"+Version)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := (&http.Client{Timeout: 30 * time.Second}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var payload struct {
+			Message interface{} `json:"message"`
+		}
+		if json.Unmarshal(data, &payload) == nil && payload.Message != nil {
+			return fmt.Errorf("%s API returned %d: %v", provider.DisplayName, resp.StatusCode, payload.Message)
+		}
+		return fmt.Errorf("%s API returned %d", provider.DisplayName, resp.StatusCode)
+	}
+	if target != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, target); err != nil {
+			return fmt.Errorf("failed to parse %s API response: %w", provider.DisplayName, err)
+		}
+	}
+	return nil
+}
+
+// forkRepository forks owner/repo into the account (or into org) and returns
+// the path of the fork, e.g. "me/repo". Providers create forks in the
+// background, so this waits until the fork can be cloned.
+func forkRepository(config *Config, account *Account, upstream, org string) (string, error) {
+	if account.GetProvider().Name == "github" {
+		client, err := requireGitHubFeature(config, account, "repos")
+		if err != nil {
+			return "", err
+		}
+		body := map[string]interface{}{}
+		if org != "" {
+			body["organization"] = org
+		}
+		var fork struct {
+			FullName string `json:"full_name"`
+		}
+		if _, err := client.do(http.MethodPost, "/repos/"+upstream+"/forks", body, &fork); err != nil {
+			return "", err
+		}
+		for i := 0; i < 30; i++ {
+			if err := client.get("/repos/"+fork.FullName, nil); err == nil {
+				return fork.FullName, nil
+			}
+			time.Sleep(2 * time.Second)
+		}
+		return "", fmt.Errorf("❌ GitHub did not finish creating %s in time; clone it later", fork.FullName)
+	}
+
+	if err := config.revealAccount(account); err != nil {
+		return "", err
+	}
+	if account.Token == "" {
+		return "", fmt.Errorf("❌ Account '%s' has no API token. Add one with 'krakn token set %s'", account.Name, account.Name)
+	}
+
+	if account.GetProvider().Name == "gitlab" {
+		body := map[string]interface{}{}
+		if org != "" {
+			body["namespace_path"] = org
+		}
+		project := "/projects/" + url.PathEscape(upstream)
+		var fork struct {
+			ID           int64  `json:"id"`
+			Path         string `json:"path_with_namespace"`
+			ImportStatus string `json:"import_status"`
+		}
+		if err := forgeRequest(account, http.MethodPost, project+"/fork", body, &fork); err != nil {
+			return "", err
+		}
+		for i := 0; i < 30 && fork.ImportStatus != "" && fork.ImportStatus != "finished" && fork.ImportStatus != "none"; i++ {
+			if fork.ImportStatus == "failed" {
+				return "", fmt.Errorf("❌ GitLab failed to create the fork %s", fork.Path)
+			}
+			time.Sleep(2 * time.Second)
+			if err := forgeRequest(account, http.MethodGet, fmt.Sprintf("/projects/%d", fork.ID), nil, &fork); err != nil {
+				return "", err
+			}
+		}
+		return fork.Path, nil
+	}
+
+	body := map[string]interface{}{}
+	if org != "" {
+		body["organization"] = org
+	}
+	var fork struct {
+		FullName string `json:"full_name"`
+	}
+	if err := forgeRequest(account, http.MethodPost, "/repos/"+upstream+"/forks", body, &fork); err != nil {
+		return "", err
+	}
+	return fork.FullName, nil
+}
+
+// parseUpstream accepts owner/repo or a remote URL and returns the provider
+// host (empty for owner/repo) and the repository path without .git
+func parseUpstream(config *Config, arg string) (host, path string, err error) {
+	if !strings.Contains(arg, ":") {
+		path = strings.Trim(arg, "/")
+		if strings.Count(path, "/") < 1 {
+			return "", "", fmt.Errorf("❌ Expected owner/repo or a repository URL, got '%s'", arg)
+		}
+		return "", strings.TrimSuffix(path, ".git"), nil
+	}
+
+	remote, err := parseRemoteURL(arg)
+	if err != nil {
+		return "", "", fmt.Errorf("❌ %w", err)
+	}
+	host = sshHostnameFor(remote.Host)
+	if account := config.findAccountByHost(host); account != nil && host != account.GetProvider().Hostname {
+		host = account.GetProvider().Hostname
+	}
+	return host, azureRepoPath(remote.Host, remote.repoPath()), nil
+}
+
+var repoCmd = &cobra.Command{
+	Use:   "repo",
+	Short: "Work with repositories on the provider",
+	Long: `Work with repositories on the provider through an account.
+
+Examples:
+  krakn repo fork golang/go --account oss`,
+}
+
+var repoForkCmd = &cobra.Command{
+	Use:   "fork <owner/repo|url> [directory]",
+	Short: "Fork a repository into an account and clone the fork",
+	Long: `Fork an upstream repository into an account (or one of its organizations)
+through the provider API, clone the fork with the account's host alias and
+identity, and set up the remotes the usual way:
+
+  origin     your fork, where you push branches
+  upstream   the original repository, to fetch from
+
+The account is --account, the account mapped to the target directory, or the
+current account. It needs an API token ('krakn token set'). GitHub, GitLab,
+Gitea and Forgejo are supported.
+
+Examples:
+  krakn repo fork golang/go --account oss
+  krakn repo fork https://github.com/spf13/cobra ~/oss/cobra
+  krakn repo fork acme/tool --account work --org acme-forks`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountName, _ := cmd.Flags().GetString("account")
+		org, _ := cmd.Flags().GetString("org")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		host, upstream, err := parseUpstream(config, args[0])
+		if err != nil {
+			return err
+		}
+
+		dest := filepath.Base(upstream)
+		if len(args) == 2 {
+			dest = args[1]
+		}
+		dest, err = filepath.Abs(expandUserPath(dest))
+		if err != nil {
+			return err
+		}
+		if fileExists(dest) {
+			return fmt.Errorf("❌ %s already exists", contractHomePath(dest))
+		}
+
+		var account *Account
+		if accountName != "" {
+			if account = config.getAccount(accountName); account == nil {
+				return config.accountNotFound(accountName)
+			}
+		} else if account = config.findAccountForPath(filepath.Dir(dest)); account == nil {
+			account = config.getAccount(config.CurrentAccount)
+		}
+		if account == nil {
+			return newError(codeNoAccounts, "pass --account", "No account selected for the fork")
+		}
+		provider := account.GetProvider()
+		if host == "" {
+			host = provider.Hostname
+		}
+		if !strings.EqualFold(host, provider.Hostname) {
+			return fmt.Errorf("❌ %s is on %s, but account '%s' is on %s", upstream, host, account.Name, provider.Hostname)
+		}
+
+		if offlineMode {
+			return fmt.Errorf("❌ Forking needs the %s API, which is not available with --offline", provider.DisplayName)
+		}
+
+		spin := startSpinner(fmt.Sprintf("Forking %s into %s", upstream, account.Name))
+		forkPath, err := forkRepository(config, account, upstream, org)
+		spin.Stop()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("🍴 Forked %s → %s\n", upstream, forkPath)
+
+		forkRemote := &remoteURL{Scheme: "scp", Host: provider.Hostname, Path: forkPath + ".git"}
+		upstreamRemote := &remoteURL{Scheme: "scp", Host: provider.Hostname, Path: upstream + ".git"}
+		spin = startSpinner("Cloning " + forkPath)
+		err = cloneWorkspaceRepo(config, workspaceRepo{URL: accountRemoteURL(forkRemote, account), Account: account.Name}, dest)
+		spin.Stop()
+		if err != nil {
+			return fmt.Errorf("failed to clone %s: %w", forkPath, err)
+		}
+
+		upstreamURL := accountRemoteURL(upstreamRemote, account)
+		if err := traceExec(exec.Command("git", "-C", dest, "remote", "add", "upstream", upstreamURL)).Run(); err != nil {
+			return fmt.Errorf("failed to add the upstream remote: %w", err)
+		}
+		fetch := traceExec(exec.Command("git", "-C", dest, "fetch", "--quiet", "upstream"))
+		fetch.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		if output, err := fetch.CombinedOutput(); err != nil {
+			fmt.Printf("⚠️  Could not fetch upstream: %s\n", firstLine(strings.TrimSpace(string(output))))
+		}
+
+		fmt.Printf("📁 Cloned into %s as '%s'\n", contractHomePath(dest), account.Name)
+		fmt.Printf("   origin   %s\n", accountRemoteURL(forkRemote, account))
+		fmt.Printf("   upstream %s\n", upstreamURL)
+		return nil
+	},
+}
+
+func init() {
+	repoForkCmd.Flags().String("account", "", "Account to fork into (default: the directory mapping or current account)")
+	repoForkCmd.Flags().String("org", "", "Fork into this organization or group instead of the account")
+	repoCmd.AddCommand(repoForkCmd)
+	RootCmd.AddCommand(repoCmd)
+}
@@ -0,0 +1,482 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// gitConfigFile is a minimal line-preserving representation of a git config
+// file. Only the sections krakncat edits are interpreted; everything else is
+// written back byte-for-byte.
+type gitConfigFile struct {
+	Path     string
+	Sections []*gitConfigSection
+}
+
+// gitConfigSection holds a section header and its raw lines.
+// The first section of a file may be an unnamed preamble (comments, blank lines).
+type gitConfigSection struct {
+	Name       string // Section name in lower case, e.g. "includeif"
+	Subsection string // Quoted subsection value, e.g. "gitdir:~/work/"
+	Lines      []string
+}
+
+// readGitConfigFile parses a git config file; a missing file yields an empty config
+func readGitConfigFile(path string) (*gitConfigFile, error) {
+	file := &gitConfigFile{Path: path}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	text := strings.TrimSuffix(string(content), "\n")
+	if text == "" {
+		return file, nil
+	}
+
+	current := &gitConfigSection{}
+	file.Sections = append(file.Sections, current)
+	for _, line := range strings.Split(text, "\n") {
+		if name, sub, ok := parseGitConfigHeader(line); ok {
+			current = &gitConfigSection{Name: name, Subsection: sub}
+			file.Sections = append(file.Sections, current)
+		}
+		current.Lines = append(current.Lines, line)
+	}
+
+	return file, nil
+}
+
+// parseGitConfigHeader recognizes [section] and [section "subsection"] lines
+func parseGitConfigHeader(line string) (name, subsection string, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "[") {
+		return "", "", false
+	}
+	end := strings.Index(trimmed, "]")
+	if end == -1 {
+		return "", "", false
+	}
+
+	header := strings.TrimSpace(trimmed[1:end])
+	if quote := strings.Index(header, "\""); quote != -1 {
+		name = strings.TrimSpace(header[:quote])
+		subsection = strings.TrimSuffix(header[quote+1:], "\"")
+		subsection = strings.ReplaceAll(subsection, `\"`, `"`)
+	} else if dot := strings.Index(header, "."); dot != -1 {
+		// Deprecated [section.subsection] syntax
+		name = header[:dot]
+		subsection = header[dot+1:]
+	} else {
+		name = header
+	}
+
+	return strings.ToLower(name), subsection, true
+}
+
+// String renders the config back to text
+func (f *gitConfigFile) String() string {
+	var lines []string
+	for _, section := range f.Sections {
+		lines = append(lines, section.Lines...)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// save writes the config back to disk, keeping the existing file mode.
+// Callers hold withFileLock(f.Path) from readGitConfigFile on, so changes
+// another krakn made in between are not overwritten.
+func (f *gitConfigFile) save() error {
+	if skipDotfileEdit(f.Path, "changes") {
+		return nil
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(f.Path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	return withFileLock(f.Path, func() error {
+		trackFile(f.Path)
+		if err := writeFileAtomic(f.Path, []byte(f.String()), mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.Path, err)
+		}
+		return nil
+	})
+}
+
+// findSections returns all sections with the given name (case-insensitive)
+func (f *gitConfigFile) findSections(name string) []*gitConfigSection {
+	var found []*gitConfigSection
+	for _, section := range f.Sections {
+		if section.Name == strings.ToLower(name) {
+			found = append(found, section)
+		}
+	}
+	return found
+}
+
+// findSection returns the section with the given name and subsection
+func (f *gitConfigFile) findSection(name, subsection string) *gitConfigSection {
+	for _, section := range f.findSections(name) {
+		if section.Subsection == subsection {
+			return section
+		}
+	}
+	return nil
+}
+
+// addSection appends a new section, separated from the previous content by a blank line
+func (f *gitConfigFile) addSection(name, subsection string) *gitConfigSection {
+	header := fmt.Sprintf("[%s]", name)
+	if subsection != "" {
+		header = fmt.Sprintf("[%s \"%s\"]", name, strings.ReplaceAll(subsection, `"`, `\"`))
+	}
+
+	if len(f.Sections) > 0 {
+		last := f.Sections[len(f.Sections)-1]
+		if len(last.Lines) > 0 && strings.TrimSpace(last.Lines[len(last.Lines)-1]) != "" {
+			last.Lines = append(last.Lines, "")
+		}
+	}
+
+	section := &gitConfigSection{Name: strings.ToLower(name), Subsection: subsection, Lines: []string{header}}
+	f.Sections = append(f.Sections, section)
+	return section
+}
+
+// removeSection drops a section and the blank line that separated it
+func (f *gitConfigFile) removeSection(target *gitConfigSection) {
+	for i, section := range f.Sections {
+		if section != target {
+			continue
+		}
+		f.Sections = append(f.Sections[:i], f.Sections[i+1:]...)
+		if i > 0 {
+			prev := f.Sections[i-1]
+			if n := len(prev.Lines); n > 0 && strings.TrimSpace(prev.Lines[n-1]) == "" {
+				prev.Lines = prev.Lines[:n-1]
+			}
+		}
+		return
+	}
+}
+
+// setSubsection rewrites the section header with a new subsection,
+// keeping the original spelling of the section name
+func (s *gitConfigSection) setSubsection(subsection string) {
+	header := strings.TrimLeft(strings.TrimSpace(s.Lines[0]), "[")
+	name := strings.TrimSpace(strings.FieldsFunc(header, func(r rune) bool {
+		return r == '"' || r == ']' || r == ' ' || r == '\t'
+	})[0])
+	s.Subsection = subsection
+	s.Lines[0] = fmt.Sprintf("[%s \"%s\"]", name, strings.ReplaceAll(subsection, `"`, `\"`))
+}
+
+// parseGitConfigLine parses a "name = value" line with git's quoting, escape
+// and inline comment rules. A bare key has the empty value, as 'git config
+// --get' prints it. Continuation lines are left to git. Every reader of
+// gitconfig files uses it, so they agree on what a file says.
+func parseGitConfigLine(line string) (name, value string, ok bool, err error) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+		return "", "", false, nil
+	}
+
+	eq := strings.Index(trimmed, "=")
+	if eq == -1 {
+		// A bare key has no value; git treats it as boolean true
+		name = strings.ToLower(strings.Fields(trimmed)[0])
+		return name, "", true, nil
+	}
+	name = strings.ToLower(strings.TrimSpace(trimmed[:eq]))
+	raw := strings.TrimSpace(trimmed[eq+1:])
+
+	var b strings.Builder
+	inQuotes := false
+	pendingSpace := ""
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(raw) {
+				return "", "", false, errUnsupportedGitConfig
+			}
+			i++
+			b.WriteString(pendingSpace)
+			pendingSpace = ""
+			switch raw[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'b':
+				b.WriteByte('\b')
+			case '"', '\\':
+				b.WriteByte(raw[i])
+			default:
+				return "", "", false, errUnsupportedGitConfig
+			}
+		case c == '"':
+			b.WriteString(pendingSpace)
+			pendingSpace = ""
+			inQuotes = !inQuotes
+		case !inQuotes && (c == '#' || c == ';'):
+			return name, b.String(), true, nil
+		case !inQuotes && (c == ' ' || c == '\t'):
+			// Whitespace is kept only when something follows it
+			pendingSpace += string(c)
+		default:
+			b.WriteString(pendingSpace)
+			pendingSpace = ""
+			b.WriteByte(c)
+		}
+	}
+	if inQuotes {
+		return "", "", false, errUnsupportedGitConfig
+	}
+	return name, b.String(), true, nil
+}
+
+// parseGitConfigEntry splits a "key = value" line of a section, ignoring
+// comments. The value is parsed like git does; a line git would not accept
+// still counts as an entry of its key, so set and unset can replace it.
+func parseGitConfigEntry(line string) (key, value string, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "[") {
+		return "", "", false
+	}
+	key, value, ok, err := parseGitConfigLine(line)
+	if err != nil {
+		name, raw, _ := strings.Cut(trimmed, "=")
+		return strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(raw), true
+	}
+	return key, value, ok
+}
+
+// get returns the last value for a key in the section
+func (s *gitConfigSection) get(key string) string {
+	value := ""
+	for _, line := range s.Lines[1:] {
+		if k, v, ok := parseGitConfigEntry(line); ok && k == strings.ToLower(key) {
+			value = v
+		}
+	}
+	return value
+}
+
+// getAll returns every value of a key in the section, in order
+func (s *gitConfigSection) getAll(key string) []string {
+	var values []string
+	for _, line := range s.Lines[1:] {
+		if k, v, ok := parseGitConfigEntry(line); ok && k == strings.ToLower(key) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// set replaces the first value for a key, or appends it when missing
+func (s *gitConfigSection) set(key, value string) {
+	for i, line := range s.Lines {
+		if i == 0 {
+			continue
+		}
+		if k, _, ok := parseGitConfigEntry(line); ok && k == strings.ToLower(key) {
+			s.Lines[i] = fmt.Sprintf("\t%s = %s", key, value)
+			return
+		}
+	}
+
+	// Insert after the last non-blank line so trailing spacing is preserved
+	insertAt := len(s.Lines)
+	for insertAt > 1 && strings.TrimSpace(s.Lines[insertAt-1]) == "" {
+		insertAt--
+	}
+	entry := fmt.Sprintf("\t%s = %s", key, value)
+	s.Lines = append(s.Lines[:insertAt], append([]string{entry}, s.Lines[insertAt:]...)...)
+}
+
+// unset removes every value of a key from the section
+func (s *gitConfigSection) unset(key string) {
+	lines := s.Lines[:1]
+	for _, line := range s.Lines[1:] {
+		if k, _, ok := parseGitConfigEntry(line); ok && k == strings.ToLower(key) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	s.Lines = lines
+}
+
+// readGitConfigValue returns the last value of a key without subsection in
+// one config file, or "" when the file does not set it
+func readGitConfigValue(path, section, name string) string {
+	file, err := readGitConfigFile(path)
+	if err != nil {
+		return ""
+	}
+	value := ""
+	for _, candidate := range file.findSections(section) {
+		if candidate.Subsection == "" && candidate.has(name) {
+			value = candidate.get(name)
+		}
+	}
+	return value
+}
+
+// gitConfigValue is a key to write with applyGitConfigValues
+type gitConfigValue struct {
+	Key   string // section[.subsection].name, e.g. user.email
+	Value string
+	Unset bool // Remove the key instead of setting it
+}
+
+// gitConfigChange is a key whose value a batch write changed
+type gitConfigChange struct {
+	Key string
+	Old string // Empty when the key was not set
+	New string
+}
+
+// splitGitConfigKey splits section[.subsection].name; the subsection may contain dots
+func splitGitConfigKey(key string) (section, subsection, name string, ok bool) {
+	first := strings.Index(key, ".")
+	last := strings.LastIndex(key, ".")
+	if first <= 0 || last == len(key)-1 {
+		return "", "", "", false
+	}
+	if first != last {
+		subsection = key[first+1 : last]
+	}
+	return key[:first], subsection, key[last+1:], true
+}
+
+// formatGitConfigValue quotes and escapes a value where git would otherwise
+// read it differently (comment characters, surrounding spaces, backslashes)
+func formatGitConfigValue(value string) string {
+	if !strings.ContainsAny(value, "#;\"\\\n\t") && strings.TrimSpace(value) == value {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// applyGitConfigValues sets several keys of one config file with a single read
+// and write, instead of running 'git config' once per key. Keys that already
+// hold the value are left alone; the changes made are returned in order.
+func applyGitConfigValues(path string, values []gitConfigValue) ([]gitConfigChange, error) {
+	var keys []string
+	for _, v := range values {
+		keys = append(keys, v.Key)
+	}
+	if skipDotfileEdit(path, "setting "+joinWords(keys)) {
+		return nil, nil
+	}
+
+	unlock, err := lockFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	file, err := readGitConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []gitConfigChange
+	for _, v := range values {
+		sectionName, subsection, name, ok := splitGitConfigKey(v.Key)
+		if !ok {
+			return nil, fmt.Errorf("invalid git config key: %s", v.Key)
+		}
+
+		// git updates the last section that sets the key, so later
+		// duplicates of a section do not shadow the new value
+		var section *gitConfigSection
+		for _, candidate := range file.findSections(sectionName) {
+			if candidate.Subsection != subsection {
+				continue
+			}
+			if section == nil || candidate.has(name) {
+				section = candidate
+			}
+		}
+		if section == nil {
+			section = file.addSection(sectionName, subsection)
+		}
+
+		old := section.get(name)
+		if v.Unset {
+			if section.has(name) {
+				section.unset(name)
+				changes = append(changes, gitConfigChange{Key: v.Key, Old: old})
+			}
+			continue
+		}
+		if section.has(name) && old == v.Value {
+			continue
+		}
+		section.set(name, formatGitConfigValue(v.Value))
+		changes = append(changes, gitConfigChange{Key: v.Key, Old: old, New: v.Value})
+	}
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	if err := file.save(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// has reports whether the section sets a key
+func (s *gitConfigSection) has(key string) bool {
+	for _, line := range s.Lines[1:] {
+		if k, _, ok := parseGitConfigEntry(line); ok && k == strings.ToLower(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasEntries reports whether the section sets any key; comments and blank
+// lines do not count
+func (s *gitConfigSection) hasEntries() bool {
+	for _, line := range s.Lines[1:] {
+		if _, _, ok := parseGitConfigEntry(line); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// printGitConfigChanges shows the consolidated diff of a batch write
+func printGitConfigChanges(path string, changes []gitConfigChange) {
+	if len(changes) == 0 && isUserDotfile(path) && localOnlyEnabled() {
+		// The skipped edit has been reported already
+		return
+	}
+	if len(changes) == 0 {
+		fmt.Printf("ℹ️  %s already up to date\n", contractHomePath(path))
+		return
+	}
+	fmt.Printf("📝 %s\n", contractHomePath(path))
+	for _, change := range changes {
+		if change.Old != "" {
+			fmt.Printf("   - %s = %s\n", change.Key, change.Old)
+		}
+		if change.New != "" || change.Old == "" {
+			fmt.Printf("   + %s = %s\n", change.Key, change.New)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseGitConfigLine(t *testing.T) {
+	tests := []struct {
+		line  string
+		name  string
+		value string
+		ok    bool
+	}{
+		{"\temail = a@b.c", "email", "a@b.c", true},
+		{"\temail = a@b.c # work", "email", "a@b.c", true},
+		{"\temail = a@b.c ; work", "email", "a@b.c", true},
+		{"\tName = \"Jane  Doe\"", "name", "Jane  Doe", true},
+		{"\tname = Jane   Doe  ", "name", "Jane   Doe", true},
+		{"\tpath = \"~/a#b.gitconfig\"", "path", "~/a#b.gitconfig", true},
+		{"\tmessage = a\\tb\\\"c\\\\", "message", "a\tb\"c\\", true},
+		{"\tgpgsign", "gpgsign", "", true},
+		{"\tempty =", "empty", "", true},
+		{"# comment", "", "", false},
+		{"; comment", "", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		name, value, ok, err := parseGitConfigLine(tt.line)
+		if err != nil || name != tt.name || value != tt.value || ok != tt.ok {
+			t.Errorf("parseGitConfigLine(%q) = %q, %q, %v, %v; want %q, %q, %v", tt.line, name, value, ok, err, tt.name, tt.value, tt.ok)
+		}
+	}
+
+	for _, line := range []string{"\tvalue = \"open", "\tvalue = a\\q", "\tvalue = a\\"} {
+		if _, _, _, err := parseGitConfigLine(line); err != errUnsupportedGitConfig {
+			t.Errorf("parseGitConfigLine(%q) error = %v, want errUnsupportedGitConfig", line, err)
+		}
+	}
+}
+
+// TestGitConfigReadersAgree reads one file through the line-preserving editor
+// and the resolver; both must report the same values
+func TestGitConfigReadersAgree(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	content := "[user]\n\tname = \"Jane Doe\" ; personal\n\temail = a@b.c # work\n[commit]\n\tgpgsign\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := readGitConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver := &gitConfigResolver{config: &resolvedGitConfig{}}
+	if err := resolver.readFile(path); err != nil {
+		t.Fatal(err)
+	}
+	resolved := resolver.config
+
+	for _, key := range [][2]string{{"user", "name"}, {"user", "email"}, {"commit", "gpgsign"}} {
+		edited := file.findSections(key[0])[0].get(key[1])
+		value, found := resolved.get(key[0] + "." + key[1])
+		if !found || value != edited {
+			t.Errorf("%s.%s: editor reads %q, resolver %q (found %v)", key[0], key[1], edited, value, found)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// githubNextPage extracts the next page URL from a Link header
+var githubNextPage = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// githubAPIError is a failed GitHub API request. The headers carry what is
+// needed to explain the failure: SSO enforcement and the scopes or
+// fine-grained permissions the endpoint accepts.
+type githubAPIError struct {
+	Status              int
+	Message             string
+	SSO                 string // X-GitHub-SSO, e.g. "required; url=https://github.com/orgs/acme/sso?..."
+	AcceptedScopes      string // X-Accepted-OAuth-Scopes
+	AcceptedPermissions string // X-Accepted-GitHub-Permissions
+}
+
+func (e *githubAPIError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("GitHub API returned %d: %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("GitHub API returned %d", e.Status)
+}
+
+// ssoURL returns the authorization URL from an X-GitHub-SSO header
+func (e *githubAPIError) ssoURL() string {
+	for _, part := range strings.Split(e.SSO, ";") {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "url=") {
+			return strings.TrimPrefix(part, "url=")
+		}
+	}
+	return ""
+}
+
+// githubClient talks to the REST API of github.com or GitHub Enterprise Server
+type githubClient struct {
+	BaseURL string
+	Token   string
+	// Scopes are the classic token scopes reported by the last response.
+	// Fine-grained tokens report none, and Classic stays false.
+	Scopes  string
+	Classic bool
+	http    *http.Client
+}
+
+// githubAPIBase returns the REST API root for a GitHub provider
+func githubAPIBase(provider Provider) string {
+	if provider.Hostname == "" || provider.Hostname == "github.com" {
+		return "https://api.github.com"
+	}
+	return fmt.Sprintf("https://%s/api/v3", provider.Hostname)
+}
+
+// newGitHubClient returns an API client authenticated with the account's token
+func newGitHubClient(config *Config, account *Account) (*githubClient, error) {
+	if offlineMode {
+		return nil, fmt.Errorf("❌ The GitHub API is not available with --offline")
+	}
+	if provider := account.GetProvider(); provider.Name != "github" {
+		return nil, fmt.Errorf("❌ Account '%s' is not a GitHub account", account.Name)
+	}
+	if err := config.revealAccount(account); err != nil {
+		return nil, err
+	}
+	if account.Token == "" {
+		return nil, fmt.Errorf("❌ Account '%s' has no API token. Add one with 'krakn token set %s'", account.Name, account.Name)
+	}
+	return &githubClient{
+		BaseURL: githubAPIBase(account.GetProvider()),
+		Token:   account.Token,
+		http:    &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+// do sends a request and decodes a JSON response into target. Non-2xx
+// responses are returned as *githubAPIError.
+func (c *githubClient) do(method, path string, body, target interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	endpoint := path
+	if !strings.HasPrefix(path, "https://") && !strings.HasPrefix(path, "http://") {
+		endpoint = c.BaseURL + path
+	}
+	req, err := http.NewRequest(method, endpoint, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "krakn/"+Version)
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	if scopes, ok := resp.Header["X-Oauth-Scopes"]; ok {
+		c.Scopes = strings.Join(scopes, ", ")
+		c.Classic = true
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &githubAPIError{
+			Status:              resp.StatusCode,
+			SSO:                 resp.Header.Get("X-GitHub-SSO"),
+			AcceptedScopes:      resp.Header.Get("X-Accepted-OAuth-Scopes"),
+			AcceptedPermissions: resp.Header.Get("X-Accepted-GitHub-Permissions"),
+		}
+		var payload struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &payload) == nil {
+			apiErr.Message = payload.Message
+		}
+		return resp, apiErr
+	}
+
+	if target != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, target); err != nil {
+			return resp, fmt.Errorf("failed to parse GitHub API response: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+// get fetches a single API resource
+func (c *githubClient) get(path string, target interface{}) error {
+	_, err := c.do(http.MethodGet, path, nil, target)
+	return err
+}
+
+// getAll fetches every page of a list endpoint into target, which must be a
+// pointer to a slice
+func (c *githubClient) getAll(path string, target interface{}) error {
+	var items []json.RawMessage
+	for path != "" {
+		var page []json.RawMessage
+		resp, err := c.do(http.MethodGet, path, nil, &page)
+		if err != nil {
+			return err
+		}
+		items = append(items, page...)
+
+		path = ""
+		if match := githubNextPage.FindStringSubmatch(resp.Header.Get("Link")); match != nil {
+			path = match[1]
+		}
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
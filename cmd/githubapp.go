@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// OrgApp is a GitHub App installed in an organization. It lets krakn run
+// org-wide checks without anyone's personal access token.
+type OrgApp struct {
+	Org        string `json:"org"`
+	AppID      string `json:"app_id"`
+	PrivateKey string `json:"private_key"`        // Path to the app's PEM private key, as issued by GitHub
+	Hostname   string `json:"hostname,omitempty"` // GitHub Enterprise Server host; empty for github.com
+}
+
+// getOrgApp returns the GitHub App configured for an organization
+func (c *Config) getOrgApp(org string) *OrgApp {
+	for i := range c.OrgApps {
+		if c.OrgApps[i].Org == org {
+			return &c.OrgApps[i]
+		}
+	}
+	return nil
+}
+
+// loadAppPrivateKey reads the RSA key GitHub issues for an app (PKCS#1 or PKCS#8 PEM)
+func loadAppPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read app private key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("❌ %s is not a PEM private key", path)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse app private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("❌ %s is not an RSA key", path)
+	}
+	return key, nil
+}
+
+// appJWT creates the short-lived token that authenticates as the app itself
+func appJWT(appID string, key *rsa.PrivateKey) (string, error) {
+	encode := func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return base64.RawURLEncoding.EncodeToString(data), nil
+	}
+
+	// Backdate iat to allow for clock drift; GitHub accepts at most 10 minutes
+	now := time.Now()
+	header, err := encode(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := encode(map[string]interface{}{
+		"iat": now.Add(-60 * time.Second).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signed := header + "." + claims
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign app token: %w", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// newAppClient returns an API client authenticated as the app's installation
+// in the organization
+func newAppClient(app *OrgApp) (*githubClient, error) {
+	if offlineMode {
+		return nil, fmt.Errorf("❌ The GitHub API is not available with --offline")
+	}
+	key, err := loadAppPrivateKey(app.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	jwt, err := appJWT(app.AppID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &githubClient{
+		BaseURL: githubAPIBase(Provider{Hostname: app.Hostname}),
+		Token:   jwt,
+		http:    &http.Client{Timeout: 15 * time.Second},
+	}
+
+	var installation struct {
+		ID int64 `json:"id"`
+	}
+	if err := client.get("/orgs/"+url.PathEscape(app.Org)+"/installation", &installation); err != nil {
+		return nil, fmt.Errorf("❌ App %s is not installed in '%s': %w", app.AppID, app.Org, err)
+	}
+
+	var access struct {
+		Token string `json:"token"`
+	}
+	if _, err := client.do(http.MethodPost, fmt.Sprintf("/app/installations/%d/access_tokens", installation.ID), nil, &access); err != nil {
+		return nil, fmt.Errorf("failed to create installation token: %w", err)
+	}
+	client.Token = access.Token
+	return client, nil
+}
+
+var orgAppCmd = &cobra.Command{
+	Use:   "app",
+	Short: "Configure a GitHub App used for organization-wide checks",
+	Long: `Configure a GitHub App installation for an organization. The app ID and private
+key are supplied by the organization's administrators; the app needs the
+organization permission "Members: read", and "Contents: read" on repositories
+for the commit email checks of 'krakn org report'. 'krakn org audit' and
+'krakn org report' then run without a personal access token.
+
+Examples:
+  krakn org app set acme --app-id 123456 --private-key ~/keys/krakn-acme.pem
+  krakn org app remove acme`,
+}
+
+var orgAppSetCmd = &cobra.Command{
+	Use:   "set <org>",
+	Short: "Store the GitHub App credentials for an organization",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appID, _ := cmd.Flags().GetString("app-id")
+		keyPath, _ := cmd.Flags().GetString("private-key")
+		hostname, _ := cmd.Flags().GetString("host")
+		if appID == "" || keyPath == "" {
+			return fmt.Errorf("❌ Both --app-id and --private-key are required")
+		}
+		keyPath = expandUserPath(keyPath)
+		if _, err := loadAppPrivateKey(keyPath); err != nil {
+			return err
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		app := OrgApp{Org: args[0], AppID: appID, PrivateKey: keyPath, Hostname: hostname}
+		if err := config.update(func(config *Config) error {
+			if existing := config.getOrgApp(app.Org); existing != nil {
+				*existing = app
+			} else {
+				config.OrgApps = append(config.OrgApps, app)
+			}
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("✅ GitHub App %s configured for '%s'\n", appID, app.Org)
+
+		if !offlineMode {
+			spin := startSpinner("Checking the installation")
+			_, err := newAppClient(&app)
+			spin.Stop()
+			if err != nil {
+				fmt.Printf("⚠️  %v\n", err)
+			} else {
+				fmt.Println("✅ Installation token obtained")
+			}
+		}
+		return nil
+	},
+}
+
+var orgAppRemoveCmd = &cobra.Command{
+	Use:   "remove <org>",
+	Short: "Forget the GitHub App credentials of an organization",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if config.getOrgApp(args[0]) == nil {
+			return fmt.Errorf("❌ No GitHub App configured for '%s'", args[0])
+		}
+		if err := config.update(func(config *Config) error {
+			for i, app := range config.OrgApps {
+				if app.Org == args[0] {
+					config.OrgApps = append(config.OrgApps[:i], config.OrgApps[i+1:]...)
+					break
+				}
+			}
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("✅ Removed the GitHub App of '%s'. The key file was kept.\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	orgAppSetCmd.Flags().String("app-id", "", "GitHub App ID")
+	orgAppSetCmd.Flags().String("private-key", "", "Path to the app's private key (.pem)")
+	orgAppSetCmd.Flags().String("host", "", "GitHub Enterprise Server hostname (default github.com)")
+	orgAppCmd.AddCommand(orgAppSetCmd)
+	orgAppCmd.AddCommand(orgAppRemoveCmd)
+	orgCmd.AddCommand(orgAppCmd)
+}
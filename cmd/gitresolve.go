@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errUnsupportedGitConfig means the native resolver met something it does not
+// implement (e.g. hasconfig: includes or continuation lines), so the caller
+// must ask git itself
+var errUnsupportedGitConfig = errors.New("git config feature not supported natively")
+
+// gitConfigEntry is one key = value assignment together with the file it came from
+type gitConfigEntry struct {
+	Key    string // Normalized "section.subsection.name"; section and name lower case
+	Value  string
+	Origin string
+}
+
+// resolvedGitConfig is the effective configuration of a repository, in the
+// order git applies it (system, global, local, with includes expanded)
+type resolvedGitConfig struct {
+	Entries []gitConfigEntry
+}
+
+// get returns the last value of a key, as 'git config --get' does
+func (c *resolvedGitConfig) get(key string) (string, bool) {
+	key = normalizeGitConfigKey(key)
+	for i := len(c.Entries) - 1; i >= 0; i-- {
+		if c.Entries[i].Key == key {
+			return c.Entries[i].Value, true
+		}
+	}
+	return "", false
+}
+
+// normalizeGitConfigKey lower-cases the section and variable name of a key,
+// keeping the subsection's case, e.g. remote.Origin.URL → remote.Origin.url
+func normalizeGitConfigKey(key string) string {
+	first := strings.Index(key, ".")
+	last := strings.LastIndex(key, ".")
+	if first == last {
+		return strings.ToLower(key)
+	}
+	return strings.ToLower(key[:first]) + key[first:last] + strings.ToLower(key[last:])
+}
+
+// gitConfigCache keeps parsed files for the lifetime of the process, so
+// scanning hundreds of repositories parses the global config only once
+var gitConfigCache = struct {
+	sync.Mutex
+	files map[string]cachedGitConfigFile
+}{files: map[string]cachedGitConfigFile{}}
+
+type cachedGitConfigFile struct {
+	modTime time.Time
+	size    int64
+	file    *gitConfigFile
+}
+
+// readGitConfigFileCached parses a config file, reusing the previous parse
+// while the file is unchanged. A missing file yields nil.
+func readGitConfigFileCached(path string) (*gitConfigFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil
+	}
+
+	gitConfigCache.Lock()
+	cached, ok := gitConfigCache.files[path]
+	gitConfigCache.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.file, nil
+	}
+
+	file, err := readGitConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	gitConfigCache.Lock()
+	gitConfigCache.files[path] = cachedGitConfigFile{modTime: info.ModTime(), size: info.Size(), file: file}
+	gitConfigCache.Unlock()
+	return file, nil
+}
+
+// findGitDir returns the git directory of a repository working tree and the
+// common directory holding its config (they differ for linked worktrees)
+func findGitDir(repoPath string) (gitDir, commonDir string, err error) {
+	dotGit := filepath.Join(repoPath, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", "", err
+	}
+
+	gitDir = dotGit
+	if !info.IsDir() {
+		// Worktrees and submodules use a "gitdir: <path>" file
+		data, err := os.ReadFile(dotGit)
+		if err != nil {
+			return "", "", err
+		}
+		line := strings.TrimSpace(string(data))
+		if !strings.HasPrefix(line, "gitdir:") {
+			return "", "", errUnsupportedGitConfig
+		}
+		gitDir = strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(repoPath, gitDir)
+		}
+	}
+
+	commonDir = gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+	return filepath.Clean(gitDir), filepath.Clean(commonDir), nil
+}
+
+// globalGitConfigFiles returns the global config files in the order git reads them
+func globalGitConfigFiles() []string {
+	if path := os.Getenv("GIT_CONFIG_GLOBAL"); path != "" {
+		return []string{expandUserPath(path)}
+	}
+	homeDir, _ := os.UserHomeDir()
+	xdg := os.Getenv("XDG_CONFIG_HOME")
+	if xdg == "" {
+		xdg = filepath.Join(homeDir, ".config")
+	}
+	return []string{filepath.Join(xdg, "git", "config"), filepath.Join(homeDir, ".gitconfig")}
+}
+
+// systemGitConfigFile returns the system-wide config, or "" when disabled
+func systemGitConfigFile() string {
+	if os.Getenv("GIT_CONFIG_NOSYSTEM") != "" {
+		return ""
+	}
+	if path := os.Getenv("GIT_CONFIG_SYSTEM"); path != "" {
+		return path
+	}
+	return "/etc/gitconfig"
+}
+
+// gitConfigResolver expands one repository's configuration
+type gitConfigResolver struct {
+	gitDir string // Empty outside a repository; gitdir: and onbranch: includes never match then
+	config *resolvedGitConfig
+	depth  int
+}
+
+// resolveRepoGitConfig reads the effective configuration of a repository
+// without running git. It returns errUnsupportedGitConfig when the result
+// might differ from git's, so callers can fall back to 'git config'.
+func resolveRepoGitConfig(repoPath string) (*resolvedGitConfig, error) {
+	// Configuration passed through the environment is only known to git
+	if os.Getenv("GIT_CONFIG_PARAMETERS") != "" || os.Getenv("GIT_CONFIG_COUNT") != "" || os.Getenv("GIT_DIR") != "" {
+		return nil, errUnsupportedGitConfig
+	}
+
+	gitDir, commonDir, err := findGitDir(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &gitConfigResolver{gitDir: gitDir, config: &resolvedGitConfig{}}
+	files := []string{}
+	if system := systemGitConfigFile(); system != "" {
+		files = append(files, system)
+	}
+	files = append(files, globalGitConfigFiles()...)
+	files = append(files, filepath.Join(commonDir, "config"))
+	if gitDir != commonDir {
+		files = append(files, filepath.Join(gitDir, "config.worktree"))
+	}
+
+	for _, path := range files {
+		if err := r.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return r.config, nil
+}
+
+// readFile appends a file's entries, expanding include and includeIf in place
+func (r *gitConfigResolver) readFile(path string) error {
+	// git gives up on include loops at a depth of 10 as well
+	if r.depth > 10 {
+		return errUnsupportedGitConfig
+	}
+	file, err := readGitConfigFileCached(path)
+	if err != nil || file == nil {
+		return err
+	}
+	tracef(traceRead, "git config %s", contractHomePath(path))
+
+	for _, section := range file.Sections {
+		if len(section.Lines) == 0 {
+			continue
+		}
+		lines := section.Lines
+		_, _, isHeader := parseGitConfigHeader(lines[0])
+		if isHeader {
+			// Entries on the header line itself ([user] name = x) are rare; let git handle them
+			header := strings.TrimSpace(lines[0])
+			rest := strings.TrimSpace(header[strings.Index(header, "]")+1:])
+			if rest != "" && !strings.HasPrefix(rest, "#") && !strings.HasPrefix(rest, ";") {
+				return errUnsupportedGitConfig
+			}
+			lines = lines[1:]
+		}
+
+		for _, line := range lines {
+			name, value, ok, err := parseGitConfigLine(line)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if !isHeader {
+				// git rejects entries before the first section
+				return errUnsupportedGitConfig
+			}
+
+			key := section.Name + "." + name
+			if section.Subsection != "" {
+				key = section.Name + "." + section.Subsection + "." + name
+			}
+
+			if name == "path" && (section.Name == "include" || section.Name == "includeif") {
+				if err := r.include(path, section, value); err != nil {
+					return err
+				}
+				continue
+			}
+			r.config.Entries = append(r.config.Entries, gitConfigEntry{Key: key, Value: value, Origin: path})
+		}
+	}
+	return nil
+}
+
+// include follows an include.path or includeIf.<condition>.path entry
+func (r *gitConfigResolver) include(from string, section *gitConfigSection, target string) error {
+	if section.Name == "includeif" {
+		matched, err := r.includeConditionMatches(from, section.Subsection)
+		if err != nil || !matched {
+			return err
+		}
+	}
+
+	target = expandUserPath(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(from), target)
+	}
+	r.depth++
+	defer func() { r.depth-- }()
+	return r.readFile(target)
+}
+
+// includeConditionMatches evaluates gitdir:, gitdir/i: and onbranch: conditions
+func (r *gitConfigResolver) includeConditionMatches(from, condition string) (bool, error) {
+	switch {
+	case strings.HasPrefix(condition, "gitdir:"):
+		return r.gitDirMatches(from, strings.TrimPrefix(condition, "gitdir:"), false), nil
+	case strings.HasPrefix(condition, "gitdir/i:"):
+		return r.gitDirMatches(from, strings.TrimPrefix(condition, "gitdir/i:"), true), nil
+	case strings.HasPrefix(condition, "onbranch:"):
+		if r.gitDir == "" {
+			return false, nil
+		}
+		head, err := os.ReadFile(filepath.Join(r.gitDir, "HEAD"))
+		if err != nil {
+			return false, nil
+		}
+		branch := strings.TrimPrefix(strings.TrimSpace(string(head)), "ref: refs/heads/")
+		pattern := strings.TrimPrefix(condition, "onbranch:")
+		if strings.HasSuffix(pattern, "/") {
+			pattern += "**"
+		}
+		return matchGlobPath(pattern, branch), nil
+	}
+	// hasconfig:remote.*.url: and future conditions need git itself
+	return false, errUnsupportedGitConfig
+}
+
+// gitDirMatches applies git's gitdir: pattern rules: ~/ and ./ expansion, an
+// implicit **/ prefix for relative patterns and ** after a trailing slash
+func (r *gitConfigResolver) gitDirMatches(from, pattern string, foldCase bool) bool {
+	if r.gitDir == "" {
+		return false
+	}
+
+	// Expanding ~/ and ./ cleans the path, which drops the trailing slash
+	dirPattern := strings.HasSuffix(pattern, "/")
+	switch {
+	case strings.HasPrefix(pattern, "~/"):
+		pattern = filepath.ToSlash(expandUserPath(pattern))
+	case strings.HasPrefix(pattern, "./"):
+		pattern = filepath.ToSlash(filepath.Join(filepath.Dir(from), pattern[2:]))
+	case !strings.HasPrefix(pattern, "/") && !filepath.IsAbs(pattern):
+		pattern = "**/" + pattern
+	}
+	if dirPattern {
+		pattern = strings.TrimSuffix(pattern, "/") + "/**"
+	}
+
+	candidates := []string{r.gitDir}
+	if real, err := filepath.EvalSymlinks(r.gitDir); err == nil && real != r.gitDir {
+		candidates = append(candidates, real)
+	}
+	for _, candidate := range candidates {
+		candidate = filepath.ToSlash(candidate)
+		if foldCase {
+			if matchGlobPath(strings.ToLower(pattern), strings.ToLower(candidate)) {
+				return true
+			}
+		} else if matchGlobPath(pattern, candidate) {
+			return true
+		}
+	}
+	return false
+}
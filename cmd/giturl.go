@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// remoteURL is a parsed git remote URL
+type remoteURL struct {
+	Scheme string // "scp", "ssh", "https", ...
+	User   string
+	Host   string
+	Port   string
+	Path   string // Repository path without leading slash, e.g. "org/repo.git"
+}
+
+// parseRemoteURL understands every remote form git accepts for a network
+// repository: scp-like ([user@]host:path, with [ ] around IPv6 addresses),
+// ssh://[user@]host[:port]/path (git+ssh:// and ssh+git:// too) and
+// http(s):// URLs. Local paths, including Windows drive letters, are rejected.
+func parseRemoteURL(raw string) (*remoteURL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("empty remote URL")
+	}
+
+	if scheme, _, ok := strings.Cut(raw, "://"); ok {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote URL %q: %w", raw, err)
+		}
+		remote := &remoteURL{
+			Scheme: strings.ToLower(scheme),
+			User:   u.User.Username(),
+			Host:   u.Hostname(),
+			Port:   u.Port(),
+			Path:   strings.Trim(u.Path, "/"),
+		}
+		if remote.Scheme == "git+ssh" || remote.Scheme == "ssh+git" {
+			remote.Scheme = "ssh"
+		}
+		if remote.Scheme == "file" {
+			return nil, fmt.Errorf("%q is a local repository, not a remote URL", raw)
+		}
+		if remote.Host == "" || remote.Path == "" {
+			return nil, fmt.Errorf("remote URL %q has no host or no repository path", raw)
+		}
+		return remote, nil
+	}
+
+	// scp-like syntax: [user@]host:path, where the host may be a bracketed IPv6
+	// address and a slash before the colon makes it a local path
+	remote := &remoteURL{Scheme: "scp"}
+	hostPart, path := "", ""
+	if strings.Contains(raw, "@[") || strings.HasPrefix(raw, "[") {
+		end := strings.Index(raw, "]:")
+		if end == -1 {
+			return nil, fmt.Errorf("unsupported remote URL %q", raw)
+		}
+		hostPart, path = raw[:end+1], raw[end+2:]
+	} else {
+		colon := strings.Index(raw, ":")
+		if colon == -1 || strings.Contains(raw[:colon], "/") {
+			return nil, fmt.Errorf("unsupported remote URL %q", raw)
+		}
+		hostPart, path = raw[:colon], raw[colon+1:]
+	}
+	if at := strings.LastIndex(hostPart, "@"); at != -1 {
+		remote.User, hostPart = hostPart[:at], hostPart[at+1:]
+	}
+	remote.Host = strings.TrimSuffix(strings.TrimPrefix(hostPart, "["), "]")
+	remote.Path = strings.Trim(path, "/")
+	if len(remote.Host) == 1 && remote.User == "" {
+		return nil, fmt.Errorf("%q is a local path, not a remote URL", raw)
+	}
+	if remote.Host == "" || remote.Path == "" {
+		return nil, fmt.Errorf("remote URL %q has no host or no repository path", raw)
+	}
+	return remote, nil
+}
+
+// isSSH reports whether the remote is reached over SSH
+func (r *remoteURL) isSSH() bool {
+	return r.Scheme == "scp" || r.Scheme == "ssh"
+}
+
+// repoPath returns the repository path without .git, e.g. "org/repo"
+func (r *remoteURL) repoPath() string {
+	return strings.TrimSuffix(r.Path, ".git")
+}
+
+// String renders the remote in its own form
+func (r *remoteURL) String() string {
+	user := ""
+	if r.User != "" {
+		user = r.User + "@"
+	}
+	if r.Scheme == "scp" {
+		host := r.Host
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		return user + host + ":" + r.Path
+	}
+	host := r.Host
+	if r.Port != "" {
+		host = net.JoinHostPort(r.Host, r.Port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return r.Scheme + "://" + user + host + "/" + r.Path
+}
+
+// completeRemoteURL completes a remote URL argument with the host aliases of
+// the accounts, followed by their username and organizations
+func completeRemoteURL(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var candidates []string
+	for i := range config.Accounts {
+		account := &config.Accounts[i]
+		if account.usesHTTPS() {
+			continue
+		}
+		prefix := fmt.Sprintf("%s@%s:", account.GetProvider().SSHUser, account.GetSSHHost())
+		for _, owner := range append([]string{account.Username}, account.Orgs...) {
+			if owner != "" {
+				candidates = append(candidates, prefix+owner+"/")
+			}
+		}
+	}
+	return candidates, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
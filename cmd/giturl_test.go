@@ -0,0 +1,77 @@
+package cmd
+
+import "testing"
+
+func TestParseRemoteURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want *remoteURL // nil when the URL must be rejected
+	}{
+		// scp-like
+		{"git@github.com:org/repo.git", &remoteURL{Scheme: "scp", User: "git", Host: "github.com", Path: "org/repo.git"}},
+		{"github.com-work:org/repo", &remoteURL{Scheme: "scp", Host: "github.com-work", Path: "org/repo"}},
+		{"git@gitlab.com:/group/sub/repo.git/", &remoteURL{Scheme: "scp", User: "git", Host: "gitlab.com", Path: "group/sub/repo.git"}},
+		{"git@[2001:db8::1]:org/repo.git", &remoteURL{Scheme: "scp", User: "git", Host: "2001:db8::1", Path: "org/repo.git"}},
+		{"[::1]:repo.git", &remoteURL{Scheme: "scp", Host: "::1", Path: "repo.git"}},
+		{"git@[2001:db8::1]org/repo.git", nil},
+
+		// ssh://
+		{"ssh://git@github.com/org/repo.git", &remoteURL{Scheme: "ssh", User: "git", Host: "github.com", Path: "org/repo.git"}},
+		{"ssh://git@git.example.com:2222/org/repo.git", &remoteURL{Scheme: "ssh", User: "git", Host: "git.example.com", Port: "2222", Path: "org/repo.git"}},
+		{"ssh://git@[2001:db8::1]:2222/org/repo", &remoteURL{Scheme: "ssh", User: "git", Host: "2001:db8::1", Port: "2222", Path: "org/repo"}},
+		{"SSH://git@github.com/org/repo", &remoteURL{Scheme: "ssh", User: "git", Host: "github.com", Path: "org/repo"}},
+		{"git+ssh://git@github.com/org/repo.git", &remoteURL{Scheme: "ssh", User: "git", Host: "github.com", Path: "org/repo.git"}},
+		{"ssh+git://git@github.com/org/repo.git", &remoteURL{Scheme: "ssh", User: "git", Host: "github.com", Path: "org/repo.git"}},
+		{"ssh://git@github.com/", nil},
+
+		// https
+		{"https://github.com/org/repo.git", &remoteURL{Scheme: "https", Host: "github.com", Path: "org/repo.git"}},
+		{"https://me@dev.azure.com/org/project/_git/repo", &remoteURL{Scheme: "https", User: "me", Host: "dev.azure.com", Path: "org/project/_git/repo"}},
+		{"http://git.example.com:8080/org/repo", &remoteURL{Scheme: "http", Host: "git.example.com", Port: "8080", Path: "org/repo"}},
+		{"https://github.com", nil},
+
+		// Local repositories
+		{"file:///srv/git/repo.git", nil},
+		{"/srv/git/repo.git", nil},
+		{"../repo", nil},
+		{"./dir:with/colon", nil},
+		{`C:\Users\me\repo`, nil},
+		{"C:/Users/me/repo", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got, err := parseRemoteURL(tt.raw)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("parseRemoteURL(%q) = %+v, want an error", tt.raw, *got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseRemoteURL(%q): %v", tt.raw, err)
+			continue
+		}
+		if *got != *tt.want {
+			t.Errorf("parseRemoteURL(%q) = %+v, want %+v", tt.raw, *got, *tt.want)
+		}
+	}
+}
+
+func TestRemoteURLString(t *testing.T) {
+	for _, raw := range []string{
+		"git@github.com:org/repo.git",
+		"git@[2001:db8::1]:org/repo.git",
+		"ssh://git@git.example.com:2222/org/repo.git",
+		"ssh://git@[2001:db8::1]:2222/org/repo",
+		"https://github.com/org/repo",
+	} {
+		remote, err := parseRemoteURL(raw)
+		if err != nil {
+			t.Errorf("parseRemoteURL(%q): %v", raw, err)
+			continue
+		}
+		if got := remote.String(); got != raw {
+			t.Errorf("parseRemoteURL(%q).String() = %q", raw, got)
+		}
+	}
+}
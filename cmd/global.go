@@ -3,7 +3,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -14,7 +13,12 @@ var globalCmd = &cobra.Command{
 	Use:   "global [account-name]",
 	Short: "Set global git configuration to use a specific account",
 	Long: `Set the global git configuration to use a specific account.
-This updates ~/.gitconfig with the default user.name and user.email.`,
+This updates ~/.gitconfig with the default user.name and user.email, and the
+account's commit template and signing key (removing those of the previous
+account).
+
+Examples:
+  krakn global personal     # Use 'personal' everywhere unless a directory overrides it`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		accountName := args[0]
@@ -22,41 +26,36 @@ This updates ~/.gitconfig with the default user.name and user.email.`,
 		// Load config to get account details
 		config, err := loadConfig()
 		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+			return errLoadConfig(err)
 		}
 
 		account := config.getAccount(accountName)
 		if account == nil {
-			if len(config.Accounts) == 0 {
-				return fmt.Errorf("❌ No accounts configured. Use 'krakn add' to add accounts first")
-			}
-			
-			var availableNames []string
-			for _, acc := range config.Accounts {
-				availableNames = append(availableNames, acc.Name)
-			}
-			return fmt.Errorf("❌ Account '%s' not found. Available accounts: %s", accountName, strings.Join(availableNames, ", "))
+			return config.accountNotFound(accountName)
 		}
 
-		// Set global git config
-		if err := setGlobalGitConfig("user.name", account.Username); err != nil {
-			return fmt.Errorf("failed to set global git user.name: %w", err)
+		if err := config.revealAccount(account); err != nil {
+			return err
 		}
-
-		if err := setGlobalGitConfig("user.email", account.Email); err != nil {
-			return fmt.Errorf("failed to set global git user.email: %w", err)
+		if err := account.checkKeyDir(); err != nil {
+			return err
 		}
 
-		// Update current account in config
-		config.CurrentAccount = accountName
-		if err := config.saveConfig(); err != nil {
-			return fmt.Errorf("failed to save config: %w", err)
+		changes, err := switchGlobalAccount(config, account, "use")
+		if err != nil {
+			return err
 		}
 
+		if config.LocalOnly {
+			fmt.Printf("✅ Current account set to '%s'; local-only mode leaves ~/.gitconfig alone\n", accountName)
+			fmt.Printf("💡 Give a repository this identity with 'krakn use %s <repo>'\n", accountName)
+			return nil
+		}
 		fmt.Printf("✅ Global git configuration set to account '%s'\n", accountName)
-		fmt.Printf("👤 Name: %s\n", account.Username)
+		fmt.Printf("👤 Name: %s\n", account.CommitName())
 		fmt.Printf("📧 Email: %s\n", account.Email)
-		fmt.Printf("🔗 SSH Host: github.com-%s\n", accountName)
+		fmt.Printf("🔗 SSH Host: %s\n", account.GetSSHHost())
+		printGitConfigChanges(globalGitConfigPath(), changes)
 		fmt.Println("\n💡 This will be used as the default for all repositories unless overridden by conditional includes!")
 
 		return nil
@@ -66,52 +65,64 @@ This updates ~/.gitconfig with the default user.name and user.email.`,
 var showIncludesCmd = &cobra.Command{
 	Use:   "show-includes",
 	Short: "Show current conditional includes in global git config",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		homeDir, _ := os.UserHomeDir()
-		globalConfigPath := filepath.Join(homeDir, ".gitconfig")
+	Long: `Show the includeIf entries of ~/.gitconfig and the include file each one loads,
+in the order git applies them. Directory (gitdir), branch (onbranch) and
+remote (hasconfig) conditions are listed apart; krakncat only writes gitdir
+and hasconfig entries and never moves or removes the others.
 
-		// Read global .gitconfig
-		content, err := os.ReadFile(globalConfigPath)
+Examples:
+  krakn show-includes
+  krakn dir includes
+  krakn show-includes --output json   # For scripts`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		globalConfigPath := globalGitConfigPath()
+		if !fileExists(globalConfigPath) {
+			return fmt.Errorf("failed to read global .gitconfig: %s does not exist", globalConfigPath)
+		}
+		gitConfig, err := readGitConfigFile(globalConfigPath)
 		if err != nil {
-			return fmt.Errorf("failed to read global .gitconfig: %w", err)
+			return err
 		}
 
-		configStr := string(content)
-		fmt.Println("🔧 Global Git Configuration:")
-		fmt.Printf("📁 File: %s\n\n", globalConfigPath)
+		includes := conditionalIncludes(gitConfig)
 
-		// Parse and display conditional includes
-		lines := strings.Split(configStr, "\n")
-		var inIncludeSection bool
-		hasIncludes := false
+		// Name the account of each include krakncat wrote
+		if config, err := loadConfig(); err == nil {
+			for i := range includes {
+				includes[i].Account = config.includeFileAccount(includes[i].Path)
+			}
+		}
+
+		if structuredOutput() {
+			return printStructured(includesOutput{SchemaVersion: schemaVersion("show-includes"), File: globalConfigPath, Includes: includes})
+		}
 
+		fmt.Println("🔧 Global Git Configuration:")
+		fmt.Printf("📁 File: %s\n\n", globalConfigPath)
 		fmt.Println("📋 Conditional Includes:")
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			
-			if strings.HasPrefix(line, "[includeIf") {
-				inIncludeSection = true
-				hasIncludes = true
-				// Extract the gitdir pattern
-				start := strings.Index(line, "\"gitdir:")
-				end := strings.Index(line[start+8:], "\"")
-				if start != -1 && end != -1 {
-					gitdir := line[start+8 : start+8+end]
-					fmt.Printf("  📁 %s\n", gitdir)
-				}
-			} else if inIncludeSection && strings.HasPrefix(line, "path") {
-				parts := strings.SplitN(line, "=", 2)
-				if len(parts) == 2 {
-					path := strings.TrimSpace(parts[1])
-					fmt.Printf("    🔗 → %s\n", path)
+		for _, include := range includes {
+			switch include.Kind {
+			case "gitdir":
+				fmt.Printf("  📁 %s\n", include.GitDir)
+			case "gitdir/i":
+				fmt.Printf("  📁 %s (case-insensitive)\n", include.GitDir)
+			case "onbranch":
+				fmt.Printf("  🌿 Branch %s (onbranch, not managed by krakncat)\n", strings.TrimPrefix(include.Condition, "onbranch:"))
+			case "hasconfig":
+				fmt.Printf("  🎯 %s\n", strings.TrimPrefix(include.Condition, "hasconfig:"))
+			default:
+				fmt.Printf("  ❔ %s\n", include.Condition)
+			}
+			if include.Path != "" {
+				if include.Account != "" {
+					fmt.Printf("    🔗 → %s (%s)\n", include.Path, include.Account)
+				} else {
+					fmt.Printf("    🔗 → %s\n", include.Path)
 				}
-				inIncludeSection = false
-			} else if strings.HasPrefix(line, "[") {
-				inIncludeSection = false
 			}
 		}
 
-		if !hasIncludes {
+		if len(includes) == 0 {
 			fmt.Println("  ℹ️  No conditional includes configured yet")
 			fmt.Println("  💡 Use 'krakn setup-dir' or 'krakn config-dir' to create them")
 		}
@@ -120,12 +131,148 @@ var showIncludesCmd = &cobra.Command{
 	},
 }
 
-func setGlobalGitConfig(key, value string) error {
-	cmd := exec.Command("git", "config", "--global", key, value)
-	return cmd.Run()
+// conditionalInclude is an includeIf entry of ~/.gitconfig. A section with
+// several path entries gives one per path.
+type conditionalInclude struct {
+	Condition string `json:"condition" desc:"The includeIf condition as written, e.g. gitdir:~/work/ or onbranch:main"`
+	Kind      string `json:"kind" desc:"Condition type: gitdir, gitdir/i, onbranch or hasconfig; empty when git does not know it"`
+	GitDir    string `json:"gitdir" desc:"Pattern of gitdir and gitdir/i conditions; empty for other kinds"`
+	Path      string `json:"path"`
+	Account   string `json:"account,omitempty" desc:"Account of the krakncat directory mapping or override that wrote the include"`
+}
+
+// includeIfKinds are the includeIf condition types git understands
+var includeIfKinds = []string{"gitdir", "gitdir/i", "onbranch", "hasconfig"}
+
+// includeIfKind returns the type of an includeIf condition, e.g. "onbranch"
+// for "onbranch:main", or "" when git does not know it
+func includeIfKind(condition string) string {
+	kind, _, ok := strings.Cut(condition, ":")
+	if !ok || !containsString(includeIfKinds, kind) {
+		return ""
+	}
+	return kind
+}
+
+// conditionalIncludes lists the includeIf entries of a config file in the
+// order git applies them, whatever their condition
+func conditionalIncludes(gitConfig *gitConfigFile) []conditionalInclude {
+	includes := []conditionalInclude{}
+	for _, section := range gitConfig.findSections("includeIf") {
+		include := conditionalInclude{Condition: section.Subsection, Kind: includeIfKind(section.Subsection)}
+		if include.Kind == "gitdir" || include.Kind == "gitdir/i" {
+			include.GitDir = strings.TrimPrefix(section.Subsection, include.Kind+":")
+		}
+		paths := section.getAll("path")
+		if len(paths) == 0 {
+			includes = append(includes, include)
+		}
+		for _, path := range paths {
+			include.Path = path
+			includes = append(includes, include)
+		}
+	}
+	return includes
+}
+
+// includeFileAccount returns the account of the directory mapping or override
+// whose include file is path, or "" when krakncat did not write it
+func (c *Config) includeFileAccount(path string) string {
+	if path == "" {
+		return ""
+	}
+	path = filepath.Clean(expandUserPath(path))
+	for _, mapping := range c.Directories {
+		if path == filepath.Clean(mapping.ConfigFile) {
+			return mapping.Account
+		}
+	}
+	for _, override := range c.Overrides {
+		if path == filepath.Clean(override.ConfigFile) {
+			return override.Account
+		}
+	}
+	return ""
+}
+
+// includesOutput is printed by 'krakn show-includes --output json|yaml'
+type includesOutput struct {
+	SchemaVersion int                  `json:"schema_version"`
+	File          string               `json:"file"`
+	Includes      []conditionalInclude `json:"includes"`
+}
+
+// globalGitConfigPath returns the path of the user's global git config file
+func globalGitConfigPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".gitconfig")
+}
+
+// setGlobalIdentity writes user.name and user.email to the global config in
+// one pass, e.g. to restore the identity krakn found before it was installed
+func setGlobalIdentity(name, email string) ([]gitConfigChange, error) {
+	return applyGitConfigValues(globalGitConfigPath(), []gitConfigValue{
+		{Key: "user.name", Value: name},
+		{Key: "user.email", Value: email},
+	})
+}
+
+// switchIdentityValues returns the values that give configPath an account's
+// identity: name, email, commit template and signing, plus core.sshCommand in
+// repositories of local-only mode. The signing key and generated template a
+// previous account left there are unset, so its trailers and GPG key do not
+// carry over.
+func (c *Config) switchIdentityValues(configPath string, account *Account, global bool) []gitConfigValue {
+	var values []gitConfigValue
+	for _, value := range c.localIdentityValues(account) {
+		if global && value.Key == "core.sshCommand" {
+			continue
+		}
+		values = append(values, value)
+	}
+	values = append(values, c.staleSigningValues(configPath, account)...)
+	if account.commitTemplatePath() == "" && isGeneratedCommitTemplate(readGitConfigValue(configPath, "commit", "template")) {
+		values = append(values, gitConfigValue{Key: "commit.template", Unset: true})
+	}
+	return values
+}
+
+// applyGlobalIdentity writes an account's identity, commit template and
+// signing to the global git config
+func (c *Config) applyGlobalIdentity(account *Account) ([]gitConfigChange, error) {
+	if err := writeCommitTemplate(account); err != nil {
+		return nil, err
+	}
+	path := globalGitConfigPath()
+	changes, err := applyGitConfigValues(path, c.switchIdentityValues(path, account, true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to set global identity: %w", err)
+	}
+	return changes, nil
+}
+
+// switchGlobalAccount makes account the current account: it writes the
+// account's identity to the global git config, saves the config and
+// announces the switch, naming source (use, schedule, network, socket) as
+// what triggered it. Every way of switching goes through here.
+func switchGlobalAccount(config *Config, account *Account, source string) ([]gitConfigChange, error) {
+	changes, err := config.applyGlobalIdentity(account)
+	if err != nil {
+		return nil, err
+	}
+	previous := config.CurrentAccount
+	if err := config.update(func(config *Config) error {
+		config.CurrentAccount = account.Name
+		return nil
+	}); err != nil {
+		return nil, errSaveConfig(err)
+	}
+	config.announceSwitch(previous, account, "", source)
+	return changes, nil
 }
 
 func init() {
 	RootCmd.AddCommand(globalCmd)
 	RootCmd.AddCommand(showIncludesCmd)
+	registerOutputSchema(outputSchema{Command: "show-includes", Version: 1, Description: "The includeIf entries of ~/.gitconfig", Type: includesOutput{}})
 }
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenDir is resolved before the tests change the working directory
+var goldenDir, _ = filepath.Abs("testdata")
+
+// testPublicKey is the key of the "work" account in the golden fixture, so
+// the fingerprint in the output never changes
+const testPublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIDGdWkp1F0TCecECZWiTpnhFsvcrHx8UMc5oNAWeK9vP work@example.com\n"
+
+const testConfig = `{
+  "accounts": [
+    {"name": "work", "email": "me@work.example", "ssh_key": "$HOME/.ssh/id_ed25519_work", "username": "me-work", "is_default": true},
+    {"name": "oss", "email": "me@oss.example", "ssh_key": "", "username": "me-oss", "auth_method": "https"}
+  ],
+  "current_account": "work",
+  "migration_done": true
+}
+`
+
+// setupGoldenHome builds a home directory with two accounts, a global
+// identity and a repository that commits as one account and pushes as the
+// other, and makes it the working directory. It returns the home directory.
+func setupGoldenHome(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	home, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(home, ".gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("KRAKN_ALLOW_ROOT", "1")
+
+	files := map[string]string{
+		".krakncat/config.json":    strings.ReplaceAll(testConfig, "$HOME", home),
+		".ssh/id_ed25519_work":     "not a real key\n",
+		".ssh/id_ed25519_work.pub": testPublicKey,
+		".gitconfig":               "[user]\n\tname = Me\n\temail = me@work.example\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(home, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo := filepath.Join(home, "src", "api")
+	for _, args := range [][]string{
+		{"init", "-q", repo},
+		{"-C", repo, "config", "user.email", "me@oss.example"},
+		{"-C", repo, "remote", "add", "origin", "git@github.com-work:acme/api.git"},
+	} {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(repo); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(dir) })
+	return home
+}
+
+// runCommand runs krakn with args and returns what it printed on stdout
+func runCommand(t *testing.T, args ...string) string {
+	t.Helper()
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = writer
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		done <- data
+	}()
+
+	RootCmd.SetArgs(args)
+	err = RootCmd.Execute()
+	writer.Close()
+	os.Stdout = stdout
+	output := <-done
+	RootCmd.SetArgs(nil)
+	outputFormat = ""
+	if err != nil {
+		t.Fatalf("krakn %s: %v", strings.Join(args, " "), err)
+	}
+	return string(output)
+}
+
+// checkGolden compares output with testdata/name; -update rewrites the file
+func checkGolden(t *testing.T, name, output string) {
+	t.Helper()
+	path := filepath.Join(goldenDir, name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(output), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run 'go test ./cmd -update' to create it)", err)
+	}
+	if string(want) != output {
+		t.Errorf("output differs from testdata/%s (run 'go test ./cmd -update' if the change is intended)\n--- want\n%s\n--- got\n%s", name, want, output)
+	}
+}
+
+// TestStructuredOutputGolden pins the JSON documents of the commands with a
+// schema, so a field that is renamed or dropped without bumping
+// schema_version fails here before it breaks someone's script
+func TestStructuredOutputGolden(t *testing.T) {
+	home := setupGoldenHome(t)
+
+	// Only the checks that look at the config and the repository; the others
+	// report on the machine the tests run on
+	checks := doctorChecks
+	doctorChecks = nil
+	for _, check := range checks {
+		if check.Name == "Accounts" || check.Name == "Repository identity" {
+			doctorChecks = append(doctorChecks, check)
+		}
+	}
+	defer func() { doctorChecks = checks }()
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"list", []string{"list", "--output", "json"}},
+		{"status", []string{"status", "--output", "json"}},
+		{"doctor", []string{"doctor", "--output", "json"}},
+		{"state", []string{"state", "--json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := runCommand(t, tt.args...)
+			checkGolden(t, filepath.Join("output", tt.name+".json"), strings.ReplaceAll(output, home, "/home/test"))
+		})
+	}
+
+	t.Run("resolve", func(t *testing.T) {
+		response := handleIPC(ipcRequest{Method: "resolve", Path: filepath.Join(home, "src", "api")}, nil)
+		data, err := json.MarshalIndent(response, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkGolden(t, filepath.Join("output", "resolve.json"), strings.ReplaceAll(string(data), home, "/home/test")+"\n")
+	})
+}
+
+// TestSchemaGolden pins what 'krakn schema <command>' prints for every
+// command with structured output
+func TestSchemaGolden(t *testing.T) {
+	t.Setenv("KRAKN_ALLOW_ROOT", "1")
+	for _, name := range schemaCommandNames() {
+		t.Run(name, func(t *testing.T) {
+			output := runCommand(t, "schema", name, "--output", "json")
+			checkGolden(t, filepath.Join("schema", strings.ReplaceAll(name, " ", "-")+".json"), output)
+		})
+	}
+}
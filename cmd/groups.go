@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// helpGroups are the sections of 'krakn --help'
+var helpGroups = []*cobra.Group{
+	{ID: "daily", Title: "Everyday commands:"},
+	{ID: "manage", Title: "Accounts, keys and directories:"},
+	{ID: "ssh", Title: "SSH and remotes:"},
+	{ID: "config", Title: "Configuration and history:"},
+}
+
+// commandGroups assigns top-level commands to a help group. Commands that
+// are not listed appear under "Additional Commands".
+var commandGroups = map[string]string{
+	"use":      "daily",
+	"global":   "daily",
+	"test":     "daily",
+	"doctor":   "daily",
+	"scan":     "daily",
+	"env":      "daily",
+	"clone":    "daily",
+	"current":  "daily",
+	"status":   "daily",
+	"state":    "daily",
+	"cli-auth": "daily",
+	"tutorial": "daily",
+	"demo":     "daily",
+
+	"account":   "manage",
+	"key":       "manage",
+	"dir":       "manage",
+	"workspace": "manage",
+	"repo":      "manage",
+	"override":  "manage",
+	"diff":      "manage",
+	"dedupe":    "manage",
+	"org":       "manage",
+	"timezone":  "manage",
+	"card":      "manage",
+	"edit":      "manage",
+
+	"commit-template": "manage",
+	"signing":         "manage",
+
+	"ssh-options":         "ssh",
+	"multiplex":           "ssh",
+	"agent":               "ssh",
+	"fix-remote":          "ssh",
+	"https-only":          "ssh",
+	"bench":               "ssh",
+	"probe-provider":      "ssh",
+	"remote-bootstrap":    "ssh",
+	"fix-perms":           "ssh",
+	"ssh-config":          "ssh",
+	"nuke-ssh-duplicates": "ssh",
+	"ci":                  "ssh",
+
+	"private":        "config",
+	"token":          "config",
+	"log":            "config",
+	"revert":         "config",
+	"repair-config":  "config",
+	"audit-log":      "config",
+	"explain":        "config",
+	"support-bundle": "config",
+	"migrate":        "config",
+	"import-config":  "config",
+	"uninstall":      "config",
+	"guard":          "config",
+	"schedule":       "config",
+	"context":        "config",
+	"notify":         "config",
+	"auth-refresh":   "config",
+	"watch":          "config",
+	"serve":          "config",
+	"service":        "config",
+	"metrics":        "config",
+	"integrations":   "config",
+	"local-only":     "config",
+	"ignore":         "config",
+	"schema":         "config",
+	"version":        "config",
+}
+
+// legacyVerbs are the original top-level commands that now live in a noun
+// group. They keep working but are hidden from help.
+var legacyVerbs = []string{"add", "list", "remove", "generate-key", "config", "show-includes", "import-ssh-hosts"}
+
+var accountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Add, list, remove and switch accounts",
+	Long: `Manage krakncat accounts.
+
+Examples:
+  krakn account add
+  krakn account list
+  krakn account use work ~/work/api`,
+}
+
+var keyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Generate and inspect SSH keys",
+	Long: `Manage the SSH keys used by krakncat accounts.
+
+Examples:
+  krakn key generate --name work --email me@company.com
+  krakn key list`,
+}
+
+var dirCmd = &cobra.Command{
+	Use:   "dir",
+	Short: "Map directories to accounts",
+	Long: `Map directories to accounts using git's conditional includes, so every
+repository below a directory uses that account automatically.
+
+Examples:
+  krakn dir map ~/work work
+  krakn dir list
+  krakn dir unmap ~/work`,
+}
+
+// nounSubcommands lists the top-level commands mirrored into each noun group,
+// as subcommand name → top-level command name
+var nounSubcommands = map[*cobra.Command][][2]string{
+	accountCmd: {{"add", "add"}, {"list", "list"}, {"remove", "remove"}, {"use", "use"}, {"global", "global"}, {"import", "import-ssh-hosts"}, {"diff", "diff"}, {"edit", "edit"}},
+	keyCmd:     {{"generate", "generate-key"}},
+	dirCmd:     {{"map", "config"}, {"includes", "show-includes"}},
+}
+
+// mirrorCommand creates a subcommand that behaves exactly like src under a new
+// name. Flags are shared with src, so they only need to be defined once.
+func mirrorCommand(src *cobra.Command, parent *cobra.Command, name string) *cobra.Command {
+	examplePattern := regexp.MustCompile(`krakn ` + regexp.QuoteMeta(src.Name()) + `\b`)
+	mirror := &cobra.Command{
+		Use:               name + strings.TrimPrefix(src.Use, src.Name()),
+		Short:             src.Short,
+		Long:              examplePattern.ReplaceAllString(src.Long, "krakn "+parent.Name()+" "+name),
+		Args:              src.Args,
+		Run:               src.Run,
+		RunE:              src.RunE,
+		ValidArgsFunction: src.ValidArgsFunction,
+	}
+	mirror.Flags().AddFlagSet(src.Flags())
+	return mirror
+}
+
+// findTopLevelCommand returns the root subcommand with the given name
+func findTopLevelCommand(name string) *cobra.Command {
+	for _, c := range RootCmd.Commands() {
+		if c.Name() == name {
+			return c
+		}
+	}
+	return nil
+}
+
+var commandGroupsReady bool
+
+// setupCommandGroups builds the noun groups and assigns help groups. It runs
+// before execution so every command's flags have been defined by then.
+func setupCommandGroups() {
+	if commandGroupsReady {
+		return
+	}
+	commandGroupsReady = true
+
+	for noun, subcommands := range nounSubcommands {
+		for _, pair := range subcommands {
+			if src := findTopLevelCommand(pair[1]); src != nil {
+				noun.AddCommand(mirrorCommand(src, noun, pair[0]))
+			}
+		}
+	}
+
+	for _, name := range legacyVerbs {
+		if c := findTopLevelCommand(name); c != nil {
+			c.Hidden = true
+		}
+	}
+
+	RootCmd.AddGroup(helpGroups...)
+	// cobra's own help and completion commands are added on execution
+	RootCmd.SetHelpCommandGroupID("config")
+	RootCmd.SetCompletionCommandGroupID("config")
+	for _, c := range RootCmd.Commands() {
+		if group, ok := commandGroups[c.Name()]; ok {
+			c.GroupID = group
+		}
+	}
+}
+
+func init() {
+	keyCmd.AddCommand(keyListCmd)
+	dirCmd.AddCommand(dirListCmd)
+	dirCmd.AddCommand(dirUnmapCmd)
+	RootCmd.AddCommand(accountCmd)
+	RootCmd.AddCommand(keyCmd)
+	RootCmd.AddCommand(dirCmd)
+}
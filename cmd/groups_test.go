@@ -0,0 +1,13 @@
+package cmd
+
+import "testing"
+
+// TestCommandGroups keeps new top-level commands out of "Additional Commands"
+func TestCommandGroups(t *testing.T) {
+	setupCommandGroups()
+	for _, c := range RootCmd.Commands() {
+		if !c.Hidden && c.GroupID == "" && c.Name() != "help" && c.Name() != "completion" {
+			t.Errorf("'krakn %s' has no help group; add it to commandGroups", c.Name())
+		}
+	}
+}
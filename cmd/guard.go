@@ -0,0 +1,558 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// guardHookMarker identifies pre-push hooks installed by krakn
+const guardHookMarker = "# Installed by krakn guard"
+
+// PushGuard is the policy for pushes from confidential accounts to public repositories
+type PushGuard struct {
+	Mode  string   `json:"mode,omitempty"`  // "warn" (default) or "block"
+	Allow []string `json:"allow,omitempty"` // Public repositories exempt from the policy, as owner/repo
+	Hooks []string `json:"hooks,omitempty"` // Repositories with the pre-push hook installed
+}
+
+// pushGuard returns the configured policy, creating an empty one when missing
+func (c *Config) pushGuard() *PushGuard {
+	if c.PushGuard == nil {
+		c.PushGuard = &PushGuard{}
+	}
+	return c.PushGuard
+}
+
+// guardHookScript runs 'krakn guard pre-push' and falls back to the binary
+// that installed it when krakn is not on PATH
+func guardHookScript() string {
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "krakn"
+	}
+	return fmt.Sprintf(`#!/bin/sh
+%s: flags pushes of confidential accounts to public repositories
+if command -v krakn >/dev/null 2>&1; then
+	exec krakn guard pre-push "$@"
+elif [ -x '%s' ]; then
+	exec '%s' guard pre-push "$@"
+fi
+echo "krakn guard: krakn not found, push not checked" >&2
+`, guardHookMarker, exe, exe)
+}
+
+// guardHookPath returns the pre-push hook of a repository
+func guardHookPath(repoPath string) (string, error) {
+	_, commonDir, err := findGitDir(repoPath)
+	if err != nil {
+		return "", errNotARepository(repoPath)
+	}
+	return filepath.Join(commonDir, "hooks", "pre-push"), nil
+}
+
+// isGuardHook reports whether a hook file was installed by krakn
+func isGuardHook(path string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(data), guardHookMarker)
+}
+
+// installGuardHook writes the pre-push hook; hooks of other tools are left alone
+func installGuardHook(repoPath string) error {
+	hookPath, err := guardHookPath(repoPath)
+	if err != nil {
+		return err
+	}
+	if fileExists(hookPath) && !isGuardHook(hookPath) {
+		return fmt.Errorf("❌ %s already has a pre-push hook; add 'krakn guard pre-push \"$@\"' to it yourself", repoPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	trackFile(hookPath)
+	if err := os.WriteFile(hookPath, []byte(guardHookScript()), 0755); err != nil {
+		return fmt.Errorf("failed to write %s: %w", hookPath, err)
+	}
+	return nil
+}
+
+// removeGuardHook deletes the hook if krakn installed it
+func removeGuardHook(repoPath string) error {
+	hookPath, err := guardHookPath(repoPath)
+	if err != nil || !isGuardHook(hookPath) {
+		return nil
+	}
+	trackFile(hookPath)
+	if err := os.Remove(hookPath); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", hookPath, err)
+	}
+	return nil
+}
+
+// repoVisibility reports whether a repository is public. The provider API is
+// asked with the account's token when it has one that needs no prompt, so
+// private repositories answer as well and the low rate limit of anonymous
+// calls does not apply. An error means the visibility is unknown: rate limits
+// and refusals are not taken for "private".
+func repoVisibility(account *Account, remote *remoteURL) (public bool, err error) {
+	if offlineMode {
+		return false, fmt.Errorf("the provider cannot be asked with --offline")
+	}
+	repoPath := remote.repoPath()
+	provider := account.GetProvider()
+	// A sealed token would need the passphrase, and git hooks have no terminal
+	if err := revealKeychainToken(account); err != nil {
+		return false, err
+	}
+	token := account.Token
+	if account.isSealed("token") {
+		token = ""
+	}
+
+	var endpoint, header, value string
+	switch provider.Name {
+	case "github":
+		endpoint, header, value = githubAPIBase(provider)+"/repos/"+repoPath, "Authorization", "Bearer "+token
+	case "gitlab":
+		endpoint, header, value = "https://"+provider.Hostname+"/api/v4/projects/"+url.PathEscape(repoPath), "PRIVATE-TOKEN", token
+	case "gitea", "forgejo":
+		endpoint, header, value = "https://"+provider.Hostname+"/api/v1/repos/"+repoPath, "Authorization", "token "+token
+	default:
+		return false, fmt.Errorf("visibility checks are not supported for %s", provider.DisplayName)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("User-Agent", "krakn/"+Version)
+	if token != "" {
+		req.Header.Set(header, value)
+	}
+	resp, err := (&http.Client{Timeout: 5 * time.Second}).Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		// Only public repositories answer anonymous calls; with a token, a
+		// repository it cannot see is not public either
+		return false, nil
+	case resp.StatusCode == http.StatusUnauthorized && token == "":
+		return false, nil
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		return false, fmt.Errorf("%s API refused the request (%d), e.g. because of its rate limit", provider.DisplayName, resp.StatusCode)
+	case resp.StatusCode != http.StatusOK:
+		return false, fmt.Errorf("%s API returned %d", provider.DisplayName, resp.StatusCode)
+	}
+
+	// GitHub and Gitea report private, GitHub and GitLab visibility;
+	// GitLab's "internal" is not public
+	var repo struct {
+		Private    bool   `json:"private"`
+		Visibility string `json:"visibility"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&repo); err != nil {
+		return false, fmt.Errorf("failed to parse %s API response: %w", provider.DisplayName, err)
+	}
+	if repo.Visibility != "" {
+		return repo.Visibility == "public", nil
+	}
+	return !repo.Private, nil
+}
+
+// pushAccount returns the account a push from the repository authenticates as
+func pushAccount(config *Config, identity *repoIdentity) *Account {
+	switch {
+	case identity.KeyAccount != nil:
+		return identity.KeyAccount
+	case identity.HostAccount != nil:
+		return identity.HostAccount
+	}
+	return identity.MappedAccount
+}
+
+var guardCmd = &cobra.Command{
+	Use:   "guard",
+	Short: "Flag or block pushes from confidential accounts to public repositories",
+	Long: `Mark accounts as confidential (e.g. work accounts) and install a pre-push hook
+that checks every push they make. Pushing to a public repository prints a
+warning, or fails in block mode. The provider API is asked with the account's
+token when it has one; when the visibility cannot be determined (rate limits,
+providers without a supported API, --offline), block mode fails the push too.
+Individual repositories can be allowed, and KRAKN_ALLOW_PUBLIC_PUSH=1 lets a
+single push through. The hook also warns when a push authenticates as a
+different account than the one commits are authored as.
+
+Examples:
+  krakn guard confidential work
+  krakn guard mode block
+  krakn guard install -r ~/work
+  krakn guard allow acme/public-docs
+  krakn guard status`,
+}
+
+var guardConfidentialCmd = &cobra.Command{
+	Use:   "confidential <account-name>",
+	Short: "Mark an account as confidential",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		off, _ := cmd.Flags().GetBool("off")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+
+		account.Confidential = !off
+		if err := config.addAccount(*account); err != nil {
+			return errSaveConfig(err)
+		}
+		if off {
+			fmt.Printf("✅ Account '%s' is no longer confidential\n", account.Name)
+			return nil
+		}
+		fmt.Printf("🔒 Account '%s' is confidential; pushes to public repositories are %s\n", account.Name, guardModeDescription(config.pushGuard().Mode))
+		if len(config.pushGuard().Hooks) == 0 {
+			fmt.Println("💡 Install the pre-push hook with 'krakn guard install -r <dir>'")
+		}
+		return nil
+	},
+}
+
+// guardModeDescription explains what a policy mode does to a push
+func guardModeDescription(mode string) string {
+	if mode == "block" {
+		return "blocked"
+	}
+	return "flagged with a warning"
+}
+
+var guardModeCmd = &cobra.Command{
+	Use:       "mode <warn|block>",
+	Short:     "Choose whether public pushes are flagged or blocked",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"warn", "block"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[0] != "warn" && args[0] != "block" {
+			return fmt.Errorf("❌ Unknown mode '%s'. Use warn or block", args[0])
+		}
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if err := config.update(func(config *Config) error {
+			config.pushGuard().Mode = args[0]
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("✅ Pushes from confidential accounts to public repositories are now %s\n", guardModeDescription(args[0]))
+		return nil
+	},
+}
+
+var guardAllowCmd = &cobra.Command{
+	Use:   "allow <owner/repo>",
+	Short: "Exempt a public repository from the policy",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		remove, _ := cmd.Flags().GetBool("remove")
+		repo := strings.TrimSuffix(args[0], ".git")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		guard := config.pushGuard()
+		if remove {
+			for _, allowed := range guard.Allow {
+				if strings.EqualFold(allowed, repo) {
+					if err := config.update(func(config *Config) error {
+						guard := config.pushGuard()
+						for i, allowed := range guard.Allow {
+							if strings.EqualFold(allowed, repo) {
+								guard.Allow = append(guard.Allow[:i], guard.Allow[i+1:]...)
+								break
+							}
+						}
+						return nil
+					}); err != nil {
+						return errSaveConfig(err)
+					}
+					fmt.Printf("✅ %s is no longer exempt\n", repo)
+					return nil
+				}
+			}
+			return fmt.Errorf("❌ %s is not on the allow list", repo)
+		}
+
+		if containsString(guard.Allow, repo) {
+			fmt.Printf("ℹ️  %s is already allowed\n", repo)
+			return nil
+		}
+		if err := config.update(func(config *Config) error {
+			if guard := config.pushGuard(); !containsString(guard.Allow, repo) {
+				guard.Allow = append(guard.Allow, repo)
+			}
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("✅ Pushes to %s are allowed from confidential accounts\n", repo)
+		return nil
+	},
+}
+
+var guardInstallCmd = &cobra.Command{
+	Use:   "install [path]",
+	Short: "Install the pre-push hook in a repository",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		recursive, _ := cmd.Flags().GetBool("recursive")
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		path, err := filepath.Abs(expandUserPath(path))
+		if err != nil {
+			return err
+		}
+
+		repos := []string{path}
+		if recursive {
+			repos = findGitRepos(path)
+			if len(repos) == 0 {
+				return fmt.Errorf("❌ No git repositories found below %s", path)
+			}
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		var hooks []string
+		for _, repo := range repos {
+			if err := installGuardHook(repo); err != nil {
+				fmt.Printf("⚠️  %s\n", strings.TrimPrefix(err.Error(), "❌ "))
+				continue
+			}
+			hooks = append(hooks, repo)
+			fmt.Printf("✅ Installed the pre-push hook in %s\n", contractHomePath(repo))
+		}
+		installed := len(hooks)
+		if err := config.update(func(config *Config) error {
+			guard := config.pushGuard()
+			for _, repo := range hooks {
+				if !containsString(guard.Hooks, repo) {
+					guard.Hooks = append(guard.Hooks, repo)
+				}
+			}
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		if installed < len(repos) {
+			return fmt.Errorf("❌ %d of %d repositories were not guarded", len(repos)-installed, len(repos))
+		}
+		return nil
+	},
+}
+
+var guardRemoveCmd = &cobra.Command{
+	Use:   "remove [path]",
+	Short: "Remove the pre-push hook from a repository",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		path, err := filepath.Abs(expandUserPath(path))
+		if err != nil {
+			return err
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if err := removeGuardHook(path); err != nil {
+			return err
+		}
+		if err := config.update(func(config *Config) error {
+			guard := config.pushGuard()
+			guard.Hooks = removeString(guard.Hooks, path)
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("✅ Removed the pre-push hook from %s\n", contractHomePath(path))
+		return nil
+	},
+}
+
+var guardStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show confidential accounts, the mode and guarded repositories",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		guard := config.pushGuard()
+
+		var confidential []string
+		for _, account := range config.Accounts {
+			if account.Confidential {
+				confidential = append(confidential, account.Name)
+			}
+		}
+		if len(confidential) == 0 {
+			fmt.Println("🔓 No confidential accounts")
+		} else {
+			fmt.Printf("🔒 Confidential accounts: %s\n", strings.Join(confidential, ", "))
+		}
+		fmt.Printf("🛡️  Public pushes are %s\n", guardModeDescription(guard.Mode))
+		if len(guard.Allow) > 0 {
+			fmt.Printf("✅ Allowed: %s\n", strings.Join(guard.Allow, ", "))
+		}
+		fmt.Printf("🪝 Hook installed in %d repositories\n", len(guard.Hooks))
+		for _, repo := range guard.Hooks {
+			path, _ := guardHookPath(repo)
+			if !isGuardHook(path) {
+				fmt.Printf("   ⚠️  %s (hook missing)\n", contractHomePath(repo))
+				continue
+			}
+			fmt.Printf("   📁 %s\n", contractHomePath(repo))
+		}
+		return nil
+	},
+}
+
+var guardPrePushCmd = &cobra.Command{
+	Use:    "pre-push <remote> <url>",
+	Short:  "Check a push (run by the pre-push hook)",
+	Hidden: true,
+	Args:   cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		root, err := findRepoRoot(".")
+		if err != nil {
+			return nil
+		}
+
+		identity := inspectRepoIdentity(config, root, args[0])
+		account := pushAccount(config, identity)
+		if account != nil && identity.EmailAccount != nil && account.Name != identity.EmailAccount.Name {
+			message := fmt.Sprintf("Commits are authored as '%s' but this push authenticates as '%s'", identity.EmailAccount.Name, account.Name)
+			fmt.Fprintf(os.Stderr, "⚠️  krakn guard: %s\n", message)
+			if err := config.notify(doctorWarn, "krakn: push with the wrong key", message); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  Could not show notification: %v\n", err)
+			}
+		}
+
+		if os.Getenv("KRAKN_ALLOW_PUBLIC_PUSH") == "1" {
+			return nil
+		}
+		if account == nil || !account.Confidential {
+			return nil
+		}
+		remote, err := parseRemoteURL(args[1])
+		if err != nil {
+			return nil
+		}
+		repo := remote.repoPath()
+		guard := config.pushGuard()
+		for _, allowed := range guard.Allow {
+			if strings.EqualFold(allowed, repo) {
+				return nil
+			}
+		}
+
+		public, err := repoVisibility(account, remote)
+		if err != nil && guard.Mode == "block" {
+			// Block mode only lets through what is known not to be public
+			fmt.Fprintf(os.Stderr, "💡 Allow it with 'krakn guard allow %s', or push once with KRAKN_ALLOW_PUBLIC_PUSH=1\n", repo)
+			cmd.SilenceErrors = true
+			return fmt.Errorf("❌ krakn guard: could not check whether %s is public (%v); '%s' is a confidential account. Push blocked", repo, err, account.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  krakn guard: could not check whether %s is public: %v\n", repo, err)
+			return nil
+		}
+		if !public {
+			return nil
+		}
+
+		if guard.Mode == "block" {
+			config.notify(doctorError, "krakn: push blocked", fmt.Sprintf("'%s' is confidential and %s is public", account.Name, repo))
+			fmt.Fprintf(os.Stderr, "💡 Allow it with 'krakn guard allow %s', or push once with KRAKN_ALLOW_PUBLIC_PUSH=1\n", repo)
+			cmd.SilenceErrors = true
+			return fmt.Errorf("❌ krakn guard: '%s' is a confidential account and %s is public. Push blocked", account.Name, repo)
+		}
+		fmt.Fprintf(os.Stderr, "⚠️  krakn guard: pushing to public repository %s from confidential account '%s'\n", repo, account.Name)
+		config.notify(doctorWarn, "krakn: public push", fmt.Sprintf("Pushing to public %s from confidential '%s'", repo, account.Name))
+		return nil
+	},
+}
+
+// guardHookFindings lists the installed hooks for 'krakn uninstall'
+func guardHookFindings(config *Config) []string {
+	if config.PushGuard == nil {
+		return nil
+	}
+	var hooks []string
+	for _, repo := range config.PushGuard.Hooks {
+		if path, err := guardHookPath(repo); err == nil && isGuardHook(path) {
+			hooks = append(hooks, path)
+		}
+	}
+	return hooks
+}
+
+func init() {
+	guardConfidentialCmd.Flags().Bool("off", false, "Remove the confidential mark")
+	guardAllowCmd.Flags().Bool("remove", false, "Remove the repository from the allow list")
+	guardInstallCmd.Flags().BoolP("recursive", "r", false, "Install in every repository below the path")
+	guardCmd.AddCommand(guardConfidentialCmd)
+	guardCmd.AddCommand(guardModeCmd)
+	guardCmd.AddCommand(guardAllowCmd)
+	guardCmd.AddCommand(guardInstallCmd)
+	guardCmd.AddCommand(guardRemoveCmd)
+	guardCmd.AddCommand(guardStatusCmd)
+	guardCmd.AddCommand(guardPrePushCmd)
+	RootCmd.AddCommand(guardCmd)
+
+	registerUninstallStep(uninstallStep{
+		Name:     "pre-push guard hooks",
+		Describe: guardHookFindings,
+		Run: func(config *Config) error {
+			for _, repo := range config.pushGuard().Hooks {
+				if err := removeGuardHook(repo); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,387 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Authentication methods of an account
+const (
+	authSSH   = "ssh"
+	authHTTPS = "https"
+)
+
+// usesHTTPS reports whether git authenticates the account over HTTPS with its
+// token instead of SSH
+func (a *Account) usesHTTPS() bool {
+	return a.AuthMethod == authHTTPS
+}
+
+// hasToken reports whether the account has an API token, possibly sealed or
+// in the keychain, without revealing it
+func (a *Account) hasToken() bool {
+	return a.Token != "" || a.isSealed("token") || a.TokenStore == tokenStoreKeychain
+}
+
+// upgradeAuthMethods converts the https_only flag of older configs
+func (c *Config) upgradeAuthMethods() {
+	for i := range c.Accounts {
+		if c.Accounts[i].HTTPSOnly {
+			c.Accounts[i].AuthMethod = authHTTPS
+			c.Accounts[i].HTTPSOnly = false
+		}
+	}
+}
+
+// credentialOwners are the path prefixes an account serves credentials for:
+// its username and organizations (GitLab subgroups may contain slashes)
+func (a *Account) credentialOwners() []string {
+	var owners []string
+	for _, owner := range append([]string{a.Username}, a.Orgs...) {
+		if owner = strings.Trim(owner, "/"); owner != "" && !containsString(owners, owner) {
+			owners = append(owners, owner)
+		}
+	}
+	return owners
+}
+
+// httpsRemoteURL points a remote at the provider over HTTPS
+func httpsRemoteURL(remote *remoteURL, account *Account) string {
+	return fmt.Sprintf("https://%s/%s", account.GetProvider().Hostname, remote.Path)
+}
+
+// accountRemoteURL returns the remote an account should use: its SSH host
+// alias, or the HTTPS URL for https-only accounts
+func accountRemoteURL(remote *remoteURL, account *Account) string {
+	if account.usesHTTPS() {
+		return httpsRemoteURL(remote, account)
+	}
+	return aliasRemoteURL(remote, account)
+}
+
+// findCredentialAccount returns the https-only account whose username or
+// organization is the longest prefix of the repository path on host. LFS
+// requests (<owner>/<repo>.git/info/lfs) match the same way.
+func (c *Config) findCredentialAccount(host, path string) *Account {
+	var best *Account
+	bestLength := 0
+	for i := range c.Accounts {
+		account := &c.Accounts[i]
+		if !account.usesHTTPS() || !strings.EqualFold(account.GetProvider().Hostname, host) {
+			continue
+		}
+		for _, owner := range account.credentialOwners() {
+			if strings.HasPrefix(strings.ToLower(path), strings.ToLower(owner)+"/") && len(owner) > bestLength {
+				best, bestLength = account, len(owner)
+			}
+		}
+	}
+	return best
+}
+
+// hostUsesHTTPS reports whether an account other than except authenticates
+// over HTTPS on host
+func (c *Config) hostUsesHTTPS(host, except string) bool {
+	for i := range c.Accounts {
+		if c.Accounts[i].Name != except && c.Accounts[i].usesHTTPS() && strings.EqualFold(c.Accounts[i].GetProvider().Hostname, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostCredentialAccount returns the account whose token answers for a
+// repository path no account owns: the account of the repository git runs
+// in, or else the current account, when it authenticates over HTTPS on host.
+// 'krakn use' therefore switches the token git uses for the host.
+func (c *Config) hostCredentialAccount(host string) *Account {
+	var names []string
+	if dir, err := os.Getwd(); err == nil {
+		state := currentState(c, dir)
+		names = append(names, state.Mapped, state.Account)
+	}
+	names = append(names, c.CurrentAccount)
+	for _, name := range names {
+		for i := range c.Accounts {
+			account := &c.Accounts[i]
+			if name != "" && account.Name == name && account.usesHTTPS() && strings.EqualFold(account.GetProvider().Hostname, host) {
+				return account
+			}
+		}
+	}
+	return nil
+}
+
+// credentialSection is the ~/.gitconfig section the helper is configured in
+func credentialSection(account *Account, owner string) string {
+	return fmt.Sprintf("https://%s/%s", account.GetProvider().Hostname, owner)
+}
+
+// installCredentialHelper makes git ask krakn for credentials of the
+// account's owners and, for other paths, of the account's host. The empty
+// helper entry first clears helpers configured earlier (e.g. a keychain),
+// which would otherwise answer with another account's password.
+func installCredentialHelper(account *Account) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the krakn binary: %w", err)
+	}
+	helper := formatGitConfigValue("!" + shellQuote(executable) + " credential")
+
+	path := globalGitConfigPath()
+	return withFileLock(path, func() error {
+		gitConfig, err := readGitConfigFile(path)
+		if err != nil {
+			return err
+		}
+		hostSection := gitConfig.findSection("credential", "https://"+account.GetProvider().Hostname)
+		if hostSection == nil {
+			hostSection = gitConfig.addSection("credential", "https://"+account.GetProvider().Hostname)
+		}
+		hostSection.set("useHttpPath", "true")
+		hostSection.unset("helper")
+		hostSection.Lines = append(hostSection.Lines, "\thelper =", "\thelper = "+helper)
+
+		for _, owner := range account.credentialOwners() {
+			section := gitConfig.findSection("credential", credentialSection(account, owner))
+			if section == nil {
+				section = gitConfig.addSection("credential", credentialSection(account, owner))
+			}
+			section.Lines = append(section.Lines[:1], "\thelper =", "\thelper = "+helper)
+		}
+		return gitConfig.save()
+	})
+}
+
+// removeCredentialHelper drops the helper sections of an account's owners
+// and, with hostToo, krakn's helper and useHttpPath for the account's host.
+// The host section goes too when nothing of the user's is left in it.
+func removeCredentialHelper(account *Account, hostToo bool) error {
+	path := globalGitConfigPath()
+	return withFileLock(path, func() error {
+		gitConfig, err := readGitConfigFile(path)
+		if err != nil {
+			return err
+		}
+		changed := false
+		for _, owner := range account.credentialOwners() {
+			if section := gitConfig.findSection("credential", credentialSection(account, owner)); section != nil {
+				gitConfig.removeSection(section)
+				changed = true
+			}
+		}
+		if hostSection := gitConfig.findSection("credential", "https://"+account.GetProvider().Hostname); hostToo && hostSection != nil && (hostSection.has("helper") || hostSection.has("useHttpPath")) {
+			hostSection.unset("helper")
+			hostSection.unset("useHttpPath")
+			if !hostSection.hasEntries() {
+				gitConfig.removeSection(hostSection)
+			}
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		return gitConfig.save()
+	})
+}
+
+var httpsOnlyCmd = &cobra.Command{
+	Use:   "https-only <account-name>",
+	Short: "Use HTTPS with a token instead of SSH for an account",
+	Long: `Mark an account as HTTPS-only, for organizations that disable SSH access.
+Remotes rewritten by 'krakn fix-remote' and repositories cloned by 'krakn
+workspace apply' then use https:// URLs, 'krakn doctor' and 'krakn test' skip
+the SSH checks, and git gets the account's token from krakn's credential
+helper.
+
+The helper is configured in ~/.gitconfig for every owner of the account (its
+username and the organizations added with 'krakn org'), so two accounts on the
+same provider each get their own token. Repositories of other owners on the
+host get the token of the repository's account, or else of the account
+selected with 'krakn use', so switching accounts switches the token too:
+
+  [credential "https://github.com"]
+      useHttpPath = true
+      helper =
+      helper = !'/usr/local/bin/krakn' credential
+  [credential "https://github.com/acme"]
+      helper =
+      helper = !'/usr/local/bin/krakn' credential
+
+You are asked for the token, which is kept in the system keychain when there
+is one; 'krakn token set' replaces it later (a fine-grained token with
+repository contents access is enough). Run the command again after adding
+organizations.
+
+Examples:
+  krakn https-only work
+  krakn https-only work --off`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		off, _ := cmd.Flags().GetBool("off")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+		if len(account.credentialOwners()) == 0 {
+			return fmt.Errorf("❌ Account '%s' has no username or organizations to serve credentials for", account.Name)
+		}
+
+		if off {
+			account.AuthMethod = authSSH
+			hostToo := !config.hostUsesHTTPS(account.GetProvider().Hostname, account.Name)
+			if err := removeCredentialHelper(account, hostToo); err != nil {
+				return fmt.Errorf("failed to update ~/.gitconfig: %w", err)
+			}
+			if err := config.addAccount(*account); err != nil {
+				return errSaveConfig(err)
+			}
+			fmt.Printf("✅ Account '%s' uses SSH again\n", account.Name)
+			fmt.Println("💡 Point existing repositories back at the SSH alias with: krakn fix-remote --account " + account.Name)
+			return nil
+		}
+
+		account.AuthMethod = authHTTPS
+		if err := installCredentialHelper(account); err != nil {
+			return fmt.Errorf("failed to update ~/.gitconfig: %w", err)
+		}
+
+		// Ask for the token right away, kept in the keychain when there is one
+		if !account.hasToken() && term.IsTerminal(int(os.Stdin.Fd())) {
+			token, err := readSecret(fmt.Sprintf("🔑 API token for '%s' (empty to add it later): ", account.Name))
+			if err != nil {
+				return fmt.Errorf("failed to read token: %w", err)
+			}
+			if token != "" {
+				if err := config.storeToken(account, token, systemSecretStore() != nil); err != nil {
+					return err
+				}
+			}
+		}
+		if err := config.addAccount(*account); err != nil {
+			return errSaveConfig(err)
+		}
+
+		fmt.Printf("✅ Account '%s' is HTTPS-only\n", account.Name)
+		for _, owner := range account.credentialOwners() {
+			fmt.Printf("   🔐 Credentials for https://%s/%s/\n", account.GetProvider().Hostname, owner)
+		}
+		fmt.Printf("   🔐 Credentials for other https://%s/ repositories while '%s' is in use\n", account.GetProvider().Hostname, account.Name)
+		if account.TokenStore == tokenStoreKeychain {
+			fmt.Printf("   🗝️  Token kept in %s\n", systemSecretStore().Name())
+		}
+		if !account.hasToken() {
+			fmt.Printf("💡 Store the token git should use: krakn token set %s --keychain\n", account.Name)
+		}
+		fmt.Println("💡 Switch existing repositories with: krakn fix-remote --account " + account.Name)
+		return nil
+	},
+}
+
+var credentialCmd = &cobra.Command{
+	Use:    "credential <get|store|erase>",
+	Short:  "Git credential helper for HTTPS-only accounts",
+	Hidden: true,
+	Long: `Answer git's and git-lfs's credential requests with the token of the
+HTTPS-only account that owns the repository path. A separate LFS server gets
+the token of the repository whose lfs.url it is. Other paths on the host get
+the token of the repository's account or of the current account. Configured by 'krakn
+https-only'; store and erase are ignored because tokens are managed with
+'krakn token'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[0] != "get" {
+			return nil
+		}
+
+		request := map[string]string{}
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			key, value, ok := strings.Cut(scanner.Text(), "=")
+			if !ok {
+				break
+			}
+			request[key] = value
+		}
+		if request["protocol"] != "https" {
+			return nil
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.findCredentialAccount(request["host"], request["path"])
+		if account == nil {
+			account = config.repoCredentialAccount(request["host"], request["path"])
+		}
+		if account == nil {
+			account = config.hostCredentialAccount(request["host"])
+		}
+		if account == nil {
+			tracef(traceMatch, "No HTTPS-only account owns %s/%s", request["host"], request["path"])
+			return nil
+		}
+		// Stdin and stdout belong to git, so there is no passphrase prompt
+		if account.isSealed("token") && (config.Sealing == nil || config.Sealing.Method != "identity") {
+			fmt.Fprintf(os.Stderr, "krakn: the token of '%s' is sealed with a passphrase; git cannot use it\n", account.Name)
+			return nil
+		}
+		if err := config.revealAccount(account); err != nil {
+			fmt.Fprintf(os.Stderr, "krakn: %v\n", err)
+			return nil
+		}
+		if account.Token == "" {
+			fmt.Fprintf(os.Stderr, "krakn: account '%s' has no token; run 'krakn token set %s'\n", account.Name, account.Name)
+			return nil
+		}
+
+		tracef(traceMatch, "%s/%s belongs to HTTPS-only account '%s'", request["host"], request["path"], account.Name)
+		fmt.Printf("username=%s\npassword=%s\n", account.Username, account.Token)
+		return nil
+	},
+}
+
+func init() {
+	httpsOnlyCmd.Flags().Bool("off", false, "Use SSH again and remove the credential helper")
+	RootCmd.AddCommand(httpsOnlyCmd)
+	RootCmd.AddCommand(credentialCmd)
+
+	registerUninstallStep(uninstallStep{
+		Name: "credential helper entries in ~/.gitconfig",
+		Describe: func(config *Config) []string {
+			var sections []string
+			for _, account := range config.Accounts {
+				if account.usesHTTPS() {
+					if host := "credential.https://" + account.GetProvider().Hostname; !containsString(sections, host) {
+						sections = append(sections, host)
+					}
+					for _, owner := range account.credentialOwners() {
+						sections = append(sections, "credential."+credentialSection(&account, owner))
+					}
+				}
+			}
+			return sections
+		},
+		Run: func(config *Config) error {
+			for i := range config.Accounts {
+				if config.Accounts[i].usesHTTPS() {
+					if err := removeCredentialHelper(&config.Accounts[i], true); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// kraknIgnoreFile lists directories that repository scans skip, using
+// .gitignore syntax relative to the directory containing the file
+const kraknIgnoreFile = ".kraknignore"
+
+// defaultIgnorePatterns are never worth descending into when looking for repositories
+var defaultIgnorePatterns = []string{"node_modules", ".cache", ".Trash"}
+
+// ignoreRule is a single pattern from a .kraknignore file or the config
+type ignoreRule struct {
+	Base    string // Directory the pattern is relative to; empty for absolute or name patterns
+	Pattern string
+	Negate  bool
+}
+
+// ignoreMatcher decides which directories a repository scan skips
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// newIgnoreMatcher starts with the default patterns and the config's ignore list
+func newIgnoreMatcher(config *Config) *ignoreMatcher {
+	m := &ignoreMatcher{}
+	for _, pattern := range defaultIgnorePatterns {
+		m.add("", pattern)
+	}
+	if config != nil {
+		for _, pattern := range config.Ignore {
+			m.add("", expandUserPath(pattern))
+		}
+	}
+	return m
+}
+
+// add parses one pattern line. Blank lines and # comments are ignored.
+func (m *ignoreMatcher) add(base, line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+	rule := ignoreRule{Base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.Negate = true
+		line = line[1:]
+	}
+	rule.Pattern = strings.TrimSuffix(filepath.ToSlash(line), "/")
+	if rule.Pattern != "" {
+		m.rules = append(m.rules, rule)
+	}
+}
+
+// loadDir reads the .kraknignore file of dir, if there is one
+func (m *ignoreMatcher) loadDir(dir string) {
+	f, err := os.Open(filepath.Join(dir, kraknIgnoreFile))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		m.add(dir, scanner.Text())
+	}
+}
+
+// ignored reports whether a directory should be skipped. As in .gitignore,
+// the last matching pattern wins, so "!" can re-include a directory.
+func (m *ignoreMatcher) ignored(dir string) bool {
+	result := false
+	for _, rule := range m.rules {
+		if rule.matches(dir) {
+			result = !rule.Negate
+		}
+	}
+	return result
+}
+
+func (r ignoreRule) matches(dir string) bool {
+	// Patterns without a slash match a directory name at any depth
+	if !strings.Contains(r.Pattern, "/") {
+		if r.Base != "" && !isWithinDir(r.Base, dir) {
+			return false
+		}
+		ok, _ := path.Match(r.Pattern, filepath.Base(dir))
+		return ok
+	}
+
+	if r.Base == "" {
+		return matchGlobPath(r.Pattern, filepath.ToSlash(dir))
+	}
+	if !isWithinDir(r.Base, dir) {
+		return false
+	}
+	rel, _ := filepath.Rel(r.Base, dir)
+	return matchGlobPath(strings.TrimPrefix(r.Pattern, "/"), filepath.ToSlash(rel))
+}
+
+// isWithinDir reports whether path is dir or below it
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+// matchGlobPath matches a slash-separated path against a glob where "**"
+// matches any number of directories
+func matchGlobPath(pattern, name string) bool {
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchGlobSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// A trailing "**" matches everything inside, but not the directory itself
+			if len(pattern) == 1 {
+				return len(name) > 0
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchGlobSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+var ignoreCmd = &cobra.Command{
+	Use:   "ignore",
+	Short: "Manage directories skipped by repository scans",
+	Long: `Manage the ignore patterns used when krakn looks for repositories (scan,
+fix-remote --recursive, directory moves).
+
+Patterns without a slash match a directory name anywhere, e.g. "vendor".
+Patterns with a slash match a path, e.g. "~/archive/**". A .kraknignore file
+in any directory works like .gitignore, relative to that directory.
+node_modules, .cache and .Trash are always skipped.
+
+Examples:
+  krakn ignore add vendor
+  krakn ignore add "~/archive"
+  krakn ignore list
+  krakn ignore remove vendor`,
+}
+
+var ignoreAddCmd = &cobra.Command{
+	Use:   "add <pattern>",
+	Short: "Skip directories matching a pattern",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		pattern := contractHomePath(expandUserPath(args[0]))
+		if containsString(config.Ignore, pattern) {
+			fmt.Printf("ℹ️  '%s' is already ignored\n", pattern)
+			return nil
+		}
+		if err := config.update(func(config *Config) error {
+			if !containsString(config.Ignore, pattern) {
+				config.Ignore = append(config.Ignore, pattern)
+			}
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("✅ Scans will skip '%s'\n", pattern)
+		return nil
+	},
+}
+
+var ignoreRemoveCmd = &cobra.Command{
+	Use:   "remove <pattern>",
+	Short: "Stop skipping directories matching a pattern",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		pattern := contractHomePath(expandUserPath(args[0]))
+		if !containsString(config.Ignore, pattern) {
+			return fmt.Errorf("❌ '%s' is not in the ignore list", pattern)
+		}
+		if err := config.update(func(config *Config) error {
+			config.Ignore = removeString(config.Ignore, pattern)
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("✅ Removed '%s'\n", pattern)
+		return nil
+	},
+}
+
+var ignoreListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show the ignore patterns",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		fmt.Println("🙈 Always skipped:")
+		for _, pattern := range defaultIgnorePatterns {
+			fmt.Printf("   %s\n", pattern)
+		}
+		if len(config.Ignore) > 0 {
+			fmt.Println("🙈 From your configuration:")
+			for _, pattern := range config.Ignore {
+				fmt.Printf("   %s\n", pattern)
+			}
+		}
+		fmt.Printf("💡 Add a %s file to a directory to skip paths below it\n", kraknIgnoreFile)
+		return nil
+	},
+}
+
+func init() {
+	ignoreCmd.AddCommand(ignoreAddCmd)
+	ignoreCmd.AddCommand(ignoreRemoveCmd)
+	ignoreCmd.AddCommand(ignoreListCmd)
+	RootCmd.AddCommand(ignoreCmd)
+}
@@ -0,0 +1,450 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Resolutions for an incoming account whose name exists locally
+const (
+	preferLocal  = "local"  // Keep the local account unchanged
+	preferRemote = "remote" // Replace it with the incoming account
+	preferRename = "rename" // Add the incoming account under a new name
+	preferMerge  = "merge"  // Combine field by field
+)
+
+// accountFields is the stored form of an account's mergeable fields, as JSON
+// values keyed by field name. Sealed fields hold their sealed value so they
+// travel together with the encryption.
+type accountFields map[string]string
+
+// unmergedFields are left out of comparisons: the name is the merge key, the
+// default flag is per machine and the profile is refetched from the provider
+var unmergedFields = []string{"name", "is_default", "profile", "sealed"}
+
+func fieldsOf(account Account) accountFields {
+	data, _ := json.Marshal(account.stripSealed())
+	var raw map[string]json.RawMessage
+	json.Unmarshal(data, &raw)
+
+	fields := accountFields{}
+	for key, value := range raw {
+		if !containsString(unmergedFields, key) {
+			fields[key] = string(value)
+		}
+	}
+	for field, sealed := range account.Sealed {
+		fields[field] = "sealed:" + sealed
+	}
+	return fields
+}
+
+// display renders a field value for the conflict table
+func (f accountFields) display(key string) string {
+	value := f[key]
+	switch {
+	case value == "" || value == `""` || value == "null":
+		return "-"
+	case strings.HasPrefix(value, "sealed:"):
+		return "(sealed)"
+	}
+	var text string
+	if json.Unmarshal([]byte(value), &text) == nil {
+		return contractHomePath(text)
+	}
+	return value
+}
+
+// differingFields returns the fields whose values differ, sorted
+func differingFields(local, remote accountFields) []string {
+	var keys []string
+	for key := range local {
+		if local[key] != remote[key] {
+			keys = append(keys, key)
+		}
+	}
+	for key := range remote {
+		if _, ok := local[key]; !ok && remote[key] != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fieldChange tells which side changed a field since the last import:
+// "local", "remote", "both", or "" when there is no earlier import to compare
+func fieldChange(base accountFields, local, remote accountFields, key string) string {
+	if base == nil {
+		return ""
+	}
+	switch {
+	case local[key] == base[key]:
+		return "remote"
+	case remote[key] == base[key]:
+		return "local"
+	}
+	return "both"
+}
+
+// mergeAccount applies the incoming value of the given fields to the local account
+func mergeAccount(local, remote Account, takeRemote []string) Account {
+	data, _ := json.Marshal(local)
+	var merged map[string]json.RawMessage
+	json.Unmarshal(data, &merged)
+
+	remoteData, _ := json.Marshal(remote.stripSealed())
+	var incoming map[string]json.RawMessage
+	json.Unmarshal(remoteData, &incoming)
+
+	sealed := map[string]string{}
+	for field, value := range local.Sealed {
+		sealed[field] = value
+	}
+	for _, key := range takeRemote {
+		if value, ok := incoming[key]; ok {
+			merged[key] = value
+		} else {
+			delete(merged, key)
+		}
+		delete(sealed, key)
+		if value, ok := remote.Sealed[key]; ok {
+			sealed[key] = value
+		}
+	}
+
+	data, _ = json.Marshal(merged)
+	var account Account
+	json.Unmarshal(data, &account)
+	account.Sealed = nil
+	if len(sealed) > 0 {
+		account.Sealed = sealed
+	}
+	return account
+}
+
+// importBasePath stores the fields of every account as last imported; it is
+// the common ancestor for the three-way merge of the next import
+func importBasePath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".krakncat", "import-base.json")
+}
+
+func loadImportBase() map[string]accountFields {
+	base := map[string]accountFields{}
+	if data, err := os.ReadFile(importBasePath()); err == nil {
+		json.Unmarshal(data, &base)
+	}
+	return base
+}
+
+func saveImportBase(base map[string]accountFields) error {
+	data, err := json.MarshalIndent(base, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(importBasePath(), data, 0600)
+}
+
+// importPlan is the decision for one incoming account
+type importPlan struct {
+	Incoming   Account
+	Resolution string   // preferLocal, preferRemote, preferRename or preferMerge; "" adds a new account
+	TakeRemote []string // Fields taken from the incoming account when merging
+	NewName    string   // Name of the renamed incoming account
+}
+
+// uniqueAccountName returns name-2, name-3, ... not used by any account
+func uniqueAccountName(config *Config, incoming []Account, name string) string {
+	taken := func(candidate string) bool {
+		if config.getAccount(candidate) != nil {
+			return true
+		}
+		for _, account := range incoming {
+			if account.Name == candidate {
+				return true
+			}
+		}
+		return false
+	}
+	for i := 2; ; i++ {
+		if candidate := fmt.Sprintf("%s-%d", name, i); !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+var importConfigCmd = &cobra.Command{
+	Use:   "import-config <config.json>",
+	Short: "Merge the accounts of another machine's config into this one",
+	Long: `Merge the accounts of another krakncat config.json, e.g. copied or synced
+from another machine, into the local configuration. New accounts are added;
+accounts that exist on both sides with different settings are resolved:
+
+  local   Keep the local account
+  remote  Replace it with the incoming account
+  rename  Add the incoming account under a new name (e.g. work-2)
+  merge   Choose field by field
+
+The fields of each import are remembered, so the next import from the same
+source is a three-way merge: a field changed on only one side since then is
+taken from that side without asking. Only fields changed on both sides are
+conflicts. Without a terminal, --prefer decides conflicts; when merging
+non-interactively, the local value wins a conflicting field.
+
+Directory mappings, rules and the current account are not imported. Sealed
+fields can only be read with the identity or passphrase that sealed them.
+
+Examples:
+  krakn import-config ~/Dropbox/krakn/config.json
+  krakn import-config laptop.json --prefer rename
+  krakn import-config laptop.json --prefer merge`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		prefer, _ := cmd.Flags().GetString("prefer")
+		if prefer != "" && prefer != preferLocal && prefer != preferRemote && prefer != preferRename && prefer != preferMerge {
+			return fmt.Errorf("❌ Unknown --prefer '%s'. Use local, remote, rename or merge", prefer)
+		}
+
+		data, err := os.ReadFile(expandUserPath(args[0]))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", args[0], err)
+		}
+		var source Config
+		if err := json.Unmarshal(data, &source); err != nil {
+			return fmt.Errorf("❌ %s is not a krakncat config: %v", args[0], err)
+		}
+		source.expandPaths()
+		if len(source.Accounts) == 0 {
+			fmt.Printf("📭 %s has no accounts\n", args[0])
+			return nil
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		base := loadImportBase()
+		interactive := term.IsTerminal(int(os.Stdin.Fd()))
+		reader := bufio.NewReader(os.Stdin)
+
+		// Decide every account before changing anything
+		var plans []importPlan
+		asked := false
+		for _, incoming := range source.Accounts {
+			plan := importPlan{Incoming: incoming}
+			local := config.getAccount(incoming.Name)
+			if local == nil {
+				plans = append(plans, plan)
+				continue
+			}
+
+			localFields, remoteFields := fieldsOf(*local), fieldsOf(incoming)
+			baseFields := base[incoming.Name]
+			differing := differingFields(localFields, remoteFields)
+			if len(differing) == 0 {
+				plan.Resolution = preferLocal
+				plans = append(plans, plan)
+				continue
+			}
+
+			// Three-way merge: take one-sided changes, collect conflicts
+			var conflicts []string
+			for _, key := range differing {
+				switch fieldChange(baseFields, localFields, remoteFields, key) {
+				case "remote":
+					plan.TakeRemote = append(plan.TakeRemote, key)
+				case "local":
+					// Changed here only: keep the local value
+				default:
+					conflicts = append(conflicts, key)
+				}
+			}
+			if len(conflicts) == 0 {
+				plan.Resolution = preferMerge
+				plans = append(plans, plan)
+				continue
+			}
+
+			if !interactive {
+				if prefer == "" {
+					return fmt.Errorf("❌ Account '%s' differs from the local one (%s). Run interactively or pass --prefer local|remote|rename|merge",
+						incoming.Name, strings.Join(conflicts, ", "))
+				}
+				plan.Resolution = prefer
+			} else {
+				asked = true
+				fmt.Printf("\n⚠️  Account '%s' differs from the local one:\n", incoming.Name)
+				fmt.Printf("   %-16s %-32s %s\n", "FIELD", "LOCAL", "INCOMING")
+				for _, key := range differing {
+					note := map[string]string{"local": "changed here", "remote": "changed there", "both": "changed on both"}[fieldChange(baseFields, localFields, remoteFields, key)]
+					fmt.Printf("   %-16s %-32s %-32s %s\n", key, localFields.display(key), remoteFields.display(key), note)
+				}
+				defaultChoice := prefer
+				if defaultChoice == "" {
+					defaultChoice = preferMerge
+				}
+				fmt.Printf("💬 [l]ocal, [r]emote, re[n]ame incoming, [m]erge fields [%s]: ", defaultChoice)
+				input, _ := reader.ReadString('\n')
+				switch strings.ToLower(strings.TrimSpace(input)) {
+				case "":
+					plan.Resolution = defaultChoice
+				case "l", "local":
+					plan.Resolution = preferLocal
+				case "r", "remote":
+					plan.Resolution = preferRemote
+				case "n", "rename":
+					plan.Resolution = preferRename
+				case "m", "merge":
+					plan.Resolution = preferMerge
+				default:
+					return fmt.Errorf("❌ Invalid choice: %s", strings.TrimSpace(input))
+				}
+			}
+
+			switch plan.Resolution {
+			case preferRename:
+				plan.NewName = uniqueAccountName(config, source.Accounts, incoming.Name)
+				if interactive {
+					fmt.Printf("💬 New name [%s]: ", plan.NewName)
+					input, _ := reader.ReadString('\n')
+					if name := strings.TrimSpace(input); name != "" {
+						if config.getAccount(name) != nil {
+							return fmt.Errorf("❌ Account '%s' already exists", name)
+						}
+						plan.NewName = name
+					}
+				}
+			case preferMerge:
+				for _, key := range conflicts {
+					takeRemote := false
+					if interactive {
+						fmt.Printf("   %s: [l]ocal %s / [r]emote %s [l]: ", key, localFields.display(key), remoteFields.display(key))
+						input, _ := reader.ReadString('\n')
+						takeRemote = strings.HasPrefix(strings.ToLower(strings.TrimSpace(input)), "r")
+					}
+					if takeRemote {
+						plan.TakeRemote = append(plan.TakeRemote, key)
+					}
+				}
+			}
+			plans = append(plans, plan)
+		}
+
+		// Apply the decisions
+		counts := map[string]int{}
+		var imported []Account
+		if asked {
+			fmt.Println()
+		}
+		for _, plan := range plans {
+			incoming := plan.Incoming
+			var changed *Account
+			switch plan.Resolution {
+			case "":
+				incoming.IsDefault = false
+				config.Accounts = append(config.Accounts, incoming)
+				changed = &config.Accounts[len(config.Accounts)-1]
+				fmt.Printf("➕ Added '%s' (%s)\n", incoming.Name, accountEmailLabel(incoming))
+				counts["added"]++
+			case preferLocal:
+				local := config.getAccount(incoming.Name)
+				if len(differingFields(fieldsOf(*local), fieldsOf(incoming))) == 0 {
+					counts["unchanged"]++
+				} else {
+					fmt.Printf("⏭️  Kept the local '%s'\n", incoming.Name)
+					counts["kept local"]++
+				}
+			case preferRemote, preferMerge:
+				local := config.getAccount(incoming.Name)
+				takeRemote := plan.TakeRemote
+				if plan.Resolution == preferRemote {
+					takeRemote = differingFields(fieldsOf(*local), fieldsOf(incoming))
+				}
+				if len(takeRemote) == 0 {
+					fmt.Printf("⏭️  Kept the local '%s'\n", incoming.Name)
+					counts["kept local"]++
+					break
+				}
+				merged := mergeAccount(*local, incoming, takeRemote)
+				for i := range config.Accounts {
+					if config.Accounts[i].Name == incoming.Name {
+						config.Accounts[i] = merged
+						changed = &config.Accounts[i]
+					}
+				}
+				fmt.Printf("🔀 Updated '%s': %s from the incoming config\n", incoming.Name, strings.Join(takeRemote, ", "))
+				counts["updated"]++
+			case preferRename:
+				incoming.Name = plan.NewName
+				incoming.IsDefault = false
+				config.Accounts = append(config.Accounts, incoming)
+				changed = &config.Accounts[len(config.Accounts)-1]
+				fmt.Printf("➕ Added the incoming '%s' as '%s'\n", plan.Incoming.Name, plan.NewName)
+				counts["renamed"]++
+			}
+
+			if changed != nil {
+				imported = append(imported, *changed)
+			}
+			if changed != nil && changed.SSHHost == "" && changed.SSHKey != "" {
+				if err := upsertSSHHostBlock(changed.GetSSHHost(), changed.GenerateSSHConfig()); err != nil {
+					return err
+				}
+			}
+			base[plan.Incoming.Name] = fieldsOf(plan.Incoming)
+		}
+
+		if len(imported) > 0 {
+			if err := config.update(func(config *Config) error {
+				for _, account := range imported {
+					config.putAccount(account)
+				}
+				if config.CurrentAccount == "" {
+					config.CurrentAccount = config.Accounts[0].Name
+				}
+				return nil
+			}); err != nil {
+				return errSaveConfig(err)
+			}
+		}
+		if err := saveImportBase(base); err != nil {
+			return fmt.Errorf("failed to record the import: %w", err)
+		}
+
+		var summary []string
+		for _, key := range []string{"added", "updated", "renamed", "kept local", "unchanged"} {
+			if counts[key] > 0 {
+				summary = append(summary, fmt.Sprintf("%d %s", counts[key], key))
+			}
+		}
+		fmt.Printf("🎉 Imported %s: %s\n", args[0], strings.Join(summary, ", "))
+		for _, account := range source.Accounts {
+			if len(account.Sealed) > 0 {
+				fmt.Printf("🔒 '%s' has sealed fields; they open with the identity or passphrase of the machine that sealed them\n", account.Name)
+			}
+		}
+		return nil
+	},
+}
+
+// accountEmailLabel returns the email to show for an account
+func accountEmailLabel(account Account) string {
+	if account.isSealed("email") {
+		return "sealed email"
+	}
+	return account.Email
+}
+
+func init() {
+	importConfigCmd.Flags().String("prefer", "", "Resolve conflicting accounts without asking: local, remote, rename or merge")
+	RootCmd.AddCommand(importConfigCmd)
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// sshHostCandidate is an existing alias block that could become an account
+type sshHostCandidate struct {
+	Alias    string
+	HostName string
+	KeyPath  string
+	Provider Provider
+	Name     string // Suggested account name
+	Skip     string // Reason the block cannot be imported, if any
+}
+
+var aliasTokenSplitter = regexp.MustCompile(`[-_.]+`)
+
+// gitHostingTokens are alias parts that describe the provider rather than the account
+var gitHostingTokens = map[string]bool{
+	"github": true, "gitlab": true, "gitea": true, "forgejo": true,
+	"codeberg": true, "bitbucket": true, "azure": true, "sr": true, "ht": true,
+	"gh": true, "gl": true, "com": true, "org": true, "io": true, "net": true,
+}
+
+// suggestAccountName derives an account name from a host alias,
+// e.g. "github.com-work" → "work", "work.gitlab.com" → "work"
+func suggestAccountName(alias, hostName string) string {
+	rest := strings.Trim(strings.ReplaceAll(alias, hostName, ""), "-_.")
+	if rest != "" && rest != alias {
+		return rest
+	}
+
+	var parts []string
+	for _, token := range aliasTokenSplitter.Split(alias, -1) {
+		if token != "" && !gitHostingTokens[strings.ToLower(token)] {
+			parts = append(parts, token)
+		}
+	}
+	if len(parts) == 0 {
+		return alias
+	}
+	return strings.Join(parts, "-")
+}
+
+// isGitHostingBlock reports whether an ssh_config block points at a git host
+func isGitHostingBlock(block *sshHostBlock) bool {
+	hostName := strings.ToLower(block.hostName())
+	for _, name := range []string{"github", "gitlab", "gitea", "forgejo", "codeberg", "bitbucket", "azure", "sr.ht"} {
+		if strings.Contains(hostName, name) {
+			return true
+		}
+	}
+	return block.get("User") == "git"
+}
+
+// discoverSSHHostCandidates lists git hosting alias blocks of ~/.ssh/config
+func discoverSSHHostCandidates(config *Config) ([]sshHostCandidate, error) {
+	blocks, err := readSSHConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH config: %w", err)
+	}
+
+	var candidates []sshHostCandidate
+	for i := range blocks {
+		block := &blocks[i]
+		alias := block.alias()
+		if alias == "" || !isGitHostingBlock(block) {
+			continue
+		}
+
+		candidate := sshHostCandidate{
+			Alias:    alias,
+			HostName: block.hostName(),
+			Provider: providerForHostname(block.hostName()),
+		}
+		if user := block.get("User"); user != "" {
+			candidate.Provider.SSHUser = user
+		}
+		if port := block.get("Port"); port != "" && port != "22" {
+			candidate.Provider.SSHPort = port
+		}
+		if identity := block.get("IdentityFile"); identity != "" {
+			candidate.KeyPath = expandSSHPath(identity, block)
+		}
+		candidate.Name = suggestAccountName(alias, candidate.HostName)
+
+		switch {
+		case alias == candidate.HostName:
+			candidate.Skip = "not an alias"
+		case config.findAccountByHost(alias) != nil:
+			candidate.Skip = "already linked"
+		case config.getAccount(candidate.Name) != nil:
+			candidate.Skip = fmt.Sprintf("account '%s' exists", candidate.Name)
+		}
+
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+var importSSHHostsCmd = &cobra.Command{
+	Use:   "import-ssh-hosts",
+	Short: "Create accounts from existing ~/.ssh/config alias blocks",
+	Long: `Scan ~/.ssh/config for GitHub, GitLab and Gitea alias blocks and create a
+krakncat account for each one, inferring the provider, key and account name.
+
+The existing blocks are linked, not rewritten: the account keeps using your
+alias exactly as it is defined today.
+
+Examples:
+  krakn import-ssh-hosts         # Review the table and choose what to import
+  krakn import-ssh-hosts --yes   # Import every importable alias`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		candidates, err := discoverSSHHostCandidates(config)
+		if err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			fmt.Println("🔍 No git hosting alias blocks found in ~/.ssh/config")
+			return nil
+		}
+
+		fmt.Println("🔍 Git hosting aliases in ~/.ssh/config:")
+		fmt.Println()
+		fmt.Printf("   %-3s %-28s %-22s %-16s %s\n", "#", "ALIAS", "PROVIDER", "ACCOUNT", "KEY")
+		importable := 0
+		for i, candidate := range candidates {
+			name := candidate.Name
+			if candidate.Skip != "" {
+				name = "(" + candidate.Skip + ")"
+			} else {
+				importable++
+			}
+			key := contractHomePath(candidate.KeyPath)
+			if key == "" {
+				key = "-"
+			}
+			fmt.Printf("   %-3d %-28s %-22s %-16s %s\n", i+1, candidate.Alias, candidate.Provider.DisplayName, name, key)
+		}
+		fmt.Println()
+
+		if importable == 0 {
+			fmt.Println("✅ Every alias is already linked to an account")
+			return nil
+		}
+
+		reader := bufio.NewReader(os.Stdin)
+		selected := map[int]bool{}
+		if yes {
+			for i := range candidates {
+				selected[i] = true
+			}
+		} else {
+			fmt.Print("💬 Import which aliases? [all] or numbers separated by commas, 0 to cancel: ")
+			input, _ := reader.ReadString('\n')
+			input = strings.TrimSpace(input)
+			switch input {
+			case "0":
+				fmt.Println("❌ Import cancelled")
+				return nil
+			case "", "all":
+				for i := range candidates {
+					selected[i] = true
+				}
+			default:
+				for _, choice := range strings.Split(input, ",") {
+					index, err := strconv.Atoi(strings.TrimSpace(choice))
+					if err != nil || index < 1 || index > len(candidates) {
+						return fmt.Errorf("❌ Invalid choice: %s", choice)
+					}
+					selected[index-1] = true
+				}
+			}
+		}
+
+		imported := 0
+		for i, candidate := range candidates {
+			if !selected[i] || candidate.Skip != "" {
+				continue
+			}
+
+			account := Account{
+				Name:    candidate.Name,
+				SSHKey:  candidate.KeyPath,
+				SSHHost: candidate.Alias,
+			}
+			provider := candidate.Provider
+			account.Provider = &provider
+
+			if !yes {
+				fmt.Printf("\n🔧 %s → account '%s'\n", candidate.Alias, candidate.Name)
+				fmt.Print("📧 Email address (optional): ")
+				email, _ := reader.ReadString('\n')
+				account.Email = strings.TrimSpace(email)
+				fmt.Printf("👤 %s username (optional): ", provider.DisplayName)
+				username, _ := reader.ReadString('\n')
+				account.Username = strings.TrimSpace(username)
+			}
+
+			if err := config.addAccount(account); err != nil {
+				return fmt.Errorf("failed to add account '%s': %w", account.Name, err)
+			}
+			imported++
+			fmt.Printf("✅ Linked '%s' to existing alias %s\n", account.Name, candidate.Alias)
+		}
+
+		fmt.Printf("\n🎉 Imported %d account(s); ~/.ssh/config was not modified\n", imported)
+		return nil
+	},
+}
+
+func init() {
+	importSSHHostsCmd.Flags().BoolP("yes", "y", false, "Import all aliases without prompting")
+	RootCmd.AddCommand(importSSHHostsCmd)
+}
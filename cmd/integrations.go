@@ -0,0 +1,712 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Integration records a generated launcher extension, so it can be
+// regenerated when accounts change or krakn is upgraded
+type Integration struct {
+	Launcher string `json:"launcher"` // raycast, alfred, wox or vscode
+	Dir      string `json:"dir"`
+}
+
+// launcher generates the files of one launcher extension
+type launcher struct {
+	Name     string
+	Title    string
+	Dir      string // Default output below ~/.krakncat
+	Generate func(config *Config, executable string) (map[string][]byte, error)
+	Hints    func(dir string) []string
+	Install  func(dir string) error // Optional; installs the generated files into the application
+}
+
+var launchers = []launcher{
+	{
+		Name:     "raycast",
+		Title:    "Raycast script commands",
+		Dir:      "raycast",
+		Generate: raycastScripts,
+		Hints: func(dir string) []string {
+			return []string{"In Raycast: Extensions → Script Commands → Add Directories → " + contractHomePath(dir)}
+		},
+	},
+	{
+		Name:     "alfred",
+		Title:    "Alfred workflow",
+		Dir:      filepath.Join("integrations", "alfred"),
+		Generate: alfredWorkflow,
+		Hints: func(dir string) []string {
+			return []string{
+				"Open " + contractHomePath(filepath.Join(dir, "krakn.alfredworkflow")) + " to import it into Alfred",
+				"Type 'git' in Alfred to list and switch accounts",
+			}
+		},
+	},
+	{
+		Name:     "wox",
+		Title:    "Wox / Flow Launcher plugin",
+		Dir:      filepath.Join("integrations", "wox"),
+		Generate: woxPlugin,
+		Hints: func(dir string) []string {
+			return []string{
+				"Copy " + contractHomePath(dir) + " into the Plugins directory of Wox or Flow Launcher and restart it",
+				"Type 'git' to list and switch accounts (needs Python 3)",
+			}
+		},
+	},
+	{
+		Name:     "vscode",
+		Title:    "VS Code extension",
+		Dir:      filepath.Join("integrations", "vscode"),
+		Generate: vscodeExtension,
+		Install:  installVSCodeExtension,
+		Hints: func(dir string) []string {
+			return []string{
+				"The status bar shows the workspace's account; click it to switch",
+				"Command palette: 'krakn: Switch Git Account', 'krakn: Clone Repository as Account'",
+			}
+		},
+	},
+}
+
+func getLauncher(name string) *launcher {
+	for i := range launchers {
+		if launchers[i].Name == name {
+			return &launchers[i]
+		}
+	}
+	return nil
+}
+
+func launcherNames() []string {
+	var names []string
+	for _, l := range launchers {
+		names = append(names, l.Name)
+	}
+	return names
+}
+
+// generatedNotice heads every generated script
+func generatedNotice(name string) string {
+	return fmt.Sprintf("Generated by krakn %s ('krakn integrations generate %s'); regenerated by 'krakn integrations update'.", Version, name)
+}
+
+// launcherIPC is the shell part shared by the generated scripts: send a
+// request to the daemon socket and print its message, or print nothing when
+// the daemon is not running so the script can fall back to the CLI
+const launcherIPC = `SOCK="$HOME/.krakncat/krakn.sock"
+krakn_request() {
+  if [ -S "$SOCK" ] && command -v nc >/dev/null 2>&1; then
+    printf '%s\n' "$1" | nc -U "$SOCK" 2>/dev/null
+  fi
+}
+krakn_message() {
+  sed -n 's/.*"message":"\([^"]*\)".*/\1/p'
+}
+`
+
+// switchScript switches to the account in $1 through the daemon, or with
+// 'krakn use --json' when it is not running, and prints one line of result
+func switchScript(executable string) string {
+	return `response=$(krakn_request "{\"method\":\"switch\",\"account\":\"$1\"}")
+if [ -n "$response" ]; then
+  printf '%s\n' "$response" | krakn_message
+  case "$response" in *'"ok":true'*) exit 0 ;; *) exit 1 ;; esac
+fi
+if ` + shellQuote(executable) + ` --plain use --json "$1" >/dev/null 2>&1; then
+  echo "Switched to $1"
+else
+  echo "Could not switch to $1"
+  exit 1
+fi
+`
+}
+
+// raycastScript renders a Raycast script command with its metadata comments
+func raycastScript(metadata [][2]string, body string) []byte {
+	var b strings.Builder
+	b.WriteString("#!/bin/bash\n\n# " + generatedNotice("raycast") + "\n\n")
+	for _, field := range metadata {
+		fmt.Fprintf(&b, "# @raycast.%s %s\n", field[0], field[1])
+	}
+	b.WriteString("\n" + launcherIPC + "\n" + body)
+	return []byte(b.String())
+}
+
+// raycastScripts returns the script commands for the configured accounts.
+// Raycast reads the dropdown from the metadata, so it lists the accounts at
+// the time of generation.
+func raycastScripts(config *Config, executable string) (map[string][]byte, error) {
+	type option struct {
+		Title string `json:"title"`
+		Value string `json:"value"`
+	}
+	var options []option
+	for _, account := range config.Accounts {
+		options = append(options, option{Title: account.Name, Value: account.Name})
+	}
+	argument, err := json.Marshal(map[string]interface{}{"type": "dropdown", "placeholder": "Account", "data": options})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{
+		"krakn-current-account.sh": raycastScript([][2]string{
+			{"schemaVersion", "1"},
+			{"title", "Git Account"},
+			{"mode", "inline"},
+			{"refreshTime", "1m"},
+			{"packageName", "krakn"},
+			{"icon", "🐙"},
+			{"description", "Show the account of the global git identity"},
+		}, `response=$(krakn_request '{"method":"current"}')
+if [ -n "$response" ]; then
+  printf '%s\n' "$response" | krakn_message
+else
+  git config --global user.email || echo "No account"
+fi
+`),
+		"krakn-switch-account.sh": raycastScript([][2]string{
+			{"schemaVersion", "1"},
+			{"title", "Switch Git Account"},
+			{"mode", "compact"},
+			{"packageName", "krakn"},
+			{"icon", "🐙"},
+			{"description", "Switch the global git identity"},
+			{"argument1", string(argument)},
+		}, switchScript(executable)),
+	}, nil
+}
+
+// alfredScriptFilter lists the accounts for Alfred's script filter. It runs
+// 'krakn list --json' on every invocation, so new accounts show up without
+// regenerating the workflow.
+const alfredScriptFilter = `function run(argv) {
+  const app = Application.currentApplication()
+  app.includeStandardAdditions = true
+  let data
+  try {
+    data = JSON.parse(app.doShellScript(KRAKN + " --plain list --json"))
+  } catch (error) {
+    return JSON.stringify({items: [{title: "krakn failed", subtitle: String(error), valid: false}]})
+  }
+  const items = data.accounts.map(account => ({
+    uid: account.name,
+    title: account.current ? account.name + " ✓" : account.name,
+    subtitle: [account.email, account.username, account.provider].filter(Boolean).join(" · "),
+    match: [account.name, account.email, account.username].filter(Boolean).join(" "),
+    arg: account.name,
+  }))
+  if (items.length === 0) {
+    items.push({title: "No accounts", subtitle: "Add one with 'krakn add'", valid: false})
+  }
+  return JSON.stringify({items: items})
+}
+`
+
+// alfredWorkflow packs a workflow with a script filter listing the accounts,
+// a script switching to the chosen one and a notification of the result
+func alfredWorkflow(config *Config, executable string) (map[string][]byte, error) {
+	krakn, err := json.Marshal(shellQuote(executable))
+	if err != nil {
+		return nil, err
+	}
+	filter := "const KRAKN = " + string(krakn) + "\n\n" + alfredScriptFilter
+	action := "# " + generatedNotice("alfred") + "\n\n" + launcherIPC + "\n" + switchScript(executable)
+
+	const (
+		filterUID       = "6E1B2C3D-0001-4A6B-9C1D-4B7241C4A701"
+		actionUID       = "6E1B2C3D-0002-4A6B-9C1D-4B7241C4A701"
+		notificationUID = "6E1B2C3D-0003-4A6B-9C1D-4B7241C4A701"
+	)
+	connection := func(destination string) string {
+		return `		<array>
+			<dict>
+				<key>destinationuid</key>
+				<string>` + destination + `</string>
+				<key>modifiers</key>
+				<integer>0</integer>
+				<key>modifiersubtext</key>
+				<string></string>
+				<key>vitoclose</key>
+				<false/>
+			</dict>
+		</array>
+`
+	}
+
+	plist := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>bundleid</key>
+	<string>com.krakncat.alfred</string>
+	<key>name</key>
+	<string>krakn</string>
+	<key>description</key>
+	<string>List and switch git accounts</string>
+	<key>createdby</key>
+	<string>krakncat</string>
+	<key>webaddress</key>
+	<string>https://github.com/alminisl/krakncat</string>
+	<key>version</key>
+	<string>` + xmlText(Version) + `</string>
+	<key>readme</key>
+	<string>` + xmlText(generatedNotice("alfred")) + `</string>
+	<key>connections</key>
+	<dict>
+		<key>` + filterUID + `</key>
+` + connection(actionUID) + `		<key>` + actionUID + `</key>
+` + connection(notificationUID) + `	</dict>
+	<key>objects</key>
+	<array>
+		<dict>
+			<key>type</key>
+			<string>alfred.workflow.input.scriptfilter</string>
+			<key>uid</key>
+			<string>` + filterUID + `</string>
+			<key>version</key>
+			<integer>3</integer>
+			<key>config</key>
+			<dict>
+				<key>keyword</key>
+				<string>git</string>
+				<key>title</key>
+				<string>Switch git account</string>
+				<key>subtext</key>
+				<string>List your krakn accounts</string>
+				<key>withspace</key>
+				<true/>
+				<key>argumenttype</key>
+				<integer>1</integer>
+				<key>alfredfiltersresults</key>
+				<true/>
+				<key>alfredfiltersresultsmatchmode</key>
+				<integer>0</integer>
+				<key>type</key>
+				<integer>7</integer>
+				<key>scriptargtype</key>
+				<integer>1</integer>
+				<key>script</key>
+				<string>` + xmlText(filter) + `</string>
+			</dict>
+		</dict>
+		<dict>
+			<key>type</key>
+			<string>alfred.workflow.action.script</string>
+			<key>uid</key>
+			<string>` + actionUID + `</string>
+			<key>version</key>
+			<integer>2</integer>
+			<key>config</key>
+			<dict>
+				<key>type</key>
+				<integer>0</integer>
+				<key>scriptargtype</key>
+				<integer>1</integer>
+				<key>escaping</key>
+				<integer>102</integer>
+				<key>script</key>
+				<string>` + xmlText(action) + `</string>
+			</dict>
+		</dict>
+		<dict>
+			<key>type</key>
+			<string>alfred.workflow.output.notification</string>
+			<key>uid</key>
+			<string>` + notificationUID + `</string>
+			<key>version</key>
+			<integer>1</integer>
+			<key>config</key>
+			<dict>
+				<key>title</key>
+				<string>krakn</string>
+				<key>text</key>
+				<string>{query}</string>
+				<key>onlyshowifquerypopulated</key>
+				<true/>
+			</dict>
+		</dict>
+	</array>
+</dict>
+</plist>
+`
+
+	// No timestamps, so regenerating an unchanged workflow gives the same bytes
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	writer, err := archive.CreateHeader(&zip.FileHeader{Name: "info.plist", Method: zip.Deflate})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write([]byte(plist)); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return map[string][]byte{"krakn.alfredworkflow": buf.Bytes()}, nil
+}
+
+// woxMain is the JSON-RPC entry point of the Wox plugin. Wox and Flow
+// Launcher run it with a request such as {"method":"query","parameters":["w"]}
+// and expect the results on stdout.
+const woxMain = `import json
+import subprocess
+import sys
+
+
+def krakn(*args):
+    output = subprocess.run([KRAKN, "--plain"] + list(args), capture_output=True, text=True, check=True).stdout
+    return json.loads(output)
+
+
+def query(text=""):
+    try:
+        data = krakn("list", "--json")
+    except Exception as error:
+        return [{"Title": "krakn failed", "SubTitle": str(error), "IcoPath": ""}]
+    results = []
+    for account in data["accounts"]:
+        haystack = " ".join(filter(None, [account["name"], account.get("email"), account.get("username")]))
+        if text.strip().lower() not in haystack.lower():
+            continue
+        results.append({
+            "Title": account["name"] + (" ✓" if account["current"] else ""),
+            "SubTitle": " · ".join(filter(None, [account.get("email"), account.get("username"), account["provider"]])),
+            "IcoPath": "",
+            "JsonRPCAction": {"method": "switch", "parameters": [account["name"]], "dontHideAfterAction": False},
+        })
+    if not data["accounts"]:
+        results.append({"Title": "No accounts", "SubTitle": "Add one with 'krakn add'", "IcoPath": ""})
+    return results
+
+
+def switch(name):
+    try:
+        krakn("use", "--json", name)
+    except Exception:
+        pass
+    return []
+
+
+if __name__ == "__main__":
+    request = json.loads(sys.argv[1])
+    handler = {"query": query, "switch": switch}.get(request.get("method"))
+    result = handler(*request.get("parameters", [])) if handler else []
+    print(json.dumps({"result": result}))
+`
+
+// woxPlugin returns a Python plugin for Wox and Flow Launcher
+func woxPlugin(config *Config, executable string) (map[string][]byte, error) {
+	krakn, err := json.Marshal(executable)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := json.MarshalIndent(map[string]interface{}{
+		"ID":              "5b0f4c8e6a3d4e1f9b2a7c6d8e9f0a1b",
+		"ActionKeyword":   "git",
+		"Name":            "krakn",
+		"Description":     "List and switch git accounts",
+		"Author":          "krakncat",
+		"Version":         Version,
+		"Language":        "python",
+		"Website":         "https://github.com/alminisl/krakncat",
+		"ExecuteFileName": "main.py",
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	main := "# " + generatedNotice("wox") + "\n\nKRAKN = " + string(krakn) + "\n\n" + woxMain
+	return map[string][]byte{
+		"plugin.json": append(manifest, '\n'),
+		"main.py":     []byte(main),
+	}, nil
+}
+
+// generateIntegration writes the files of a launcher into dir and returns
+// the paths that changed
+func generateIntegration(config *Config, l *launcher, dir string) ([]string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the krakn binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	files, err := l.Generate(config, executable)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var changed []string
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, files[name]) {
+			continue
+		}
+		mode := os.FileMode(0644)
+		if strings.HasSuffix(name, ".sh") {
+			mode = 0755
+		}
+		trackFile(path)
+		if err := writeFileAtomic(path, files[name], mode); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		changed = append(changed, path)
+	}
+	return changed, nil
+}
+
+// hasIntegration reports whether a launcher extension generated into dir is recorded
+func (c *Config) hasIntegration(launcher, dir string) bool {
+	for _, integration := range c.Integrations {
+		if integration.Launcher == launcher && integration.Dir == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// updateIntegrations regenerates every recorded integration
+func updateIntegrations(config *Config) ([]string, error) {
+	var changed []string
+	for _, integration := range config.Integrations {
+		l := getLauncher(integration.Launcher)
+		if l == nil {
+			continue
+		}
+		paths, err := generateIntegration(config, l, integration.Dir)
+		if err != nil {
+			return changed, fmt.Errorf("%s: %w", l.Title, err)
+		}
+		changed = append(changed, paths...)
+		if len(paths) > 0 && l.Install != nil {
+			if err := l.Install(integration.Dir); err != nil {
+				return changed, fmt.Errorf("%s: %w", l.Title, err)
+			}
+		}
+	}
+	return changed, nil
+}
+
+var integrationsCmd = &cobra.Command{
+	Use:   "integrations",
+	Short: "Generate launcher, tray and editor integrations",
+	Long: `Generate extensions for Raycast, Alfred, Wox / Flow Launcher and VS Code that
+list your accounts and switch between them. They use the socket API of 'krakn watch'
+(see 'krakn watch --help') when it is running, so switching is instant, and
+otherwise call 'krakn list --json' and 'krakn use --json'.
+
+Generated integrations are remembered and regenerated by 'krakn integrations
+update' and by 'krakn watch', so they follow new accounts and krakn upgrades.
+
+Examples:
+  krakn integrations generate raycast
+  krakn integrations generate alfred
+  krakn integrations generate wox --output ~/wox-plugins/krakn
+  krakn integrations generate vscode
+  krakn integrations update`,
+}
+
+var integrationsGenerateCmd = &cobra.Command{
+	Use:       "generate <raycast|alfred|wox|vscode>",
+	Short:     "Generate a launcher extension",
+	ValidArgs: launcherNames(),
+	Long: `Generate a launcher extension:
+
+  raycast   Script commands: "Git Account" shows the current account inline,
+            "Switch Git Account" switches with a dropdown of your accounts
+            (default ~/.krakncat/raycast)
+  alfred    krakn.alfredworkflow: type 'git' to list and switch accounts
+            (default ~/.krakncat/integrations/alfred)
+  wox       A Python plugin for Wox and Flow Launcher: type 'git' to list and
+            switch accounts (default ~/.krakncat/integrations/wox)
+  vscode    krakn.vsix, installed with 'code --install-extension' when VS Code
+            is on PATH: the status bar shows the workspace's account and
+            whether pushes match it; the command palette switches accounts
+            and clones (default ~/.krakncat/integrations/vscode)
+
+Examples:
+  krakn integrations generate raycast
+  krakn integrations generate vscode
+  krakn integrations generate alfred
+  krakn integrations generate wox --output ~/wox-plugins/krakn`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		l := getLauncher(args[0])
+		if l == nil {
+			return fmt.Errorf("❌ Unknown launcher '%s'; use %s", args[0], strings.Join(launcherNames(), ", "))
+		}
+		output, _ := cmd.Flags().GetString("output")
+		return runIntegrationsGenerate(l, output)
+	},
+}
+
+func runIntegrationsGenerate(l *launcher, output string) error {
+	if output == "" {
+		homeDir, _ := os.UserHomeDir()
+		output = filepath.Join(homeDir, ".krakncat", l.Dir)
+	}
+	output = expandUserPath(output)
+	if abs, err := filepath.Abs(output); err == nil {
+		output = abs
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return errLoadConfig(err)
+	}
+	if len(config.Accounts) == 0 {
+		return errNoAccounts()
+	}
+	if _, err := generateIntegration(config, l, output); err != nil {
+		return err
+	}
+	files, _ := l.Generate(config, "")
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("📝 %s\n", contractHomePath(filepath.Join(output, name)))
+	}
+	if l.Install != nil {
+		if err := l.Install(output); err != nil {
+			return err
+		}
+	}
+
+	if !config.hasIntegration(l.Name, output) {
+		if err := config.update(func(config *Config) error {
+			if !config.hasIntegration(l.Name, output) {
+				config.Integrations = append(config.Integrations, Integration{Launcher: l.Name, Dir: output})
+			}
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+	}
+
+	fmt.Printf("✅ %s for %d accounts\n", l.Title, len(config.Accounts))
+	for _, hint := range l.Hints(output) {
+		fmt.Printf("💡 %s\n", hint)
+	}
+	fmt.Println("💡 Keep 'krakn watch' running ('krakn service install') for instant switching")
+	return nil
+}
+
+// launcherAliasCmd is the hidden 'integrations <launcher>' shorthand for
+// 'integrations generate <launcher>'. 'integrations raycast' was the command
+// before other launchers existed, and the shorthand is what people try first.
+func launcherAliasCmd(l *launcher) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    l.Name,
+		Short:  "Generate the " + l.Title,
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString("output")
+			return runIntegrationsGenerate(l, output)
+		},
+	}
+	cmd.Flags().StringP("output", "o", "", "Directory for the generated files")
+	return cmd
+}
+
+var integrationsUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Regenerate the generated launcher extensions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if len(config.Integrations) == 0 {
+			fmt.Println("📭 No integrations generated yet. Generate one with 'krakn integrations generate <launcher>'.")
+			return nil
+		}
+		changed, err := updateIntegrations(config)
+		for _, path := range changed {
+			fmt.Printf("📝 %s\n", contractHomePath(path))
+		}
+		if err != nil {
+			return err
+		}
+		if len(changed) == 0 {
+			fmt.Println("✅ All integrations are up to date")
+		} else {
+			fmt.Printf("✅ Updated %d files\n", len(changed))
+		}
+		return nil
+	},
+}
+
+func init() {
+	integrationsGenerateCmd.Flags().StringP("output", "o", "", "Directory for the generated files")
+	integrationsCmd.AddCommand(integrationsGenerateCmd)
+	integrationsCmd.AddCommand(integrationsUpdateCmd)
+	for i := range launchers {
+		integrationsCmd.AddCommand(launcherAliasCmd(&launchers[i]))
+	}
+	RootCmd.AddCommand(integrationsCmd)
+
+	// New accounts and upgrades of krakn change the generated files
+	registerWatchTask(watchTask{
+		Name: "Integrations",
+		Run: func(ctx *watchContext) error {
+			changed, err := updateIntegrations(ctx.Config)
+			for _, path := range changed {
+				fmt.Printf("🧩 %s Updated %s\n", ctx.Now.Format("15:04"), contractHomePath(path))
+			}
+			return err
+		},
+	})
+	registerUninstallStep(uninstallStep{
+		Name: "launcher integrations",
+		Describe: func(config *Config) []string {
+			var paths []string
+			for _, integration := range config.Integrations {
+				if fileExists(integration.Dir) {
+					paths = append(paths, contractHomePath(integration.Dir))
+				}
+			}
+			return paths
+		},
+		Run: func(config *Config) error {
+			for _, integration := range config.Integrations {
+				if l := getLauncher(integration.Launcher); l != nil {
+					files, _ := l.Generate(config, "")
+					for name := range files {
+						path := filepath.Join(integration.Dir, name)
+						trackFile(path)
+						os.Remove(path)
+					}
+				}
+				// Only removed when nothing else was put there
+				os.Remove(integration.Dir)
+			}
+			return nil
+		},
+	})
+}
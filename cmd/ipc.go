@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ipcVersion is the version of the socket API. Fields are only ever added;
+// a change that breaks clients gets a new version.
+const ipcVersion = 1
+
+// daemonMu serializes the wake-ups of 'krakn watch' and the requests served
+// on its socket, which change the same files
+var daemonMu sync.Mutex
+
+func getSocketPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".krakncat", "krakn.sock")
+}
+
+// ipcRequest is one line of JSON sent by a client
+type ipcRequest struct {
+	Method  string `json:"method"`            // "accounts", "current", "switch", "list", "resolve" or "doctor"
+	Account string `json:"account,omitempty"` // Account to switch to
+	Path    string `json:"path,omitempty"`    // Directory to resolve or diagnose, e.g. the editor's workspace folder
+}
+
+// ipcAccount describes an account to clients; secrets are never included
+type ipcAccount struct {
+	Name        string `json:"name"`
+	Email       string `json:"email,omitempty"`
+	Username    string `json:"username,omitempty"`
+	Provider    string `json:"provider"`
+	Auth        string `json:"auth" desc:"ssh or https"`
+	Current     bool   `json:"current"`
+	Fingerprint string `json:"fingerprint,omitempty" desc:"SHA256 fingerprint of the account's public key"`
+}
+
+// describeAccounts lists the accounts for clients of the socket API and of
+// 'krakn list --json'
+func describeAccounts(config *Config) []ipcAccount {
+	accounts := []ipcAccount{}
+	for _, account := range config.Accounts {
+		item := ipcAccount{
+			Name:     account.Name,
+			Username: account.Username,
+			Provider: account.GetProvider().Name,
+			Auth:     authSSH,
+			Current:  account.Name == config.CurrentAccount,
+		}
+		if !account.isSealed("email") {
+			item.Email = account.Email
+		}
+		if account.usesHTTPS() {
+			item.Auth = authHTTPS
+		} else if account.SSHKey != "" {
+			_, item.Fingerprint, _ = describePublicKey(account.SSHKey + ".pub")
+		}
+		accounts = append(accounts, item)
+	}
+	return accounts
+}
+
+// ipcResponse answers a request. Message is a one-line summary for clients
+// that just display it, such as the generated Raycast scripts.
+type ipcResponse struct {
+	API      int          `json:"api"`
+	OK       bool         `json:"ok"`
+	Error    string       `json:"error,omitempty"`
+	Message  string       `json:"message,omitempty"`
+	Current  string       `json:"current,omitempty"`
+	Accounts []ipcAccount `json:"accounts,omitempty"`
+	// Result is the document of the list, resolve and doctor methods, in the
+	// schema 'krakn schema list|status|doctor' prints
+	Result interface{} `json:"result,omitempty"`
+}
+
+// handleIPC answers one request against the current configuration
+func handleIPC(request ipcRequest, state *watchState) ipcResponse {
+	fail := func(format string, args ...interface{}) ipcResponse {
+		message := fmt.Sprintf(format, args...)
+		return ipcResponse{API: ipcVersion, Error: message, Message: message}
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return fail("could not load config: %v", err)
+	}
+
+	switch request.Method {
+	case "accounts":
+		response := ipcResponse{API: ipcVersion, OK: true, Current: config.CurrentAccount, Accounts: describeAccounts(config)}
+		response.Message = fmt.Sprintf("%d accounts", len(response.Accounts))
+		return response
+
+	case "current":
+		if config.CurrentAccount == "" {
+			return ipcResponse{API: ipcVersion, OK: true, Message: "No account"}
+		}
+		return ipcResponse{API: ipcVersion, OK: true, Current: config.CurrentAccount, Message: config.CurrentAccount}
+
+	case "switch":
+		account := config.getAccount(request.Account)
+		if account == nil {
+			return fail("account '%s' not found", request.Account)
+		}
+		// Nobody is at a terminal to enter a passphrase
+		if account.isSealed("email") {
+			return fail("account '%s' has a sealed email; switch with 'krakn use %s'", account.Name, account.Name)
+		}
+		if _, err := switchGlobalAccount(config, account, "socket"); err != nil {
+			return fail("could not switch: %v", err)
+		}
+		state.LastSwitch = time.Now()
+		state.LastSwitchTo = account.Name
+		state.save()
+		if err := finishOperation(); err != nil {
+			fmt.Printf("⚠️  Could not record operation history: %v\n", err)
+		}
+		fmt.Printf("🔄 %s Switched to '%s' (socket)\n", time.Now().Format("15:04"), account.Name)
+		return ipcResponse{API: ipcVersion, OK: true, Current: account.Name, Message: "Switched to " + account.Name}
+
+	case "list":
+		result := listOutput{SchemaVersion: schemaVersion("list"), Current: config.CurrentAccount, Accounts: describeAccounts(config)}
+		if request.Path != "" {
+			result.Local = gitIdentity{Name: getRepoGitConfig(request.Path, "user.name"), Email: getRepoGitConfig(request.Path, "user.email")}
+		}
+		result.Global = gitIdentity{Name: getGitConfig("user.name", true), Email: getGitConfig("user.email", true)}
+		return ipcResponse{API: ipcVersion, OK: true, Current: config.CurrentAccount, Message: fmt.Sprintf("%d accounts", len(result.Accounts)), Result: result}
+
+	case "resolve":
+		if request.Path == "" {
+			return fail("resolve needs a path")
+		}
+		repoRoot, err := findRepoRoot(request.Path)
+		if err != nil {
+			return fail("%s is not inside a git repository", request.Path)
+		}
+		status := repoStatus(config, repoRoot)
+		message := status.Account
+		if status.Match == "mismatch" {
+			message += " ⚠️"
+		}
+		return ipcResponse{API: ipcVersion, OK: true, Current: config.CurrentAccount, Message: message, Result: status}
+
+	case "doctor":
+		ctx := &doctorContext{Config: config}
+		if request.Path != "" {
+			if root, err := findRepoRoot(request.Path); err == nil {
+				ctx.RepoRoot = root
+			}
+		}
+		report := runDoctorChecks(ctx)
+		return ipcResponse{API: ipcVersion, OK: true, Current: config.CurrentAccount, Message: fmt.Sprintf("%d problem(s)", report.Problems), Result: report}
+	}
+	return fail("unknown method '%s'; use accounts, current, switch, list, resolve or doctor", request.Method)
+}
+
+// serveIPC listens on a socket for 'krakn watch' and 'krakn serve'. Each
+// connection carries one request line and gets one response line. The socket
+// is only accessible to the user.
+func serveIPC(path string, state *watchState) (net.Listener, error) {
+	// A socket left behind by a crashed daemon refuses connections
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("another krakn process ('krakn watch' or 'krakn serve') is serving %s", contractHomePath(path))
+	}
+	os.Remove(path)
+	if err := makePrivateDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	listener, err := listenPrivate(path)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(10 * time.Second))
+				line, err := bufio.NewReader(conn).ReadBytes('\n')
+				if err != nil && len(line) == 0 {
+					return
+				}
+				var request ipcRequest
+				var response ipcResponse
+				if err := json.Unmarshal(line, &request); err != nil {
+					response = ipcResponse{API: ipcVersion, Error: "invalid request: " + err.Error()}
+				} else {
+					daemonMu.Lock()
+					response = handleIPC(request, state)
+					daemonMu.Unlock()
+				}
+				data, _ := json.Marshal(response)
+				conn.Write(append(data, '\n'))
+			}()
+		}
+	}()
+	return listener, nil
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// hostKeyPinned reports whether known_hosts holds a key for host:port.
+// Lookups use a throwaway key: a mismatch means the host is pinned.
+func hostKeyPinned(check ssh.HostKeyCallback, host, port string) bool {
+	if port == "" {
+		port = "22"
+	}
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return false
+	}
+	probe, err := ssh.NewPublicKey(public)
+	if err != nil {
+		return false
+	}
+	err = check(net.JoinHostPort(host, port), &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 22}, probe)
+	var keyErr *knownhosts.KeyError
+	return errors.As(err, &keyErr) && len(keyErr.Want) > 0
+}
+
+// checkKeyStrength flags weak key algorithms, keys stored without a
+// passphrase and provider hosts whose host key is not pinned
+func checkKeyStrength(ctx *doctorContext) []doctorFinding {
+	var findings []doctorFinding
+	for _, account := range ctx.Config.Accounts {
+		if account.usesHTTPS() || account.SSHKey == "" || !fileExists(account.SSHKey) {
+			continue
+		}
+
+		if data, err := os.ReadFile(account.SSHKey + ".pub"); err == nil {
+			if key, _, _, _, err := ssh.ParseAuthorizedKey(data); err == nil {
+				if reason := weakKeyReason(key); reason != "" {
+					findings = append(findings, doctorFinding{
+						Level:   doctorError,
+						Message: fmt.Sprintf("Account '%s': %s", account.Name, reason),
+						Hint:    "krakn key rotate " + account.Name,
+					})
+				}
+			}
+		}
+
+		// A key that parses without a passphrase is stored unprotected
+		if data, err := os.ReadFile(account.SSHKey); err == nil {
+			if _, err := ssh.ParseRawPrivateKey(data); err == nil {
+				findings = append(findings, doctorFinding{
+					Level:   doctorInfo,
+					Message: fmt.Sprintf("Account '%s': SSH key has no passphrase", account.Name),
+					Hint:    "ssh-keygen -p -f " + contractHomePath(account.SSHKey),
+				})
+			}
+		}
+
+		for _, option := range account.SSHOptions {
+			value := strings.ToLower(option.Value)
+			if strings.EqualFold(option.Key, "StrictHostKeyChecking") && value == "no" ||
+				strings.EqualFold(option.Key, "UserKnownHostsFile") && value == "/dev/null" {
+				findings = append(findings, doctorFinding{
+					Level:   doctorWarn,
+					Message: fmt.Sprintf("Account '%s': %s %s disables host key verification", account.Name, option.Key, option.Value),
+					Hint:    fmt.Sprintf("krakn ssh-options %s --unset %s", account.Name, option.Key),
+				})
+			}
+		}
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	check, err := knownhosts.New(filepath.Join(homeDir, ".ssh", "known_hosts"))
+	if err != nil {
+		check = func(string, net.Addr, ssh.PublicKey) error { return &knownhosts.KeyError{} }
+	}
+	var seen []string
+	for i := range ctx.Config.Accounts {
+		account := &ctx.Config.Accounts[i]
+		if account.usesHTTPS() || account.SSHKey == "" {
+			continue
+		}
+		_, host, port := sshEndpoint(account)
+		if containsString(seen, host+":"+port) {
+			continue
+		}
+		seen = append(seen, host+":"+port)
+		if !hostKeyPinned(check, host, port) {
+			findings = append(findings, doctorFinding{
+				Level:   doctorWarn,
+				Message: fmt.Sprintf("Host key of %s is not pinned in ~/.ssh/known_hosts", host),
+				Hint:    fmt.Sprintf("krakn test %s --accept-new, then compare the fingerprint with the one %s publishes", account.Name, account.GetProvider().DisplayName),
+			})
+		}
+	}
+
+	if len(findings) == 0 && len(ctx.Config.Accounts) > 0 {
+		findings = append(findings, doctorFinding{Level: doctorOK, Message: "Keys use strong algorithms and all hosts are pinned"})
+	}
+	return findings
+}
+
+// generateKeyAt writes a new ed25519 key pair, with ssh-keygen when installed
+func generateKeyAt(keyPath, comment string) error {
+	if !sshKeygenAvailable() {
+		return generateNativeEd25519Key(keyPath, comment, "")
+	}
+	keygen := traceExec(exec.Command("ssh-keygen", "-t", "ed25519", "-C", comment, "-f", keyPath, "-q", "-N", ""))
+	if output, err := keygen.CombinedOutput(); err != nil {
+		return fmt.Errorf("ssh-keygen failed: %s", firstLine(string(output)))
+	}
+	return restrictToOwner(keyPath)
+}
+
+var keyRotateCmd = &cobra.Command{
+	Use:   "rotate <account-name>",
+	Short: "Replace an account's SSH key with a new ed25519 key",
+	Long: `Replace an account's SSH key with a newly generated ed25519 key at the same
+path, so ~/.ssh/config and repositories keep working unchanged. The old key is
+kept next to it as <key>.old-<date> until you have added the new key to the
+provider and removed the old one there.
+
+Examples:
+  krakn key rotate work
+  krakn doctor          # Lists weak keys with the rotate command to run`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+		if account.SSHKey == "" {
+			return newError(codeNoSSHKey, "krakn key generate --name "+account.Name+" --email <email>", "Account '%s' has no SSH key", account.Name)
+		}
+		if err := config.revealAccount(account); err != nil {
+			return err
+		}
+
+		keyPath := account.SSHKey
+		oldPath := keyPath + ".old-" + time.Now().Format("20060102")
+		if fileExists(oldPath) {
+			return fmt.Errorf("❌ %s already exists from an earlier rotation today; remove it first", contractHomePath(oldPath))
+		}
+
+		// Keep the old pair until the new key works on the provider
+		hadKey := fileExists(keyPath)
+		if hadKey {
+			for _, suffix := range []string{"", ".pub"} {
+				trackFile(keyPath + suffix)
+				trackFile(oldPath + suffix)
+				if err := os.Rename(keyPath+suffix, oldPath+suffix); err != nil && !os.IsNotExist(err) {
+					return fmt.Errorf("failed to move the old key aside: %w", err)
+				}
+			}
+		}
+
+		spin := startSpinner("Generating a new ed25519 key")
+		err = generateKeyAt(keyPath, account.Email)
+		spin.Stop()
+		if err != nil {
+			if hadKey {
+				os.Rename(oldPath, keyPath)
+				os.Rename(oldPath+".pub", keyPath+".pub")
+			}
+			return fmt.Errorf("failed to generate key: %w", err)
+		}
+		if err := upsertSSHHostBlock(account.GetSSHHost(), account.GenerateSSHConfig()); err != nil {
+			return err
+		}
+
+		publicKey, _ := os.ReadFile(keyPath + ".pub")
+		_, fingerprint, _ := describePublicKey(keyPath + ".pub")
+		provider := account.GetProvider()
+		fmt.Printf("✅ New key for '%s' at %s (%s)\n", account.Name, contractHomePath(keyPath), fingerprint)
+		if hadKey {
+			fmt.Printf("📦 Old key kept at %s\n", contractHomePath(oldPath))
+		}
+		fmt.Println("\n🔑 Public key:\n" + strings.TrimSpace(string(publicKey)))
+		fmt.Println("\n📋 Next steps:")
+		fmt.Printf("   1. Add the public key on %s: %s\n", provider.DisplayName, provider.WebURL)
+		fmt.Printf("   2. Check it works: krakn test %s\n", account.Name)
+		if hadKey {
+			fmt.Printf("   3. Delete the old key on %s, then: rm %s %s.pub\n", provider.DisplayName, contractHomePath(oldPath), contractHomePath(oldPath))
+		}
+		return nil
+	},
+}
+
+func init() {
+	keyCmd.AddCommand(keyRotateCmd)
+	registerDoctorCheck(doctorCheck{Name: "Key strength", Run: checkKeyStrength})
+}
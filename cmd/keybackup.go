@@ -0,0 +1,463 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/spf13/cobra"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/crypto/ssh"
+)
+
+// keyBackup is the content of an encrypted key backup
+type keyBackup struct {
+	Version    int       `json:"version"`
+	Created    time.Time `json:"created"`
+	Account    Account   `json:"account"`
+	PrivateKey string    `json:"private_key"`
+	PublicKey  string    `json:"public_key"`
+}
+
+// paperEncoding is the alphabet of paper backups before they were words: no
+// padding, and case does not matter when typing the code back in
+var paperEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// backupPassphrase returns the backup passphrase from KRAKN_BACKUP_PASSPHRASE
+// or a prompt. It is separate from the private config passphrase on purpose:
+// a backup outlives the machine it was made on.
+func backupPassphrase(confirm bool) (string, error) {
+	if env := os.Getenv("KRAKN_BACKUP_PASSPHRASE"); env != "" {
+		return env, nil
+	}
+	passphrase, err := readSecret("🔐 Backup passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("❌ The passphrase cannot be empty")
+	}
+	if confirm {
+		again, err := readSecret("🔐 Confirm passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", fmt.Errorf("❌ Passphrases do not match")
+		}
+	}
+	return passphrase, nil
+}
+
+// parsePrivateKeyFile parses an OpenSSH private key, asking for its
+// passphrase when it is encrypted
+func parsePrivateKeyFile(path string) (interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ssh.ParseRawPrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		passphrase, readErr := readSecret(fmt.Sprintf("🔑 Passphrase for %s: ", contractHomePath(path)))
+		if readErr != nil {
+			return nil, readErr
+		}
+		key, err = ssh.ParseRawPrivateKeyWithPassphrase(data, []byte(passphrase))
+	}
+	return key, err
+}
+
+// paperCode renders an ed25519 seed as its 24 BIP39 words, numbered and four
+// to a line, so it can be written down and read back without confusing
+// similar characters. The last word carries a checksum.
+func paperCode(seed []byte) (string, error) {
+	mnemonic, err := bip39.NewMnemonic(seed)
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	var line strings.Builder
+	for i, word := range strings.Fields(mnemonic) {
+		fmt.Fprintf(&line, "%2d %-10s", i+1, word)
+		if (i+1)%4 == 0 {
+			lines = append(lines, strings.TrimRight(line.String(), " "))
+			line.Reset()
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// parsePaperCode turns typed-in paper words back into the seed. Numbers
+// between the words are ignored. Codes printed by earlier versions, groups of
+// base32 characters, are still accepted.
+func parsePaperCode(code string) ([]byte, error) {
+	var words []string
+	for _, field := range strings.Fields(strings.ToLower(code)) {
+		if strings.Trim(field, "0123456789.)") != "" {
+			words = append(words, field)
+		}
+	}
+	if len(words) != 24 || len(words[0]) < 3 {
+		return parseBase32PaperCode(code)
+	}
+	for i, word := range words {
+		if _, ok := bip39.GetWordIndex(word); !ok {
+			return nil, fmt.Errorf("❌ Word %d ('%s') is not a paper backup word; check its spelling", i+1, word)
+		}
+	}
+	seed, err := bip39.EntropyFromMnemonic(strings.Join(words, " "))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("❌ The paper code checksum does not match; check the order of the words")
+	}
+	return seed, nil
+}
+
+// parseBase32PaperCode reads the base32 groups of earlier paper backups
+func parseBase32PaperCode(code string) ([]byte, error) {
+	code = strings.ToUpper(strings.Join(strings.Fields(code), ""))
+	data, err := paperEncoding.DecodeString(code)
+	if err != nil || len(data) != ed25519.SeedSize+2 {
+		return nil, fmt.Errorf("❌ That is not a complete paper code; it has 24 words")
+	}
+	seed := data[:ed25519.SeedSize]
+	sum := sha256.Sum256(seed)
+	if !bytes.Equal(sum[:2], data[ed25519.SeedSize:]) {
+		return nil, fmt.Errorf("❌ The paper code checksum does not match; check for a mistyped group")
+	}
+	return seed, nil
+}
+
+// writeKeyPair writes a private and public key, refusing to replace a
+// different existing key unless force is set. It reports whether the key
+// was already in place.
+func writeKeyPair(keyPath string, privatePEM, publicKey []byte, force bool) (unchanged bool, err error) {
+	if existing, err := os.ReadFile(keyPath + ".pub"); err == nil {
+		// An empty or truncated .pub file counts as a different key
+		have, want := strings.Fields(string(existing)), strings.Fields(string(publicKey))
+		if len(have) >= 2 && len(want) >= 2 && have[0] == want[0] && have[1] == want[1] {
+			return true, nil
+		}
+		if !force {
+			return false, fmt.Errorf("❌ A different key exists at %s. Use --force to replace it, or --to for another path", contractHomePath(keyPath))
+		}
+	} else if fileExists(keyPath) && !force {
+		return false, fmt.Errorf("❌ A key exists at %s. Use --force to replace it, or --to for another path", contractHomePath(keyPath))
+	}
+
+	if err := makePrivateDir(filepath.Dir(keyPath)); err != nil {
+		return false, err
+	}
+	// The history keeps no copy of private keys, so a replaced one is gone
+	if fileExists(keyPath) {
+		fmt.Printf("⚠️  Replacing the key at %s; the old key is not kept\n", contractHomePath(keyPath))
+	}
+	trackFile(keyPath)
+	trackFile(keyPath + ".pub")
+	if err := writePrivateFile(keyPath, privatePEM); err != nil {
+		return false, fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := os.WriteFile(keyPath+".pub", publicKey, 0644); err != nil {
+		return false, fmt.Errorf("failed to write public key: %w", err)
+	}
+	return false, nil
+}
+
+// adoptRestoredAccount points an account at a restored key, creating the
+// account and its SSH host alias when this machine does not know it yet
+func adoptRestoredAccount(config *Config, restored Account, keyPath string) error {
+	account := config.getAccount(restored.Name)
+	created := account == nil
+	if created {
+		account = &restored
+	}
+	if account.SSHKey == keyPath && !created {
+		return nil
+	}
+	account.SSHKey = keyPath
+	if err := config.addAccount(*account); err != nil {
+		return errSaveConfig(err)
+	}
+	if err := upsertSSHHostBlock(account.GetSSHHost(), account.GenerateSSHConfig()); err != nil {
+		return err
+	}
+	if created {
+		fmt.Printf("👤 Added account '%s' (%s) with SSH host %s\n", account.Name, account.Email, account.GetSSHHost())
+	}
+	return nil
+}
+
+var keyBackupCmd = &cobra.Command{
+	Use:   "backup <account-name>",
+	Short: "Write a passphrase-encrypted backup of an account's SSH key",
+	Long: `Write an account's SSH key pair and account details to a file encrypted with
+a passphrase (age, scrypt). Keep it somewhere other than this machine; 'krakn key
+restore' brings the key and the account back on a new one, so a lost laptop
+does not force rotating the key on every provider.
+
+With --paper an ed25519 key is printed as 24 words (BIP39) to write down or
+print. The words are the key itself: anyone holding the sheet can use the key.
+
+The passphrase is prompted for, or read from KRAKN_BACKUP_PASSPHRASE.
+
+Examples:
+  krakn key backup work                          # Writes krakn-work-<date>.age
+  krakn key backup work -o /media/usb/work.age
+  krakn key backup work --paper`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		paper, _ := cmd.Flags().GetBool("paper")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+		if account.AgentKey {
+			return fmt.Errorf("❌ The private key of '%s' only lives in ssh-agent and cannot be backed up", account.Name)
+		}
+		if account.SSHKey == "" || !fileExists(account.SSHKey) {
+			return fmt.Errorf("❌ Account '%s' has no SSH key to back up", account.Name)
+		}
+		if err := config.revealAccount(account); err != nil {
+			return err
+		}
+
+		privateKey, err := os.ReadFile(account.SSHKey)
+		if err != nil {
+			return fmt.Errorf("failed to read private key: %w", err)
+		}
+		publicKey, err := os.ReadFile(account.SSHKey + ".pub")
+		if err != nil {
+			return fmt.Errorf("failed to read public key: %w", err)
+		}
+
+		if paper {
+			key, err := parsePrivateKeyFile(account.SSHKey)
+			if err != nil {
+				return fmt.Errorf("❌ Cannot read %s: %v", contractHomePath(account.SSHKey), err)
+			}
+			edKey, ok := key.(*ed25519.PrivateKey)
+			if !ok {
+				return fmt.Errorf("❌ Paper backups need an ed25519 key; use the encrypted backup for this key")
+			}
+			keyType, fingerprint, _ := describePublicKey(account.SSHKey + ".pub")
+			fmt.Printf("📄 Paper backup of '%s' (%s, %s %s)\n\n", account.Name, account.Email, keyType, fingerprint)
+			code, err := paperCode(edKey.Seed())
+			if err != nil {
+				return err
+			}
+			fmt.Println(code)
+			fmt.Printf("\n⚠️  Anyone with this code can use the key. Restore with 'krakn key restore --paper %s'\n", account.Name)
+			return nil
+		}
+
+		// Tokens and cached profiles are easy to recreate and have no place in a long-lived backup
+		backupAccount := account.stripSealed()
+		backupAccount.Email = account.Email
+		backupAccount.Token = ""
+		backupAccount.Sealed = nil
+		backupAccount.Profile = nil
+		// Stored as ~/... so the backup restores under another home directory
+		backupAccount.SSHKey = contractHomePath(account.SSHKey)
+		data, err := json.MarshalIndent(keyBackup{
+			Version:    1,
+			Created:    time.Now(),
+			Account:    backupAccount,
+			PrivateKey: string(privateKey),
+			PublicKey:  string(publicKey),
+		}, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		passphrase, err := backupPassphrase(true)
+		if err != nil {
+			return err
+		}
+		recipient, err := age.NewScryptRecipient(passphrase)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		armored := armor.NewWriter(&buf)
+		w, err := age.Encrypt(armored, recipient)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt backup: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		if err := armored.Close(); err != nil {
+			return err
+		}
+
+		if output == "" {
+			output = fmt.Sprintf("krakn-%s-%s.age", account.Name, time.Now().Format("2006-01-02"))
+		}
+		output = expandUserPath(output)
+		if err := writePrivateFile(output, buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to write backup: %w", err)
+		}
+		fmt.Printf("✅ Backed up the key of '%s' to %s\n", account.Name, output)
+		fmt.Println("💡 Store it off this machine and keep the passphrase somewhere else")
+		return nil
+	},
+}
+
+var keyRestoreCmd = &cobra.Command{
+	Use:   "restore <backup-file | --paper account-name>",
+	Short: "Restore an SSH key from a backup",
+	Long: `Restore an SSH key from a file written by 'krakn key backup'. The account is
+added back as well when this machine does not know it yet, including its SSH
+host alias.
+
+With --paper, type in the words printed by 'krakn key backup --paper' to
+recreate an ed25519 key for an existing account. The restored key has no
+passphrase; add one with 'ssh-keygen -p -f <key>'.
+
+Examples:
+  krakn key restore krakn-work-2025-01-01.age
+  krakn key restore krakn-work-2025-01-01.age --to ~/.ssh/id_work_restored
+  krakn key restore --paper work`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		to, _ := cmd.Flags().GetString("to")
+		force, _ := cmd.Flags().GetBool("force")
+		paper, _ := cmd.Flags().GetBool("paper")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		if paper {
+			account := config.getAccount(args[0])
+			if account == nil {
+				fmt.Println("💡 Paper codes only hold the key; add the account with 'krakn add' first")
+				return config.accountNotFound(args[0])
+			}
+			if err := config.revealAccount(account); err != nil {
+				return err
+			}
+			fmt.Println("⌨️  Type the 24 words (numbers and line breaks do not matter), then an empty line:")
+			var code strings.Builder
+			scanner := bufio.NewScanner(os.Stdin)
+			for scanner.Scan() && strings.TrimSpace(scanner.Text()) != "" {
+				code.WriteString(scanner.Text() + " ")
+			}
+			seed, err := parsePaperCode(code.String())
+			if err != nil {
+				return err
+			}
+
+			privateKey := ed25519.NewKeyFromSeed(seed)
+			block, err := ssh.MarshalPrivateKey(privateKey, account.Email)
+			if err != nil {
+				return fmt.Errorf("failed to encode private key: %w", err)
+			}
+			sshPublicKey, err := ssh.NewPublicKey(privateKey.Public())
+			if err != nil {
+				return fmt.Errorf("failed to encode public key: %w", err)
+			}
+			publicKey := append(bytes.TrimSuffix(ssh.MarshalAuthorizedKey(sshPublicKey), []byte("\n")), []byte(" "+account.Email+"\n")...)
+
+			keyPath := account.SSHKey
+			if to != "" {
+				keyPath, _ = filepath.Abs(expandUserPath(to))
+			}
+			if keyPath == "" {
+				return fmt.Errorf("❌ Account '%s' has no key path; give one with --to", account.Name)
+			}
+			unchanged, err := writeKeyPair(keyPath, pem.EncodeToMemory(block), publicKey, force)
+			if err != nil {
+				return err
+			}
+			if !unchanged {
+				if err := adoptRestoredAccount(config, *account, keyPath); err != nil {
+					return err
+				}
+			}
+			fmt.Printf("✅ Restored the key of '%s' to %s\n", account.Name, contractHomePath(keyPath))
+			fmt.Printf("💡 Protect it with a passphrase: ssh-keygen -p -f %s\n", contractHomePath(keyPath))
+			return nil
+		}
+
+		armored, err := os.ReadFile(expandUserPath(args[0]))
+		if err != nil {
+			return fmt.Errorf("❌ Cannot read %s: %v", args[0], err)
+		}
+		passphrase, err := backupPassphrase(false)
+		if err != nil {
+			return err
+		}
+		identity, err := age.NewScryptIdentity(passphrase)
+		if err != nil {
+			return err
+		}
+		r, err := age.Decrypt(armor.NewReader(bytes.NewReader(armored)), identity)
+		if err != nil {
+			return fmt.Errorf("❌ Cannot decrypt %s: wrong passphrase or not a krakn backup", args[0])
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("failed to read backup: %w", err)
+		}
+		var backup keyBackup
+		if err := json.Unmarshal(data, &backup); err != nil || backup.Version != 1 || backup.Account.Name == "" {
+			return fmt.Errorf("❌ %s is not a krakn key backup", args[0])
+		}
+
+		backup.Account.SSHKey = expandUserPath(backup.Account.SSHKey)
+		keyPath := backup.Account.SSHKey
+		if existing := config.getAccount(backup.Account.Name); existing != nil && existing.SSHKey != "" {
+			keyPath = existing.SSHKey
+		}
+		if to != "" {
+			keyPath, _ = filepath.Abs(expandUserPath(to))
+		}
+		if err := ensureSSHDirectory(); err != nil {
+			return err
+		}
+		unchanged, err := writeKeyPair(keyPath, []byte(backup.PrivateKey), []byte(backup.PublicKey), force)
+		if err != nil {
+			return err
+		}
+		if unchanged {
+			fmt.Printf("ℹ️  The key of '%s' is already in place at %s\n", backup.Account.Name, contractHomePath(keyPath))
+		} else {
+			fmt.Printf("✅ Restored the key of '%s' (backed up %s) to %s\n", backup.Account.Name, backup.Created.Format("2006-01-02"), contractHomePath(keyPath))
+		}
+		return adoptRestoredAccount(config, backup.Account, keyPath)
+	},
+}
+
+func init() {
+	keyBackupCmd.Flags().StringP("output", "o", "", "Backup file (default krakn-<account>-<date>.age)")
+	keyBackupCmd.Flags().Bool("paper", false, "Print an ed25519 key as 24 words to write down instead")
+	keyRestoreCmd.Flags().String("to", "", "Write the key to this path instead of the account's key path")
+	keyRestoreCmd.Flags().Bool("force", false, "Replace a different key at the target path")
+	keyRestoreCmd.Flags().Bool("paper", false, "Restore from a paper code for the named account")
+	keyCmd.AddCommand(keyBackupCmd)
+	keyCmd.AddCommand(keyRestoreCmd)
+}
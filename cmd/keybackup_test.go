@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"strings"
+	"testing"
+)
+
+func TestPaperCodeRoundTrip(t *testing.T) {
+	seed := sha256.Sum256([]byte("krakn paper backup"))
+	code, err := paperCode(seed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if words := strings.Fields(code); len(words) != 48 {
+		t.Fatalf("paper code has %d fields, want 24 numbered words:\n%s", len(words), code)
+	}
+	got, err := parsePaperCode(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, seed[:]) {
+		t.Errorf("parsePaperCode returned another seed")
+	}
+
+	// Words without numbers, in upper case, work as well
+	var words []string
+	for _, field := range strings.Fields(code) {
+		if strings.Trim(field, "0123456789") != "" {
+			words = append(words, strings.ToUpper(field))
+		}
+	}
+	if got, err := parsePaperCode(strings.Join(words, " ")); err != nil || !bytes.Equal(got, seed[:]) {
+		t.Errorf("parsePaperCode without numbers: %v", err)
+	}
+
+	// Swapping two words breaks the checksum
+	words[0], words[1] = words[1], words[0]
+	if _, err := parsePaperCode(strings.Join(words, " ")); err == nil && words[0] != words[1] {
+		t.Errorf("parsePaperCode accepted swapped words")
+	}
+}
+
+func TestParseBase32PaperCode(t *testing.T) {
+	seed := sha256.Sum256([]byte("krakn paper backup"))
+	sum := sha256.Sum256(seed[:])
+	code := paperEncoding.EncodeToString(append(seed[:], sum[:2]...))
+	got, err := parsePaperCode(strings.ToLower(code[:8]) + " " + code[8:])
+	if err != nil || !bytes.Equal(got, seed[:]) {
+		t.Errorf("parsePaperCode(base32) = %x, %v", got, err)
+	}
+}
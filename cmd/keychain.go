@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+// keychainService names krakncat's entries in the system credential store
+const keychainService = "krakncat"
+
+// errSecretNotFound is returned by a secretStore without an entry for the key
+var errSecretNotFound = errors.New("no such secret in the keychain")
+
+// secretStore keeps secrets in the operating system's credential store:
+// macOS Keychain, Windows Credential Manager or the Secret Service (libsecret)
+// on Linux. Entries are addressed by krakncat's service name and a key such as
+// "ssh-key:/home/me/.ssh/id_ed25519_gh_work".
+type secretStore interface {
+	Name() string
+	Get(key string) (string, error)
+	Set(key, label, secret string) error
+	Delete(key string) error
+}
+
+// systemSecretStore returns the credential store of this machine, or nil
+// when none is available (e.g. a headless Linux without secret-tool)
+func systemSecretStore() secretStore {
+	return platformSecretStore()
+}
+
+// tokenStoreKeychain is the TokenStore of accounts whose API token lives in
+// the system keychain
+const tokenStoreKeychain = "keychain"
+
+// tokenKeychainEntry is the keychain entry holding an account's API token
+func tokenKeychainEntry(name string) string {
+	return "token:" + name
+}
+
+// revealKeychainToken reads the token of an account that keeps it in the
+// keychain. Without a keychain or entry the account simply has no token.
+func revealKeychainToken(account *Account) error {
+	if account.TokenStore != tokenStoreKeychain || account.Token != "" {
+		return nil
+	}
+	store := systemSecretStore()
+	if store == nil {
+		tracef(traceRead, "No system keychain for the token of '%s'", account.Name)
+		return nil
+	}
+	token, err := store.Get(tokenKeychainEntry(account.Name))
+	if errors.Is(err, errSecretNotFound) {
+		tracef(traceRead, "%s holds no token for '%s'", store.Name(), account.Name)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("❌ Could not read the token of '%s' from %s: %w", account.Name, store.Name(), err)
+	}
+	account.Token = token
+	return nil
+}
+
+// keyPassphraseEntry is the keychain entry holding the passphrase of an SSH key
+func keyPassphraseEntry(keyPath string) string {
+	if abs, err := filepath.Abs(keyPath); err == nil {
+		keyPath = abs
+	}
+	return "ssh-key:" + keyPath
+}
+
+// storedKeyPassphrase returns the passphrase of an SSH key kept in the
+// keychain, and the name of the store it came from
+func storedKeyPassphrase(keyPath string) (string, string, bool) {
+	store := systemSecretStore()
+	if store == nil {
+		return "", "", false
+	}
+	passphrase, err := store.Get(keyPassphraseEntry(keyPath))
+	if err != nil || passphrase == "" {
+		return "", "", false
+	}
+	return passphrase, store.Name(), true
+}
+
+// offerRememberPassphrase asks whether to keep a key's passphrase in the
+// keychain, so 'krakn use' and 'krakn test' load the key without asking
+func offerRememberPassphrase(store secretStore, keyPath, passphrase string) {
+	fmt.Printf("💾 Remember the passphrase in %s so krakn loads the key into ssh-agent without asking? [y/N]: ", store.Name())
+	resp, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if resp = strings.ToLower(strings.TrimSpace(resp)); resp != "y" && resp != "yes" {
+		return
+	}
+	if err := store.Set(keyPassphraseEntry(keyPath), "krakncat: passphrase of "+contractHomePath(keyPath), passphrase); err != nil {
+		fmt.Printf("⚠️  Could not save the passphrase: %v\n", err)
+		return
+	}
+	fmt.Printf("🔐 Saved the passphrase of %s in %s\n", contractHomePath(keyPath), store.Name())
+}
+
+var keyPassphraseCmd = &cobra.Command{
+	Use:   "passphrase <account-name>",
+	Short: "Keep an SSH key's passphrase in the system keychain",
+	Long: `Save the passphrase of an account's SSH key in macOS Keychain, Windows
+Credential Manager or the Secret Service (GNOME Keyring, KWallet, KeePassXC
+through libsecret's secret-tool). 'krakn use' and 'krakn test' then load the
+key into ssh-agent without prompting. The passphrase is checked against the
+key before it is saved.
+
+Set KRAKN_KEY_PASSPHRASE to save without a prompt, e.g. from a script.
+
+Examples:
+  krakn key passphrase work
+  krakn key passphrase work --forget`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		forget, _ := cmd.Flags().GetBool("forget")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+		if account.SSHKey == "" || account.AgentKey {
+			return fmt.Errorf("❌ Account '%s' has no SSH key file", account.Name)
+		}
+		store := systemSecretStore()
+		if store == nil {
+			return fmt.Errorf("❌ No system keychain found; on Linux install secret-tool (libsecret-tools)")
+		}
+		entry := keyPassphraseEntry(account.SSHKey)
+
+		if forget {
+			if err := store.Delete(entry); errors.Is(err, errSecretNotFound) {
+				fmt.Printf("ℹ️  %s holds no passphrase for %s\n", store.Name(), contractHomePath(account.SSHKey))
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("❌ Could not remove the passphrase: %w", err)
+			}
+			fmt.Printf("🗑️  Removed the passphrase of %s from %s\n", contractHomePath(account.SSHKey), store.Name())
+			return nil
+		}
+
+		if !keyIsEncrypted(account.SSHKey) {
+			return fmt.Errorf("❌ %s has no passphrase", contractHomePath(account.SSHKey))
+		}
+		passphrase := os.Getenv("KRAKN_KEY_PASSPHRASE")
+		if passphrase == "" {
+			if passphrase, err = readSecret(fmt.Sprintf("🔐 Passphrase for %s: ", contractHomePath(account.SSHKey))); err != nil {
+				return err
+			}
+		}
+		data, err := os.ReadFile(account.SSHKey)
+		if err != nil {
+			return fmt.Errorf("❌ Cannot read %s: %v", contractHomePath(account.SSHKey), err)
+		}
+		if _, err := ssh.ParseRawPrivateKeyWithPassphrase(data, []byte(passphrase)); err != nil {
+			return fmt.Errorf("❌ The passphrase does not open %s", contractHomePath(account.SSHKey))
+		}
+		if err := store.Set(entry, "krakncat: passphrase of "+contractHomePath(account.SSHKey), passphrase); err != nil {
+			return fmt.Errorf("❌ Could not save the passphrase: %w", err)
+		}
+		fmt.Printf("🔐 Saved the passphrase of %s in %s\n", contractHomePath(account.SSHKey), store.Name())
+		return nil
+	},
+}
+
+func init() {
+	keyPassphraseCmd.Flags().Bool("forget", false, "Remove the saved passphrase")
+	keyCmd.AddCommand(keyPassphraseCmd)
+}
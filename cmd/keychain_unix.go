@@ -0,0 +1,98 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// platformSecretStore uses the security tool on macOS and secret-tool
+// (libsecret) elsewhere
+func platformSecretStore() secretStore {
+	if runtime.GOOS == "darwin" {
+		if _, err := exec.LookPath("security"); err == nil {
+			return macKeychain{}
+		}
+		return nil
+	}
+	if _, err := exec.LookPath("secret-tool"); err == nil {
+		return secretService{}
+	}
+	return nil
+}
+
+// macKeychain stores generic passwords in the login keychain
+type macKeychain struct{}
+
+func (macKeychain) Name() string { return "macOS Keychain" }
+
+func (macKeychain) Get(key string) (string, error) {
+	output, err := traceExec(exec.Command("security", "find-generic-password", "-s", keychainService, "-a", key, "-w")).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+		return "", errSecretNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("security find-generic-password failed: %w", err)
+	}
+	return strings.TrimSuffix(string(output), "\n"), nil
+}
+
+// Set runs security in interactive mode, so the secret is passed on stdin
+// instead of a command line other users can see
+func (macKeychain) Set(key, label, secret string) error {
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+	cmd := traceExec(exec.Command("security", "-i"))
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -l %s -w %s\n",
+		quote(keychainService), quote(key), quote(label), quote(secret)))
+	if output, err := cmd.CombinedOutput(); err != nil || strings.Contains(string(output), "error") {
+		return fmt.Errorf("security add-generic-password failed: %s", firstLine(strings.TrimSpace(string(output))))
+	}
+	return nil
+}
+
+func (macKeychain) Delete(key string) error {
+	err := traceExec(exec.Command("security", "delete-generic-password", "-s", keychainService, "-a", key)).Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+		return errSecretNotFound
+	}
+	return err
+}
+
+// secretService stores secrets through libsecret's secret-tool, which reaches
+// GNOME Keyring, KWallet and KeePassXC alike
+type secretService struct{}
+
+func (secretService) Name() string { return "Secret Service" }
+
+func (secretService) Get(key string) (string, error) {
+	output, err := traceExec(exec.Command("secret-tool", "lookup", "service", keychainService, "account", key)).Output()
+	if err != nil || len(output) == 0 {
+		// secret-tool exits with 1 both for a missing entry and a locked collection
+		return "", errSecretNotFound
+	}
+	return strings.TrimSuffix(string(output), "\n"), nil
+}
+
+func (secretService) Set(key, label, secret string) error {
+	cmd := traceExec(exec.Command("secret-tool", "store", "--label="+label, "service", keychainService, "account", key))
+	cmd.Stdin = strings.NewReader(secret)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool store failed: %s", firstLine(strings.TrimSpace(string(output))))
+	}
+	return nil
+}
+
+func (secretService) Delete(key string) error {
+	if _, err := (secretService{}).Get(key); err != nil {
+		return err
+	}
+	return traceExec(exec.Command("secret-tool", "clear", "service", keychainService, "account", key)).Run()
+}
@@ -0,0 +1,116 @@
+//go:build windows
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	advapi32       = windows.NewLazySystemDLL("advapi32.dll")
+	procCredReadW  = advapi32.NewProc("CredReadW")
+	procCredWriteW = advapi32.NewProc("CredWriteW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+)
+
+// winCredential mirrors the CREDENTIALW structure
+type winCredential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// platformSecretStore uses Windows Credential Manager
+func platformSecretStore() secretStore {
+	return credentialManager{}
+}
+
+// credentialManager stores generic credentials named krakncat:<key>
+type credentialManager struct{}
+
+func (credentialManager) Name() string { return "Windows Credential Manager" }
+
+func credentialTarget(key string) (*uint16, error) {
+	return windows.UTF16PtrFromString(keychainService + ":" + key)
+}
+
+func (credentialManager) Get(key string) (string, error) {
+	target, err := credentialTarget(key)
+	if err != nil {
+		return "", err
+	}
+	var cred *winCredential
+	ret, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if ret == 0 {
+		if errors.Is(err, windows.ERROR_NOT_FOUND) {
+			return "", errSecretNotFound
+		}
+		return "", fmt.Errorf("CredRead failed: %w", err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+func (credentialManager) Set(key, label, secret string) error {
+	target, err := credentialTarget(key)
+	if err != nil {
+		return err
+	}
+	comment, err := windows.UTF16PtrFromString(label)
+	if err != nil {
+		return err
+	}
+	user, err := windows.UTF16PtrFromString(key)
+	if err != nil {
+		return err
+	}
+	blob := []byte(secret)
+	cred := winCredential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		Comment:            comment,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+	if ret, _, err := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0); ret == 0 {
+		return fmt.Errorf("CredWrite failed: %w", err)
+	}
+	return nil
+}
+
+func (credentialManager) Delete(key string) error {
+	target, err := credentialTarget(key)
+	if err != nil {
+		return err
+	}
+	if ret, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0); ret == 0 {
+		if errors.Is(err, windows.ERROR_NOT_FOUND) {
+			return errSecretNotFound
+		}
+		return fmt.Errorf("CredDelete failed: %w", err)
+	}
+	return nil
+}
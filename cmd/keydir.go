@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultKeyPath returns where a new key for an account is created: keyDir,
+// or ~/.ssh when the account has none
+func defaultKeyPath(keyDir, suffix, name string) string {
+	if keyDir == "" {
+		homeDir, _ := os.UserHomeDir()
+		keyDir = filepath.Join(homeDir, ".ssh")
+	}
+	return filepath.Join(keyDir, fmt.Sprintf("id_ed25519_%s_%s", suffix, name))
+}
+
+// GetKeyPath returns the account's SSH key, or where a new one is created
+func (a *Account) GetKeyPath() string {
+	if a.SSHKey != "" {
+		return a.SSHKey
+	}
+	return defaultKeyPath(a.KeyDir, a.GetProvider().KeySuffix, a.Name)
+}
+
+// insideDir reports whether path lies below dir
+func insideDir(path, dir string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..")
+}
+
+// checkKeyDir verifies that an account's key directory is usable before its
+// key is relied on: the directory is there (its volume is mounted), private,
+// and holds the key
+func (a *Account) checkKeyDir() error {
+	if a.KeyDir == "" || a.usesHTTPS() {
+		return nil
+	}
+	dir := contractHomePath(a.KeyDir)
+	info, err := os.Stat(a.KeyDir)
+	switch {
+	case os.IsNotExist(err):
+		return newError(codeKeyDirUnusable, "Mount the volume holding "+dir, "Key directory %s of account '%s' is not available; is its volume mounted?", dir, a.Name)
+	case err != nil:
+		return &kraknError{Code: codeKeyDirUnusable, Cause: fmt.Sprintf("Key directory %s of account '%s'", dir, a.Name), Err: err}
+	case !info.IsDir():
+		return newError(codeKeyDirUnusable, "krakn key dir "+a.Name+" <directory>", "Key directory %s of account '%s' is not a directory", dir, a.Name)
+	case runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0:
+		return newError(codeKeyDirUnusable, "chmod 700 "+dir, "Key directory %s of account '%s' is accessible by other users (%o)", dir, a.Name, info.Mode().Perm())
+	}
+	if owner, foreign := pathOwner(a.KeyDir); foreign {
+		return newError(codeKeyDirUnusable, "", "Key directory %s of account '%s' is owned by %s", dir, a.Name, owner)
+	}
+	if a.SSHKey == "" {
+		return nil
+	}
+	if !insideDir(a.SSHKey, a.KeyDir) {
+		return newError(codeKeyDirUnusable, fmt.Sprintf("krakn key dir %s %s --move", a.Name, dir), "SSH key %s of account '%s' is outside its key directory %s", contractHomePath(a.SSHKey), a.Name, dir)
+	}
+	if !fileExists(a.SSHKey) {
+		return newError(codeKeyDirUnusable, "Mount the volume holding "+dir, "SSH key %s of account '%s' is missing from %s; is its volume mounted?", contractHomePath(a.SSHKey), a.Name, dir)
+	}
+	return nil
+}
+
+// checkKeyDirs reports the key directory of every account that has one
+func checkKeyDirs(ctx *doctorContext) []doctorFinding {
+	var findings []doctorFinding
+	for _, account := range ctx.Config.Accounts {
+		if account.KeyDir == "" || account.usesHTTPS() {
+			continue
+		}
+		if err := account.checkKeyDir(); err != nil {
+			finding := doctorFinding{Level: doctorError, Message: err.Error()}
+			var coded *kraknError
+			if errors.As(err, &coded) {
+				finding.Hint = coded.Try
+			}
+			findings = append(findings, finding)
+			continue
+		}
+		volume := ""
+		if mount, ok := isMountPoint(account.KeyDir); ok && mount {
+			volume = ", a mount point"
+		}
+		findings = append(findings, doctorFinding{
+			Level:   doctorOK,
+			Message: fmt.Sprintf("Account '%s': keys in %s (private%s)", account.Name, contractHomePath(account.KeyDir), volume),
+		})
+	}
+	return findings
+}
+
+// moveKeyIntoDir moves an account's key pair into dir and points the account
+// and its Host block at the new path
+func moveKeyIntoDir(config *Config, account *Account, dir string) error {
+	newPath := filepath.Join(dir, filepath.Base(account.SSHKey))
+	if fileExists(newPath) {
+		return fmt.Errorf("❌ %s already exists", contractHomePath(newPath))
+	}
+	for _, suffix := range []string{"", ".pub"} {
+		if !fileExists(account.SSHKey + suffix) {
+			continue
+		}
+		trackFile(account.SSHKey + suffix)
+		trackFile(newPath + suffix)
+		if err := os.Rename(account.SSHKey+suffix, newPath+suffix); err != nil {
+			// Renaming fails across volumes, which is the usual case here
+			data, readErr := os.ReadFile(account.SSHKey + suffix)
+			if readErr != nil {
+				return fmt.Errorf("failed to move %s: %w", contractHomePath(account.SSHKey+suffix), err)
+			}
+			mode := os.FileMode(0600)
+			if suffix == ".pub" {
+				mode = 0644
+			}
+			if err := writeFileAtomic(newPath+suffix, data, mode); err != nil {
+				return fmt.Errorf("failed to move %s: %w", contractHomePath(account.SSHKey+suffix), err)
+			}
+			if suffix == "" {
+				if err := restrictToOwner(newPath); err != nil {
+					return fmt.Errorf("failed to restrict access to %s: %w", contractHomePath(newPath), err)
+				}
+			}
+			if err := os.Remove(account.SSHKey + suffix); err != nil {
+				return fmt.Errorf("failed to remove %s after copying it: %w", contractHomePath(account.SSHKey+suffix), err)
+			}
+		}
+	}
+	fmt.Printf("📦 Moved %s to %s\n", contractHomePath(account.SSHKey), contractHomePath(newPath))
+	account.SSHKey = newPath
+	if err := config.addAccount(*account); err != nil {
+		return errSaveConfig(err)
+	}
+	if account.SSHHost == "" {
+		if err := upsertSSHHostBlock(account.GetSSHHost(), account.GenerateSSHConfig()); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("💡 Update IdentityFile in your own Host %s block to %s\n", account.SSHHost, contractHomePath(newPath))
+	}
+	return nil
+}
+
+var keyDirCmd = &cobra.Command{
+	Use:   "dir <account-name> [directory]",
+	Short: "Keep an account's SSH keys in a directory of their own",
+	Long: `Set the directory an account's SSH keys live in, e.g. a directory on an
+encrypted volume managed by your employer. Without a directory the current
+setting is shown.
+
+New keys for the account ('krakn key generate', 'krakn add') are created in the
+directory. Before 'krakn use' and 'krakn global' switch to the account they
+check that the directory is there (its volume is mounted), is private (0700)
+and holds the key, and refuse to switch otherwise; 'krakn doctor' reports the
+same. Use --move to move an existing key pair into the directory.
+
+Examples:
+  krakn key dir work /Volumes/Corp/ssh          # Keys of 'work' live on the corporate volume
+  krakn key dir work /Volumes/Corp/ssh --move   # ...and move the current key there
+  krakn key dir work                            # Show the setting
+  krakn key dir work --unset                    # Back to ~/.ssh for new keys`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		unset, _ := cmd.Flags().GetBool("unset")
+		move, _ := cmd.Flags().GetBool("move")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+
+		switch {
+		case unset:
+			account.KeyDir = ""
+			if err := config.addAccount(*account); err != nil {
+				return errSaveConfig(err)
+			}
+			fmt.Printf("✅ New keys for '%s' are created in ~/.ssh again\n", account.Name)
+			return nil
+		case len(args) == 1:
+			if account.KeyDir == "" {
+				fmt.Printf("🔑 Account '%s' has no key directory; new keys go to ~/.ssh\n", account.Name)
+				return nil
+			}
+			fmt.Printf("🔑 Keys of '%s' live in %s\n", account.Name, contractHomePath(account.KeyDir))
+			if err := account.checkKeyDir(); err != nil {
+				return err
+			}
+			fmt.Println("✅ Available, private and holding the key")
+			return nil
+		}
+
+		dir, err := filepath.Abs(expandUserPath(args[1]))
+		if err != nil {
+			return fmt.Errorf("failed to resolve directory path: %w", err)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return fmt.Errorf("❌ Directory not found: %s. Mount the volume and create it first", dir)
+		}
+		account.KeyDir = dir
+		if err := config.addAccount(*account); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("✅ Keys of '%s' live in %s\n", account.Name, contractHomePath(dir))
+
+		if account.SSHKey != "" && !insideDir(account.SSHKey, dir) {
+			if !move {
+				fmt.Printf("⚠️  The current key %s is outside the directory; 'krakn use %s' refuses it until it is moved\n", contractHomePath(account.SSHKey), account.Name)
+				fmt.Printf("💡 krakn key dir %s %s --move\n", account.Name, contractHomePath(dir))
+				return nil
+			}
+			if err := moveKeyIntoDir(config, account, dir); err != nil {
+				return err
+			}
+		}
+		if err := account.checkKeyDir(); err != nil {
+			fmt.Printf("⚠️  %s\n", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	keyDirCmd.Flags().Bool("unset", false, "Create new keys in ~/.ssh again")
+	keyDirCmd.Flags().Bool("move", false, "Move the account's current key pair into the directory")
+	keyCmd.AddCommand(keyDirCmd)
+	registerDoctorCheck(doctorCheck{Name: "Key directories", Run: checkKeyDirs})
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshKeygenAvailable reports whether the OpenSSH ssh-keygen binary can be used
+func sshKeygenAvailable() bool {
+	_, err := exec.LookPath("ssh-keygen")
+	return err == nil
+}
+
+// generateNativeEd25519Key writes an ed25519 key pair in the same OpenSSH
+// format ssh-keygen produces, encrypted when a passphrase is given. Used when
+// ssh-keygen is not installed or a passphrase must stay off its command line.
+func generateNativeEd25519Key(keyPath, comment, passphrase string) error {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate ed25519 key: %w", err)
+	}
+
+	var block *pem.Block
+	if passphrase != "" {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(privateKey, comment, []byte(passphrase))
+	} else {
+		block, err = ssh.MarshalPrivateKey(privateKey, comment)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode private key: %w", err)
+	}
+
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to encode public key: %w", err)
+	}
+	authorizedKey := ssh.MarshalAuthorizedKey(sshPublicKey)
+	if comment != "" {
+		// MarshalAuthorizedKey ends with a newline; the comment goes before it
+		authorizedKey = append(authorizedKey[:len(authorizedKey)-1], []byte(" "+comment+"\n")...)
+	}
+
+	if err := writePrivateFile(keyPath, pem.EncodeToMemory(block)); err != nil {
+		return fmt.Errorf("failed to write private key: %w", err)
+	}
+	if err := os.WriteFile(keyPath+".pub", authorizedKey, 0644); err != nil {
+		return fmt.Errorf("failed to write public key: %w", err)
+	}
+	return nil
+}
+
+// newKeyPassphrase returns the passphrase for a new SSH key from
+// KRAKN_KEY_PASSPHRASE or a prompt. Unless required, an empty answer means an
+// unencrypted key.
+func newKeyPassphrase(required bool) (string, error) {
+	if env := os.Getenv("KRAKN_KEY_PASSPHRASE"); env != "" {
+		return env, nil
+	}
+	prompt := "🔐 Passphrase for the new key: "
+	if !required {
+		prompt = "🔐 Passphrase for the new key (empty for none): "
+	}
+	passphrase, err := readSecret(prompt)
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		if required {
+			return "", fmt.Errorf("❌ The passphrase cannot be empty")
+		}
+		return "", nil
+	}
+	again, err := readSecret("🔐 Confirm passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if again != passphrase {
+		return "", fmt.Errorf("❌ Passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// describePublicKey returns the type and SHA256 fingerprint of a public key file
+func describePublicKey(pubPath string) (keyType, fingerprint string, err error) {
+	data, err := os.ReadFile(pubPath)
+	if err != nil {
+		return "", "", err
+	}
+	key, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return "", "", err
+	}
+	return key.Type(), ssh.FingerprintSHA256(key), nil
+}
+
+// keyFingerprint returns the type and SHA256 fingerprint of the public key
+// next to keyPath, the way provider key settings show it, or "" when it cannot
+// be read
+func keyFingerprint(keyPath string) string {
+	keyType, fingerprint, err := describePublicKey(keyPath + ".pub")
+	if err != nil {
+		return ""
+	}
+	return keyType + " " + fingerprint
+}
+
+// minRSAKeyBits is the smallest RSA key size krakn considers acceptable
+const minRSAKeyBits = 2048
+
+// weakKeyReason explains why a public key is considered weak, or returns ""
+func weakKeyReason(key ssh.PublicKey) string {
+	switch key.Type() {
+	case ssh.KeyAlgoDSA:
+		return "DSA keys are deprecated and disabled in OpenSSH"
+	case ssh.KeyAlgoRSA:
+		if cryptoKey, ok := key.(ssh.CryptoPublicKey); ok {
+			if rsaKey, ok := cryptoKey.CryptoPublicKey().(*rsa.PublicKey); ok && rsaKey.N.BitLen() < minRSAKeyBits {
+				return fmt.Sprintf("RSA key has %d bits (minimum %d)", rsaKey.N.BitLen(), minRSAKeyBits)
+			}
+		}
+	}
+	return ""
+}
+
+var keyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the SSH keys used by accounts",
+	Long: `List each account's SSH key with its type and SHA256 fingerprint.
+
+Examples:
+  krakn key list`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		if len(config.Accounts) == 0 {
+			fmt.Println("📭 No accounts configured. Use 'krakn add' to add an account.")
+			return nil
+		}
+
+		fmt.Println("🔑 SSH keys:")
+		for _, account := range config.Accounts {
+			fmt.Printf("\n  %s\n", account.Name)
+			if account.SSHKey == "" {
+				fmt.Println("     ⚠️  No key configured")
+				continue
+			}
+			fmt.Printf("     📄 %s\n", contractHomePath(account.identityFile()))
+			if account.AgentKey {
+				fmt.Println("     🔒 Private key in ssh-agent only")
+			} else if !fileExists(account.SSHKey) {
+				fmt.Println("     ❌ Private key missing")
+				continue
+			}
+			keyType, fingerprint, err := describePublicKey(account.SSHKey + ".pub")
+			if err != nil {
+				fmt.Printf("     ⚠️  Cannot read public key: %v\n", err)
+				continue
+			}
+			fmt.Printf("     🔐 %s %s\n", keyType, fingerprint)
+		}
+		return nil
+	},
+}
+
+// checkTools reports which external programs krakncat relies on are missing
+func checkTools(ctx *doctorContext) []doctorFinding {
+	var findings []doctorFinding
+	if _, err := exec.LookPath("git"); err != nil {
+		findings = append(findings, doctorFinding{Level: doctorError, Message: "git not found in PATH"})
+	}
+	if _, err := exec.LookPath("ssh"); err != nil {
+		findings = append(findings, doctorFinding{Level: doctorWarn, Message: "ssh not found in PATH; git cannot use SSH remotes"})
+	}
+	if !sshKeygenAvailable() {
+		findings = append(findings, doctorFinding{
+			Level:   doctorInfo,
+			Message: "ssh-keygen not found; new keys are generated natively (ed25519, no passphrase)",
+			Hint:    "Install the OpenSSH client (e.g. apt install openssh-client) for passphrase-protected keys",
+		})
+	}
+	return findings
+}
+
+func init() {
+	registerDoctorCheck(doctorCheck{Name: "Tools", Run: checkTools})
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// authorizedKeysScript appends a public key to ~/.ssh/authorized_keys unless
+// the key (type and blob, whatever the comment) is already there. It prints
+// "present" or "added" so the caller can tell which happened.
+func authorizedKeysScript(publicKey string) string {
+	fields := strings.Fields(publicKey)
+	match := strings.Join(fields[:2], " ")
+	return fmt.Sprintf(`umask 077; mkdir -p ~/.ssh && touch ~/.ssh/authorized_keys && `+
+		`if grep -qF %s ~/.ssh/authorized_keys; then echo present; `+
+		`else printf '%%s\n' %s >> ~/.ssh/authorized_keys && echo added; fi`,
+		shellQuote(match), shellQuote(publicKey))
+}
+
+var keyInstallCmd = &cobra.Command{
+	Use:   "install <account-name> <[user@]server>",
+	Short: "Add an account's public key to a server's authorized_keys",
+	Long: `Append an account's public key to ~/.ssh/authorized_keys on a server, like
+ssh-copy-id. Useful when the per-account key is also used to reach deployment
+servers. The key is only added once; running the command again is harmless.
+
+The connection uses your normal ssh setup, so an existing multiplexed
+connection, your agent or a password prompt all work. Use --identity to log
+in with a specific key.
+
+Examples:
+  krakn key install work deploy@app1.example.com
+  krakn key install work admin@10.0.0.5 --port 2222
+  krakn key install work deploy@app1 --dry-run`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountName, target := args[0], args[1]
+		port, _ := cmd.Flags().GetString("port")
+		identity, _ := cmd.Flags().GetString("identity")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(accountName)
+		if account == nil {
+			return config.accountNotFound(accountName)
+		}
+		if account.SSHKey == "" {
+			return newError(codeNoSSHKey, "krakn key generate --name "+accountName+" --email <email>", "Account '%s' has no SSH key", accountName)
+		}
+		data, err := os.ReadFile(account.SSHKey + ".pub")
+		if err != nil {
+			return fmt.Errorf("❌ Cannot read the public key %s.pub: %v", contractHomePath(account.SSHKey), err)
+		}
+		publicKey := strings.TrimSpace(string(data))
+		if len(strings.Fields(publicKey)) < 2 {
+			return fmt.Errorf("❌ %s.pub is not a public key", contractHomePath(account.SSHKey))
+		}
+
+		sshArgs := []string{}
+		if port != "" {
+			sshArgs = append(sshArgs, "-p", port)
+		}
+		if identity != "" {
+			sshArgs = append(sshArgs, "-i", expandUserPath(identity))
+		}
+		sshArgs = append(sshArgs, target, authorizedKeysScript(publicKey))
+
+		keyType, fingerprint, _ := describePublicKey(account.SSHKey + ".pub")
+		fmt.Printf("🔑 %s key of '%s' (%s %s)\n", contractHomePath(account.SSHKey)+".pub", account.Name, keyType, fingerprint)
+		if dryRun {
+			fmt.Println("🔍 Would run:")
+			fmt.Printf("   ssh %s\n", strings.Join(sshArgs[:len(sshArgs)-1], " ")+" "+shellQuote(sshArgs[len(sshArgs)-1]))
+			return nil
+		}
+
+		fmt.Printf("🌐 Connecting to %s...\n", target)
+		ssh := traceExec(exec.Command("ssh", sshArgs...))
+		ssh.Stdin = os.Stdin
+		ssh.Stderr = os.Stderr
+		output, err := ssh.Output()
+		if err != nil {
+			return fmt.Errorf("❌ Could not install the key on %s: %v", target, err)
+		}
+
+		if strings.TrimSpace(string(output)) == "present" {
+			fmt.Printf("ℹ️  The key is already authorized on %s\n", target)
+			return nil
+		}
+		fmt.Printf("✅ Added the key of '%s' to %s:~/.ssh/authorized_keys\n", account.Name, target)
+		hostArgs := ""
+		if port != "" {
+			hostArgs = " -p " + port
+		}
+		fmt.Printf("💡 Log in with: ssh -i %s%s %s\n", contractHomePath(account.SSHKey), hostArgs, target)
+		return nil
+	},
+}
+
+func init() {
+	keyInstallCmd.Flags().StringP("port", "p", "", "SSH port of the server")
+	keyInstallCmd.Flags().StringP("identity", "i", "", "Key to log in with (default: your ssh configuration)")
+	keyInstallCmd.Flags().Bool("dry-run", false, "Show the ssh command without running it")
+	keyCmd.AddCommand(keyInstallCmd)
+}
@@ -0,0 +1,359 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// githubKeyUploadScope is the classic scope a device-flow token is requested with
+const githubKeyUploadScope = "admin:public_key"
+
+// keyUploadSupported reports whether krakn can add keys through the
+// provider's API: GitHub (and GHES), GitLab, Gitea and Forgejo
+func keyUploadSupported(provider Provider) bool {
+	switch provider.Name {
+	case "github", "gitlab", "gitea", "forgejo":
+		return true
+	}
+	return false
+}
+
+// keyUploadTokenEnv is the environment variable a key upload token is read
+// from when the account has no token of its own
+func keyUploadTokenEnv(provider Provider) string {
+	switch provider.Name {
+	case "github":
+		return "KRAKN_GITHUB_TOKEN"
+	case "gitlab":
+		return "KRAKN_GITLAB_TOKEN"
+	}
+	return "KRAKN_GITEA_TOKEN"
+}
+
+// keyTokenPage returns where a token for uploading keys is created, and the
+// scope it needs
+func keyTokenPage(provider Provider) (string, string) {
+	base := "https://" + provider.Hostname
+	switch provider.Name {
+	case "github":
+		return base + "/settings/tokens/new", githubKeyUploadScope
+	case "gitlab":
+		return base + "/-/user_settings/personal_access_tokens", "api"
+	}
+	return base + "/user/settings/applications", "write:user"
+}
+
+// keyUploadToken returns a token for uploading keys to an account's provider:
+// the account's own token, KRAKN_<PROVIDER>_TOKEN, or, interactively, a pasted
+// personal access token or (GitHub only) a device-flow authorization
+func keyUploadToken(config *Config, account *Account, interactive bool) (string, error) {
+	if err := config.revealAccount(account); err != nil {
+		return "", err
+	}
+	if account.Token != "" {
+		return account.Token, nil
+	}
+	provider := account.GetProvider()
+	variable := keyUploadTokenEnv(provider)
+	if token := os.Getenv(variable); token != "" {
+		return token, nil
+	}
+	if !interactive {
+		return "", fmt.Errorf("❌ Uploading a key needs a token: set %s or run 'krakn token set %s'", variable, account.Name)
+	}
+
+	if clientID := os.Getenv("KRAKN_GITHUB_CLIENT_ID"); clientID != "" && provider.Name == "github" {
+		fmt.Print("💬 Authorize in the browser instead of pasting a token? [Y/n]: ")
+		resp, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if resp = strings.ToLower(strings.TrimSpace(resp)); resp == "" || resp == "y" || resp == "yes" {
+			return githubDeviceFlow(provider, clientID, githubKeyUploadScope)
+		}
+	}
+	page, scope := keyTokenPage(provider)
+	fmt.Printf("💡 Create a token with the '%s' scope at %s\n", scope, page)
+	token, err := readSecret("🔑 Personal access token: ")
+	if err != nil {
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+	if token == "" {
+		return "", fmt.Errorf("token cannot be empty")
+	}
+	return token, nil
+}
+
+// githubDeviceFlow authorizes an OAuth app with the device flow: the user
+// enters a code on the provider's website while krakn polls for the token
+func githubDeviceFlow(provider Provider, clientID, scope string) (string, error) {
+	base := "https://" + provider.Hostname
+	httpClient := &http.Client{Timeout: 15 * time.Second}
+	post := func(path string, form url.Values, target interface{}) error {
+		req, err := http.NewRequest(http.MethodPost, base+path, strings.NewReader(form.Encode()))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("User-Agent", "krakn/"+Version)
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s returned %d", path, resp.StatusCode)
+		}
+		return json.NewDecoder(resp.Body).Decode(target)
+	}
+
+	var code struct {
+		DeviceCode      string `json:"device_code"`
+		UserCode        string `json:"user_code"`
+		VerificationURI string `json:"verification_uri"`
+		ExpiresIn       int    `json:"expires_in"`
+		Interval        int    `json:"interval"`
+	}
+	if err := post("/login/device/code", url.Values{"client_id": {clientID}, "scope": {scope}}, &code); err != nil {
+		return "", fmt.Errorf("❌ Could not start the device authorization: %w", err)
+	}
+	fmt.Printf("🌐 Open %s and enter the code: %s\n", code.VerificationURI, code.UserCode)
+
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	spin := startSpinner("Waiting for authorization")
+	defer spin.Stop()
+	for time.Now().Before(deadline) {
+		time.Sleep(interval)
+		var result struct {
+			AccessToken string `json:"access_token"`
+			Error       string `json:"error"`
+		}
+		err := post("/login/oauth/access_token", url.Values{
+			"client_id":   {clientID},
+			"device_code": {code.DeviceCode},
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		}, &result)
+		if err != nil {
+			return "", fmt.Errorf("❌ Device authorization failed: %w", err)
+		}
+		switch result.Error {
+		case "":
+			return result.AccessToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		case "access_denied":
+			return "", fmt.Errorf("❌ The authorization was denied")
+		default:
+			return "", fmt.Errorf("❌ Device authorization failed: %s", result.Error)
+		}
+	}
+	return "", fmt.Errorf("❌ The device code expired before it was entered")
+}
+
+// githubKeyClient returns a GitHub API client whose token may manage keys
+func githubKeyClient(account *Account, token string) (*githubClient, error) {
+	client := &githubClient{
+		BaseURL: githubAPIBase(account.GetProvider()),
+		Token:   token,
+		http:    &http.Client{Timeout: 15 * time.Second},
+	}
+	if err := client.verifyFeature(findGitHubFeature("keys")); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// keyUploadAllowed returns why keys of the account cannot be managed through
+// its provider's API, if they cannot
+func keyUploadAllowed(account *Account) error {
+	provider := account.GetProvider()
+	if offlineMode {
+		return fmt.Errorf("❌ The %s API is not available with --offline", provider.DisplayName)
+	}
+	if !keyUploadSupported(provider) {
+		return fmt.Errorf("❌ Uploading keys is not supported for %s; add it at %s", provider.DisplayName, provider.WebURL)
+	}
+	return nil
+}
+
+// uploadAccountKey adds the account's public key to its user on the provider
+// and records the key's API ID in the account, so removing the account can
+// delete it again
+func uploadAccountKey(config *Config, account *Account, interactive bool) error {
+	if err := keyUploadAllowed(account); err != nil {
+		return err
+	}
+	pubKey, err := os.ReadFile(account.SSHKey + ".pub")
+	if err != nil {
+		return fmt.Errorf("❌ Cannot read the public key: %v", err)
+	}
+	token, err := keyUploadToken(config, account, interactive)
+	if err != nil {
+		return err
+	}
+
+	provider := account.GetProvider()
+	title := "krakn " + account.Name
+	if hostname, err := os.Hostname(); err == nil {
+		title += "@" + hostname
+	}
+	body := map[string]string{
+		"title": title,
+		"key":   strings.TrimSpace(string(pubKey)),
+	}
+	var key struct {
+		ID int64 `json:"id"`
+	}
+
+	if provider.Name == "github" {
+		client, err := githubKeyClient(account, token)
+		if err != nil {
+			return err
+		}
+		spin := startSpinner("Uploading the public key")
+		_, err = client.do(http.MethodPost, "/user/keys", body, &key)
+		spin.Stop()
+		var apiErr *githubAPIError
+		if errors.As(err, &apiErr) && apiErr.Status == http.StatusUnprocessableEntity {
+			return fmt.Errorf("❌ GitHub rejected the key: %s. It may already be added to this or another user", apiErr.Message)
+		} else if err != nil {
+			return fmt.Errorf("❌ Could not upload the key: %w", err)
+		}
+	} else {
+		// GitLab, Gitea and Forgejo share the endpoint and request body
+		withToken := *account
+		withToken.Token = token
+		spin := startSpinner("Uploading the public key")
+		err := forgeRequest(&withToken, http.MethodPost, "/user/keys", body, &key)
+		spin.Stop()
+		if err != nil {
+			return fmt.Errorf("❌ Could not upload the key: %w", err)
+		}
+	}
+
+	account.KeyID = key.ID
+	fmt.Printf("☁️  Uploaded the public key to %s as '%s'\n", provider.DisplayName, title)
+	if fingerprint := keyFingerprint(account.SSHKey); fingerprint != "" {
+		fmt.Printf("🔏 Fingerprint: %s; %s shows the same in its key settings\n", fingerprint, provider.DisplayName)
+	}
+	return nil
+}
+
+// saveKeyID records the ID of an account's uploaded key
+func (c *Config) saveKeyID(name string, id int64) error {
+	account := c.getAccount(name)
+	if account == nil {
+		return c.accountNotFound(name)
+	}
+	account.KeyID = id
+	return c.addAccount(*account)
+}
+
+// offerKeyUpload uploads a newly created key when --upload was given, or asks
+// first when krakn runs interactively. It reports whether the key was uploaded.
+func offerKeyUpload(config *Config, account *Account, upload bool) bool {
+	interactive := term.IsTerminal(int(os.Stdin.Fd())) && !structuredOutput()
+	provider := account.GetProvider()
+	if !keyUploadSupported(provider) || offlineMode || (!upload && !interactive) {
+		return false
+	}
+	if !upload {
+		fmt.Printf("☁️  Upload the public key to %s now? [y/N]: ", provider.DisplayName)
+		resp, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if resp = strings.ToLower(strings.TrimSpace(resp)); resp != "y" && resp != "yes" {
+			return false
+		}
+	}
+	if err := uploadAccountKey(config, account, interactive); err != nil {
+		fmt.Printf("⚠️  %s\n", strings.TrimPrefix(err.Error(), "❌ "))
+		fmt.Printf("📋 Add it by hand at %s\n", provider.WebURL)
+		return false
+	}
+	return true
+}
+
+// deleteUploadedKey deletes the key krakn uploaded for an account. A key
+// already deleted on GitHub counts as deleted.
+func deleteUploadedKey(config *Config, account *Account) error {
+	if err := keyUploadAllowed(account); err != nil {
+		return err
+	}
+	token, err := keyUploadToken(config, account, term.IsTerminal(int(os.Stdin.Fd())))
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/user/keys/%d", account.KeyID)
+	if account.GetProvider().Name != "github" {
+		withToken := *account
+		withToken.Token = token
+		return forgeRequest(&withToken, http.MethodDelete, path, nil, nil)
+	}
+
+	client, err := githubKeyClient(account, token)
+	if err != nil {
+		return err
+	}
+	_, err = client.do(http.MethodDelete, path, nil, nil)
+	var apiErr *githubAPIError
+	if errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+var keyUploadCmd = &cobra.Command{
+	Use:   "upload <account-name>",
+	Short: "Add an account's public key to its provider through the API",
+	Long: `Add the public key of an account to its user on GitHub (including GitHub
+Enterprise Server), GitLab, Gitea or Forgejo, self-hosted instances included,
+so setting up a new machine needs no trip to the provider's settings page.
+
+The token is the account's API token ('krakn token set'), KRAKN_GITHUB_TOKEN,
+KRAKN_GITLAB_TOKEN or KRAKN_GITEA_TOKEN, or one you paste: GitHub needs the
+admin:public_key scope, GitLab api and Gitea/Forgejo write:user. The key's ID
+is saved, and 'krakn remove' offers to delete the key again.
+
+Examples:
+  krakn key upload work
+  KRAKN_GITEA_TOKEN=... krakn key upload codeberg`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+		if account.SSHKey == "" || account.usesHTTPS() {
+			return fmt.Errorf("❌ Account '%s' has no SSH key", account.Name)
+		}
+
+		interactive := term.IsTerminal(int(os.Stdin.Fd())) && !structuredOutput()
+		if err := uploadAccountKey(config, account, interactive); err != nil {
+			return err
+		}
+		if err := config.saveKeyID(account.Name, account.KeyID); err != nil {
+			return errSaveConfig(err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	keyCmd.AddCommand(keyUploadCmd)
+}
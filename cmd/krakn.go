@@ -11,8 +11,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// generateSSHKey generates an SSH key and optionally updates SSH config
-func generateSSHKey(name, email, keyPath string) error {
+// generateSSHKey generates an SSH key, encrypted with passphrase unless it is
+// empty, and optionally adds the Host block of the account on provider (GitHub
+// when nil) to SSH config
+func generateSSHKey(name, email, keyPath, passphrase string, provider *Provider) error {
 	// Ensure the SSH directory exists
 	if err := ensureSSHDirectory(); err != nil {
 		return err
@@ -28,22 +30,51 @@ func generateSSHKey(name, email, keyPath string) error {
 		return fmt.Errorf("❌ SSH key already exists at %s", keyPath)
 	}
 
-	// Generate SSH key
-	cmdArgs := []string{
-		"-t", "ed25519",
-		"-C", email,
-		"-f", keyPath,
-		"-q",
-		"-N", "",
-	}
+	trackFile(keyPath)
+	trackFile(keyPath + ".pub")
+
+	// Generate SSH key, preferring ssh-keygen when it is installed. A passphrase
+	// would be visible to other users on ssh-keygen's command line, so keys
+	// with one are generated natively in the same format.
+	if passphrase != "" {
+		spin := startSpinner("Generating SSH key")
+		err := generateNativeEd25519Key(keyPath, email, passphrase)
+		spin.Stop()
+		if err != nil {
+			return err
+		}
+	} else if sshKeygenAvailable() {
+		cmdArgs := []string{
+			"-t", "ed25519",
+			"-C", email,
+			"-f", keyPath,
+			"-q",
+			"-N", "",
+		}
 
-	cmdGen := exec.Command("ssh-keygen", cmdArgs...)
-	cmdGen.Stdin = os.Stdin
-	cmdGen.Stdout = os.Stdout
-	cmdGen.Stderr = os.Stderr
+		cmdGen := traceExec(exec.Command("ssh-keygen", cmdArgs...))
+		cmdGen.Stdin = os.Stdin
+		cmdGen.Stdout = os.Stdout
+		cmdGen.Stderr = os.Stderr
 
-	if err := cmdGen.Run(); err != nil {
-		return fmt.Errorf("failed to generate ssh key: %w", err)
+		spin := startSpinner("Generating SSH key")
+		err := cmdGen.Run()
+		spin.Stop()
+		if err != nil {
+			return fmt.Errorf("failed to generate ssh key: %w", err)
+		}
+	} else {
+		fmt.Println("⚠️  ssh-keygen not found; generating an ed25519 key natively")
+		fmt.Println("   💡 Install the OpenSSH client to use ssh-keygen instead")
+		spin := startSpinner("Generating SSH key")
+		err := generateNativeEd25519Key(keyPath, email, "")
+		spin.Stop()
+		if err != nil {
+			return err
+		}
+	}
+	if err := restrictToOwner(keyPath); err != nil {
+		return fmt.Errorf("failed to restrict access to %s: %w", keyPath, err)
 	}
 
 	// Read public key
@@ -53,13 +84,7 @@ func generateSSHKey(name, email, keyPath string) error {
 	}
 
 	// Create SSH config snippet
-	sshConfigSnippet := fmt.Sprintf(`
-
-Host github.com-%s
-  HostName github.com
-  User git
-  IdentityFile %s
-`, name, keyPath)
+	account := Account{Name: name, SSHKey: keyPath, Provider: provider}
 
 	// Ask user if they want to update SSH config
 	reader := bufio.NewReader(os.Stdin)
@@ -68,31 +93,30 @@ Host github.com-%s
 	resp = strings.ToLower(strings.TrimSpace(resp))
 
 	if resp == "y" || resp == "" {
-		// Ensure SSH directory exists before writing config
-		if err := ensureSSHDirectory(); err != nil {
+		// Replaces an existing block for the alias instead of appending a duplicate
+		if err := upsertSSHHostBlock(account.GetSSHHost(), account.GenerateSSHConfig()); err != nil {
 			return err
 		}
-		
-		homeDir, _ := os.UserHomeDir()
-		configPath := filepath.Join(homeDir, ".ssh", "config")
-
-		f, err := os.OpenFile(configPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-		if err != nil {
-			return fmt.Errorf("failed to write to SSH config: %w", err)
-		}
-		defer f.Close()
-		if _, err := f.WriteString(sshConfigSnippet); err != nil {
-			return fmt.Errorf("could not write config: %w", err)
-		}
 		fmt.Println("✅ SSH config updated.")
 	} else {
 		fmt.Println("⚠️ Skipped modifying ~/.ssh/config.")
 	}
 
 	fmt.Println("\n✅ SSH key created at:", keyPath)
+	if fingerprint := keyFingerprint(keyPath); fingerprint != "" {
+		fmt.Printf("🔏 Fingerprint: %s\n", fingerprint)
+	}
 	fmt.Println("\n🔑 Public key:\n" + string(pubKey))
-	fmt.Println("\n📋 Add this public key to GitHub: https://github.com/settings/ssh/new")
-	fmt.Printf("🌐 Host alias for SSH: github.com-%s\n", name)
+	if webURL := account.GetProvider().WebURL; webURL != "" {
+		fmt.Printf("\n📋 Add this public key to %s: %s\n", account.GetProvider().DisplayName, webURL)
+	}
+	fmt.Printf("🌐 Host alias for SSH: %s\n", account.GetSSHHost())
+	if passphrase != "" {
+		if store := systemSecretStore(); store != nil {
+			offerRememberPassphrase(store, keyPath, passphrase)
+		}
+		offerAgentAdd(&account)
+	}
 
 	return nil
 }
@@ -105,25 +129,67 @@ func ensureSSHDirectory() error {
 	}
 
 	sshDir := filepath.Join(homeDir, ".ssh")
-	if err := os.MkdirAll(sshDir, 0700); err != nil {
-		return fmt.Errorf("failed to create SSH directory: %w", err)
-	}
-
-	return nil
+	return makePrivateDir(sshDir)
 }
 
 // ensureSSHKeyDirectory creates the directory for an SSH key path if it doesn't exist
 func ensureSSHKeyDirectory(keyPath string) error {
 	keyDir := filepath.Dir(keyPath)
-	if err := os.MkdirAll(keyDir, 0700); err != nil {
+	if err := makePrivateDir(keyDir); err != nil {
 		return fmt.Errorf("failed to create directory for SSH key %s: %w", keyDir, err)
 	}
 	return nil
 }
 
+// makePrivateDir creates a directory only its owner can use. Existing
+// directories are left as they are; 'krakn fix-perms' tightens those.
+func makePrivateDir(dir string) error {
+	if fileExists(dir) {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return restrictToOwner(dir)
+}
+
+// writePrivateFile writes a file only its owner can read, also on Windows
+// where the create mode does not keep other users out
+func writePrivateFile(path string, data []byte) error {
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	return restrictToOwner(path)
+}
+
 var generateKeyCmd = &cobra.Command{
 	Use:   "generate-key",
-	Short: "Generate and configure a new SSH key for a GitHub account",
+	Short: "Generate and configure a new SSH key for an account",
+	Long: `Generate an ed25519 SSH key for an account, add its Host block to
+~/.ssh/config and optionally save the account. ssh-keygen is used when it is
+installed; otherwise the key is generated natively.
+
+With --passphrase the key is encrypted with a passphrase you enter (or
+KRAKN_KEY_PASSPHRASE), and krakn offers to load it into ssh-agent so git does
+not ask for the passphrase on every push.
+
+--provider takes the same values as 'krakn add': github (the default),
+gitlab, gitea, the hostname of a self-hosted server, or custom. The key is
+named after the provider and its Host block points at the provider's server.
+
+krakn offers to upload the public key to GitHub, or does so without asking
+with --upload. It uses the account's API token, KRAKN_GITHUB_TOKEN, or asks for
+a token with the admin:public_key scope; with KRAKN_GITHUB_CLIENT_ID set to an
+OAuth app's client ID you can authorize in the browser instead. The key's ID is
+saved so 'krakn remove' can delete it from GitHub again.
+
+Examples:
+  krakn generate-key --name work --email me@company.com
+  krakn key generate --name personal --email me@example.com
+  krakn key generate --name work --email me@company.com --key-dir /Volumes/Corp/ssh
+  krakn key generate --name work --email me@company.com --passphrase
+  krakn key generate --name oss --email me@example.com --provider gitlab
+  KRAKN_GITHUB_TOKEN=ghp_... krakn generate-key --name ci --email ci@company.com --upload`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name, _ := cmd.Flags().GetString("name")
 		email, _ := cmd.Flags().GetString("email")
@@ -132,14 +198,30 @@ var generateKeyCmd = &cobra.Command{
 			return fmt.Errorf("please provide both --name and --email")
 		}
 
-		homeDir, _ := os.UserHomeDir()
-		sshDir := filepath.Join(homeDir, ".ssh")
-		keyPath := filepath.Join(sshDir, fmt.Sprintf("id_ed25519_gh_%s", name))
+		keyDir, _ := cmd.Flags().GetString("key-dir")
+		if keyDir != "" {
+			keyDir, _ = filepath.Abs(expandUserPath(keyDir))
+		}
+		providerName, _ := cmd.Flags().GetString("provider")
+		provider, err := resolveProvider(providerName, bufio.NewReader(os.Stdin))
+		if err != nil {
+			return err
+		}
+		keyPath := defaultKeyPath(keyDir, provider.KeySuffix, name)
+
+		passphrase := ""
+		if protect, _ := cmd.Flags().GetBool("passphrase"); protect {
+			if passphrase, err = newKeyPassphrase(true); err != nil {
+				return err
+			}
+		}
 
-		if err := generateSSHKey(name, email, keyPath); err != nil {
+		if err := generateSSHKey(name, email, keyPath, passphrase, &provider); err != nil {
 			return err
 		}
 
+		upload, _ := cmd.Flags().GetBool("upload")
+
 		// Ask if user wants to save account configuration
 		reader := bufio.NewReader(os.Stdin)
 		fmt.Print("\n💾 Do you want to save this as an account configuration? [Y/n]: ")
@@ -147,7 +229,7 @@ var generateKeyCmd = &cobra.Command{
 		resp = strings.ToLower(strings.TrimSpace(resp))
 
 		if resp == "y" || resp == "" {
-			fmt.Print("👤 GitHub username: ")
+			fmt.Printf("👤 %s username: ", provider.DisplayName)
 			username, _ := reader.ReadString('\n')
 			username = strings.TrimSpace(username)
 
@@ -163,6 +245,10 @@ var generateKeyCmd = &cobra.Command{
 					Email:    email,
 					SSHKey:   keyPath,
 					Username: username,
+					KeyDir:   keyDir,
+				}
+				if provider != DefaultProviders["github"] {
+					account.Provider = &provider
 				}
 
 				if err := config.addAccount(account); err != nil {
@@ -171,9 +257,23 @@ var generateKeyCmd = &cobra.Command{
 				}
 
 				fmt.Printf("✅ Account '%s' saved to configuration!\n", name)
+
+				if offerKeyUpload(config, &account, upload) {
+					if err := config.saveKeyID(account.Name, account.KeyID); err != nil {
+						return errSaveConfig(err)
+					}
+				}
+				return nil
 			}
 		}
 
+		// Without a saved account there is nowhere to keep the key's ID, so
+		// 'krakn remove' cannot delete it later
+		unsaved := &Account{Name: name, SSHKey: keyPath}
+		if provider != DefaultProviders["github"] {
+			unsaved.Provider = &provider
+		}
+		offerKeyUpload(&Config{}, unsaved, upload)
 		return nil
 	},
 }
@@ -181,5 +281,10 @@ var generateKeyCmd = &cobra.Command{
 func init() {
 	generateKeyCmd.Flags().String("name", "", "Unique account name (e.g. 'work')")
 	generateKeyCmd.Flags().String("email", "", "Email address for SSH key")
+	generateKeyCmd.Flags().Bool("passphrase", false, "Protect the key with a passphrase, prompted for or read from KRAKN_KEY_PASSPHRASE")
+	generateKeyCmd.Flags().String("provider", "github", "Provider of the account: github, ghes[:<hostname>], gitlab, gitea, bitbucket, codeberg, azure-devops, sourcehut, a self-hosted hostname or custom")
+	generateKeyCmd.RegisterFlagCompletionFunc("provider", completeProvider)
+	generateKeyCmd.Flags().Bool("upload", false, "Upload the public key to the provider without asking")
+	generateKeyCmd.Flags().String("key-dir", "", "Directory to create the key in instead of ~/.ssh, e.g. on an encrypted volume")
 	RootCmd.AddCommand(generateKeyCmd)
 }
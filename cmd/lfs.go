@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// lfsEnabled reports whether a repository tracks files with Git LFS
+func lfsEnabled(repoRoot string) bool {
+	if data, err := os.ReadFile(filepath.Join(repoRoot, ".gitattributes")); err == nil && strings.Contains(string(data), "filter=lfs") {
+		return true
+	}
+	_, commonDir, err := findGitDir(repoRoot)
+	return err == nil && fileExists(filepath.Join(commonDir, "lfs"))
+}
+
+// lfsEndpoint returns the LFS server URL git-lfs uses for a remote: lfs.url,
+// remote.<name>.lfsurl, the repository's .lfsconfig, or the default derived
+// from the remote URL
+func lfsEndpoint(repoRoot, remoteName, remoteURL string) string {
+	if url := getRepoGitConfig(repoRoot, "lfs.url"); url != "" {
+		return url
+	}
+	if url := getRepoGitConfig(repoRoot, "remote."+remoteName+".lfsurl"); url != "" {
+		return url
+	}
+	if lfsConfig, err := readGitConfigFile(filepath.Join(repoRoot, ".lfsconfig")); err == nil {
+		if section := lfsConfig.findSection("lfs", ""); section != nil && section.get("url") != "" {
+			return section.get("url")
+		}
+	}
+	return remoteURL
+}
+
+// endpointAccount returns the account git authenticates as at a URL: the
+// owner of an SSH host alias, or the HTTPS-only account the credential
+// helper answers for
+func (c *Config) endpointAccount(endpoint string) *Account {
+	remote, err := parseRemoteURL(endpoint)
+	if err != nil {
+		return nil
+	}
+	if remote.isSSH() {
+		return c.findAccountByHost(remote.Host)
+	}
+	return c.findCredentialAccount(remote.Host, remote.Path)
+}
+
+// repoCredentialAccount answers a credential request for a host no account
+// lives on, such as a separate LFS server: when git-lfs runs in a repository
+// whose lfs.url is the requested URL, the account of the repository's origin
+// remote is used
+func (c *Config) repoCredentialAccount(host, path string) *Account {
+	repoRoot, err := findRepoRoot(".")
+	if err != nil {
+		return nil
+	}
+	endpoint, err := parseRemoteURL(lfsEndpoint(repoRoot, "origin", ""))
+	if err != nil || !strings.EqualFold(endpoint.Host, host) || !strings.HasPrefix(path, strings.TrimSuffix(endpoint.Path, "/")) {
+		return nil
+	}
+	account := c.endpointAccount(getRepoRemoteURL(repoRoot, "origin"))
+	if account != nil {
+		tracef(traceMatch, "%s is the LFS server of %s → account '%s'", host, contractHomePath(repoRoot), account.Name)
+	}
+	return account
+}
+
+// checkLFS warns when the LFS server of the current repository would
+// authenticate as another account than the repository itself
+func checkLFS(ctx *doctorContext) []doctorFinding {
+	if ctx.RepoRoot == "" || !lfsEnabled(ctx.RepoRoot) {
+		return nil
+	}
+
+	remoteURL := getRepoRemoteURL(ctx.RepoRoot, "origin")
+	endpoint := lfsEndpoint(ctx.RepoRoot, "origin", remoteURL)
+	if endpoint == "" || endpoint == remoteURL {
+		return []doctorFinding{{Level: doctorOK, Message: "LFS uses the remote's own server and credentials"}}
+	}
+
+	repoAccount := ctx.Config.endpointAccount(remoteURL)
+	if repoAccount == nil {
+		identity := inspectRepoIdentity(ctx.Config, ctx.RepoRoot, "origin")
+		if repoAccount = identity.MappedAccount; repoAccount == nil {
+			repoAccount = identity.EmailAccount
+		}
+	}
+	if repoAccount == nil {
+		return []doctorFinding{{Level: doctorInfo, Message: "LFS server " + endpoint + " (no krakn account for the repository)"}}
+	}
+
+	lfsAccount := ctx.Config.endpointAccount(endpoint)
+	remote, _ := parseRemoteURL(endpoint)
+	switch {
+	case lfsAccount != nil && lfsAccount.Name != repoAccount.Name:
+		return []doctorFinding{{
+			Level:   doctorWarn,
+			Message: fmt.Sprintf("lfs.url %s authenticates as '%s', but the repository uses '%s'", endpoint, lfsAccount.Name, repoAccount.Name),
+			Hint:    "Point lfs.url at a URL of the repository's account, or git config --unset lfs.url",
+		}}
+	case lfsAccount == nil && remote != nil && !remote.isSSH() && repoAccount.usesHTTPS():
+		// Only served when the helper is configured for the LFS host too
+		if !strings.Contains(getRepoGitConfig(ctx.RepoRoot, "credential.https://"+remote.Host+".helper"), "credential") {
+			return []doctorFinding{{
+				Level:   doctorWarn,
+				Message: fmt.Sprintf("LFS server %s does not get the token of '%s'", remote.Host, repoAccount.Name),
+				Hint:    fmt.Sprintf("git config --global credential.https://%s.helper '!krakn credential'", remote.Host),
+			}}
+		}
+	case lfsAccount == nil && remote != nil && remote.isSSH() && !repoAccount.usesHTTPS():
+		return []doctorFinding{{
+			Level:   doctorWarn,
+			Message: fmt.Sprintf("lfs.url uses %s directly, so SSH picks the default key instead of '%s'", remote.Host, repoAccount.Name),
+			Hint:    fmt.Sprintf("git config lfs.url %s", strings.Replace(endpoint, remote.Host, repoAccount.GetSSHHost(), 1)),
+		}}
+	}
+	return []doctorFinding{{Level: doctorOK, Message: fmt.Sprintf("LFS server %s authenticates as '%s'", endpoint, repoAccount.Name)}}
+}
+
+func init() {
+	registerDoctorCheck(doctorCheck{Name: "Git LFS", Run: checkLFS})
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -11,20 +12,64 @@ import (
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
-	Short:   "List all configured GitHub accounts",
-	Long: `List all configured GitHub accounts and current git configuration.
+	Short:   "List all configured accounts",
+	Long: `List all configured accounts and current git configuration. Accounts on
+more than one provider are grouped by provider.
 
-Use --global flag to show only global git configuration.`,
+Use --global flag to show only global git configuration.
+
+Use --long to show the SHA256 fingerprint of each account's key, as GitHub,
+GitLab and Gitea list it in their SSH key settings, to tell which uploaded key
+belongs to which account.
+
+Use --check for a quick health view: whether each account's key file exists,
+whether its ~/.ssh/config Host block is in place and points at that key, and
+the result of the last 'krakn test'. Nothing is contacted over the network;
+run 'krakn doctor' for the full diagnosis. 'krakn auth-refresh on' has the
+watch daemon keep those results fresh.
+
+Examples:
+  krakn list                # Accounts and current configuration
+  krakn list --long         # Include key fingerprints
+  krakn list --check        # Annotate accounts with key, SSH block and auth status
+  krakn list --global       # Only the global git configuration
+  krakn list --output json  # Accounts as JSON, for scripts and launchers
+  krakn list --output yaml  # ...or as YAML
+
+--json is short for --output json. Structured output always includes the key
+fingerprints, so --long changes nothing there; --check has no structured form
+and is refused with it.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		globalOnly, _ := cmd.Flags().GetBool("global")
+		check, _ := cmd.Flags().GetBool("check")
+		long, _ := cmd.Flags().GetBool("long")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		if asJSON {
+			outputFormat = "json"
+		}
 
-		if globalOnly {
+		if globalOnly && !structuredOutput() {
 			return showGlobalConfig()
 		}
 
 		config, err := loadConfig()
 		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+			return errLoadConfig(err)
+		}
+
+		if structuredOutput() {
+			// The check lines are meant for people; scripts get the same facts from 'krakn doctor --output json'
+			if check {
+				return fmt.Errorf("❌ --check cannot be combined with --output %s; use 'krakn doctor --output %s' for the checks", outputFormat, outputFormat)
+			}
+			return printStructured(listOutput{
+				SchemaVersion: schemaVersion("list"),
+				Current:       config.CurrentAccount,
+				Accounts:      describeAccounts(config),
+				Local:         gitIdentity{Name: getGitConfig("user.name", false), Email: getGitConfig("user.email", false)},
+				Global:        gitIdentity{Name: getGitConfig("user.name", true), Email: getGitConfig("user.email", true)},
+			})
 		}
 
 		if len(config.Accounts) == 0 {
@@ -33,21 +78,86 @@ Use --global flag to show only global git configuration.`,
 			return nil
 		}
 
-		fmt.Println("📋 Configured GitHub accounts:")
+		fmt.Println("📋 Configured accounts:")
 		fmt.Println()
 
-		for _, account := range config.Accounts {
-			status := ""
-			if account.Name == config.CurrentAccount {
-				status = " ✅ (current)"
+		var blocks []sshHostBlock
+		var authCache map[string]authResult
+		problems := 0
+		if check {
+			blocks, _ = readSSHConfig()
+			authCache = loadAuthCache()
+		}
+
+		groups := groupAccountsByProvider(config.Accounts)
+		for _, group := range groups {
+			if len(groups) > 1 {
+				heading := group.Provider.DisplayName
+				if !strings.Contains(heading, group.Provider.Hostname) {
+					heading += " (" + group.Provider.Hostname + ")"
+				}
+				fmt.Printf("🌐 %s\n\n", heading)
 			}
+			for _, account := range group.Accounts {
+				status := ""
+				if account.Name == config.CurrentAccount {
+					status = " ✅ (current)"
+				}
+
+				email := account.Email
+				if account.isSealed("email") {
+					email = "🔒 (sealed)"
+				}
 
-			fmt.Printf("👤 %s%s\n", account.Name, status)
-			fmt.Printf("   📧 Email: %s\n", account.Email)
-			fmt.Printf("   🔑 SSH Key: %s\n", account.SSHKey)
-			fmt.Printf("   🌐 GitHub: @%s\n", account.Username)
-			fmt.Printf("   🔗 SSH Host: github.com-%s\n", account.Name)
-			fmt.Println()
+				fmt.Printf("👤 %s%s\n", account.Name, status)
+				fmt.Printf("   📧 Email: %s\n", email)
+				if account.usesHTTPS() {
+					token := "no token"
+					switch {
+					case account.TokenStore == tokenStoreKeychain:
+						token = "token in the system keychain"
+					case account.isSealed("token"):
+						token = "sealed token"
+					case account.Token != "":
+						token = "token in the config"
+					}
+					fmt.Printf("   🔐 Auth: HTTPS (%s)\n", token)
+				} else {
+					fmt.Printf("   🔑 SSH Key: %s\n", account.identityFile())
+					if long && account.SSHKey != "" {
+						if fingerprint := keyFingerprint(account.SSHKey); fingerprint != "" {
+							fmt.Printf("   🔏 Fingerprint: %s\n", fingerprint)
+						} else {
+							fmt.Printf("   🔏 Fingerprint: ⚠️  %s.pub cannot be read\n", contractHomePath(account.SSHKey))
+						}
+					}
+				}
+				fmt.Printf("   🌐 %s: @%s\n", account.GetProvider().DisplayName, account.Username)
+				if profile := account.Profile; profile != nil {
+					verified := "@" + profile.Login
+					if profile.DisplayName != "" {
+						verified = fmt.Sprintf("%s (@%s)", profile.DisplayName, profile.Login)
+					}
+					fmt.Printf("   ☑️  Verified: %s, id %d, on %s\n", verified, profile.ID, profile.VerifiedAt.Format("2006-01-02"))
+					if profile.AvatarURL != "" {
+						fmt.Printf("   🖼️  Avatar: %s\n", profile.AvatarURL)
+					}
+				}
+				if !account.usesHTTPS() {
+					fmt.Printf("   🔗 SSH Host: %s\n", account.GetSSHHost())
+				}
+				if check {
+					line, ok := accountCheckLine(&account, blocks, authCache)
+					fmt.Printf("   🩺 %s\n", line)
+					if !ok {
+						problems++
+					}
+				}
+				fmt.Println()
+			}
+		}
+		if problems > 0 {
+			fmt.Printf("💡 %d account(s) need attention: 'krakn doctor' explains, 'krakn test' refreshes authentication\n\n", problems)
 		}
 
 		// Show current git config
@@ -76,12 +186,105 @@ Use --global flag to show only global git configuration.`,
 	},
 }
 
+// providerGroup is the accounts of one provider host, as listed by 'krakn list'
+type providerGroup struct {
+	Provider Provider
+	Accounts []Account
+}
+
+// groupAccountsByProvider groups accounts by provider hostname, in the order
+// each provider first appears
+func groupAccountsByProvider(accounts []Account) []providerGroup {
+	var groups []providerGroup
+	index := map[string]int{}
+	for _, account := range accounts {
+		hostname := account.GetProvider().Hostname
+		i, ok := index[hostname]
+		if !ok {
+			i = len(groups)
+			index[hostname] = i
+			groups = append(groups, providerGroup{Provider: account.GetProvider()})
+		}
+		groups[i].Accounts = append(groups[i].Accounts, account)
+	}
+	return groups
+}
+
+// accountCheckLine summarizes an account's key file, SSH Host block and last
+// authentication result without contacting the provider
+func accountCheckLine(account *Account, blocks []sshHostBlock, authCache map[string]authResult) (string, bool) {
+	if account.usesHTTPS() {
+		if !account.hasToken() {
+			return "https ⚠️  no token", false
+		}
+		return "https ✅ token", true
+	}
+
+	ok := true
+	key := "key ✅"
+	switch {
+	case account.SSHKey == "":
+		key, ok = "key ⚠️  none", false
+	case account.AgentKey && !fileExists(account.SSHKey+".pub"):
+		key, ok = "agent key ❌ public key missing", false
+	case account.AgentKey:
+		key = "agent key ✅"
+	case !fileExists(account.SSHKey):
+		key, ok = "key ❌ missing", false
+	}
+
+	block := "ssh block ❌ missing"
+	for i := range blocks {
+		if blocks[i].alias() != account.GetSSHHost() {
+			continue
+		}
+		identity := blocks[i].get("IdentityFile")
+		if identity == "" || account.SSHKey == "" || filepath.Clean(expandSSHPath(identity, &blocks[i])) == filepath.Clean(account.identityFile()) {
+			block = "ssh block ✅"
+		} else {
+			block = "ssh block ⚠️  other key"
+		}
+		break
+	}
+	if block != "ssh block ✅" {
+		ok = false
+	}
+
+	auth := "auth ❔ not tested"
+	if result, found := authCache[account.Name]; found {
+		switch {
+		case !result.OK:
+			auth, ok = fmt.Sprintf("auth ❌ %s", formatAge(result.Time)), false
+		case result.Username != "":
+			auth = fmt.Sprintf("auth ✅ as %s, %s", result.Username, formatAge(result.Time))
+		default:
+			auth = fmt.Sprintf("auth ✅ %s", formatAge(result.Time))
+		}
+	}
+	return key + "   " + block + "   " + auth, ok
+}
+
+// gitIdentity is a user.name and user.email pair in structured output
+type gitIdentity struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// listOutput is printed by 'krakn list --output json|yaml'
+type listOutput struct {
+	SchemaVersion int          `json:"schema_version"`
+	Current       string       `json:"current" desc:"Global current account; empty when none is selected"`
+	Accounts      []ipcAccount `json:"accounts"`
+	Local         gitIdentity  `json:"local" desc:"Identity git uses in the working directory"`
+	Global        gitIdentity  `json:"global" desc:"Identity in the global git config"`
+}
+
 func getGitConfig(key string, global bool) string {
 	var cmd *exec.Cmd
 	if global {
-		cmd = exec.Command("git", "config", "--global", "--get", key)
+		cmd = traceExec(exec.Command("git", "config", "--global", "--get", key))
 	} else {
-		cmd = exec.Command("git", "config", "--get", key)
+		cmd = traceExec(exec.Command("git", "config", "--get", key))
 	}
 
 	output, err := cmd.Output()
@@ -112,5 +315,9 @@ func showGlobalConfig() error {
 
 func init() {
 	listCmd.Flags().BoolP("global", "g", false, "Show only global git configuration")
+	listCmd.Flags().BoolP("long", "l", false, "Show the fingerprint of each account's SSH key")
+	listCmd.Flags().BoolP("check", "c", false, "Annotate accounts with key, SSH block and cached authentication status")
+	listCmd.Flags().Bool("json", false, "Same as --output json")
 	RootCmd.AddCommand(listCmd)
+	registerOutputSchema(outputSchema{Command: "list", Version: 1, Description: "Accounts and the local and global git identity", Type: listOutput{}})
 }
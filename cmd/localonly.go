@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// localOnlyEnabled reports whether local-only mode is on. The low-level
+// writers of git and SSH config files do not get the config passed in, so it
+// is read again; the watch daemon thereby sees the setting change too.
+func localOnlyEnabled() bool {
+	config, err := loadConfig()
+	return err == nil && config.LocalOnly
+}
+
+// isUserDotfile reports whether path is one of the user-wide files local-only
+// mode leaves alone: the global git config files and ~/.ssh/config
+func isUserDotfile(path string) bool {
+	path = filepath.Clean(path)
+	for _, dotfile := range append(globalGitConfigFiles(), globalGitConfigPath(), getSSHConfigPath()) {
+		if path == filepath.Clean(dotfile) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipDotfileEdit reports whether an edit of path must be skipped because of
+// local-only mode, and says so. what describes the edit, e.g. "Host block
+// github.com-work".
+func skipDotfileEdit(path, what string) bool {
+	if !isUserDotfile(path) || !localOnlyEnabled() {
+		return false
+	}
+	tracef(traceMatch, "Local-only mode is on, so %s is not edited", contractHomePath(path))
+	fmt.Printf("⏭️  Local-only mode: skipped %s in %s\n", what, contractHomePath(path))
+	return true
+}
+
+// localIdentityValues returns the repository config values that give a
+// repository an account's identity. In local-only mode there is no Host alias
+// in ~/.ssh/config, so core.sshCommand selects the account's key instead.
+func (c *Config) localIdentityValues(account *Account) []gitConfigValue {
+	values := []gitConfigValue{
+		{Key: "user.name", Value: account.CommitName()},
+		{Key: "user.email", Value: account.Email},
+	}
+	if template := account.commitTemplatePath(); template != "" {
+		values = append(values, gitConfigValue{Key: "commit.template", Value: contractHomePath(template)})
+	}
+	values = append(values, account.signingValues()...)
+	if c.LocalOnly && !account.usesHTTPS() && account.SSHKey != "" {
+		values = append(values, gitConfigValue{Key: "core.sshCommand", Value: localSSHCommand(account)})
+	}
+	return values
+}
+
+// localSSHCommand returns a core.sshCommand that authenticates with the
+// account's key and nothing else
+func localSSHCommand(account *Account) string {
+	return "ssh -i " + shellQuote(account.SSHKey) + " -o IdentitiesOnly=yes"
+}
+
+// cloneHost returns the host to put in SSH clone URLs: the account's alias,
+// or in local-only mode the provider's hostname, as no alias is written
+func (c *Config) cloneHost(account *Account) string {
+	if c.LocalOnly {
+		return account.GetProvider().Hostname
+	}
+	return account.GetSSHHost()
+}
+
+var localOnlyCmd = &cobra.Command{
+	Use:   "local-only [on|off]",
+	Short: "Never edit ~/.gitconfig or ~/.ssh/config",
+	Long: `Turn local-only mode on or off, or show whether it is on.
+
+In local-only mode krakn never edits your global git config or ~/.ssh/config.
+Commands that would have changed them say what they skipped and continue:
+
+  krakn use <account> <repo>    Writes user.name, user.email and a
+                                core.sshCommand selecting the account's key
+                                into the repository's own config
+  krakn use <account>           Records the current account for 'krakn env'
+                                without setting a global identity
+  krakn config <dir> <account>  Works like --existing-repos: every repository
+                                under the directory gets the identity
+  krakn add                     Creates the key but no Host alias; clone with
+                                the plain hostname
+
+Turning the mode on leaves entries krakn wrote earlier in place; remove them
+with 'krakn uninstall' if you no longer want them.
+
+Examples:
+  krakn local-only        # Show the current setting
+  krakn local-only on
+  krakn local-only off`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"on", "off"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if len(args) == 0 {
+			if config.LocalOnly {
+				fmt.Println("🏠 Local-only mode is on: ~/.gitconfig and ~/.ssh/config are never edited")
+			} else {
+				fmt.Println("🌐 Local-only mode is off")
+			}
+			return nil
+		}
+
+		if args[0] != "on" && args[0] != "off" {
+			return fmt.Errorf("❌ Expected 'on' or 'off', got '%s'", args[0])
+		}
+		if err := config.update(func(config *Config) error {
+			config.LocalOnly = args[0] == "on"
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+
+		if !config.LocalOnly {
+			fmt.Println("🌐 Local-only mode is off; krakn manages ~/.gitconfig and ~/.ssh/config again")
+			fmt.Println("💡 Run 'krakn use <account>' and 'krakn add' again to write the global identity and Host aliases")
+			return nil
+		}
+		fmt.Println("🏠 Local-only mode is on: ~/.gitconfig and ~/.ssh/config are never edited")
+		var leftovers []string
+		if gitConfig, err := readGitConfigFile(globalGitConfigPath()); err == nil {
+			for _, mapping := range config.Directories {
+				if findIncludeIfForDir(gitConfig, mapping.patternDir()) != nil {
+					leftovers = append(leftovers, "includeIf for "+contractHomePath(mapping.patternDir()))
+				}
+			}
+		}
+		if blocks, err := readSSHConfig(); err == nil {
+			for _, block := range blocks {
+				for _, account := range config.Accounts {
+					if account.SSHHost == "" && block.alias() == account.GetSSHHost() {
+						leftovers = append(leftovers, "Host "+block.alias())
+					}
+				}
+			}
+		}
+		if len(leftovers) > 0 {
+			fmt.Println("\nℹ️  Entries written earlier stay in place:")
+			for _, leftover := range leftovers {
+				fmt.Printf("   %s\n", leftover)
+			}
+			fmt.Println("💡 Remove them with 'krakn uninstall' if you no longer want them")
+		}
+		fmt.Println("\n💡 Give repositories an identity with 'krakn use <account> <repo>' or 'krakn config <dir> <account>'")
+		return nil
+	},
+}
+
+// checkLocalOnly reports local-only mode, so missing aliases and includes
+// elsewhere in the report are not mistaken for problems
+func checkLocalOnly(ctx *doctorContext) []doctorFinding {
+	if !ctx.Config.LocalOnly {
+		return nil
+	}
+	return []doctorFinding{{Level: doctorInfo, Message: "Local-only mode: ~/.gitconfig and ~/.ssh/config are not managed by krakn"}}
+}
+
+func init() {
+	RootCmd.AddCommand(localOnlyCmd)
+	registerDoctorCheck(doctorCheck{Name: "Local-only mode", Run: checkLocalOnly})
+}
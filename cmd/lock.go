@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// lockTimeout is how long to wait for another krakn to finish writing
+	lockTimeout = 10 * time.Second
+	// staleLockAge is when a lock is considered abandoned no matter what.
+	// Locks are only held around a single read-modify-write, never across
+	// prompts, so a legitimate holder is done long before this.
+	staleLockAge = 2 * time.Minute
+)
+
+// lockInfo is written into a lock file to identify its holder
+type lockInfo struct {
+	PID     int       `json:"pid"`
+	Host    string    `json:"host"`
+	Command string    `json:"command"`
+	Time    time.Time `json:"time"`
+}
+
+// heldLocks counts the locks this process holds per path, so a function
+// holding a lock can call helpers that lock the same file
+var (
+	heldLocks   = map[string]int{}
+	heldLocksMu sync.Mutex
+)
+
+// lockPath returns the lock file guarding path. Symlinks are resolved so a
+// ~/.gitconfig linked into a dotfiles repository is locked only once.
+func lockPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return path + ".krakn.lock"
+}
+
+// staleLock reports whether the lock's holder is gone: a process on this
+// machine that no longer runs, or any lock older than staleLockAge
+func staleLock(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if time.Since(info.ModTime()) > staleLockAge {
+		return true
+	}
+
+	var holder lockInfo
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &holder) != nil {
+		// Being written right now, or garbage; let the age decide
+		return false
+	}
+	host, _ := os.Hostname()
+	return holder.Host == host && holder.PID > 0 && !processAlive(holder.PID)
+}
+
+// lockFile takes the lock for path, waiting up to lockTimeout for another
+// krakn process to release it. The returned function releases the lock.
+func lockFile(path string) (func(), error) {
+	lock := lockPath(path)
+
+	heldLocksMu.Lock()
+	if heldLocks[lock] > 0 {
+		heldLocks[lock]++
+		heldLocksMu.Unlock()
+		return func() { releaseLock(lock) }, nil
+	}
+	heldLocksMu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(lock), 0700); err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	info, _ := json.Marshal(lockInfo{
+		PID:     os.Getpid(),
+		Host:    host,
+		Command: "krakn " + strings.Join(os.Args[1:], " "),
+		Time:    time.Now(),
+	})
+
+	deadline := time.Now().Add(lockTimeout)
+	for delay := 10 * time.Millisecond; ; delay = min(delay*2, 500*time.Millisecond) {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, err = f.Write(info)
+			f.Close()
+			if err != nil {
+				os.Remove(lock)
+				return nil, fmt.Errorf("failed to write lock file %s: %w", lock, err)
+			}
+			heldLocksMu.Lock()
+			heldLocks[lock] = 1
+			heldLocksMu.Unlock()
+			return func() { releaseLock(lock) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock file %s: %w", lock, err)
+		}
+
+		if staleLock(lock) {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, lockHeldError(path, lock)
+		}
+		time.Sleep(delay)
+	}
+}
+
+func releaseLock(lock string) {
+	heldLocksMu.Lock()
+	defer heldLocksMu.Unlock()
+	heldLocks[lock]--
+	if heldLocks[lock] <= 0 {
+		delete(heldLocks, lock)
+		os.Remove(lock)
+	}
+}
+
+// lockHeldError names the process holding a lock, so the user can tell a
+// slow krakn from an abandoned lock
+func lockHeldError(path, lock string) error {
+	var holder lockInfo
+	if data, err := os.ReadFile(lock); err == nil && json.Unmarshal(data, &holder) == nil {
+		return newError(codeFileLocked, "if no krakn is running, rm "+contractHomePath(lock),
+			"%s is being changed by another krakn (pid %d, '%s', since %s)",
+			contractHomePath(path), holder.PID, holder.Command, holder.Time.Format("15:04:05"))
+	}
+	return newError(codeFileLocked, "if no krakn is running, rm "+contractHomePath(lock),
+		"%s is being changed by another krakn", contractHomePath(path))
+}
+
+// withFileLock runs fn while holding the lock for path. Wrap the whole
+// read-modify-write so concurrent krakn runs cannot lose each other's changes.
+func withFileLock(path string, fn func() error) error {
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+// writeFileAtomic replaces path with data through a temporary file and a
+// rename, so readers never see a partially written file. Symlinks are
+// followed and stay in place.
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".krakn-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
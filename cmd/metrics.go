@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// MetricsConfig makes 'krakn watch' serve its state over HTTP on a loopback
+// address, for dashboards and menu bar tools
+type MetricsConfig struct {
+	Address string `json:"address"` // e.g. "127.0.0.1:9464"
+}
+
+// defaultMetricsAddress is the loopback address used when none is given
+const defaultMetricsAddress = "127.0.0.1:9464"
+
+// metricsDoctorInterval is how often the daemon re-runs doctor for the
+// metrics; the checks read many files and should not run every minute
+const metricsDoctorInterval = 15 * time.Minute
+
+// isLoopbackHost reports whether a host name or address only reaches this machine
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// validateMetricsAddress accepts host:port with a loopback host, or a bare
+// port on 127.0.0.1
+func validateMetricsAddress(address string) (string, error) {
+	if !strings.Contains(address, ":") {
+		address = "127.0.0.1:" + address
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", fmt.Errorf("❌ Invalid address '%s': %v", address, err)
+	}
+	if !isLoopbackHost(host) {
+		return "", fmt.Errorf("❌ The metrics endpoint only listens on loopback addresses such as 127.0.0.1 or [::1], not '%s'", host)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// metricsStatus is what the endpoint reports, published after every wake-up
+type metricsStatus struct {
+	Version        string     `json:"version"`
+	Account        string     `json:"account"`
+	Accounts       int        `json:"accounts"`
+	LastWakeUp     time.Time  `json:"last_wakeup"`
+	LastSwitch     *time.Time `json:"last_switch,omitempty"`
+	LastSwitchTo   string     `json:"last_switch_to,omitempty"`
+	DoctorChecked  *time.Time `json:"doctor_checked,omitempty"`
+	DoctorWarnings int        `json:"doctor_warnings"`
+	DoctorErrors   int        `json:"doctor_errors"`
+}
+
+var (
+	metricsMu      sync.Mutex
+	metricsCurrent metricsStatus
+	metricsServer  *http.Server
+	metricsAddress string
+)
+
+// runMetricsDoctor re-runs the doctor checks for the metrics. The network
+// checks are skipped so the daemon never calls provider APIs on its own.
+func runMetricsDoctor(ctx *watchContext) error {
+	if ctx.Config.Metrics == nil || ctx.Now.Sub(ctx.State.DoctorChecked) < metricsDoctorInterval {
+		return nil
+	}
+	ctx.State.DoctorChecked = ctx.Now
+	ctx.State.DoctorWarnings, ctx.State.DoctorErrors = 0, 0
+	doctorCtx := &doctorContext{Config: ctx.Config, Offline: true}
+	for _, check := range doctorChecks {
+		for _, finding := range check.Run(doctorCtx) {
+			switch finding.Level {
+			case doctorWarn:
+				ctx.State.DoctorWarnings++
+			case doctorError:
+				ctx.State.DoctorErrors++
+			}
+		}
+	}
+	return nil
+}
+
+// optionalTime leaves a zero time out of the JSON status
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// publishMetrics updates what the endpoint reports and starts, moves or
+// stops the server when the configuration changed since the last wake-up
+func publishMetrics(ctx *watchContext) {
+	metricsMu.Lock()
+	metricsCurrent = metricsStatus{
+		Version:        Version,
+		Account:        ctx.Config.CurrentAccount,
+		Accounts:       len(ctx.Config.Accounts),
+		LastWakeUp:     ctx.Now,
+		LastSwitch:     optionalTime(ctx.State.LastSwitch),
+		LastSwitchTo:   ctx.State.LastSwitchTo,
+		DoctorChecked:  optionalTime(ctx.State.DoctorChecked),
+		DoctorWarnings: ctx.State.DoctorWarnings,
+		DoctorErrors:   ctx.State.DoctorErrors,
+	}
+	metricsMu.Unlock()
+
+	address := ""
+	if ctx.Config.Metrics != nil {
+		address = ctx.Config.Metrics.Address
+	}
+	if address == metricsAddress {
+		return
+	}
+	if metricsServer != nil {
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		metricsServer.Shutdown(shutdown)
+		cancel()
+		metricsServer = nil
+		fmt.Printf("📉 %s Stopped the metrics endpoint on %s\n", ctx.Now.Format("15:04"), metricsAddress)
+	}
+	metricsAddress = address
+	if address == "" {
+		return
+	}
+	// The config may have been edited by hand
+	if _, err := validateMetricsAddress(address); err != nil {
+		fmt.Printf("⚠️  %s %v\n", ctx.Now.Format("15:04"), strings.TrimPrefix(err.Error(), "❌ "))
+		return
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		fmt.Printf("⚠️  %s Cannot serve metrics on %s: %v\n", ctx.Now.Format("15:04"), address, err)
+		return
+	}
+	metricsServer = &http.Server{Handler: metricsHandler(), ReadHeaderTimeout: 5 * time.Second}
+	go metricsServer.Serve(listener)
+	fmt.Printf("📈 %s Serving metrics on http://%s/metrics\n", ctx.Now.Format("15:04"), address)
+}
+
+// metricsHandler serves /metrics in the Prometheus text format and /status as
+// JSON. Requests naming another host are refused, so web pages cannot read
+// the endpoint through DNS rebinding.
+func metricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		metricsMu.Lock()
+		status := metricsCurrent
+		metricsMu.Unlock()
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		writePrometheusMetrics(w, status)
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		metricsMu.Lock()
+		status := metricsCurrent
+		metricsMu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(status)
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		if !isLoopbackHost(strings.Trim(host, "[]")) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// prometheusLabel escapes a label value
+func prometheusLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// writePrometheusMetrics renders the status as Prometheus metrics
+func writePrometheusMetrics(w io.Writer, status metricsStatus) {
+	timestamp := func(t *time.Time) int64 {
+		if t == nil {
+			return 0
+		}
+		return t.Unix()
+	}
+	fmt.Fprintln(w, "# HELP krakn_info Version of the running krakn watch daemon.")
+	fmt.Fprintln(w, "# TYPE krakn_info gauge")
+	fmt.Fprintf(w, "krakn_info{version=\"%s\"} 1\n", prometheusLabel(status.Version))
+	fmt.Fprintln(w, "# HELP krakn_active_account The account of the global git identity.")
+	fmt.Fprintln(w, "# TYPE krakn_active_account gauge")
+	fmt.Fprintf(w, "krakn_active_account{account=\"%s\"} 1\n", prometheusLabel(status.Account))
+	fmt.Fprintln(w, "# HELP krakn_accounts Number of configured accounts.")
+	fmt.Fprintln(w, "# TYPE krakn_accounts gauge")
+	fmt.Fprintf(w, "krakn_accounts %d\n", status.Accounts)
+	fmt.Fprintln(w, "# HELP krakn_last_wakeup_timestamp_seconds Time of the daemon's last wake-up.")
+	fmt.Fprintln(w, "# TYPE krakn_last_wakeup_timestamp_seconds gauge")
+	fmt.Fprintf(w, "krakn_last_wakeup_timestamp_seconds %d\n", status.LastWakeUp.Unix())
+	fmt.Fprintln(w, "# HELP krakn_last_switch_timestamp_seconds Time the daemon last switched the global identity (0 if never).")
+	fmt.Fprintln(w, "# TYPE krakn_last_switch_timestamp_seconds gauge")
+	fmt.Fprintf(w, "krakn_last_switch_timestamp_seconds{account=\"%s\"} %d\n", prometheusLabel(status.LastSwitchTo), timestamp(status.LastSwitch))
+	fmt.Fprintln(w, "# HELP krakn_doctor_problems Problems found by the last doctor run, by severity.")
+	fmt.Fprintln(w, "# TYPE krakn_doctor_problems gauge")
+	fmt.Fprintf(w, "krakn_doctor_problems{level=\"warn\"} %d\n", status.DoctorWarnings)
+	fmt.Fprintf(w, "krakn_doctor_problems{level=\"error\"} %d\n", status.DoctorErrors)
+	fmt.Fprintln(w, "# HELP krakn_doctor_last_run_timestamp_seconds Time of the last doctor run (0 if never).")
+	fmt.Fprintln(w, "# TYPE krakn_doctor_last_run_timestamp_seconds gauge")
+	fmt.Fprintf(w, "krakn_doctor_last_run_timestamp_seconds %d\n", timestamp(status.DoctorChecked))
+}
+
+var metricsCmd = &cobra.Command{
+	Use:   "metrics",
+	Short: "Serve the watch daemon's state on localhost",
+	Long: `Let 'krakn watch' (and so 'krakn service') serve its state over HTTP, for
+dashboards and menu bar tools that show the identity without running krakn:
+
+  /metrics   Prometheus text format: active account, last switch time,
+             doctor problems by severity
+  /status    The same as JSON
+
+The endpoint is off by default, listens on loopback addresses only and answers
+read-only GET requests. Doctor runs every 15 minutes for it, without network
+checks. A running daemon picks up the change at its next wake-up.
+
+Examples:
+  krakn metrics                          # Show the current settings
+  krakn metrics on
+  krakn metrics on --address 127.0.0.1:9500
+  krakn metrics off
+  curl -s localhost:9464/status`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if config.Metrics == nil {
+			fmt.Println("📉 The metrics endpoint is off. Turn it on with 'krakn metrics on'.")
+			return nil
+		}
+		fmt.Printf("📈 'krakn watch' serves metrics on http://%s/metrics\n", config.Metrics.Address)
+		return nil
+	},
+}
+
+var metricsOnCmd = &cobra.Command{
+	Use:   "on",
+	Short: "Serve metrics from the watch daemon",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		address, _ := cmd.Flags().GetString("address")
+		address, err := validateMetricsAddress(address)
+		if err != nil {
+			return err
+		}
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if err := config.update(func(config *Config) error {
+			config.Metrics = &MetricsConfig{Address: address}
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("📈 'krakn watch' serves metrics on http://%s/metrics\n", address)
+		fmt.Println("💡 Run the daemon with 'krakn service install' if it is not running yet")
+		return nil
+	},
+}
+
+var metricsOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Stop serving metrics",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if err := config.update(func(config *Config) error {
+			config.Metrics = nil
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Println("📉 The metrics endpoint is off")
+		return nil
+	},
+}
+
+func init() {
+	metricsOnCmd.Flags().String("address", defaultMetricsAddress, "Loopback address and port to listen on")
+	metricsCmd.AddCommand(metricsOnCmd)
+	metricsCmd.AddCommand(metricsOffCmd)
+	RootCmd.AddCommand(metricsCmd)
+	registerWatchTask(watchTask{Name: "Doctor", Run: runMetricsDoctor})
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -14,22 +15,88 @@ import (
 
 // DiscoveredAccount represents a potential account found during migration
 type DiscoveredAccount struct {
-	Name       string
-	Email      string
-	Username   string
-	Source     string // "global", "ssh-config", etc.
-	Suggested  bool   // Whether this is a suggested match
+	Name      string
+	Email     string
+	Username  string
+	Source    string    // "global", "ssh-config", etc.
+	Suggested bool      // Whether this is a suggested match
+	SSHHost   string    // Host alias of an SSH config discovery
+	SSHKey    string    // IdentityFile of an SSH config discovery
+	Existing  string    // Account it was already imported as
+	Agent     *agentKey // Key of an ssh-agent discovery
+}
+
+// importedAs returns the account a discovery was already imported as, matched
+// by email, Host alias or SSH key, so a forced 'krakn migrate' does not add it
+// a second time
+func (c *Config) importedAs(discovered DiscoveredAccount) string {
+	for _, account := range c.Accounts {
+		switch {
+		case discovered.Email != "" && strings.EqualFold(discovered.Email, account.Email):
+		case discovered.SSHHost != "" && discovered.SSHHost == account.GetSSHHost():
+		case discovered.SSHKey != "" && sameKey(discovered.SSHKey, account.SSHKey):
+		default:
+			continue
+		}
+		return account.Name
+	}
+	return ""
+}
+
+// migrationPending reports whether the first-run migration still has to be
+// offered. It only peeks at config.json, so the common case of an existing
+// setup costs one small file read instead of a full loadConfig.
+func migrationPending() bool {
+	data, err := os.ReadFile(getConfigPath())
+	if os.IsNotExist(err) {
+		return true
+	}
+	if err != nil {
+		return false
+	}
+	var state struct {
+		MigrationDone bool              `json:"migration_done"`
+		Accounts      []json.RawMessage `json:"accounts"`
+	}
+	// A damaged config is not a first run; 'krakn repair-config' deals with it
+	if json.Unmarshal(data, &state) != nil {
+		return false
+	}
+	return !state.MigrationDone && len(state.Accounts) == 0
 }
 
 // checkAndOfferMigration checks if this is first run and offers to migrate existing git config
 func checkAndOfferMigration() error {
+	return offerMigration(false)
+}
+
+// finishMigration adds the migrated accounts, making the first one current
+// when none is, and records that the migration was offered
+func (c *Config) finishMigration(accounts []Account) error {
+	return c.update(func(config *Config) error {
+		for _, account := range accounts {
+			if config.getAccount(account.Name) == nil {
+				config.Accounts = append(config.Accounts, account)
+			}
+		}
+		if len(config.Accounts) > 0 && config.CurrentAccount == "" {
+			config.CurrentAccount = config.Accounts[0].Name
+		}
+		config.MigrationDone = true
+		return nil
+	})
+}
+
+// offerMigration discovers existing configuration and imports what the user
+// selects. Unless forced it only runs once, before any account exists.
+func offerMigration(forced bool) error {
 	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
 	// Skip if migration already done or accounts already exist
-	if config.MigrationDone || len(config.Accounts) > 0 {
+	if !forced && (config.MigrationDone || len(config.Accounts) > 0) {
 		return nil
 	}
 
@@ -38,12 +105,25 @@ func checkAndOfferMigration() error {
 
 	if len(discovered) == 0 {
 		// No existing configuration found, mark migration as done
-		config.MigrationDone = true
-		return config.saveConfig()
+		if forced {
+			fmt.Println("📭 No git or SSH configuration found to migrate")
+		}
+		return config.finishMigration(nil)
+	}
+
+	// Configuration that matches an account was migrated (or added) before
+	var fresh []DiscoveredAccount
+	for i := range discovered {
+		discovered[i].Existing = config.importedAs(discovered[i])
+		if discovered[i].Existing == "" {
+			fresh = append(fresh, discovered[i])
+		}
 	}
 
 	// Offer migration
-	fmt.Println("👋 Welcome to krakncat!")
+	if !forced {
+		fmt.Println("👋 Welcome to krakncat!")
+	}
 	fmt.Println("\n🔍 I found existing git/SSH configuration:")
 
 	for i, acc := range discovered {
@@ -57,7 +137,17 @@ func checkAndOfferMigration() error {
 		if acc.Username != "" {
 			fmt.Printf(" - Username: %s", acc.Username)
 		}
+		if acc.Existing != "" {
+			fmt.Printf(" ✅ already imported as '%s'", acc.Existing)
+		}
 	}
+	fmt.Println()
+
+	if len(fresh) == 0 {
+		fmt.Println("\n✅ Everything found is already imported; nothing to migrate")
+		return config.finishMigration(nil)
+	}
+	discovered = fresh
 
 	reader := bufio.NewReader(os.Stdin)
 	fmt.Print("\n💫 Would you like to migrate any of these accounts to krakncat? [Y/n]: ")
@@ -65,40 +155,42 @@ func checkAndOfferMigration() error {
 	resp = strings.ToLower(strings.TrimSpace(resp))
 
 	if resp == "n" || resp == "no" {
-		config.MigrationDone = true
-		return config.saveConfig()
+		return config.finishMigration(nil)
 	}
 
 	// Let user select which accounts to migrate
 	selected := selectAccountsToMigrate(discovered)
 	if len(selected) == 0 {
-		config.MigrationDone = true
-		return config.saveConfig()
+		return config.finishMigration(nil)
 	}
 
 	// Migrate selected accounts
+	migrated := 0
+	var accounts []Account
 	for _, acc := range selected {
 		migratedAccount, err := migrateAccount(acc)
 		if err != nil {
 			fmt.Printf("❌ Failed to migrate account: %v\n", err)
 			continue
 		}
-		config.Accounts = append(config.Accounts, migratedAccount)
-	}
-
-	// Set first account as current
-	if len(config.Accounts) > 0 {
-		config.CurrentAccount = config.Accounts[0].Name
+		duplicate := config.getAccount(migratedAccount.Name) != nil
+		for _, account := range accounts {
+			duplicate = duplicate || account.Name == migratedAccount.Name
+		}
+		if duplicate {
+			fmt.Printf("❌ Account '%s' already exists; skipped %s\n", migratedAccount.Name, acc.Source)
+			continue
+		}
+		accounts = append(accounts, migratedAccount)
+		migrated++
 	}
 
-	config.MigrationDone = true
-
-	if err := config.saveConfig(); err != nil {
+	if err := config.finishMigration(accounts); err != nil {
 		return fmt.Errorf("failed to save migrated config: %w", err)
 	}
 
-	fmt.Printf("\n✅ Successfully imported %d account(s)!\n", len(config.Accounts))
-	
+	fmt.Printf("\n✅ Successfully imported %d account(s)!\n", migrated)
+
 	fmt.Printf("\n🎯 Next steps:\n")
 	fmt.Printf("   • Use 'krakn list' to see your accounts\n")
 	fmt.Printf("   • Use 'krakn config ~/work work' to set up directory-based switching\n")
@@ -117,9 +209,9 @@ func discoverExistingAccounts() []DiscoveredAccount {
 
 	if globalUser != "" || globalEmail != "" {
 		discovered = append(discovered, DiscoveredAccount{
-			Name:     globalUser,
-			Email:    globalEmail,
-			Source:   "Global Git Config",
+			Name:      globalUser,
+			Email:     globalEmail,
+			Source:    "Global Git Config",
 			Suggested: true,
 		})
 	}
@@ -128,6 +220,9 @@ func discoverExistingAccounts() []DiscoveredAccount {
 	sshAccounts := discoverSSHAccounts()
 	discovered = append(discovered, sshAccounts...)
 
+	// Keys loaded in ssh-agent, including those without a file on disk
+	discovered = append(discovered, discoverAgentAccounts()...)
+
 	return discovered
 }
 
@@ -146,29 +241,26 @@ func discoverSSHAccounts() []DiscoveredAccount {
 		return accounts
 	}
 
-	lines := strings.Split(string(content), "\n")
-	var currentHost string
-	var currentUser string
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		
+	for _, block := range parseSSHConfig(string(content)) {
 		// Match Host github.com-* patterns
-		if strings.HasPrefix(line, "Host github.com-") {
-			currentHost = strings.TrimPrefix(line, "Host ")
-			currentUser = ""
-		} else if strings.HasPrefix(line, "User ") && currentHost != "" {
-			currentUser = strings.TrimPrefix(line, "User ")
-			
-			// Extract account name from host
-			accountName := strings.TrimPrefix(currentHost, "github.com-")
-			if accountName != "" && accountName != "github.com" {
-				accounts = append(accounts, DiscoveredAccount{
-					Username: currentUser,
-					Source:   fmt.Sprintf("SSH Config (%s)", currentHost),
-					Suggested: false,
-				})
+		currentHost := block.alias()
+		if !strings.HasPrefix(currentHost, "github.com-") || block.get("User") == "" {
+			continue
+		}
+
+		// Extract account name from host
+		accountName := strings.TrimPrefix(currentHost, "github.com-")
+		if accountName != "" && accountName != "github.com" {
+			account := DiscoveredAccount{
+				Username:  block.get("User"),
+				Source:    fmt.Sprintf("SSH Config (%s)", currentHost),
+				Suggested: false,
+				SSHHost:   currentHost,
+			}
+			if identityFile := block.get("IdentityFile"); identityFile != "" {
+				account.SSHKey = expandSSHPath(identityFile, &block)
 			}
+			accounts = append(accounts, account)
 		}
 	}
 
@@ -195,7 +287,12 @@ func selectAccountsToMigrate(discovered []DiscoveredAccount) []DiscoveredAccount
 
 	for {
 		fmt.Print("\nEnter your choice(s) separated by commas (e.g., 1,3): ")
-		input, _ := reader.ReadString('\n')
+		input, err := reader.ReadString('\n')
+		if err != nil && input == "" {
+			// No more input, e.g. stdin is not a terminal
+			fmt.Println()
+			return nil
+		}
 		input = strings.TrimSpace(input)
 
 		if input == "0" {
@@ -243,10 +340,10 @@ func migrateAccount(discovered DiscoveredAccount) (Account, error) {
 	} else {
 		fmt.Print("📝 Account name (e.g., 'personal', 'work'): ")
 	}
-	
+
 	input, _ := reader.ReadString('\n')
 	accountName = strings.TrimSpace(input)
-	
+
 	if accountName == "" {
 		if discovered.Username != "" {
 			accountName = discovered.Username
@@ -273,7 +370,7 @@ func migrateAccount(discovered DiscoveredAccount) (Account, error) {
 		}
 		input, _ := reader.ReadString('\n')
 		input = strings.TrimSpace(input)
-		
+
 		if input != "" {
 			username = input
 		} else if discovered.Name != "" {
@@ -281,14 +378,25 @@ func migrateAccount(discovered DiscoveredAccount) (Account, error) {
 		}
 	}
 
-	// Select SSH key
-	sshKey := selectSSHKey(accountName)
+	// Select SSH key; an agent discovery brings its own
+	var sshKey string
+	agentOnly := false
+	if discovered.Agent != nil {
+		var err error
+		sshKey, agentOnly, err = bindAgentKey(*discovered.Agent, "", DefaultProviders["github"].KeySuffix, accountName)
+		if err != nil {
+			return Account{}, err
+		}
+	} else {
+		sshKey = selectSSHKey(accountName)
+	}
 
 	account := Account{
 		Name:     accountName,
 		Email:    email,
 		SSHKey:   sshKey,
 		Username: username,
+		AgentKey: agentOnly,
 	}
 
 	fmt.Printf("✅ Configured account '%s'\n", accountName)
@@ -331,7 +439,7 @@ func selectSSHKey(accountName string) string {
 
 	fmt.Println("\n🔑 SSH Key Options:")
 	fmt.Println("   0. Generate new key later")
-	
+
 	for i, key := range existingKeys {
 		fmt.Printf("   %d. %s", i+1, key)
 		// Highlight suggested key
@@ -378,9 +486,9 @@ func selectSSHKey(accountName string) string {
 func getGitConfigValue(key string, global bool) string {
 	var cmd *exec.Cmd
 	if global {
-		cmd = exec.Command("git", "config", "--global", "--get", key)
+		cmd = traceExec(exec.Command("git", "config", "--global", "--get", key))
 	} else {
-		cmd = exec.Command("git", "config", "--get", key)
+		cmd = traceExec(exec.Command("git", "config", "--get", key))
 	}
 
 	output, err := cmd.Output()
@@ -396,20 +504,17 @@ var migrateCmd = &cobra.Command{
 	Short: "Migrate existing git configuration to krakncat",
 	Long: `Migrate your existing global git configuration to krakncat.
 This command helps you import your current git user.name and user.email
-as your first krakncat account.`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		// Force migration even if already done
-		config, err := loadConfig()
-		if err != nil {
-			return err
-		}
+as your first krakncat account.
 
-		config.MigrationDone = false
-		if err := config.saveConfig(); err != nil {
-			return err
-		}
+Running it again discovers the configuration once more. Discoveries whose
+email, SSH Host alias or SSH key belong to an existing account are shown as
+already imported and are not offered again.
 
-		return checkAndOfferMigration()
+Examples:
+  krakn migrate             # Import the current global identity`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Force migration even if already done
+		return offerMigration(true)
 	},
 }
 
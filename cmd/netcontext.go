@@ -0,0 +1,418 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ContextRule selects an account on a network. Every condition that is set
+// must match: the Wi-Fi SSID, an interface name (e.g. a VPN's "utun*" or
+// "wg0"), or an address of this machine inside a CIDR range.
+type ContextRule struct {
+	Account   string `json:"account"`
+	SSID      string `json:"ssid,omitempty"`
+	Interface string `json:"interface,omitempty"` // Glob, e.g. "tun*"
+	CIDR      string `json:"cidr,omitempty"`
+}
+
+// ContextConfig holds the network rules. In paranoid mode (the default) a
+// matching rule only suggests the account; in auto mode 'krakn watch' switches.
+type ContextConfig struct {
+	Rules []ContextRule `json:"rules,omitempty"`
+	Mode  string        `json:"mode,omitempty"` // "paranoid" (default) or "auto"
+}
+
+// networkInfo is what the current network looks like from this machine
+type networkInfo struct {
+	SSID       string
+	Interfaces []string
+	Addrs      []net.IP
+}
+
+// currentSSID asks the platform's Wi-Fi tooling for the connected network
+func currentSSID() string {
+	var output []byte
+	switch runtime.GOOS {
+	case "darwin":
+		output, _ = exec.Command("networksetup", "-getairportnetwork", "en0").Output()
+		if _, ssid, ok := strings.Cut(string(output), "Current Wi-Fi Network: "); ok {
+			return strings.TrimSpace(ssid)
+		}
+		return ""
+	case "windows":
+		output, _ = exec.Command("netsh", "wlan", "show", "interfaces").Output()
+		for _, line := range strings.Split(string(output), "\n") {
+			key, value, ok := strings.Cut(line, ":")
+			if ok && strings.TrimSpace(key) == "SSID" {
+				return strings.TrimSpace(value)
+			}
+		}
+		return ""
+	}
+
+	if output, err := exec.Command("iwgetid", "-r").Output(); err == nil {
+		return strings.TrimSpace(string(output))
+	}
+	output, _ = exec.Command("nmcli", "-t", "-f", "active,ssid", "dev", "wifi").Output()
+	for _, line := range strings.Split(string(output), "\n") {
+		if ssid, ok := strings.CutPrefix(line, "yes:"); ok {
+			return strings.TrimSpace(ssid)
+		}
+	}
+	return ""
+}
+
+// detectNetwork collects the SSID and the interfaces that are up
+func detectNetwork() *networkInfo {
+	info := &networkInfo{SSID: currentSSID()}
+	interfaces, _ := net.Interfaces()
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		info.Interfaces = append(info.Interfaces, iface.Name)
+		addrs, _ := iface.Addrs()
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				info.Addrs = append(info.Addrs, ipNet.IP)
+			}
+		}
+	}
+	return info
+}
+
+// matches reports whether the rule's conditions all hold on the network
+func (r ContextRule) matches(info *networkInfo) bool {
+	if r.SSID == "" && r.Interface == "" && r.CIDR == "" {
+		return false
+	}
+	if r.SSID != "" && r.SSID != info.SSID {
+		return false
+	}
+	if r.Interface != "" {
+		found := false
+		for _, name := range info.Interfaces {
+			if matched, _ := path.Match(r.Interface, name); matched {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.CIDR != "" {
+		_, network, err := net.ParseCIDR(r.CIDR)
+		if err != nil {
+			return false
+		}
+		found := false
+		for _, ip := range info.Addrs {
+			if network.Contains(ip) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// describe renders a rule's conditions for listings
+func (r ContextRule) describe() string {
+	var parts []string
+	if r.SSID != "" {
+		parts = append(parts, "Wi-Fi "+strconv.Quote(r.SSID))
+	}
+	if r.Interface != "" {
+		parts = append(parts, "interface "+r.Interface)
+	}
+	if r.CIDR != "" {
+		parts = append(parts, "address in "+r.CIDR)
+	}
+	return strings.Join(parts, " + ")
+}
+
+// contextAccount returns the account the first matching network rule selects
+func (c *Config) contextAccount(info *networkInfo) string {
+	if c.Context == nil {
+		return ""
+	}
+	for _, rule := range c.Context.Rules {
+		if rule.matches(info) && c.getAccount(rule.Account) != nil {
+			return rule.Account
+		}
+	}
+	return ""
+}
+
+// suggestedAccount is the account pickers offer by default: the network
+// context when a rule matches, otherwise the schedule
+func (c *Config) suggestedAccount() string {
+	if c.Context != nil && len(c.Context.Rules) > 0 {
+		if name := c.contextAccount(detectNetwork()); name != "" {
+			tracef(traceMatch, "Network rules suggest account '%s'", name)
+			return name
+		}
+	}
+	name := c.scheduledAccount(time.Now())
+	if name != "" {
+		tracef(traceMatch, "The schedule suggests account '%s'", name)
+	}
+	return name
+}
+
+// suggestedChoice returns the 1-based position of the suggested account in
+// config.Accounts, for use as the default answer of account pickers, or 0
+func (c *Config) suggestedChoice() int {
+	name := c.suggestedAccount()
+	for i, account := range c.Accounts {
+		if name != "" && account.Name == name {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// runContextSwitch follows network changes: in auto mode it sets the global
+// identity, in paranoid mode it only prints a suggestion. Like the schedule,
+// it acts only when the detected account changes.
+func runContextSwitch(ctx *watchContext) error {
+	if ctx.Config.Context == nil || len(ctx.Config.Context.Rules) == 0 {
+		return nil
+	}
+	detected := ctx.Config.contextAccount(detectNetwork())
+	if detected == ctx.State.ContextAccount {
+		return nil
+	}
+	ctx.State.ContextAccount = detected
+	if detected == "" || detected == ctx.Config.CurrentAccount {
+		return nil
+	}
+
+	if ctx.Config.Context.Mode != "auto" {
+		fmt.Printf("💡 %s Network suggests '%s': krakn use %s\n", ctx.Now.Format("15:04"), detected, detected)
+		return nil
+	}
+	account := ctx.Config.getAccount(detected)
+	if account.isSealed("email") {
+		return fmt.Errorf("account '%s' has a sealed email and cannot be switched to unattended", detected)
+	}
+	changes, err := switchGlobalAccount(ctx.Config, account, "network")
+	if err != nil {
+		return err
+	}
+	ctx.State.LastSwitch = ctx.Now
+	ctx.State.LastSwitchTo = detected
+	fmt.Printf("🔄 %s Switched to '%s' (network)\n", ctx.Now.Format("15:04"), detected)
+	printGitConfigChanges(globalGitConfigPath(), changes)
+	return nil
+}
+
+var contextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Detect the network and suggest the matching account",
+	Long: `Show the current network (Wi-Fi SSID, interfaces such as a corporate VPN, and
+addresses) and the account its rules select.
+
+In paranoid mode (the default) a match is only a suggestion: it is the default
+answer of account pickers and 'krakn watch' prints it. In auto mode
+'krakn watch' switches the global identity when the network changes.
+
+Examples:
+  krakn context
+  krakn context rules add work --interface "utun*" --cidr 10.20.0.0/16
+  krakn context rules add work --ssid "ACME-Corp"
+  krakn context mode auto`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		info := detectNetwork()
+		ssid := info.SSID
+		if ssid == "" {
+			ssid = "(none)"
+		}
+		fmt.Printf("📶 Wi-Fi: %s\n", ssid)
+		fmt.Printf("🔌 Interfaces: %s\n", strings.Join(info.Interfaces, ", "))
+
+		if config.Context == nil || len(config.Context.Rules) == 0 {
+			fmt.Println("📭 No network rules. Use 'krakn context rules add <account>'.")
+			return nil
+		}
+		detected := config.contextAccount(info)
+		switch {
+		case detected == "":
+			fmt.Println("🤷 No rule matches this network")
+		case detected == config.CurrentAccount:
+			fmt.Printf("✅ Network matches '%s', which is already in use\n", detected)
+		default:
+			fmt.Printf("💡 Network matches '%s'. Switch with 'krakn use %s'\n", detected, detected)
+		}
+		return nil
+	},
+}
+
+var contextRulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "List the network rules",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if config.Context == nil || len(config.Context.Rules) == 0 {
+			fmt.Println("📭 No network rules. Use 'krakn context rules add <account>'.")
+			return nil
+		}
+
+		info := detectNetwork()
+		fmt.Println("🌐 Network rules (first match wins):")
+		for i, rule := range config.Context.Rules {
+			marker := ""
+			if rule.matches(info) {
+				marker = " ◀ matches"
+			}
+			fmt.Printf("   %d. %s → %s%s\n", i+1, rule.describe(), rule.Account, marker)
+		}
+		if config.Context.Mode == "auto" {
+			fmt.Println("🔄 Mode: auto ('krakn watch' switches the global identity)")
+		} else {
+			fmt.Println("🛡️  Mode: paranoid (suggestions only)")
+		}
+		return nil
+	},
+}
+
+var contextRulesAddCmd = &cobra.Command{
+	Use:   "add <account-name>",
+	Short: "Add a network rule",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rule := ContextRule{Account: args[0]}
+		rule.SSID, _ = cmd.Flags().GetString("ssid")
+		rule.Interface, _ = cmd.Flags().GetString("interface")
+		rule.CIDR, _ = cmd.Flags().GetString("cidr")
+		if rule.SSID == "" && rule.Interface == "" && rule.CIDR == "" {
+			return fmt.Errorf("❌ Give at least one of --ssid, --interface or --cidr")
+		}
+		if rule.CIDR != "" {
+			if _, _, err := net.ParseCIDR(rule.CIDR); err != nil {
+				return fmt.Errorf("❌ Invalid CIDR '%s'", rule.CIDR)
+			}
+		}
+		if _, err := path.Match(rule.Interface, ""); err != nil {
+			return fmt.Errorf("❌ Invalid interface pattern '%s'", rule.Interface)
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if config.getAccount(rule.Account) == nil {
+			return config.accountNotFound(rule.Account)
+		}
+		if err := config.update(func(config *Config) error {
+			if config.Context == nil {
+				config.Context = &ContextConfig{}
+			}
+			config.Context.Rules = append(config.Context.Rules, rule)
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("✅ Rule %d: %s → %s\n", len(config.Context.Rules), rule.describe(), rule.Account)
+		return nil
+	},
+}
+
+var contextRulesRemoveCmd = &cobra.Command{
+	Use:   "remove <rule-number>",
+	Short: "Remove a network rule",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || config.Context == nil || n < 1 || n > len(config.Context.Rules) {
+			return fmt.Errorf("❌ No rule %s. See 'krakn context rules'", args[0])
+		}
+		rule := config.Context.Rules[n-1]
+		if err := config.update(func(config *Config) error {
+			// Rules may have been renumbered by another krakn meanwhile
+			if config.Context == nil {
+				return nil
+			}
+			for i, existing := range config.Context.Rules {
+				if existing == rule {
+					config.Context.Rules = append(config.Context.Rules[:i], config.Context.Rules[i+1:]...)
+					break
+				}
+			}
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("✅ Removed rule %s → %s\n", rule.describe(), rule.Account)
+		return nil
+	},
+}
+
+var contextModeCmd = &cobra.Command{
+	Use:       "mode <paranoid|auto>",
+	Short:     "Only suggest accounts (paranoid) or let 'krakn watch' switch (auto)",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"paranoid", "auto"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[0] != "paranoid" && args[0] != "auto" {
+			return fmt.Errorf("❌ Unknown mode '%s'. Use paranoid or auto", args[0])
+		}
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if err := config.update(func(config *Config) error {
+			if config.Context == nil {
+				config.Context = &ContextConfig{}
+			}
+			config.Context.Mode = args[0]
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		if args[0] == "auto" {
+			fmt.Println("✅ 'krakn watch' will switch the global identity when the network changes")
+		} else {
+			fmt.Println("🛡️  Network rules only suggest accounts; nothing switches automatically")
+		}
+		return nil
+	},
+}
+
+func init() {
+	contextRulesAddCmd.Flags().String("ssid", "", "Wi-Fi network name")
+	contextRulesAddCmd.Flags().String("interface", "", "Interface name or glob, e.g. utun* for a VPN")
+	contextRulesAddCmd.Flags().String("cidr", "", "Address range this machine has an address in, e.g. 10.0.0.0/8")
+	contextRulesCmd.AddCommand(contextRulesAddCmd)
+	contextRulesCmd.AddCommand(contextRulesRemoveCmd)
+	contextCmd.AddCommand(contextRulesCmd)
+	contextCmd.AddCommand(contextModeCmd)
+	RootCmd.AddCommand(contextCmd)
+	registerWatchTask(watchTask{Name: "Network context", Run: runContextSwitch})
+}
@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// NotifyConfig enables desktop notifications for the listed severities
+// ("info", "warn", "error"). Terminal output is printed regardless.
+type NotifyConfig struct {
+	Levels []string `json:"levels,omitempty"`
+}
+
+// notifyLevels are the severities a notification can have, in doctor's terms
+var notifyLevels = []string{doctorInfo, doctorWarn, doctorError}
+
+// parseNotifyLevels accepts a comma-separated list such as "warn,error"
+func parseNotifyLevels(spec string) ([]string, error) {
+	var levels []string
+	for _, level := range strings.Split(spec, ",") {
+		level = strings.ToLower(strings.TrimSpace(level))
+		if level == "warning" {
+			level = doctorWarn
+		}
+		if !containsString(notifyLevels, level) {
+			return nil, fmt.Errorf("❌ Unknown severity '%s'. Use info, warn or error", level)
+		}
+		if !containsString(levels, level) {
+			levels = append(levels, level)
+		}
+	}
+	return levels, nil
+}
+
+// notifyEnabled reports whether findings of a severity raise a notification
+func (c *Config) notifyEnabled(level string) bool {
+	return c.Notify != nil && containsString(c.Notify.Levels, level)
+}
+
+// notify raises a desktop notification when the severity is enabled, and
+// posts warnings and errors to the webhook as violations. Failing to notify
+// never fails the caller, so the error is only worth showing when the user
+// asked for a notification explicitly.
+func (c *Config) notify(level, title, message string) error {
+	if level == doctorWarn || level == doctorError {
+		c.postWebhook(webhookEvent{Event: "violation", Level: level, Text: title + ": " + message})
+	}
+	if !c.notifyEnabled(level) || notificationsSuppressed() {
+		return nil
+	}
+	return desktopNotify(level, title, message)
+}
+
+// desktopNotify shows a notification with the platform's own tooling:
+// notify-send on Linux, osascript on macOS and a PowerShell toast on Windows
+func desktopNotify(level, title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// Passing the text as arguments avoids quoting it for AppleScript
+		cmd = exec.Command("osascript",
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			title, message)
+	case "windows":
+		script := `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $template.GetElementsByTagName('text')
+$text.Item(0).AppendChild($template.CreateTextNode($env:KRAKN_NOTIFY_TITLE)) | Out-Null
+$text.Item(1).AppendChild($template.CreateTextNode($env:KRAKN_NOTIFY_MESSAGE)) | Out-Null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('krakn').Show([Windows.UI.Notifications.ToastNotification]::new($template))`
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+		cmd.Env = append(os.Environ(), "KRAKN_NOTIFY_TITLE="+title, "KRAKN_NOTIFY_MESSAGE="+message)
+	default:
+		urgency := "normal"
+		switch level {
+		case doctorInfo:
+			urgency = "low"
+		case doctorError:
+			urgency = "critical"
+		}
+		cmd = exec.Command("notify-send", "--app-name=krakn", "--urgency="+urgency, title, message)
+	}
+
+	if _, err := exec.LookPath(cmd.Args[0]); err != nil {
+		return fmt.Errorf("%s is not installed", cmd.Args[0])
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		if len(output) > 0 {
+			return fmt.Errorf("%s: %s", cmd.Args[0], firstLine(string(output)))
+		}
+		return fmt.Errorf("%s: %w", cmd.Args[0], err)
+	}
+	return nil
+}
+
+// repoScanInterval is how often 'krakn watch' checks the repositories below
+// mapped directories; walking them every minute would be wasteful
+const repoScanInterval = 15 * time.Minute
+
+// runRepoIdentityCheck runs doctor's repository checks on every repository
+// below a mapped directory and reports new problems once, in the terminal and
+// as a notification
+func runRepoIdentityCheck(ctx *watchContext) error {
+	if len(ctx.Config.Directories) == 0 || ctx.Now.Sub(ctx.State.LastRepoScan) < repoScanInterval {
+		return nil
+	}
+	ctx.State.LastRepoScan = ctx.Now
+
+	var current []string
+	notifyFailed := false
+	for _, mapping := range ctx.Config.Directories {
+		repos, _ := partitionForeignRepos(findGitRepos(mapping.Path))
+		for _, repo := range repos {
+			for _, finding := range checkRepoIdentity(&doctorContext{Config: ctx.Config, RepoRoot: repo}) {
+				if finding.Level != doctorWarn && finding.Level != doctorError {
+					continue
+				}
+				key := repo + ": " + finding.Message
+				if containsString(current, key) {
+					continue
+				}
+				current = append(current, key)
+				if containsString(ctx.State.Reported, key) {
+					continue
+				}
+				fmt.Printf("%s %s %s: %s\n", finding.icon(), ctx.Now.Format("15:04"), contractHomePath(repo), finding.Message)
+				if err := ctx.Config.notify(finding.Level, "krakn: "+contractHomePath(repo), finding.Message); err != nil && !notifyFailed {
+					fmt.Printf("⚠️  Could not show notification: %v\n", err)
+					notifyFailed = true
+				}
+			}
+		}
+	}
+	// Forgetting fixed problems lets them be reported again if they come back
+	ctx.State.Reported = current
+	return nil
+}
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Show desktop notifications for identity mismatches",
+	Long: `Raise desktop notifications (notify-send, osascript or a Windows toast) when
+'krakn watch' finds a repository with the wrong identity or the pre-push hook
+of 'krakn guard' sees a push with the wrong key. Terminal output is printed
+either way; notifications are raised for the enabled severities only.
+
+Examples:
+  krakn notify                      # Show the current settings
+  krakn notify on                   # Warnings and errors
+  krakn notify on --levels error
+  krakn notify test`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if config.Notify == nil || len(config.Notify.Levels) == 0 {
+			fmt.Println("🔕 Desktop notifications are off. Turn them on with 'krakn notify on'.")
+			return nil
+		}
+		fmt.Printf("🔔 Desktop notifications for: %s\n", strings.Join(config.Notify.Levels, ", "))
+		return nil
+	},
+}
+
+var notifyOnCmd = &cobra.Command{
+	Use:   "on",
+	Short: "Turn desktop notifications on",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spec, _ := cmd.Flags().GetString("levels")
+		levels, err := parseNotifyLevels(spec)
+		if err != nil {
+			return err
+		}
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if err := config.update(func(config *Config) error {
+			config.Notify = &NotifyConfig{Levels: levels}
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("🔔 Desktop notifications for: %s\n", strings.Join(levels, ", "))
+		fmt.Println("💡 Check that they appear with 'krakn notify test'")
+		return nil
+	},
+}
+
+var notifyOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Turn desktop notifications off",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if err := config.update(func(config *Config) error {
+			config.Notify = nil
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Println("🔕 Desktop notifications are off")
+		return nil
+	},
+}
+
+var notifyTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Send a test notification",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := desktopNotify(doctorInfo, "krakn", "Notifications are working"); err != nil {
+			return fmt.Errorf("❌ Could not show a notification: %v", err)
+		}
+		fmt.Println("✅ Sent a test notification")
+		return nil
+	},
+}
+
+func init() {
+	notifyOnCmd.Flags().String("levels", "warn,error", "Severities that raise a notification: info, warn, error")
+	notifyCmd.AddCommand(notifyOnCmd)
+	notifyCmd.AddCommand(notifyOffCmd)
+	notifyCmd.AddCommand(notifyTestCmd)
+	RootCmd.AddCommand(notifyCmd)
+	registerWatchTask(watchTask{Name: "Repository identities", Run: runRepoIdentityCheck})
+}
@@ -0,0 +1,396 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// maxLoggedOperations bounds how many operations keep their file snapshots
+const maxLoggedOperations = 100
+
+// Operation is a single mutating krakn invocation recorded in the history log
+type Operation struct {
+	ID      string       `json:"id"`
+	Command string       `json:"command"`
+	Time    time.Time    `json:"time"`
+	Files   []FileChange `json:"files"`
+	Trace   []TraceStep  `json:"trace,omitempty"` // Decisions and side effects, see 'krakn explain'
+}
+
+// FileChange records the state of one file before and after an operation
+type FileChange struct {
+	Path       string `json:"path"`
+	Existed    bool   `json:"existed"`               // Whether the file existed before the operation
+	BeforeHash string `json:"before_hash,omitempty"` // Empty when the file did not exist
+	AfterHash  string `json:"after_hash,omitempty"`  // Empty when the operation deleted the file
+	Snapshot   string `json:"snapshot,omitempty"`    // Copy of the previous content
+	// NotRevertible is set for private keys: they are never copied into the
+	// history, so a deleted or rotated key is really gone
+	NotRevertible bool `json:"not_revertible,omitempty"`
+}
+
+// action describes what the operation did to the file
+func (c FileChange) action() string {
+	action := "modified"
+	if !c.Existed {
+		action = "created"
+	} else if c.AfterHash == "" {
+		action = "deleted"
+	}
+	if c.NotRevertible {
+		action += ", not revertible"
+	}
+	return action
+}
+
+// isPrivateKey reports whether data is a private key, which must not be
+// copied anywhere krakn does not need it
+func isPrivateKey(data []byte) bool {
+	return bytes.Contains(data, []byte("PRIVATE KEY"))
+}
+
+// operationCommand describes the running command for the log: the command
+// path and the names of the flags given. Arguments and flag values are left
+// out, since they can be tokens or passphrases.
+func operationCommand() string {
+	cmd, _, err := RootCmd.Find(os.Args[1:])
+	if err != nil || cmd == nil {
+		return RootCmd.Name()
+	}
+	parts := []string{cmd.CommandPath()}
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		parts = append(parts, "--"+flag.Name)
+	})
+	return strings.Join(parts, " ")
+}
+
+// currentOperation collects file snapshots for the running command
+var currentOperation *pendingOperation
+
+type pendingOperation struct {
+	files  []string
+	before map[string][]byte // nil value means the file did not exist
+}
+
+func getHistoryDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".krakncat", "history")
+}
+
+func getHistoryLogPath() string {
+	return filepath.Join(getHistoryDir(), "log.jsonl")
+}
+
+// trackFile snapshots a file before the running command modifies it.
+// It must be called before every write so the change can be reverted later.
+func trackFile(path string) {
+	if path == "" {
+		return
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	if currentOperation == nil {
+		currentOperation = &pendingOperation{before: map[string][]byte{}}
+	}
+	if containsString(currentOperation.files, path) {
+		return
+	}
+
+	currentOperation.files = append(currentOperation.files, path)
+	tracef(traceWrite, "%s", contractHomePath(path))
+	if data, err := os.ReadFile(path); err == nil {
+		currentOperation.before[path] = data
+	}
+}
+
+// discardOperation forgets the snapshots of the running command, e.g. when the
+// history itself is being deleted
+func discardOperation() {
+	currentOperation = nil
+}
+
+// finishOperation compares tracked files with their snapshots and appends an
+// entry to the history log when anything actually changed.
+func finishOperation() error {
+	pending := currentOperation
+	currentOperation = nil
+	trace := takeTrace()
+	if pending == nil || len(pending.files) == 0 {
+		return nil
+	}
+
+	op := Operation{
+		ID:      newOperationID(),
+		Command: operationCommand(),
+		Time:    time.Now(),
+		Trace:   trace,
+	}
+
+	snapshotDir := filepath.Join(getHistoryDir(), op.ID)
+	for i, path := range pending.files {
+		before, existed := pending.before[path]
+		change := FileChange{Path: path, Existed: existed}
+		if existed {
+			change.BeforeHash = hashBytes(before)
+		}
+		if after, err := os.ReadFile(path); err == nil {
+			change.AfterHash = hashBytes(after)
+		}
+		if change.BeforeHash == change.AfterHash {
+			continue
+		}
+
+		if existed && isPrivateKey(before) {
+			change.NotRevertible = true
+		} else if existed {
+			if err := os.MkdirAll(snapshotDir, 0700); err != nil {
+				return err
+			}
+			change.Snapshot = filepath.Join(snapshotDir, fmt.Sprintf("%d.before", i))
+			if err := os.WriteFile(change.Snapshot, before, 0600); err != nil {
+				return err
+			}
+		}
+		op.Files = append(op.Files, change)
+	}
+
+	if len(op.Files) == 0 {
+		return nil
+	}
+
+	if err := appendOperation(op); err != nil {
+		return err
+	}
+	if settings := auditSettings(); settings != nil {
+		if err := writeAuditRecord(settings, op); err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+	}
+	return pruneOperations()
+}
+
+func appendOperation(op Operation) error {
+	if err := os.MkdirAll(getHistoryDir(), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(getHistoryLogPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// loadOperations returns all recorded operations, oldest first
+func loadOperations() ([]Operation, error) {
+	f, err := os.Open(getHistoryLogPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var ops []Operation
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	for scanner.Scan() {
+		var op Operation
+		if err := json.Unmarshal(scanner.Bytes(), &op); err != nil {
+			continue
+		}
+		ops = append(ops, op)
+	}
+	return ops, scanner.Err()
+}
+
+// pruneOperations drops the oldest operations beyond maxLoggedOperations
+func pruneOperations() error {
+	ops, err := loadOperations()
+	if err != nil || len(ops) <= maxLoggedOperations {
+		return err
+	}
+
+	stale := ops[:len(ops)-maxLoggedOperations]
+	for _, op := range stale {
+		os.RemoveAll(filepath.Join(getHistoryDir(), op.ID))
+	}
+
+	var lines []string
+	for _, op := range ops[len(stale):] {
+		data, err := json.Marshal(op)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, string(data))
+	}
+	return os.WriteFile(getHistoryLogPath(), []byte(strings.Join(lines, "\n")+"\n"), 0600)
+}
+
+func newOperationID() string {
+	suffix := make([]byte, 2)
+	rand.Read(suffix)
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hashFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return hashBytes(data)
+}
+
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show the history of operations that modified files",
+	Long: `Show the history of krakn operations that modified configuration files.
+Each entry can be undone individually with 'krakn revert <op-id>' and
+explained step by step with 'krakn explain <op-id>'.
+
+Commands are logged without their arguments and flag values, which can be
+tokens. Private keys are never copied into the history: changes to them are
+listed as not revertible, so a removed or rotated key does not linger.
+
+Examples:
+  krakn log                 # Recent operations
+  krakn log -n 50           # The last 50 operations`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		ops, err := loadOperations()
+		if err != nil {
+			return fmt.Errorf("failed to read history: %w", err)
+		}
+
+		if len(ops) == 0 {
+			fmt.Println("📜 No operations recorded yet.")
+			return nil
+		}
+
+		fmt.Println("📜 Operation history (newest first):")
+		fmt.Println()
+		shown := 0
+		for i := len(ops) - 1; i >= 0 && (limit <= 0 || shown < limit); i-- {
+			op := ops[i]
+			fmt.Printf("🆔 %s  %s\n", op.ID, op.Time.Format("2006-01-02 15:04:05"))
+			fmt.Printf("   💻 %s\n", op.Command)
+			for _, change := range op.Files {
+				fmt.Printf("   📄 %s (%s)\n", change.Path, change.action())
+			}
+			fmt.Println()
+			shown++
+		}
+
+		return nil
+	},
+}
+
+var revertCmd = &cobra.Command{
+	Use:   "revert [op-id]",
+	Short: "Undo the file changes of a recorded operation",
+	Long: `Undo the file changes made by a single recorded operation.
+
+Files that were modified again after the operation are not touched unless
+--force is given, so unrelated later changes are never lost silently.
+
+Examples:
+  krakn log                           # Find the operation id
+  krakn revert 20250101-120000-ab12   # Undo that operation`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opID := args[0]
+		force, _ := cmd.Flags().GetBool("force")
+
+		ops, err := loadOperations()
+		if err != nil {
+			return fmt.Errorf("failed to read history: %w", err)
+		}
+
+		var op *Operation
+		for i := range ops {
+			if ops[i].ID == opID {
+				op = &ops[i]
+				break
+			}
+		}
+		if op == nil {
+			return fmt.Errorf("❌ Operation '%s' not found. Use 'krakn log' to list operations", opID)
+		}
+
+		// Refuse to clobber files that changed after the operation
+		var conflicts []string
+		for _, change := range op.Files {
+			if !change.NotRevertible && hashFile(change.Path) != change.AfterHash {
+				conflicts = append(conflicts, change.Path)
+			}
+		}
+		if len(conflicts) > 0 && !force {
+			return fmt.Errorf("❌ These files changed after operation %s:\n   %s\n   Use --force to revert anyway",
+				opID, strings.Join(conflicts, "\n   "))
+		}
+
+		for _, change := range op.Files {
+			if change.NotRevertible {
+				fmt.Printf("⚠️  Not restored: %s (private keys are not kept in the history)\n", change.Path)
+				continue
+			}
+			trackFile(change.Path)
+			if !change.Existed {
+				if err := os.Remove(change.Path); err != nil && !os.IsNotExist(err) {
+					return fmt.Errorf("failed to remove %s: %w", change.Path, err)
+				}
+				fmt.Printf("🗑️  Removed: %s\n", change.Path)
+				continue
+			}
+
+			data, err := os.ReadFile(change.Snapshot)
+			if err != nil {
+				return fmt.Errorf("snapshot for %s is missing: %w", change.Path, err)
+			}
+			mode := os.FileMode(0600)
+			if info, err := os.Stat(change.Path); err == nil {
+				mode = info.Mode().Perm()
+			}
+			if err := withFileLock(change.Path, func() error { return writeFileAtomic(change.Path, data, mode) }); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", change.Path, err)
+			}
+			fmt.Printf("↩️  Restored: %s\n", change.Path)
+		}
+
+		fmt.Printf("✅ Reverted operation %s (%s)\n", op.ID, op.Command)
+		return nil
+	},
+}
+
+func init() {
+	logCmd.Flags().IntP("limit", "n", 20, "Number of operations to show (0 for all)")
+	revertCmd.Flags().Bool("force", false, "Revert even if files changed after the operation")
+	RootCmd.AddCommand(logCmd)
+	RootCmd.AddCommand(revertCmd)
+}
@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// samlErrorURL finds the authorization link in git's SAML SSO rejection message
+var samlErrorURL = regexp.MustCompile(`https://\S+/sso\S*`)
+
+// orgSSOFindings checks that an account's token and SSH key are authorized
+// for an organization that enforces SAML single sign-on
+func orgSSOFindings(config *Config, account *Account, org string) []doctorFinding {
+	label := fmt.Sprintf("%s/%s", account.Name, org)
+
+	client, err := newGitHubClient(config, account)
+	if err != nil {
+		return []doctorFinding{{Level: doctorInfo, Message: fmt.Sprintf("%s: token not checked (%v)", label, strings.TrimPrefix(err.Error(), "❌ "))}}
+	}
+	if err := client.verifyFeature(findGitHubFeature("orgs")); err != nil {
+		return []doctorFinding{{Level: doctorWarn, Message: fmt.Sprintf("%s: %s", label, strings.TrimPrefix(err.Error(), "❌ "))}}
+	}
+
+	// Token: the membership endpoint answers 403 with X-GitHub-SSO when the
+	// token is not authorized for the organization
+	var membership struct {
+		State string `json:"state"`
+		Role  string `json:"role"`
+	}
+	err = client.get("/user/memberships/orgs/"+url.PathEscape(org), &membership)
+	var apiErr *githubAPIError
+	switch {
+	case err == nil:
+	case errors.As(err, &apiErr) && apiErr.SSO != "":
+		finding := doctorFinding{Level: doctorError, Message: fmt.Sprintf("%s: token is not authorized for SAML SSO", label)}
+		if link := apiErr.ssoURL(); link != "" {
+			finding.Hint = "Authorize it at " + link
+		} else {
+			finding.Hint = "Authorize it under https://github.com/settings/tokens → Configure SSO"
+		}
+		return []doctorFinding{finding}
+	case errors.As(err, &apiErr) && apiErr.Status == 404:
+		return []doctorFinding{{
+			Level:   doctorWarn,
+			Message: fmt.Sprintf("%s: not a member, or the token cannot read organization memberships", label),
+			Hint:    "Classic tokens need read:org; fine-grained tokens need the organization 'Members: read' permission",
+		}}
+	default:
+		return []doctorFinding{{Level: doctorWarn, Message: fmt.Sprintf("%s: %v", label, err)}}
+	}
+
+	findings := []doctorFinding{{Level: doctorOK, Message: fmt.Sprintf("%s: token authorized (%s, %s)", label, membership.Role, membership.State)}}
+
+	// SSH key: try to read a private repository of the organization. GitHub
+	// rejects keys that are not authorized for SSO with an explanatory message.
+	var repos []struct {
+		FullName string `json:"full_name"`
+	}
+	if err := client.get("/orgs/"+url.PathEscape(org)+"/repos?type=private&per_page=1", &repos); err != nil || len(repos) == 0 {
+		return append(findings, doctorFinding{Level: doctorInfo, Message: fmt.Sprintf("%s: no private repository to check the SSH key against", label)})
+	}
+
+	user, _, _ := sshEndpoint(account)
+	remote := fmt.Sprintf("%s@%s:%s.git", user, account.GetSSHHost(), repos[0].FullName)
+	cmd := traceExec(exec.Command("git", "ls-remote", remote, "HEAD"))
+	cmd.Env = append(os.Environ(), "GIT_SSH_COMMAND=ssh -o BatchMode=yes -o ConnectTimeout=10", "GIT_TERMINAL_PROMPT=0")
+	output, err := cmd.CombinedOutput()
+	switch {
+	case err == nil:
+		findings = append(findings, doctorFinding{Level: doctorOK, Message: fmt.Sprintf("%s: SSH key authorized (read %s)", label, repos[0].FullName)})
+	case strings.Contains(string(output), "SAML SSO"):
+		finding := doctorFinding{
+			Level:   doctorError,
+			Message: fmt.Sprintf("%s: SSH key %s is not authorized for SAML SSO", label, contractHomePath(account.SSHKey)),
+			Hint:    "Authorize it under https://github.com/settings/keys → Configure SSO",
+		}
+		if link := samlErrorURL.FindString(string(output)); link != "" {
+			finding.Hint = "Authorize it at " + link
+		}
+		findings = append(findings, finding)
+	default:
+		findings = append(findings, doctorFinding{
+			Level:   doctorWarn,
+			Message: fmt.Sprintf("%s: could not read %s over SSH: %s", label, repos[0].FullName, firstLine(strings.TrimSpace(string(output)))),
+		})
+	}
+	return findings
+}
+
+// checkOrgSSO verifies SSO authorization for every organization mapped to an account
+func checkOrgSSO(ctx *doctorContext) []doctorFinding {
+	if ctx.offline() {
+		return nil
+	}
+	var findings []doctorFinding
+	for i := range ctx.Config.Accounts {
+		account := &ctx.Config.Accounts[i]
+		for _, org := range account.Orgs {
+			findings = append(findings, orgSSOFindings(ctx.Config, account, org)...)
+		}
+	}
+	return findings
+}
+
+var orgCmd = &cobra.Command{
+	Use:   "org",
+	Short: "Map GitHub organizations to accounts and check their access",
+	Long: `Record which GitHub organizations an account works with, so krakn can verify
+access to them, such as SAML single sign-on authorization. Organization
+administrators can also audit the SSH keys of all members.
+
+Examples:
+  krakn org map work acme
+  krakn org list
+  krakn org sso work
+  krakn org audit acme`,
+}
+
+var orgMapCmd = &cobra.Command{
+	Use:   "map <account-name> <org>...",
+	Short: "Associate GitHub organizations with an account",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+
+		for _, org := range args[1:] {
+			if containsString(account.Orgs, org) {
+				fmt.Printf("ℹ️  '%s' is already mapped to '%s'\n", org, account.Name)
+				continue
+			}
+			account.Orgs = append(account.Orgs, org)
+			fmt.Printf("✅ Mapped organization '%s' to account '%s'\n", org, account.Name)
+		}
+		if err := config.addAccount(*account); err != nil {
+			return errSaveConfig(err)
+		}
+		return nil
+	},
+}
+
+var orgUnmapCmd = &cobra.Command{
+	Use:   "unmap <account-name> <org>",
+	Short: "Remove an organization from an account",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+
+		for i, org := range account.Orgs {
+			if org == args[1] {
+				account.Orgs = append(account.Orgs[:i], account.Orgs[i+1:]...)
+				if err := config.addAccount(*account); err != nil {
+					return errSaveConfig(err)
+				}
+				fmt.Printf("✅ Unmapped organization '%s' from account '%s'\n", args[1], account.Name)
+				return nil
+			}
+		}
+		return fmt.Errorf("❌ Organization '%s' is not mapped to account '%s'", args[1], account.Name)
+	},
+}
+
+var orgListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show the organizations mapped to each account",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		found := false
+		for _, account := range config.Accounts {
+			if len(account.Orgs) == 0 {
+				continue
+			}
+			found = true
+			fmt.Printf("🏢 %s: %s\n", account.Name, strings.Join(account.Orgs, ", "))
+		}
+		if !found {
+			fmt.Println("📭 No organizations mapped. Use 'krakn org map <account> <org>'.")
+		}
+		return nil
+	},
+}
+
+var orgSSOCmd = &cobra.Command{
+	Use:   "sso [account-name]",
+	Short: "Check that tokens and SSH keys are authorized for SAML SSO",
+	Long: `Check every mapped organization that enforces SAML single sign-on. Both the
+account's API token and its SSH key must be authorized for the organization;
+when one is not, the authorization URL is printed.
+
+Examples:
+  krakn org sso          # Check all accounts
+  krakn org sso work`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if offlineMode {
+			return fmt.Errorf("❌ SSO checks need the GitHub API and cannot run with --offline")
+		}
+
+		problems := 0
+		checked := false
+		for i := range config.Accounts {
+			account := &config.Accounts[i]
+			if len(args) == 1 && account.Name != args[0] || len(account.Orgs) == 0 {
+				continue
+			}
+			checked = true
+
+			spin := startSpinner("Checking organizations of " + account.Name)
+			var findings []doctorFinding
+			for _, org := range account.Orgs {
+				findings = append(findings, orgSSOFindings(config, account, org)...)
+			}
+			spin.Stop()
+
+			for _, finding := range findings {
+				fmt.Printf("%s %s\n", finding.icon(), finding.Message)
+				if finding.Hint != "" {
+					fmt.Printf("   💡 %s\n", finding.Hint)
+				}
+				if finding.Level == doctorWarn || finding.Level == doctorError {
+					problems++
+				}
+			}
+		}
+
+		if !checked {
+			fmt.Println("📭 No organizations mapped. Use 'krakn org map <account> <org>'.")
+			return nil
+		}
+		if problems > 0 {
+			return fmt.Errorf("❌ %d organization check(s) failed", problems)
+		}
+		return nil
+	},
+}
+
+func init() {
+	registerDoctorCheck(doctorCheck{Name: "Organization SSO", Run: checkOrgSSO})
+	orgCmd.AddCommand(orgMapCmd)
+	orgCmd.AddCommand(orgUnmapCmd)
+	orgCmd.AddCommand(orgListCmd)
+	orgCmd.AddCommand(orgSSOCmd)
+	RootCmd.AddCommand(orgCmd)
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+)
+
+// auditedKey is an SSH key registered by an organization member
+type auditedKey struct {
+	ID                int64  `json:"id"`
+	Type              string `json:"type"`
+	Fingerprint       string `json:"fingerprint"`
+	LegacyFingerprint string `json:"-"`              // MD5 form, reported by some GitHub endpoints
+	Weak              string `json:"weak,omitempty"` // Why the key violates policy, empty when it does not
+}
+
+// memberAudit is the key inventory of one organization member
+type memberAudit struct {
+	Login string       `json:"login"`
+	Keys  []auditedKey `json:"keys"`
+	Error string       `json:"error,omitempty"`
+}
+
+// orgAPIClient authenticates for an organization: through its GitHub App when
+// one is configured, otherwise with the personal token of accountName
+func orgAPIClient(config *Config, org, accountName string) (*githubClient, string, error) {
+	if app := config.getOrgApp(org); app != nil && accountName == "" {
+		client, err := newAppClient(app)
+		return client, "GitHub App " + app.AppID, err
+	}
+
+	if accountName == "" {
+		for _, account := range config.Accounts {
+			if containsString(account.Orgs, org) {
+				accountName = account.Name
+				break
+			}
+		}
+	}
+	if accountName == "" {
+		return nil, "", fmt.Errorf("❌ No GitHub App or account for '%s'. Use 'krakn org app set %s' or --account", org, org)
+	}
+	account := config.getAccount(accountName)
+	if account == nil {
+		return nil, "", config.accountNotFound(accountName)
+	}
+	client, err := requireGitHubFeature(config, account, "orgs")
+	return client, "token of '" + account.Name + "'", err
+}
+
+// auditOrgMembers fetches the public SSH keys of every organization member
+func auditOrgMembers(client *githubClient, org string) ([]memberAudit, error) {
+	var members []struct {
+		Login string `json:"login"`
+	}
+	if err := client.getAll("/orgs/"+url.PathEscape(org)+"/members?per_page=100", &members); err != nil {
+		return nil, fmt.Errorf("failed to list members of '%s': %w", org, err)
+	}
+
+	audits := make([]memberAudit, len(members))
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, 8)
+	for i, member := range members {
+		wg.Add(1)
+		go func(i int, login string) {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			audit := memberAudit{Login: login}
+			var keys []struct {
+				ID  int64  `json:"id"`
+				Key string `json:"key"`
+			}
+			if err := client.getAll("/users/"+url.PathEscape(login)+"/keys?per_page=100", &keys); err != nil {
+				audit.Error = err.Error()
+			}
+			for _, k := range keys {
+				parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(k.Key))
+				if err != nil {
+					continue
+				}
+				audit.Keys = append(audit.Keys, auditedKey{
+					ID:                k.ID,
+					Type:              parsed.Type(),
+					Fingerprint:       ssh.FingerprintSHA256(parsed),
+					LegacyFingerprint: ssh.FingerprintLegacyMD5(parsed),
+					Weak:              weakKeyReason(parsed),
+				})
+			}
+			audits[i] = audit
+		}(i, member.Login)
+	}
+	wg.Wait()
+
+	sort.Slice(audits, func(i, j int) bool { return audits[i].Login < audits[j].Login })
+	return audits, nil
+}
+
+var orgAuditCmd = &cobra.Command{
+	Use:   "audit <org>",
+	Short: "Inventory the SSH keys of all organization members",
+	Long: `List every member of an organization with their registered SSH keys and flag
+keys that violate policy (DSA, RSA below 2048 bits) and members without keys.
+
+Authentication uses the organization's GitHub App (see 'krakn org app'), so no
+personal access token is needed. Without an app, the token of --account or of
+the account the organization is mapped to is used.
+
+Examples:
+  krakn org audit acme
+  krakn org audit acme --account work`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		org := args[0]
+		accountName, _ := cmd.Flags().GetString("account")
+		verbose, _ := cmd.Flags().GetBool("verbose")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		spin := startSpinner("Authenticating for " + org)
+		client, via, err := orgAPIClient(config, org, accountName)
+		if err != nil {
+			spin.Stop()
+			return err
+		}
+		spin.Update("Fetching members and keys of " + org)
+		audits, err := auditOrgMembers(client, org)
+		spin.Stop()
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("🏢 %s: %d members (via %s)\n", org, len(audits), via)
+		weak, keyless := 0, 0
+		for _, audit := range audits {
+			var issues []string
+			if audit.Error != "" {
+				issues = append(issues, "❌ "+audit.Error)
+			} else if len(audit.Keys) == 0 {
+				issues = append(issues, "⚠️  No SSH keys registered")
+				keyless++
+			}
+			hasWeak := false
+			for _, key := range audit.Keys {
+				if key.Weak != "" {
+					issues = append(issues, fmt.Sprintf("⚠️  %s %s: %s", key.Type, key.Fingerprint, key.Weak))
+					hasWeak = true
+				}
+			}
+			if hasWeak {
+				weak++
+			}
+
+			if len(issues) == 0 {
+				if verbose {
+					fmt.Printf("   ✅ %s (%d keys)\n", audit.Login, len(audit.Keys))
+				}
+				continue
+			}
+			fmt.Printf("   👤 %s\n", audit.Login)
+			for _, issue := range issues {
+				fmt.Printf("      %s\n", issue)
+			}
+		}
+
+		fmt.Println()
+		if weak == 0 && keyless == 0 {
+			fmt.Println("🎉 No policy violations found")
+		} else {
+			fmt.Printf("🔎 %d members with weak keys, %d without keys\n", weak, keyless)
+		}
+		return nil
+	},
+}
+
+func init() {
+	orgAuditCmd.Flags().String("account", "", "Use this account's token instead of the organization's GitHub App")
+	orgAuditCmd.Flags().BoolP("verbose", "v", false, "Also list members without problems")
+	orgCmd.AddCommand(orgAuditCmd)
+}
@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// policyViolation is one finding of 'krakn org report'
+type policyViolation struct {
+	Login  string `json:"login"`
+	Kind   string `json:"kind"` // "weak-key", "old-key", "no-keys" or "email"
+	Detail string `json:"detail"`
+}
+
+// orgReportPolicy configures what 'krakn org report' flags
+type orgReportPolicy struct {
+	Domain       string        // Corporate email domain; empty disables email checks
+	AllowNoreply bool          // Accept <id>+<login>@users.noreply.github.com
+	MaxKeyAge    time.Duration // Zero disables key age checks
+	Since        time.Time     // Oldest commit considered
+	Repos        int           // Most recently pushed repositories to inspect
+}
+
+// keyAuthorizationDates returns when each SSH key was authorized for the
+// organization's SAML SSO, keyed by fingerprint. Only SSO organizations expose
+// this, and only to owners, so an empty map is normal.
+func keyAuthorizationDates(client *githubClient, org string) map[string]time.Time {
+	var authorizations []struct {
+		Type         string    `json:"credential_type"`
+		Fingerprint  string    `json:"fingerprint"`
+		AuthorizedAt time.Time `json:"credential_authorized_at"`
+	}
+	dates := map[string]time.Time{}
+	if err := client.getAll("/orgs/"+url.PathEscape(org)+"/credential-authorizations?per_page=100", &authorizations); err != nil {
+		return dates
+	}
+	for _, auth := range authorizations {
+		if auth.Type == "SSH key" && auth.Fingerprint != "" {
+			dates[normalizeFingerprint(auth.Fingerprint)] = auth.AuthorizedAt
+		}
+	}
+	return dates
+}
+
+// normalizeFingerprint makes SHA256 and legacy MD5 fingerprints comparable
+func normalizeFingerprint(fingerprint string) string {
+	fingerprint = strings.TrimPrefix(fingerprint, "SHA256:")
+	fingerprint = strings.TrimPrefix(fingerprint, "MD5:")
+	return strings.ReplaceAll(fingerprint, ":", "")
+}
+
+// commitEmailsByLogin collects the author emails of recent commits in the
+// organization's most recently pushed repositories
+func commitEmailsByLogin(client *githubClient, org string, policy orgReportPolicy) (map[string][]string, error) {
+	var repos []struct {
+		FullName string `json:"full_name"`
+		Archived bool   `json:"archived"`
+	}
+	path := fmt.Sprintf("/orgs/%s/repos?sort=pushed&direction=desc&per_page=%d", url.PathEscape(org), policy.Repos)
+	if err := client.get(path, &repos); err != nil {
+		return nil, fmt.Errorf("failed to list repositories of '%s': %w", org, err)
+	}
+
+	emails := map[string][]string{}
+	since := url.QueryEscape(policy.Since.UTC().Format(time.RFC3339))
+	for _, repo := range repos {
+		if repo.Archived {
+			continue
+		}
+		var commits []struct {
+			Commit struct {
+				Author struct {
+					Email string `json:"email"`
+				} `json:"author"`
+			} `json:"commit"`
+			Author *struct {
+				Login string `json:"login"`
+			} `json:"author"`
+		}
+		if err := client.get("/repos/"+repo.FullName+"/commits?per_page=100&since="+since, &commits); err != nil {
+			// Empty repositories answer 409; they have nothing to report
+			continue
+		}
+		for _, commit := range commits {
+			if commit.Author == nil || commit.Author.Login == "" {
+				continue
+			}
+			email := strings.ToLower(commit.Commit.Author.Email)
+			if !containsString(emails[commit.Author.Login], email) {
+				emails[commit.Author.Login] = append(emails[commit.Author.Login], email)
+			}
+		}
+	}
+	return emails, nil
+}
+
+// emailMatchesDomain reports whether an email belongs to domain or a subdomain of it
+func emailMatchesDomain(email, domain string) bool {
+	at := strings.LastIndex(email, "@")
+	if at == -1 {
+		return false
+	}
+	host := strings.ToLower(email[at+1:])
+	domain = strings.ToLower(strings.TrimPrefix(domain, "@"))
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// findPolicyViolations turns the key inventory and commit emails into findings
+func findPolicyViolations(audits []memberAudit, keyDates map[string]time.Time, emails map[string][]string, policy orgReportPolicy) []policyViolation {
+	var violations []policyViolation
+	for _, audit := range audits {
+		if audit.Error == "" && len(audit.Keys) == 0 {
+			violations = append(violations, policyViolation{Login: audit.Login, Kind: "no-keys", Detail: "No SSH keys registered"})
+		}
+		for _, key := range audit.Keys {
+			if key.Weak != "" {
+				violations = append(violations, policyViolation{Login: audit.Login, Kind: "weak-key", Detail: fmt.Sprintf("%s %s: %s", key.Type, key.Fingerprint, key.Weak)})
+			}
+			if policy.MaxKeyAge == 0 {
+				continue
+			}
+			authorized, ok := keyDates[normalizeFingerprint(key.Fingerprint)]
+			if !ok {
+				authorized, ok = keyDates[normalizeFingerprint(key.LegacyFingerprint)]
+			}
+			if ok && time.Since(authorized) > policy.MaxKeyAge {
+				violations = append(violations, policyViolation{Login: audit.Login, Kind: "old-key", Detail: fmt.Sprintf("%s %s authorized %s", key.Type, key.Fingerprint, authorized.Format("2006-01-02"))})
+			}
+		}
+
+		if policy.Domain == "" {
+			continue
+		}
+		for _, email := range emails[audit.Login] {
+			if emailMatchesDomain(email, policy.Domain) {
+				continue
+			}
+			if policy.AllowNoreply && strings.HasSuffix(email, "@users.noreply.github.com") {
+				continue
+			}
+			violations = append(violations, policyViolation{Login: audit.Login, Kind: "email", Detail: fmt.Sprintf("Committed as %s (expected @%s)", email, strings.TrimPrefix(policy.Domain, "@"))})
+		}
+	}
+	return violations
+}
+
+// writePolicyViolations renders the report as table, json or csv
+func writePolicyViolations(w io.Writer, format string, violations []policyViolation) error {
+	switch format {
+	case "json":
+		if violations == nil {
+			violations = []policyViolation{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(violations)
+	case "csv":
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"login", "kind", "detail"})
+		for _, v := range violations {
+			writer.Write([]string{v.Login, v.Kind, v.Detail})
+		}
+		writer.Flush()
+		return writer.Error()
+	case "table":
+		if len(violations) == 0 {
+			fmt.Fprintln(w, "🎉 No policy violations found")
+			return nil
+		}
+		last := ""
+		for _, v := range violations {
+			if v.Login != last {
+				fmt.Fprintf(w, "👤 %s\n", v.Login)
+				last = v.Login
+			}
+			fmt.Fprintf(w, "   ⚠️  [%s] %s\n", v.Kind, v.Detail)
+		}
+		return nil
+	}
+	return fmt.Errorf("❌ Unknown format '%s'. Use table, json or csv", format)
+}
+
+var orgReportCmd = &cobra.Command{
+	Use:   "report <org>",
+	Short: "Report members whose keys or commit emails violate policy",
+	Long: `Report, for every member of an organization, SSH keys that are weak (DSA, RSA
+below 2048 bits) or older than --max-key-age, members without keys, and recent
+commit emails outside the corporate --domain.
+
+Key age is only known for organizations with SAML SSO, where GitHub records
+when each key was authorized, and requires an organization owner's access.
+Commit emails are collected from the most recently pushed repositories.
+
+Examples:
+  krakn org report acme --domain acme.com
+  krakn org report acme --domain acme.com --format csv --output report.csv
+  krakn org report acme --max-key-age 365d --format json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		org := args[0]
+		accountName, _ := cmd.Flags().GetString("account")
+		format, _ := cmd.Flags().GetString("format")
+		output, _ := cmd.Flags().GetString("output")
+		policy := orgReportPolicy{}
+		policy.Domain, _ = cmd.Flags().GetString("domain")
+		policy.AllowNoreply, _ = cmd.Flags().GetBool("allow-noreply")
+		policy.Repos, _ = cmd.Flags().GetInt("repos")
+		maxAge, _ := cmd.Flags().GetString("max-key-age")
+		sinceDays, _ := cmd.Flags().GetInt("since-days")
+
+		if maxAge != "" {
+			age, err := parseDays(maxAge)
+			if err != nil {
+				return err
+			}
+			policy.MaxKeyAge = age
+		}
+		if format != "table" && format != "json" && format != "csv" {
+			return fmt.Errorf("❌ Unknown format '%s'. Use table, json or csv", format)
+		}
+		policy.Since = time.Now().AddDate(0, 0, -sinceDays)
+		if policy.Repos < 1 || policy.Repos > 100 {
+			return fmt.Errorf("❌ --repos must be between 1 and 100")
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		spin := startSpinner("Authenticating for " + org)
+		client, _, err := orgAPIClient(config, org, accountName)
+		if err != nil {
+			spin.Stop()
+			return err
+		}
+		spin.Update("Fetching members and keys of " + org)
+		audits, err := auditOrgMembers(client, org)
+		if err != nil {
+			spin.Stop()
+			return err
+		}
+		keyDates := map[string]time.Time{}
+		if policy.MaxKeyAge > 0 {
+			spin.Update("Fetching SSO key authorizations")
+			keyDates = keyAuthorizationDates(client, org)
+		}
+		emails := map[string][]string{}
+		if policy.Domain != "" {
+			spin.Update("Collecting recent commit emails")
+			if emails, err = commitEmailsByLogin(client, org, policy); err != nil {
+				spin.Stop()
+				return err
+			}
+		}
+		spin.Stop()
+
+		// On stderr, so JSON and CSV reports still say the check did not run
+		if policy.MaxKeyAge > 0 && len(keyDates) == 0 {
+			fmt.Fprintln(os.Stderr, "ℹ️  Key authorization dates are not available; skipping the key age check")
+		}
+
+		violations := findPolicyViolations(audits, keyDates, emails, policy)
+
+		w := os.Stdout
+		if output != "" && output != "-" {
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", output, err)
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := writePolicyViolations(w, format, violations); err != nil {
+			return err
+		}
+		if w != os.Stdout {
+			fmt.Printf("✅ Wrote %d violations for %d members to %s\n", len(violations), len(audits), output)
+		}
+		return nil
+	},
+}
+
+// parseDays parses a duration that may use a "d" suffix for days, e.g. "365d"
+func parseDays(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		var days int
+		if _, err := fmt.Sscanf(value, "%dd", &days); err != nil || days <= 0 {
+			return 0, fmt.Errorf("❌ Invalid duration: %s", value)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("❌ Invalid duration: %s", value)
+	}
+	return d, nil
+}
+
+func init() {
+	orgReportCmd.Flags().String("account", "", "Use this account's token instead of the organization's GitHub App")
+	orgReportCmd.Flags().String("domain", "", "Corporate email domain commit emails must use")
+	orgReportCmd.Flags().Bool("allow-noreply", false, "Accept GitHub noreply commit emails")
+	orgReportCmd.Flags().String("max-key-age", "", "Flag SSH keys authorized longer ago than this, e.g. 365d")
+	orgReportCmd.Flags().Int("since-days", 90, "Consider commits from this many days back")
+	orgReportCmd.Flags().Int("repos", 20, "Number of most recently pushed repositories to inspect")
+	orgReportCmd.Flags().String("format", "table", "Output format: table, json or csv")
+	orgReportCmd.Flags().StringP("output", "o", "", "Write the report to a file instead of stdout")
+	orgCmd.AddCommand(orgReportCmd)
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// outputFormat is the format chosen with --output: "text", "json" or "yaml"
+var outputFormat string
+
+// structuredOutputCommands are the commands that implement --output json|yaml,
+// by path below krakn, including their noun group mirrors
+var structuredOutputCommands = map[string]bool{
+	"list":          true,
+	"account list":  true,
+	"current":       true,
+	"show-includes": true,
+	"dir includes":  true,
+	"use":           true,
+	"account use":   true,
+	"doctor":        true,
+	"status":        true,
+	"state":         true,
+	"schema":        true,
+}
+
+// checkOutputFormat rejects unknown formats, and structured formats for
+// commands that would silently print text instead. Commands with an --output
+// of their own (a file or directory) shadow the global flag and never get here
+// with another format than text.
+func checkOutputFormat(cmd *cobra.Command) error {
+	switch outputFormat {
+	case "", "text":
+		return nil
+	case "json", "yaml":
+	default:
+		return fmt.Errorf("❌ Unknown output format '%s'; use text, json or yaml", outputFormat)
+	}
+	if !structuredOutputCommands[strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")] {
+		return fmt.Errorf("❌ '%s' only prints text; --output %s works with %s", cmd.CommandPath(), outputFormat, joinWords(schemaCommandNames()))
+	}
+	return nil
+}
+
+// structuredOutput reports whether --output asked for JSON or YAML
+func structuredOutput() bool {
+	return outputFormat == "json" || outputFormat == "yaml"
+}
+
+// printStructured writes v in the --output format. Both formats come from
+// the json tags, so field names and omitted fields are the same in each.
+func printStructured(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if outputFormat != "yaml" {
+		fmt.Println(string(data))
+		return nil
+	}
+
+	// JSON is YAML; parsing it into nodes keeps the field order, and
+	// clearing the styles turns flow mappings and quoted strings into the
+	// usual block form
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	resetYAMLStyle(&node)
+	out, err := yaml.Marshal(&node)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(out))
+	return nil
+}
+
+// resetYAMLStyle clears the style of node and its children. The encoder
+// still quotes strings that would read as another type, such as "true".
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetYAMLStyle(child)
+	}
+}
+
+func init() {
+	RootCmd.PersistentFlags().StringVar(&outputFormat, "output", "text", "Output format for list, current, status, show-includes, use and doctor: text, json or yaml")
+}
@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// RepoOverride maps one upstream repository to an account, wherever it is
+// cloned. It takes precedence over directory mappings.
+type RepoOverride struct {
+	Repo       string `json:"repo"`            // host/owner/repo, e.g. github.com/golang/go
+	Account    string `json:"account"`         // Account name the repository maps to
+	Email      string `json:"email,omitempty"` // Commit email instead of the account's, e.g. a CLA-signed address
+	ConfigFile string `json:"config_file"`     // Include file with the identity
+}
+
+// getOverridesDir returns where override include files are kept
+func getOverridesDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".krakncat", "overrides")
+}
+
+// normalizeRepoSpec turns a remote URL or host/owner/repo into host/owner/repo.
+// Host aliases of accounts are resolved to the provider hostname. The path
+// keeps its case: git matches hasconfig patterns case-sensitively.
+func (c *Config) normalizeRepoSpec(spec string) (string, error) {
+	host, path := "", ""
+	if remote, err := parseRemoteURL(spec); err == nil {
+		host, path = remote.Host, remote.Path
+	} else if parts := strings.SplitN(strings.Trim(spec, "/"), "/", 2); len(parts) == 2 {
+		host, path = parts[0], parts[1]
+	}
+	if account := c.findAccountByHost(host); account != nil {
+		host = account.GetProvider().Hostname
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if host == "" || !strings.Contains(path, "/") {
+		return "", fmt.Errorf("❌ Expected host/owner/repo or a repository URL, got '%s'", spec)
+	}
+	return strings.ToLower(host) + "/" + path, nil
+}
+
+// getOverride returns the override for a normalized repository, ignoring case
+// the way providers do
+func (c *Config) getOverride(repo string) *RepoOverride {
+	for i := range c.Overrides {
+		if strings.EqualFold(c.Overrides[i].Repo, repo) {
+			return &c.Overrides[i]
+		}
+	}
+	return nil
+}
+
+// findOverride returns the override matching any remote of a repository,
+// the same remotes git's hasconfig:remote.*.url condition looks at
+func (c *Config) findOverride(repoRoot string) *RepoOverride {
+	if len(c.Overrides) == 0 {
+		return nil
+	}
+	output, err := traceExec(exec.Command("git", "-C", repoRoot, "config", "--get-regexp", `^remote\..*\.url$`)).Output()
+	if err != nil {
+		return nil
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		repo, err := c.normalizeRepoSpec(fields[1])
+		if err != nil {
+			continue
+		}
+		if override := c.getOverride(repo); override != nil {
+			tracef(traceMatch, "Remote %s is overridden → account '%s'", fields[1], override.Account)
+			return override
+		}
+	}
+	return nil
+}
+
+// overrideURLPatterns lists the remote URLs that select an override: HTTPS
+// and SSH URLs of the provider hostname, and SSH URLs of every account alias
+// on it, with and without .git
+func (c *Config) overrideURLPatterns(override RepoOverride) []string {
+	host, path, _ := strings.Cut(override.Repo, "/")
+	urls := []string{"https://" + host + "/" + path}
+	hosts := []string{host}
+	for i := range c.Accounts {
+		if strings.EqualFold(c.Accounts[i].GetProvider().Hostname, host) && !containsString(hosts, c.Accounts[i].GetSSHHost()) {
+			hosts = append(hosts, c.Accounts[i].GetSSHHost())
+		}
+	}
+	for _, h := range hosts {
+		urls = append(urls, "git@"+h+":"+path, "ssh://git@"+h+"/"+path)
+	}
+
+	var patterns []string
+	for _, url := range urls {
+		patterns = append(patterns, url, url+".git")
+	}
+	return patterns
+}
+
+// syncOverrides writes the include file of every override and rewrites their
+// includeIf entries at the end of ~/.gitconfig. git applies later entries
+// last, so this keeps overrides ahead of directory mappings; it runs again
+// whenever a mapping is added.
+func (c *Config) syncOverrides() error {
+	for _, override := range c.Overrides {
+		account := c.getAccount(override.Account)
+		if account == nil {
+			continue
+		}
+		if err := makePrivateDir(getOverridesDir()); err != nil {
+			return err
+		}
+		if err := writeCommitTemplate(account); err != nil {
+			return err
+		}
+		trackFile(override.ConfigFile)
+		if err := writePrivateFile(override.ConfigFile, []byte(identityInclude(account, override.Email))); err != nil {
+			return fmt.Errorf("failed to write %s: %w", override.ConfigFile, err)
+		}
+	}
+
+	path := globalGitConfigPath()
+	return withFileLock(path, func() error {
+		gitConfig, err := readGitConfigFile(path)
+		if err != nil {
+			return err
+		}
+		before := gitConfig.String()
+		// Only the hasconfig entries krakn wrote move; gitdir, onbranch and the
+		// user's own entries keep their place
+		for _, section := range gitConfig.findSections("includeIf") {
+			if includeIfKind(section.Subsection) == "hasconfig" && strings.HasPrefix(expandUserPath(section.get("path")), getOverridesDir()+string(filepath.Separator)) {
+				gitConfig.removeSection(section)
+			}
+		}
+		for _, override := range c.Overrides {
+			for _, pattern := range c.overrideURLPatterns(override) {
+				section := gitConfig.addSection("includeIf", "hasconfig:remote.*.url:"+pattern)
+				section.set("path", contractHomePath(override.ConfigFile))
+			}
+		}
+		if gitConfig.String() == before {
+			return nil
+		}
+		return gitConfig.save()
+	})
+}
+
+var overrideCmd = &cobra.Command{
+	Use:   "override",
+	Short: "Map specific upstream repositories to an account",
+	Long: `Map specific upstream repositories to an account (and optionally another
+email), wherever they are cloned. An override wins over directory mappings: a
+clone of github.com/golang/go inside ~/work still commits as your OSS identity.
+
+Overrides are includeIf "hasconfig:remote.*.url:..." entries (git 2.36 or newer)
+kept at the end of ~/.gitconfig, so git itself applies them. They match the
+provider hostname and your accounts' host aliases, over SSH and HTTPS.
+
+Examples:
+  krakn override add github.com/golang/go oss
+  krakn override add git@github.com:kubernetes/kubernetes.git oss --email me@cncf-cla.example
+  krakn override list
+  krakn override remove github.com/golang/go`,
+}
+
+var overrideAddCmd = &cobra.Command{
+	Use:   "add <host/owner/repo|url> <account-name>",
+	Short: "Use an account for an upstream repository",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		email, _ := cmd.Flags().GetString("email")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		repo, err := config.normalizeRepoSpec(args[0])
+		if err != nil {
+			return err
+		}
+		account := config.getAccount(args[1])
+		if account == nil {
+			return config.accountNotFound(args[1])
+		}
+		if err := config.revealAccount(account); err != nil {
+			return err
+		}
+
+		override := RepoOverride{
+			Repo:       repo,
+			Account:    account.Name,
+			Email:      email,
+			ConfigFile: filepath.Join(getOverridesDir(), strings.NewReplacer("/", "_", ":", "_").Replace(strings.ToLower(repo))+".gitconfig"),
+		}
+		if err := updateConfig(func(config *Config) error {
+			if existing := config.getOverride(repo); existing != nil {
+				*existing = override
+			} else {
+				config.Overrides = append(config.Overrides, override)
+			}
+			if err := config.syncOverrides(); err != nil {
+				return fmt.Errorf("failed to write the override: %w", err)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if email == "" {
+			email = account.Email
+		}
+		fmt.Printf("✅ %s commits as '%s' <%s>, wherever it is cloned\n", repo, account.Name, email)
+		fmt.Printf("📁 Config file: %s\n", contractHomePath(override.ConfigFile))
+		return nil
+	},
+}
+
+var overrideListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List repository overrides",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if len(config.Overrides) == 0 {
+			fmt.Println("📭 No overrides. Add one with 'krakn override add <host/owner/repo> <account>'.")
+			return nil
+		}
+		fmt.Println("🎯 Repository overrides:")
+		for _, override := range config.Overrides {
+			status := "✅"
+			if config.getAccount(override.Account) == nil {
+				status = "⚠️  (account missing)"
+			} else if !fileExists(override.ConfigFile) {
+				status = "⚠️  (include file missing)"
+			}
+			line := fmt.Sprintf("  %s %s → %s", status, override.Repo, override.Account)
+			if override.Email != "" {
+				line += " <" + override.Email + ">"
+			}
+			fmt.Println(line)
+		}
+		return nil
+	},
+}
+
+var overrideRemoveCmd = &cobra.Command{
+	Use:   "remove <host/owner/repo|url>",
+	Short: "Remove a repository override",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		repo, err := config.normalizeRepoSpec(args[0])
+		if err != nil {
+			return err
+		}
+		override := config.getOverride(repo)
+		if override == nil {
+			return fmt.Errorf("❌ No override for %s", repo)
+		}
+
+		configFile := override.ConfigFile
+		if err := updateConfig(func(config *Config) error {
+			var kept []RepoOverride
+			for _, o := range config.Overrides {
+				if !strings.EqualFold(o.Repo, repo) {
+					kept = append(kept, o)
+				}
+			}
+			config.Overrides = kept
+			if err := config.syncOverrides(); err != nil {
+				return fmt.Errorf("failed to update ~/.gitconfig: %w", err)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		trackFile(configFile)
+		os.Remove(configFile)
+		fmt.Printf("🗑️  Removed the override for %s\n", repo)
+		return nil
+	},
+}
+
+func init() {
+	overrideAddCmd.Flags().String("email", "", "Commit email for the repository instead of the account's")
+	overrideCmd.AddCommand(overrideAddCmd)
+	overrideCmd.AddCommand(overrideListCmd)
+	overrideCmd.AddCommand(overrideRemoveCmd)
+	RootCmd.AddCommand(overrideCmd)
+
+	registerUninstallStep(uninstallStep{
+		Name: "repository overrides",
+		Describe: func(config *Config) []string {
+			var repos []string
+			for _, override := range config.Overrides {
+				repos = append(repos, override.Repo)
+			}
+			return repos
+		},
+		Run: func(config *Config) error {
+			files := config.Overrides
+			config.Overrides = nil
+			if err := config.syncOverrides(); err != nil {
+				return err
+			}
+			for _, override := range files {
+				trackFile(override.ConfigFile)
+				os.Remove(override.ConfigFile)
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,51 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// pathOwner returns the name of the user owning path, and whether that is
+// someone other than the current user
+func pathOwner(path string) (owner string, foreign bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", false
+	}
+
+	uid := strconv.FormatUint(uint64(stat.Uid), 10)
+	owner = uid
+	if u, err := user.LookupId(uid); err == nil {
+		owner = u.Username
+	}
+	return owner, int(stat.Uid) != os.Geteuid()
+}
+
+// isMountPoint reports whether path is the root of a mounted file system, i.e.
+// it lives on another device than its parent directory. ok is false when this
+// cannot be determined.
+func isMountPoint(path string) (mount, ok bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, false
+	}
+	parent, err := os.Stat(filepath.Dir(filepath.Clean(path)))
+	if err != nil {
+		return false, false
+	}
+	stat, ok1 := info.Sys().(*syscall.Stat_t)
+	parentStat, ok2 := parent.Sys().(*syscall.Stat_t)
+	if !ok1 || !ok2 {
+		return false, false
+	}
+	return stat.Dev != parentStat.Dev, true
+}
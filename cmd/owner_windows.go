@@ -0,0 +1,15 @@
+//go:build windows
+
+package cmd
+
+// pathOwner returns the name of the user owning path, and whether that is
+// someone other than the current user. Ownership is not inspected on Windows.
+func pathOwner(path string) (owner string, foreign bool) {
+	return "", false
+}
+
+// isMountPoint reports whether path is the root of a mounted file system.
+// Mounts are not inspected on Windows.
+func isMountPoint(path string) (mount, ok bool) {
+	return false, false
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// foreignHomePattern matches home directories of other platforms or users,
+// e.g. /Users/me on macOS, /home/me on Linux and C:\Users\me on Windows
+var foreignHomePattern = regexp.MustCompile(`^(/Users/[^/]+|/home/[^/]+|[A-Za-z]:\\Users\\[^\\]+)([/\\].*)?$`)
+
+// expandUserPath expands a leading ~, $HOME or ${HOME} to the user's home directory
+func expandUserPath(path string) string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	for _, token := range []string{"~", "${HOME}", "$HOME"} {
+		if path == token {
+			return homeDir
+		}
+		if strings.HasPrefix(path, token+"/") {
+			return filepath.Join(homeDir, path[len(token)+1:])
+		}
+	}
+	return path
+}
+
+// contractHomePath rewrites a path inside the home directory to the portable ~/ form
+func contractHomePath(path string) string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil || path == "" || !filepath.IsAbs(path) {
+		return path
+	}
+
+	rel, err := filepath.Rel(homeDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	if rel == "." {
+		return "~"
+	}
+	return "~/" + filepath.ToSlash(rel)
+}
+
+// resolveStoredPath expands a path read from config.json. Absolute paths that
+// point into another machine's home directory (e.g. a config synced from
+// macOS to Linux) are remapped onto the current home when they don't exist.
+func resolveStoredPath(path string) string {
+	if path == "" {
+		return path
+	}
+
+	path = expandUserPath(path)
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	match := foreignHomePattern.FindStringSubmatch(path)
+	if match == nil || filepath.Clean(match[1]) == filepath.Clean(homeDir) {
+		return path
+	}
+
+	rest := strings.TrimLeft(strings.ReplaceAll(match[2], `\`, "/"), "/")
+	return filepath.Join(homeDir, filepath.FromSlash(rest))
+}
+
+// expandPaths resolves all stored paths after loading the config
+func (c *Config) expandPaths() {
+	for i := range c.Accounts {
+		c.Accounts[i].SSHKey = resolveStoredPath(expandSSHPath(c.Accounts[i].SSHKey, nil))
+		c.Accounts[i].KeyDir = resolveStoredPath(c.Accounts[i].KeyDir)
+		c.Accounts[i].CommitTemplate = resolveStoredPath(c.Accounts[i].CommitTemplate)
+	}
+	for i := range c.Directories {
+		c.Directories[i].Path = resolveStoredPath(c.Directories[i].Path)
+		c.Directories[i].ConfigFile = resolveStoredPath(c.Directories[i].ConfigFile)
+	}
+	for i := range c.Overrides {
+		c.Overrides[i].ConfigFile = resolveStoredPath(c.Overrides[i].ConfigFile)
+	}
+	for i := range c.OrgApps {
+		c.OrgApps[i].PrivateKey = resolveStoredPath(c.OrgApps[i].PrivateKey)
+	}
+	for i := range c.Integrations {
+		c.Integrations[i].Dir = resolveStoredPath(c.Integrations[i].Dir)
+	}
+	if c.Sealing != nil {
+		c.Sealing.Identity = resolveStoredPath(c.Sealing.Identity)
+	}
+	if c.PushGuard != nil {
+		for i := range c.PushGuard.Hooks {
+			c.PushGuard.Hooks[i] = resolveStoredPath(c.PushGuard.Hooks[i])
+		}
+	}
+	if c.Audit != nil {
+		c.Audit.Path = resolveStoredPath(c.Audit.Path)
+	}
+}
+
+// contractPaths rewrites all stored paths to the portable ~/ form before saving
+func (c *Config) contractPaths() {
+	for i := range c.Accounts {
+		c.Accounts[i].SSHKey = contractHomePath(c.Accounts[i].SSHKey)
+		c.Accounts[i].KeyDir = contractHomePath(c.Accounts[i].KeyDir)
+		c.Accounts[i].CommitTemplate = contractHomePath(c.Accounts[i].CommitTemplate)
+	}
+	for i := range c.Directories {
+		c.Directories[i].Path = contractHomePath(c.Directories[i].Path)
+		c.Directories[i].ConfigFile = contractHomePath(c.Directories[i].ConfigFile)
+	}
+	for i := range c.Overrides {
+		c.Overrides[i].ConfigFile = contractHomePath(c.Overrides[i].ConfigFile)
+	}
+	for i := range c.OrgApps {
+		c.OrgApps[i].PrivateKey = contractHomePath(c.OrgApps[i].PrivateKey)
+	}
+	for i := range c.Integrations {
+		c.Integrations[i].Dir = contractHomePath(c.Integrations[i].Dir)
+	}
+	if c.Sealing != nil {
+		sealing := *c.Sealing
+		sealing.Identity = contractHomePath(sealing.Identity)
+		c.Sealing = &sealing
+	}
+	if c.PushGuard != nil {
+		guard := *c.PushGuard
+		guard.Hooks = make([]string, len(c.PushGuard.Hooks))
+		for i, repo := range c.PushGuard.Hooks {
+			guard.Hooks[i] = contractHomePath(repo)
+		}
+		c.PushGuard = &guard
+	}
+	if c.Audit != nil {
+		audit := *c.Audit
+		audit.Path = contractHomePath(audit.Path)
+		c.Audit = &audit
+	}
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// pathFieldPattern matches the names of config fields that hold a path
+var pathFieldPattern = regexp.MustCompile(`(Path|Dir|File|Key|Identity|Template|Hooks)$`)
+
+// notPathFields are fields whose names look like paths but hold something else
+var notPathFields = map[string]bool{
+	"Accounts.SSHOptions.Key": true, // ssh_config option name
+	"Accounts.SigningKey":     true, // GPG key ID
+	"Webhook.Template":        true, // text/template source
+}
+
+// visitPathFields calls fn for every path field reachable from v, filling in
+// nil pointers and empty slices on the way when fill is set
+func visitPathFields(v reflect.Value, name string, fill bool, fn func(name string, field reflect.Value)) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			if !fill {
+				return
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		visitPathFields(v.Elem(), name, fill, fn)
+	case reflect.Slice:
+		if v.Len() == 0 && fill {
+			v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		}
+		for i := 0; i < v.Len(); i++ {
+			visitPathFields(v.Index(i), name, fill, fn)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+			visitPathFields(v.Field(i), strings.TrimPrefix(name+"."+field.Name, "."), fill, fn)
+		}
+	case reflect.String:
+		if pathFieldPattern.MatchString(name) && !notPathFields[name] {
+			fn(name, v)
+		}
+	}
+}
+
+// TestConfigPathsRoundTrip sets every path field of the config to a path in
+// the home directory, saves and loads the config, and checks that each one
+// was stored as ~/... and read back unchanged. A new path field that
+// expandPaths and contractPaths don't handle fails here.
+func TestConfigPathsRoundTrip(t *testing.T) {
+	home, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+
+	config := &Config{}
+	want := map[string]string{}
+	visitPathFields(reflect.ValueOf(config), "", true, func(name string, field reflect.Value) {
+		field.SetString(filepath.Join(home, "paths", name))
+		want[name] = field.String()
+	})
+	if err := config.saveConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	visitPathFields(reflect.ValueOf(config), "", false, func(name string, field reflect.Value) {
+		if field.String() != want[name] {
+			t.Errorf("saving changed %s in memory to %q", name, field.String())
+		}
+	})
+
+	data, err := os.ReadFile(getConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), home) {
+		t.Errorf("config.json contains the home directory instead of ~/:\n%s", data)
+	}
+	for name := range want {
+		if !strings.Contains(string(data), `"~/paths/`+name+`"`) {
+			t.Errorf("config.json does not store %s as ~/paths/%s", name, name)
+		}
+	}
+
+	loaded, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	visitPathFields(reflect.ValueOf(loaded), "", false, func(name string, field reflect.Value) {
+		got[name] = field.String()
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("paths after loading = %v, want %v", got, want)
+	}
+}
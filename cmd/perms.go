@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// permTarget is a file or directory whose mode must not exceed Max
+type permTarget struct {
+	Path string
+	Max  os.FileMode
+	What string // e.g. "SSH key of 'work'"
+}
+
+// permProblem is a target that exists and is more open than allowed
+type permProblem struct {
+	permTarget
+	Mode os.FileMode
+}
+
+// fixedMode returns the mode fix-perms sets: the current one without the
+// bits that are not allowed
+func (p permProblem) fixedMode() os.FileMode {
+	return p.Mode & p.Max
+}
+
+// permissionTargets lists the SSH and krakncat files whose permissions matter.
+// OpenSSH ignores keys others can read and a config others can write to.
+func permissionTargets(config *Config) []permTarget {
+	homeDir, _ := os.UserHomeDir()
+	targets := []permTarget{
+		{Path: filepath.Join(homeDir, ".ssh"), Max: 0700, What: "SSH directory"},
+		{Path: getSSHConfigPath(), Max: 0600, What: "SSH config"},
+		{Path: getConfigPath(), Max: 0600, What: "krakncat config"},
+	}
+	for _, account := range config.Accounts {
+		if account.KeyDir != "" {
+			targets = append(targets, permTarget{Path: account.KeyDir, Max: 0700, What: fmt.Sprintf("Key directory of '%s'", account.Name)})
+		}
+		if account.SSHKey != "" && !account.usesHTTPS() {
+			targets = append(targets, permTarget{Path: account.SSHKey, Max: 0600, What: fmt.Sprintf("SSH key of '%s'", account.Name)})
+		}
+	}
+	// Include files hold names and emails and are only read by git as the user
+	for _, mapping := range config.Directories {
+		targets = append(targets, permTarget{Path: mapping.ConfigFile, Max: 0600, What: "Include file of " + contractHomePath(mapping.patternDir())})
+	}
+	for _, override := range config.Overrides {
+		targets = append(targets, permTarget{Path: override.ConfigFile, Max: 0600, What: "Override include file of " + override.Repo})
+	}
+	return targets
+}
+
+// findPermProblems returns the targets that are more open than allowed.
+// Missing files are not a permission problem and are skipped.
+func findPermProblems(config *Config) []permProblem {
+	var problems []permProblem
+	seen := map[string]bool{}
+	for _, target := range permissionTargets(config) {
+		if target.Path == "" || seen[filepath.Clean(target.Path)] {
+			continue
+		}
+		seen[filepath.Clean(target.Path)] = true
+		info, err := os.Stat(target.Path)
+		if err != nil {
+			continue
+		}
+		if mode := info.Mode().Perm(); mode&^target.Max != 0 {
+			problems = append(problems, permProblem{permTarget: target, Mode: mode})
+		}
+	}
+	return problems
+}
+
+// checkPermissions reports SSH and krakncat files that are too open
+func checkPermissions(ctx *doctorContext) []doctorFinding {
+	if runtime.GOOS == "windows" {
+		return []doctorFinding{{Level: doctorInfo, Message: "File modes are not checked on Windows"}}
+	}
+	problems := findPermProblems(ctx.Config)
+	if len(problems) == 0 {
+		return []doctorFinding{{Level: doctorOK, Message: "SSH directory, keys, SSH config and include files are private"}}
+	}
+	var findings []doctorFinding
+	for _, problem := range problems {
+		findings = append(findings, doctorFinding{
+			Level:   doctorWarn,
+			Message: fmt.Sprintf("%s %s is %o, expected at most %o", problem.What, contractHomePath(problem.Path), problem.Mode, problem.Max),
+			Hint:    "krakn fix-perms",
+		})
+	}
+	return findings
+}
+
+// restrictTargetACLs gives every existing target an ACL that only grants
+// the current user access, as file modes do not apply on Windows
+func restrictTargetACLs(config *Config, dryRun bool) error {
+	count := 0
+	seen := map[string]bool{}
+	for _, target := range permissionTargets(config) {
+		if target.Path == "" || seen[filepath.Clean(target.Path)] || !fileExists(target.Path) {
+			continue
+		}
+		seen[filepath.Clean(target.Path)] = true
+		count++
+		if dryRun {
+			fmt.Printf("🔓 %s %s: would be restricted to you\n", target.What, contractHomePath(target.Path))
+			continue
+		}
+		if err := restrictToOwner(target.Path); err != nil {
+			return fmt.Errorf("failed to restrict access to %s: %w", contractHomePath(target.Path), err)
+		}
+		fmt.Printf("🔒 %s %s: restricted to you\n", target.What, contractHomePath(target.Path))
+	}
+	if count == 0 {
+		fmt.Println("✅ Nothing to restrict")
+	}
+	return nil
+}
+
+var fixPermsCmd = &cobra.Command{
+	Use:   "fix-perms",
+	Short: "Make SSH keys, ~/.ssh and krakncat's files private",
+	Long: `Tighten the permissions OpenSSH and git expect, which 'krakn doctor' checks:
+
+  ~/.ssh and key directories    700
+  SSH private keys              600
+  ~/.ssh/config                 600
+  ~/.krakncat/config.json       600
+  Include files of krakncat     600
+
+OpenSSH refuses private keys that others can read ("UNPROTECTED PRIVATE KEY
+FILE") and a config that others can write to. Only the bits that are too
+open are removed; files that are already stricter are left alone. On Windows
+each of these paths gets an ACL that only grants you access.
+
+Examples:
+  krakn fix-perms
+  krakn fix-perms --dry-run   # Only show what would change`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if runtime.GOOS == "windows" {
+			return restrictTargetACLs(config, dryRun)
+		}
+		problems := findPermProblems(config)
+		if len(problems) == 0 {
+			fmt.Println("✅ All permissions are fine")
+			return nil
+		}
+
+		for _, problem := range problems {
+			if dryRun {
+				fmt.Printf("🔓 %s %s: %o → %o\n", problem.What, contractHomePath(problem.Path), problem.Mode, problem.fixedMode())
+				continue
+			}
+			if err := os.Chmod(problem.Path, problem.fixedMode()); err != nil {
+				return fmt.Errorf("failed to change the permissions of %s: %w", contractHomePath(problem.Path), err)
+			}
+			fmt.Printf("🔒 %s %s: %o → %o\n", problem.What, contractHomePath(problem.Path), problem.Mode, problem.fixedMode())
+		}
+		if dryRun {
+			fmt.Printf("\n💡 Run 'krakn fix-perms' to fix %d path(s)\n", len(problems))
+		} else {
+			fmt.Printf("\n✅ Fixed %d path(s)\n", len(problems))
+		}
+		return nil
+	},
+}
+
+func init() {
+	fixPermsCmd.Flags().Bool("dry-run", false, "Only show what would change")
+	RootCmd.AddCommand(fixPermsCmd)
+	registerDoctorCheck(doctorCheck{Name: "Permissions", Run: checkPermissions})
+}
@@ -0,0 +1,479 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// SealConfig describes how private account fields are encrypted at rest
+type SealConfig struct {
+	Method   string `json:"method"`             // "passphrase" or "identity"
+	Identity string `json:"identity,omitempty"` // Path to an age identity file when Method is "identity"
+}
+
+// sealableFields lists the account fields that can be encrypted at rest
+var sealableFields = []string{"email", "token"}
+
+// cachedPassphrase avoids prompting more than once per invocation
+var cachedPassphrase string
+
+func (a *Account) fieldValue(field string) *string {
+	switch field {
+	case "email":
+		return &a.Email
+	case "token":
+		return &a.Token
+	}
+	return nil
+}
+
+// isSealed reports whether the given field is stored encrypted
+func (a *Account) isSealed(field string) bool {
+	_, ok := a.Sealed[field]
+	return ok
+}
+
+// stripSealed returns a copy of the account with sealed plaintext removed,
+// and without a token that belongs in the keychain
+func (a Account) stripSealed() Account {
+	for field := range a.Sealed {
+		if value := a.fieldValue(field); value != nil {
+			*value = ""
+		}
+	}
+	if a.TokenStore == tokenStoreKeychain {
+		a.Token = ""
+	}
+	return a
+}
+
+// revealAccount decrypts any sealed fields of the account in place and reads
+// a token kept in the keychain. The passphrase or identity is only requested
+// when a sealed field exists.
+func (c *Config) revealAccount(account *Account) error {
+	if err := revealKeychainToken(account); err != nil {
+		return err
+	}
+	if len(account.Sealed) == 0 {
+		return nil
+	}
+
+	identities, err := c.sealIdentities()
+	if err != nil {
+		return err
+	}
+
+	for field, sealed := range account.Sealed {
+		value := account.fieldValue(field)
+		if value == nil {
+			continue
+		}
+		plaintext, err := openValue(sealed, identities)
+		if err != nil {
+			return &kraknError{
+				Code:  codeCannotUnseal,
+				Cause: fmt.Sprintf("Could not decrypt %s for account '%s'", field, account.Name),
+				Try:   "check the passphrase; identity-sealed configs need their age identity file (see krakn private --help)",
+				Err:   err,
+			}
+		}
+		*value = plaintext
+	}
+
+	return nil
+}
+
+// verifySealing opens one existing sealed value, so new values are never
+// sealed with a passphrase other than the one the config already uses
+func (c *Config) verifySealing() error {
+	for _, account := range c.Accounts {
+		for field, sealed := range account.Sealed {
+			identities, err := c.sealIdentities()
+			if err != nil {
+				return err
+			}
+			if _, err := openValue(sealed, identities); err != nil {
+				return &kraknError{
+					Code:  codeCannotUnseal,
+					Cause: fmt.Sprintf("The passphrase does not open the sealed %s of account '%s'", field, account.Name),
+					Try:   "enter the passphrase the config is already sealed with",
+					Err:   err,
+				}
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// sealRecipients returns the age recipients used to encrypt new values
+func (c *Config) sealRecipients() ([]age.Recipient, error) {
+	if c.Sealing == nil {
+		return nil, fmt.Errorf("sealing is not configured")
+	}
+
+	switch c.Sealing.Method {
+	case "identity":
+		identity, err := loadAgeIdentity(c.Sealing.Identity)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Recipient{identity.Recipient()}, nil
+	case "passphrase":
+		passphrase, err := sealPassphrase(true)
+		if err != nil {
+			return nil, err
+		}
+		recipient, err := age.NewScryptRecipient(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Recipient{recipient}, nil
+	}
+
+	return nil, fmt.Errorf("unknown sealing method '%s'", c.Sealing.Method)
+}
+
+// sealIdentities returns the age identities used to decrypt sealed values
+func (c *Config) sealIdentities() ([]age.Identity, error) {
+	if c.Sealing == nil {
+		return nil, fmt.Errorf("config contains sealed fields but no sealing method is configured")
+	}
+
+	switch c.Sealing.Method {
+	case "identity":
+		identity, err := loadAgeIdentity(c.Sealing.Identity)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Identity{identity}, nil
+	case "passphrase":
+		passphrase, err := sealPassphrase(false)
+		if err != nil {
+			return nil, err
+		}
+		identity, err := age.NewScryptIdentity(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Identity{identity}, nil
+	}
+
+	return nil, fmt.Errorf("unknown sealing method '%s'", c.Sealing.Method)
+}
+
+func sealValue(plaintext string, recipients []age.Recipient) (string, error) {
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipients...)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.WriteString(w, plaintext); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func openValue(sealed string, identities []age.Identity) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("corrupt sealed value: %w", err)
+	}
+	r, err := age.Decrypt(bytes.NewReader(data), identities...)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// sealPassphrase returns the sealing passphrase from KRAKN_PASSPHRASE or an interactive prompt
+func sealPassphrase(confirm bool) (string, error) {
+	if cachedPassphrase != "" {
+		return cachedPassphrase, nil
+	}
+	if env := os.Getenv("KRAKN_PASSPHRASE"); env != "" {
+		cachedPassphrase = env
+		return env, nil
+	}
+
+	passphrase, err := readSecret("🔐 Passphrase for private config: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase cannot be empty")
+	}
+
+	if confirm {
+		again, err := readSecret("🔐 Confirm passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+
+	cachedPassphrase = passphrase
+	return passphrase, nil
+}
+
+// loadAgeIdentity reads the first X25519 identity from an age identity file
+func loadAgeIdentity(path string) (*age.X25519Identity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open age identity: %w", err)
+	}
+	defer f.Close()
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse age identity: %w", err)
+	}
+	for _, identity := range identities {
+		if x, ok := identity.(*age.X25519Identity); ok {
+			return x, nil
+		}
+	}
+	return nil, fmt.Errorf("no X25519 identity found in %s", path)
+}
+
+// createAgeIdentity generates a new age identity file readable only by the user
+func createAgeIdentity(path string) error {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return fmt.Errorf("failed to generate age identity: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	content := fmt.Sprintf("# public key: %s\n%s\n", identity.Recipient(), identity)
+	return os.WriteFile(path, []byte(content), 0600)
+}
+
+// readSecret prompts for a value without echoing it when stdin is a terminal
+func readSecret(prompt string) (string, error) {
+	fmt.Print(prompt)
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		secret, err := term.ReadPassword(fd)
+		fmt.Println()
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(secret)), nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	secret, _ := reader.ReadString('\n')
+	return strings.TrimSpace(secret), nil
+}
+
+var privateCmd = &cobra.Command{
+	Use:   "private",
+	Short: "Encrypt private account fields at rest",
+	Long: `Encrypt private account fields (email, API token) in ~/.krakncat/config.json
+with a passphrase or an age identity. Sealed fields are only decrypted when a
+command actually needs them.
+
+This is intended for machines without a system keychain.
+
+Examples:
+  krakn private seal work
+  krakn private unseal work`,
+}
+
+var privateSealCmd = &cobra.Command{
+	Use:   "seal [account-name]",
+	Short: "Encrypt private fields of an account",
+	Long: `Encrypt private fields of an account.
+
+Examples:
+  krakn private seal work                            # Seal email and token with a passphrase
+  krakn private seal work --field token              # Seal only the token
+  krakn private seal work --identity ~/.krakncat/age.key  # Use (or create) an age identity`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountName := args[0]
+		fields, _ := cmd.Flags().GetStringSlice("field")
+		identityPath, _ := cmd.Flags().GetString("identity")
+
+		for _, field := range fields {
+			if !containsString(sealableFields, field) {
+				return fmt.Errorf("❌ Field '%s' cannot be sealed. Sealable fields: %s", field, strings.Join(sealableFields, ", "))
+			}
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		account := config.getAccount(accountName)
+		if account == nil {
+			return config.accountNotFound(accountName)
+		}
+
+		// Configure the sealing method on first use
+		if config.Sealing == nil {
+			if identityPath != "" {
+				identityPath = expandUserPath(identityPath)
+				if _, err := os.Stat(identityPath); os.IsNotExist(err) {
+					if err := createAgeIdentity(identityPath); err != nil {
+						return err
+					}
+					fmt.Printf("🔑 Created age identity at %s\n", identityPath)
+				}
+				config.Sealing = &SealConfig{Method: "identity", Identity: identityPath}
+			} else {
+				config.Sealing = &SealConfig{Method: "passphrase"}
+			}
+		} else if identityPath != "" && config.Sealing.Identity != expandUserPath(identityPath) {
+			return fmt.Errorf("❌ Config is already sealed using %s; unseal all accounts before switching", config.Sealing.Method)
+		}
+
+		// Existing sealed values must be readable before re-sealing
+		if err := config.revealAccount(account); err != nil {
+			return err
+		}
+		// Fields of other accounts must stay readable with the same passphrase
+		if err := config.verifySealing(); err != nil {
+			return err
+		}
+
+		recipients, err := config.sealRecipients()
+		if err != nil {
+			return err
+		}
+
+		sealedCount := 0
+		for _, field := range fields {
+			value := account.fieldValue(field)
+			if *value == "" {
+				continue
+			}
+			sealed, err := sealValue(*value, recipients)
+			if err != nil {
+				return fmt.Errorf("failed to seal %s: %w", field, err)
+			}
+			if account.Sealed == nil {
+				account.Sealed = map[string]string{}
+			}
+			account.Sealed[field] = sealed
+			sealedCount++
+		}
+
+		if sealedCount == 0 {
+			fmt.Printf("ℹ️  Nothing to seal for account '%s'\n", accountName)
+			return nil
+		}
+
+		sealing := *config.Sealing
+		if err := config.update(func(config *Config) error {
+			config.Sealing = &sealing
+			config.putAccount(*account)
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+
+		fmt.Printf("🔒 Sealed %d field(s) for account '%s' using %s\n", sealedCount, accountName, config.Sealing.Method)
+		return nil
+	},
+}
+
+var privateUnsealCmd = &cobra.Command{
+	Use:   "unseal [account-name]",
+	Short: "Decrypt private fields of an account and store them as plaintext",
+	Long: `Decrypt the sealed fields of an account and store them as plaintext again.
+The sealing method is dropped once no account uses it.
+
+Examples:
+  krakn private unseal work`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountName := args[0]
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		account := config.getAccount(accountName)
+		if account == nil {
+			return config.accountNotFound(accountName)
+		}
+
+		if len(account.Sealed) == 0 {
+			fmt.Printf("ℹ️  Account '%s' has no sealed fields\n", accountName)
+			return nil
+		}
+
+		if err := config.revealAccount(account); err != nil {
+			return err
+		}
+		account.Sealed = nil
+
+		if err := config.update(func(config *Config) error {
+			config.putAccount(*account)
+			// Drop the sealing method once nothing depends on it
+			for _, acc := range config.Accounts {
+				if len(acc.Sealed) > 0 {
+					return nil
+				}
+			}
+			config.Sealing = nil
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+
+		fmt.Printf("🔓 Unsealed account '%s'\n", accountName)
+		return nil
+	},
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// removeString returns list without any occurrence of value
+func removeString(list []string, value string) []string {
+	var kept []string
+	for _, item := range list {
+		if item != value {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+func init() {
+	privateSealCmd.Flags().StringSlice("field", sealableFields, "Fields to seal (email, token)")
+	privateSealCmd.Flags().String("identity", "", "Use an age identity file instead of a passphrase (created if missing)")
+	privateCmd.AddCommand(privateSealCmd)
+	privateCmd.AddCommand(privateUnsealCmd)
+	RootCmd.AddCommand(privateCmd)
+}
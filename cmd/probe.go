@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// offlineMode disables all network probing, e.g. on air-gapped machines
+var offlineMode bool
+
+// providerProbe is what could be learned about a self-hosted git server
+type providerProbe struct {
+	Hostname  string
+	SSHPort   string // Port that answered with an SSH banner, empty if none
+	SSHBanner string
+	HTTPS     bool   // Whether https://<hostname> answered
+	Kind      string // "github", "gitlab", "gitea", "forgejo" or "" if unknown
+	Version   string
+	Errors    []string
+}
+
+// probeSSHPorts are tried in order when the requested port does not answer
+var probeSSHPorts = []string{"22", "2222", "443"}
+
+var probeClient = &http.Client{Timeout: 5 * time.Second}
+
+// readSSHBanner connects to host:port and returns the server identification line
+func readSSHBanner(hostname, port string) (string, error) {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(hostname, port), 5*time.Second)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "SSH-") {
+		return "", fmt.Errorf("port %s does not speak SSH", port)
+	}
+	return line, nil
+}
+
+// probeJSON fetches a URL and decodes a JSON body, returning the response for header checks
+func probeJSON(url string, target interface{}) (*http.Response, error) {
+	resp, err := probeClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if target != nil {
+		json.Unmarshal(body, target)
+	}
+	return resp, nil
+}
+
+// detectServerKind identifies the server software from its API responses
+func (p *providerProbe) detectServerKind() {
+	base := "https://" + p.Hostname
+
+	// Forgejo also answers the Gitea endpoint, so check it first
+	var version struct {
+		Version string `json:"version"`
+	}
+	if resp, err := probeJSON(base+"/api/forgejo/v1/version", &version); err == nil && resp.StatusCode == http.StatusOK && version.Version != "" {
+		p.Kind, p.Version = "forgejo", version.Version
+		return
+	}
+	if resp, err := probeJSON(base+"/api/v1/version", &version); err == nil && resp.StatusCode == http.StatusOK && version.Version != "" {
+		p.Kind, p.Version = "gitea", version.Version
+		return
+	}
+
+	var meta struct {
+		InstalledVersion string `json:"installed_version"`
+	}
+	if resp, err := probeJSON(base+"/api/v3/meta", &meta); err == nil {
+		if v := resp.Header.Get("X-GitHub-Enterprise-Version"); v != "" || meta.InstalledVersion != "" {
+			p.Kind, p.Version = "github", v
+			if p.Version == "" {
+				p.Version = meta.InstalledVersion
+			}
+			return
+		}
+	}
+
+	// GitLab requires authentication for /version but still identifies itself
+	var gitlab struct {
+		Version string `json:"version"`
+		Message string `json:"message"`
+	}
+	if resp, err := probeJSON(base+"/api/v4/version", &gitlab); err == nil {
+		if gitlab.Version != "" || (resp.StatusCode == http.StatusUnauthorized && strings.Contains(gitlab.Message, "401")) {
+			p.Kind, p.Version = "gitlab", gitlab.Version
+		}
+	}
+}
+
+// probeProvider checks SSH and HTTPS reachability of a self-hosted git server
+// and tries to identify the software it runs
+func probeProvider(hostname, port string) *providerProbe {
+	probe := &providerProbe{Hostname: hostname}
+
+	ports := []string{port}
+	for _, candidate := range probeSSHPorts {
+		if candidate != port {
+			ports = append(ports, candidate)
+		}
+	}
+	for _, candidate := range ports {
+		if candidate == "" {
+			continue
+		}
+		banner, err := readSSHBanner(hostname, candidate)
+		if err == nil {
+			probe.SSHPort, probe.SSHBanner = candidate, banner
+			break
+		}
+		if candidate == port {
+			probe.Errors = append(probe.Errors, fmt.Sprintf("SSH port %s: %v", candidate, err))
+		}
+	}
+
+	if resp, err := probeClient.Get("https://" + hostname); err != nil {
+		probe.Errors = append(probe.Errors, fmt.Sprintf("HTTPS: %v", err))
+	} else {
+		resp.Body.Close()
+		probe.HTTPS = true
+		probe.detectServerKind()
+	}
+
+	return probe
+}
+
+// applyTo fills provider defaults from the probe results
+func (p *providerProbe) applyTo(provider *Provider) {
+	if p.SSHPort != "" && p.SSHPort != "22" {
+		provider.SSHPort = p.SSHPort
+	}
+
+	base := "https://" + p.Hostname
+	switch p.Kind {
+	case "github":
+		enterprise := githubEnterpriseProvider(p.Hostname)
+		provider.Name, provider.DisplayName, provider.WebURL = enterprise.Name, enterprise.DisplayName, enterprise.WebURL
+	case "gitlab":
+		provider.Name = "gitlab"
+		provider.DisplayName = "GitLab (" + p.Hostname + ")"
+		provider.WebURL = base + "/-/profile/keys"
+	case "gitea", "forgejo":
+		provider.Name = "gitea"
+		provider.DisplayName = "Gitea (" + p.Hostname + ")"
+		if p.Kind == "forgejo" {
+			provider.DisplayName = "Forgejo (" + p.Hostname + ")"
+		}
+		provider.WebURL = base + "/user/settings/keys"
+	}
+}
+
+// print shows the probe results
+func (p *providerProbe) print() {
+	if p.SSHPort != "" {
+		fmt.Printf("   ✅ SSH on port %s (%s)\n", p.SSHPort, p.SSHBanner)
+	} else {
+		fmt.Println("   ❌ No SSH server found")
+	}
+	if p.HTTPS {
+		fmt.Printf("   ✅ HTTPS at https://%s\n", p.Hostname)
+	} else {
+		fmt.Println("   ❌ HTTPS not reachable")
+	}
+	if p.Kind != "" {
+		version := p.Version
+		if version == "" {
+			version = "unknown version"
+		}
+		fmt.Printf("   🔍 Detected %s (%s)\n", p.Kind, version)
+	} else if p.HTTPS {
+		fmt.Println("   ❓ Could not identify the server software")
+	}
+	for _, problem := range p.Errors {
+		fmt.Printf("   ⚠️  %s\n", problem)
+	}
+}
+
+var probeProviderCmd = &cobra.Command{
+	Use:   "probe-provider [hostname]",
+	Short: "Check a self-hosted git server and detect its software",
+	Long: `Check SSH and HTTPS reachability of a self-hosted git server and detect whether
+it runs GitHub Enterprise, GitLab, Gitea or Forgejo. The same probe prefills the
+answers when setting up a custom provider; pass --offline to skip it there.
+
+Examples:
+  krakn probe-provider git.company.com
+  krakn probe-provider git.company.com --port 2222`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hostname := args[0]
+		port, _ := cmd.Flags().GetString("port")
+
+		if !isValidHostname(hostname) {
+			return fmt.Errorf("❌ Invalid hostname format: %s", hostname)
+		}
+		if offlineMode {
+			return fmt.Errorf("❌ Probing is disabled by --offline")
+		}
+
+		spin := startSpinner("Probing " + hostname)
+		probe := probeProvider(hostname, port)
+		spin.Stop()
+		probe.print()
+
+		provider := providerForHostname(hostname)
+		probe.applyTo(&provider)
+		fmt.Println()
+		fmt.Printf("🌐 Suggested provider: %s\n", provider.DisplayName)
+		fmt.Printf("   SSH: %s@%s", provider.SSHUser, provider.Hostname)
+		if provider.SSHPort != "" {
+			fmt.Printf(":%s", provider.SSHPort)
+		}
+		fmt.Printf("\n   Keys: %s\n", provider.WebURL)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.PersistentFlags().BoolVar(&offlineMode, "offline", false, "Never contact remote servers (skips provider probing)")
+	probeProviderCmd.Flags().String("port", "22", "SSH port to try first")
+	RootCmd.AddCommand(probeProviderCmd)
+}
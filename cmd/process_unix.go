@@ -0,0 +1,11 @@
+//go:build !windows
+
+package cmd
+
+import "syscall"
+
+// processAlive reports whether a process with the given pid exists
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package cmd
+
+import "golang.org/x/sys/windows"
+
+// processAlive reports whether a process with the given pid is still running
+func processAlive(pid int) bool {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer windows.CloseHandle(handle)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(handle, &code); err != nil {
+		return false
+	}
+	const stillActive = 259
+	return code == stillActive
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// AccountProfile is the account's profile as reported by the provider's API.
+// It is stored so krakn can show verified details without a network call.
+type AccountProfile struct {
+	ID          int64     `json:"id"`
+	Login       string    `json:"login"`
+	DisplayName string    `json:"display_name,omitempty"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+	VerifiedAt  time.Time `json:"verified_at"`
+}
+
+// CommitName returns the name used for user.name: the provider's display
+// name when the profile has one, otherwise the username
+func (a *Account) CommitName() string {
+	if a.Profile != nil && a.Profile.DisplayName != "" {
+		return a.Profile.DisplayName
+	}
+	return a.Username
+}
+
+// fetchAccountProfile asks the provider who the account's token belongs to
+func fetchAccountProfile(config *Config, account *Account) (*AccountProfile, error) {
+	provider := account.GetProvider()
+	if provider.Name == "github" {
+		client, err := newGitHubClient(config, account)
+		if err != nil {
+			return nil, err
+		}
+		var user struct {
+			ID        int64  `json:"id"`
+			Login     string `json:"login"`
+			Name      string `json:"name"`
+			AvatarURL string `json:"avatar_url"`
+		}
+		if err := client.get("/user", &user); err != nil {
+			return nil, err
+		}
+		return &AccountProfile{ID: user.ID, Login: user.Login, DisplayName: user.Name, AvatarURL: user.AvatarURL, VerifiedAt: time.Now()}, nil
+	}
+
+	if offlineMode {
+		return nil, fmt.Errorf("❌ The %s API is not available with --offline", provider.DisplayName)
+	}
+	if err := config.revealAccount(account); err != nil {
+		return nil, err
+	}
+	if account.Token == "" {
+		return nil, fmt.Errorf("❌ Account '%s' has no API token. Add one with 'krakn token set %s'", account.Name, account.Name)
+	}
+
+	if provider.Name != "gitlab" && provider.Name != "gitea" && provider.Name != "forgejo" {
+		return nil, fmt.Errorf("❌ Profiles are not supported for %s accounts", provider.DisplayName)
+	}
+
+	// GitLab calls the login "username", Gitea calls the display name "full_name"
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Username  string `json:"username"`
+		Name      string `json:"name"`
+		FullName  string `json:"full_name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := forgeRequest(account, http.MethodGet, "/user", nil, &user); err != nil {
+		return nil, err
+	}
+	profile := &AccountProfile{ID: user.ID, Login: user.Login, DisplayName: user.Name, AvatarURL: user.AvatarURL, VerifiedAt: time.Now()}
+	if profile.Login == "" {
+		profile.Login = user.Username
+	}
+	if profile.DisplayName == "" {
+		profile.DisplayName = user.FullName
+	}
+	return profile, nil
+}
+
+// refreshAccountProfile fetches and stores the profile of an account and
+// reports the result. The account is saved by the caller.
+func refreshAccountProfile(config *Config, account *Account) error {
+	spin := startSpinner("Fetching the profile of " + account.Name)
+	profile, err := fetchAccountProfile(config, account)
+	spin.Stop()
+	if err != nil {
+		return err
+	}
+
+	account.Profile = profile
+	name := profile.Login
+	if profile.DisplayName != "" {
+		name = fmt.Sprintf("%s (@%s)", profile.DisplayName, profile.Login)
+	}
+	fmt.Printf("✅ Verified '%s' as %s, id %d\n", account.Name, name, profile.ID)
+	if account.Username != "" && !strings.EqualFold(account.Username, profile.Login) {
+		fmt.Printf("⚠️  The token belongs to '%s', but the account's username is '%s'\n", profile.Login, account.Username)
+	}
+	return nil
+}
+
+var accountRefreshCmd = &cobra.Command{
+	Use:   "refresh [account-name]",
+	Short: "Fetch display names and avatars from the provider",
+	Long: `Fetch the provider profile (display name, avatar URL and account id) of accounts
+with an API token and store it. The display name becomes the default commit name
+used by 'krakn use' and 'krakn global', and 'krakn list' marks the account as
+verified.
+
+Examples:
+  krakn account refresh          # All accounts with a token
+  krakn account refresh work`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		names := args
+		if len(names) == 0 {
+			for _, account := range config.Accounts {
+				if account.hasToken() {
+					names = append(names, account.Name)
+				}
+			}
+			if len(names) == 0 {
+				fmt.Println("📭 No account has an API token. Add one with 'krakn token set <account>'.")
+				return nil
+			}
+		}
+
+		failed := 0
+		for _, name := range names {
+			account := config.getAccount(name)
+			if account == nil {
+				return config.accountNotFound(name)
+			}
+			if err := refreshAccountProfile(config, account); err != nil {
+				fmt.Printf("❌ %s: %s\n", name, strings.TrimPrefix(err.Error(), "❌ "))
+				failed++
+				continue
+			}
+			if err := config.addAccount(*account); err != nil {
+				return errSaveConfig(err)
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("❌ %d profile(s) could not be fetched", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	accountCmd.AddCommand(accountRefreshCmd)
+}
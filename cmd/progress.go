@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// plainOutput disables animated output, e.g. for logs and screen readers
+var plainOutput bool
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// isInteractiveOutput reports whether stdout is a terminal that can be redrawn
+func isInteractiveOutput() bool {
+	return !plainOutput && os.Getenv("TERM") != "dumb" && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// spinner shows that a long operation is still running. It writes to stderr,
+// so it never ends up in output redirected for scripts, e.g. a JSON report.
+// When stdout is a terminal it animates on a single line; otherwise the
+// message is printed once.
+type spinner struct {
+	mu      sync.Mutex
+	message string
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// startSpinner displays message until Stop is called
+func startSpinner(message string) *spinner {
+	s := &spinner{message: message}
+	if !isInteractiveOutput() {
+		fmt.Fprintf(os.Stderr, "⏳ %s...\n", message)
+		return s
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for frame := 0; ; frame++ {
+			s.mu.Lock()
+			fmt.Fprintf(os.Stderr, "\r\033[K%s %s", spinnerFrames[frame%len(spinnerFrames)], s.message)
+			s.mu.Unlock()
+
+			select {
+			case <-s.stop:
+				fmt.Fprint(os.Stderr, "\r\033[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return s
+}
+
+// Update replaces the message, e.g. to show a running count
+func (s *spinner) Update(message string) {
+	s.mu.Lock()
+	s.message = message
+	s.mu.Unlock()
+}
+
+// Stop clears the spinner line. It is safe to call more than once.
+func (s *spinner) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+}
+
+func init() {
+	RootCmd.PersistentFlags().BoolVar(&plainOutput, "plain", false, "Disable spinners and other animated output")
+}
@@ -1,41 +1,31 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"net/url"
-	"os"
-	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/spf13/cobra"
 )
 
 // Provider represents a Git hosting provider
 type Provider struct {
-	Name         string `json:"name"`          // "github", "gitlab", "gitea", "custom"
-	DisplayName  string `json:"display_name"` // "GitHub", "GitLab", "Gitea"
-	Hostname     string `json:"hostname"`     // "github.com", "gitlab.com", "git.company.com"
-	SSHUser      string `json:"ssh_user"`     // Usually "git"
-	SSHPort      string `json:"ssh_port,omitempty"` // SSH port, empty for default (22)
-	WebURL       string `json:"web_url"`      // For SSH key management URL
-	KeySuffix    string `json:"key_suffix"`   // "gh", "gl", "gitea"
-}
-
-// Account represents a user account on a specific provider
-type AccountV2 struct {
-	Name         string   `json:"name"`          // "personal", "work"
-	Email        string   `json:"email"`
-	SSHKey       string   `json:"ssh_key"`
-	Username     string   `json:"username"`
-	Provider     Provider `json:"provider"`
-	IsDefault    bool     `json:"is_default"`
+	Name          string `json:"name"`                     // "github", "gitlab", "gitea", "custom"
+	DisplayName   string `json:"display_name"`             // "GitHub", "GitLab", "Gitea"
+	Hostname      string `json:"hostname"`                 // "github.com", "gitlab.com", "git.company.com"
+	SSHUser       string `json:"ssh_user"`                 // Usually "git"
+	SSHPort       string `json:"ssh_port,omitempty"`       // SSH port, empty for default (22)
+	WebURL        string `json:"web_url"`                  // For SSH key management URL
+	KeySuffix     string `json:"key_suffix"`               // "gh", "gl", "gitea"
+	BannerPattern string `json:"banner_pattern,omitempty"` // Regexp capturing the username in the server's 'ssh -T' greeting, for customized or localized servers
 }
 
-type ConfigV2 struct {
-	Accounts        []AccountV2 `json:"accounts"`
-	CurrentAccount  string      `json:"current_account"`
-	MigrationDone   bool        `json:"migration_done"`
-	ConfigVersion   int         `json:"config_version"` // For future migrations
-}
+// configVersion is the config format krakncat writes. Version 2 records the
+// provider of every account that is not on GitHub; version 1 configs could
+// leave it out for accounts linked to an existing ~/.ssh/config alias.
+const configVersion = 2
 
 // Predefined providers
 var DefaultProviders = map[string]Provider{
@@ -63,193 +53,347 @@ var DefaultProviders = map[string]Provider{
 		WebURL:      "https://gitea.com/user/settings/keys",
 		KeySuffix:   "gitea",
 	},
+	"bitbucket": {
+		Name:        "bitbucket",
+		DisplayName: "Bitbucket",
+		Hostname:    "bitbucket.org",
+		SSHUser:     "git",
+		WebURL:      "https://bitbucket.org/account/settings/ssh-keys/",
+		KeySuffix:   "bb",
+	},
+	// Codeberg runs Forgejo, so the Forgejo API covers key upload and forks
+	"codeberg": {
+		Name:        "forgejo",
+		DisplayName: "Codeberg",
+		Hostname:    "codeberg.org",
+		SSHUser:     "git",
+		WebURL:      "https://codeberg.org/user/settings/keys",
+		KeySuffix:   "cb",
+	},
+	// Azure DevOps serves SSH on its own host, with v3/<org>/<project>/<repo>
+	// paths (see GetSSHCloneURL). Keys are added per organization under
+	// User settings → SSH public keys.
+	"azure-devops": {
+		Name:        "azure-devops",
+		DisplayName: "Azure DevOps",
+		Hostname:    "ssh.dev.azure.com",
+		SSHUser:     "git",
+		WebURL:      "https://dev.azure.com/",
+		KeySuffix:   "ado",
+	},
+	"sourcehut": {
+		Name:        "sourcehut",
+		DisplayName: "sourcehut",
+		Hostname:    "git.sr.ht",
+		SSHUser:     "git",
+		WebURL:      "https://meta.sr.ht/keys",
+		KeySuffix:   "srht",
+	},
+	// The sandbox is the mock SSH server 'krakn demo' runs (see demo.go)
+	"sandbox": {
+		Name:        "sandbox",
+		DisplayName: "Sandbox",
+		Hostname:    "localhost",
+		SSHUser:     "git",
+		KeySuffix:   "sandbox",
+	},
 }
 
-// Helper functions for the new multi-provider system
+// providerPresets lists the predefined providers in the order they are
+// offered; ghes asks for the instance's hostname, and the sandbox only exists
+// for 'krakn demo'
+var providerPresets = []string{"github", "ghes", "gitlab", "gitea", "bitbucket", "codeberg", "azure-devops", "sourcehut"}
 
-func (a *AccountV2) GetSSHHost() string {
-	return fmt.Sprintf("%s-%s", a.Provider.Hostname, a.Name)
+// completeProvider completes --provider with the presets
+func completeProvider(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var names []string
+	for _, name := range append(providerPresets, "custom") {
+		if provider, ok := DefaultProviders[name]; ok {
+			name += "\t" + provider.DisplayName + " (" + provider.Hostname + ")"
+		} else if name == "ghes" {
+			name += "\tGitHub Enterprise Server (ghes:<hostname>, or asks for it)"
+		}
+		names = append(names, name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
 }
 
-func (a *AccountV2) GetSSHCloneURL(repo string) string {
-	return fmt.Sprintf("git@%s:%s", a.GetSSHHost(), repo)
-}
+// azureWebHostnames are the web hosts of Azure DevOps, whose repositories are
+// cloned over SSH from ssh.dev.azure.com
+var azureWebHostnames = []string{"dev.azure.com", "visualstudio.com"}
 
-func (a *AccountV2) GetKeyPath() string {
-	if a.SSHKey != "" {
-		return a.SSHKey
+// sshHostnameFor returns the SSH host serving the repositories of a web
+// hostname, which differs only for Azure DevOps
+func sshHostnameFor(hostname string) string {
+	hostname = strings.ToLower(hostname)
+	for _, web := range azureWebHostnames {
+		if hostname == web || strings.HasSuffix(hostname, "."+web) {
+			return DefaultProviders["azure-devops"].Hostname
+		}
 	}
-	homeDir, _ := os.UserHomeDir()
-	return filepath.Join(homeDir, ".ssh", fmt.Sprintf("id_ed25519_%s_%s", a.Provider.KeySuffix, a.Name))
+	return hostname
 }
 
-func (a *AccountV2) GenerateSSHConfig() string {
-	config := fmt.Sprintf(`
-Host %s
-  HostName %s
-  User %s
-  IdentityFile %s`, a.GetSSHHost(), a.Provider.Hostname, a.Provider.SSHUser, a.GetKeyPath())
+// azureRepoPath returns the org/project/_git/repo path of an Azure DevOps web
+// URL. The older https://org.visualstudio.com/project/_git/repo form names the
+// organization in the hostname and may put DefaultCollection before the
+// project; other hosts keep their path.
+func azureRepoPath(hostname, path string) string {
+	org, ok := strings.CutSuffix(strings.ToLower(hostname), ".visualstudio.com")
+	if !ok || org == "" || strings.Contains(org, ".") {
+		return path
+	}
+	path = strings.Trim(path, "/")
+	if first, rest, ok := strings.Cut(path, "/"); ok && strings.EqualFold(first, "DefaultCollection") {
+		path = rest
+	}
+	return org + "/" + path
+}
 
-	// Add port if not default
-	if a.Provider.SSHPort != "" && a.Provider.SSHPort != "22" {
-		config += fmt.Sprintf("\n  Port %s", a.Provider.SSHPort)
+// GetSSHCloneURL returns the SSH clone URL of a repository on the provider
+// through host, the provider hostname or an account's alias. path is
+// owner/repo as shown on the web; Azure DevOps paths (org/project/_git/repo)
+// become v3/org/project/repo, and sourcehut and Azure DevOps take no .git.
+func (p Provider) GetSSHCloneURL(host, path string) string {
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	switch p.Name {
+	case "azure-devops":
+		path = strings.TrimPrefix(path, "v3/")
+		if parts := strings.Split(path, "/"); len(parts) == 4 && parts[2] == "_git" {
+			path = strings.Join([]string{parts[0], parts[1], parts[3]}, "/")
+		}
+		path = "v3/" + path
+	case "sourcehut":
+	default:
+		path += ".git"
 	}
-	
-	config += "\n"
-	return config
+	return fmt.Sprintf("%s@%s:%s", p.SSHUser, host, path)
 }
 
-// Migration function to convert old config to new format
-func migrateConfigToV2(oldConfig *Config) *ConfigV2 {
-	newConfig := &ConfigV2{
-		Accounts:       []AccountV2{},
-		CurrentAccount: oldConfig.CurrentAccount,
-		MigrationDone:  oldConfig.MigrationDone,
-		ConfigVersion:  2,
-	}
-
-	// Convert old accounts to new format (assume GitHub)
-	for _, oldAccount := range oldConfig.Accounts {
-		newAccount := AccountV2{
-			Name:      oldAccount.Name,
-			Email:     oldAccount.Email,
-			SSHKey:    oldAccount.SSHKey,
-			Username:  oldAccount.Username,
-			Provider:  DefaultProviders["github"], // Default to GitHub
-			IsDefault: oldAccount.IsDefault,
+// upgradeProviders migrates a version 1 config in memory, like
+// upgradeAuthMethods: accounts linked to an existing Host alias without a
+// provider get the provider of the alias's HostName, so self-hosted GitLab or
+// Gitea accounts are no longer taken for GitHub ones. The next save records
+// version 2.
+func (c *Config) upgradeProviders() {
+	if c.ConfigVersion >= configVersion {
+		return
+	}
+	c.ConfigVersion = configVersion
+
+	var blocks []sshHostBlock
+	for i := range c.Accounts {
+		account := &c.Accounts[i]
+		if account.Provider != nil || account.SSHHost == "" {
+			continue
+		}
+		if blocks == nil {
+			blocks, _ = readSSHConfig()
+		}
+		for j := range blocks {
+			if blocks[j].alias() != account.SSHHost || blocks[j].hostName() == "" {
+				continue
+			}
+			provider := providerForHostname(blocks[j].hostName())
+			if provider.Name == "github" && provider.Hostname == DefaultProviders["github"].Hostname {
+				break
+			}
+			if user := blocks[j].get("User"); user != "" {
+				provider.SSHUser = user
+			}
+			if port := blocks[j].get("Port"); port != "" && port != "22" {
+				provider.SSHPort = port
+			}
+			account.Provider = &provider
+			tracef(traceRead, "Account '%s' is on %s (from Host %s)", account.Name, provider.Hostname, account.SSHHost)
+			break
 		}
-		newConfig.Accounts = append(newConfig.Accounts, newAccount)
 	}
+}
 
-	return newConfig
+// githubEnterpriseProvider returns the provider of a GitHub Enterprise
+// Server instance. Its API is https://<hostname>/api/v3 (see githubAPIBase)
+// and keys are added on the instance's own settings page.
+func githubEnterpriseProvider(hostname string) Provider {
+	return Provider{
+		Name:        "github",
+		DisplayName: fmt.Sprintf("GitHub Enterprise (%s)", hostname),
+		Hostname:    hostname,
+		SSHUser:     "git",
+		WebURL:      fmt.Sprintf("https://%s/settings/ssh/new", hostname),
+		KeySuffix:   "ghe",
+	}
 }
 
-// Enhanced provider detection from SSH config
-func detectProviderFromSSHHost(host string) *Provider {
-	if strings.Contains(host, "github.com") {
-		provider := DefaultProviders["github"]
-		return &provider
+// createGHESProvider sets up a GitHub Enterprise Server instance: it asks for
+// the hostname unless one is given, and checks the instance's API
+func createGHESProvider(hostname string, reader *bufio.Reader) (Provider, error) {
+	if hostname == "" {
+		fmt.Print("🏢 GitHub Enterprise hostname (e.g., github.company.com): ")
+		hostname = promptLine(reader, "")
 	}
-	if strings.Contains(host, "gitlab.com") {
-		provider := DefaultProviders["gitlab"]
-		return &provider
+	hostname = strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(hostname), "https://"), "/"))
+	if !isValidHostname(hostname) || !strings.Contains(hostname, ".") {
+		return Provider{}, fmt.Errorf("❌ Invalid hostname: %s", hostname)
 	}
-	if strings.Contains(host, "gitea") {
-		provider := DefaultProviders["gitea"]
-		return &provider
+	if hostname == DefaultProviders["github"].Hostname {
+		return Provider{}, fmt.Errorf("❌ github.com is not an Enterprise Server; use --provider github")
 	}
-	
-	// For custom/self-hosted instances
-	parts := strings.Split(host, "-")
-	if len(parts) >= 2 {
-		hostname := strings.Join(parts[:len(parts)-1], "-")
-		return &Provider{
-			Name:        "custom",
-			DisplayName: "Custom Git Host",
-			Hostname:    hostname,
-			SSHUser:     "git",
-			WebURL:      fmt.Sprintf("https://%s", hostname),
-			KeySuffix:   "custom",
+
+	provider := githubEnterpriseProvider(hostname)
+	if offlineMode {
+		fmt.Println("📴 Offline mode: skipping the instance check")
+	} else {
+		spin := startSpinner("Checking " + hostname)
+		probe := probeProvider(hostname, "22")
+		spin.Stop()
+		switch {
+		case probe.Kind == "github":
+			fmt.Printf("✅ GitHub Enterprise Server %s\n", probe.Version)
+		case probe.Kind != "":
+			fmt.Printf("⚠️  %s looks like %s, not GitHub Enterprise; continuing anyway\n", hostname, probe.Kind)
+		default:
+			fmt.Printf("⚠️  Could not reach the API of %s (VPN?); continuing anyway\n", hostname)
+		}
+		if probe.SSHPort != "" && probe.SSHPort != "22" {
+			provider.SSHPort = probe.SSHPort
 		}
 	}
-	
-	return nil
+	fmt.Printf("🔗 API: %s\n", githubAPIBase(provider))
+	fmt.Printf("🔑 Keys: %s\n", provider.WebURL)
+	return provider, nil
 }
 
-// Interactive provider selection
-func selectProvider() (*Provider, error) {
-	fmt.Println("\n🌐 Select Git hosting provider:")
-	fmt.Println("   1. GitHub (github.com)")
-	fmt.Println("   2. GitLab (gitlab.com)")  
-	fmt.Println("   3. Gitea (gitea.com)")
-	fmt.Println("   4. Custom/Self-hosted (e.g., git.company.com, code.myorg.io)")
-	
-	var choice int
-	fmt.Print("Enter choice (1-4): ")
-	if _, err := fmt.Scanf("%d", &choice); err != nil {
-		return nil, fmt.Errorf("invalid input: %w", err)
-	}
-	
-	switch choice {
-	case 1:
-		provider := DefaultProviders["github"]
-		return &provider, nil
-	case 2:
-		provider := DefaultProviders["gitlab"]
-		return &provider, nil
-	case 3:
-		provider := DefaultProviders["gitea"]
-		return &provider, nil
-	case 4:
-		return createCustomProvider()
-	default:
-		return nil, fmt.Errorf("invalid choice")
+// resolveProvider turns a --provider value into a provider: one of
+// providerPresets or sandbox, ghes:<hostname>, the hostname of a self-hosted
+// server, or "custom" to be asked for the server's settings
+func resolveProvider(value string, reader *bufio.Reader) (Provider, error) {
+	if provider, ok := builtinProvider(value); ok {
+		if provider.Name == "sandbox" && provider.SSHPort == "" {
+			return Provider{}, fmt.Errorf("❌ The sandbox provider only exists while 'krakn demo --serve' runs; set KRAKN_SANDBOX_PORT to its port")
+		}
+		return provider, nil
+	}
+	if name, hostname, _ := strings.Cut(value, ":"); strings.EqualFold(name, "ghes") {
+		return createGHESProvider(hostname, reader)
 	}
+	if strings.EqualFold(value, "custom") {
+		provider, err := createCustomProvider(reader)
+		if err != nil {
+			return Provider{}, err
+		}
+		return *provider, nil
+	}
+	if !isValidHostname(value) || !strings.Contains(value, ".") {
+		return Provider{}, fmt.Errorf("❌ Unknown provider '%s'. Use %s, custom or the hostname of a self-hosted server", value, strings.Join(providerPresets, ", "))
+	}
+	return providerForHostname(sshHostnameFor(value)), nil
 }
 
-func createCustomProvider() (*Provider, error) {
+// providerForHostname infers the provider for a real SSH hostname.
+// Self-hosted GitLab/Gitea instances keep their provider type with the custom hostname.
+func providerForHostname(hostname string) Provider {
+	hostname = strings.ToLower(hostname)
+	for _, provider := range DefaultProviders {
+		if provider.Hostname == hostname && provider.Name != "sandbox" {
+			return provider
+		}
+	}
+
+	if strings.Contains(hostname, "github") {
+		return githubEnterpriseProvider(hostname)
+	}
+	for _, name := range []string{"gitlab", "gitea"} {
+		if strings.Contains(hostname, name) {
+			provider := DefaultProviders[name]
+			provider.DisplayName = fmt.Sprintf("%s (%s)", provider.DisplayName, hostname)
+			provider.Hostname = hostname
+			provider.WebURL = fmt.Sprintf("https://%s", hostname)
+			return provider
+		}
+	}
+
+	return Provider{
+		Name:        "custom",
+		DisplayName: hostname,
+		Hostname:    hostname,
+		SSHUser:     "git",
+		WebURL:      fmt.Sprintf("https://%s", hostname),
+		KeySuffix:   generateKeySuffix(hostname),
+	}
+}
+
+// createCustomProvider asks for the settings of a self-hosted server,
+// prefilled by probing it
+func createCustomProvider(reader *bufio.Reader) (*Provider, error) {
 	fmt.Println("\n🔧 Custom Git Provider Setup")
 	fmt.Println("   Configure your self-hosted Git server or custom Git hosting")
-	
+
 	// Get hostname
 	fmt.Print("\n🌐 Enter hostname (e.g., git.company.com, code.myorg.io): ")
-	var hostname string
-	if _, err := fmt.Scanf("%s", &hostname); err != nil {
-		return nil, fmt.Errorf("invalid hostname: %w", err)
-	}
-	
+	hostname := promptLine(reader, "")
+
 	// Validate hostname format
 	if !isValidHostname(hostname) {
 		return nil, fmt.Errorf("invalid hostname format: %s", hostname)
 	}
-	
-	// Get display name
-	fmt.Printf("📝 Enter display name [%s]: ", hostname)
-	var displayName string
-	if _, err := fmt.Scanf("%s", &displayName); err != nil || displayName == "" {
-		displayName = hostname // fallback to hostname
+
+	// Probe the server to prefill the remaining answers
+	defaults := Provider{
+		Name:        "custom",
+		DisplayName: hostname,
+		SSHUser:     "git",
+		SSHPort:     "22",
+		WebURL:      fmt.Sprintf("https://%s", hostname),
 	}
-	
-	// Get SSH user (default: git)
-	fmt.Print("👤 SSH user [git]: ")
-	var sshUser string
-	if _, err := fmt.Scanf("%s", &sshUser); err != nil || sshUser == "" {
-		sshUser = "git"
+	if offlineMode {
+		fmt.Println("📴 Offline mode: skipping server probe")
+	} else {
+		spin := startSpinner("Probing " + hostname)
+		probe := probeProvider(hostname, "22")
+		spin.Stop()
+		probe.print()
+		probe.applyTo(&defaults)
+		if defaults.SSHPort == "" {
+			defaults.SSHPort = "22"
+		}
 	}
-	
+
+	// Get display name
+	fmt.Printf("📝 Enter display name [%s]: ", defaults.DisplayName)
+	displayName := promptLine(reader, defaults.DisplayName)
+
+	// Get SSH user (default: git)
+	fmt.Printf("👤 SSH user [%s]: ", defaults.SSHUser)
+	sshUser := promptLine(reader, defaults.SSHUser)
+
 	// Get SSH port if non-standard
-	fmt.Print("🔌 SSH port [22]: ")
-	var port string
-	if _, err := fmt.Scanf("%s", &port); err != nil || port == "" {
-		port = "22"
-	}
-	
+	fmt.Printf("🔌 SSH port [%s]: ", defaults.SSHPort)
+	port := promptLine(reader, defaults.SSHPort)
+
 	// Ask about SSH key management URL
-	fmt.Printf("🔗 SSH key management URL [https://%s]: ", hostname)
-	var webURL string
-	if _, err := fmt.Scanf("%s", &webURL); err != nil || webURL == "" {
-		webURL = fmt.Sprintf("https://%s", hostname)
-	}
-	
+	fmt.Printf("🔗 SSH key management URL [%s]: ", defaults.WebURL)
+	webURL := promptLine(reader, defaults.WebURL)
+
 	// Generate key suffix from hostname
 	keySuffix := generateKeySuffix(hostname)
 	fmt.Printf("🔑 SSH key suffix will be: %s\n", keySuffix)
-	
+
 	// Create provider
 	provider := &Provider{
-		Name:        "custom",
+		Name:        defaults.Name,
 		DisplayName: displayName,
 		Hostname:    hostname,
 		SSHUser:     sshUser,
 		WebURL:      webURL,
 		KeySuffix:   keySuffix,
 	}
-	
+
 	// Add SSH port if non-standard
 	if port != "22" {
 		provider.SSHPort = port
 	}
-	
+
 	// Confirm configuration
 	fmt.Println("\n✅ Custom provider configuration:")
 	fmt.Printf("   Name: %s\n", provider.DisplayName)
@@ -260,41 +404,46 @@ func createCustomProvider() (*Provider, error) {
 	}
 	fmt.Printf("   Web URL: %s\n", provider.WebURL)
 	fmt.Printf("   Key Suffix: %s\n", provider.KeySuffix)
-	
+
 	fmt.Print("\n💾 Save this configuration? [Y/n]: ")
-	var confirm string
-	if _, err := fmt.Scanf("%s", &confirm); err != nil {
-		confirm = "y"
-	}
-	
+	confirm := promptLine(reader, "y")
 	if strings.ToLower(confirm) == "n" {
 		return nil, fmt.Errorf("configuration cancelled")
 	}
-	
+
 	return provider, nil
 }
 
 // Helper functions for custom provider validation and configuration
 
+// promptLine reads a single answer, returning fallback when it is left empty
+func promptLine(reader *bufio.Reader, fallback string) string {
+	answer, _ := reader.ReadString('\n')
+	if answer = strings.TrimSpace(answer); answer == "" {
+		return fallback
+	}
+	return answer
+}
+
 // isValidHostname validates if a hostname is properly formatted
 func isValidHostname(hostname string) bool {
 	if hostname == "" {
 		return false
 	}
-	
+
 	// Basic hostname validation
 	hostnameRegex := regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9\-\.]*[a-zA-Z0-9])?$`)
 	if !hostnameRegex.MatchString(hostname) {
 		return false
 	}
-	
+
 	// Check if it's a valid URL-like hostname
 	if strings.Contains(hostname, "://") {
 		if _, err := url.Parse(hostname); err != nil {
 			return false
 		}
 	}
-	
+
 	return true
 }
 
@@ -304,7 +453,7 @@ func generateKeySuffix(hostname string) string {
 	hostname = strings.TrimPrefix(hostname, "git.")
 	hostname = strings.TrimPrefix(hostname, "code.")
 	hostname = strings.TrimPrefix(hostname, "source.")
-	
+
 	// Split by dots and take meaningful parts
 	parts := strings.Split(hostname, ".")
 	if len(parts) >= 2 {
@@ -316,11 +465,11 @@ func generateKeySuffix(hostname string) string {
 		}
 		return suffix
 	}
-	
+
 	// Fallback: use first 8 characters
 	if len(hostname) > 8 {
 		return hostname[:8]
 	}
-	
+
 	return hostname
 }
@@ -0,0 +1,30 @@
+package cmd
+
+import "testing"
+
+func TestAzureDevOpsCloneURL(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"https://dev.azure.com/org/project/_git/repo", "git@ssh.dev.azure.com:v3/org/project/repo"},
+		{"https://me@dev.azure.com/org/project/_git/repo", "git@ssh.dev.azure.com:v3/org/project/repo"},
+		{"https://org.visualstudio.com/project/_git/repo", "git@ssh.dev.azure.com:v3/org/project/repo"},
+		{"https://org.visualstudio.com/DefaultCollection/project/_git/repo", "git@ssh.dev.azure.com:v3/org/project/repo"},
+		{"git@ssh.dev.azure.com:v3/org/project/repo", "git@ssh.dev.azure.com:v3/org/project/repo"},
+	}
+	provider := DefaultProviders["azure-devops"]
+	for _, tt := range tests {
+		host, path, err := parseUpstream(&Config{}, tt.arg)
+		if err != nil {
+			t.Errorf("parseUpstream(%q): %v", tt.arg, err)
+			continue
+		}
+		if host != provider.Hostname {
+			t.Errorf("parseUpstream(%q) host = %q, want %q", tt.arg, host, provider.Hostname)
+		}
+		if got := provider.GetSSHCloneURL(host, path); got != tt.want {
+			t.Errorf("clone URL of %q = %q, want %q", tt.arg, got, tt.want)
+		}
+	}
+}
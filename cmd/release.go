@@ -0,0 +1,408 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+// releaseService is a long-running command packagers should offer as a service
+type releaseService struct {
+	Name        string   // Service name, e.g. "krakn-watch"
+	Args        []string // Arguments passed to krakn
+	Description string
+}
+
+var releaseServices []releaseService
+
+// registerReleaseService adds a service definition to generated packaging files
+func registerReleaseService(service releaseService) {
+	releaseServices = append(releaseServices, service)
+}
+
+// missingChecksum marks a hash that must be filled in before publishing
+const missingChecksum = "MISSING-CHECKSUM"
+
+// releaseInfo is the metadata every packaging template is rendered from
+type releaseInfo struct {
+	Version     string
+	Description string
+	Long        string
+	Homepage    string
+	License     string
+	Maintainer  string
+	BaseURL     string
+	Checksums   map[string]string // Archive file name → sha256
+	Services    []releaseService
+	Missing     []string
+}
+
+// archiveName returns the release archive name for a platform
+func (r *releaseInfo) archiveName(goos, goarch string) string {
+	ext := "tar.gz"
+	if goos == "windows" {
+		ext = "zip"
+	}
+	return fmt.Sprintf("krakn_%s_%s_%s.%s", r.Version, goos, goarch, ext)
+}
+
+// URL returns the download URL of a platform archive
+func (r *releaseInfo) URL(goos, goarch string) string {
+	return strings.TrimSuffix(r.BaseURL, "/") + "/" + r.archiveName(goos, goarch)
+}
+
+// SHA256 returns the checksum of a platform archive, recording it when missing
+func (r *releaseInfo) SHA256(goos, goarch string) string {
+	name := r.archiveName(goos, goarch)
+	if sum, ok := r.Checksums[name]; ok {
+		return sum
+	}
+	if !containsString(r.Missing, name) {
+		r.Missing = append(r.Missing, name)
+	}
+	return missingChecksum
+}
+
+// readChecksums parses a "sha256  filename" list as produced by sha256sum
+func readChecksums(path string) (map[string]string, error) {
+	sums := map[string]string{}
+	if path == "" {
+		return sums, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checksums: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 {
+			sums[strings.TrimPrefix(fields[1], "*")] = fields[0]
+		}
+	}
+	return sums, scanner.Err()
+}
+
+// debianDescription formats the root description as indented Debian paragraph lines
+func debianDescription(long string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(long), "\n") {
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, " .")
+			continue
+		}
+		lines = append(lines, " "+line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+var brewFormulaTemplate = `class Krakn < Formula
+  desc "{{.Description}}"
+  homepage "{{.Homepage}}"
+  version "{{.Version}}"
+  license "{{.License}}"
+
+  on_macos do
+    on_arm do
+      url "{{.URL "darwin" "arm64"}}"
+      sha256 "{{.SHA256 "darwin" "arm64"}}"
+    end
+    on_intel do
+      url "{{.URL "darwin" "amd64"}}"
+      sha256 "{{.SHA256 "darwin" "amd64"}}"
+    end
+  end
+
+  on_linux do
+    on_arm do
+      url "{{.URL "linux" "arm64"}}"
+      sha256 "{{.SHA256 "linux" "arm64"}}"
+    end
+    on_intel do
+      url "{{.URL "linux" "amd64"}}"
+      sha256 "{{.SHA256 "linux" "amd64"}}"
+    end
+  end
+
+  depends_on "git"
+
+  def install
+    bin.install "krakn"
+    generate_completions_from_executable(bin/"krakn", "completion")
+    system bin/"krakn", "docs", "man", buildpath/"man"
+    man1.install Dir[buildpath/"man/*.1"]
+  end
+{{range .Services}}
+  service do
+    run [opt_bin/"krakn"{{range .Args}}, "{{.}}"{{end}}]
+    keep_alive true
+    log_path var/"log/{{.Name}}.log"
+    error_log_path var/"log/{{.Name}}.log"
+  end
+{{end}}
+  test do
+    assert_match version.to_s, shell_output("#{bin}/krakn version")
+  end
+end
+`
+
+var debControlTemplate = `Package: krakn
+Version: {{.Version}}
+Section: vcs
+Priority: optional
+Architecture: ARCH
+Depends: git
+Recommends: openssh-client
+Maintainer: {{.Maintainer}}
+Homepage: {{.Homepage}}
+Description: {{.Description}}
+{{debianDescription .Long}}
+`
+
+var debStageTemplate = `#!/bin/sh
+# Lay out a krakn .deb package tree: ./stage.sh <path-to-krakn> <arch>
+set -e
+
+BIN="$1"
+ARCH="$2"
+ROOT="krakn_{{.Version}}_${ARCH}"
+
+install -Dm755 "$BIN" "$ROOT/usr/bin/krakn"
+
+# Shell completions
+install -d "$ROOT/usr/share/bash-completion/completions" "$ROOT/usr/share/zsh/vendor-completions" "$ROOT/usr/share/fish/vendor_completions.d"
+"$BIN" completion bash > "$ROOT/usr/share/bash-completion/completions/krakn"
+"$BIN" completion zsh > "$ROOT/usr/share/zsh/vendor-completions/_krakn"
+"$BIN" completion fish > "$ROOT/usr/share/fish/vendor_completions.d/krakn.fish"
+
+# Man pages
+"$BIN" --plain docs man "$ROOT/usr/share/man/man1" > /dev/null
+gzip -9n "$ROOT"/usr/share/man/man1/*.1
+{{range .Services}}
+# User service
+install -Dm644 "$(dirname "$0")/{{.Name}}.service" "$ROOT/usr/lib/systemd/user/{{.Name}}.service"
+{{end}}
+install -d "$ROOT/DEBIAN"
+sed "s/^Architecture: ARCH$/Architecture: ${ARCH}/" "$(dirname "$0")/control" > "$ROOT/DEBIAN/control"
+dpkg-deb --build --root-owner-group "$ROOT"
+`
+
+var systemdUnitTemplate = `[Unit]
+Description={{.Description}}
+
+[Service]
+ExecStart=/usr/bin/krakn{{range .Args}} {{.}}{{end}}
+Restart=on-failure
+
+[Install]
+WantedBy=default.target
+`
+
+// scoopManifest is the JSON document Scoop buckets expect
+type scoopManifest struct {
+	Version      string                       `json:"version"`
+	Description  string                       `json:"description"`
+	Homepage     string                       `json:"homepage"`
+	License      string                       `json:"license"`
+	Architecture map[string]scoopArchitecture `json:"architecture"`
+	Bin          string                       `json:"bin"`
+	CheckVer     map[string]string            `json:"checkver"`
+	AutoUpdate   map[string]interface{}       `json:"autoupdate"`
+}
+
+type scoopArchitecture struct {
+	URL  string `json:"url"`
+	Hash string `json:"hash,omitempty"`
+}
+
+// renderScoopManifest builds the Scoop manifest for the Windows archives
+func renderScoopManifest(info *releaseInfo) ([]byte, error) {
+	manifest := scoopManifest{
+		Version:     info.Version,
+		Description: info.Description,
+		Homepage:    info.Homepage,
+		License:     info.License,
+		Architecture: map[string]scoopArchitecture{
+			"64bit": {URL: info.URL("windows", "amd64"), Hash: info.SHA256("windows", "amd64")},
+			"arm64": {URL: info.URL("windows", "arm64"), Hash: info.SHA256("windows", "arm64")},
+		},
+		Bin:      "krakn.exe",
+		CheckVer: map[string]string{"github": info.Homepage},
+		AutoUpdate: map[string]interface{}{
+			"architecture": map[string]scoopArchitecture{
+				"64bit": {URL: strings.ReplaceAll(info.URL("windows", "amd64"), info.Version, "$version")},
+				"arm64": {URL: strings.ReplaceAll(info.URL("windows", "arm64"), info.Version, "$version")},
+			},
+		},
+	}
+	data, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// renderTemplate executes a packaging template with the release metadata
+func renderTemplate(text string, data interface{}) ([]byte, error) {
+	tmpl, err := template.New("release").Funcs(template.FuncMap{"debianDescription": debianDescription}).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return nil, err
+	}
+	return []byte(out.String()), nil
+}
+
+var releaseCmd = &cobra.Command{
+	Use:    "release",
+	Short:  "Release engineering helpers",
+	Hidden: true,
+	Long: `Release engineering helpers for maintainers and packagers.
+
+Examples:
+  krakn release manifest --checksums dist/checksums.txt`,
+}
+
+var releaseManifestCmd = &cobra.Command{
+	Use:   "manifest",
+	Short: "Generate Homebrew, Scoop and Debian packaging files",
+	Long: `Generate packaging files from this binary's metadata: a Homebrew formula, a
+Scoop manifest and a Debian control file with a staging script. Completions and
+man pages are produced by the binary itself at install time, so new commands are
+packaged without touching the files by hand.
+
+Archives are expected to be named krakn_<version>_<os>_<arch>.tar.gz (.zip on
+Windows). Checksums are read from a sha256sum-style file.
+
+Examples:
+  krakn release manifest --checksums dist/checksums.txt
+  krakn release manifest --version 1.4.0 --format brew --output -`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, _ := cmd.Flags().GetString("version")
+		checksumsPath, _ := cmd.Flags().GetString("checksums")
+		baseURL, _ := cmd.Flags().GetString("base-url")
+		maintainer, _ := cmd.Flags().GetString("maintainer")
+		formats, _ := cmd.Flags().GetStringSlice("format")
+		output, _ := cmd.Flags().GetString("output")
+
+		version = strings.TrimPrefix(version, "v")
+		if version == "" || version == "dev" {
+			return fmt.Errorf("❌ This is a development build; pass --version")
+		}
+
+		checksums, err := readChecksums(checksumsPath)
+		if err != nil {
+			return err
+		}
+
+		info := &releaseInfo{
+			Version:     version,
+			Description: RootCmd.Short,
+			Long:        strings.SplitN(RootCmd.Long, "\n\nExamples:", 2)[0],
+			Homepage:    projectHomepage,
+			License:     projectLicense,
+			Maintainer:  maintainer,
+			BaseURL:     strings.ReplaceAll(baseURL, "{version}", version),
+			Checksums:   checksums,
+			Services:    releaseServices,
+		}
+
+		files := map[string][]byte{}
+		for _, format := range formats {
+			switch format {
+			case "brew":
+				data, err := renderTemplate(brewFormulaTemplate, info)
+				if err != nil {
+					return fmt.Errorf("failed to render formula: %w", err)
+				}
+				files["homebrew/krakn.rb"] = data
+			case "scoop":
+				data, err := renderScoopManifest(info)
+				if err != nil {
+					return fmt.Errorf("failed to render scoop manifest: %w", err)
+				}
+				files["scoop/krakn.json"] = data
+			case "deb":
+				control, err := renderTemplate(debControlTemplate, info)
+				if err != nil {
+					return fmt.Errorf("failed to render control file: %w", err)
+				}
+				stage, err := renderTemplate(debStageTemplate, info)
+				if err != nil {
+					return fmt.Errorf("failed to render staging script: %w", err)
+				}
+				files["deb/control"] = control
+				files["deb/stage.sh"] = stage
+				for _, service := range info.Services {
+					unit, err := renderTemplate(systemdUnitTemplate, service)
+					if err != nil {
+						return fmt.Errorf("failed to render %s unit: %w", service.Name, err)
+					}
+					files["deb/"+service.Name+".service"] = unit
+				}
+			default:
+				return fmt.Errorf("❌ Unknown format '%s'. Use brew, scoop or deb", format)
+			}
+		}
+
+		var names []string
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		if output == "-" {
+			for _, name := range names {
+				fmt.Printf("# ---- %s ----\n%s\n", name, files[name])
+			}
+		} else {
+			for _, name := range names {
+				data := files[name]
+				path := filepath.Join(output, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+				}
+				mode := os.FileMode(0644)
+				if strings.HasSuffix(name, ".sh") {
+					mode = 0755
+				}
+				if err := os.WriteFile(path, data, mode); err != nil {
+					return fmt.Errorf("failed to write %s: %w", path, err)
+				}
+				fmt.Printf("📦 %s\n", path)
+			}
+		}
+
+		if len(info.Missing) > 0 {
+			fmt.Fprintf(os.Stderr, "⚠️  No checksum for %d archive(s); '%s' must be replaced before publishing:\n", len(info.Missing), missingChecksum)
+			for _, name := range info.Missing {
+				fmt.Fprintf(os.Stderr, "   %s\n", name)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	releaseManifestCmd.Flags().String("version", Version, "Version to package (defaults to this binary's version)")
+	releaseManifestCmd.Flags().String("checksums", "", "sha256sum-style checksums file for the release archives")
+	releaseManifestCmd.Flags().String("base-url", projectHomepage+"/releases/download/v{version}", "Download URL prefix; {version} is substituted")
+	releaseManifestCmd.Flags().String("maintainer", "krakncat maintainers <krakncat@users.noreply.github.com>", "Debian package maintainer")
+	releaseManifestCmd.Flags().StringSlice("format", []string{"brew", "scoop", "deb"}, "Formats to generate: brew, scoop, deb")
+	releaseManifestCmd.Flags().String("output", filepath.Join("dist", "packaging"), "Output directory, or - for stdout")
+	releaseCmd.AddCommand(releaseManifestCmd)
+	RootCmd.AddCommand(releaseCmd)
+}
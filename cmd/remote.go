@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// findRepoRoot returns the top-level directory of the repository containing path
+func findRepoRoot(path string) (string, error) {
+	output, err := traceExec(exec.Command("git", "-C", path, "rev-parse", "--show-toplevel")).Output()
+	if err != nil {
+		return "", errNotARepository(path)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// getRepoRemoteURL returns the URL of a named remote, or "" when it is not set
+func getRepoRemoteURL(repoPath, remote string) string {
+	return getRepoGitConfig(repoPath, fmt.Sprintf("remote.%s.url", remote))
+}
+
+// sshCommandIdentity extracts the identity file selected by a core.sshCommand value
+func sshCommandIdentity(sshCommand string) string {
+	fields := strings.Fields(sshCommand)
+	for i, field := range fields {
+		switch {
+		case field == "-i" && i+1 < len(fields):
+			return expandSSHPath(strings.Trim(fields[i+1], `"'`), nil)
+		case strings.HasPrefix(field, "-i") && len(field) > 2:
+			return expandSSHPath(strings.Trim(field[2:], `"'`), nil)
+		case strings.HasPrefix(field, "IdentityFile="):
+			return expandSSHPath(strings.Trim(strings.TrimPrefix(field, "IdentityFile="), `"'`), nil)
+		}
+	}
+	return ""
+}
+
+// findAccountByKey returns the account whose SSH key matches the given path
+func (c *Config) findAccountByKey(keyPath string) *Account {
+	if keyPath == "" {
+		return nil
+	}
+	keyPath = filepath.Clean(keyPath)
+	for i := range c.Accounts {
+		if c.Accounts[i].SSHKey != "" && filepath.Clean(c.Accounts[i].SSHKey) == keyPath {
+			return &c.Accounts[i]
+		}
+	}
+	return nil
+}
+
+// findAccountByHost returns the account whose SSH host alias matches the host
+func (c *Config) findAccountByHost(host string) *Account {
+	for i := range c.Accounts {
+		if c.Accounts[i].GetSSHHost() == host {
+			return &c.Accounts[i]
+		}
+	}
+	return nil
+}
+
+// findAccountForPath returns the account mapped to the directory containing path
+func (c *Config) findAccountForPath(path string) *Account {
+	// Worktree mappings match the git directory of a linked worktree by name;
+	// like git, the mapping added last wins
+	if gitDir, commonDir, err := findGitDir(path); err == nil && gitDir != commonDir {
+		for i := len(c.Directories) - 1; i >= 0; i-- {
+			mapping := &c.Directories[i]
+			if mapping.Worktrees == "" || filepath.Clean(mapping.Path) != commonDir {
+				continue
+			}
+			if filepath.Base(filepath.Dir(gitDir)) == "worktrees" && matchGlobPath(mapping.Worktrees, filepath.Base(gitDir)) {
+				tracef(traceMatch, "Worktree %s matches '%s' of %s → account '%s'", filepath.Base(gitDir), mapping.Worktrees, contractHomePath(mapping.Path), mapping.Account)
+				return c.getAccount(mapping.Account)
+			}
+		}
+	}
+
+	var best *DirectoryMapping
+	for i := range c.Directories {
+		mapping := &c.Directories[i]
+		if mapping.Worktrees != "" {
+			continue
+		}
+		rel, err := filepath.Rel(mapping.Path, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if best == nil || len(mapping.Path) > len(best.Path) {
+			best = mapping
+		}
+	}
+	if best == nil {
+		tracef(traceMatch, "No directory mapping covers %s", contractHomePath(path))
+		return nil
+	}
+	tracef(traceMatch, "%s is below mapped directory %s → account '%s'", contractHomePath(path), contractHomePath(best.Path), best.Account)
+	return c.getAccount(best.Account)
+}
+
+// repoIdentity describes which account mechanisms a repository uses
+type repoIdentity struct {
+	Root          string
+	RemoteURL     string
+	Remote        *remoteURL
+	HostAccount   *Account // Account whose host alias the remote uses
+	SSHCommand    string
+	SSHCommandKey string
+	KeyAccount    *Account // Account whose key core.sshCommand selects
+	Email         string
+	EmailAccount  *Account // Account whose email matches user.email
+	MappedAccount *Account // Account mapped to the directory via 'krakn config', or the repository override
+	Override      *RepoOverride
+}
+
+// inspectRepoIdentity gathers identity information for a repository
+func inspectRepoIdentity(config *Config, repoRoot, remote string) *repoIdentity {
+	identity := &repoIdentity{Root: repoRoot}
+
+	identity.RemoteURL = getRepoRemoteURL(repoRoot, remote)
+	if parsed, err := parseRemoteURL(identity.RemoteURL); err == nil {
+		identity.Remote = parsed
+		identity.HostAccount = config.findAccountByHost(parsed.Host)
+	}
+
+	identity.SSHCommand = getRepoGitConfig(repoRoot, "core.sshCommand")
+	if identity.SSHCommand != "" {
+		identity.SSHCommandKey = sshCommandIdentity(identity.SSHCommand)
+		identity.KeyAccount = config.findAccountByKey(identity.SSHCommandKey)
+	}
+
+	identity.Email = getRepoGitConfig(repoRoot, "user.email")
+	for i := range config.Accounts {
+		if identity.Email != "" && config.Accounts[i].Email == identity.Email {
+			identity.EmailAccount = &config.Accounts[i]
+			break
+		}
+	}
+
+	identity.MappedAccount = config.findAccountForPath(repoRoot)
+	if identity.Override = config.findOverride(repoRoot); identity.Override != nil {
+		if account := config.getAccount(identity.Override.Account); account != nil {
+			identity.MappedAccount = account
+		}
+	}
+	if identity.HostAccount != nil {
+		tracef(traceMatch, "Remote host %s is the alias of account '%s'", identity.Remote.Host, identity.HostAccount.Name)
+	}
+	if identity.KeyAccount != nil {
+		tracef(traceMatch, "core.sshCommand selects the key of account '%s'", identity.KeyAccount.Name)
+	}
+	if identity.EmailAccount != nil {
+		tracef(traceMatch, "user.email %s belongs to account '%s'", identity.Email, identity.EmailAccount.Name)
+	}
+	return identity
+}
+
+// aliasRemoteURL rewrites a remote to go through the account's SSH host
+// alias. ssh:// remotes stay ssh:// URLs; the alias supplies the port.
+func aliasRemoteURL(remote *remoteURL, account *Account) string {
+	aliased := &remoteURL{Scheme: "scp", User: account.GetProvider().SSHUser, Host: account.GetSSHHost(), Path: remote.Path}
+	if aliased.User == "" {
+		aliased.User = "git"
+	}
+	if remote.Scheme == "ssh" {
+		aliased.Scheme = "ssh"
+	}
+	return aliased.String()
+}
+
+var fixRemoteCmd = &cobra.Command{
+	Use:   "fix-remote [path]",
+	Short: "Rewrite a repository remote to use an account's SSH host alias",
+	Long: `Rewrite a repository remote so it goes through the SSH host alias of an account
+(e.g. git@github.com:org/repo.git → git@github.com-work:org/repo.git).
+
+The account is taken from --account, the directory mapping, or the repository's
+user.email. Repositories that already select a key with core.sshCommand are left
+alone, because stacking a host alias on top would offer two different keys.
+HTTPS-only accounts (see 'krakn https-only') get an https:// URL instead.
+
+Examples:
+  krakn fix-remote                    # Fix origin of the current repository
+  krakn fix-remote ~/work/api --account work
+  krakn fix-remote --remote upstream
+  krakn fix-remote -r ~/work --account work  # Every repository below ~/work`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		accountName, _ := cmd.Flags().GetString("account")
+		remoteName, _ := cmd.Flags().GetString("remote")
+		force, _ := cmd.Flags().GetBool("force")
+		recursive, _ := cmd.Flags().GetBool("recursive")
+		trust, _ := cmd.Flags().GetBool("safe-directory")
+
+		var repoRoot string
+		var err error
+		if recursive {
+			repoRoot, err = filepath.Abs(expandUserPath(path))
+		} else {
+			repoRoot, err = findRepoRoot(path)
+		}
+		if err != nil {
+			return fmt.Errorf("❌ %w", err)
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		if !recursive {
+			return fixRepoRemote(config, repoRoot, accountName, remoteName, force)
+		}
+
+		spin := startSpinner("Scanning " + repoRoot + " for repositories")
+		repos := findGitRepos(repoRoot)
+		spin.Stop()
+		repos = filterForeignRepos(repos, trust)
+
+		failures := 0
+		for _, repo := range repos {
+			fmt.Printf("📁 %s\n", contractHomePath(repo))
+			if err := fixRepoRemote(config, repo, accountName, remoteName, force); err != nil {
+				fmt.Printf("   %v\n", err)
+				failures++
+			}
+		}
+		if failures > 0 {
+			return fmt.Errorf("❌ %d of %d repositories could not be fixed", failures, len(repos))
+		}
+		return nil
+	},
+}
+
+// fixRepoRemote points one repository's remote at the account's host alias
+func fixRepoRemote(config *Config, repoRoot, accountName, remoteName string, force bool) error {
+	identity := inspectRepoIdentity(config, repoRoot, remoteName)
+	if identity.RemoteURL == "" {
+		return fmt.Errorf("❌ Remote '%s' is not configured in %s", remoteName, repoRoot)
+	}
+	if identity.Remote == nil {
+		return fmt.Errorf("❌ Cannot parse remote URL: %s", identity.RemoteURL)
+	}
+
+	// Pick the target account
+	var account *Account
+	switch {
+	case accountName != "":
+		account = config.getAccount(accountName)
+		if account == nil {
+			return config.accountNotFound(accountName)
+		}
+	case identity.MappedAccount != nil:
+		account = identity.MappedAccount
+	case identity.EmailAccount != nil:
+		account = identity.EmailAccount
+	case identity.KeyAccount != nil:
+		account = identity.KeyAccount
+	default:
+		return fmt.Errorf("❌ Could not determine the account for %s. Use --account", repoRoot)
+	}
+
+	// Local-only mode writes no Host aliases, so the key is selected with
+	// core.sshCommand and the remote keeps the plain hostname
+	if config.LocalOnly && !account.usesHTTPS() {
+		return fixRepoRemoteLocalOnly(repoRoot, remoteName, identity, account)
+	}
+
+	// core.sshCommand is an intentional key selection mechanism
+	if identity.SSHCommand != "" && !account.usesHTTPS() {
+		fmt.Printf("🔧 core.sshCommand: %s\n", identity.SSHCommand)
+		if identity.KeyAccount != nil {
+			fmt.Printf("   🔑 Selects key of account '%s'\n", identity.KeyAccount.Name)
+		} else if identity.SSHCommandKey != "" {
+			fmt.Printf("   🔑 Selects key %s (not managed by krakncat)\n", identity.SSHCommandKey)
+		}
+
+		if !force {
+			if identity.KeyAccount != nil && identity.KeyAccount.Name == account.Name {
+				fmt.Printf("✅ Repository already authenticates as '%s' via core.sshCommand; leaving remote unchanged\n", account.Name)
+				return nil
+			}
+			return fmt.Errorf("❌ core.sshCommand already selects a key for this repository; rewriting the remote would stack a conflicting host alias.\n   Remove it with 'git -C %s config --unset core.sshCommand' or rerun with --force", repoRoot)
+		}
+		fmt.Println("⚠️  --force given: the host alias will be added on top of core.sshCommand")
+	}
+
+	newURL := accountRemoteURL(identity.Remote, account)
+	if account.usesHTTPS() && identity.RemoteURL == newURL {
+		fmt.Printf("✅ Remote '%s' already uses HTTPS\n", remoteName)
+		return nil
+	}
+	if !account.usesHTTPS() && identity.Remote.Host == account.GetSSHHost() {
+		fmt.Printf("✅ Remote '%s' already uses %s\n", remoteName, account.GetSSHHost())
+		return nil
+	}
+
+	trackFile(filepath.Join(repoRoot, ".git", "config"))
+	if err := traceExec(exec.Command("git", "-C", repoRoot, "remote", "set-url", remoteName, newURL)).Run(); err != nil {
+		return fmt.Errorf("failed to update remote: %w", err)
+	}
+
+	fmt.Printf("✅ Remote '%s' now uses account '%s'\n", remoteName, account.Name)
+	fmt.Printf("   ❌ Old: %s\n", identity.RemoteURL)
+	fmt.Printf("   ✅ New: %s\n", newURL)
+	return nil
+}
+
+// fixRepoRemoteLocalOnly makes a repository authenticate as the account
+// without a Host alias: core.sshCommand selects the key, and a remote going
+// through an alias is pointed back at the provider's hostname
+func fixRepoRemoteLocalOnly(repoRoot, remoteName string, identity *repoIdentity, account *Account) error {
+	configPath, err := gitConfigTarget(repoRoot, false)
+	if err != nil {
+		return err
+	}
+	changes, err := applyGitConfigValues(configPath, []gitConfigValue{{Key: "core.sshCommand", Value: localSSHCommand(account)}})
+	if err != nil {
+		return fmt.Errorf("failed to set core.sshCommand: %w", err)
+	}
+	printGitConfigChanges(configPath, changes)
+
+	hostname := account.GetProvider().Hostname
+	if strings.HasPrefix(identity.Remote.Scheme, "http") {
+		fmt.Printf("ℹ️  Remote '%s' uses HTTPS; core.sshCommand applies once it is switched to SSH\n", remoteName)
+		return nil
+	}
+	if identity.Remote.Host == hostname {
+		fmt.Printf("✅ Remote '%s' authenticates as '%s' via core.sshCommand\n", remoteName, account.Name)
+		return nil
+	}
+	newURL := fmt.Sprintf("git@%s:%s", hostname, identity.Remote.Path)
+	trackFile(configPath)
+	if err := traceExec(exec.Command("git", "-C", repoRoot, "remote", "set-url", remoteName, newURL)).Run(); err != nil {
+		return fmt.Errorf("failed to update remote: %w", err)
+	}
+	fmt.Printf("✅ Remote '%s' now uses account '%s' via core.sshCommand (local-only mode)\n", remoteName, account.Name)
+	fmt.Printf("   ❌ Old: %s\n", identity.RemoteURL)
+	fmt.Printf("   ✅ New: %s\n", newURL)
+	return nil
+}
+
+func init() {
+	fixRemoteCmd.Flags().String("account", "", "Account to use (defaults to the directory mapping or user.email)")
+	fixRemoteCmd.Flags().String("remote", "origin", "Remote to rewrite")
+	fixRemoteCmd.Flags().Bool("force", false, "Rewrite even if core.sshCommand already selects a key")
+	fixRemoteCmd.Flags().BoolP("recursive", "r", false, "Fix every repository below the given directory")
+	fixRemoteCmd.Flags().Bool("safe-directory", false, "With --recursive, trust and include repositories owned by other users")
+	RootCmd.AddCommand(fixRemoteCmd)
+}
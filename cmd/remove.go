@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -11,26 +12,44 @@ import (
 
 var removeCmd = &cobra.Command{
 	Use:   "remove [account-name]",
-	Short: "Remove a GitHub account configuration",
-	Args:  cobra.ExactArgs(1),
+	Short: "Remove an account configuration",
+	Long: `Remove an account from krakncat, optionally deleting its SSH key files and
+the public key 'krakn key upload' (or 'add'/'generate-key --upload') put on the provider.
+
+krakn also offers to remove what it configured for the account: its Host block
+in ~/.ssh/config, the includeIf sections of directories mapped to it in
+~/.gitconfig with their include files, and its repository overrides. Host
+blocks linked from an existing ~/.ssh/config entry are yours and stay. Use
+--purge to remove all of it without asking.
+
+Examples:
+  krakn remove work
+  krakn remove work --purge
+  krakn account remove work`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		accountName := args[0]
+		purge, _ := cmd.Flags().GetBool("purge")
 
 		// Load config
 		config, err := loadConfig()
 		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+			return errLoadConfig(err)
 		}
 
 		// Check if account exists
 		account := config.getAccount(accountName)
 		if account == nil {
-			availableAccounts := make([]string, len(config.Accounts))
-			for i, acc := range config.Accounts {
-				availableAccounts[i] = acc.Name
+			return config.accountNotFound(accountName)
+		}
+
+		// Show the key so it can be matched with the provider's key settings
+		fingerprint := ""
+		if account.SSHKey != "" && !account.usesHTTPS() {
+			fingerprint = keyFingerprint(account.SSHKey)
+			if fingerprint != "" {
+				fmt.Printf("🔏 Key of '%s': %s\n", accountName, fingerprint)
 			}
-			return fmt.Errorf("❌ Account '%s' not found. Available accounts: %s", 
-				accountName, strings.Join(availableAccounts, ", "))
 		}
 
 		// Confirm removal
@@ -44,41 +63,85 @@ var removeCmd = &cobra.Command{
 			return nil
 		}
 
-		// Remove from accounts list
-		var newAccounts []Account
-		for _, acc := range config.Accounts {
-			if acc.Name != accountName {
-				newAccounts = append(newAccounts, acc)
+		// Delete the key krakn uploaded while the account's token is still known
+		keyDeleted := false
+		if account.KeyID != 0 {
+			fmt.Printf("☁️  Delete the public key krakn uploaded to %s (id %d)? [Y/n]: ", account.GetProvider().DisplayName, account.KeyID)
+			resp, _ := reader.ReadString('\n')
+			resp = strings.ToLower(strings.TrimSpace(resp))
+			if resp == "y" || resp == "yes" || resp == "" {
+				if err := deleteUploadedKey(config, account); err != nil {
+					fmt.Printf("⚠️  Could not delete the key from %s: %s\n", account.GetProvider().DisplayName, strings.TrimPrefix(err.Error(), "❌ "))
+				} else {
+					keyDeleted = true
+					fmt.Printf("🗑️  Deleted the public key from %s\n", account.GetProvider().DisplayName)
+				}
 			}
 		}
-		config.Accounts = newAccounts
 
-		// Update current account if needed
-		if config.CurrentAccount == accountName {
-			if len(config.Accounts) > 0 {
-				config.CurrentAccount = config.Accounts[0].Name
-				fmt.Printf("🔄 Current account switched to '%s'\n", config.CurrentAccount)
-			} else {
-				config.CurrentAccount = ""
-				fmt.Println("📝 No accounts remaining")
+		// git must not keep asking krakn for the token of a removed account
+		if account.usesHTTPS() {
+			hostToo := !config.hostUsesHTTPS(account.GetProvider().Hostname, account.Name)
+			if err := removeCredentialHelper(account, hostToo); err != nil {
+				fmt.Printf("⚠️  Could not remove the credential helper: %v\n", err)
+			}
+		}
+		if account.TokenStore == tokenStoreKeychain {
+			if store := systemSecretStore(); store != nil {
+				if err := store.Delete(tokenKeychainEntry(account.Name)); err != nil && !errors.Is(err, errSecretNotFound) {
+					fmt.Printf("⚠️  Could not remove the token from %s: %v\n", store.Name(), err)
+				}
 			}
 		}
 
-		// Save config
-		if err := config.saveConfig(); err != nil {
-			return fmt.Errorf("failed to save config: %w", err)
+		// Collect what krakn configured for the account while it is still in the config
+		artifacts := findAccountArtifacts(config, account)
+
+		wasCurrent := config.CurrentAccount == accountName
+		if err := config.update(func(config *Config) error {
+			config.dropAccount(accountName)
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		if wasCurrent && config.CurrentAccount != "" {
+			fmt.Printf("🔄 Current account switched to '%s'\n", config.CurrentAccount)
+		} else if wasCurrent {
+			fmt.Println("📝 No accounts remaining")
 		}
 
 		fmt.Printf("✅ Account '%s' removed successfully\n", accountName)
-		
+
+		// Offer to remove the SSH Host block, directory mappings and overrides
+		cleaned := artifacts.empty()
+		if !cleaned {
+			if !purge {
+				fmt.Println("\n🧹 krakn configured for this account:")
+				artifacts.describe()
+				fmt.Print("🗑️  Remove these as well? [y/N]: ")
+				resp, _ := reader.ReadString('\n')
+				resp = strings.ToLower(strings.TrimSpace(resp))
+				purge = resp == "y" || resp == "yes"
+			}
+			if purge {
+				cleaned = artifacts.remove(config)
+			}
+		}
+
 		// Optionally remove SSH key
 		if account.SSHKey != "" {
 			fmt.Printf("\n💡 SSH key still exists at: %s\n", account.SSHKey)
+			if fingerprint != "" {
+				fmt.Printf("   🔏 %s\n", fingerprint)
+			}
 			fmt.Print("🗑️  Do you want to remove the SSH key files? [y/N]: ")
 			resp, _ := reader.ReadString('\n')
 			resp = strings.ToLower(strings.TrimSpace(resp))
 
 			if resp == "y" || resp == "yes" {
+				trackFile(account.SSHKey)
+				trackFile(account.SSHKey + ".pub")
+
 				// Remove private key
 				if err := os.Remove(account.SSHKey); err != nil {
 					fmt.Printf("⚠️  Could not remove private key: %v\n", err)
@@ -96,14 +159,49 @@ var removeCmd = &cobra.Command{
 			}
 		}
 
+		if keyDeleted && cleaned {
+			return nil
+		}
 		fmt.Println("\n💡 Note: You may want to:")
-		fmt.Printf("   - Remove the SSH key from GitHub: https://github.com/settings/ssh\n")
-		fmt.Printf("   - Clean up any conditional includes in ~/.gitconfig manually\n")
+		if !keyDeleted {
+			provider := account.GetProvider()
+			if settings := strings.TrimSuffix(provider.WebURL, "/new"); settings != "" {
+				fmt.Printf("   - Remove the SSH key from %s: %s\n", provider.DisplayName, settings)
+			} else {
+				fmt.Printf("   - Remove the SSH key from %s\n", provider.DisplayName)
+			}
+			if fingerprint != "" {
+				fmt.Printf("     (the one with fingerprint %s)\n", strings.Fields(fingerprint)[1])
+			}
+		}
+		if !cleaned {
+			fmt.Printf("   - Remove the Host block from ~/.ssh/config and unmap directories with 'krakn dir unmap'\n")
+		}
 
 		return nil
 	},
 }
 
 func init() {
+	removeCmd.Flags().Bool("purge", false, "Also remove the account's SSH Host block, directory mappings and overrides without asking")
 	RootCmd.AddCommand(removeCmd)
 }
+
+// dropAccount removes an account; when it was the current account, the first
+// remaining one becomes current
+func (c *Config) dropAccount(name string) {
+	var accounts []Account
+	for _, account := range c.Accounts {
+		if account.Name != name {
+			accounts = append(accounts, account)
+		}
+	}
+	c.Accounts = accounts
+
+	if c.CurrentAccount == name {
+		c.CurrentAccount = ""
+		if len(c.Accounts) > 0 {
+			c.CurrentAccount = c.Accounts[0].Name
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// accountArtifacts is what krakn configured outside its own config for an
+// account, which 'krakn remove' can take away with it
+type accountArtifacts struct {
+	SSHAlias    string         // Host alias krakn generated in ~/.ssh/config
+	Directories []string       // Directories (or worktree pattern directories) with an includeIf for the account
+	Overrides   []RepoOverride // Repository overrides using the account
+}
+
+// findAccountArtifacts collects the artifacts of an account still in config.
+// Host blocks linked with an existing alias belong to the user, and an alias
+// shared with another account stays. Directories mapped before mappings were
+// recorded count when their include file has the account's email and no other
+// account uses it.
+func findAccountArtifacts(config *Config, account *Account) accountArtifacts {
+	var artifacts accountArtifacts
+
+	alias := account.GetSSHHost()
+	shared := false
+	emailShared := false
+	for _, other := range config.Accounts {
+		if other.Name == account.Name {
+			continue
+		}
+		shared = shared || other.GetSSHHost() == alias
+		emailShared = emailShared || other.Email == account.Email
+	}
+	if !account.usesHTTPS() && !shared && containsString(managedSSHAliases(config), alias) {
+		artifacts.SSHAlias = alias
+	}
+
+	for _, dir := range managedDirectories(config) {
+		if mapping := config.getDirectoryMapping(dir); mapping != nil {
+			if mapping.Account == account.Name {
+				artifacts.Directories = append(artifacts.Directories, dir)
+			}
+			continue
+		}
+		email := readGitConfigValue(filepath.Join(dir, ".gitconfig"), "user", "email")
+		if !emailShared && email != "" && email == account.Email {
+			artifacts.Directories = append(artifacts.Directories, dir)
+		}
+	}
+
+	for _, override := range config.Overrides {
+		if override.Account == account.Name {
+			artifacts.Overrides = append(artifacts.Overrides, override)
+		}
+	}
+	return artifacts
+}
+
+func (a accountArtifacts) empty() bool {
+	return a.SSHAlias == "" && len(a.Directories) == 0 && len(a.Overrides) == 0
+}
+
+// describe lists the artifacts, one per line
+func (a accountArtifacts) describe() {
+	if a.SSHAlias != "" {
+		fmt.Printf("   🔗 Host %s in %s\n", a.SSHAlias, contractHomePath(getSSHConfigPath()))
+	}
+	for _, dir := range a.Directories {
+		fmt.Printf("   📁 Conditional include for %s in ~/.gitconfig\n", contractHomePath(dir))
+	}
+	for _, override := range a.Overrides {
+		fmt.Printf("   🎯 Override for %s\n", override.Repo)
+	}
+}
+
+// remove deletes the artifacts, reporting each failure and carrying on. It
+// returns whether everything was removed. config no longer holds the account.
+func (a accountArtifacts) remove(config *Config) bool {
+	ok := true
+	if a.SSHAlias != "" {
+		if removed, err := removeSSHHostBlock(a.SSHAlias); err != nil {
+			fmt.Printf("⚠️  Could not remove Host %s: %v\n", a.SSHAlias, err)
+			ok = false
+		} else if removed {
+			fmt.Printf("🗑️  Removed Host %s from %s\n", a.SSHAlias, contractHomePath(getSSHConfigPath()))
+		}
+	}
+
+	for _, dir := range a.Directories {
+		if err := unmapDirectoryConfig(config, dir); err != nil {
+			fmt.Printf("⚠️  Could not unmap %s: %v\n", contractHomePath(dir), err)
+			ok = false
+		}
+	}
+
+	if len(a.Overrides) > 0 {
+		account := a.Overrides[0].Account
+		dropOverrides := func(config *Config) {
+			var kept []RepoOverride
+			for _, override := range config.Overrides {
+				if override.Account != account {
+					kept = append(kept, override)
+				}
+			}
+			config.Overrides = kept
+		}
+		if err := updateConfig(func(config *Config) error {
+			dropOverrides(config)
+			if err := config.syncOverrides(); err != nil {
+				return fmt.Errorf("could not update ~/.gitconfig: %w", err)
+			}
+			return nil
+		}); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+			return false
+		}
+		dropOverrides(config)
+		for _, override := range a.Overrides {
+			trackFile(override.ConfigFile)
+			os.Remove(override.ConfigFile)
+			fmt.Printf("🗑️  Removed the override for %s\n", override.Repo)
+		}
+	}
+	return ok
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// findGitRepos walks root and returns every directory that contains a .git
+// entry. Repositories are not descended into, and directories matched by the
+// ignore patterns (see ignore.go) are skipped.
+func findGitRepos(root string) []string {
+	var repos []string
+
+	config, _ := loadConfig()
+	ignore := newIgnoreMatcher(config)
+
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped rather than aborting the walk
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && ignore.ignored(path) {
+			return filepath.SkipDir
+		}
+		ignore.loadDir(path)
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+			repos = append(repos, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+
+	return repos
+}
+
+// getRepoGitConfig returns the effective value of a git config key inside a
+// repository. The config files are read directly, which matters when scanning
+// many repositories; git is only run for configurations the native reader
+// does not support.
+func getRepoGitConfig(repoPath, key string) string {
+	if config, err := resolveRepoGitConfig(repoPath); err == nil {
+		value, _ := config.get(key)
+		return value
+	}
+
+	output, err := traceExec(exec.Command("git", "-C", repoPath, "config", "--get", key)).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// foreignRepo is a repository owned by another user. Git refuses to work in
+// such repositories ("dubious ownership") unless they are listed in safe.directory.
+type foreignRepo struct {
+	Path  string
+	Owner string
+}
+
+// safeDirectories returns the global safe.directory entries
+func safeDirectories() []string {
+	output, err := traceExec(exec.Command("git", "config", "--global", "--get-all", "safe.directory")).Output()
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			dirs = append(dirs, line)
+		}
+	}
+	return dirs
+}
+
+// isSafeDirectory reports whether git already trusts the repository
+func isSafeDirectory(repoPath string, safeDirs []string) bool {
+	for _, dir := range safeDirs {
+		if dir == "*" || filepath.Clean(expandUserPath(dir)) == filepath.Clean(repoPath) {
+			return true
+		}
+		if strings.HasSuffix(dir, "/*") && strings.HasPrefix(repoPath, filepath.Clean(expandUserPath(strings.TrimSuffix(dir, "*")))+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// addSafeDirectory adds a repository to the global safe.directory list
+func addSafeDirectory(repoPath string) error {
+	if skipDotfileEdit(globalGitConfigPath(), "adding safe.directory "+repoPath) {
+		return nil
+	}
+	trackFile(globalGitConfigPath())
+	if err := traceExec(exec.Command("git", "config", "--global", "--add", "safe.directory", repoPath)).Run(); err != nil {
+		return fmt.Errorf("failed to add %s to safe.directory: %w", repoPath, err)
+	}
+	return nil
+}
+
+// partitionForeignRepos splits repositories into ones git will work in and
+// ones owned by another user that are not yet trusted via safe.directory
+func partitionForeignRepos(repos []string) (usable []string, foreign []foreignRepo) {
+	safeDirs := safeDirectories()
+	for _, repo := range repos {
+		owner, isForeign := pathOwner(repo)
+		if !isForeign {
+			owner, isForeign = pathOwner(filepath.Join(repo, ".git"))
+		}
+		if isForeign && !isSafeDirectory(repo, safeDirs) {
+			foreign = append(foreign, foreignRepo{Path: repo, Owner: owner})
+			continue
+		}
+		usable = append(usable, repo)
+	}
+	return usable, foreign
+}
+
+// filterForeignRepos drops repositories owned by other users from a scan.
+// With trust set (or after confirmation on a terminal) they are added to
+// safe.directory and kept instead.
+func filterForeignRepos(repos []string, trust bool) []string {
+	usable, foreign := partitionForeignRepos(repos)
+	if len(foreign) == 0 {
+		return usable
+	}
+
+	fmt.Printf("👥 %d repositories are owned by other users:\n", len(foreign))
+	for _, repo := range foreign {
+		fmt.Printf("   📁 %s (owner: %s)\n", repo.Path, repo.Owner)
+	}
+
+	if !trust && isInteractiveOutput() {
+		fmt.Print("💬 Add them to git's safe.directory list and include them? [y/N]: ")
+		resp, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		resp = strings.ToLower(strings.TrimSpace(resp))
+		trust = resp == "y" || resp == "yes"
+	}
+	if !trust {
+		fmt.Println("⏭️  Skipping them. Use --safe-directory to trust and include them.")
+		return usable
+	}
+
+	for _, repo := range foreign {
+		if err := addSafeDirectory(repo.Path); err != nil {
+			fmt.Printf("   ⚠️  %v\n", err)
+			continue
+		}
+		fmt.Printf("   ✅ Added %s to safe.directory\n", repo.Path)
+		usable = append(usable, repo.Path)
+	}
+	sort.Strings(usable)
+	return usable
+}
@@ -1,26 +1,79 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var RootCmd = &cobra.Command{
 	Use:   "krakn",
 	Short: "krakncat CLI tool for managing GitHub accounts",
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		// Skip migration check for help commands and migrate command itself
-		if cmd.Name() == "help" || cmd.Name() == "migrate" || cmd.Parent() != nil && cmd.Parent().Name() == "help" {
-			return
+	Long: `krakncat manages multiple git identities (GitHub, GitLab, Gitea and
+self-hosted servers) with separate SSH keys, host aliases and per-directory
+git configuration.
+
+Examples:
+  krakn account add            # Add an account and its SSH key
+  krakn use work               # Use 'work' for all repositories
+  krakn dir map ~/work work    # Use 'work' for every repository under ~/work
+  krakn doctor                 # Check the current repository's identity`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Never write into root's home by accident
+		if err := checkRootUser(cmd); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+		if err := checkOutputFormat(cmd); err != nil {
+			return err
+		}
+
+		// Read-only commands (help, version, completion) do no startup work at
+		// all; commands load the config themselves when they need it
+		if isReadOnlyCommand(cmd) {
+			return nil
+		}
+
+		// A damaged config is repaired before anything reads it; the welcome
+		// flow below never runs on it, so it cannot overwrite what is recoverable
+		if cmd.Name() != "repair-config" && cmd.Name() != "revert" {
+			offerConfigRepair()
 		}
-		
+		if cmd.Name() == "migrate" || cmd.Name() == "uninstall" {
+			return nil
+		}
+
+		// The welcome prompt needs someone to answer it; scripts and editor
+		// integrations get it on the next interactive run instead
+		if !migrationPending() || !term.IsTerminal(int(os.Stdin.Fd())) {
+			return nil
+		}
+
 		// Run migration check
 		if err := checkAndOfferMigration(); err != nil {
 			// Don't fail the command if migration fails, just warn
 			// This ensures the tool still works even if migration has issues
 		}
+		return nil
 	},
 }
 
+// Execute runs the command line and renders any error on stderr; the caller
+// only decides the exit status
 func Execute() error {
-	return RootCmd.Execute()
+	setupCommandGroups()
+	setupErrorPresenter(RootCmd)
+	err := RootCmd.Execute()
+	if err != nil {
+		presentError(os.Stderr, err)
+	}
+
+	// Record what the command changed so it can be reverted later
+	if logErr := finishOperation(); logErr != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not record operation history: %v\n", logErr)
+	}
+
+	return err
 }
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// allowRoot lets krakncat run as root, e.g. inside a container
+var allowRoot bool
+
+// readOnlyCommands never write to the home directory, so they are safe as root.
+// They also skip all startup work: prompts and shell completion run them on
+// every keystroke.
+var readOnlyCommands = map[string]bool{
+	"help":                          true,
+	"version":                       true,
+	"docs":                          true,
+	"completion":                    true,
+	"env":                           true,
+	"credential":                    true,
+	"tutorial":                      true,
+	"schema":                        true,
+	"state":                         true,
+	cobra.ShellCompRequestCmd:       true,
+	cobra.ShellCompNoDescRequestCmd: true,
+}
+
+// isReadOnlyCommand reports whether cmd or one of its parents is read-only
+func isReadOnlyCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if readOnlyCommands[c.Name()] {
+			return true
+		}
+	}
+	return false
+}
+
+// sudoTargetHome returns the home directory of the user who invoked sudo, if any
+func sudoTargetHome() (string, string) {
+	name := os.Getenv("SUDO_USER")
+	if name == "" || name == "root" {
+		return "", ""
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return name, ""
+	}
+	return name, u.HomeDir
+}
+
+// checkRootUser refuses to run as root unless --allow-root or
+// KRAKN_ALLOW_ROOT=1 is given. Running through sudo would otherwise create
+// /root/.krakncat (or root-owned files in the user's home) and the user's
+// own configuration would appear to be lost.
+func checkRootUser(cmd *cobra.Command) error {
+	if os.Geteuid() != 0 || allowRoot || os.Getenv("KRAKN_ALLOW_ROOT") == "1" || isReadOnlyCommand(cmd) {
+		return nil
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	fmt.Fprintln(os.Stderr, "⚠️  krakn is running as root.")
+	fmt.Fprintf(os.Stderr, "   Configuration would be read from and written to %s\n", filepath.Join(homeDir, ".krakncat"))
+
+	if name, userHome := sudoTargetHome(); name != "" {
+		if userHome != "" && userHome == homeDir {
+			fmt.Fprintf(os.Stderr, "   That is %s's home, so files there would become owned by root.\n", name)
+		} else if userHome != "" {
+			fmt.Fprintf(os.Stderr, "   You probably meant %s's configuration in %s\n", name, filepath.Join(userHome, ".krakncat"))
+		}
+		fmt.Fprintf(os.Stderr, "💡 Run krakn without sudo as %s.\n", name)
+	} else {
+		fmt.Fprintln(os.Stderr, "💡 Run krakn as the user whose git identities you want to manage.")
+	}
+	return newError(codeRunningAsRoot, "if root really is the intended user (e.g. in a container), pass --allow-root or set KRAKN_ALLOW_ROOT=1", "Refusing to run as root")
+}
+
+func init() {
+	RootCmd.PersistentFlags().BoolVar(&allowRoot, "allow-root", false, "Allow running as root (configuration goes to root's home)")
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan [dir]",
+	Short: "Check the identity of every repository below a directory",
+	Long: `Find every git repository below a directory and run the same identity checks
+as 'krakn doctor' on each one, reporting only repositories with problems.
+
+Repositories owned by other users are skipped, because git refuses to work in
+them ("dubious ownership"). With --safe-directory (or after confirming on a
+terminal) they are added to git's safe.directory list and checked as well.
+
+Examples:
+  krakn scan                  # Scan the current directory
+  krakn scan ~/work --verbose # Also list repositories without problems
+  krakn scan / --safe-directory`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root := "."
+		if len(args) == 1 {
+			root = args[0]
+		}
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		trust, _ := cmd.Flags().GetBool("safe-directory")
+
+		root, err := filepath.Abs(expandUserPath(root))
+		if err != nil {
+			return fmt.Errorf("failed to resolve path: %w", err)
+		}
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			return fmt.Errorf("❌ Directory does not exist: %s", root)
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		spin := startSpinner("Scanning " + root + " for repositories")
+		repos := findGitRepos(root)
+		spin.Stop()
+		repos = filterForeignRepos(repos, trust)
+		if len(repos) == 0 {
+			fmt.Println("ℹ️  No git repositories found")
+			return nil
+		}
+
+		problems := 0
+		for _, repo := range repos {
+			findings := checkRepoIdentity(&doctorContext{Config: config, RepoRoot: repo})
+
+			var issues []doctorFinding
+			for _, finding := range findings {
+				if finding.Level == doctorWarn || finding.Level == doctorError {
+					issues = append(issues, finding)
+				}
+			}
+			if len(issues) == 0 {
+				if verbose {
+					fmt.Printf("✅ %s\n", contractHomePath(repo))
+				}
+				continue
+			}
+
+			problems++
+			fmt.Printf("⚠️  %s\n", contractHomePath(repo))
+			for _, finding := range issues {
+				fmt.Printf("   %s %s\n", finding.icon(), finding.Message)
+				if finding.Hint != "" {
+					fmt.Printf("      💡 %s\n", finding.Hint)
+				}
+			}
+		}
+
+		if problems == 0 {
+			fmt.Printf("🎉 %d repositories checked, no problems found\n", len(repos))
+		} else {
+			fmt.Printf("🔎 %d of %d repositories have problems\n", problems, len(repos))
+		}
+		return nil
+	},
+}
+
+func init() {
+	scanCmd.Flags().BoolP("verbose", "v", false, "Also list repositories without problems")
+	scanCmd.Flags().Bool("safe-directory", false, "Add repositories owned by other users to git's safe.directory and include them")
+	RootCmd.AddCommand(scanCmd)
+}
@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ScheduleRule selects an account on some days between two times of day.
+// Rules are evaluated in order and the first match wins, so a rule without
+// days or times at the end acts as the fallback.
+type ScheduleRule struct {
+	Account string   `json:"account"`
+	Days    []string `json:"days,omitempty"` // "mon" … "sun"; empty means every day
+	From    string   `json:"from,omitempty"` // "09:00"; empty means all day
+	To      string   `json:"to,omitempty"`   // "17:00"; before From for windows past midnight
+}
+
+// ScheduleConfig holds the time-based account rules
+type ScheduleConfig struct {
+	Rules []ScheduleRule `json:"rules,omitempty"`
+	// AutoSwitch lets 'krakn watch' set the global identity when the scheduled account changes
+	AutoSwitch bool `json:"auto_switch,omitempty"`
+}
+
+var scheduleDays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// parseScheduleDays accepts "weekdays", "weekend", ranges ("mon-fri") and lists ("mon,wed")
+func parseScheduleDays(spec string) ([]string, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	switch spec {
+	case "", "daily", "all":
+		return nil, nil
+	case "weekdays":
+		spec = "mon-fri"
+	case "weekend":
+		spec = "sat,sun"
+	}
+
+	index := func(day string) (int, error) {
+		day = strings.TrimSpace(day)
+		if len(day) > 3 {
+			day = day[:3]
+		}
+		for i, d := range scheduleDays {
+			if d == day {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("❌ Unknown day '%s'. Use mon, tue, wed, thu, fri, sat or sun", day)
+	}
+
+	var days []string
+	for _, part := range strings.Split(spec, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := index(first)
+		if err != nil {
+			return nil, err
+		}
+		end := start
+		if isRange {
+			if end, err = index(last); err != nil {
+				return nil, err
+			}
+		}
+		for i := start; ; i = (i + 1) % 7 {
+			if !containsString(days, scheduleDays[i]) {
+				days = append(days, scheduleDays[i])
+			}
+			if i == end {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// parseClock parses "9", "9:30" or "09:30" into minutes after midnight
+func parseClock(value string) (int, error) {
+	hours, minutes, _ := strings.Cut(strings.TrimSpace(value), ":")
+	h, err := strconv.Atoi(hours)
+	m := 0
+	if err == nil && minutes != "" {
+		m, err = strconv.Atoi(minutes)
+	}
+	if err != nil || h < 0 || h > 24 || m < 0 || m > 59 || h == 24 && m > 0 {
+		return 0, fmt.Errorf("❌ Invalid time '%s'. Use HH:MM", value)
+	}
+	return h*60 + m, nil
+}
+
+// matches reports whether the rule applies at the given time
+func (r ScheduleRule) matches(now time.Time) bool {
+	minute := now.Hour()*60 + now.Minute()
+	day := now.Weekday()
+
+	from, to := 0, 24*60
+	if r.From != "" {
+		from, _ = parseClock(r.From)
+	}
+	if r.To != "" {
+		to, _ = parseClock(r.To)
+	}
+	if to <= from && minute < to {
+		// Past midnight in a window that started the day before
+		day = (day + 6) % 7
+	} else if to > from && (minute < from || minute >= to) || to <= from && minute < from {
+		return false
+	}
+	return len(r.Days) == 0 || containsString(r.Days, scheduleDays[day])
+}
+
+// describe renders a rule for listings, e.g. "mon-fri 09:00-17:00"
+func (r ScheduleRule) describe() string {
+	days := "every day"
+	if len(r.Days) > 0 {
+		days = strings.Join(r.Days, ",")
+	}
+	if r.From == "" && r.To == "" {
+		return days + ", all day"
+	}
+	from, to := r.From, r.To
+	if from == "" {
+		from = "00:00"
+	}
+	if to == "" {
+		to = "24:00"
+	}
+	return fmt.Sprintf("%s, %s–%s", days, from, to)
+}
+
+// scheduledAccount returns the account the schedule selects at a time, or "" without a match
+func (c *Config) scheduledAccount(now time.Time) string {
+	if c.Schedule == nil {
+		return ""
+	}
+	for _, rule := range c.Schedule.Rules {
+		if rule.matches(now) && c.getAccount(rule.Account) != nil {
+			return rule.Account
+		}
+	}
+	return ""
+}
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Suggest or switch accounts by time of day",
+	Long: `Define which account to use at which times. The first matching rule wins, so
+add specific rules first and an all-day fallback last. The scheduled account is
+the default answer of account pickers; with 'krakn schedule auto on',
+'krakn watch' also switches the global identity whenever the scheduled account
+changes. Switching by hand always wins until the schedule changes again.
+
+Examples:
+  krakn schedule add work --days mon-fri --from 09:00 --to 17:00
+  krakn schedule add personal
+  krakn schedule list
+  krakn schedule auto on`,
+}
+
+var scheduleAddCmd = &cobra.Command{
+	Use:   "add <account-name>",
+	Short: "Add a schedule rule",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		daySpec, _ := cmd.Flags().GetString("days")
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+
+		days, err := parseScheduleDays(daySpec)
+		if err != nil {
+			return err
+		}
+		for _, clock := range []*string{&from, &to} {
+			if *clock == "" {
+				continue
+			}
+			minutes, err := parseClock(*clock)
+			if err != nil {
+				return err
+			}
+			*clock = fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if config.getAccount(args[0]) == nil {
+			return config.accountNotFound(args[0])
+		}
+		rule := ScheduleRule{Account: args[0], Days: days, From: from, To: to}
+		if err := config.update(func(config *Config) error {
+			if config.Schedule == nil {
+				config.Schedule = &ScheduleConfig{}
+			}
+			config.Schedule.Rules = append(config.Schedule.Rules, rule)
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("✅ Rule %d: %s → %s\n", len(config.Schedule.Rules), rule.describe(), rule.Account)
+		return nil
+	},
+}
+
+var scheduleListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show the schedule rules and the account scheduled now",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if config.Schedule == nil || len(config.Schedule.Rules) == 0 {
+			fmt.Println("📭 No schedule rules. Use 'krakn schedule add <account>'.")
+			return nil
+		}
+
+		now := time.Now()
+		active := -1
+		for i, rule := range config.Schedule.Rules {
+			if active == -1 && rule.matches(now) {
+				active = i
+			}
+		}
+		fmt.Println("🗓️  Schedule (first match wins):")
+		for i, rule := range config.Schedule.Rules {
+			marker := ""
+			if i == active {
+				marker = " ◀ now"
+			}
+			fmt.Printf("   %d. %s → %s%s\n", i+1, rule.describe(), rule.Account, marker)
+		}
+		if config.Schedule.AutoSwitch {
+			fmt.Println("🔄 'krakn watch' switches the global identity automatically")
+		} else {
+			fmt.Println("💡 Only suggestions; 'krakn schedule auto on' lets 'krakn watch' switch")
+		}
+		return nil
+	},
+}
+
+var scheduleRemoveCmd = &cobra.Command{
+	Use:   "remove <rule-number>",
+	Short: "Remove a schedule rule",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || config.Schedule == nil || n < 1 || n > len(config.Schedule.Rules) {
+			return fmt.Errorf("❌ No rule %s. See 'krakn schedule list'", args[0])
+		}
+		rule := config.Schedule.Rules[n-1]
+		if err := config.update(func(config *Config) error {
+			// Rules may have been renumbered by another krakn meanwhile
+			if config.Schedule == nil {
+				return nil
+			}
+			for i, existing := range config.Schedule.Rules {
+				if existing.Account == rule.Account && existing.describe() == rule.describe() {
+					config.Schedule.Rules = append(config.Schedule.Rules[:i], config.Schedule.Rules[i+1:]...)
+					break
+				}
+			}
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("✅ Removed rule %s → %s\n", rule.describe(), rule.Account)
+		return nil
+	},
+}
+
+var scheduleAutoCmd = &cobra.Command{
+	Use:       "auto <on|off>",
+	Short:     "Let 'krakn watch' switch the global identity on schedule",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"on", "off"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[0] != "on" && args[0] != "off" {
+			return fmt.Errorf("❌ Use 'on' or 'off'")
+		}
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if err := config.update(func(config *Config) error {
+			if config.Schedule == nil {
+				config.Schedule = &ScheduleConfig{}
+			}
+			config.Schedule.AutoSwitch = args[0] == "on"
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		if config.Schedule.AutoSwitch {
+			fmt.Println("✅ 'krakn watch' will switch the global identity on schedule")
+		} else {
+			fmt.Println("✅ The schedule only suggests accounts now")
+		}
+		return nil
+	},
+}
+
+func init() {
+	scheduleAddCmd.Flags().String("days", "", "Days the rule applies: weekdays, weekend, mon-fri or mon,wed (default every day)")
+	scheduleAddCmd.Flags().String("from", "", "Start time, e.g. 09:00 (default start of day)")
+	scheduleAddCmd.Flags().String("to", "", "End time, e.g. 17:00 (default end of day)")
+	scheduleCmd.AddCommand(scheduleAddCmd)
+	scheduleCmd.AddCommand(scheduleListCmd)
+	scheduleCmd.AddCommand(scheduleRemoveCmd)
+	scheduleCmd.AddCommand(scheduleAutoCmd)
+	RootCmd.AddCommand(scheduleCmd)
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// outputSchema describes the structured output of one command. Version is
+// printed as schema_version in every document; it is bumped whenever a field
+// is removed, renamed or changes its type or meaning. New optional fields do
+// not change the version, so consumers must ignore fields they do not know.
+type outputSchema struct {
+	Command     string
+	Version     int
+	Description string
+	Type        interface{} // Zero value of the output type, described by its json tags
+}
+
+// outputSchemas are the structured outputs with a stable schema, by command
+var outputSchemas = map[string]outputSchema{}
+
+// registerOutputSchema declares a command's structured output
+func registerOutputSchema(schema outputSchema) {
+	outputSchemas[schema.Command] = schema
+}
+
+// schemaVersion returns the schema_version printed by a command
+func schemaVersion(command string) int {
+	return outputSchemas[command].Version
+}
+
+// jsonSchema renders the JSON Schema of a registered output
+func (s outputSchema) jsonSchema() map[string]interface{} {
+	schema := typeSchema(reflect.TypeOf(s.Type))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$id"] = fmt.Sprintf("https://github.com/alminisl/krakncat/schema/%s/v%d.json", strings.ReplaceAll(s.Command, " ", "-"), s.Version)
+	schema["title"] = "krakn " + s.Command
+	schema["description"] = s.Description
+	// schema_version is pinned, so a document of another version fails validation
+	schema["properties"].(map[string]interface{})["schema_version"] = map[string]interface{}{"const": s.Version}
+	return schema
+}
+
+// typeSchema describes a Go type the way encoding/json marshals it. Fields
+// without omitempty are required; a "desc" tag becomes the description.
+func typeSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		if t.String() == "time.Time" {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+		properties := map[string]interface{}{}
+		required := []string{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			property := typeSchema(field.Type)
+			if desc := field.Tag.Get("desc"); desc != "" {
+				property["description"] = desc
+			}
+			properties[name] = property
+			if !strings.Contains(options, "omitempty") {
+				required = append(required, name)
+			}
+		}
+		sort.Strings(required)
+		return map[string]interface{}{"type": "object", "properties": properties, "required": required}
+	}
+	return map[string]interface{}{}
+}
+
+// schemaCommandNames lists the commands with a registered schema
+func schemaCommandNames() []string {
+	var names []string
+	for name := range outputSchemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [command]",
+	Short: "Print the JSON Schema of a command's JSON output",
+	Long: `Print the JSON Schema of the structured output of a command, for validating
+it in scripts and generating types. Without a command the commands that have a
+schema are listed.
+
+Every JSON and YAML document carries a schema_version. It changes only when a
+field is removed, renamed or changes its meaning; new optional fields can be
+added within a version, so ignore fields you do not know.
+
+Examples:
+  krakn schema                 # Commands with a schema
+  krakn schema list            # Schema of 'krakn list --output json'
+  krakn schema doctor > doctor.schema.json
+  krakn schema use --output yaml`,
+	Args: cobra.MaximumNArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return schemaCommandNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			fmt.Println("📐 Commands with a JSON schema:")
+			for _, name := range schemaCommandNames() {
+				schema := outputSchemas[name]
+				fmt.Printf("   %-14s v%d  %s\n", name, schema.Version, schema.Description)
+			}
+			return nil
+		}
+
+		schema, ok := outputSchemas[args[0]]
+		if !ok {
+			return fmt.Errorf("❌ 'krakn %s' has no JSON output schema; schemas exist for %s", args[0], joinWords(schemaCommandNames()))
+		}
+		if !structuredOutput() {
+			outputFormat = "json"
+		}
+		return printStructured(schema.jsonSchema())
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(schemaCmd)
+}
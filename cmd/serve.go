@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the local socket API for editor plugins",
+	Long: `Answer requests on a local Unix socket until interrupted, so editor plugins
+(a VS Code extension, a Neovim plugin) can ask which account a workspace uses
+or switch accounts without starting krakn for every keystroke.
+
+Each connection sends one JSON line and gets one back. The documents in
+"result" have the schemas of the matching commands ('krakn schema list',
+'krakn schema status', 'krakn schema doctor'):
+
+  {"method":"list","path":"/src/api"}     → {"api":1,"ok":true,"result":{"schema_version":1,"accounts":[...],...}}
+  {"method":"resolve","path":"/src/api"}  → {"api":1,"ok":true,"message":"work","result":{"schema_version":1,"match":"match",...}}
+  {"method":"doctor","path":"/src/api"}   → {"api":1,"ok":true,"message":"0 problem(s)","result":{...}}
+  {"method":"switch","account":"oss"}     → {"api":1,"ok":true,"current":"oss","message":"Switched to oss"}
+
+The accounts and current methods of 'krakn watch' work as well. Failures have
+"ok":false and an "error". 'krakn watch' serves the same API on the default
+socket, so run serve only when the daemon is not running or on another socket.
+The socket is only accessible to you.
+
+Examples:
+  krakn serve
+  krakn serve --socket ~/.krakncat/editor.sock
+  echo '{"method":"resolve","path":"'$PWD'"}' | nc -U ~/.krakncat/krakn.sock`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		socket, _ := cmd.Flags().GetString("socket")
+		path, err := filepath.Abs(expandUserPath(socket))
+		if err != nil {
+			return err
+		}
+
+		listener, err := serveIPC(path, loadWatchState())
+		if err != nil {
+			return fmt.Errorf("❌ Cannot serve %s: %w", contractHomePath(path), err)
+		}
+		defer listener.Close()
+		defer os.Remove(path)
+		fmt.Printf("🔌 Accepting requests on %s (Ctrl+C to stop)\n", contractHomePath(path))
+
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+		<-stop
+		fmt.Println("👋 Stopped serving")
+		return nil
+	},
+}
+
+func init() {
+	serveCmd.Flags().String("socket", contractHomePath(getSocketPath()), "Unix socket to listen on")
+	RootCmd.AddCommand(serveCmd)
+}
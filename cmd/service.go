@@ -0,0 +1,407 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// watchServiceLabel names the watch daemon in launchd and systemd
+const (
+	watchServiceLabel = "com.krakncat.watch"
+	watchServiceUnit  = "krakn-watch.service"
+)
+
+// watchService describes where the watch daemon is installed on this platform
+type watchService struct {
+	Path    string // launchd plist or systemd unit
+	LogPath string // Output of the daemon
+}
+
+// getWatchService returns the service files for the current platform:
+// a launchd agent on macOS and a systemd user unit on Linux
+func getWatchService() (*watchService, error) {
+	homeDir, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "darwin":
+		return &watchService{
+			Path:    filepath.Join(homeDir, "Library", "LaunchAgents", watchServiceLabel+".plist"),
+			LogPath: filepath.Join(homeDir, "Library", "Logs", "krakncat", "watch.log"),
+		}, nil
+	case "linux":
+		configHome := os.Getenv("XDG_CONFIG_HOME")
+		if configHome == "" {
+			configHome = filepath.Join(homeDir, ".config")
+		}
+		stateHome := os.Getenv("XDG_STATE_HOME")
+		if stateHome == "" {
+			stateHome = filepath.Join(homeDir, ".local", "state")
+		}
+		return &watchService{
+			Path:    filepath.Join(configHome, "systemd", "user", watchServiceUnit),
+			LogPath: filepath.Join(stateHome, "krakncat", "watch.log"),
+		}, nil
+	}
+	return nil, fmt.Errorf("❌ Services are supported on macOS (launchd) and Linux (systemd); run 'krakn watch' from your session's startup instead")
+}
+
+// xmlText escapes a value for the plist
+func xmlText(value string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
+
+// renderLaunchdPlist builds the launchd agent. launchd starts agents with a
+// minimal PATH, so the installing shell's PATH is kept for git and ssh.
+func renderLaunchdPlist(service *watchService, args []string) []byte {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>` + watchServiceLabel + `</string>
+	<key>ProgramArguments</key>
+	<array>
+`)
+	for _, arg := range args {
+		b.WriteString("\t\t<string>" + xmlText(arg) + "</string>\n")
+	}
+	b.WriteString(`	</array>
+	<key>EnvironmentVariables</key>
+	<dict>
+		<key>PATH</key>
+		<string>` + xmlText(os.Getenv("PATH")) + `</string>
+	</dict>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>ProcessType</key>
+	<string>Background</string>
+	<key>StandardOutPath</key>
+	<string>` + xmlText(service.LogPath) + `</string>
+	<key>StandardErrorPath</key>
+	<string>` + xmlText(service.LogPath) + `</string>
+</dict>
+</plist>
+`)
+	return []byte(b.String())
+}
+
+// systemdQuote quotes a word of an ExecStart line
+func systemdQuote(word string) string {
+	if word != "" && !strings.ContainsAny(word, " \t\"'\\%$;") {
+		return word
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$").Replace(word) + `"`
+}
+
+// renderSystemdUnit builds the systemd user unit. Output is appended to the
+// log file (systemd 240 or newer) rather than only to the journal, so
+// 'krakn service status' can show it on every system.
+func renderSystemdUnit(service *watchService, args []string) []byte {
+	var words []string
+	for _, arg := range args {
+		words = append(words, systemdQuote(arg))
+	}
+	return []byte(fmt.Sprintf(`[Unit]
+Description=krakncat identity watcher
+
+[Service]
+ExecStart=%s
+Environment=%s
+Restart=on-failure
+RestartSec=10
+StandardOutput=append:%s
+StandardError=append:%s
+
+[Install]
+WantedBy=default.target
+`, strings.Join(words, " "), systemdQuote("PATH="+os.Getenv("PATH")), service.LogPath, service.LogPath))
+}
+
+// launchdDomain is the launchctl domain of the user's GUI session
+func launchdDomain() string {
+	return fmt.Sprintf("gui/%d", os.Getuid())
+}
+
+// serviceCommand runs launchctl or systemctl and returns its error with the
+// first line of its output
+func serviceCommand(name string, args ...string) error {
+	output, err := traceExec(exec.Command(name, args...)).CombinedOutput()
+	if err != nil {
+		if text := firstLine(strings.TrimSpace(string(output))); text != "" {
+			return fmt.Errorf("%s %s: %s", name, args[0], text)
+		}
+		return fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return nil
+}
+
+// watchServiceState returns whether the daemon is running and its pid
+func watchServiceState() (running bool, pid string) {
+	if runtime.GOOS == "darwin" {
+		output, err := traceExec(exec.Command("launchctl", "print", launchdDomain()+"/"+watchServiceLabel)).Output()
+		if err != nil {
+			return false, ""
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			key, value, ok := strings.Cut(strings.TrimSpace(line), " = ")
+			if ok && key == "state" {
+				running = value == "running"
+			} else if ok && key == "pid" {
+				pid = value
+			}
+		}
+		return running, pid
+	}
+	output, _ := traceExec(exec.Command("systemctl", "--user", "show", watchServiceUnit, "--property=ActiveState,MainPID")).Output()
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), "=")
+		if key == "ActiveState" {
+			running = value == "active"
+		} else if key == "MainPID" && value != "0" {
+			pid = value
+		}
+	}
+	return running, pid
+}
+
+// stopWatchService stops the daemon and removes its service file
+func stopWatchService(service *watchService) error {
+	if runtime.GOOS == "darwin" {
+		// Not loaded is fine: the agent may have been stopped already
+		serviceCommand("launchctl", "bootout", launchdDomain()+"/"+watchServiceLabel)
+	} else {
+		serviceCommand("systemctl", "--user", "disable", "--now", watchServiceUnit)
+	}
+	trackFile(service.Path)
+	if err := os.Remove(service.Path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if runtime.GOOS == "linux" {
+		return serviceCommand("systemctl", "--user", "daemon-reload")
+	}
+	return nil
+}
+
+var serviceCmd = &cobra.Command{
+	Use:   "service",
+	Short: "Run 'krakn watch' as a user service",
+	Long: `Install 'krakn watch' as a user-level service that starts with your session
+and restarts when it fails: a launchd agent on macOS, a systemd user unit on
+Linux. No root access is needed.
+
+  macOS   ~/Library/LaunchAgents/com.krakncat.watch.plist
+          log: ~/Library/Logs/krakncat/watch.log
+  Linux   ~/.config/systemd/user/krakn-watch.service
+          log: ~/.local/state/krakncat/watch.log
+
+The service runs the krakn binary that installed it. Run 'krakn service
+install' again after moving the binary; 'krakn doctor' warns when it is gone.
+
+Examples:
+  krakn service install
+  krakn service install --interval 5m
+  krakn service status
+  krakn service uninstall`,
+}
+
+var serviceInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install and start the watch service",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		if interval < time.Second {
+			return fmt.Errorf("❌ --interval must be at least 1s")
+		}
+		service, err := getWatchService()
+		if err != nil {
+			return err
+		}
+		manager := "systemctl"
+		if runtime.GOOS == "darwin" {
+			manager = "launchctl"
+		}
+		if _, err := exec.LookPath(manager); err != nil {
+			return fmt.Errorf("❌ %s is not available, so the service cannot be installed", manager)
+		}
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to locate the krakn binary: %w", err)
+		}
+		if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+			executable = resolved
+		}
+
+		watchArgs := []string{executable, "--plain", "watch", "--interval", interval.String()}
+		content := renderSystemdUnit(service, watchArgs)
+		if runtime.GOOS == "darwin" {
+			content = renderLaunchdPlist(service, watchArgs)
+		}
+
+		for _, dir := range []string{filepath.Dir(service.Path), filepath.Dir(service.LogPath)} {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("failed to create %s: %w", dir, err)
+			}
+		}
+		// Replace a running instance, e.g. after an upgrade moved the binary
+		if fileExists(service.Path) {
+			if err := stopWatchService(service); err != nil {
+				return fmt.Errorf("failed to stop the installed service: %w", err)
+			}
+		}
+		trackFile(service.Path)
+		if err := writeFileAtomic(service.Path, content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", service.Path, err)
+		}
+
+		if runtime.GOOS == "darwin" {
+			err = serviceCommand("launchctl", "bootstrap", launchdDomain(), service.Path)
+		} else if err = serviceCommand("systemctl", "--user", "daemon-reload"); err == nil {
+			err = serviceCommand("systemctl", "--user", "enable", "--now", watchServiceUnit)
+		}
+		if err != nil {
+			return fmt.Errorf("❌ Wrote %s but could not start it: %w", contractHomePath(service.Path), err)
+		}
+
+		fmt.Printf("✅ Installed the watch service (every %s)\n", interval)
+		fmt.Printf("📁 Service: %s\n", contractHomePath(service.Path))
+		fmt.Printf("📜 Log: %s\n", contractHomePath(service.LogPath))
+		fmt.Println("💡 Check on it with: krakn service status")
+		return nil
+	},
+}
+
+var serviceUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Stop and remove the watch service",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		service, err := getWatchService()
+		if err != nil {
+			return err
+		}
+		if !fileExists(service.Path) {
+			fmt.Println("ℹ️  The watch service is not installed")
+			return nil
+		}
+		if err := stopWatchService(service); err != nil {
+			return fmt.Errorf("failed to remove the service: %w", err)
+		}
+		fmt.Println("🗑️  Removed the watch service")
+		fmt.Printf("📜 The log stays at %s\n", contractHomePath(service.LogPath))
+		return nil
+	},
+}
+
+var serviceStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the watch service is running",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lines, _ := cmd.Flags().GetInt("lines")
+		service, err := getWatchService()
+		if err != nil {
+			return err
+		}
+		if !fileExists(service.Path) {
+			fmt.Println("📭 The watch service is not installed. Install it with 'krakn service install'.")
+			return nil
+		}
+
+		fmt.Printf("📁 Service: %s\n", contractHomePath(service.Path))
+		if running, pid := watchServiceState(); running {
+			fmt.Printf("🟢 Running (pid %s)\n", pid)
+		} else {
+			fmt.Println("🔴 Not running")
+		}
+		if state := loadWatchState(); !state.LastSwitch.IsZero() {
+			fmt.Printf("🔄 Last switch: '%s' at %s\n", state.LastSwitchTo, state.LastSwitch.Format("2006-01-02 15:04"))
+		}
+
+		data, err := os.ReadFile(service.LogPath)
+		if err != nil {
+			fmt.Printf("📜 Log: %s (empty)\n", contractHomePath(service.LogPath))
+			return nil
+		}
+		fmt.Printf("📜 Log: %s\n", contractHomePath(service.LogPath))
+		logLines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+		if len(logLines) > lines {
+			logLines = logLines[len(logLines)-lines:]
+		}
+		for _, line := range logLines {
+			fmt.Println("   " + line)
+		}
+		return nil
+	},
+}
+
+// checkWatchService warns when the installed service points at a binary that
+// no longer exists, e.g. after an upgrade moved it
+func checkWatchService(ctx *doctorContext) []doctorFinding {
+	service, err := getWatchService()
+	if err != nil || !fileExists(service.Path) {
+		return nil
+	}
+	data, err := os.ReadFile(service.Path)
+	if err != nil {
+		return nil
+	}
+	executable, _ := os.Executable()
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	if executable != "" && !strings.Contains(string(data), executable) {
+		return []doctorFinding{{
+			Level:   doctorWarn,
+			Message: "The watch service runs another krakn binary than this one",
+			Hint:    "krakn service install",
+		}}
+	}
+	if running, _ := watchServiceState(); !running {
+		return []doctorFinding{{
+			Level:   doctorWarn,
+			Message: "The watch service is installed but not running",
+			Hint:    "krakn service status",
+		}}
+	}
+	return []doctorFinding{{Level: doctorOK, Message: "The watch service is running"}}
+}
+
+func init() {
+	serviceInstallCmd.Flags().Duration("interval", time.Minute, "Time between wake-ups of 'krakn watch'")
+	serviceStatusCmd.Flags().IntP("lines", "n", 10, "Number of log lines to show")
+	serviceCmd.AddCommand(serviceInstallCmd)
+	serviceCmd.AddCommand(serviceUninstallCmd)
+	serviceCmd.AddCommand(serviceStatusCmd)
+	RootCmd.AddCommand(serviceCmd)
+
+	registerDoctorCheck(doctorCheck{Name: "Watch service", Run: checkWatchService})
+	registerUninstallStep(uninstallStep{
+		Name: "watch service",
+		Describe: func(config *Config) []string {
+			if service, err := getWatchService(); err == nil && fileExists(service.Path) {
+				return []string{contractHomePath(service.Path)}
+			}
+			return nil
+		},
+		Run: func(config *Config) error {
+			if service, err := getWatchService(); err == nil && fileExists(service.Path) {
+				return stopWatchService(service)
+			}
+			return nil
+		},
+	})
+}
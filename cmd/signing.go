@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// signingValues returns the git config values of the account's commit
+// signing: its key and commit.gpgsign, or commit.gpgsign off when signing is
+// turned off for it. Accounts with neither leave signing as it is.
+func (a *Account) signingValues() []gitConfigValue {
+	switch {
+	case a.SigningKey != "":
+		return []gitConfigValue{
+			{Key: "user.signingkey", Value: a.SigningKey},
+			{Key: "commit.gpgsign", Value: "true"},
+		}
+	case a.NoSigning:
+		return []gitConfigValue{{Key: "commit.gpgsign", Value: "false"}}
+	}
+	return nil
+}
+
+// isAccountSigningKey reports whether a user.signingkey value is the signing
+// key of a krakn account, which a switch to another account may remove
+func (c *Config) isAccountSigningKey(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, account := range c.Accounts {
+		if account.SigningKey == value {
+			return true
+		}
+	}
+	return false
+}
+
+// staleSigningValues unsets the signing an account switched away from left in
+// configPath, so commits of an account without signing settings are not
+// signed with another account's key
+func (c *Config) staleSigningValues(configPath string, account *Account) []gitConfigValue {
+	if account.SigningKey != "" || account.NoSigning || !c.isAccountSigningKey(readGitConfigValue(configPath, "user", "signingkey")) {
+		return nil
+	}
+	return []gitConfigValue{
+		{Key: "user.signingkey", Unset: true},
+		{Key: "commit.gpgsign", Unset: true},
+	}
+}
+
+// checkGPGSecretKey reports whether gpg holds the secret key. It is skipped
+// when gpg is not installed, since git may be set up to use another program.
+func checkGPGSecretKey(key string) error {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		return nil
+	}
+	if err := traceExec(exec.Command("gpg", "--list-secret-keys", key)).Run(); err != nil {
+		return fmt.Errorf("gpg has no secret key '%s'", key)
+	}
+	return nil
+}
+
+var signingCmd = &cobra.Command{
+	Use:   "signing <account-name> [gpg-key-id]",
+	Short: "Set the GPG key an account's commits are signed with",
+	Long: `Sign the commits of an account with a GPG key, so work identities that
+require signed commits get them while others do not. 'krakn use' sets
+user.signingkey and commit.gpgsign, and the include files of the account's
+directory mappings and overrides carry them too.
+
+Accounts without signing settings leave commit.gpgsign alone; a switch to one
+only removes a signing key krakn set for another account. Use --never for an
+account whose commits must not be signed even where signing is on globally.
+
+Without a key or flags, the current setting is shown.
+
+Examples:
+  krakn signing work 3AA5C34371567BD2
+  krakn signing personal --never
+  krakn signing work --clear`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		never, _ := cmd.Flags().GetBool("never")
+		reset, _ := cmd.Flags().GetBool("clear")
+		if len(args) == 2 && (never || reset) {
+			return fmt.Errorf("❌ Give either a key, --never or --clear")
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+
+		if len(args) == 1 && !never && !reset {
+			switch {
+			case account.SigningKey != "":
+				fmt.Printf("✍️  Commits of '%s' are signed with GPG key %s\n", account.Name, account.SigningKey)
+			case account.NoSigning:
+				fmt.Printf("✍️  Commits of '%s' are never signed\n", account.Name)
+			default:
+				fmt.Printf("ℹ️  Account '%s' has no signing settings\n", account.Name)
+			}
+			return nil
+		}
+
+		account.SigningKey, account.NoSigning = "", never
+		if len(args) == 2 {
+			key := strings.TrimSpace(args[1])
+			if err := checkGPGSecretKey(key); err != nil {
+				fmt.Printf("⚠️  %v; commits will fail to sign until it is imported\n", err)
+			}
+			account.SigningKey = key
+		}
+
+		if err := config.addAccount(*account); err != nil {
+			return errSaveConfig(err)
+		}
+		if err := config.rewriteAccountIncludes(account); err != nil {
+			return err
+		}
+
+		switch {
+		case account.SigningKey != "":
+			fmt.Printf("✅ Commits of '%s' are signed with GPG key %s\n", account.Name, account.SigningKey)
+		case account.NoSigning:
+			fmt.Printf("✅ Commits of '%s' are never signed\n", account.Name)
+		default:
+			fmt.Printf("✅ Removed the signing settings of '%s'\n", account.Name)
+		}
+		fmt.Printf("💡 Run 'krakn use %s' to apply it to the global or a repository's config\n", account.Name)
+		return nil
+	},
+}
+
+func init() {
+	signingCmd.Flags().Bool("never", false, "Never sign the account's commits, even where signing is on globally")
+	signingCmd.Flags().Bool("clear", false, "Remove the account's signing settings")
+	RootCmd.AddCommand(signingCmd)
+}
@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sshOption is a single keyword/argument line inside an ssh_config block
+type sshOption struct {
+	Key   string // Keyword as written, e.g. "IdentityFile"
+	Value string
+}
+
+// sshHostBlock is a "Host" block of ~/.ssh/config
+type sshHostBlock struct {
+	Patterns  []string
+	Options   []sshOption
+	StartLine int // Index of the Host line
+	EndLine   int // Index after the last line belonging to the block
+}
+
+// getSSHConfigPath returns the path of the user's SSH client config
+func getSSHConfigPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".ssh", "config")
+}
+
+// splitSSHConfigLine splits "Keyword value" or "Keyword=value"
+func splitSSHConfigLine(line string) (key, value string, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return "", "", false
+	}
+
+	end := strings.IndexAny(trimmed, " \t=")
+	if end == -1 {
+		return trimmed, "", true
+	}
+	key = trimmed[:end]
+	value = strings.TrimSpace(trimmed[end:])
+	value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+	return key, strings.Trim(value, `"`), true
+}
+
+// parseSSHConfig returns the Host blocks of an ssh_config file.
+// Match blocks terminate the preceding Host block but are not returned.
+func parseSSHConfig(content string) []sshHostBlock {
+	var blocks []sshHostBlock
+	var current *sshHostBlock
+
+	lines := strings.Split(content, "\n")
+	closeBlock := func(end int) {
+		if current != nil {
+			current.EndLine = end
+			blocks = append(blocks, *current)
+			current = nil
+		}
+	}
+
+	for i, line := range lines {
+		key, value, ok := splitSSHConfigLine(line)
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "host":
+			closeBlock(i)
+			current = &sshHostBlock{Patterns: strings.Fields(value), StartLine: i}
+		case "match":
+			closeBlock(i)
+		default:
+			if current != nil {
+				current.Options = append(current.Options, sshOption{Key: key, Value: value})
+			}
+		}
+	}
+	closeBlock(len(lines))
+
+	return blocks
+}
+
+// readSSHConfig parses ~/.ssh/config; a missing file yields no blocks
+func readSSHConfig() ([]sshHostBlock, error) {
+	content, err := os.ReadFile(getSSHConfigPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseSSHConfig(string(content)), nil
+}
+
+// get returns the first value for a keyword, matching ssh's first-wins semantics
+func (b *sshHostBlock) get(key string) string {
+	for _, option := range b.Options {
+		if strings.EqualFold(option.Key, key) {
+			return option.Value
+		}
+	}
+	return ""
+}
+
+// alias returns the first non-wildcard pattern of the block
+func (b *sshHostBlock) alias() string {
+	for _, pattern := range b.Patterns {
+		if !strings.ContainsAny(pattern, "*?!") {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// hostName returns the real hostname the block connects to
+func (b *sshHostBlock) hostName() string {
+	if hostName := b.get("HostName"); hostName != "" {
+		return hostName
+	}
+	return b.alias()
+}
+
+// sshEnvPattern matches the ${VAR} references ssh expands in file paths
+var sshEnvPattern = regexp.MustCompile(`\$\{[A-Za-z_][A-Za-z0-9_]*\}`)
+
+// expandSSHPath expands a file path from ssh_config the way ssh does for
+// IdentityFile: a leading ~ or ~user, ${VAR} environment variables and the
+// %d, %u, %i, %l, %L, %h, %n, %k, %p, %r and %% tokens of ssh_config(5). The
+// host tokens come from block, which may be nil for paths outside a block.
+// Unknown tokens are left as written.
+func expandSSHPath(path string, block *sshHostBlock) string {
+	path = expandUserPath(path)
+	if strings.HasPrefix(path, "~") {
+		name, rest, _ := strings.Cut(path[1:], "/")
+		if u, err := user.Lookup(name); err == nil {
+			path = filepath.Join(u.HomeDir, rest)
+		}
+	}
+
+	path = sshEnvPattern.ReplaceAllStringFunc(path, func(match string) string {
+		if value, ok := os.LookupEnv(match[2 : len(match)-1]); ok {
+			return value
+		}
+		return match
+	})
+	if !strings.Contains(path, "%") {
+		return path
+	}
+
+	values := map[byte]string{'%': "%"}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		values['d'] = homeDir
+	}
+	if u, err := user.Current(); err == nil {
+		values['u'] = u.Username
+	}
+	if uid := os.Getuid(); uid >= 0 {
+		values['i'] = strconv.Itoa(uid)
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		values['l'] = hostname
+		values['L'], _, _ = strings.Cut(hostname, ".")
+	}
+	if block != nil {
+		values['n'] = block.alias()
+		values['h'] = block.hostName()
+		values['k'] = block.alias()
+		if alias := block.get("HostKeyAlias"); alias != "" {
+			values['k'] = alias
+		}
+		values['p'] = "22"
+		if port := block.get("Port"); port != "" {
+			values['p'] = port
+		}
+		if remoteUser := block.get("User"); remoteUser != "" {
+			values['r'] = remoteUser
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '%' && i+1 < len(path) {
+			if value, ok := values[path[i+1]]; ok {
+				b.WriteString(value)
+				i++
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
+
+// GenerateSSHConfig renders the managed Host block for the account
+func (a *Account) GenerateSSHConfig() string {
+	provider := a.GetProvider()
+	lines := []string{
+		"Host " + a.GetSSHHost(),
+		"  HostName " + provider.Hostname,
+		"  User " + provider.SSHUser,
+		"  IdentityFile " + contractHomePath(a.identityFile()),
+	}
+	if provider.SSHPort != "" && provider.SSHPort != "22" {
+		lines = append(lines, "  Port "+provider.SSHPort)
+	}
+	if a.Multiplex {
+		persist := a.ControlPersist
+		if persist == "" {
+			persist = defaultControlPersist
+		}
+		lines = append(lines,
+			"  ControlMaster auto",
+			"  ControlPath "+contractHomePath(filepath.Join(getSocketDir(), "%C")),
+			"  ControlPersist "+persist,
+		)
+	}
+	for _, option := range a.SSHOptions {
+		lines = append(lines, fmt.Sprintf("  %s %s", option.Key, option.Value))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// findSSHHostBlockLines returns the line range of the single-pattern Host block
+// for alias. Blank lines and comments that precede the next block are excluded.
+func findSSHHostBlockLines(text, alias string) (start, end int, ok bool) {
+	lines := strings.Split(text, "\n")
+	for _, existing := range parseSSHConfig(text) {
+		if len(existing.Patterns) != 1 || existing.Patterns[0] != alias {
+			continue
+		}
+		start, end := existing.lineRange(lines)
+		return start, end, true
+	}
+	return 0, 0, false
+}
+
+// lineRange returns the lines the block occupies in lines, without the blank
+// lines and comments that precede the next block
+func (b *sshHostBlock) lineRange(lines []string) (start, end int) {
+	end = b.EndLine
+	for end > b.StartLine+1 && (strings.TrimSpace(lines[end-1]) == "" || strings.HasPrefix(strings.TrimSpace(lines[end-1]), "#")) {
+		end--
+	}
+	return b.StartLine, end
+}
+
+// removeSSHHostBlock deletes the Host block for alias from ~/.ssh/config.
+// It reports whether a block was found.
+func removeSSHHostBlock(alias string) (bool, error) {
+	configPath := getSSHConfigPath()
+	if skipDotfileEdit(configPath, "removing Host "+alias) {
+		return false, nil
+	}
+	unlock, err := lockFile(configPath)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	content, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read SSH config: %w", err)
+	}
+
+	text := string(content)
+	start, end, ok := findSSHHostBlockLines(text, alias)
+	if !ok {
+		return false, nil
+	}
+
+	lines := strings.Split(text, "\n")
+	// Drop the blank line separating the block from the next one as well
+	for end < len(lines)-1 && strings.TrimSpace(lines[end]) == "" {
+		end++
+	}
+	updated := append(append([]string{}, lines[:start]...), lines[end:]...)
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return false, err
+	}
+	trackFile(configPath)
+	if err := writeFileAtomic(configPath, []byte(strings.Join(updated, "\n")), info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("failed to write SSH config: %w", err)
+	}
+	if err := restrictToOwner(configPath); err != nil {
+		return false, fmt.Errorf("failed to restrict access to the SSH config: %w", err)
+	}
+	return true, nil
+}
+
+// upsertSSHHostBlock replaces the Host block for alias in ~/.ssh/config,
+// or appends it when the alias is not defined yet. Other blocks are untouched.
+func upsertSSHHostBlock(alias, block string) error {
+	if skipDotfileEdit(getSSHConfigPath(), "writing Host "+alias) {
+		return nil
+	}
+	if err := ensureSSHDirectory(); err != nil {
+		return err
+	}
+
+	configPath := getSSHConfigPath()
+	unlock, err := lockFile(configPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	content, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read SSH config: %w", err)
+	}
+
+	text := string(content)
+	lines := strings.Split(text, "\n")
+	blockLines := strings.Split(strings.TrimSuffix(block, "\n"), "\n")
+
+	start, end, replaced := findSSHHostBlockLines(text, alias)
+	if replaced {
+		updated := append([]string{}, lines[:start]...)
+		updated = append(updated, blockLines...)
+		updated = append(updated, lines[end:]...)
+		lines = updated
+	}
+
+	if replaced {
+		text = strings.Join(lines, "\n")
+	} else {
+		if strings.TrimSpace(text) != "" {
+			text = strings.TrimRight(text, "\n") + "\n\n"
+		}
+		text += strings.Join(blockLines, "\n") + "\n"
+	}
+
+	// OpenSSH refuses a config others can write to; new files are private
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(configPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	trackFile(configPath)
+	if err := writeFileAtomic(configPath, []byte(text), mode); err != nil {
+		return fmt.Errorf("failed to write SSH config: %w", err)
+	}
+	// The rename gives the file the parent's ACL on Windows, so this runs
+	// after every write
+	if err := restrictToOwner(configPath); err != nil {
+		return fmt.Errorf("failed to restrict access to the SSH config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// accountIncludeMappings returns the directory mappings to render include
+// snippets for: the account's own mappings, or one for dir when given
+func accountIncludeMappings(config *Config, account *Account, dir string) ([]DirectoryMapping, error) {
+	if dir != "" {
+		path, err := filepath.Abs(expandUserPath(dir))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve directory path: %w", err)
+		}
+		return []DirectoryMapping{{Path: path, Account: account.Name, ConfigFile: filepath.Join(path, ".gitconfig")}}, nil
+	}
+	var mappings []DirectoryMapping
+	for _, mapping := range config.Directories {
+		if mapping.Account == account.Name {
+			mappings = append(mappings, mapping)
+		}
+	}
+	return mappings, nil
+}
+
+// renderAccountConfig renders the SSH Host block and gitconfig include
+// snippets krakncat would write for an account, as commented sections
+func renderAccountConfig(account *Account, mappings []DirectoryMapping) string {
+	out := ""
+	if account.usesHTTPS() {
+		out += "# ~/.ssh/config: none, the account uses HTTPS only\n"
+	} else {
+		out += "# ~/.ssh/config\n" + account.GenerateSSHConfig()
+	}
+
+	identity := identityInclude(account, "")
+	if len(mappings) == 0 {
+		// Not mapped anywhere yet; show where the snippets would go
+		example := filepath.Join("~", "code", account.Name)
+		out += fmt.Sprintf("\n# ~/.gitconfig\n[includeIf \"gitdir:%s\"]\n\tpath = %s\n", gitDirPattern(example), filepath.Join(example, ".gitconfig"))
+		out += fmt.Sprintf("\n# %s\n%s", filepath.Join(example, ".gitconfig"), identity)
+		return out
+	}
+	for _, mapping := range mappings {
+		out += fmt.Sprintf("\n# ~/.gitconfig\n[includeIf \"gitdir:%s\"]\n\tpath = %s\n", mapping.includeIfPattern(), contractHomePath(mapping.ConfigFile))
+		out += fmt.Sprintf("\n# %s\n%s", contractHomePath(mapping.ConfigFile), identity)
+	}
+	return out
+}
+
+var sshConfigCmd = &cobra.Command{
+	Use:   "ssh-config",
+	Short: "Show the SSH and git configuration krakncat manages",
+	Long: `Inspect the configuration krakncat writes to ~/.ssh/config and ~/.gitconfig.
+
+Examples:
+  krakn ssh-config print work`,
+}
+
+var sshConfigPrintCmd = &cobra.Command{
+	Use:   "print <account-name>",
+	Short: "Print an account's SSH Host block and gitconfig include without writing them",
+	Long: `Print the exact SSH Host block and gitconfig include snippets krakncat
+would write for an account, for copying into dotfiles you manage yourself.
+Nothing is written.
+
+Each snippet is preceded by a comment naming the file it belongs in. The
+include snippets are those of the account's directory mappings; --dir renders
+them for another directory, and an unmapped account gets an example for
+~/code/<account>.
+
+Examples:
+  krakn ssh-config print work
+  krakn ssh-config print work --dir ~/work
+  krakn ssh-config print work >> ~/dotfiles/ssh/config`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("dir")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+		if err := config.revealAccount(account); err != nil {
+			return err
+		}
+
+		mappings, err := accountIncludeMappings(config, account, dir)
+		if err != nil {
+			return err
+		}
+		fmt.Print(renderAccountConfig(account, mappings))
+		return nil
+	},
+}
+
+func init() {
+	sshConfigPrintCmd.Flags().String("dir", "", "Render the include snippets for this directory")
+	sshConfigCmd.AddCommand(sshConfigPrintCmd)
+	RootCmd.AddCommand(sshConfigCmd)
+}
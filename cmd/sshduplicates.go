@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// sshDuplicateGroup is an alias with more than one krakncat-style Host block
+// in ~/.ssh/config, as older versions appended them on every run
+type sshDuplicateGroup struct {
+	Alias   string
+	Blocks  []sshHostBlock // In file order
+	Account *Account       // Account whose generated block this is, if any
+	Keep    int            // Index in Blocks of the block that stays
+}
+
+// canonical returns the block text the alias should have: the account's
+// generated block, or else the block that stays as it is
+func (g *sshDuplicateGroup) canonical(lines []string) string {
+	if g.Account != nil {
+		return strings.TrimSuffix(g.Account.GenerateSSHConfig(), "\n")
+	}
+	start, end := g.Blocks[g.Keep].lineRange(lines)
+	return strings.Join(lines[start:end], "\n")
+}
+
+// rewritesKept reports whether the block that stays differs from the
+// canonical one and is replaced by it
+func (g *sshDuplicateGroup) rewritesKept(lines []string) bool {
+	start, end := g.Blocks[g.Keep].lineRange(lines)
+	return normalizeSSHBlock(strings.Join(lines[start:end], "\n")) != normalizeSSHBlock(g.canonical(lines))
+}
+
+// normalizeSSHBlock drops indentation, blank lines and comments, so blocks
+// that differ only in layout compare equal
+func normalizeSSHBlock(text string) string {
+	var kept []string
+	for _, line := range strings.Split(text, "\n") {
+		if key, value, ok := splitSSHConfigLine(line); ok {
+			kept = append(kept, strings.ToLower(key)+" "+value)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// isKrakncatStyleBlock reports whether a block looks like one krakncat
+// generated: a single alias of the form <hostname>-<name> with a HostName and
+// an IdentityFile
+func isKrakncatStyleBlock(block *sshHostBlock) bool {
+	hostName := block.get("HostName")
+	return hostName != "" && block.get("IdentityFile") != "" && strings.HasPrefix(block.Patterns[0], hostName+"-")
+}
+
+// findSSHDuplicates returns the aliases of ~/.ssh/config text that have more
+// than one single-pattern Host block generated by krakncat or for an account.
+// Blocks the user wrote by hand for other aliases are never touched.
+func findSSHDuplicates(config *Config, text string) []sshDuplicateGroup {
+	lines := strings.Split(text, "\n")
+	byAlias := map[string]*sshDuplicateGroup{}
+	var aliases []string
+	for _, block := range parseSSHConfig(text) {
+		if len(block.Patterns) != 1 || block.alias() == "" {
+			continue
+		}
+		alias := block.Patterns[0]
+		group := byAlias[alias]
+		if group == nil {
+			group = &sshDuplicateGroup{Alias: alias}
+			byAlias[alias] = group
+			aliases = append(aliases, alias)
+		}
+		group.Blocks = append(group.Blocks, block)
+	}
+
+	var groups []sshDuplicateGroup
+	for _, alias := range aliases {
+		group := byAlias[alias]
+		if len(group.Blocks) < 2 {
+			continue
+		}
+		for i := range config.Accounts {
+			account := &config.Accounts[i]
+			if account.SSHHost == "" && !account.usesHTTPS() && account.GetSSHHost() == alias {
+				group.Account = account
+				break
+			}
+		}
+		if group.Account == nil && !isKrakncatStyleBlock(&group.Blocks[0]) {
+			continue
+		}
+
+		// ssh applies the first block, so it stays unless a later one is
+		// already the account's generated block
+		if group.Account != nil {
+			canonical := normalizeSSHBlock(group.Account.GenerateSSHConfig())
+			for i := range group.Blocks {
+				start, end := group.Blocks[i].lineRange(lines)
+				if normalizeSSHBlock(strings.Join(lines[start:end], "\n")) == canonical {
+					group.Keep = i
+					break
+				}
+			}
+		}
+		groups = append(groups, *group)
+	}
+	return groups
+}
+
+// removeSSHDuplicates returns the text without the extra blocks of every
+// group, with each kept block replaced by its canonical text
+func removeSSHDuplicates(text string, groups []sshDuplicateGroup) string {
+	lines := strings.Split(text, "\n")
+	type edit struct {
+		start, end  int
+		replacement []string
+	}
+	var edits []edit
+	for _, group := range groups {
+		for i, block := range group.Blocks {
+			start, end := block.lineRange(lines)
+			if i == group.Keep {
+				if group.rewritesKept(lines) {
+					edits = append(edits, edit{start, end, strings.Split(group.canonical(lines), "\n")})
+				}
+				continue
+			}
+			// Drop the blank line separating the block from the next one as well
+			for end < len(lines)-1 && strings.TrimSpace(lines[end]) == "" {
+				end++
+			}
+			edits = append(edits, edit{start: start, end: end})
+		}
+	}
+
+	// From the bottom up, so earlier line numbers stay valid
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		updated := append([]string{}, lines[:e.start]...)
+		updated = append(updated, e.replacement...)
+		lines = append(updated, lines[e.end:]...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// printSSHDuplicates shows which block of every group stays and which go
+func printSSHDuplicates(groups []sshDuplicateGroup, lines []string) {
+	for _, group := range groups {
+		owner := ""
+		if group.Account != nil {
+			owner = fmt.Sprintf(" (account '%s')", group.Account.Name)
+		}
+		fmt.Printf("👯 Host %s appears %d times%s\n", group.Alias, len(group.Blocks), owner)
+		for i, block := range group.Blocks {
+			action := "🗑️  remove"
+			if i == group.Keep {
+				action = "✅ keep  "
+				if group.rewritesKept(lines) {
+					action = "✏️  rewrite"
+				}
+			}
+			fmt.Printf("   %s line %-4d IdentityFile %s\n", action, block.StartLine+1, block.get("IdentityFile"))
+		}
+	}
+}
+
+var nukeSSHDuplicatesCmd = &cobra.Command{
+	Use:   "nuke-ssh-duplicates",
+	Short: "Remove repeated Host blocks older versions appended to ~/.ssh/config",
+	Long: `Find aliases with more than one Host block in ~/.ssh/config, as older
+versions of krakncat appended a new block on every run, and keep one block per
+alias.
+
+Only krakncat-style blocks are considered: the generated alias of an account,
+or an alias of the form <hostname>-<name> with a HostName and an IdentityFile.
+For an account's alias the block kept is the one krakncat generates from the
+config today, written in place of the first one when none matches it. Other
+aliases keep their first block, the one ssh applies.
+
+The original file is saved next to it as config.krakn-<time>.bak before it is
+rewritten, and 'krakn revert' undoes the change too.
+
+Examples:
+  krakn nuke-ssh-duplicates --dry-run   # Only show what would be removed
+  krakn nuke-ssh-duplicates
+  krakn nuke-ssh-duplicates --yes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		configPath := getSSHConfigPath()
+		content, err := os.ReadFile(configPath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read SSH config: %w", err)
+		}
+		groups := findSSHDuplicates(config, string(content))
+		if len(groups) == 0 {
+			fmt.Printf("✅ No duplicate Host blocks in %s\n", contractHomePath(configPath))
+			return nil
+		}
+		printSSHDuplicates(groups, strings.Split(string(content), "\n"))
+		if dryRun {
+			fmt.Println("\n💡 Dry run: nothing was changed. Run without --dry-run to clean up")
+			return nil
+		}
+		if skipDotfileEdit(configPath, "removing duplicate Host blocks") {
+			return nil
+		}
+		if !yes {
+			if !term.IsTerminal(int(os.Stdin.Fd())) {
+				return fmt.Errorf("❌ Found %d duplicated alias(es); pass --yes to remove the extra blocks or --dry-run to only show them", len(groups))
+			}
+			fmt.Print("\n🧹 Remove the extra blocks? [y/N]: ")
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+				fmt.Println("⏭️  Nothing changed")
+				return nil
+			}
+		}
+
+		unlock, err := lockFile(configPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		// The file may have changed while the prompt was open
+		content, err = os.ReadFile(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to read SSH config: %w", err)
+		}
+		groups = findSSHDuplicates(config, string(content))
+		if len(groups) == 0 {
+			fmt.Println("✅ The duplicates are already gone")
+			return nil
+		}
+
+		backup := configPath + ".krakn-" + time.Now().Format("20060102-150405") + ".bak"
+		if err := writePrivateFile(backup, content); err != nil {
+			return fmt.Errorf("failed to back up the SSH config: %w", err)
+		}
+		info, err := os.Stat(configPath)
+		if err != nil {
+			return err
+		}
+		trackFile(configPath)
+		if err := writeFileAtomic(configPath, []byte(removeSSHDuplicates(string(content), groups)), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write SSH config: %w", err)
+		}
+		if err := restrictToOwner(configPath); err != nil {
+			return fmt.Errorf("failed to restrict access to the SSH config: %w", err)
+		}
+
+		removed := 0
+		for _, group := range groups {
+			removed += len(group.Blocks) - 1
+		}
+		fmt.Printf("🧹 Removed %d duplicate Host block(s) from %s\n", removed, contractHomePath(configPath))
+		fmt.Printf("💾 Backup: %s\n", contractHomePath(backup))
+		return nil
+	},
+}
+
+func checkSSHDuplicates(ctx *doctorContext) []doctorFinding {
+	content, err := os.ReadFile(getSSHConfigPath())
+	if err != nil {
+		return nil
+	}
+	var findings []doctorFinding
+	for _, group := range findSSHDuplicates(ctx.Config, string(content)) {
+		findings = append(findings, doctorFinding{
+			Level:   doctorWarn,
+			Message: fmt.Sprintf("Host %s is defined %d times in %s", group.Alias, len(group.Blocks), contractHomePath(getSSHConfigPath())),
+			Hint:    "krakn nuke-ssh-duplicates",
+		})
+	}
+	return findings
+}
+
+func init() {
+	nukeSSHDuplicatesCmd.Flags().Bool("dry-run", false, "Only show the duplicates and what would be removed")
+	nukeSSHDuplicatesCmd.Flags().BoolP("yes", "y", false, "Remove the extra blocks without asking")
+	RootCmd.AddCommand(nukeSSHDuplicatesCmd)
+	registerDoctorCheck(doctorCheck{Name: "Duplicate Host blocks", Run: checkSSHDuplicates})
+}
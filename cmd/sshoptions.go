@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// reservedSSHOptions are rendered by krakncat itself and cannot be overridden
+var reservedSSHOptions = []string{"host", "hostname", "user", "identityfile", "port", "match",
+	"controlmaster", "controlpath", "controlpersist"}
+
+// validateSSHOption asks OpenSSH to resolve the option so typos are caught at edit time.
+// Validation is skipped when the ssh client is not installed.
+func validateSSHOption(alias string, option SSHOption) error {
+	if containsString(reservedSSHOptions, strings.ToLower(option.Key)) {
+		if strings.HasPrefix(strings.ToLower(option.Key), "control") {
+			return fmt.Errorf("%s is managed by 'krakn multiplex'", option.Key)
+		}
+		return fmt.Errorf("%s is managed by krakncat and cannot be set as an extra option", option.Key)
+	}
+	if _, err := exec.LookPath("ssh"); err != nil {
+		return nil
+	}
+
+	output, err := traceExec(exec.Command("ssh", "-G", "-o", option.Key+"="+option.Value, alias)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ssh rejected %s=%s: %s", option.Key, option.Value, strings.TrimSpace(string(output)))
+	}
+
+	// ssh -G prints every resolved option in lower case
+	prefix := strings.ToLower(option.Key) + " "
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, prefix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("ssh does not recognize the option %s", option.Key)
+}
+
+// parseSSHOptionArg parses "Key=Value" or "Key Value"
+func parseSSHOptionArg(arg string) (SSHOption, error) {
+	key, value, ok := splitSSHConfigLine(arg)
+	if !ok || key == "" || value == "" {
+		return SSHOption{}, fmt.Errorf("invalid option %q, expected Key=Value", arg)
+	}
+	return SSHOption{Key: key, Value: value}, nil
+}
+
+// setSSHOption replaces an option with the same keyword or appends it
+func (a *Account) setSSHOption(option SSHOption) {
+	for i, existing := range a.SSHOptions {
+		if strings.EqualFold(existing.Key, option.Key) {
+			a.SSHOptions[i] = option
+			return
+		}
+	}
+	a.SSHOptions = append(a.SSHOptions, option)
+}
+
+// unsetSSHOption removes an option by keyword; it reports whether anything was removed
+func (a *Account) unsetSSHOption(key string) bool {
+	for i, existing := range a.SSHOptions {
+		if strings.EqualFold(existing.Key, key) {
+			a.SSHOptions = append(a.SSHOptions[:i], a.SSHOptions[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+var sshOptionsCmd = &cobra.Command{
+	Use:   "ssh-options [account-name] [Key=Value...]",
+	Short: "Show or set extra SSH options for an account's Host block",
+	Long: `Show or set extra ssh_config options (compression, multiplexing, keepalives, ...)
+for an account. Options are validated with 'ssh -G' and rendered into the
+account's Host block in ~/.ssh/config.
+
+Examples:
+  krakn ssh-options work                                  # List options
+  krakn ssh-options work Compression=yes ServerAliveInterval=30
+  krakn ssh-options work --unset Compression`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountName := args[0]
+		unset, _ := cmd.Flags().GetStringSlice("unset")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		account := config.getAccount(accountName)
+		if account == nil {
+			return config.accountNotFound(accountName)
+		}
+
+		if len(args) == 1 && len(unset) == 0 {
+			if len(account.SSHOptions) == 0 {
+				fmt.Printf("ℹ️  No extra SSH options for '%s'\n", accountName)
+				return nil
+			}
+			fmt.Printf("⚙️  SSH options for '%s' (%s):\n", accountName, account.GetSSHHost())
+			for _, option := range account.SSHOptions {
+				fmt.Printf("   %s %s\n", option.Key, option.Value)
+			}
+			return nil
+		}
+
+		if account.SSHHost != "" {
+			return fmt.Errorf("❌ Account '%s' is linked to your own alias %s; edit that block in ~/.ssh/config directly", accountName, account.SSHHost)
+		}
+
+		for _, arg := range args[1:] {
+			option, err := parseSSHOptionArg(arg)
+			if err != nil {
+				return fmt.Errorf("❌ %w", err)
+			}
+			if err := validateSSHOption(account.GetSSHHost(), option); err != nil {
+				return fmt.Errorf("❌ %w", err)
+			}
+			account.setSSHOption(option)
+			fmt.Printf("✅ %s %s\n", option.Key, option.Value)
+		}
+
+		for _, key := range unset {
+			if account.unsetSSHOption(key) {
+				fmt.Printf("🗑️  Removed %s\n", key)
+			} else {
+				fmt.Printf("ℹ️  %s was not set\n", key)
+			}
+		}
+
+		if err := config.addAccount(*account); err != nil {
+			return errSaveConfig(err)
+		}
+
+		if err := upsertSSHHostBlock(account.GetSSHHost(), account.GenerateSSHConfig()); err != nil {
+			return err
+		}
+		if !config.LocalOnly {
+			fmt.Printf("📝 Updated Host %s in ~/.ssh/config\n", account.GetSSHHost())
+		}
+		return nil
+	},
+}
+
+func init() {
+	sshOptionsCmd.Flags().StringSlice("unset", nil, "Remove an option by keyword")
+	RootCmd.AddCommand(sshOptionsCmd)
+}
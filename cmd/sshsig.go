@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"hash"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SSH signatures in the format of 'ssh-keygen -Y sign' (PROTOCOL.sshsig in
+// the OpenSSH sources). The namespace is part of the signed data, so a
+// signature made for one purpose cannot be passed off as one for another,
+// e.g. a card signature as a signed commit.
+
+const (
+	sshsigMagic   = "SSHSIG"
+	sshsigVersion = 1
+	sshsigArmor   = "SSH SIGNATURE"
+)
+
+// sshsigSignedData is what the key actually signs, after the magic preamble
+type sshsigSignedData struct {
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+// sshsigBlob is the signature as written out, after the magic preamble
+type sshsigBlob struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// sshsigHash returns the hash function for a hash algorithm name
+func sshsigHash(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case "sha512":
+		return sha512.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	}
+	return nil, fmt.Errorf("unsupported hash algorithm '%s'", algorithm)
+}
+
+// sshsigData builds the data signed for message under namespace
+func sshsigData(namespace, algorithm string, message []byte) ([]byte, error) {
+	h, err := sshsigHash(algorithm)
+	if err != nil {
+		return nil, err
+	}
+	h.Write(message)
+	signed := sshsigSignedData{Namespace: namespace, HashAlgorithm: algorithm, Hash: h.Sum(nil)}
+	return append([]byte(sshsigMagic), ssh.Marshal(&signed)...), nil
+}
+
+// sshsigSign signs message under namespace and returns the armored
+// signature, the same as 'ssh-keygen -Y sign -n <namespace>' would print
+func sshsigSign(signer ssh.Signer, namespace string, message []byte) (string, error) {
+	data, err := sshsigData(namespace, "sha512", message)
+	if err != nil {
+		return "", err
+	}
+
+	var signature *ssh.Signature
+	if signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		// RSA signatures must use SHA-2; the default ssh-rsa is SHA-1
+		algorithmSigner, ok := signer.(ssh.AlgorithmSigner)
+		if !ok {
+			return "", fmt.Errorf("the RSA key cannot make SHA-2 signatures")
+		}
+		signature, err = algorithmSigner.SignWithAlgorithm(rand.Reader, data, ssh.KeyAlgoRSASHA512)
+	} else {
+		signature, err = signer.Sign(rand.Reader, data)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	blob := sshsigBlob{
+		Version:       sshsigVersion,
+		PublicKey:     signer.PublicKey().Marshal(),
+		Namespace:     namespace,
+		HashAlgorithm: "sha512",
+		Signature:     ssh.Marshal(signature),
+	}
+	raw := append([]byte(sshsigMagic), ssh.Marshal(&blob)...)
+
+	// Wrapped at 70 columns like ssh-keygen
+	var out bytes.Buffer
+	out.WriteString("-----BEGIN " + sshsigArmor + "-----\n")
+	encoded := base64.StdEncoding.EncodeToString(raw)
+	for len(encoded) > 70 {
+		out.WriteString(encoded[:70] + "\n")
+		encoded = encoded[70:]
+	}
+	out.WriteString(encoded + "\n")
+	out.WriteString("-----END " + sshsigArmor + "-----\n")
+	return out.String(), nil
+}
+
+// sshsigVerify checks an armored signature over message under namespace and
+// returns the key that made it. The caller decides whether it trusts the key.
+func sshsigVerify(armored string, namespace string, message []byte) (ssh.PublicKey, error) {
+	block, _ := pem.Decode([]byte(armored))
+	if block == nil || block.Type != sshsigArmor {
+		return nil, fmt.Errorf("not an SSH signature")
+	}
+	if !bytes.HasPrefix(block.Bytes, []byte(sshsigMagic)) {
+		return nil, fmt.Errorf("not an SSH signature")
+	}
+	var blob sshsigBlob
+	if err := ssh.Unmarshal(block.Bytes[len(sshsigMagic):], &blob); err != nil {
+		return nil, fmt.Errorf("malformed SSH signature: %w", err)
+	}
+	if blob.Version != sshsigVersion {
+		return nil, fmt.Errorf("unsupported SSH signature version %d", blob.Version)
+	}
+	if blob.Namespace != namespace {
+		return nil, fmt.Errorf("the signature is for '%s', not '%s'", blob.Namespace, namespace)
+	}
+
+	public, err := ssh.ParsePublicKey(blob.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("malformed key in the SSH signature: %w", err)
+	}
+	var signature ssh.Signature
+	if err := ssh.Unmarshal(blob.Signature, &signature); err != nil {
+		return nil, fmt.Errorf("malformed SSH signature: %w", err)
+	}
+	if signature.Format == ssh.KeyAlgoRSA {
+		return nil, fmt.Errorf("SHA-1 RSA signatures are not accepted")
+	}
+	data, err := sshsigData(blob.Namespace, blob.HashAlgorithm, message)
+	if err != nil {
+		return nil, err
+	}
+	if err := public.Verify(data, &signature); err != nil {
+		return nil, fmt.Errorf("the signature does not match")
+	}
+	return public, nil
+}
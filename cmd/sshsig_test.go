@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// testSigners returns an Ed25519 and an RSA private key to sign with
+func testSigners(t *testing.T) map[string]interface{} {
+	t.Helper()
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]interface{}{"ed25519": edKey, "rsa": rsaKey}
+}
+
+func TestSSHSigRoundTrip(t *testing.T) {
+	message := []byte(`{"name":"work"}`)
+	for name, key := range testSigners(t) {
+		t.Run(name, func(t *testing.T) {
+			signer, err := ssh.NewSignerFromKey(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			armored, err := sshsigSign(signer, cardSignatureNamespace, message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			public, err := sshsigVerify(armored, cardSignatureNamespace, message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ssh.FingerprintSHA256(public) != ssh.FingerprintSHA256(signer.PublicKey()) {
+				t.Errorf("verified key %s, want %s", ssh.FingerprintSHA256(public), ssh.FingerprintSHA256(signer.PublicKey()))
+			}
+			if _, err := sshsigVerify(armored, "git", message); err == nil {
+				t.Error("the signature verified under another namespace")
+			}
+			if _, err := sshsigVerify(armored, cardSignatureNamespace, []byte(`{"name":"home"}`)); err == nil {
+				t.Error("the signature verified for another message")
+			}
+		})
+	}
+}
+
+// TestSSHSigMatchesSSHKeygen checks the format against OpenSSH both ways
+func TestSSHSigMatchesSSHKeygen(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+	message := []byte(`{"name":"work"}`)
+	for name, key := range testSigners(t) {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			signer, err := ssh.NewSignerFromKey(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			armored, err := sshsigSign(signer, cardSignatureNamespace, message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sigPath := filepath.Join(dir, "card.sig")
+			if err := os.WriteFile(sigPath, []byte(armored), 0600); err != nil {
+				t.Fatal(err)
+			}
+			check := exec.Command("ssh-keygen", "-Y", "check-novalidate", "-n", cardSignatureNamespace, "-s", sigPath)
+			check.Stdin = strings.NewReader(string(message))
+			if output, err := check.CombinedOutput(); err != nil {
+				t.Errorf("ssh-keygen rejects the signature: %v\n%s", err, output)
+			}
+
+			block, err := ssh.MarshalPrivateKey(key, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			keyPath := filepath.Join(dir, "id")
+			if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600); err != nil {
+				t.Fatal(err)
+			}
+			sign := exec.Command("ssh-keygen", "-q", "-Y", "sign", "-n", cardSignatureNamespace, "-f", keyPath)
+			sign.Stdin = strings.NewReader(string(message))
+			output, err := sign.Output()
+			if err != nil {
+				t.Fatalf("ssh-keygen -Y sign: %v", err)
+			}
+			if _, err := sshsigVerify(string(output), cardSignatureNamespace, message); err != nil {
+				t.Errorf("ssh-keygen's signature does not verify: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,525 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// authBannerPatterns extract the authenticated username from the message a
+// provider prints after a successful 'ssh -T', keyed by Provider.Name. They
+// are the last resort: servers can customize or translate the message, so a
+// provider's own banner_pattern and the API come first.
+var authBannerPatterns = map[string]*regexp.Regexp{
+	"github":  regexp.MustCompile(`Hi ([^!\s]+)! You've successfully authenticated`),
+	"gitlab":  regexp.MustCompile(`Welcome to GitLab, @([^!\s]+)!`),
+	"gitea":   regexp.MustCompile(`Hi there, ([^!\s]+)! You've successfully authenticated`),
+	"sandbox": regexp.MustCompile(`Hi ([^!\s]+)! You've authenticated to the krakn sandbox`),
+}
+
+// sshProbeResult is the outcome of an identity probe for one account
+type sshProbeResult struct {
+	Address  string // host:port that was contacted
+	Username string // Username reported by the provider, empty if not recognised
+	Source   string // How the username was found: "banner pattern", "API" or "banner"
+	Banner   string // Raw server message
+	Duration time.Duration
+	Notes    []string // Things changed along the way, e.g. trusted host keys
+}
+
+// parseAuthBanner returns the username announced by the provider's banner.
+// The built-in pattern of the provider is tried first, then every known one.
+func parseAuthBanner(providerName, banner string) string {
+	if pattern, ok := authBannerPatterns[providerName]; ok {
+		if user := matchBannerUser(pattern, banner); user != "" {
+			return user
+		}
+	}
+	for _, pattern := range authBannerPatterns {
+		if user := matchBannerUser(pattern, banner); user != "" {
+			return user
+		}
+	}
+	return ""
+}
+
+// matchBannerUser returns the group named "user" of pattern in banner, or
+// else its first group
+func matchBannerUser(pattern *regexp.Regexp, banner string) string {
+	match := pattern.FindStringSubmatch(banner)
+	if match == nil || len(match) < 2 {
+		return ""
+	}
+	if index := pattern.SubexpIndex("user"); index > 0 {
+		return match[index]
+	}
+	return match[1]
+}
+
+// setBannerPattern saves the banner pattern of every account on hostname
+func (c *Config) setBannerPattern(hostname, pattern string) error {
+	return c.update(func(config *Config) error {
+		for i := range config.Accounts {
+			provider := config.Accounts[i].GetProvider()
+			if !strings.EqualFold(provider.Hostname, hostname) {
+				continue
+			}
+			provider.BannerPattern = pattern
+			config.Accounts[i].Provider = &provider
+			if provider == DefaultProviders["github"] {
+				config.Accounts[i].Provider = nil
+			}
+		}
+		return nil
+	})
+}
+
+// compileBannerPattern checks a banner_pattern: it must compile and capture
+// the username in a group
+func compileBannerPattern(value string) (*regexp.Regexp, error) {
+	pattern, err := regexp.Compile(value)
+	if err != nil {
+		return nil, fmt.Errorf("❌ Invalid banner pattern: %w", err)
+	}
+	if pattern.NumSubexp() == 0 {
+		return nil, fmt.Errorf("❌ The banner pattern needs a group capturing the username, e.g. 'Welcome, @(\\S+)'")
+	}
+	return pattern, nil
+}
+
+// apiKeyOwner asks the provider's API, with the account's token, who the
+// token belongs to and whether the account's public key is one of that user's
+// keys. It needs no banner at all.
+func apiKeyOwner(config *Config, account *Account) (login string, listed bool, err error) {
+	public, err := os.ReadFile(account.SSHKey + ".pub")
+	if err != nil {
+		return "", false, err
+	}
+	want := strings.Fields(string(public))
+	if len(want) < 2 {
+		return "", false, fmt.Errorf("%s.pub is not a public key", contractHomePath(account.SSHKey))
+	}
+
+	profile, err := fetchAccountProfile(config, account)
+	if err != nil {
+		return "", false, err
+	}
+	var keys []struct {
+		Key string `json:"key"`
+	}
+	if account.GetProvider().Name == "github" {
+		client, err := newGitHubClient(config, account)
+		if err != nil {
+			return "", false, err
+		}
+		err = client.getAll("/user/keys?per_page=100", &keys)
+	} else {
+		err = forgeRequest(account, http.MethodGet, "/user/keys?per_page=100&limit=50", nil, &keys)
+	}
+	if err != nil {
+		return "", false, err
+	}
+	for _, key := range keys {
+		if fields := strings.Fields(key.Key); len(fields) >= 2 && fields[0] == want[0] && fields[1] == want[1] {
+			return profile.Login, true, nil
+		}
+	}
+	return profile.Login, false, nil
+}
+
+// bannerUser returns the username in a provider's banner and how it was
+// found: the provider's own banner_pattern, or else the built-in patterns
+func bannerUser(provider Provider, banner string) (user, source string) {
+	if provider.BannerPattern != "" {
+		if pattern, err := regexp.Compile(provider.BannerPattern); err == nil {
+			if user := matchBannerUser(pattern, banner); user != "" {
+				return user, "banner pattern"
+			}
+		}
+	}
+	if user := parseAuthBanner(provider.Name, banner); user != "" {
+		return user, "banner"
+	}
+	return "", ""
+}
+
+// confirmKeyOwner asks the API who owns the key after a successful probe,
+// when the account has a token that can be read without a prompt. A
+// configured banner_pattern is trusted as it is.
+func confirmKeyOwner(config *Config, account *Account, result *sshProbeResult) {
+	if result.Source == "banner pattern" || offlineMode || (account.Token == "" && account.TokenStore != tokenStoreKeychain) {
+		return
+	}
+	login, listed, err := apiKeyOwner(config, account)
+	switch {
+	case err != nil:
+		tracef(traceMatch, "The %s API could not verify the key: %v", account.GetProvider().DisplayName, err)
+	case listed:
+		result.Username, result.Source = login, "API"
+	default:
+		result.Notes = append(result.Notes, fmt.Sprintf("The key is not among the keys of @%s, the owner of the token", login))
+	}
+}
+
+// sshEndpoint returns where an account's SSH traffic actually goes. Linked
+// aliases are resolved through their own ~/.ssh/config block.
+func sshEndpoint(account *Account) (user, host, port string) {
+	provider := account.GetProvider()
+	user, host, port = provider.SSHUser, provider.Hostname, provider.SSHPort
+
+	if account.SSHHost != "" {
+		blocks, _ := readSSHConfig()
+		for i := range blocks {
+			if blocks[i].alias() != account.SSHHost {
+				continue
+			}
+			host = blocks[i].hostName()
+			if value := blocks[i].get("User"); value != "" {
+				user = value
+			}
+			if value := blocks[i].get("Port"); value != "" {
+				port = value
+			}
+			break
+		}
+	}
+
+	if port == "" {
+		port = "22"
+	}
+	return user, host, port
+}
+
+// loadSSHSigner reads a private key, asking for its passphrase when needed
+func loadSSHSigner(keyPath string) (ssh.Signer, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH key: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if passphrase, _, ok := storedKeyPassphrase(keyPath); ok {
+			if signer, err := ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase)); err == nil {
+				return signer, nil
+			}
+		}
+		passphrase, err := readSecret(fmt.Sprintf("🔐 Passphrase for %s: ", contractHomePath(keyPath)))
+		if err != nil {
+			return nil, err
+		}
+		return ssh.ParsePrivateKeyWithPassphrase(data, []byte(passphrase))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH key: %w", err)
+	}
+	return signer, nil
+}
+
+// knownHostsCallback verifies host keys against ~/.ssh/known_hosts. Unknown
+// hosts are rejected unless acceptNew is set, in which case they are recorded,
+// matching OpenSSH's StrictHostKeyChecking=accept-new, and reported to onAdd.
+func knownHostsCallback(acceptNew bool, onAdd func(note string)) (ssh.HostKeyCallback, error) {
+	homeDir, _ := os.UserHomeDir()
+	knownHostsPath := filepath.Join(homeDir, ".ssh", "known_hosts")
+
+	if !fileExists(knownHostsPath) {
+		if err := ensureSSHDirectory(); err != nil {
+			return nil, err
+		}
+		if err := writePrivateFile(knownHostsPath, nil); err != nil {
+			return nil, fmt.Errorf("failed to create known_hosts: %w", err)
+		}
+	}
+
+	check, err := knownhosts.New(knownHostsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known_hosts: %w", err)
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := check(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) {
+			return err
+		}
+
+		if len(keyErr.Want) > 0 {
+			return fmt.Errorf("HOST KEY MISMATCH for %s (got %s); the server key changed or the connection is intercepted", hostname, ssh.FingerprintSHA256(key))
+		}
+		if !acceptNew {
+			return fmt.Errorf("host %s is not in known_hosts (key %s); rerun with --accept-new to trust it", hostname, ssh.FingerprintSHA256(key))
+		}
+
+		f, err := os.OpenFile(knownHostsPath, os.O_APPEND|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		trackFile(knownHostsPath)
+		if _, err := fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)); err != nil {
+			return err
+		}
+		onAdd(fmt.Sprintf("Added %s (%s) to known_hosts", hostname, ssh.FingerprintSHA256(key)))
+		return nil
+	}, nil
+}
+
+// errKeyRejected is returned by nativeSSHProbe when the server refuses the key,
+// as opposed to failing to reach the server at all
+var errKeyRejected = errors.New("the server rejected key")
+
+// nativeSSHProbe authenticates with only the account's key using the Go SSH
+// client, so neither an OpenSSH install nor ~/.ssh/config can interfere
+func nativeSSHProbe(account *Account, acceptNew bool) (*sshProbeResult, error) {
+	keyPath, loadSigner := account.SSHKey, loadSSHSigner
+	if loaded, _ := agentHasKey(account.SSHKey + ".pub"); account.AgentKey || loaded && keyIsEncrypted(account.SSHKey) {
+		// The agent signs without asking for the passphrase again
+		keyPath, loadSigner = account.SSHKey+".pub", agentSigner
+	}
+	signer, err := loadSigner(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	user, host, port := sshEndpoint(account)
+	result := &sshProbeResult{Address: net.JoinHostPort(host, port)}
+
+	hostKeyCallback, err := knownHostsCallback(acceptNew, func(note string) {
+		result.Notes = append(result.Notes, note)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	spin := startSpinner("Connecting to " + result.Address)
+	defer spin.Stop()
+
+	start := time.Now()
+	client, err := ssh.Dial("tcp", result.Address, &ssh.ClientConfig{
+		User:            user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         10 * time.Second,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "unable to authenticate") {
+			return result, fmt.Errorf("%w %s", errKeyRejected, contractHomePath(account.SSHKey))
+		}
+		return result, err
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return result, fmt.Errorf("failed to open session: %w", err)
+	}
+	defer session.Close()
+
+	// Providers print their greeting in response to a shell request and exit
+	var output bytes.Buffer
+	session.Stdout = &output
+	session.Stderr = &output
+	timer := time.AfterFunc(10*time.Second, func() { client.Close() })
+	defer timer.Stop()
+	if err := session.Shell(); err == nil {
+		session.Wait()
+	}
+
+	result.Duration = time.Since(start)
+	result.Banner = strings.TrimSpace(output.String())
+	result.Username, result.Source = bannerUser(account.GetProvider(), result.Banner)
+	return result, nil
+}
+
+// opensshProbe runs 'ssh -T' through the account's host alias
+func opensshProbe(account *Account) (*sshProbeResult, error) {
+	user, _, _ := sshEndpoint(account)
+	result := &sshProbeResult{Address: account.GetSSHHost()}
+
+	spin := startSpinner("Connecting to " + result.Address)
+	start := time.Now()
+	output, err := traceExec(exec.Command("ssh", "-T", "-o", "BatchMode=yes", "-o", "ConnectTimeout=10",
+		"-o", "IdentitiesOnly=yes", "-i", account.identityFile(), user+"@"+account.GetSSHHost())).CombinedOutput()
+	result.Duration = time.Since(start)
+	spin.Stop()
+	result.Banner = strings.TrimSpace(string(output))
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 255 {
+		return result, fmt.Errorf("%s", firstLine(result.Banner))
+	} else if err != nil && exitErr == nil {
+		return result, err
+	}
+
+	result.Username, result.Source = bannerUser(account.GetProvider(), result.Banner)
+	return result, nil
+}
+
+var testCmd = &cobra.Command{
+	Use:     "test [account-name...]",
+	Aliases: []string{"whoami"},
+	Short:   "Verify which provider user each account's SSH key authenticates as",
+	Long: `Connect to each account's provider offering only the account's key and report
+the username the provider greets you with.
+
+The built-in SSH client is used by default, so the check works without OpenSSH
+installed and is not affected by ~/.ssh/config or a running ssh-agent.
+
+Whether the key is accepted comes from the SSH handshake (or the exit status
+of ssh), never from the greeting. The username comes from, in order: the
+provider's banner pattern, the provider's API when the account has a token
+(the key must be one of the token owner's keys), and the greetings of GitHub,
+GitLab and Gitea. Servers with a customized or translated greeting get a
+pattern of their own with --banner-pattern, a regular expression whose first
+group (or group named "user") captures the username. It is tried against the
+greeting, saved for every account on the same host when it matches, and
+removed with --banner-pattern "".
+
+Examples:
+  krakn test                  # Test all accounts
+  krakn test work --accept-new  # Trust a host missing from known_hosts
+  krakn test work --openssh   # Use the ssh binary and the account's host alias
+  krakn test work --banner-pattern 'Bienvenue sur GitLab, @(\S+)'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		useOpenSSH, _ := cmd.Flags().GetBool("openssh")
+		acceptNew, _ := cmd.Flags().GetBool("accept-new")
+		bannerPattern, _ := cmd.Flags().GetString("banner-pattern")
+		setPattern := cmd.Flags().Changed("banner-pattern")
+		if setPattern && len(args) != 1 {
+			return fmt.Errorf("❌ --banner-pattern needs exactly one account")
+		}
+		if setPattern && bannerPattern != "" {
+			if _, err := compileBannerPattern(bannerPattern); err != nil {
+				return err
+			}
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		var accounts []*Account
+		if len(args) == 0 {
+			for i := range config.Accounts {
+				accounts = append(accounts, &config.Accounts[i])
+			}
+		} else {
+			for _, name := range args {
+				account := config.getAccount(name)
+				if account == nil {
+					return config.accountNotFound(name)
+				}
+				accounts = append(accounts, account)
+			}
+		}
+		if len(accounts) == 0 {
+			fmt.Println("📭 No accounts configured. Use 'krakn add' to add an account.")
+			return nil
+		}
+
+		failures := 0
+		results := map[string]authResult{}
+		for _, account := range accounts {
+			fmt.Printf("🔌 %s (%s)\n", account.Name, account.GetProvider().DisplayName)
+			if account.usesHTTPS() {
+				fmt.Println("   ℹ️  HTTPS only; SSH is not used")
+				continue
+			}
+			if account.SSHKey == "" {
+				fmt.Println("   ⚠️  No SSH key configured")
+				failures++
+				continue
+			}
+
+			offerAgentAdd(account)
+
+			if setPattern {
+				provider := account.GetProvider()
+				provider.BannerPattern = bannerPattern
+				account.Provider = &provider
+			}
+			var result *sshProbeResult
+			if useOpenSSH {
+				result, err = opensshProbe(account)
+			} else {
+				result, err = nativeSSHProbe(account, acceptNew)
+			}
+			if err == nil {
+				confirmKeyOwner(config, account, result)
+			}
+			if result != nil {
+				for _, note := range result.Notes {
+					fmt.Printf("   📌 %s\n", note)
+				}
+			}
+			if err != nil {
+				fmt.Printf("   ❌ %v\n", err)
+				results[account.Name] = authResult{Error: err.Error(), Time: time.Now()}
+				failures++
+				continue
+			}
+
+			if setPattern && bannerPattern != "" && result.Source != "banner pattern" {
+				fmt.Printf("   ❌ The banner pattern does not match the greeting: %s\n", firstLine(result.Banner))
+				return fmt.Errorf("❌ Banner pattern not saved")
+			}
+			if setPattern {
+				if err := config.setBannerPattern(account.GetProvider().Hostname, bannerPattern); err != nil {
+					return errSaveConfig(err)
+				}
+				if bannerPattern == "" {
+					fmt.Printf("   💾 Removed the banner pattern of %s\n", account.GetProvider().Hostname)
+				} else {
+					fmt.Printf("   💾 Saved the banner pattern for accounts on %s\n", account.GetProvider().Hostname)
+				}
+			}
+
+			results[account.Name] = authResult{OK: true, Username: result.Username, Time: time.Now()}
+			switch {
+			case result.Username == "":
+				fmt.Printf("   ✅ Authenticated at %s in %dms\n", result.Address, result.Duration.Milliseconds())
+				if result.Banner != "" {
+					fmt.Printf("   💬 %s\n", firstLine(result.Banner))
+				}
+				fmt.Printf("   💡 To verify the username, add a token ('krakn token set %s') or a pattern for this greeting (--banner-pattern)\n", account.Name)
+			case account.Username != "" && !strings.EqualFold(result.Username, account.Username):
+				fmt.Printf("   ⚠️  Authenticated as '%s', but the account's username is '%s'\n", result.Username, account.Username)
+				results[account.Name] = authResult{Username: result.Username, Error: "authenticated as " + result.Username, Time: time.Now()}
+				failures++
+			case result.Source == "API":
+				fmt.Printf("   ✅ Authenticated as '%s' in %dms (key confirmed through the %s API)\n", result.Username, result.Duration.Milliseconds(), account.GetProvider().DisplayName)
+			default:
+				fmt.Printf("   ✅ Authenticated as '%s' in %dms\n", result.Username, result.Duration.Milliseconds())
+			}
+		}
+
+		if err := saveAuthResults(results); err != nil {
+			fmt.Printf("⚠️  Could not cache the results: %v\n", err)
+		}
+		if failures > 0 {
+			return fmt.Errorf("❌ %d account(s) failed verification", failures)
+		}
+		return nil
+	},
+}
+
+func init() {
+	testCmd.Flags().Bool("openssh", false, "Use the ssh binary instead of the built-in client")
+	testCmd.Flags().Bool("accept-new", false, "Add unknown host keys to ~/.ssh/known_hosts")
+	testCmd.Flags().String("banner-pattern", "", "Regular expression capturing the username in the provider's greeting; saved for the host when it matches")
+	RootCmd.AddCommand(testCmd)
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// stateOutput is printed by 'krakn state --json'
+type stateOutput struct {
+	SchemaVersion int    `json:"schema_version"`
+	Account       string `json:"account,omitempty" desc:"Account whose email is the effective user.email; the current account outside repositories"`
+	Mapped        string `json:"mapped,omitempty" desc:"Account of the repository override or directory mapping"`
+	Current       string `json:"current,omitempty" desc:"The global current account"`
+	RemoteAccount string `json:"remote_account,omitempty" desc:"Account whose host alias origin uses"`
+	Mismatch      bool   `json:"mismatch" desc:"The account differs from the mapped or remote account"`
+	Repository    string `json:"repository,omitempty"`
+}
+
+// findWorkTree returns the working tree containing dir by looking for .git,
+// without running git
+func findWorkTree(dir string) (string, bool) {
+	for {
+		if _, _, err := findGitDir(dir); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// currentState resolves the account state of dir from the files alone. It is
+// polled by statuslines every few seconds, so git is only run for
+// configurations the native reader does not support.
+func currentState(config *Config, dir string) stateOutput {
+	state := stateOutput{SchemaVersion: schemaVersion("state"), Current: config.CurrentAccount}
+	if account := config.findAccountForPath(dir); account != nil {
+		state.Mapped = account.Name
+	}
+
+	root, ok := findWorkTree(dir)
+	if !ok {
+		state.Account = config.CurrentAccount
+		state.Mismatch = state.Mapped != "" && state.Mapped != state.Account
+		return state
+	}
+	state.Repository = root
+
+	var email, remote string
+	var remotes []string
+	if resolved, err := resolveRepoGitConfig(root); err == nil {
+		email, _ = resolved.get("user.email")
+		remote, _ = resolved.get("remote.origin.url")
+		for _, entry := range resolved.Entries {
+			if strings.HasPrefix(entry.Key, "remote.") && strings.HasSuffix(entry.Key, ".url") {
+				remotes = append(remotes, entry.Value)
+			}
+		}
+	} else {
+		email = getRepoGitConfig(root, "user.email")
+		remote = getRepoRemoteURL(root, "origin")
+		remotes = []string{remote}
+	}
+
+	for i := range config.Accounts {
+		if email != "" && config.Accounts[i].Email == email {
+			state.Account = config.Accounts[i].Name
+			break
+		}
+	}
+	for _, url := range remotes {
+		if repo, err := config.normalizeRepoSpec(url); err == nil {
+			if override := config.getOverride(repo); override != nil {
+				state.Mapped = override.Account
+				break
+			}
+		}
+	}
+	if parsed, err := parseRemoteURL(remote); err == nil {
+		if account := config.findAccountByHost(parsed.Host); account != nil {
+			state.RemoteAccount = account.Name
+		}
+	}
+
+	state.Mismatch = (state.Mapped != "" && state.Mapped != state.Account) ||
+		(state.RemoteAccount != "" && state.RemoteAccount != state.Account)
+	return state
+}
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Print the account state of the current directory for statuslines",
+	Long: `Print the account in effect, the mapped account and whether they disagree,
+in one cheap call meant for statusline plugins (Neovim, zsh, tmux) that poll
+every few seconds. Only files are read; git is not run for usual
+configurations.
+
+The account is the one whose email is the effective user.email (the current
+account outside repositories). mapped is the account of the repository
+override or directory mapping, remote_account the account whose host alias
+origin uses. mismatch is true when either differs from the account.
+
+Without --json a single word is printed: the account, followed by "!" on a
+mismatch, or nothing when no account applies.
+
+Examples:
+  krakn state --json   # {"schema_version":1,"account":"work","mapped":"work",...,"mismatch":false}
+  krakn state          # work
+
+  # zsh prompt
+  RPROMPT='$(krakn state 2>/dev/null)'
+
+  -- Neovim (lualine component)
+  function() return vim.fn.system("krakn state"):gsub("%s+$", "") end`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		if asJSON {
+			outputFormat = "json"
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+
+		state := currentState(config, dir)
+		if structuredOutput() {
+			return printStructured(state)
+		}
+		if state.Account == "" {
+			return nil
+		}
+		if state.Mismatch {
+			fmt.Println(state.Account + "!")
+		} else {
+			fmt.Println(state.Account)
+		}
+		return nil
+	},
+}
+
+func init() {
+	stateCmd.Flags().Bool("json", false, "Same as --output json")
+	RootCmd.AddCommand(stateCmd)
+	registerOutputSchema(outputSchema{Command: "state", Version: 1, Description: "Account, mapped account and mismatch of the working directory, for statuslines", Type: stateOutput{}})
+}
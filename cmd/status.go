@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// statusKeys are the settings that decide who commits and which key pushes
+var statusKeys = []string{"user.name", "user.email", "core.sshCommand"}
+
+// statusLayer is one assignment of an identity setting, in the order git
+// reads them; the last one of a key is effective
+type statusLayer struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Origin    string `json:"origin" desc:"File the value is set in"`
+	Source    string `json:"source" desc:"system, global, include, override or local"`
+	Effective bool   `json:"effective"`
+}
+
+// statusOutput is printed by 'krakn status --output json|yaml'
+type statusOutput struct {
+	SchemaVersion int           `json:"schema_version"`
+	Repository    string        `json:"repository,omitempty"`
+	Layers        []statusLayer `json:"layers"`
+	Account       string        `json:"account,omitempty" desc:"Account whose email is the effective user.email"`
+	Email         string        `json:"email,omitempty"`
+	Remote        string        `json:"remote,omitempty" desc:"URL of origin"`
+	HostAlias     string        `json:"host_alias,omitempty" desc:"SSH host the remote connects through"`
+	RemoteAccount string        `json:"remote_account,omitempty" desc:"Account whose host alias or core.sshCommand key authenticates pushes"`
+	Match         string        `json:"match" desc:"match, mismatch or unknown"`
+	Reason        string        `json:"reason,omitempty" desc:"Why the match is unknown or a mismatch"`
+}
+
+// layerSource classifies a config file for 'krakn status'
+func layerSource(config *Config, repoRoot, path string) string {
+	path = filepath.Clean(path)
+	for _, global := range globalGitConfigFiles() {
+		if path == filepath.Clean(global) {
+			return "global"
+		}
+	}
+	if system := systemGitConfigFile(); system != "" && path == filepath.Clean(system) {
+		return "system"
+	}
+	for _, override := range config.Overrides {
+		if path == filepath.Clean(override.ConfigFile) {
+			return "override"
+		}
+	}
+	if _, commonDir, err := findGitDir(repoRoot); err == nil && insideDir(path, commonDir) && strings.HasPrefix(filepath.Base(path), "config") {
+		return "local"
+	}
+	return "include"
+}
+
+// describeLayerOrigin names the file a setting comes from, with the directory
+// mapping that added it when it is one of krakncat's include files
+func describeLayerOrigin(config *Config, path string) string {
+	for _, mapping := range config.Directories {
+		if filepath.Clean(path) == filepath.Clean(mapping.ConfigFile) {
+			return fmt.Sprintf("%s (mapping of %s to '%s')", contractHomePath(path), contractHomePath(mapping.patternDir()), mapping.Account)
+		}
+	}
+	return contractHomePath(path)
+}
+
+// statusLayers returns every assignment of the identity settings in a
+// repository. When the native resolver cannot read the configuration, git
+// reports the origins instead.
+func statusLayers(config *Config, repoRoot string) []statusLayer {
+	var entries []gitConfigEntry
+	if resolved, err := resolveRepoGitConfig(repoRoot); err == nil {
+		for _, entry := range resolved.Entries {
+			for _, key := range statusKeys {
+				if entry.Key == normalizeGitConfigKey(key) {
+					entry.Key = key
+					entries = append(entries, entry)
+				}
+			}
+		}
+	} else {
+		for _, key := range statusKeys {
+			output, err := traceExec(exec.Command("git", "-C", repoRoot, "config", "--show-origin", "--get-all", key)).Output()
+			if err != nil {
+				continue
+			}
+			for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+				origin, value, _ := strings.Cut(line, "\t")
+				entries = append(entries, gitConfigEntry{Key: key, Value: value, Origin: strings.TrimPrefix(origin, "file:")})
+			}
+		}
+	}
+
+	layers := []statusLayer{}
+	last := map[string]int{}
+	for _, entry := range entries {
+		last[entry.Key] = len(layers)
+		layers = append(layers, statusLayer{
+			Key:    entry.Key,
+			Value:  entry.Value,
+			Origin: describeLayerOrigin(config, entry.Origin),
+			Source: layerSource(config, repoRoot, entry.Origin),
+		})
+	}
+	for _, i := range last {
+		layers[i].Effective = true
+	}
+	return layers
+}
+
+// repoStatus resolves who commits in a repository and who pushes to origin
+func repoStatus(config *Config, repoRoot string) statusOutput {
+	status := statusOutput{SchemaVersion: schemaVersion("status"), Repository: repoRoot, Layers: statusLayers(config, repoRoot), Match: "unknown"}
+	identity := inspectRepoIdentity(config, repoRoot, "origin")
+	status.Email = identity.Email
+	status.Remote = identity.RemoteURL
+	if identity.EmailAccount != nil {
+		status.Account = identity.EmailAccount.Name
+	}
+
+	pushAccount := identity.HostAccount
+	if identity.Remote != nil && identity.Remote.isSSH() {
+		status.HostAlias = identity.Remote.Host
+	}
+	if identity.SSHCommand != "" {
+		// core.sshCommand picks the key whatever host the remote names
+		pushAccount = identity.KeyAccount
+	}
+	if pushAccount != nil {
+		status.RemoteAccount = pushAccount.Name
+	}
+
+	switch {
+	case identity.EmailAccount == nil && identity.Email == "":
+		status.Reason = "user.email is not set"
+	case identity.EmailAccount == nil:
+		status.Reason = fmt.Sprintf("user.email %s belongs to no account", identity.Email)
+	case identity.RemoteURL == "":
+		status.Reason = "the repository has no origin remote"
+	case identity.Remote != nil && !identity.Remote.isSSH():
+		status.Reason = "origin is not an SSH remote; the credential helper decides who pushes"
+	case pushAccount == nil && identity.SSHCommand != "":
+		status.Reason = "core.sshCommand selects a key that belongs to no account"
+	case pushAccount == nil:
+		status.Reason = fmt.Sprintf("origin uses %s, not an account's host alias, so ssh offers its default keys", status.HostAlias)
+	case pushAccount.Name == identity.EmailAccount.Name:
+		status.Match = "match"
+	default:
+		status.Match = "mismatch"
+		status.Reason = fmt.Sprintf("commits are made as '%s' but pushes authenticate as '%s'", identity.EmailAccount.Name, pushAccount.Name)
+	}
+	return status
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status [path]",
+	Short: "Show which account a repository commits and pushes as",
+	Long: `Show the identity in effect for the repository in the current directory
+(or path): every place user.name, user.email and core.sshCommand are set, in
+the order git reads them (system, global, conditional includes, local), the
+account the effective user.email belongs to, the SSH host alias origin
+connects through, and whether committing and pushing use the same account.
+
+Outside a repository the global identity and current account are shown.
+'krakn doctor' explains problems in more depth.
+
+Examples:
+  krakn status
+  krakn status ~/work/api
+  krakn status --output json   # For prompts and scripts ('krakn schema status')`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		repoRoot, err := findRepoRoot(path)
+		if err != nil {
+			if len(args) == 1 {
+				return err
+			}
+			if structuredOutput() {
+				return printStructured(statusOutput{SchemaVersion: schemaVersion("status"), Layers: []statusLayer{}, Account: config.CurrentAccount, Email: getGitConfig("user.email", true), Match: "unknown", Reason: "not inside a git repository"})
+			}
+			fmt.Println("ℹ️  Not inside a git repository")
+			if config.CurrentAccount != "" {
+				fmt.Printf("👤 Current account: %s\n", config.CurrentAccount)
+			}
+			if email := getGitConfig("user.email", true); email != "" {
+				fmt.Printf("📧 Global email: %s\n", email)
+			}
+			return nil
+		}
+
+		status := repoStatus(config, repoRoot)
+		if structuredOutput() {
+			return printStructured(status)
+		}
+
+		fmt.Printf("📁 Repository: %s\n", contractHomePath(repoRoot))
+		fmt.Println("\n🧾 Settings, in the order git reads them:")
+		if len(status.Layers) == 0 {
+			fmt.Println("   ℹ️  user.name and user.email are not set anywhere")
+		}
+		for _, layer := range status.Layers {
+			marker := "  "
+			if layer.Effective {
+				marker = "→ "
+			}
+			fmt.Printf("   %s%-15s %-28s %s %s\n", marker, layer.Key, layer.Value, layer.Source, layer.Origin)
+		}
+
+		fmt.Println()
+		if status.Account != "" {
+			fmt.Printf("👤 Commits as: %s (%s)\n", status.Account, status.Email)
+		} else if status.Email != "" {
+			fmt.Printf("👤 Commits as: %s (no account)\n", status.Email)
+		}
+		if status.Remote != "" {
+			fmt.Printf("🌐 origin: %s\n", status.Remote)
+		}
+		if status.RemoteAccount != "" {
+			fmt.Printf("🔑 Pushes as: %s\n", status.RemoteAccount)
+		}
+
+		switch status.Match {
+		case "match":
+			fmt.Println("✅ Commits and pushes use the same account")
+		case "mismatch":
+			fmt.Printf("⚠️  Mismatch: %s\n", status.Reason)
+			fmt.Printf("💡 krakn fix-remote --account %s, or krakn use %s %s\n", status.Account, status.RemoteAccount, contractHomePath(repoRoot))
+		default:
+			fmt.Printf("ℹ️  Cannot tell whether commits and pushes match: %s\n", status.Reason)
+		}
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(statusCmd)
+	registerOutputSchema(outputSchema{Command: "status", Version: 1, Description: "Who a repository commits and pushes as, and where each setting comes from", Type: statusOutput{}})
+}
@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// bundleFile is one file of a support bundle
+type bundleFile struct {
+	Name    string
+	Content string
+}
+
+// emailPattern finds email addresses in collected text
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+
+// hashEmail replaces an address with a short stable hash, so the same email
+// can still be recognized across files of a bundle
+func hashEmail(email string) string {
+	return "email-" + hashBytes([]byte(strings.ToLower(email)))[:12]
+}
+
+// sanitizeText hashes email addresses and replaces the home directory with ~.
+// The git@ user of SSH remotes is not an address and is kept.
+func sanitizeText(text string) string {
+	text = emailPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if strings.HasPrefix(match, "git@") {
+			return match
+		}
+		return hashEmail(match)
+	})
+	if homeDir, err := os.UserHomeDir(); err == nil && homeDir != "" && homeDir != "/" {
+		text = strings.ReplaceAll(text, homeDir, "~")
+	}
+	return text
+}
+
+// sanitizedConfig renders the config without tokens, sealed values and the
+// webhook's secrets
+func sanitizedConfig(config *Config) string {
+	copied := *config
+	if config.Webhook != nil {
+		webhook := *config.Webhook
+		webhook.URL = redactWebhookURL(webhook.URL)
+		if webhook.Template != "" {
+			webhook.Template = "<redacted>"
+		}
+		copied.Webhook = &webhook
+	}
+	copied.Accounts = nil
+	for _, account := range config.Accounts {
+		if account.Token != "" {
+			account.Token = "<redacted>"
+		}
+		if len(account.Sealed) > 0 {
+			sealed := map[string]string{}
+			for field := range account.Sealed {
+				sealed[field] = "<sealed>"
+			}
+			account.Sealed = sealed
+		}
+		copied.Accounts = append(copied.Accounts, account)
+	}
+	data, _ := json.MarshalIndent(copied, "", "  ")
+	return string(data) + "\n"
+}
+
+// commandVersion returns the first line a tool prints for its version
+func commandVersion(name string, args ...string) string {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil && len(output) == 0 {
+		return "not available"
+	}
+	return firstLine(strings.TrimSpace(string(output)))
+}
+
+// collectSupportBundle gathers the bundle files, already sanitized
+func collectSupportBundle(config *Config) []bundleFile {
+	var versions strings.Builder
+	fmt.Fprintf(&versions, "krakn: %s", Version)
+	if Commit != "" {
+		fmt.Fprintf(&versions, " (%s)", Commit)
+	}
+	fmt.Fprintf(&versions, "\ngo: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&versions, "git: %s\n", commandVersion("git", "--version"))
+	fmt.Fprintf(&versions, "ssh: %s\n", commandVersion("ssh", "-V"))
+
+	// Only the Host blocks of krakn accounts; other hosts are none of our business
+	var sshConfig strings.Builder
+	if content, err := os.ReadFile(getSSHConfigPath()); err == nil {
+		lines := strings.Split(string(content), "\n")
+		blocks, _ := readSSHConfig()
+		for _, account := range config.Accounts {
+			for _, block := range blocks {
+				if block.alias() == account.GetSSHHost() {
+					sshConfig.WriteString(strings.Join(lines[block.StartLine:block.EndLine], "\n") + "\n")
+				}
+			}
+		}
+	}
+
+	var includes strings.Builder
+	if gitConfig, err := readGitConfigFile(globalGitConfigPath()); err == nil {
+		for _, section := range gitConfig.findSections("includeIf") {
+			includes.WriteString(strings.Join(section.Lines, "\n") + "\n")
+			path := expandUserPath(section.get("path"))
+			if content, err := os.ReadFile(path); err == nil {
+				fmt.Fprintf(&includes, "# --- %s\n%s\n", path, strings.TrimSpace(string(content)))
+			}
+			includes.WriteString("\n")
+		}
+	}
+
+	var doctor bytes.Buffer
+	ctx := &doctorContext{Config: config}
+	if root, err := findRepoRoot("."); err == nil {
+		ctx.RepoRoot = root
+	}
+	problems := printDoctorReport(&doctor, ctx)
+	fmt.Fprintf(&doctor, "%d problem(s) found\n", problems)
+
+	files := []bundleFile{
+		{Name: "versions.txt", Content: versions.String()},
+		{Name: "config.json", Content: sanitizedConfig(config)},
+		{Name: "ssh-config.txt", Content: sshConfig.String()},
+		{Name: "gitconfig-includes.txt", Content: includes.String()},
+		{Name: "doctor.txt", Content: doctor.String()},
+	}
+	var collected []bundleFile
+	for _, file := range files {
+		if file.Content != "" {
+			file.Content = sanitizeText(file.Content)
+			collected = append(collected, file)
+		}
+	}
+	return collected
+}
+
+// reviewBundleFiles lets the user view, redact or drop each file
+func reviewBundleFiles(files []bundleFile) []bundleFile {
+	reader := bufio.NewReader(os.Stdin)
+	var kept []bundleFile
+	for _, file := range files {
+		for {
+			fmt.Printf("\n📄 %s (%d lines)\n", file.Name, strings.Count(file.Content, "\n"))
+			fmt.Print("💬 [Y]es include, [n]o skip, [v]iew, [r]edact text: ")
+			input, _ := reader.ReadString('\n')
+			switch strings.ToLower(strings.TrimSpace(input)) {
+			case "", "y", "yes":
+				kept = append(kept, file)
+			case "n", "no":
+				fmt.Printf("⏭️  Skipping %s\n", file.Name)
+			case "v", "view":
+				fmt.Println(strings.TrimRight(file.Content, "\n"))
+				continue
+			case "r", "redact":
+				fmt.Print("💬 Text to redact: ")
+				text, _ := reader.ReadString('\n')
+				if text = strings.TrimSpace(text); text != "" {
+					count := strings.Count(file.Content, text)
+					file.Content = strings.ReplaceAll(file.Content, text, "<redacted>")
+					fmt.Printf("✂️  Redacted %d occurrence(s)\n", count)
+				}
+				continue
+			default:
+				continue
+			}
+			break
+		}
+	}
+	return kept
+}
+
+// writeSupportBundle writes the files as a gzipped tarball only the user can read
+func writeSupportBundle(path string, files []bundleFile) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, file := range files {
+		header := &tar.Header{Name: "krakn-support/" + file.Name, Mode: 0600, Size: int64(len(file.Content)), ModTime: now}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write([]byte(file.Content)); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+var supportBundleCmd = &cobra.Command{
+	Use:   "support-bundle",
+	Short: "Collect a sanitized diagnostic bundle to attach to bug reports",
+	Long: `Collect what maintainers usually ask for in a bug report into a .tar.gz:
+
+  versions.txt             krakn, Go, git and ssh versions
+  config.json              Your krakn config; tokens and sealed values removed
+  ssh-config.txt           The ~/.ssh/config Host blocks of your accounts
+  gitconfig-includes.txt   includeIf entries of ~/.gitconfig and the files they load
+  doctor.txt               The output of 'krakn doctor'
+
+Email addresses are replaced by a short hash (the same address always gets the
+same hash) and your home directory by ~. Before anything is written you review
+each file: view it, redact more text such as an org or host name, or leave it
+out. Nothing is uploaded; attach the file yourself.
+
+Examples:
+  krakn support-bundle
+  krakn support-bundle --output /tmp/krakn.tar.gz
+  krakn support-bundle --yes   # Skip the review, e.g. in a script`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		yes, _ := cmd.Flags().GetBool("yes")
+		if output == "" {
+			output = "krakn-support-" + time.Now().Format("20060102-150405") + ".tar.gz"
+		}
+		if !yes && !term.IsTerminal(int(os.Stdin.Fd())) {
+			return fmt.Errorf("❌ The bundle must be reviewed interactively; pass --yes to write it without review")
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		spin := startSpinner("Collecting diagnostics")
+		files := collectSupportBundle(config)
+		spin.Stop()
+
+		if !yes {
+			fmt.Println("🔍 Review each file before it goes into the bundle")
+			files = reviewBundleFiles(files)
+			if len(files) == 0 {
+				fmt.Println("📭 Nothing selected; no bundle written")
+				return nil
+			}
+		}
+
+		if err := writeSupportBundle(output, files); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output, err)
+		}
+		fmt.Printf("📦 Wrote %s (%d files)\n", output, len(files))
+		fmt.Println("💡 Attach it to your issue at " + projectHomepage + "/issues")
+		return nil
+	},
+}
+
+func init() {
+	supportBundleCmd.Flags().StringP("output", "o", "", "Archive to write (default: krakn-support-<time>.tar.gz)")
+	supportBundleCmd.Flags().BoolP("yes", "y", false, "Write the bundle without the interactive review")
+	RootCmd.AddCommand(supportBundleCmd)
+}
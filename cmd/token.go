@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage provider API tokens for accounts",
+	Long: `Store or clear provider API tokens used by commands that talk to the
+provider's API.
+
+Examples:
+  krakn token set work
+  krakn token clear work`,
+}
+
+// storeToken sets an account's token: in the system keychain when keychain
+// is set or the account keeps it there, sealed again when the previous token
+// was sealed, and in plain text otherwise. The caller saves the account.
+func (c *Config) storeToken(account *Account, token string, keychain bool) error {
+	if keychain && account.isSealed("token") {
+		return fmt.Errorf("❌ The token of '%s' is sealed; run 'krakn token clear %s' before keeping it in the keychain", account.Name, account.Name)
+	}
+	if keychain || account.TokenStore == tokenStoreKeychain {
+		store := systemSecretStore()
+		if store == nil {
+			return fmt.Errorf("❌ No system keychain found; on Linux install secret-tool (libsecret-tools)")
+		}
+		if err := store.Set(tokenKeychainEntry(account.Name), "krakncat: API token of "+account.Name, token); err != nil {
+			return fmt.Errorf("❌ Could not save the token in %s: %w", store.Name(), err)
+		}
+		account.Token = token
+		account.TokenStore = tokenStoreKeychain
+		return nil
+	}
+
+	account.Token = token
+	if account.isSealed("token") {
+		// Verify the existing secret before sealing with it again
+		if err := c.revealAccount(account); err != nil {
+			return err
+		}
+		account.Token = token
+
+		recipients, err := c.sealRecipients()
+		if err != nil {
+			return err
+		}
+		sealed, err := sealValue(token, recipients)
+		if err != nil {
+			return fmt.Errorf("failed to seal token: %w", err)
+		}
+		account.Sealed["token"] = sealed
+	}
+	return nil
+}
+
+var tokenSetCmd = &cobra.Command{
+	Use:   "set [account-name]",
+	Short: "Store a provider API token for an account",
+	Long: `Store a provider API token (e.g. a GitHub personal access token) for an account.
+The token is read without echo. If the account's token is sealed, the new token
+is sealed again with the same method. With --keychain (or when the account
+already keeps it there) the token goes to macOS Keychain, Windows Credential
+Manager or the Secret Service instead of the config file.
+
+Examples:
+  krakn token set work
+  echo "$GITHUB_TOKEN" | krakn token set work
+  krakn token set work --keychain`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountName := args[0]
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		account := config.getAccount(accountName)
+		if account == nil {
+			return config.accountNotFound(accountName)
+		}
+
+		token, err := readSecret(fmt.Sprintf("🔑 API token for '%s': ", accountName))
+		if err != nil {
+			return fmt.Errorf("failed to read token: %w", err)
+		}
+		if token == "" {
+			return fmt.Errorf("token cannot be empty")
+		}
+
+		keychain, _ := cmd.Flags().GetBool("keychain")
+		if err := config.storeToken(account, token, keychain); err != nil {
+			return err
+		}
+
+		// A new token may belong to someone else; refresh the verified profile
+		if !offlineMode {
+			account.Profile = nil
+			if err := refreshAccountProfile(config, account); err != nil {
+				fmt.Printf("⚠️  Could not fetch the profile: %s\n", strings.TrimPrefix(err.Error(), "❌ "))
+			}
+		}
+
+		if err := config.addAccount(*account); err != nil {
+			return errSaveConfig(err)
+		}
+
+		if account.TokenStore == tokenStoreKeychain {
+			fmt.Printf("✅ Token stored for account '%s' in %s\n", accountName, systemSecretStore().Name())
+		} else {
+			fmt.Printf("✅ Token stored for account '%s'\n", accountName)
+		}
+		if !account.isSealed("token") && account.TokenStore != tokenStoreKeychain {
+			fmt.Printf("💡 Use 'krakn private seal %s --field token' to encrypt it at rest\n", accountName)
+		}
+		return nil
+	},
+}
+
+var tokenClearCmd = &cobra.Command{
+	Use:   "clear [account-name]",
+	Short: "Remove the stored API token for an account",
+	Long: `Remove the stored API token for an account, sealed or not.
+
+Examples:
+  krakn token clear work`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountName := args[0]
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		account := config.getAccount(accountName)
+		if account == nil {
+			return config.accountNotFound(accountName)
+		}
+
+		if account.TokenStore == tokenStoreKeychain {
+			if store := systemSecretStore(); store != nil {
+				if err := store.Delete(tokenKeychainEntry(account.Name)); err != nil && !errors.Is(err, errSecretNotFound) {
+					return fmt.Errorf("❌ Could not remove the token from %s: %w", store.Name(), err)
+				}
+			}
+			account.TokenStore = ""
+		}
+		account.Token = ""
+		account.Profile = nil
+		delete(account.Sealed, "token")
+
+		if err := config.addAccount(*account); err != nil {
+			return errSaveConfig(err)
+		}
+
+		fmt.Printf("🗑️  Token removed for account '%s'\n", accountName)
+		return nil
+	},
+}
+
+func init() {
+	tokenSetCmd.Flags().Bool("keychain", false, "Keep the token in the system keychain instead of the config file")
+	tokenCmd.AddCommand(tokenSetCmd)
+	tokenCmd.AddCommand(tokenClearCmd)
+	RootCmd.AddCommand(tokenCmd)
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// githubFeature is something krakn does through the GitHub API, with the
+// token access it requires
+type githubFeature struct {
+	Name        string
+	Description string
+	Scopes      []string // Classic token scopes, any one of which is enough
+	Permissions []string // Fine-grained token permissions
+	Probe       string   // Read-only endpoint that fails without the permissions
+}
+
+// githubFeatures lists every API feature so tokens can be checked up front
+var githubFeatures = []githubFeature{
+	{
+		Name:        "keys",
+		Description: "Upload and list SSH keys",
+		Scopes:      []string{"admin:public_key", "write:public_key"},
+		Permissions: []string{"Account permissions → Git SSH keys: Read and write"},
+		Probe:       "/user/keys?per_page=1",
+	},
+	{
+		Name:        "repos",
+		Description: "List, create and fork repositories",
+		Scopes:      []string{"repo"},
+		Permissions: []string{"Repository permissions → Metadata: Read", "Repository permissions → Administration: Read and write (to create repositories)"},
+		Probe:       "/user/repos?per_page=1",
+	},
+	{
+		Name:        "orgs",
+		Description: "Check organization membership and SSO",
+		Scopes:      []string{"read:org", "write:org", "admin:org"},
+		Permissions: []string{"Organization permissions → Members: Read"},
+		Probe:       "/user/orgs?per_page=1",
+	},
+}
+
+// impliedScopes maps a classic scope to the scopes it includes
+var impliedScopes = map[string][]string{
+	"admin:public_key": {"write:public_key", "read:public_key"},
+	"write:public_key": {"read:public_key"},
+	"admin:org":        {"write:org", "read:org"},
+	"write:org":        {"read:org"},
+	"repo":             {"public_repo", "repo:status"},
+}
+
+func findGitHubFeature(name string) *githubFeature {
+	for i := range githubFeatures {
+		if githubFeatures[i].Name == name {
+			return &githubFeatures[i]
+		}
+	}
+	return nil
+}
+
+// hasScope reports whether a comma-separated X-OAuth-Scopes value grants scope
+func hasScope(granted, scope string) bool {
+	for _, have := range strings.Split(granted, ",") {
+		have = strings.TrimSpace(have)
+		if have == scope || containsString(impliedScopes[have], scope) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyFeature checks that the token can use a feature, returning an error
+// that names exactly what to grant when it cannot
+func (c *githubClient) verifyFeature(feature *githubFeature) error {
+	// Any request reports the classic scopes and validates the token itself
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := c.get("/user", &user); err != nil {
+		var apiErr *githubAPIError
+		if errors.As(err, &apiErr) && apiErr.Status == 401 {
+			return fmt.Errorf("❌ The API token is invalid or expired. Replace it with 'krakn token set'")
+		}
+		return err
+	}
+
+	if c.Classic {
+		for _, scope := range feature.Scopes {
+			if hasScope(c.Scopes, scope) {
+				return nil
+			}
+		}
+		granted := c.Scopes
+		if granted == "" {
+			granted = "none"
+		}
+		return fmt.Errorf("❌ %s needs a token with the '%s' scope (granted: %s).\n   Edit the token at https://github.com/settings/tokens",
+			feature.Description, feature.Scopes[0], granted)
+	}
+
+	// Fine-grained tokens do not report permissions, so try the feature's endpoint
+	err := c.get(feature.Probe, nil)
+	var apiErr *githubAPIError
+	if errors.As(err, &apiErr) && (apiErr.Status == 403 || apiErr.Status == 404) {
+		msg := fmt.Sprintf("❌ %s needs these fine-grained token permissions:", feature.Description)
+		for _, permission := range feature.Permissions {
+			msg += "\n   • " + permission
+		}
+		if apiErr.AcceptedPermissions != "" {
+			msg += "\n   GitHub reports the endpoint accepts: " + apiErr.AcceptedPermissions
+		}
+		msg += "\n   Edit the token at https://github.com/settings/personal-access-tokens"
+		return fmt.Errorf("%s", msg)
+	}
+	return err
+}
+
+// requireGitHubFeature returns an API client for the account after checking
+// that its token can use the feature, so commands fail before doing any work
+func requireGitHubFeature(config *Config, account *Account, name string) (*githubClient, error) {
+	feature := findGitHubFeature(name)
+	if feature == nil {
+		return nil, fmt.Errorf("unknown GitHub API feature %q", name)
+	}
+	client, err := newGitHubClient(config, account)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.verifyFeature(feature); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+var tokenCheckCmd = &cobra.Command{
+	Use:   "check [account-name]",
+	Short: "Show which krakn features an account's API token allows",
+	Long: `Check an account's GitHub API token against every feature that uses the API and
+print the scopes or fine-grained permissions that are missing.
+
+Examples:
+  krakn token check work`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+
+		client, err := newGitHubClient(config, account)
+		if err != nil {
+			return err
+		}
+
+		spin := startSpinner("Checking token of " + account.Name)
+		results := make([]error, len(githubFeatures))
+		for i := range githubFeatures {
+			results[i] = client.verifyFeature(&githubFeatures[i])
+		}
+		spin.Stop()
+
+		if client.Classic {
+			fmt.Printf("🔑 Classic token with scopes: %s\n", client.Scopes)
+		} else {
+			fmt.Println("🔑 Fine-grained token")
+		}
+		missing := 0
+		for i, feature := range githubFeatures {
+			if results[i] == nil {
+				fmt.Printf("   ✅ %s\n", feature.Description)
+				continue
+			}
+			missing++
+			fmt.Printf("   ⚠️  %s\n", strings.TrimPrefix(results[i].Error(), "❌ "))
+		}
+		if missing == 0 {
+			fmt.Println("🎉 The token allows every feature")
+		}
+		return nil
+	},
+}
+
+func init() {
+	tokenCmd.AddCommand(tokenCheckCmd)
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+// Kinds of trace steps
+const (
+	traceRead  = "read"  // A configuration file was read
+	traceMatch = "match" // A decision, e.g. which account applies
+	traceWrite = "write" // A file is about to be modified
+	traceRun   = "run"   // An external command (git, ssh, ...) was started
+)
+
+// TraceStep is one decision or side effect of a krakn invocation. Steps are
+// stored with the operation in the history log so 'krakn explain' can show
+// them afterwards.
+type TraceStep struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// traceLive prints steps to stderr as they happen (--trace)
+var traceLive bool
+
+// traceSteps collects the steps of the running command. Commands such as
+// 'krakn bench' run git and ssh concurrently, hence the lock.
+var (
+	traceSteps []TraceStep
+	traceMu    sync.Mutex
+)
+
+// tracef records a step. Repeated identical steps, e.g. the same file read
+// for several repositories, are recorded once.
+func tracef(kind, format string, args ...interface{}) {
+	step := TraceStep{Kind: kind, Message: fmt.Sprintf(format, args...)}
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	for _, existing := range traceSteps {
+		if existing == step {
+			return
+		}
+	}
+	traceSteps = append(traceSteps, step)
+	if traceLive {
+		fmt.Fprintf(os.Stderr, "🔎 %s %s\n", step.icon(), step.Message)
+	}
+}
+
+// traceExec records an external command before it runs
+func traceExec(cmd *exec.Cmd) *exec.Cmd {
+	tracef(traceRun, "%s", strings.Join(cmd.Args, " "))
+	return cmd
+}
+
+// takeTrace returns the steps collected so far and starts a new trace
+func takeTrace() []TraceStep {
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	steps := traceSteps
+	traceSteps = nil
+	return steps
+}
+
+func (s TraceStep) icon() string {
+	switch s.Kind {
+	case traceRead:
+		return "📖"
+	case traceMatch:
+		return "🎯"
+	case traceWrite:
+		return "✏️ "
+	case traceRun:
+		return "⚙️ "
+	}
+	return "•"
+}
+
+// findOperation returns the operation with the given id or, for a command
+// name such as "use", the most recent operation of that command
+func findOperation(ops []Operation, query string) *Operation {
+	if query == "" && len(ops) > 0 {
+		return &ops[len(ops)-1]
+	}
+	for i := len(ops) - 1; i >= 0; i-- {
+		if ops[i].ID == query {
+			return &ops[i]
+		}
+	}
+	prefix := "krakn " + strings.TrimPrefix(query, "krakn ")
+	for i := len(ops) - 1; i >= 0; i-- {
+		if ops[i].Command == prefix || strings.HasPrefix(ops[i].Command, prefix+" ") {
+			return &ops[i]
+		}
+	}
+	return nil
+}
+
+var explainCmd = &cobra.Command{
+	Use:   "explain [op-id | command | error-code]",
+	Short: "Show the decisions and side effects of a recorded operation",
+	Long: `Show step by step what a recorded operation did: which configuration files it
+read, which account it picked and why, which files it modified and which git
+and ssh commands it ran. Without an argument the most recent operation is
+explained; a command name picks the most recent run of that command.
+
+Only operations that modified files are recorded. To watch any command,
+including ones that change nothing, run it with --trace.
+
+An error code such as KRKN-002, printed with an error, is explained too.
+
+Examples:
+  krakn explain                       # The most recent operation
+  krakn explain use                   # The last 'krakn use'
+  krakn explain 20250101-120000-ab12  # An operation from 'krakn log'
+  krakn use work --trace              # Print the steps while running
+  krakn explain KRKN-002              # What an error code means`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		query := strings.Join(args, " ")
+		if code := strings.ToUpper(query); errorCatalog[code] != "" {
+			fmt.Printf("%s: %s\n", code, errorCatalog[code])
+			return nil
+		}
+
+		ops, err := loadOperations()
+		if err != nil {
+			return fmt.Errorf("failed to read history: %w", err)
+		}
+		op := findOperation(ops, query)
+		if op == nil {
+			if query == "" {
+				return fmt.Errorf("❌ No operations recorded yet")
+			}
+			return fmt.Errorf("❌ No recorded operation matches '%s'. Use 'krakn log' to list operations", query)
+		}
+
+		fmt.Printf("🆔 %s  %s\n", op.ID, op.Time.Format("2006-01-02 15:04:05"))
+		fmt.Printf("💻 %s\n", op.Command)
+		fmt.Println()
+		if len(op.Trace) == 0 {
+			fmt.Println("ℹ️  No steps were recorded for this operation (it predates tracing)")
+		}
+		for i, step := range op.Trace {
+			fmt.Printf("%3d. %s %s\n", i+1, step.icon(), step.Message)
+		}
+
+		fmt.Println()
+		fmt.Println("📄 Result:")
+		for _, change := range op.Files {
+			fmt.Printf("   %s (%s)\n", contractHomePath(change.Path), change.action())
+		}
+		fmt.Printf("\n💡 Undo with 'krakn revert %s'\n", op.ID)
+		return nil
+	},
+}
+
+func init() {
+	RootCmd.PersistentFlags().BoolVar(&traceLive, "trace", false, "Print every decision, file access and external command to stderr")
+	RootCmd.AddCommand(explainCmd)
+}
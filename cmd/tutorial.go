@@ -0,0 +1,446 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// tutorialNotes explain the files the tutorial creates, keyed by their path
+// below the sandbox home. Paths not listed here fall back to a suffix match.
+var tutorialNotes = map[string]string{
+	".krakncat/config.json": "krakn's own list of accounts. Nothing else reads it; the files below are generated from it.",
+	".ssh/config":           "One Host alias per account. A remote such as git@github.com-work:acme/api.git makes SSH offer the work key and nothing else.",
+	".gitconfig":            "Your global git config. 'krakn use' sets the default identity; includeIf rules load a directory's identity on top of it.",
+	"work/.gitconfig":       "The identity of everything below ~/work, loaded by the includeIf rule in ~/.gitconfig.",
+	"work/api/.git/config":  "Repository settings. Values here win over ~/.gitconfig and every include.",
+}
+
+// tutorialSandbox runs krakn and git against a throwaway home directory
+type tutorialSandbox struct {
+	Home       string
+	executable string
+	files      map[string]string // Snapshot of the sandbox after the previous step
+	env        []string          // Extra environment, e.g. the port of the demo's sandbox provider
+}
+
+func newTutorialSandbox() (*tutorialSandbox, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the krakn binary: %w", err)
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("❌ The tutorial needs git, which is not installed")
+	}
+	home, err := os.MkdirTemp("", "krakn-tutorial-")
+	if err != nil {
+		return nil, err
+	}
+	// Resolve symlinks (macOS /var → /private/var) so git's gitdir matching sees the same path
+	if resolved, err := filepath.EvalSymlinks(home); err == nil {
+		home = resolved
+	}
+	return &tutorialSandbox{Home: home, executable: executable, files: map[string]string{}}, nil
+}
+
+// environ is the environment of every command in the sandbox: the real home,
+// git and krakn settings and the SSH agent are left out so nothing outside
+// the sandbox is read or changed
+func (s *tutorialSandbox) environ() []string {
+	var env []string
+	for _, variable := range os.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+		if name == "HOME" || name == "USERPROFILE" || name == "XDG_CONFIG_HOME" || name == "SSH_AUTH_SOCK" ||
+			strings.HasPrefix(name, "GIT_") || strings.HasPrefix(name, "KRAKN_") {
+			continue
+		}
+		env = append(env, variable)
+	}
+	env = append(env, "HOME="+s.Home, "USERPROFILE="+s.Home, "GIT_CONFIG_NOSYSTEM=1", "KRAKN_ALLOW_ROOT=1")
+	return append(env, s.env...)
+}
+
+// display replaces the sandbox path with ~, as it appears to the commands
+func (s *tutorialSandbox) display(text string) string {
+	return strings.ReplaceAll(text, s.Home, "~")
+}
+
+// printOutput indents command output. Prompts answered from input run into
+// the next line of output, so only what follows the last prompt is kept.
+func printOutput(output string) {
+	for _, line := range strings.Split(strings.TrimRight(output, "\n"), "\n") {
+		if strings.HasPrefix(line, "💬") || strings.HasPrefix(line, "🤔") {
+			if i := strings.LastIndex(line, ": "); i >= 0 {
+				line = line[i+2:]
+			}
+		}
+		if strings.TrimSpace(line) != "" {
+			fmt.Println("     " + line)
+		}
+	}
+}
+
+// krakn runs krakn in the sandbox, answering its prompts with input
+func (s *tutorialSandbox) krakn(dir, input string, args ...string) error {
+	fmt.Printf("   $ krakn %s\n", s.display(strings.Join(args, " ")))
+	cmd := traceExec(exec.Command(s.executable, append([]string{"--plain", "--offline"}, args...)...))
+	cmd.Dir = filepath.Join(s.Home, dir)
+	cmd.Env = s.environ()
+	cmd.Stdin = strings.NewReader(input)
+	output, err := cmd.CombinedOutput()
+	printOutput(s.display(string(output)))
+	return err
+}
+
+// git runs git in a directory of the sandbox
+func (s *tutorialSandbox) git(dir string, args ...string) (string, error) {
+	fmt.Printf("   $ git %s\n", strings.Join(args, " "))
+	cmd := traceExec(exec.Command("git", args...))
+	cmd.Dir = filepath.Join(s.Home, dir)
+	cmd.Env = s.environ()
+	output, err := cmd.CombinedOutput()
+	printOutput(s.display(string(output)))
+	return strings.TrimSpace(string(output)), err
+}
+
+// snapshot reads the text files of the sandbox. Private keys are not shown,
+// of git directories only the config is of interest and the backups of
+// config.json would only repeat it.
+func (s *tutorialSandbox) snapshot() map[string]string {
+	files := map[string]string{}
+	filepath.WalkDir(s.Home, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(s.Home, path)
+		rel = filepath.ToSlash(rel)
+		if entry.IsDir() {
+			if rel == ".krakncat/history" {
+				return filepath.SkipDir
+			}
+			if entry.Name() == ".git" {
+				if data, err := os.ReadFile(filepath.Join(path, "config")); err == nil {
+					files[rel+"/config"] = string(data)
+				}
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(rel, ".lock") {
+			return nil
+		}
+		if name := entry.Name(); strings.HasPrefix(name, "config.json.bak.") || strings.HasPrefix(name, "config.json.corrupt-") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		if isPrivateKey(data) {
+			files[rel] = "(private key, not shown)\n"
+			return nil
+		}
+		files[rel] = string(data)
+		return nil
+	})
+	return files
+}
+
+// diffLines returns a minimal line diff of two texts, with "+", "-" or " "
+// in front of every line
+func diffLines(before, after string) []string {
+	a := strings.Split(strings.TrimSuffix(before, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(after, "\n"), "\n")
+	if before == "" {
+		a = nil
+	}
+	if after == "" {
+		b = nil
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+	return lines
+}
+
+// diffContext keeps the changed lines of a diff and the given number of
+// unchanged lines around them, marking gaps with "…"
+func diffContext(lines []string, context int) []string {
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if strings.HasPrefix(line, "  ") {
+			continue
+		}
+		for j := i - context; j <= i+context; j++ {
+			if j >= 0 && j < len(lines) {
+				keep[j] = true
+			}
+		}
+	}
+	var result []string
+	for i, line := range lines {
+		if keep[i] {
+			result = append(result, line)
+		} else if i == 0 || keep[i-1] {
+			result = append(result, "  …")
+		}
+	}
+	return result
+}
+
+// tutorialNote returns the explanation of a file in the sandbox
+func tutorialNote(rel string) string {
+	if note, ok := tutorialNotes[rel]; ok {
+		return note
+	}
+	switch {
+	case strings.HasPrefix(rel, ".ssh/") && strings.HasSuffix(rel, ".pub"):
+		return "The public key to add to the provider account."
+	case strings.HasPrefix(rel, ".ssh/id_"):
+		return "The private key. It never leaves this machine."
+	}
+	return ""
+}
+
+// showChanges prints the files that changed since the previous step, each
+// with a note on what it is for
+func (s *tutorialSandbox) showChanges() {
+	current := s.snapshot()
+	var paths []string
+	for path := range current {
+		if current[path] != s.files[path] {
+			paths = append(paths, path)
+		}
+	}
+	for path := range s.files {
+		if _, ok := current[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		fmt.Printf("\n   📝 ~/%s\n", path)
+		if note := tutorialNote(path); note != "" {
+			fmt.Printf("      ℹ️  %s\n", note)
+		}
+		for _, line := range diffContext(diffLines(s.files[path], current[path]), 2) {
+			fmt.Println("      " + s.display(line))
+		}
+	}
+	s.files = current
+}
+
+// tutorialStep is one stage of the tutorial
+type tutorialStep struct {
+	Title   string
+	Explain string
+	Run     func(s *tutorialSandbox) error
+}
+
+var tutorialSteps = []tutorialStep{
+	{
+		Title: "Add a work account",
+		Explain: `Every account gets its own SSH key and a Host alias in ~/.ssh/config.
+'krakn add' asks for a name, email and username; the tutorial answers for you.`,
+		Run: func(s *tutorialSandbox) error {
+			return s.krakn("", "work\nalice@acme.example\nalice-acme\n\ny\ny\n", "add")
+		},
+	},
+	{
+		Title: "Add a personal account",
+		Explain: `A second account on the same provider. Both live on github.com; the alias
+decides which key SSH offers.`,
+		Run: func(s *tutorialSandbox) error {
+			return s.krakn("", "personal\nalice@home.example\nalice\n\ny\ny\n", "add")
+		},
+	},
+	{
+		Title:   "Make personal the default",
+		Explain: `'krakn use' writes the global identity, used wherever no mapping applies.`,
+		Run: func(s *tutorialSandbox) error {
+			return s.krakn("", "", "use", "personal")
+		},
+	},
+	{
+		Title: "Map ~/work to the work account",
+		Explain: `A directory mapping makes git use the work identity for every repository
+below ~/work, without touching the repositories themselves.`,
+		Run: func(s *tutorialSandbox) error {
+			if err := os.MkdirAll(filepath.Join(s.Home, "work"), 0755); err != nil {
+				return err
+			}
+			return s.krakn("", "", "config", filepath.Join(s.Home, "work"), "work")
+		},
+	},
+	{
+		Title: "Clone a work repository",
+		Explain: `The remote goes through the work alias, and the mapping picks the email.
+(The tutorial creates the repository locally instead of cloning it.)`,
+		Run: func(s *tutorialSandbox) error {
+			if err := os.MkdirAll(filepath.Join(s.Home, "work", "api"), 0755); err != nil {
+				return err
+			}
+			for _, args := range [][]string{
+				{"init", "--quiet"},
+				{"remote", "add", "origin", "git@github.com-work:acme/api.git"},
+				{"config", "user.email"},
+			} {
+				if _, err := s.git("work/api", args...); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Title: "Commit with the wrong identity",
+		Explain: `A common slip: a local user.email copied from a personal project. git accepts
+it silently, and the commit would be pushed to acme under your private email.
+'krakn scan' (and 'krakn doctor' inside the repository) catch the mismatch.`,
+		Run: func(s *tutorialSandbox) error {
+			for _, args := range [][]string{
+				{"config", "user.email", "alice@home.example"},
+				{"commit", "--quiet", "--allow-empty", "-m", "Add API skeleton"},
+				{"log", "-1", "--format=%an <%ae>"},
+			} {
+				if _, err := s.git("work/api", args...); err != nil {
+					return err
+				}
+			}
+			s.krakn("", "", "scan", filepath.Join(s.Home, "work"))
+			return nil
+		},
+	},
+	{
+		Title: "Fix the repository",
+		Explain: `'krakn use <account> <path>' sets the repository back to the work identity.
+Amend the commit afterwards to re-author it; nothing was pushed yet.`,
+		Run: func(s *tutorialSandbox) error {
+			if err := s.krakn("", "", "use", "work", filepath.Join(s.Home, "work", "api")); err != nil {
+				return err
+			}
+			if _, err := s.git("work/api", "commit", "--quiet", "--amend", "--allow-empty", "--no-edit", "--reset-author"); err != nil {
+				return err
+			}
+			if _, err := s.git("work/api", "log", "-1", "--format=%an <%ae>"); err != nil {
+				return err
+			}
+			return s.krakn("", "", "scan", filepath.Join(s.Home, "work"))
+		},
+	},
+}
+
+var tutorialCmd = &cobra.Command{
+	Use:   "tutorial",
+	Short: "Walk through krakn in a sandbox",
+	Long: `Walk through krakn step by step in a throwaway home directory: add two demo
+accounts, map a directory, and watch a commit with the wrong identity being
+caught and fixed. After every step the files krakn created or changed are shown
+as annotated diffs.
+
+Nothing outside the sandbox is read or changed: your real ~/.ssh, ~/.gitconfig
+and krakn configuration stay untouched, and the sandbox is deleted at the end
+unless --keep is given. No network access is needed.
+
+Examples:
+  krakn tutorial
+  krakn tutorial --keep       # Look around the sandbox afterwards
+  krakn tutorial --no-pause   # Run all steps without waiting`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keep, _ := cmd.Flags().GetBool("keep")
+		noPause, _ := cmd.Flags().GetBool("no-pause")
+		pause := !noPause && term.IsTerminal(int(os.Stdin.Fd()))
+		cmd.SilenceUsage = true
+
+		sandbox, err := newTutorialSandbox()
+		if err != nil {
+			return err
+		}
+		failed := false
+		defer func() {
+			if !keep && !failed {
+				os.RemoveAll(sandbox.Home)
+			}
+		}()
+
+		fmt.Println("🎓 krakn tutorial")
+		fmt.Printf("   Sandbox home: %s (shown as ~ below)\n", sandbox.Home)
+		reader := bufio.NewReader(os.Stdin)
+		for i, step := range tutorialSteps {
+			if pause && i > 0 {
+				fmt.Print("\n⏎  Press Enter for the next step (q to quit): ")
+				input, _ := reader.ReadString('\n')
+				if strings.TrimSpace(strings.ToLower(input)) == "q" {
+					break
+				}
+			}
+			fmt.Printf("\n%s\n📘 Step %d/%d: %s\n\n", strings.Repeat("─", 60), i+1, len(tutorialSteps), step.Title)
+			for _, line := range strings.Split(step.Explain, "\n") {
+				fmt.Println("   " + line)
+			}
+			fmt.Println()
+			if err := step.Run(sandbox); err != nil {
+				failed = true
+				return fmt.Errorf("❌ Step %d failed: %w (sandbox kept at %s)", i+1, err, sandbox.Home)
+			}
+			sandbox.showChanges()
+		}
+
+		fmt.Printf("\n%s\n🎉 That's it! On your machine:\n", strings.Repeat("─", 60))
+		fmt.Println("   krakn add                       # Add your accounts")
+		fmt.Println("   krakn config ~/work <account>   # Map directories")
+		fmt.Println("   krakn doctor                    # Check a repository")
+		if keep {
+			fmt.Printf("📁 Sandbox kept at %s\n", sandbox.Home)
+			fmt.Printf("💡 Explore it with: HOME=%s krakn list\n", shellQuote(sandbox.Home))
+		}
+		return nil
+	},
+}
+
+func init() {
+	tutorialCmd.Flags().Bool("keep", false, "Keep the sandbox directory afterwards")
+	tutorialCmd.Flags().Bool("no-pause", false, "Do not wait for Enter between steps")
+	RootCmd.AddCommand(tutorialCmd)
+}
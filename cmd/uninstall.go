@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// uninstallStep removes state owned by a feature, e.g. installed git hooks.
+// Describe returns what would be removed, or nothing when there is nothing to do.
+type uninstallStep struct {
+	Name     string
+	Describe func(config *Config) []string
+	Run      func(config *Config) error
+}
+
+var uninstallSteps []uninstallStep
+
+// registerUninstallStep lets a feature clean up after itself on 'krakn uninstall'
+func registerUninstallStep(step uninstallStep) {
+	uninstallSteps = append(uninstallSteps, step)
+}
+
+// managedSSHAliases returns the Host aliases krakncat generated. Aliases that
+// were linked from an existing ~/.ssh/config block belong to the user.
+func managedSSHAliases(config *Config) []string {
+	blocks, _ := readSSHConfig()
+	var aliases []string
+	for _, account := range config.Accounts {
+		if account.SSHHost != "" {
+			continue
+		}
+		for i := range blocks {
+			if len(blocks[i].Patterns) == 1 && blocks[i].Patterns[0] == account.GetSSHHost() {
+				aliases = append(aliases, account.GetSSHHost())
+				break
+			}
+		}
+	}
+	return aliases
+}
+
+// managedDirectories returns the directories krakncat configured, including
+// includeIf entries written before mappings were recorded (path = <dir>/.gitconfig)
+func managedDirectories(config *Config) []string {
+	var dirs []string
+	for _, mapping := range config.Directories {
+		dirs = append(dirs, mapping.patternDir())
+	}
+
+	gitConfig, err := readGitConfigFile(globalGitConfigPath())
+	if err != nil {
+		return dirs
+	}
+	for _, section := range gitConfig.findSections("includeIf") {
+		pattern := strings.TrimPrefix(section.Subsection, "gitdir:")
+		if pattern == section.Subsection {
+			continue
+		}
+		dir := filepath.Clean(expandUserPath(pattern))
+		if filepath.Clean(expandUserPath(section.get("path"))) != filepath.Join(dir, ".gitconfig") {
+			continue
+		}
+		if !containsString(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// chooseFinalIdentity asks which account should remain as the single global identity.
+// It returns nil to keep the current global user.name/user.email.
+func chooseFinalIdentity(config *Config, reader *bufio.Reader) (*Account, error) {
+	if len(config.Accounts) == 0 {
+		return nil, nil
+	}
+
+	currentName := getGitConfig("user.name", true)
+	currentEmail := getGitConfig("user.email", true)
+
+	fmt.Println("\n👤 Which identity should remain in your global git config?")
+	fmt.Printf("   0. Keep the current one (%s <%s>)\n", currentName, currentEmail)
+	for i, account := range config.Accounts {
+		email := account.Email
+		if account.isSealed("email") {
+			email = "🔒 sealed"
+		}
+		fmt.Printf("   %d. %s (%s <%s>)\n", i+1, account.Name, account.Username, email)
+	}
+	fmt.Print("💬 Choice [0]: ")
+	resp, _ := reader.ReadString('\n')
+	resp = strings.TrimSpace(resp)
+	if resp == "" || resp == "0" {
+		return nil, nil
+	}
+
+	index, err := strconv.Atoi(resp)
+	if err != nil || index < 1 || index > len(config.Accounts) {
+		return nil, fmt.Errorf("❌ Invalid choice: %s", resp)
+	}
+	return &config.Accounts[index-1], nil
+}
+
+var uninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove everything krakncat set up and restore a single identity",
+	Long: `Remove everything krakncat manages: generated SSH Host blocks, directory
+include files and their conditional includes, and anything else features
+installed (such as git hooks). Your SSH keys and repositories are kept.
+
+You choose which account stays as the global git identity. With --purge the
+~/.krakncat directory (configuration and history) is deleted as well; without
+it, 'krakn revert' can still undo the uninstall.
+
+Examples:
+  krakn uninstall                      # Review the plan, then confirm
+  krakn uninstall --identity personal  # Keep 'personal' as the global identity
+  krakn uninstall --purge --yes        # Remove everything without prompting`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		purge, _ := cmd.Flags().GetBool("purge")
+		yes, _ := cmd.Flags().GetBool("yes")
+		identityName, _ := cmd.Flags().GetString("identity")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+
+		aliases := managedSSHAliases(config)
+		dirs := managedDirectories(config)
+
+		// Show the plan
+		fmt.Println("🧹 krakn uninstall will remove:")
+		nothing := true
+		for _, alias := range aliases {
+			fmt.Printf("   🔗 SSH Host block %s\n", alias)
+			nothing = false
+		}
+		for _, dir := range dirs {
+			fmt.Printf("   📁 Directory configuration for %s\n", contractHomePath(dir))
+			nothing = false
+		}
+		for _, step := range uninstallSteps {
+			for _, item := range step.Describe(config) {
+				fmt.Printf("   🧩 %s: %s\n", step.Name, item)
+				nothing = false
+			}
+		}
+		if purge {
+			fmt.Printf("   🗑️  %s (configuration and history)\n", contractHomePath(filepath.Dir(getConfigPath())))
+			nothing = false
+		}
+		if nothing {
+			fmt.Println("   (nothing)")
+		}
+
+		var keys []string
+		for _, account := range config.Accounts {
+			if account.SSHKey != "" && fileExists(account.SSHKey) {
+				keys = append(keys, contractHomePath(account.SSHKey))
+			}
+		}
+
+		reader := bufio.NewReader(os.Stdin)
+		if !yes {
+			fmt.Print("\n⚠️  Continue? [y/N]: ")
+			resp, _ := reader.ReadString('\n')
+			resp = strings.ToLower(strings.TrimSpace(resp))
+			if resp != "y" && resp != "yes" {
+				fmt.Println("❌ Uninstall cancelled")
+				return nil
+			}
+		}
+
+		// Pick the identity that stays, while account data is still available
+		var identity *Account
+		if identityName != "" {
+			identity = config.getAccount(identityName)
+			if identity == nil {
+				return config.accountNotFound(identityName)
+			}
+		} else if !yes {
+			if identity, err = chooseFinalIdentity(config, reader); err != nil {
+				return err
+			}
+		}
+		if identity != nil {
+			if err := config.revealAccount(identity); err != nil {
+				return err
+			}
+		}
+
+		fmt.Println()
+		for _, alias := range aliases {
+			if _, err := removeSSHHostBlock(alias); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Removed SSH Host block %s\n", alias)
+		}
+		for _, dir := range dirs {
+			if err := unmapDirectoryConfig(config, dir); err != nil {
+				fmt.Printf("⚠️  %v\n", err)
+			}
+		}
+		for _, step := range uninstallSteps {
+			if len(step.Describe(config)) == 0 {
+				continue
+			}
+			if err := step.Run(config); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", step.Name, err)
+			}
+			fmt.Printf("✅ Removed %s\n", step.Name)
+		}
+
+		if identity != nil {
+			if _, err := setGlobalIdentity(identity.Username, identity.Email); err != nil {
+				return fmt.Errorf("failed to set global identity: %w", err)
+			}
+			fmt.Printf("✅ Global identity set to %s <%s>\n", identity.Username, identity.Email)
+		}
+
+		if purge {
+			// The history is going away, so there is nothing left to record it in
+			discardOperation()
+			if err := os.RemoveAll(filepath.Dir(getConfigPath())); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", filepath.Dir(getConfigPath()), err)
+			}
+			fmt.Printf("✅ Removed %s\n", contractHomePath(filepath.Dir(getConfigPath())))
+		}
+
+		fmt.Println("\n🎉 krakncat has been uninstalled.")
+		if len(keys) > 0 {
+			fmt.Println("🔑 Your SSH keys were kept:")
+			for _, key := range keys {
+				fmt.Printf("   %s\n", key)
+			}
+		}
+		if executable, err := os.Executable(); err == nil {
+			fmt.Printf("💡 Remove the binary itself with: rm %s\n", executable)
+		}
+		if !purge {
+			fmt.Println("💡 Changed your mind? 'krakn log' and 'krakn revert' can undo this.")
+		}
+		return nil
+	},
+}
+
+func init() {
+	uninstallCmd.Flags().Bool("purge", false, "Also delete ~/.krakncat (configuration and history)")
+	uninstallCmd.Flags().BoolP("yes", "y", false, "Do not prompt; keeps the current global identity unless --identity is given")
+	uninstallCmd.Flags().String("identity", "", "Account to keep as the global git identity")
+	RootCmd.AddCommand(uninstallCmd)
+}
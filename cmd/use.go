@@ -3,23 +3,22 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 var useCmd = &cobra.Command{
 	Use:   "use [account-name] [path]",
-	Short: "Switch git configuration to use a specific GitHub account",
-	Long: `Switch git configuration to use a specific GitHub account.
+	Short: "Switch git configuration to use a specific account",
+	Long: `Switch git configuration to use a specific account, on any provider.
 
 Examples:
   krakn use personal              # Switch to personal account globally
   krakn use work ~/my-project     # Switch to work account for specific repository
   krakn use personal --global     # Explicitly set global configuration
   krakn use personal -g           # Same as --global (shorthand)
+  krakn use work --json           # Print the result as JSON, for scripts and launchers
 
 By default, switches globally unless a path is provided.
 Use --global flag to explicitly set global configuration.`,
@@ -27,10 +26,11 @@ Use --global flag to explicitly set global configuration.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		accountName := args[0]
 		var repoPath string
-		
+
 		// Check if --global flag is set
 		globalFlag, _ := cmd.Flags().GetBool("global")
-		
+		asJSON, _ := cmd.Flags().GetBool("json")
+
 		// Determine if this should be a global or local config change
 		global := true
 		if len(args) > 1 && !globalFlag {
@@ -40,7 +40,7 @@ Use --global flag to explicitly set global configuration.`,
 
 			// Check if path is a git repository
 			if !isGitRepository(repoPath) {
-				return fmt.Errorf("❌ '%s' is not a git repository", repoPath)
+				return errNotARepository(repoPath)
 			}
 		} else if len(args) > 1 && globalFlag {
 			// Both path and --global provided = error
@@ -51,57 +51,114 @@ Use --global flag to explicitly set global configuration.`,
 		// Load config
 		config, err := loadConfig()
 		if err != nil {
-			return fmt.Errorf("failed to load config: %w", err)
+			return errLoadConfig(err)
 		}
 
 		// Find account
 		account := config.getAccount(accountName)
 		if account == nil {
-			if len(config.Accounts) == 0 {
-				return fmt.Errorf("❌ No accounts configured. Use 'krakn add' to add accounts first")
-			}
-			
-			var availableNames []string
-			for _, acc := range config.Accounts {
-				availableNames = append(availableNames, acc.Name)
-			}
-			return fmt.Errorf("❌ Account '%s' not found. Available accounts: %s", accountName, strings.Join(availableNames, ", "))
+			return config.accountNotFound(accountName)
 		}
 
-		// Update git config
-		if err := setGitConfig("user.name", account.Username, repoPath, global); err != nil {
-			return fmt.Errorf("failed to set git user.name: %w", err)
-		}
+		tracef(traceMatch, "Account '%s' was named on the command line", account.Name)
 
-		if err := setGitConfig("user.email", account.Email, repoPath, global); err != nil {
-			return fmt.Errorf("failed to set git user.email: %w", err)
+		if err := config.revealAccount(account); err != nil {
+			return err
+		}
+		if err := account.checkKeyDir(); err != nil {
+			return err
 		}
 
-		// Update current account in config
+		// Update git config
+		configPath, err := gitConfigTarget(repoPath, global)
+		if err != nil {
+			return err
+		}
 		if global {
-			config.CurrentAccount = accountName
-			if err := config.saveConfig(); err != nil {
-				return fmt.Errorf("failed to save config: %w", err)
+			tracef(traceMatch, "No repository given, so the global git config is the target")
+		} else {
+			tracef(traceMatch, "Repository %s, so its own git config is the target", repoPath)
+		}
+		var changes []gitConfigChange
+		if global {
+			if changes, err = switchGlobalAccount(config, account, "use"); err != nil {
+				return err
 			}
+		} else {
+			if err := writeCommitTemplate(account); err != nil {
+				return err
+			}
+			if changes, err = applyGitConfigValues(configPath, config.switchIdentityValues(configPath, account, false)); err != nil {
+				return fmt.Errorf("failed to update git config: %w", err)
+			}
+			absPath, _ := filepath.Abs(repoPath)
+			config.announceSwitch("", account, absPath, "use")
+		}
+
+		if asJSON {
+			outputFormat = "json"
+		}
+		if structuredOutput() {
+			result := useOutput{
+				SchemaVersion: schemaVersion("use"),
+				OK:            true,
+				Account:       accountName,
+				Scope:         "global",
+				Name:          account.CommitName(),
+				Email:         account.Email,
+			}
+			if !global {
+				result.Scope = "repository"
+				result.Path = repoPath
+			}
+			return printStructured(result)
 		}
 
 		// Display success message
 		scope := "globally"
 		if !global {
 			scope = fmt.Sprintf("for repository at %s", repoPath)
+		} else if config.LocalOnly {
+			scope = "as the current account (local-only mode)"
 		} else if globalFlag {
 			scope = "globally (via --global flag)"
 		}
 
 		fmt.Printf("🎉 Successfully using: %s\n", accountName)
 		fmt.Printf("✅ Switched to account '%s' %s\n", accountName, scope)
-		fmt.Printf("👤 Name: %s\n", account.Username)
+		fmt.Printf("👤 Name: %s\n", account.CommitName())
 		fmt.Printf("📧 Email: %s\n", account.Email)
-		fmt.Printf("🔗 SSH Host: github.com-%s\n", accountName)
+		if !account.usesHTTPS() {
+			fmt.Printf("🔗 SSH Host: %s\n", account.GetSSHHost())
+		}
+		if account.SigningKey != "" {
+			fmt.Printf("✍️  Signing commits with GPG key %s\n", account.SigningKey)
+		}
+		if account.Timezone != "" {
+			fmt.Printf("🕐 Commit time zone: %s (applied by the 'krakn env' shell hook)\n", account.Timezone)
+		}
+		printGitConfigChanges(configPath, changes)
+		if account.usesHTTPS() {
+			// git asks 'krakn credential', which now answers with this account's token
+			if err := installCredentialHelper(account); err != nil {
+				fmt.Printf("⚠️  Could not update the credential helper: %v\n", err)
+			}
+			if global {
+				fmt.Printf("🔐 HTTPS token for %s: %s\n", account.GetProvider().Hostname, accountName)
+			}
+			if !account.hasToken() {
+				fmt.Printf("⚠️  Account '%s' has no token yet: krakn token set %s --keychain\n", accountName, accountName)
+			}
+		} else {
+			offerAgentAdd(account)
+		}
 
 		if !global {
 			fmt.Printf("\n💡 To clone repositories with this account, use:\n")
-			fmt.Printf("   git clone git@github.com-%s:username/repo.git\n", accountName)
+			fmt.Printf("   git clone git@%s:username/repo.git\n", config.cloneHost(account))
+		} else if config.LocalOnly {
+			fmt.Printf("\n💡 'krakn env' and the shell hook now report '%s' outside configured repositories\n", accountName)
+			fmt.Printf("   Give a repository this identity with 'krakn use %s <repo>'\n", accountName)
 		} else {
 			fmt.Printf("\n💡 Global git configuration updated!\n")
 			fmt.Printf("   All new repositories will use this account by default\n")
@@ -119,21 +176,35 @@ func isGitRepository(path string) bool {
 	return false
 }
 
-func setGitConfig(key, value, repoPath string, global bool) error {
-	var cmd *exec.Cmd
-
+// gitConfigTarget returns the config file 'git config' would write to: the
+// global file, or the repository's own config
+func gitConfigTarget(repoPath string, global bool) (string, error) {
 	if global {
-		cmd = exec.Command("git", "config", "--global", key, value)
-	} else {
-		cmd = exec.Command("git", "-C", repoPath, "config", key, value)
+		return globalGitConfigPath(), nil
 	}
-
-	return cmd.Run()
+	_, commonDir, err := findGitDir(repoPath)
+	if err != nil {
+		return "", errNotARepository(repoPath)
+	}
+	return filepath.Join(commonDir, "config"), nil
 }
 
 func init() {
 	RootCmd.AddCommand(useCmd)
-	
+
 	// Add the --global flag
 	useCmd.Flags().BoolP("global", "g", false, "Set global git configuration (default behavior when no path is provided)")
+	useCmd.Flags().Bool("json", false, "Same as --output json")
+	registerOutputSchema(outputSchema{Command: "use", Version: 1, Description: "The account switched to and where", Type: useOutput{}})
+}
+
+// useOutput is printed by 'krakn use --output json|yaml'
+type useOutput struct {
+	SchemaVersion int    `json:"schema_version"`
+	OK            bool   `json:"ok"`
+	Account       string `json:"account"`
+	Scope         string `json:"scope" desc:"global or repository"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	Path          string `json:"path,omitempty" desc:"Repository path as given, for the repository scope"`
 }
@@ -0,0 +1,330 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// usernameChange is a provider login that no longer matches the stored username
+type usernameChange struct {
+	Old     string
+	New     string
+	Source  string // "token" or "SSH key"
+	Certain bool   // The provider account id matches the stored profile, so it is a rename
+}
+
+// detectUsernameChange asks the provider for the account's current login,
+// through the token when one is usable and the SSH greeting otherwise. It
+// returns nil when the login still matches. Sealed tokens are only used with
+// reveal set, since opening them may prompt.
+func detectUsernameChange(config *Config, account *Account, reveal bool) (*usernameChange, *AccountProfile, error) {
+	if account.Token != "" || account.TokenStore == tokenStoreKeychain || (reveal && account.isSealed("token")) {
+		profile, err := fetchAccountProfile(config, account)
+		if err != nil {
+			return nil, nil, err
+		}
+		if strings.EqualFold(profile.Login, account.Username) {
+			return nil, profile, nil
+		}
+		certain := account.Profile != nil && account.Profile.ID == profile.ID
+		return &usernameChange{Old: account.Username, New: profile.Login, Source: "token", Certain: certain}, profile, nil
+	}
+
+	if account.SSHKey == "" {
+		return nil, nil, fmt.Errorf("no API token or SSH key to ask the provider with")
+	}
+	result, err := nativeSSHProbe(account, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if result.Username == "" {
+		return nil, nil, fmt.Errorf("%s does not report the username over SSH", account.GetProvider().DisplayName)
+	}
+	if strings.EqualFold(result.Username, account.Username) {
+		return nil, nil, nil
+	}
+	return &usernameChange{Old: account.Username, New: result.Username, Source: "SSH key"}, nil, nil
+}
+
+// checkUsernameChanges reports accounts whose provider login was renamed
+func checkUsernameChanges(ctx *doctorContext) []doctorFinding {
+	if ctx.offline() {
+		return nil
+	}
+	var findings []doctorFinding
+	for i := range ctx.Config.Accounts {
+		account := &ctx.Config.Accounts[i]
+		if account.Username == "" {
+			continue
+		}
+		change, _, err := detectUsernameChange(ctx.Config, account, false)
+		switch {
+		case err != nil:
+			findings = append(findings, doctorFinding{Level: doctorInfo, Message: fmt.Sprintf("Account '%s': username not checked (%s)", account.Name, strings.TrimPrefix(err.Error(), "❌ "))})
+		case change == nil:
+			findings = append(findings, doctorFinding{Level: doctorOK, Message: fmt.Sprintf("Account '%s': @%s is current", account.Name, account.Username)})
+		default:
+			message := fmt.Sprintf("Account '%s': the %s now belongs to @%s, not @%s", account.Name, change.Source, change.New, change.Old)
+			if change.Certain {
+				message = fmt.Sprintf("Account '%s': @%s was renamed to @%s", account.Name, change.Old, change.New)
+			}
+			findings = append(findings, doctorFinding{
+				Level:   doctorWarn,
+				Message: message,
+				Hint:    fmt.Sprintf("krakn account sync-username %s", account.Name),
+			})
+		}
+	}
+	return findings
+}
+
+// renamedNoreplyEmail returns the GitHub noreply address for the new login
+// when email is the noreply address of the old one, and "" otherwise
+func renamedNoreplyEmail(email, oldLogin, newLogin string) string {
+	pattern := regexp.MustCompile(`(?i)^(\d+\+)?` + regexp.QuoteMeta(oldLogin) + `@users\.noreply\.github\.com$`)
+	match := pattern.FindStringSubmatch(email)
+	if match == nil {
+		return ""
+	}
+	return match[1] + newLogin + "@users.noreply.github.com"
+}
+
+// identityUpdates returns the [user] values of a config file that still carry
+// the old name or email
+func identityUpdates(path, oldName, newName, oldEmail, newEmail string) []gitConfigValue {
+	file, err := readGitConfigFile(path)
+	if err != nil {
+		return nil
+	}
+	section := file.findSection("user", "")
+	if section == nil {
+		return nil
+	}
+	var values []gitConfigValue
+	if newName != oldName && section.has("name") && section.get("name") == oldName {
+		values = append(values, gitConfigValue{Key: "user.name", Value: newName})
+	}
+	if newEmail != "" && newEmail != oldEmail && section.has("email") && strings.EqualFold(section.get("email"), oldEmail) {
+		values = append(values, gitConfigValue{Key: "user.email", Value: newEmail})
+	}
+	return values
+}
+
+// remoteRename is a remote URL to point at the renamed owner
+type remoteRename struct {
+	ConfigPath string
+	Repo       string
+	Key        string
+	Old        string
+	New        string
+}
+
+// findRenamedRemotes finds remotes of the account's host whose owner is the old login
+func findRenamedRemotes(account *Account, repos []string, oldLogin, newLogin string) []remoteRename {
+	hosts := []string{account.GetSSHHost(), account.GetProvider().Hostname}
+
+	var renames []remoteRename
+	for _, repo := range repos {
+		resolved, err := resolveRepoGitConfig(repo)
+		if err != nil {
+			continue
+		}
+		_, commonDir, _ := findGitDir(repo)
+		configPath := filepath.Join(commonDir, "config")
+
+		for _, entry := range resolved.Entries {
+			if entry.Origin != configPath || !strings.HasPrefix(entry.Key, "remote.") || !strings.HasSuffix(entry.Key, ".url") {
+				continue
+			}
+			remote, err := parseRemoteURL(entry.Value)
+			if err != nil || !containsString(hosts, remote.Host) {
+				continue
+			}
+			owner, rest, found := strings.Cut(remote.Path, "/")
+			if !found || !strings.EqualFold(owner, oldLogin) {
+				continue
+			}
+			at := strings.LastIndex(entry.Value, remote.Path)
+			renamed := entry.Value[:at] + newLogin + "/" + rest + entry.Value[at+len(remote.Path):]
+			renames = append(renames, remoteRename{ConfigPath: configPath, Repo: repo, Key: entry.Key, Old: entry.Value, New: renamed})
+		}
+	}
+	return renames
+}
+
+var accountSyncUsernameCmd = &cobra.Command{
+	Use:   "sync-username <account-name> [new-username]",
+	Short: "Follow a username change on the provider",
+	Long: `Update an account after its username was changed on the provider. Without a new
+username, the current one is looked up with the account's token or SSH key.
+
+Each step is confirmed separately:
+  - the account's stored username
+  - a GitHub noreply email address that contains the old username
+  - user.name and user.email in ~/.gitconfig and the account's directory includes
+  - remotes of repositories in the account's mapped directories (and --dir)
+    that still point at the old owner
+
+Examples:
+  krakn account sync-username work
+  krakn account sync-username work new-login --dir ~/src --yes`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		yes, _ := cmd.Flags().GetBool("yes")
+		extraDirs, _ := cmd.Flags().GetStringSlice("dir")
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		account := config.getAccount(args[0])
+		if account == nil {
+			return config.accountNotFound(args[0])
+		}
+		if err := config.revealAccount(account); err != nil {
+			return err
+		}
+
+		oldLogin := account.Username
+		var newLogin string
+		if len(args) == 2 {
+			newLogin = args[1]
+		} else {
+			if offlineMode {
+				return fmt.Errorf("❌ Pass the new username; it cannot be looked up with --offline")
+			}
+			change, profile, err := detectUsernameChange(config, account, true)
+			if err != nil {
+				return fmt.Errorf("❌ Could not look up the username: %s", strings.TrimPrefix(err.Error(), "❌ "))
+			}
+			if profile != nil {
+				account.Profile = profile
+			}
+			if change == nil {
+				fmt.Printf("✅ @%s is still the username of '%s'\n", oldLogin, account.Name)
+				if err := config.addAccount(*account); err != nil {
+					return errSaveConfig(err)
+				}
+				return nil
+			}
+			newLogin = change.New
+			fmt.Printf("🔎 The %s of '%s' now belongs to @%s\n", change.Source, account.Name, newLogin)
+			if !change.Certain {
+				fmt.Println("   ⚠️  This may also mean the key or token belongs to a different user")
+			}
+		}
+		if strings.EqualFold(newLogin, oldLogin) {
+			fmt.Printf("✅ @%s is already the stored username\n", oldLogin)
+			return nil
+		}
+
+		reader := bufio.NewReader(os.Stdin)
+		ask := func(question string) bool {
+			if yes {
+				return true
+			}
+			fmt.Printf("💬 %s [Y/n]: ", question)
+			resp, _ := reader.ReadString('\n')
+			resp = strings.ToLower(strings.TrimSpace(resp))
+			return resp == "" || resp == "y" || resp == "yes"
+		}
+
+		if !ask(fmt.Sprintf("Change the username of '%s' from @%s to @%s?", account.Name, oldLogin, newLogin)) {
+			fmt.Println("🚫 Nothing changed")
+			return nil
+		}
+		oldName, oldEmail := account.CommitName(), account.Email
+		account.Username = newLogin
+
+		newEmail := ""
+		if account.isSealed("email") {
+			fmt.Println("ℹ️  The email is sealed; check it for the old username yourself")
+		} else if renamed := renamedNoreplyEmail(account.Email, oldLogin, newLogin); renamed != "" {
+			if ask(fmt.Sprintf("Change the noreply email to %s?", renamed)) {
+				newEmail = renamed
+				account.Email = renamed
+			}
+		}
+
+		if err := config.addAccount(*account); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Printf("✅ Username of '%s' is now @%s\n", account.Name, newLogin)
+
+		// Git identity files written by 'krakn use', 'krakn global' and 'krakn config'
+		newName := account.CommitName()
+		files := []string{globalGitConfigPath()}
+		var dirs []string
+		for _, mapping := range config.Directories {
+			if mapping.Account == account.Name {
+				files = append(files, mapping.ConfigFile)
+				dirs = append(dirs, mapping.Path)
+			}
+		}
+		for _, path := range files {
+			values := identityUpdates(path, oldName, newName, oldEmail, newEmail)
+			if len(values) == 0 {
+				continue
+			}
+			if !ask(fmt.Sprintf("Update the identity in %s?", contractHomePath(path))) {
+				continue
+			}
+			changes, err := applyGitConfigValues(path, values)
+			if err != nil {
+				fmt.Printf("⚠️  %v\n", err)
+				continue
+			}
+			printGitConfigChanges(path, changes)
+		}
+
+		// Remotes still pointing at the old owner; the provider redirects them for now
+		for _, dir := range extraDirs {
+			dirs = append(dirs, expandUserPath(dir))
+		}
+		var repos []string
+		for _, dir := range dirs {
+			found, _ := partitionForeignRepos(findGitRepos(dir))
+			for _, repo := range found {
+				if !containsString(repos, repo) {
+					repos = append(repos, repo)
+				}
+			}
+		}
+		renames := findRenamedRemotes(account, repos, oldLogin, newLogin)
+		if len(renames) > 0 {
+			fmt.Printf("🔗 %d remotes still use @%s:\n", len(renames), oldLogin)
+			for _, rename := range renames {
+				fmt.Printf("   📁 %s: %s\n", contractHomePath(rename.Repo), rename.Old)
+			}
+			if ask("Point them at the new username?") {
+				for _, rename := range renames {
+					if _, err := applyGitConfigValues(rename.ConfigPath, []gitConfigValue{{Key: rename.Key, Value: rename.New}}); err != nil {
+						fmt.Printf("   ⚠️  %s: %v\n", rename.Repo, err)
+						continue
+					}
+					fmt.Printf("   ✅ %s → %s\n", contractHomePath(rename.Repo), rename.New)
+				}
+			}
+		} else if len(repos) > 0 {
+			fmt.Printf("✅ None of %d repositories uses the old username in a remote\n", len(repos))
+		}
+
+		if strings.EqualFold(account.Name, oldLogin) {
+			fmt.Printf("ℹ️  The account name '%s' and its SSH host alias %s keep the old username; they continue to work\n", account.Name, account.GetSSHHost())
+		}
+		return nil
+	},
+}
+
+func init() {
+	accountSyncUsernameCmd.Flags().BoolP("yes", "y", false, "Apply every step without asking")
+	accountSyncUsernameCmd.Flags().StringSlice("dir", nil, "Also update remotes of repositories below this directory")
+	accountCmd.AddCommand(accountSyncUsernameCmd)
+	registerDoctorCheck(doctorCheck{Name: "Provider usernames", Run: checkUsernameChanges})
+}
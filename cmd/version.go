@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// Build metadata, set at link time:
+//
+//	go build -ldflags "-X github.com/alminisl/krakncat/cmd.Version=1.2.0 -X github.com/alminisl/krakncat/cmd.Commit=abc123"
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Project metadata shared by the version output and release manifests
+const (
+	projectHomepage = "https://github.com/alminisl/krakncat"
+	projectLicense  = "MIT"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Show the krakn version and build information",
+	Long: `Show the krakn version, the commit it was built from and the Go toolchain.
+
+Examples:
+  krakn version
+  krakn --version`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf("krakn %s\n", Version)
+		if Commit != "" {
+			fmt.Printf("   commit: %s\n", Commit)
+		}
+		if BuildDate != "" {
+			fmt.Printf("   built:  %s\n", BuildDate)
+		}
+		fmt.Printf("   go:     %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	},
+}
+
+func init() {
+	RootCmd.Version = Version
+	RootCmd.AddCommand(versionCmd)
+}
@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// vscodeExtensionMain is the companion extension. It asks the socket of
+// 'krakn serve' or 'krakn watch' first and runs the CLI when nothing listens,
+// so the status bar stays cheap to refresh.
+const vscodeExtensionMain = `const vscode = require("vscode")
+const net = require("net")
+const os = require("os")
+const path = require("path")
+const { execFile } = require("child_process")
+
+function setting(name, fallback) {
+  return vscode.workspace.getConfiguration("krakn").get(name) || fallback
+}
+
+function socketPath() {
+  const configured = setting("socket", "").replace(/^~(?=\/|\\|$)/, os.homedir())
+  return configured || path.join(os.homedir(), ".krakncat", "krakn.sock")
+}
+
+// request sends one JSON line to the socket and resolves with the response,
+// or with null when no server is listening
+function request(body) {
+  return new Promise(resolve => {
+    const socket = net.createConnection(socketPath())
+    let data = ""
+    socket.setTimeout(2000)
+    socket.on("connect", () => socket.write(JSON.stringify(body) + "\n"))
+    socket.on("data", chunk => { data += chunk })
+    socket.on("end", () => {
+      try { resolve(JSON.parse(data)) } catch (error) { resolve(null) }
+    })
+    socket.on("timeout", () => { socket.destroy(); resolve(null) })
+    socket.on("error", () => resolve(null))
+  })
+}
+
+function run(args, cwd) {
+  return new Promise((resolve, reject) => {
+    execFile(setting("path", KRAKN), ["--plain"].concat(args), { cwd: cwd || os.homedir() }, (error, stdout, stderr) => {
+      if (error) reject(new Error((stderr || error.message).trim()))
+      else resolve(stdout)
+    })
+  })
+}
+
+async function resolveFolder(folder) {
+  const response = await request({ method: "resolve", path: folder })
+  if (response && response.ok) return response.result
+  return JSON.parse(await run(["status", "--output", "json"], folder))
+}
+
+async function listAccounts() {
+  const response = await request({ method: "list" })
+  if (response && response.ok) return response.result.accounts
+  return JSON.parse(await run(["list", "--output", "json"])).accounts
+}
+
+function activeFolder() {
+  const editor = vscode.window.activeTextEditor
+  const folder = (editor && vscode.workspace.getWorkspaceFolder(editor.document.uri)) || (vscode.workspace.workspaceFolders || [])[0]
+  return folder && folder.uri.scheme === "file" ? folder.uri.fsPath : null
+}
+
+function fail(error) {
+  vscode.window.showErrorMessage("krakn: " + (error.message || error))
+}
+
+function activate(context) {
+  const item = vscode.window.createStatusBarItem(vscode.StatusBarAlignment.Left, 100)
+  item.command = "krakn.switch"
+  const output = vscode.window.createOutputChannel("krakn")
+  context.subscriptions.push(item, output)
+
+  const refresh = async () => {
+    const folder = activeFolder()
+    if (!folder) {
+      item.hide()
+      return
+    }
+    try {
+      const status = await resolveFolder(folder)
+      const mismatch = status.match === "mismatch"
+      item.text = (mismatch ? "$(warning) " : "$(account) ") + (status.account || status.email || "no account")
+      item.tooltip = mismatch ? status.reason : [status.email, status.remote].filter(Boolean).join("\n")
+    } catch (error) {
+      item.text = "$(account) krakn"
+      item.tooltip = String(error.message || error)
+    }
+    item.show()
+  }
+
+  const switchAccount = async () => {
+    const folder = activeFolder()
+    let accounts
+    try { accounts = await listAccounts() } catch (error) { return fail(error) }
+    const account = await vscode.window.showQuickPick(accounts.map(a => ({
+      label: a.name,
+      description: [a.email, a.username, a.provider].filter(Boolean).join(" · "),
+      detail: a.current ? "Current global account" : undefined,
+    })), { placeHolder: "Switch to account" })
+    if (!account) return
+    const scopes = [{ label: "Globally", global: true }]
+    if (folder) scopes.unshift({ label: "This repository", description: folder, global: false })
+    const scope = scopes.length > 1 ? await vscode.window.showQuickPick(scopes, { placeHolder: "Use " + account.label }) : scopes[0]
+    if (!scope) return
+    try {
+      if (scope.global) {
+        const response = await request({ method: "switch", account: account.label })
+        if (!response) await run(["use", "--output", "json", account.label])
+        else if (!response.ok) throw new Error(response.error)
+      } else {
+        await run(["use", "--output", "json", account.label, folder], folder)
+      }
+      vscode.window.showInformationMessage("Using " + account.label + (scope.global ? " globally" : " in this repository"))
+    } catch (error) {
+      fail(error)
+    }
+    refresh()
+  }
+
+  const cloneRepository = async () => {
+    const url = await vscode.window.showInputBox({ prompt: "Repository to clone", placeHolder: "git@github.com:org/repo.git or org/repo" })
+    if (!url) return
+    let accounts
+    try { accounts = await listAccounts() } catch (error) { return fail(error) }
+    const choices = [{ label: "Automatic", description: "URL alias, directory mapping or the only account", automatic: true }]
+    const account = await vscode.window.showQuickPick(choices.concat(accounts.map(a => ({ label: a.name, description: a.email || "" }))), { placeHolder: "Clone as" })
+    if (!account) return
+    const parent = await vscode.window.showOpenDialog({ canSelectFolders: true, canSelectFiles: false, openLabel: "Clone here" })
+    if (!parent) return
+    const dest = path.join(parent[0].fsPath, url.replace(/\.git$/, "").split(/[\/:]/).pop())
+    const args = ["clone", url, dest]
+    if (!account.automatic) args.push("--account", account.label)
+    try {
+      await vscode.window.withProgress({ location: vscode.ProgressLocation.Notification, title: "Cloning " + url }, () => run(args))
+      if (await vscode.window.showInformationMessage("Cloned into " + dest, "Open")) {
+        vscode.commands.executeCommand("vscode.openFolder", vscode.Uri.file(dest), true)
+      }
+    } catch (error) {
+      fail(error)
+    }
+  }
+
+  const showStatus = async () => {
+    try {
+      output.clear()
+      output.append(await run(["status"], activeFolder()))
+      output.show(true)
+    } catch (error) {
+      fail(error)
+    }
+  }
+
+  const timer = setInterval(refresh, 60000)
+  context.subscriptions.push(
+    vscode.commands.registerCommand("krakn.switch", switchAccount),
+    vscode.commands.registerCommand("krakn.clone", cloneRepository),
+    vscode.commands.registerCommand("krakn.status", showStatus),
+    vscode.window.onDidChangeActiveTextEditor(refresh),
+    vscode.window.onDidChangeWindowState(state => { if (state.focused) refresh() }),
+    vscode.workspace.onDidChangeWorkspaceFolders(refresh),
+    { dispose: () => clearInterval(timer) },
+  )
+  refresh()
+}
+
+module.exports = { activate, deactivate() {} }
+`
+
+// semverPattern matches the versions VS Code accepts for an extension
+var semverPattern = regexp.MustCompile(`^v?(\d+\.\d+\.\d+)`)
+
+// vscodeExtensionVersion returns krakn's version in the form VS Code accepts;
+// development builds become 0.0.0
+func vscodeExtensionVersion() string {
+	if match := semverPattern.FindStringSubmatch(Version); match != nil {
+		return match[1]
+	}
+	return "0.0.0"
+}
+
+// vscodeExtension packs the companion extension as a .vsix, the package
+// 'code --install-extension' installs
+func vscodeExtension(config *Config, executable string) (map[string][]byte, error) {
+	krakn, err := json.Marshal(executable)
+	if err != nil {
+		return nil, err
+	}
+	version := vscodeExtensionVersion()
+	command := func(id, title string) map[string]string {
+		return map[string]string{"command": id, "title": title, "category": "krakn"}
+	}
+	manifest, err := json.MarshalIndent(map[string]interface{}{
+		"name":             "krakn",
+		"displayName":      "krakn",
+		"publisher":        "krakncat",
+		"version":          version,
+		"description":      "Show the git account of the workspace and switch or clone with krakn",
+		"repository":       "https://github.com/alminisl/krakncat",
+		"engines":          map[string]string{"vscode": "^1.60.0"},
+		"main":             "./extension.js",
+		"activationEvents": []string{"onStartupFinished"},
+		"contributes": map[string]interface{}{
+			"commands": []map[string]string{
+				command("krakn.switch", "Switch Git Account"),
+				command("krakn.clone", "Clone Repository as Account"),
+				command("krakn.status", "Show Git Identity Status"),
+			},
+			"configuration": map[string]interface{}{
+				"title": "krakn",
+				"properties": map[string]interface{}{
+					"krakn.path":   map[string]string{"type": "string", "default": "", "description": "krakn executable; empty uses the one that generated the extension"},
+					"krakn.socket": map[string]string{"type": "string", "default": "", "description": "Socket of 'krakn serve' or 'krakn watch'; empty uses ~/.krakncat/krakn.sock"},
+				},
+			},
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	main := "// " + generatedNotice("vscode") + "\n\nconst KRAKN = " + string(krakn) + "\n\n" + vscodeExtensionMain
+
+	vsixManifest := `<?xml version="1.0" encoding="utf-8"?>
+<PackageManifest Version="2.0.0" xmlns="http://schemas.microsoft.com/developer/vsx-schema/2011" xmlns:d="http://schemas.microsoft.com/developer/vsx-schema-design/2011">
+  <Metadata>
+    <Identity Language="en-US" Id="krakn" Version="` + version + `" Publisher="krakncat"/>
+    <DisplayName>krakn</DisplayName>
+    <Description xml:space="preserve">` + xmlText(generatedNotice("vscode")) + `</Description>
+    <Properties>
+      <Property Id="Microsoft.VisualStudio.Code.Engine" Value="^1.60.0"/>
+    </Properties>
+  </Metadata>
+  <Installation>
+    <InstallationTarget Id="Microsoft.VisualStudio.Code"/>
+  </Installation>
+  <Dependencies/>
+  <Assets>
+    <Asset Type="Microsoft.VisualStudio.Code.Manifest" Path="extension/package.json" Addressable="true"/>
+  </Assets>
+</PackageManifest>
+`
+	contentTypes := `<?xml version="1.0" encoding="utf-8"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+  <Default Extension=".json" ContentType="application/json"/>
+  <Default Extension=".js" ContentType="application/javascript"/>
+  <Default Extension=".vsixmanifest" ContentType="text/xml"/>
+</Types>
+`
+
+	// No timestamps, so regenerating an unchanged extension gives the same bytes
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range []struct{ Name, Content string }{
+		{"[Content_Types].xml", contentTypes},
+		{"extension.vsixmanifest", vsixManifest},
+		{"extension/package.json", string(manifest) + "\n"},
+		{"extension/extension.js", main},
+	} {
+		writer, err := archive.CreateHeader(&zip.FileHeader{Name: file.Name, Method: zip.Deflate})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write([]byte(file.Content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return map[string][]byte{"krakn.vsix": buf.Bytes()}, nil
+}
+
+// vscodeCLIs are the editors that install .vsix packages, in order of preference
+var vscodeCLIs = []string{"code", "code-insiders", "codium"}
+
+// installVSCodeExtension installs the generated .vsix into every VS Code
+// variant whose command line tool is on PATH
+func installVSCodeExtension(dir string) error {
+	vsix := filepath.Join(dir, "krakn.vsix")
+	installed := 0
+	for _, name := range vscodeCLIs {
+		if _, err := exec.LookPath(name); err != nil {
+			continue
+		}
+		output, err := traceExec(exec.Command(name, "--install-extension", vsix, "--force")).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("'%s --install-extension' failed: %s", name, firstLine(strings.TrimSpace(string(output))))
+		}
+		fmt.Printf("🧩 Installed into %s\n", name)
+		installed++
+	}
+	if installed == 0 {
+		fmt.Printf("💡 VS Code's 'code' command is not on PATH; install it with: code --install-extension %s\n", contractHomePath(vsix))
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+// watchState is what the watch daemon remembers between wake-ups and restarts
+type watchState struct {
+	ScheduledAccount string               `json:"scheduled_account,omitempty"` // Account the schedule selected at the last wake-up
+	ContextAccount   string               `json:"context_account,omitempty"`   // Account the network rules selected at the last wake-up
+	LastSwitch       time.Time            `json:"last_switch,omitempty"`
+	LastSwitchTo     string               `json:"last_switch_to,omitempty"`
+	LastRepoScan     time.Time            `json:"last_repo_scan,omitempty"`
+	Reported         []string             `json:"reported,omitempty"`       // Repository problems already reported, as "repo: message"
+	DoctorChecked    time.Time            `json:"doctor_checked,omitempty"` // Last doctor run for the metrics endpoint
+	DoctorWarnings   int                  `json:"doctor_warnings,omitempty"`
+	DoctorErrors     int                  `json:"doctor_errors,omitempty"`
+	NextVerify       map[string]time.Time `json:"next_verify,omitempty"` // When each account's verification is refreshed next
+}
+
+// watchContext is shared by the tasks of one wake-up
+type watchContext struct {
+	Config *Config
+	State  *watchState
+	Now    time.Time
+}
+
+// watchTask is work the daemon does on every wake-up, registered by the files
+// that own the feature
+type watchTask struct {
+	Name string
+	Run  func(ctx *watchContext) error
+}
+
+var watchTasks []watchTask
+
+// watchBackground tracks work that tasks start outside daemonMu, such as network
+// probes, so slow work never holds up socket requests. 'krakn watch --once'
+// waits for it before exiting.
+var watchBackground sync.WaitGroup
+
+// registerWatchTask adds a task to every 'krakn watch' wake-up
+func registerWatchTask(task watchTask) {
+	watchTasks = append(watchTasks, task)
+}
+
+func getWatchStatePath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".krakncat", "watch-state.json")
+}
+
+func loadWatchState() *watchState {
+	state := &watchState{}
+	if data, err := os.ReadFile(getWatchStatePath()); err == nil {
+		json.Unmarshal(data, state)
+	}
+	return state
+}
+
+func (s *watchState) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(getWatchStatePath()), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(getWatchStatePath(), data, 0600)
+}
+
+// runScheduleSwitch sets the global identity when the scheduled account
+// changes. Only changes trigger a switch, so switching by hand in between
+// is never undone until the schedule moves on.
+func runScheduleSwitch(ctx *watchContext) error {
+	if ctx.Config.Schedule == nil || !ctx.Config.Schedule.AutoSwitch {
+		return nil
+	}
+	scheduled := ctx.Config.scheduledAccount(ctx.Now)
+	if scheduled == "" || scheduled == ctx.State.ScheduledAccount {
+		return nil
+	}
+	ctx.State.ScheduledAccount = scheduled
+	if scheduled == ctx.Config.CurrentAccount {
+		return nil
+	}
+
+	account := ctx.Config.getAccount(scheduled)
+	if account.isSealed("email") {
+		return fmt.Errorf("account '%s' has a sealed email and cannot be switched to unattended", scheduled)
+	}
+	changes, err := switchGlobalAccount(ctx.Config, account, "schedule")
+	if err != nil {
+		return err
+	}
+	ctx.State.LastSwitch = ctx.Now
+	ctx.State.LastSwitchTo = scheduled
+	fmt.Printf("🔄 %s Switched to '%s' (schedule)\n", ctx.Now.Format("15:04"), scheduled)
+	printGitConfigChanges(globalGitConfigPath(), changes)
+	return nil
+}
+
+// watchWakeUp runs every watch task once. A daemon that keeps running also
+// publishes its state to the metrics endpoint.
+func watchWakeUp(state *watchState, serve bool) {
+	daemonMu.Lock()
+	defer daemonMu.Unlock()
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Printf("⚠️  %s Could not load config: %v\n", time.Now().Format("15:04"), err)
+		return
+	}
+	ctx := &watchContext{Config: config, State: state, Now: time.Now()}
+	for _, task := range watchTasks {
+		if err := task.Run(ctx); err != nil {
+			fmt.Printf("⚠️  %s %s: %v\n", ctx.Now.Format("15:04"), task.Name, err)
+		}
+	}
+	if err := state.save(); err != nil {
+		fmt.Printf("⚠️  Could not save watch state: %v\n", err)
+	}
+	if serve {
+		publishMetrics(ctx)
+	}
+
+	// Each wake-up is its own entry in 'krakn log'
+	if err := finishOperation(); err != nil {
+		fmt.Printf("⚠️  Could not record operation history: %v\n", err)
+	}
+}
+
+// configReloadDelay lets a burst of events (a sync tool writing a temporary
+// file and renaming it) settle into one reload
+const configReloadDelay = 500 * time.Millisecond
+
+// watchConfigFile reports changes to config.json on the returned channel. The
+// directory is watched rather than the file, because editors and sync tools
+// replace the file by renaming, which ends a watch on the file itself.
+func watchConfigFile() (*fsnotify.Watcher, <-chan struct{}, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, err
+	}
+	configPath := getConfigPath()
+	if err := watcher.Add(filepath.Dir(configPath)); err != nil {
+		watcher.Close()
+		return nil, nil, err
+	}
+
+	changed := make(chan struct{}, 1)
+	go func() {
+		var debounce *time.Timer
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Base(event.Name) != filepath.Base(configPath) || event.Op == fsnotify.Chmod {
+					continue
+				}
+				if debounce != nil {
+					debounce.Stop()
+				}
+				debounce = time.AfterFunc(configReloadDelay, func() {
+					select {
+					case changed <- struct{}{}:
+					default:
+					}
+				})
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	return watcher, changed, nil
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Run in the background and apply schedules and network rules",
+	Long: `Stay running and wake up periodically to apply time-based schedules (see
+'krakn schedule') and network rules (see 'krakn context'). Every 15 minutes the
+repositories below mapped directories are checked as well, and new identity
+problems are reported (see 'krakn notify' for desktop notifications). With
+'krakn auth-refresh on', the cached 'krakn test' results are refreshed too.
+Waking up from sleep counts as a wake-up as well, so the identity is corrected
+right after the machine resumes. Changes to the krakn
+config (e.g. from a sync tool) are picked up immediately, without a restart.
+To start it with your session, install it with 'krakn service install'.
+
+Tray apps and launcher extensions talk to the daemon over the Unix socket
+~/.krakncat/krakn.sock: each connection sends one JSON line and gets one back.
+
+  {"method":"accounts"}                   → {"api":1,"ok":true,"current":"work","accounts":[...]}
+  {"method":"current"}                    → {"api":1,"ok":true,"current":"work","message":"work"}
+  {"method":"switch","account":"oss"}     → {"api":1,"ok":true,"current":"oss","message":"Switched to oss"}
+
+The list, resolve and doctor methods of 'krakn serve' are served as well.
+Failures have "ok":false and an "error". The "api" version only changes for
+incompatible changes. 'krakn integrations generate' builds launcher extensions on it.
+
+Examples:
+  krakn watch                 # Check every minute until interrupted
+  krakn watch --interval 5m
+  krakn watch --once          # Single wake-up, e.g. from cron`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interval, _ := cmd.Flags().GetDuration("interval")
+		once, _ := cmd.Flags().GetBool("once")
+		if interval < time.Second {
+			return fmt.Errorf("❌ --interval must be at least 1s")
+		}
+
+		state := loadWatchState()
+		watchWakeUp(state, !once)
+		if once {
+			watchBackground.Wait()
+			return nil
+		}
+
+		fmt.Printf("👀 Watching every %s (Ctrl+C to stop)\n", interval)
+		if listener, err := serveIPC(getSocketPath(), state); err != nil {
+			fmt.Printf("⚠️  Cannot serve the socket API: %v\n", err)
+		} else {
+			defer listener.Close()
+			fmt.Printf("🔌 Accepting requests on %s\n", contractHomePath(getSocketPath()))
+		}
+		var configChanged <-chan struct{}
+		if watcher, changed, err := watchConfigFile(); err != nil {
+			fmt.Printf("⚠️  Cannot watch the config for changes (%v); it is reloaded every %s instead\n", err, interval)
+		} else {
+			defer watcher.Close()
+			configChanged = changed
+		}
+
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				watchWakeUp(state, true)
+			case <-configChanged:
+				if config, err := loadConfig(); err == nil {
+					fmt.Printf("🔁 %s Config changed, reloaded %d accounts\n", time.Now().Format("15:04"), len(config.Accounts))
+				}
+				watchWakeUp(state, true)
+			case <-stop:
+				fmt.Println("👋 Stopped watching")
+				return nil
+			}
+		}
+	},
+}
+
+func init() {
+	watchCmd.Flags().Duration("interval", time.Minute, "Time between wake-ups")
+	watchCmd.Flags().Bool("once", false, "Wake up once and exit")
+	RootCmd.AddCommand(watchCmd)
+	registerWatchTask(watchTask{Name: "Schedule", Run: runScheduleSwitch})
+	registerReleaseService(releaseService{
+		Name:        "krakn-watch",
+		Args:        []string{"watch"},
+		Description: "krakncat identity watcher",
+	})
+}
@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/user"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// WebhookConfig posts switches and policy violations to a URL, e.g. a Slack
+// incoming webhook or a local automation server
+type WebhookConfig struct {
+	URL      string   `json:"url"`
+	Events   []string `json:"events,omitempty"`   // "switch", "violation"; both when empty
+	Template string   `json:"template,omitempty"` // text/template rendering the body; the event as JSON when empty
+}
+
+// webhookEvents are the events that can be posted
+var webhookEvents = []string{"switch", "violation"}
+
+// slackTemplate is the body Slack's incoming webhooks expect
+const slackTemplate = `{"text": {{json .Text}}}`
+
+// noNotify suppresses desktop notifications and webhooks for one run. Git
+// hooks cannot pass flags, so KRAKN_NO_NOTIFY=1 does the same.
+var noNotify bool
+
+func notificationsSuppressed() bool {
+	return noNotify || os.Getenv("KRAKN_NO_NOTIFY") == "1"
+}
+
+// webhookEvent is the payload of a webhook, and the data of its template
+type webhookEvent struct {
+	Event      string    `json:"event"` // "switch" or "violation"
+	Level      string    `json:"level"` // info, warn or error
+	Text       string    `json:"text"`  // One-line summary for chat tools
+	Account    string    `json:"account,omitempty"`
+	Previous   string    `json:"previous,omitempty"` // Account before a global switch
+	Email      string    `json:"email,omitempty"`    // Left out for sealed emails
+	Scope      string    `json:"scope,omitempty"`    // "global" or "repository"
+	Repository string    `json:"repository,omitempty"`
+	Source     string    `json:"source,omitempty"` // What switched: use, socket, schedule or network
+	Host       string    `json:"host"`
+	User       string    `json:"user"`
+	Time       time.Time `json:"time"`
+}
+
+func (w *WebhookConfig) wants(event string) bool {
+	return len(w.Events) == 0 || containsString(w.Events, event)
+}
+
+// renderWebhook returns the body of a webhook: the event as JSON, or the
+// template rendered with the event. Templates get a json function that
+// quotes a value, so {"text": {{json .Text}}} stays valid JSON.
+func renderWebhook(w *WebhookConfig, event webhookEvent) ([]byte, error) {
+	if w.Template == "" {
+		return json.Marshal(event)
+	}
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{
+		"json": func(value interface{}) (string, error) {
+			data, err := json.Marshal(value)
+			return string(data), err
+		},
+	}).Parse(w.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("the template does not render valid JSON: %s", firstLine(buf.String()))
+	}
+	return buf.Bytes(), nil
+}
+
+// sendWebhook posts an event and fails on anything but a 2xx response
+func sendWebhook(w *WebhookConfig, event webhookEvent) error {
+	body, err := renderWebhook(w, event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "krakn/"+Version)
+	resp, err := (&http.Client{Timeout: 5 * time.Second}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s answered %s", redactWebhookURL(w.URL), resp.Status)
+	}
+	return nil
+}
+
+// postWebhook fills in where the event happened and posts it. A webhook is
+// informational, so failing to post is reported but never fails the caller.
+func (c *Config) postWebhook(event webhookEvent) {
+	if c.Webhook == nil || !c.Webhook.wants(event.Event) || notificationsSuppressed() {
+		return
+	}
+	if offlineMode {
+		tracef(traceMatch, "Offline, so the %s webhook is not posted", event.Event)
+		return
+	}
+	event.Host, _ = os.Hostname()
+	if current, err := user.Current(); err == nil {
+		event.User = current.Username
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if err := sendWebhook(c.Webhook, event); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Could not post to the webhook: %v\n", err)
+	}
+}
+
+// announceSwitch posts a switch of the global identity (repository empty)
+// or of a repository's identity
+func (c *Config) announceSwitch(previous string, account *Account, repository, source string) {
+	event := webhookEvent{
+		Event:    "switch",
+		Level:    doctorInfo,
+		Account:  account.Name,
+		Previous: previous,
+		Scope:    "global",
+		Source:   source,
+		Text:     fmt.Sprintf("Switched to '%s'", account.Name),
+	}
+	if repository != "" {
+		event.Scope = "repository"
+		event.Repository = repository
+		event.Previous = ""
+		event.Text = fmt.Sprintf("Switched %s to '%s'", contractHomePath(repository), account.Name)
+	} else if previous != "" && previous != account.Name {
+		event.Text = fmt.Sprintf("Switched from '%s' to '%s'", previous, account.Name)
+	}
+	if !account.isSealed("email") {
+		event.Email = account.Email
+	}
+	c.postWebhook(event)
+}
+
+// redactWebhookURL hides the path of a webhook URL, which is the secret of
+// services such as Slack
+func redactWebhookURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return "(invalid URL)"
+	}
+	if parsed.Path == "" || parsed.Path == "/" {
+		return parsed.Scheme + "://" + parsed.Host
+	}
+	return parsed.Scheme + "://" + parsed.Host + "/…"
+}
+
+var notifyWebhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Post switches and policy violations to a webhook",
+	Long: `Post a JSON payload to a URL whenever the account is switched (krakn use, the
+socket API, schedules and network contexts) and for policy violations (the
+findings of 'krakn watch' and 'krakn guard' that also raise notifications).
+Teams can track identity hygiene in Slack or feed a local automation.
+
+The payload is the event as JSON:
+
+  {"event":"switch","level":"info","text":"Switched from 'oss' to 'work'",
+   "account":"work","previous":"oss","email":"alice@acme.example",
+   "scope":"global","source":"use","host":"laptop","user":"alice",
+   "time":"2026-10-16T09:30:00Z"}
+
+A --template replaces it with a text/template rendered with the same fields
+(.Event, .Level, .Text, .Account, .Previous, .Email, .Scope, .Repository,
+.Source, .Host, .User, .Time). The json function quotes a value. The result
+must be valid JSON. --slack uses {"text": {{json .Text}}}.
+
+Pass --no-notify (or set KRAKN_NO_NOTIFY=1) to skip notifications and the
+webhook for one command. Nothing is posted with --offline.
+
+Examples:
+  krakn notify webhook set https://hooks.slack.com/services/T0/B0/XXX --slack
+  krakn notify webhook set http://localhost:8080/krakn --events violation
+  krakn notify webhook set https://example.com/hook --template '{"who": {{json .User}}, "what": {{json .Text}}}'
+  krakn notify webhook test
+  krakn notify webhook off
+  krakn use work --no-notify`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if config.Webhook == nil {
+			fmt.Println("🔕 No webhook is configured. Add one with 'krakn notify webhook set <url>'.")
+			return nil
+		}
+		events := config.Webhook.Events
+		if len(events) == 0 {
+			events = webhookEvents
+		}
+		fmt.Printf("🪝 Webhook: %s\n", redactWebhookURL(config.Webhook.URL))
+		fmt.Printf("📣 Events: %s\n", strings.Join(events, ", "))
+		if config.Webhook.Template != "" {
+			fmt.Printf("📝 Template: %s\n", config.Webhook.Template)
+		}
+		return nil
+	},
+}
+
+var notifyWebhookSetCmd = &cobra.Command{
+	Use:   "set <url>",
+	Short: "Configure the webhook",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tmpl, _ := cmd.Flags().GetString("template")
+		slack, _ := cmd.Flags().GetBool("slack")
+		spec, _ := cmd.Flags().GetString("events")
+
+		parsed, err := url.Parse(args[0])
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("❌ '%s' is not an http(s) URL", args[0])
+		}
+		if slack {
+			if tmpl != "" {
+				return fmt.Errorf("❌ Use either --slack or --template")
+			}
+			tmpl = slackTemplate
+		}
+		webhook := &WebhookConfig{URL: args[0], Template: tmpl}
+		for _, event := range strings.Split(spec, ",") {
+			event = strings.ToLower(strings.TrimSpace(event))
+			if !containsString(webhookEvents, event) {
+				return fmt.Errorf("❌ Unknown event '%s'. Use %s", event, strings.Join(webhookEvents, ", "))
+			}
+			if !containsString(webhook.Events, event) {
+				webhook.Events = append(webhook.Events, event)
+			}
+		}
+		if len(webhook.Events) == len(webhookEvents) {
+			webhook.Events = nil
+		}
+		// Catch template mistakes now rather than on the first switch
+		if _, err := renderWebhook(webhook, webhookEvent{Event: "switch", Account: "example", Text: "Switched to 'example'"}); err != nil {
+			return fmt.Errorf("❌ %v", err)
+		}
+
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if err := config.update(func(config *Config) error {
+			config.Webhook = webhook
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		events := webhook.Events
+		if len(events) == 0 {
+			events = webhookEvents
+		}
+		fmt.Printf("🪝 Posting %s events to %s\n", strings.Join(events, " and "), redactWebhookURL(webhook.URL))
+		fmt.Println("💡 Check it with 'krakn notify webhook test'")
+		return nil
+	},
+}
+
+var notifyWebhookOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Remove the webhook",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if err := config.update(func(config *Config) error {
+			config.Webhook = nil
+			return nil
+		}); err != nil {
+			return errSaveConfig(err)
+		}
+		fmt.Println("🔕 The webhook is removed")
+		return nil
+	},
+}
+
+var notifyWebhookTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Post a test event to the webhook",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		if config.Webhook == nil {
+			return fmt.Errorf("❌ No webhook is configured. Add one with 'krakn notify webhook set <url>'")
+		}
+		event := webhookEvent{Event: "test", Level: doctorInfo, Text: "krakn webhook test", Account: config.CurrentAccount, Time: time.Now()}
+		event.Host, _ = os.Hostname()
+		if current, err := user.Current(); err == nil {
+			event.User = current.Username
+		}
+		if err := sendWebhook(config.Webhook, event); err != nil {
+			return fmt.Errorf("❌ Could not post to the webhook: %v", err)
+		}
+		fmt.Printf("✅ Posted a test event to %s\n", redactWebhookURL(config.Webhook.URL))
+		return nil
+	},
+}
+
+func init() {
+	notifyWebhookSetCmd.Flags().String("template", "", "text/template for the JSON body (default: the event as JSON)")
+	notifyWebhookSetCmd.Flags().Bool("slack", false, "Format the body for a Slack incoming webhook")
+	notifyWebhookSetCmd.Flags().String("events", "switch,violation", "Events to post: switch, violation")
+	notifyWebhookCmd.AddCommand(notifyWebhookSetCmd)
+	notifyWebhookCmd.AddCommand(notifyWebhookOffCmd)
+	notifyWebhookCmd.AddCommand(notifyWebhookTestCmd)
+	notifyCmd.AddCommand(notifyWebhookCmd)
+	RootCmd.PersistentFlags().BoolVar(&noNotify, "no-notify", false, "Raise no desktop notifications and post no webhooks")
+}
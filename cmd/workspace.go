@@ -0,0 +1,332 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultWorkspaceFile is the manifest 'krakn workspace' reads when no --file is given
+const defaultWorkspaceFile = "krakn-workspace.json"
+
+// workspaceManifest describes a set of repositories to clone, e.g. everything
+// a team member needs. It is meant to be shared and kept in version control.
+type workspaceManifest struct {
+	Root  string          `json:"root,omitempty"` // Clone destination; defaults to the manifest's directory
+	Repos []workspaceRepo `json:"repos"`
+}
+
+// workspaceRepo is one repository of a workspace
+type workspaceRepo struct {
+	Path    string `json:"path"` // Relative to the workspace root
+	URL     string `json:"url"`
+	Account string `json:"account,omitempty"` // Clone through this account's host alias and identity
+	Branch  string `json:"branch,omitempty"`
+}
+
+// workspaceState records the outcome of the last apply per repository path,
+// so a rerun only retries what failed or is still missing
+type workspaceState struct {
+	Manifest string                         `json:"manifest"`
+	Repos    map[string]workspaceRepoStatus `json:"repos"`
+}
+
+type workspaceRepoStatus struct {
+	Status string    `json:"status"` // "cloned", "existing" or "failed"
+	Error  string    `json:"error,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+func loadWorkspaceManifest(path string) (*workspaceManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace manifest: %w", err)
+	}
+	var manifest workspaceManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if manifest.Root == "" {
+		manifest.Root = filepath.Dir(path)
+	} else {
+		manifest.Root = expandUserPath(manifest.Root)
+		if !filepath.IsAbs(manifest.Root) {
+			manifest.Root = filepath.Join(filepath.Dir(path), manifest.Root)
+		}
+	}
+	for i, repo := range manifest.Repos {
+		if repo.URL == "" || repo.Path == "" {
+			return nil, fmt.Errorf("❌ Repository #%d in %s needs both 'path' and 'url'", i+1, path)
+		}
+	}
+	return &manifest, nil
+}
+
+// getWorkspaceStatePath returns where the state of a manifest is kept. State
+// lives in ~/.krakncat so shared manifests stay free of per-machine data.
+func getWorkspaceStatePath(manifestPath string) string {
+	sum := sha256.Sum256([]byte(manifestPath))
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".krakncat", "workspaces", hex.EncodeToString(sum[:8])+".json")
+}
+
+func loadWorkspaceState(manifestPath string) *workspaceState {
+	state := &workspaceState{Manifest: manifestPath, Repos: map[string]workspaceRepoStatus{}}
+	data, err := os.ReadFile(getWorkspaceStatePath(manifestPath))
+	if err != nil {
+		return state
+	}
+	if json.Unmarshal(data, state) != nil || state.Repos == nil {
+		state.Repos = map[string]workspaceRepoStatus{}
+	}
+	return state
+}
+
+func (s *workspaceState) save() error {
+	path := getWorkspaceStatePath(s.Manifest)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// cloneWorkspaceRepo clones a repository, routing SSH remotes through the
+// account's host alias and setting its identity in the new repository
+func cloneWorkspaceRepo(config *Config, repo workspaceRepo, dest string) error {
+	url := repo.URL
+	args := []string{"clone", "--quiet"}
+
+	if repo.Account != "" {
+		account := config.getAccount(repo.Account)
+		if account == nil {
+			return config.accountNotFound(repo.Account)
+		}
+		if remote, err := parseRemoteURL(url); err == nil {
+			switch {
+			case account.usesHTTPS() || (remote.isSSH() && !config.LocalOnly):
+				url = accountRemoteURL(remote, account)
+			case remote.isSSH():
+				// Local-only mode has no Host alias; core.sshCommand selects the key
+				url = fmt.Sprintf("git@%s:%s", account.GetProvider().Hostname, remote.Path)
+			}
+		}
+		if err := writeCommitTemplate(account); err != nil {
+			return err
+		}
+		for _, value := range config.localIdentityValues(account) {
+			args = append(args, "-c", value.Key+"="+value.Value)
+		}
+	}
+	if repo.Branch != "" {
+		args = append(args, "--branch", repo.Branch)
+	}
+	args = append(args, url, dest)
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	cmd := traceExec(exec.Command("git", args...))
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := firstLine(strings.TrimSpace(string(output))); msg != "" {
+			return fmt.Errorf("%s", msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// resolveWorkspaceFile returns the absolute manifest path from --file
+func resolveWorkspaceFile(cmd *cobra.Command) (string, error) {
+	file, _ := cmd.Flags().GetString("file")
+	path, err := filepath.Abs(expandUserPath(file))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	if !fileExists(path) {
+		return "", fmt.Errorf("❌ Workspace manifest not found: %s", path)
+	}
+	return path, nil
+}
+
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Clone a set of repositories described by a manifest",
+	Long: `Clone the repositories listed in a workspace manifest (krakn-workspace.json),
+each through the SSH host alias and identity of its account:
+
+  {
+    "root": "~/work",
+    "repos": [
+      {"path": "api", "url": "git@github.com:acme/api.git", "account": "work"},
+      {"path": "tools/cli", "url": "git@github.com:me/cli.git", "account": "personal"}
+    ]
+  }
+
+Examples:
+  krakn workspace apply                    # Clone everything missing
+  krakn workspace apply --jobs 8           # Clone up to 8 repositories at once
+  krakn workspace status --file team.json  # Show what the last apply did`,
+}
+
+var workspaceApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Clone the workspace's missing repositories in parallel",
+	Long: `Clone every repository of the workspace that does not exist yet. Clones run in
+parallel and their outcome is recorded, so rerunning apply after a failure
+only retries the repositories that failed or are still missing.
+
+Examples:
+  krakn workspace apply
+  krakn workspace apply --jobs 8 --file ~/team/krakn-workspace.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		if jobs < 1 {
+			jobs = 1
+		}
+		manifestPath, err := resolveWorkspaceFile(cmd)
+		if err != nil {
+			return err
+		}
+		manifest, err := loadWorkspaceManifest(manifestPath)
+		if err != nil {
+			return err
+		}
+		config, err := loadConfig()
+		if err != nil {
+			return errLoadConfig(err)
+		}
+		for i := range config.Accounts {
+			if err := config.revealAccount(&config.Accounts[i]); err != nil {
+				return err
+			}
+		}
+
+		state := loadWorkspaceState(manifestPath)
+
+		// Repositories that already exist are done; everything else is cloned
+		var pending []workspaceRepo
+		for _, repo := range manifest.Repos {
+			dest := filepath.Join(manifest.Root, repo.Path)
+			if isGitRepository(dest) {
+				if state.Repos[repo.Path].Status != "cloned" {
+					state.Repos[repo.Path] = workspaceRepoStatus{Status: "existing", Time: time.Now()}
+				}
+				continue
+			}
+			pending = append(pending, repo)
+		}
+		if len(pending) == 0 {
+			fmt.Printf("✅ All %d repositories are present in %s\n", len(manifest.Repos), contractHomePath(manifest.Root))
+			return state.save()
+		}
+
+		fmt.Printf("📦 Cloning %d of %d repositories into %s (%d at a time)\n",
+			len(pending), len(manifest.Repos), contractHomePath(manifest.Root), jobs)
+
+		type cloneResult struct {
+			repo workspaceRepo
+			err  error
+		}
+		work := make(chan workspaceRepo)
+		results := make(chan cloneResult)
+		for i := 0; i < jobs; i++ {
+			go func() {
+				for repo := range work {
+					results <- cloneResult{repo, cloneWorkspaceRepo(config, repo, filepath.Join(manifest.Root, repo.Path))}
+				}
+			}()
+		}
+		go func() {
+			for _, repo := range pending {
+				work <- repo
+			}
+			close(work)
+		}()
+
+		// Results are recorded as they arrive so an interrupted apply can resume
+		failures := 0
+		for done := 1; done <= len(pending); done++ {
+			result := <-results
+			status := workspaceRepoStatus{Status: "cloned", Time: time.Now()}
+			if result.err != nil {
+				status = workspaceRepoStatus{Status: "failed", Error: result.err.Error(), Time: time.Now()}
+				failures++
+				fmt.Printf("   ❌ [%d/%d] %s: %v\n", done, len(pending), result.repo.Path, result.err)
+			} else {
+				fmt.Printf("   ✅ [%d/%d] %s\n", done, len(pending), result.repo.Path)
+			}
+			state.Repos[result.repo.Path] = status
+			if err := state.save(); err != nil {
+				fmt.Printf("⚠️  Could not record workspace state: %v\n", err)
+			}
+		}
+
+		if failures > 0 {
+			fmt.Println("💡 Rerun 'krakn workspace apply' to retry the failed repositories")
+			return fmt.Errorf("❌ %d of %d clones failed", failures, len(pending))
+		}
+		fmt.Printf("🎉 Cloned %d repositories\n", len(pending))
+		return nil
+	},
+}
+
+var workspaceStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which workspace repositories are present, missing or failed",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manifestPath, err := resolveWorkspaceFile(cmd)
+		if err != nil {
+			return err
+		}
+		manifest, err := loadWorkspaceManifest(manifestPath)
+		if err != nil {
+			return err
+		}
+		state := loadWorkspaceState(manifestPath)
+
+		fmt.Printf("📦 Workspace %s → %s\n", contractHomePath(manifestPath), contractHomePath(manifest.Root))
+		missing := 0
+		for _, repo := range manifest.Repos {
+			status := state.Repos[repo.Path]
+			switch {
+			case isGitRepository(filepath.Join(manifest.Root, repo.Path)):
+				fmt.Printf("   ✅ %s\n", repo.Path)
+			case status.Status == "failed":
+				fmt.Printf("   ❌ %s (failed %s: %s)\n", repo.Path, status.Time.Format("2006-01-02 15:04"), status.Error)
+				missing++
+			default:
+				fmt.Printf("   ⏳ %s (missing)\n", repo.Path)
+				missing++
+			}
+		}
+		if missing > 0 {
+			fmt.Printf("💡 %d repositories left; run 'krakn workspace apply'\n", missing)
+		}
+		return nil
+	},
+}
+
+func init() {
+	workspaceCmd.PersistentFlags().StringP("file", "f", defaultWorkspaceFile, "Workspace manifest")
+	workspaceApplyCmd.Flags().IntP("jobs", "j", 4, "Number of repositories to clone at once")
+	workspaceCmd.AddCommand(workspaceApplyCmd)
+	workspaceCmd.AddCommand(workspaceStatusCmd)
+	RootCmd.AddCommand(workspaceCmd)
+}
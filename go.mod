@@ -2,9 +2,20 @@ module github.com/alminisl/krakncat
 
 go 1.21
 
-require github.com/spf13/cobra v1.9.1
+require (
+	filippo.io/age v1.2.1
+	github.com/fsnotify/fsnotify v1.9.0
+	github.com/spf13/cobra v1.9.1
+	github.com/spf13/pflag v1.0.6
+	github.com/tyler-smith/go-bip39 v1.1.0
+	golang.org/x/crypto v0.24.0
+	golang.org/x/sys v0.21.0
+	golang.org/x/term v0.21.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
+	github.com/cpuguy83/go-md2man/v2 v2.0.7 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.6 // indirect
+	github.com/russross/blackfriday/v2 v2.1.0 // indirect
 )